@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"termsuji-local/config"
+	"termsuji-local/sgf"
+	"termsuji-local/ui"
+)
+
+// engineVersionTimeout bounds how long the About page waits on
+// "gnugo --version".
+const engineVersionTimeout = 2 * time.Second
+
+// buildInfo returns the commit and build date, preferring the ldflags
+// values and falling back to what the Go toolchain stamped into the binary.
+func (a *App) buildInfo() (commit, date string) {
+	commit, date = a.opts.Commit, a.opts.BuildDate
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return commit, date
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		case "vcs.time":
+			if date == "" {
+				date = s.Value
+			}
+		}
+	}
+	if commit == "" && revision != "" {
+		if len(revision) > 7 {
+			revision = revision[:7]
+		}
+		if modified == "true" {
+			revision += "-dirty"
+		}
+		commit = revision
+	}
+	return commit, date
+}
+
+// engineVersion runs the engine with --version and returns the first line
+// of its output.
+func engineVersion(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), engineVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// probeAbout gathers what the About page shows from the running program.
+func (a *App) probeAbout() ui.AboutInfo {
+	info := ui.AboutInfo{
+		Version:   a.opts.Version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		DebugLog:  a.opts.DebugLog,
+		Colors:    a.screenColors,
+		TrueColor: a.screenColors >= 1<<24,
+		Mouse:     mouseEnabled,
+	}
+	info.Commit, info.BuildDate = a.buildInfo()
+	info.ConfigPath, info.ConfigExists = config.ConfigPath()
+	info.StatePath, _ = config.StatePath()
+
+	info.HistoryDir = config.HistoryDir()
+	games, err := sgf.ListGames(info.HistoryDir)
+	info.HistoryGames, info.HistoryErr = len(games), err
+
+	if path, err := GnuGoPath(a.cfg); err != nil {
+		info.EngineErr = err
+	} else {
+		info.EnginePath = path
+		info.EngineVersion, info.EngineErr = engineVersion(path)
+	}
+	return info
+}
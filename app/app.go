@@ -0,0 +1,460 @@
+// Package app wires the screens of termsuji-local together: it builds the
+// pages, switches between them, and starts, loads and reviews games.
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/coords"
+	"termsuji-local/engine"
+	"termsuji-local/server"
+	"termsuji-local/sgf"
+	"termsuji-local/ui"
+)
+
+// mouseEnabled is passed to the application; the About page reports it.
+const mouseEnabled = false
+
+// EngineFactory makes the engine for a game. The program plays GnuGo and
+// other GTP engines; tests substitute their own.
+type EngineFactory func(engine.GameConfig) engine.GameEngine
+
+// Options are what the command line asks of the app.
+type Options struct {
+	// Build info for the About page and the release notes
+	Version   string
+	Commit    string
+	BuildDate string
+	DebugLog  string // where the GTP traffic, and moves dropped at game end, are logged
+
+	FirstRun  bool // there was no config file, so the welcome card shows
+	NoColor   bool
+	ColorFGBG string // the terminal's COLORFGBG, to tell a light background from a dark one
+	NoUTF8    bool   // the locale isn't UTF-8, so box drawing and stones are likely garbled
+
+	// Quick start: any of these starts a game straight away
+	Play       bool
+	BoardSize  int
+	Color      string // "black" or "white", or just "b" or "w"
+	Difficulty int
+	Komi       float64 // negative for the configured default
+	Handicap   int
+	Focus      bool
+
+	Book     string // SGF whose main line both sides follow
+	Load     string // SGF from anywhere to open for review
+	Continue bool   // continue the last unfinished game
+	NoRecord bool   // don't record games this session, whatever the config says
+	Seed     int64  // GnuGo's random seed, so a game replays the same; 0 for its own
+}
+
+// quickStart reports whether the options start a game without the setup
+// card.
+func (o Options) quickStart() bool {
+	return o.Play || o.BoardSize > 0 || o.Color != "" || o.Difficulty > 0 || o.Komi >= 0 || o.Handicap > 0 || o.Focus
+}
+
+// App is the running program: its pages and the game on the board.
+type App struct {
+	cfg       *config.Config
+	opts      Options
+	newEngine EngineFactory
+
+	tv       *tview.Application
+	pages    *tview.Pages
+	board    *ui.GoBoardUI
+	frame    *tview.Flex
+	hint     *tview.TextView
+	setup    *ui.GameSetupUI
+	history  *ui.HistoryBrowserUI
+	match    *ui.MatchConfirmUI
+	whatsNew *ui.WhatsNewUI
+	help     *ui.HelpUI
+	games    *ui.GameList // the history's games, shared by the pages showing them
+
+	matchReturnPage    string
+	whatsNewReturnPage string
+	reviewReturnPage   string
+	screenColors       int // colors reported by the terminal, captured on draw
+
+	// reviewedGame is the game on the board in review, continued with c.
+	reviewedGame sgf.GameInfo
+
+	// unfinished is the game --continue picks up.
+	unfinished sgf.GameInfo
+}
+
+// New builds the app's pages for cfg. The error is for options that can't
+// be met, such as a --load file that can't be opened; it comes before the
+// terminal is taken over, so it can be printed.
+func New(cfg *config.Config, opts Options, newEngine EngineFactory) (*App, error) {
+	if opts.Load != "" {
+		if _, err := sgf.CheckImport(opts.Load); err != nil {
+			return nil, err
+		}
+	}
+	a := &App{cfg: cfg, opts: opts, newEngine: newEngine, games: ui.NewGameList(config.HistoryDir())}
+	// Deleted games are kept for a while in case they're wanted back
+	sgf.PurgeTrash(config.HistoryDir(), sgf.TrashMaxAge, time.Now())
+	if opts.Continue {
+		game, ok := a.lastUnfinished()
+		if !ok {
+			return nil, fmt.Errorf("no unfinished game to continue")
+		}
+		a.unfinished = game
+	}
+
+	coords.SetRowsFromTop(cfg.RowsFromTop)
+
+	// Start from the chosen preset, in the variant for the terminal's
+	// background, with the colors picked on top
+	background := config.ResolveBackground(cfg.Background, opts.ColorFGBG)
+	cfg.ApplyTheme(background)
+	asciiOnly := cfg.Theme.AsciiOnly
+	ui.SetLightBackground(background == config.BackgroundLight)
+
+	// Honor the NO_COLOR convention (https://no-color.org) before any UI is built
+	if opts.NoColor {
+		cfg.Theme = config.NoColorTheme
+		ui.SetNoColor(true)
+	}
+
+	// Without a UTF-8 locale, draw in ASCII rather than risk garbage. Only
+	// the config's own choice goes in the theme, to be saved with it.
+	cfg.Theme.AsciiOnly = asciiOnly
+	asciiNotice := ""
+	if !asciiOnly && opts.NoUTF8 {
+		asciiOnly = true
+		asciiNotice = "No UTF-8 locale: drawing in ASCII (set LANG, e.g. en_US.UTF-8)"
+	}
+	ui.SetASCIIOnly(asciiOnly)
+	ui.SetMenuGlyphs(cfg.MenuGlyphs)
+
+	a.tv = tview.NewApplication()
+	a.tv.EnableMouse(mouseEnabled)
+	a.installSuspendHandler()
+	a.pages = tview.NewPages()
+	a.pages.SetBorder(true).SetTitle(ui.Glyphs(" ⬡ termsuji "))
+
+	// Draw "f to toggle" on the bottom border when in focus mode
+	a.pages.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		a.screenColors = screen.Colors()
+		if a.board != nil && a.board.IsFocusMode() {
+			title := " f to toggle "
+			titleX := x + (width-len(title))/2
+			titleY := y + height - 1 // bottom border line
+			for i, r := range title {
+				screen.SetContent(titleX+i, titleY, r, nil, tcell.StyleDefault)
+			}
+		}
+		return x, y, width, height
+	})
+
+	// Game view setup - compact horizontal status bar
+	a.hint = tview.NewTextView()
+	a.hint.SetBorder(false)
+	a.hint.SetDynamicColors(true)
+	a.board = ui.NewGoBoard(a.tv, cfg, a.hint)
+
+	// Create game layout with centered board and side panel
+	a.frame = ui.CreateGameLayout(a.board, a.hint)
+	a.board.Box.SetInputCapture(a.boardKey)
+
+	// History browser screen
+	a.history = ui.NewHistoryBrowser(a.games, func() {
+		a.pages.SwitchToPage("setup")
+	}, func(game sgf.GameInfo) {
+		a.reviewGame(game, "history")
+	}, func(game sgf.GameInfo) {
+		a.loadGame(game)
+	}, func(game sgf.GameInfo) {
+		a.rematchGame(game)
+	}, func() {
+		a.showVerifyReport()
+	})
+	a.history.SetApp(a.tv)
+
+	// Game setup screen
+	a.setup = ui.NewGameSetup(
+		func(gameCfg engine.GameConfig) {
+			a.startGame(gameCfg)
+		},
+		func() {
+			a.tv.Stop()
+		},
+		func() {
+			a.pages.SwitchToPage("colors")
+		},
+		func() {
+			a.history.Refresh()
+			a.pages.SwitchToPage("history")
+		},
+	)
+
+	var engineNames []string
+	for _, p := range cfg.EngineProfiles() {
+		engineNames = append(engineNames, p.Name)
+	}
+	a.setup.SetEngines(engineNames)
+	a.setup.SetNotice(asciiNotice)
+	a.setup.SetConfirmMoves(cfg.ConfirmMoves, func(on bool) {
+		cfg.ConfirmMoves = on
+		cfg.Save()
+	})
+	a.setup.SetRecordGames(a.recording(), a.setRecording)
+	a.history.SetPlayerName(cfg.PlayerName)
+	a.setup.SetPlayerName(cfg.PlayerName, func(name string) {
+		cfg.PlayerName = name
+		cfg.Save()
+		a.history.SetPlayerName(name)
+		a.offerLastGame()
+	})
+
+	// Keep the history in the order chosen last
+	a.history.SetSort(sgf.SortKey(config.LoadState().HistorySort), func(key sgf.SortKey) {
+		state := config.LoadState()
+		state.HistorySort = string(key)
+		state.Save()
+	})
+
+	// Reopen the setup card on the tab used last
+	a.setup.SetTab(config.LoadState().SetupTab)
+	a.setup.SetTabFunc(func(tab string) {
+		state := config.LoadState()
+		state.SetupTab = tab
+		state.Save()
+	})
+
+	// Color configuration screen
+	colorConfig := ui.NewColorConfig(cfg, func() {
+		// Refresh the game board with new colors
+		a.board.SetConfig(cfg)
+		a.pages.SwitchToPage("setup")
+	})
+	colorConfig.SetPresetFunc(func() {
+		a.board.SetConfig(cfg)
+	})
+	colorConfig.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || (event.Key() == tcell.KeyRune && event.Rune() == 'q') {
+			a.pages.SwitchToPage("setup")
+			return nil
+		}
+		if event.Key() == tcell.KeyTab {
+			colorConfig.ToggleMode()
+			return nil
+		}
+		return event
+	})
+
+	// Pre-game confirmation card for rematches
+	a.match = ui.NewMatchConfirm(func(gameCfg engine.GameConfig) {
+		a.startGame(gameCfg)
+	}, func() {
+		a.pages.SwitchToPage(a.matchReturnPage)
+	})
+
+	// Add pages - start on setup by default, or gameview if quick start
+	quickStart := opts.quickStart()
+	a.pages.AddPage("setup", a.setup.Form(), true, !quickStart)
+	a.pages.AddPage("gameview", a.frame, true, quickStart)
+	a.pages.AddPage("colors", colorConfig.Flex(), true, false)
+	a.pages.AddPage("history", a.history.Flex(), true, false)
+	a.pages.AddPage("match", a.match.Flex(), true, false)
+
+	// Release notes, shown once after an update
+	a.whatsNew = ui.NewWhatsNew(func(dismiss bool) {
+		if dismiss {
+			state := config.LoadState()
+			state.LastSeenVersion = opts.Version
+			state.Save()
+		}
+		a.pages.SwitchToPage(a.whatsNewReturnPage)
+	})
+	a.pages.AddPage("whatsnew", a.whatsNew.Flex(), true, false)
+
+	// Build info, paths and diagnostics
+	about := ui.NewAbout(func() {
+		a.pages.SwitchToPage("setup")
+	})
+	a.pages.AddPage("about", about.Flex(), true, false)
+	a.setup.SetAboutFunc(func() {
+		about.SetInfo(a.probeAbout())
+		a.pages.SwitchToPage("about")
+	})
+
+	// The key reference, over the setup card or the game
+	a.help = ui.NewHelp(func() {
+		a.pages.HidePage("help")
+	})
+	a.pages.AddPage("help", a.help.Flex(), true, false)
+	a.setup.SetHelpFunc(a.showHelp)
+
+	// The rules of go, opened from the key reference and back to it
+	rules := ui.NewRules(func() {
+		a.pages.HidePage("rules")
+		a.pages.ShowPage("help")
+	})
+	a.pages.AddPage("rules", rules.Flex(), true, false)
+	a.help.SetRulesFunc(func() {
+		rules.Reset()
+		a.pages.HidePage("help")
+		a.pages.ShowPage("rules")
+		a.pages.SendToFront("rules")
+	})
+
+	// A finished game's report, over the board
+	reportView := ui.NewReportViewer(func() {
+		a.pages.HidePage("report")
+	})
+	a.pages.AddPage("report", reportView.Flex(), true, false)
+	a.board.SetReportFunc(func(text string) {
+		reportView.SetReport(text)
+		a.pages.ShowPage("report")
+		a.pages.SendToFront("report")
+	})
+
+	// SGF files from outside the history, e.g. downloads from go servers
+	loadSGF := ui.NewLoadSGF(func(path string, cont, copyToHistory bool) {
+		if err := a.openExternal(path, cont, copyToHistory, "load"); err != nil {
+			a.showError(fmt.Sprintf("Can't load game:\n%s", err.Error()))
+		}
+	}, func() {
+		a.pages.SwitchToPage("setup")
+	})
+	a.pages.AddPage("load", loadSGF.Flex(), true, false)
+	a.setup.SetLoadFunc(func() {
+		loadSGF.Reset()
+		a.pages.SwitchToPage("load")
+	})
+
+	// Offer the last unfinished game, and the newest game for review,
+	// whenever the setup card comes up
+	a.offerContinue()
+	a.offerLastGame()
+	a.pages.SetChangedFunc(func() {
+		if page, _ := a.pages.GetFrontPage(); page == "setup" {
+			a.offerContinue()
+			a.offerLastGame()
+		}
+	})
+
+	// First-run welcome, once and never over a quick start
+	welcome := ui.NewWelcome(func() {
+		dismissWelcome()
+		a.startGame(a.starterConfig())
+	}, func() {
+		dismissWelcome()
+		a.pages.SwitchToPage("setup")
+	})
+	a.pages.AddPage("welcome", welcome.Pages(), true, false)
+
+	return a, nil
+}
+
+// Run starts whatever the options ask for and runs the app until it quits.
+func (a *App) Run() error {
+	a.start()
+	return a.tv.SetRoot(a.pages, true).Run()
+}
+
+// start opens the game the options ask for; without one it greets a new
+// user or shows what's new.
+func (a *App) start() {
+	switch {
+	case a.opts.Continue:
+		a.loadGame(a.unfinished)
+	case a.opts.Load != "":
+		if err := a.openExternal(a.opts.Load, false, false, "setup"); err != nil {
+			a.showError(fmt.Sprintf("Can't load game:\n%s", err.Error()))
+		}
+	case a.opts.quickStart():
+		a.startGame(a.flagGameConfig())
+		// Enter focus mode if requested
+		if a.opts.Focus {
+			a.board.SetFocusMode(true)
+			ui.BuildFocusLayout(a.frame, a.board)
+		}
+	default:
+		if a.opts.FirstRun && !config.LoadState().WelcomeDone {
+			a.pages.SwitchToPage("welcome")
+		}
+		a.checkWhatsNew()
+	}
+}
+
+// offerContinue puts the last unfinished game, if any, on the setup card.
+func (a *App) offerContinue() {
+	game, ok := a.lastUnfinished()
+	if !ok {
+		a.setup.SetContinue("", nil)
+		return
+	}
+	label := fmt.Sprintf("Continue last game (move %d, %dx%d)", game.MoveCount, game.BoardSize, game.BoardSize)
+	a.setup.SetContinue(label, func() {
+		a.loadGame(game)
+	})
+}
+
+// lastUnfinished returns the newest game left in progress, as
+// sgf.LastUnfinished does for the history directory.
+func (a *App) lastUnfinished() (sgf.GameInfo, bool) {
+	games, err := a.games.Games()
+	if err != nil {
+		return sgf.GameInfo{}, false
+	}
+	return sgf.Unfinished(games)
+}
+
+// offerLastGame puts the newest game in the history, if any, under the
+// setup card's buttons, for review with l.
+func (a *App) offerLastGame() {
+	game, ok := a.games.Latest()
+	if !ok {
+		a.setup.SetLastGame("", sgf.OutcomeUnknown, nil)
+		return
+	}
+	a.setup.SetLastGame(ui.LastGameLabel(game, time.Now()), game.OutcomeFor(a.cfg.PlayerName), func() {
+		a.reviewGame(game, "setup")
+	})
+}
+
+// MoveSnapshot adapts the board's move history for the HTTP API.
+func (a *App) MoveSnapshot() []server.Move {
+	history := a.board.MovesSnapshot()
+	moves := make([]server.Move, len(history))
+	for i, m := range history {
+		moves[i] = server.Move{Color: m.Color, X: m.X, Y: m.Y, At: m.At, Think: m.Think}
+	}
+	return moves
+}
+
+// showHelp lays the key reference over the current page, which has the
+// keys again once it closes.
+func (a *App) showHelp() {
+	a.help.Reset()
+	a.pages.ShowPage("help")
+	a.pages.SendToFront("help")
+}
+
+// showError shows msg in a modal over the current page.
+func (a *App) showError(msg string) {
+	modal := tview.NewModal().
+		SetText(msg).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.HidePage("error")
+		})
+	a.pages.AddPage("error", modal, true, true)
+}
+
+// dismissWelcome records that the welcome card has been answered.
+func dismissWelcome() {
+	state := config.LoadState()
+	state.WelcomeDone = true
+	state.Save()
+}
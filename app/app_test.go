@@ -0,0 +1,337 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/config"
+	"termsuji-local/engine"
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+// TestMain keeps the config, state and history in a scratch directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "termsuji-app")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("XDG_STATE_HOME", dir)
+	xdg.Reload()
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// fakeEngine is a GameEngine that accepts every move and replies to
+// nothing, or fails to connect with err. Counting starts when the test
+// calls scoring.
+type fakeEngine struct {
+	cfg     engine.GameConfig
+	state   *types.BoardState
+	err     error
+	scoring func(bs *types.BoardState)
+}
+
+func (f *fakeEngine) Connect() error                                            { return f.err }
+func (f *fakeEngine) GetBoardState() *types.BoardState                          { return f.state }
+func (f *fakeEngine) PlayMove(x, y int) error                                   { return nil }
+func (f *fakeEngine) Pass() error                                               { return nil }
+func (f *fakeEngine) Resign() error                                             { return nil }
+func (f *fakeEngine) LoseOnTime() error                                         { return nil }
+func (f *fakeEngine) IsMyTurn() bool                                            { return true }
+func (f *fakeEngine) GetPlayerColor() int                                       { return f.cfg.PlayerColor }
+func (f *fakeEngine) Undo() error                                               { return nil }
+func (f *fakeEngine) ResetAndReplay(moves [][3]int) error                       { return nil }
+func (f *fakeEngine) OnGameEnd(cb func(outcome string))                         {}
+func (f *fakeEngine) OnProgress(cb func(done, total int, bs *types.BoardState)) {}
+func (f *fakeEngine) Close()                                                    {}
+func (f *fakeEngine) OnMove(cb func(x, y, c int, bs *types.BoardState))         {}
+func (f *fakeEngine) OnScoring(cb func(bs *types.BoardState))                   { f.scoring = cb }
+func (f *fakeEngine) ConfirmScore(dead [][2]int) error                          { return nil }
+func (f *fakeEngine) ResumePlay() error                                         { return nil }
+func (f *fakeEngine) TopMoves() ([]engine.Candidate, error)                     { return nil, nil }
+func (f *fakeEngine) EstimateDead() ([][2]int, error)                           { return nil, nil }
+func (f *fakeEngine) CanEstimate() bool                                         { return false }
+
+// bootApp runs an app with opts on a simulation screen, its games played
+// by fake engines, which are sent down the returned channel as they are
+// made. connectErr, if set, makes them fail to connect.
+func bootApp(t *testing.T, opts Options, connectErr error) (*App, <-chan *fakeEngine) {
+	t.Helper()
+	cfg := config.DefaultConfig
+	cfg.EnableRecording = false
+	engines := make(chan *fakeEngine, 10)
+	a, err := New(&cfg, opts, func(gc engine.GameConfig) engine.GameEngine {
+		eng := &fakeEngine{cfg: gc, state: types.NewBoardState(gc.BoardSize), err: connectErr}
+		engines <- eng
+		return eng
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("screen: %v", err)
+	}
+	screen.SetSize(100, 40)
+	a.tv.SetScreen(screen)
+	done := make(chan error)
+	go func() { done <- a.Run() }()
+	t.Cleanup(func() {
+		a.tv.Stop()
+		<-done
+	})
+	return a, engines
+}
+
+// noQuickStart are options that open on the setup card.
+var noQuickStart = Options{Komi: -1}
+
+// frontPage returns the page on top, read on the app's event loop.
+func frontPage(a *App) string {
+	var name string
+	a.tv.QueueUpdate(func() {
+		name, _ = a.pages.GetFrontPage()
+	})
+	return name
+}
+
+// waitPage waits for want to come to the front.
+func waitPage(t *testing.T, a *App, want string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := frontPage(a)
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("front page %q, want %q", got, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// press sends keys to the app as if typed.
+func press(a *App, keys ...*tcell.EventKey) {
+	for _, k := range keys {
+		a.tv.QueueEvent(k)
+	}
+}
+
+func key(k tcell.Key) *tcell.EventKey { return tcell.NewEventKey(k, 0, tcell.ModNone) }
+func char(r rune) *tcell.EventKey     { return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone) }
+
+func TestPageTransitions(t *testing.T) {
+	a, engines := bootApp(t, noQuickStart, nil)
+	waitPage(t, a, "setup")
+
+	// p plays the card's game; q leaves it
+	press(a, char('p'))
+	waitPage(t, a, "gameview")
+	if eng := <-engines; eng.cfg.BoardSize != 19 || eng.cfg.PlayerColor != 1 {
+		t.Errorf("started %dx%d as color %d", eng.cfg.BoardSize, eng.cfg.BoardSize, eng.cfg.PlayerColor)
+	}
+	press(a, char('q'))
+	waitPage(t, a, "setup")
+
+	// Past board size, color, strength and PLAY to HISTORY
+	press(a, key(tcell.KeyTab), key(tcell.KeyTab), key(tcell.KeyTab), key(tcell.KeyTab), key(tcell.KeyEnter))
+	waitPage(t, a, "history")
+}
+
+func TestQuickStart(t *testing.T) {
+	opts := noQuickStart
+	opts.BoardSize, opts.Color, opts.Handicap = 9, "white", 3
+	a, engines := bootApp(t, opts, nil)
+	waitPage(t, a, "gameview")
+	eng := <-engines
+	if eng.cfg.BoardSize != 9 || eng.cfg.PlayerColor != 2 || eng.cfg.Handicap != 3 {
+		t.Errorf("quick start played %dx%d as color %d with handicap %d", eng.cfg.BoardSize, eng.cfg.BoardSize, eng.cfg.PlayerColor, eng.cfg.Handicap)
+	}
+	if eng.cfg.Komi != engine.CheckSetup(2, 3, config.DefaultConfig.GnuGo.DefaultKomi).SuggestedKomi {
+		t.Errorf("handicap game komi %.1f", eng.cfg.Komi)
+	}
+}
+
+func TestSeedReachesGnuGo(t *testing.T) {
+	opts := noQuickStart
+	opts.Play, opts.Seed = true, 42
+	a, engines := bootApp(t, opts, nil)
+	waitPage(t, a, "gameview")
+	eng := <-engines
+	args := strings.Join(eng.cfg.EngineArgs, " ")
+	if eng.cfg.Seed != 42 || !strings.HasSuffix(args, "--seed 42") {
+		t.Errorf("seed %d, args %q", eng.cfg.Seed, args)
+	}
+}
+
+func TestRulesReachGnuGo(t *testing.T) {
+	opts := noQuickStart
+	opts.Play = true
+	a, engines := bootApp(t, opts, nil)
+	waitPage(t, a, "gameview")
+	eng := <-engines
+	args := strings.Join(eng.cfg.EngineArgs, " ")
+	if want := rules.GnuGoFlag(eng.cfg.Ruleset); !strings.HasSuffix(args, want) {
+		t.Errorf("%q rules, args %q; want %s", eng.cfg.Ruleset, args, want)
+	}
+}
+
+func TestContinueKeepsTheRules(t *testing.T) {
+	a, engines := bootApp(t, noQuickStart, nil)
+	waitPage(t, a, "setup")
+	path := filepath.Join(t.TempDir(), "chinese.sgf")
+	record := "(;GM[1]FF[4]SZ[9]KM[7.5]RU[Chinese]PB[Player]PW[GnuGo Level 5]RE[?];B[ee])"
+	if err := os.WriteFile(path, []byte(record), 0644); err != nil {
+		t.Fatal(err)
+	}
+	game, err := sgf.ParseHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.tv.QueueUpdateDraw(func() { a.loadGame(*game) })
+	eng := <-engines
+	if eng.cfg.Ruleset != "Chinese" || eng.cfg.Komi != 7.5 {
+		t.Errorf("continued with %q rules, komi %.1f; want Chinese and 7.5", eng.cfg.Ruleset, eng.cfg.Komi)
+	}
+}
+
+func TestRematchConfig(t *testing.T) {
+	prev := engine.GameConfig{
+		BoardSize:   13,
+		Komi:        7.5,
+		PlayerColor: 1,
+		EngineLevel: 6,
+		Handicap:    2,
+		Ruleset:     "Chinese",
+		MainTime:    10 * time.Minute,
+	}
+	got := rematchConfig(prev)
+	if got.PlayerColor != 2 {
+		t.Errorf("rematch as color %d, want 2", got.PlayerColor)
+	}
+	if got.BoardSize != 13 || got.Komi != 7.5 || got.EngineLevel != 6 || got.Handicap != 2 || got.MainTime != 10*time.Minute {
+		t.Errorf("rematch %+v doesn't match %+v", got, prev)
+	}
+	if got.Ruleset != "Chinese" {
+		t.Errorf("rematch with %q rules, want Chinese", got.Ruleset)
+	}
+}
+
+func TestStartErrorModal(t *testing.T) {
+	a, _ := bootApp(t, noQuickStart, errors.New("engine missing"))
+	waitPage(t, a, "setup")
+	press(a, char('p'))
+	waitPage(t, a, "error")
+	press(a, key(tcell.KeyEnter))
+	waitPage(t, a, "setup")
+}
+
+func TestContinueWithoutGame(t *testing.T) {
+	cfg := config.DefaultConfig
+	opts := noQuickStart
+	opts.Continue = true
+	if _, err := New(&cfg, opts, nil); err == nil {
+		t.Error("--continue with an empty history should fail")
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	a, _ := bootApp(t, noQuickStart, nil)
+	waitPage(t, a, "setup")
+
+	press(a, char('?'))
+	waitPage(t, a, "help")
+	press(a, char('p')) // swallowed by the overlay
+	press(a, char('?'))
+	waitPage(t, a, "setup")
+
+	// Over the game, and the board has the keys back once it closes
+	press(a, char('p'))
+	waitPage(t, a, "gameview")
+	press(a, char('?'))
+	waitPage(t, a, "help")
+	press(a, key(tcell.KeyEsc))
+	waitPage(t, a, "gameview")
+	press(a, char('q'))
+	waitPage(t, a, "setup")
+}
+
+func TestBranchFromReview(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "game.sgf")
+	game := "(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]RE[B+R];B[ee];W[cc];B[gg];W[cg];B[])"
+	if err := os.WriteFile(src, []byte(game), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := noQuickStart
+	opts.Load = src
+	a, engines := bootApp(t, opts, nil)
+	waitPage(t, a, "gameview")
+
+	// Three moves in, with White to play: GnuGo's turn
+	press(a, char('l'), char('l'), char('l'), char('b'))
+	eng := <-engines
+	if len(eng.cfg.LoadMoves) != 3 || eng.cfg.LoadNextColor != 2 || eng.cfg.PlayerColor != 1 {
+		t.Errorf("branch replays %d moves with %d to play as color %d; want 3, 2, 1",
+			len(eng.cfg.LoadMoves), eng.cfg.LoadNextColor, eng.cfg.PlayerColor)
+	}
+	if filepath.Dir(eng.cfg.LoadSGFPath) != config.HistoryDir() {
+		t.Errorf("branch recorded to %s, want a new game in the history", eng.cfg.LoadSGFPath)
+	}
+	if data, _ := os.ReadFile(src); string(data) != game {
+		t.Errorf("the reviewed game changed:\n%s", data)
+	}
+}
+
+func TestGuessFromReview(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "game.sgf")
+	game := "(;GM[1]FF[4]SZ[9]KM[6.5]RU[Chinese]PB[Player]PW[GnuGo Level 5]RE[B+R];B[ee];W[cc])"
+	if err := os.WriteFile(src, []byte(game), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := noQuickStart
+	opts.Load = src
+	a, engines := bootApp(t, opts, nil)
+	waitPage(t, a, "gameview")
+
+	// The fake engine can't analyse, so the guesses are only scored
+	press(a, char('g'))
+	eng := <-engines
+	if eng.cfg.EngineLevel != analysisLevel || eng.cfg.Ruleset != "Chinese" || eng.cfg.BoardSize != 9 {
+		t.Errorf("analysis engine at level %d, %q rules, size %d", eng.cfg.EngineLevel, eng.cfg.Ruleset, eng.cfg.BoardSize)
+	}
+	press(a, key(tcell.KeyEnter))
+	waitBoard(t, a, "a guess on show", func() bool { return a.board.IsGuessing() && a.board.GuessShown() })
+	press(a, char('g'))
+	waitBoard(t, a, "stepping through the game again", func() bool { return !a.board.IsGuessing() && a.board.IsReviewing() })
+}
+
+// waitBoard waits for ok, read on the app's event loop, to hold.
+func waitBoard(t *testing.T, a *App, what string, ok func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var done bool
+		a.tv.QueueUpdate(func() { done = ok() })
+		if done {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("waited for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,535 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/engine"
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+)
+
+// handicapper is an engine that places its own handicap stones; the
+// record is told where they went.
+type handicapper interface {
+	HandicapStones() [][2]int
+}
+
+// komiNoter is an engine that can say it took other komi than it was
+// given; the player is told.
+type komiNoter interface {
+	KomiNote() string
+}
+
+// startGame starts a game with the given configuration.
+func (a *App) startGame(gameCfg engine.GameConfig) {
+	// End the previous game first. An engine still thinking about its move
+	// is killed after a short grace period rather than waited out.
+	a.board.Close()
+	a.games.Invalidate() // this game's record joins the history
+
+	// Resolve the chosen engine's command line, and the undo allowance
+	profile := a.cfg.EngineProfile(gameCfg.EngineName)
+	gameCfg.EngineName = profile.Name
+	gameCfg.EnginePath, gameCfg.EngineArgs = profile.CommandLine(gameCfg.EngineLevel)
+	gameCfg.MaxUndos = a.cfg.MaxUndosPerGame
+	gameCfg.PlayerName = a.cfg.PlayerName
+	gameCfg.MoveBudget, gameCfg.MinLevel = a.cfg.MoveBudget(), a.cfg.GnuGo.MinLevel
+	if profile.Name == config.GnuGoProfile {
+		gameCfg.EngineArgs = append(gameCfg.EngineArgs, rules.GnuGoFlag(gameCfg.Ruleset))
+	}
+	if a.opts.Seed != 0 && profile.Name == config.GnuGoProfile {
+		// Only GnuGo is known to take a seed
+		gameCfg.Seed = a.opts.Seed
+		gameCfg.EngineArgs = append(gameCfg.EngineArgs, "--seed", strconv.FormatInt(a.opts.Seed, 10))
+	}
+	if a.cfg.QuickOpenings {
+		gameCfg.Replies = engine.OpeningReplies()
+	}
+
+	// Set komi on info panel
+	a.board.SetKomi(gameCfg.Komi)
+
+	if a.opts.Book != "" {
+		book, err := loadBook(a.opts.Book, gameCfg.BoardSize)
+		if err != nil {
+			a.showError(fmt.Sprintf("Failed to start game:\n%s", err.Error()))
+			return
+		}
+		gameCfg.Book = book
+	}
+
+	// Start the game
+	eng := a.newEngine(gameCfg)
+	if err := a.board.ConnectEngine(eng); err != nil {
+		a.showError(fmt.Sprintf("Failed to start game:\n%s", err.Error()))
+		return
+	}
+	if k, ok := eng.(komiNoter); ok && k.KomiNote() != "" {
+		a.board.SetNotice(k.KomiNote())
+	}
+
+	// Set up SGF recording
+	a.board.SetGameConfig(gameCfg)
+	if a.recording() {
+		rec, err := sgf.NewGameRecord(config.HistoryDir(), gameCfg.BoardSize, gameCfg.Komi, gameCfg.PlayerColor, gameCfg.EngineLevel, gameCfg.RecordOptions())
+		if err == nil {
+			rec.Begin()
+			if h, ok := eng.(handicapper); ok {
+				if stones := h.HandicapStones(); len(stones) > 0 {
+					rec.SetHandicap(stones)
+				}
+			}
+			if profile.Name != config.GnuGoProfile {
+				rec.SetOpponent(profile.Name)
+			}
+			if gameCfg.PlayerName != "" {
+				rec.SetPlayerName(gameCfg.PlayerName)
+			}
+			if gameCfg.Seed != 0 {
+				rec.SetSeed(gameCfg.Seed)
+			}
+			rec.Commit()
+			a.board.SetRecorder(rec)
+		}
+	}
+
+	a.pages.SwitchToPage("gameview")
+}
+
+// confirmResign asks before conceding, so a stray keypress doesn't end
+// the game.
+func (a *App) confirmResign() {
+	modal := tview.NewModal().
+		SetText("Resign this game?").
+		AddButtons([]string{"Resign", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.HidePage("resign")
+			if buttonLabel == "Resign" {
+				a.board.Resign()
+			}
+		})
+	modal.SetFocus(1)
+	a.pages.AddPage("resign", modal, true, true)
+}
+
+// loadBook reads the main line of an SGF to use as an opening book.
+func loadBook(path string, boardSize int) (*engine.Book, error) {
+	info, err := sgf.ParseHeader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening book: %w", err)
+	}
+	if info.BoardSize != boardSize {
+		return nil, fmt.Errorf("opening book is %dx%d, game is %dx%d", info.BoardSize, info.BoardSize, boardSize, boardSize)
+	}
+	moves, err := sgf.ParseMovesAsEntries(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening book: %w", err)
+	}
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("opening book %s has no moves", path)
+	}
+	return engine.NewBook(moves), nil
+}
+
+// rematchGame starts a new game set up like a saved one, straight from
+// the history browser. A game against someone other than GnuGo is played
+// at the configured default level.
+func (a *App) rematchGame(game sgf.GameInfo) {
+	setup := sgf.InferSetup(game, a.cfg.PlayerName)
+	gameCfg := engine.GameConfig{
+		BoardSize:   setup.BoardSize,
+		Komi:        setup.Komi,
+		PlayerColor: setup.PlayerColor,
+		EngineLevel: setup.EngineLevel,
+		Handicap:    setup.Handicap,
+		Ruleset:     game.Ruleset,
+	}
+	notice := ""
+	if gameCfg.EngineLevel == 0 {
+		gameCfg.EngineLevel = a.cfg.GnuGo.DefaultLevel
+		notice = fmt.Sprintf("not a game against GnuGo; playing level %d", gameCfg.EngineLevel)
+	}
+	a.startGame(gameCfg)
+	if notice != "" {
+		a.board.SetNotice(notice)
+	}
+}
+
+// reviewGame opens a saved game to step through on the board, going back
+// to returnPage after. No engine is started; GnuGo is only needed to
+// continue the game.
+func (a *App) reviewGame(game sgf.GameInfo, returnPage string) {
+	rep, err := sgf.OpenReplayer(game.FilePath, game.GameIndex)
+	if err != nil {
+		a.showError(fmt.Sprintf("Failed to open game:\n%s", err.Error()))
+		return
+	}
+	a.board.Close()
+	a.board.SetKomi(game.Komi)
+	a.board.StartReview(rep)
+	a.board.SetGameInfo(game)
+	a.reviewedGame = game
+	a.reviewReturnPage = returnPage
+	a.pages.SwitchToPage("gameview")
+}
+
+// analysisLevel is the level GnuGo analyses a reviewed game at: its
+// strongest, as nothing is played against it.
+const analysisLevel = 10
+
+// positionAnalyst is an engine that can rate a position of a saved game.
+type positionAnalyst interface {
+	TopMovesAt(sgfPath string, n int) ([]engine.Candidate, error)
+}
+
+// reviewAnalyst rates the positions of the reviewed game on an engine
+// started for it.
+type reviewAnalyst struct {
+	eng  engine.GameEngine
+	at   positionAnalyst
+	path string
+}
+
+func (r reviewAnalyst) TopMovesAt(n int) ([]engine.Candidate, error) {
+	return r.at.TopMovesAt(r.path, n)
+}
+
+func (r reviewAnalyst) Close() {
+	r.eng.Close()
+}
+
+// startGuessing hides the reviewed game's next moves to be guessed, with
+// GnuGo's choices to compare when it can be started to analyse the game.
+// Without it the guesses are still scored, and the player is told why
+// there is no analysis.
+func (a *App) startGuessing() {
+	game := a.reviewedGame
+	if game.GameIndex > 0 {
+		// GnuGo's loadsgf only reads the first game of a collection
+		a.board.StartGuessing(nil)
+		a.board.SetNotice("no analysis for a collection's later games")
+		return
+	}
+	cfg := engine.GameConfig{
+		BoardSize:   game.BoardSize,
+		Komi:        game.Komi,
+		PlayerColor: 1, // nothing is played, so the engine never has a turn
+		EngineLevel: analysisLevel,
+		Ruleset:     game.Ruleset,
+	}
+	profile := a.cfg.EngineProfile(config.GnuGoProfile)
+	cfg.EngineName = profile.Name
+	cfg.EnginePath, cfg.EngineArgs = profile.CommandLine(analysisLevel)
+	cfg.EngineArgs = append(cfg.EngineArgs, rules.GnuGoFlag(cfg.Ruleset))
+	eng := a.newEngine(cfg)
+	at, ok := eng.(positionAnalyst)
+	if !ok {
+		a.board.StartGuessing(nil)
+		return
+	}
+	if err := eng.Connect(); err != nil {
+		eng.Close()
+		a.board.StartGuessing(nil)
+		a.board.SetNotice("no analysis: " + err.Error())
+		return
+	}
+	a.board.StartGuessing(reviewAnalyst{eng: eng, at: at, path: game.FilePath})
+}
+
+// branchGame plays a saved game differently from the position after its
+// first n moves: the moves up to there are copied to a new record in the
+// history, which is then continued like any other. The saved game is left
+// as it was.
+func (a *App) branchGame(game sgf.GameInfo, n int) {
+	rec, err := sgf.BranchGameRecord(game.FilePath, config.HistoryDir(), n, game.GameIndex)
+	if err != nil {
+		a.showError(fmt.Sprintf("Failed to branch the game:\n%s", err.Error()))
+		return
+	}
+	rec.Close()
+	branch, err := sgf.ParseHeader(rec.FilePath)
+	if err != nil {
+		a.showError(fmt.Sprintf("Failed to branch the game:\n%s", err.Error()))
+		return
+	}
+	a.board.StopReview()
+	a.loadGame(*branch)
+}
+
+// openExternal opens an SGF from anywhere on disk, for review or to play
+// on from its last move, copying it into the history first if asked. The
+// error says why a file can't be used.
+func (a *App) openExternal(path string, cont, copyToHistory bool, returnPage string) error {
+	game, err := sgf.CheckImport(path)
+	if err != nil {
+		return err
+	}
+	if copyToHistory {
+		copied, err := sgf.ImportGame(path, config.HistoryDir(), game.BoardSize)
+		if err != nil {
+			return fmt.Errorf("copy to history: %w", err)
+		}
+		a.games.Invalidate()
+		if game, err = sgf.ParseHeader(copied); err != nil {
+			return err
+		}
+	}
+	if cont {
+		a.loadGame(*game)
+	} else {
+		a.reviewGame(*game, returnPage)
+	}
+	return nil
+}
+
+// continuable reports whether game can be played on, showing why not when
+// it can't: no game of a collection can, as the record written back would
+// lose the others.
+func (a *App) continuable(game sgf.GameInfo) bool {
+	if game.Games <= 1 {
+		return true
+	}
+	a.showError(fmt.Sprintf("Game %d of %s is in a collection of %d; continuing it would leave the file without the others.\nb in review plays on from it as a new game.", game.GameIndex+1, game.FileName, game.Games))
+	return false
+}
+
+// loadGame loads a saved game from history for continued play.
+func (a *App) loadGame(game sgf.GameInfo) {
+	if !a.continuable(game) {
+		return
+	}
+	a.board.Close()
+	a.games.Invalidate() // the game's record changes as it goes on
+	setup := sgf.InferSetup(game, a.cfg.PlayerName)
+	engineLevel := setup.EngineLevel
+	if engineLevel == 0 {
+		engineLevel = 5
+	}
+
+	// Moves are replayed by the engine one at a time so the board can
+	// show progress; without them it falls back to a plain loadsgf.
+	moves, _ := sgf.ParseMovesAsEntries(game.FilePath)
+
+	gameCfg := engine.GameConfig{
+		BoardSize:     game.BoardSize,
+		Komi:          game.Komi,
+		PlayerColor:   setup.PlayerColor,
+		EngineLevel:   engineLevel,
+		EnginePath:    a.cfg.GnuGo.Path,
+		MaxUndos:      a.cfg.MaxUndosPerGame,
+		PlayerName:    a.cfg.PlayerName,
+		LoadSGFPath:   game.FilePath,
+		LoadMoveCount: game.MoveCount,
+		LoadNextColor: game.NextColor,
+		LoadMoves:     moves,
+		Handicap:      game.Handicap,
+		Ruleset:       game.Ruleset,
+		MoveBudget:    a.cfg.MoveBudget(),
+		MinLevel:      a.cfg.GnuGo.MinLevel,
+		Seed:          a.opts.Seed,
+	}
+	if a.cfg.QuickOpenings {
+		gameCfg.Replies = engine.OpeningReplies()
+	}
+
+	a.board.SetKomi(gameCfg.Komi)
+	a.pages.SwitchToPage("gameview")
+
+	// Connect off the UI goroutine: a long game takes a while to replay,
+	// and quitting meanwhile closes the engine, which cancels the load.
+	eng := a.newEngine(gameCfg)
+	go func() {
+		if err := a.board.ConnectEngine(eng); err != nil {
+			if errors.Is(err, engine.ErrClosed) {
+				return
+			}
+			a.tv.QueueUpdateDraw(func() {
+				modal := tview.NewModal().
+					SetText(fmt.Sprintf("Failed to load game:\n%s", err.Error())).
+					AddButtons([]string{"OK"}).
+					SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+						a.pages.HidePage("error")
+						a.pages.SwitchToPage("history")
+					})
+				a.pages.AddPage("error", modal, true, true)
+			})
+			return
+		}
+
+		a.board.SetGameConfig(gameCfg)
+
+		// Rebuild move history from SGF so undo and move list work
+		if moves != nil {
+			a.board.SetMoveHistory(moves)
+			a.board.SetMoveComments(game.MoveComments)
+		}
+
+		note := turnNotice(game)
+		if k, ok := eng.(komiNoter); ok && k.KomiNote() != "" {
+			if note != "" {
+				note += "; "
+			}
+			note += k.KomiNote()
+		}
+		if note != "" {
+			a.tv.QueueUpdateDraw(func() {
+				a.board.SetNotice(note)
+			})
+		}
+
+		// Open existing SGF for continued recording; a file from outside
+		// the history is left as it was
+		if filepath.Dir(game.FilePath) == config.HistoryDir() {
+			if rec, err := sgf.OpenGameRecord(game.FilePath); err == nil {
+				a.board.SetRecorder(rec)
+				if dropped := rec.Dropped(); len(dropped) > 0 {
+					a.tv.QueueUpdateDraw(func() {
+						a.board.SetNotice("lossy save: the record no longer has " + strings.Join(dropped, " or "))
+					})
+				}
+			}
+		}
+		a.tv.QueueUpdateDraw(func() {})
+	}()
+}
+
+// recording reports whether new games are recorded: as the config says,
+// unless --no-record turned it off for the session.
+func (a *App) recording() bool {
+	return a.cfg.EnableRecording && !a.opts.NoRecord
+}
+
+// setRecording makes on the choice for new games, from the setup card or
+// r in a game, and saves it. It outlasts --no-record.
+func (a *App) setRecording(on bool) {
+	a.opts.NoRecord = false
+	if a.cfg.EnableRecording != on {
+		a.cfg.EnableRecording = on
+		a.cfg.Save()
+	}
+	a.setup.ShowRecordGames(on)
+}
+
+// turnNotice warns that a record's moves don't alternate, so the turn was
+// worked out from who actually moved last; "" for a regular record.
+func turnNotice(game sgf.GameInfo) string {
+	if game.OutOfTurn == 0 {
+		return ""
+	}
+	next := "Black"
+	if game.NextColor == 2 {
+		next = "White"
+	}
+	moves := "move"
+	if game.OutOfTurn > 1 {
+		moves = "moves"
+	}
+	return fmt.Sprintf("irregular record: %d %s out of turn; %s to play", game.OutOfTurn, moves, next)
+}
+
+// showMatchConfirm opens the pre-game card for a computed configuration.
+// Cancelling returns to returnPage.
+func (a *App) showMatchConfirm(gameCfg engine.GameConfig, returnPage string) {
+	a.matchReturnPage = returnPage
+	a.match.SetGameConfig(gameCfg)
+	a.pages.SwitchToPage("match")
+}
+
+// rematchConfig derives a new game from a finished one: same board, level,
+// komi, handicap, rules and time controls, with colors swapped.
+func rematchConfig(prev engine.GameConfig) engine.GameConfig {
+	gameCfg := engine.GameConfig{
+		BoardSize:   prev.BoardSize,
+		Komi:        prev.Komi,
+		PlayerColor: 1,
+		EngineLevel: prev.EngineLevel,
+		EnginePath:  prev.EnginePath,
+		EngineName:  prev.EngineName,
+		Handicap:    prev.Handicap,
+		Ruleset:     prev.Ruleset,
+
+		MainTime:      prev.MainTime,
+		ByoYomiTime:   prev.ByoYomiTime,
+		ByoYomiStones: prev.ByoYomiStones,
+	}
+	if prev.PlayerColor == 1 {
+		gameCfg.PlayerColor = 2
+	}
+	return gameCfg
+}
+
+// starterLevel is GnuGo's level for the welcome card's first game.
+const starterLevel = 1
+
+// starterConfig is the welcome card's first game: 9x9 as Black against an
+// easy GnuGo.
+func (a *App) starterConfig() engine.GameConfig {
+	return engine.GameConfig{
+		BoardSize:   9,
+		Komi:        a.cfg.GnuGo.DefaultKomi,
+		PlayerColor: 1,
+		EngineLevel: starterLevel,
+		EnginePath:  a.cfg.GnuGo.Path,
+	}
+}
+
+// flagGameConfig creates the quick start's GameConfig from the options.
+func (a *App) flagGameConfig() engine.GameConfig {
+	opts := a.opts
+
+	// Start with defaults
+	gameCfg := engine.GameConfig{
+		BoardSize:   a.cfg.GnuGo.DefaultBoardSize,
+		Komi:        a.cfg.GnuGo.DefaultKomi,
+		PlayerColor: 1, // Black by default
+		EngineLevel: a.cfg.GnuGo.DefaultLevel,
+		EnginePath:  a.cfg.GnuGo.Path,
+	}
+
+	// Override with flags
+	if opts.BoardSize == 9 || opts.BoardSize == 13 || opts.BoardSize == 19 {
+		gameCfg.BoardSize = opts.BoardSize
+	}
+
+	if opts.Color == "black" || opts.Color == "b" {
+		gameCfg.PlayerColor = 1
+	} else if opts.Color == "white" || opts.Color == "w" {
+		gameCfg.PlayerColor = 2
+	}
+
+	if opts.Difficulty >= 1 && opts.Difficulty <= 10 {
+		gameCfg.EngineLevel = opts.Difficulty
+	}
+
+	if opts.Handicap >= 2 && opts.Handicap <= 9 {
+		gameCfg.Handicap = opts.Handicap
+		// Handicap games use a token komi unless one was given
+		if opts.Komi < 0 {
+			gameCfg.Komi = engine.CheckSetup(gameCfg.PlayerColor, gameCfg.Handicap, gameCfg.Komi).SuggestedKomi
+		}
+	}
+
+	if opts.Komi >= 0 {
+		gameCfg.Komi = opts.Komi
+	}
+
+	return gameCfg
+}
+
+// GnuGoPath resolves the configured GnuGo binary, verifying that it is
+// installed and accessible.
+func GnuGoPath(cfg *config.Config) (string, error) {
+	path := cfg.GnuGo.Path
+	if path == "" {
+		path = "gnugo"
+	}
+	return exec.LookPath(path)
+}
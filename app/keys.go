@@ -0,0 +1,227 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/ui"
+)
+
+// boardKey handles a key on the game board, letting through the keys it
+// has no use for. The key reference lists every key it takes.
+func (a *App) boardKey(event *tcell.EventKey) *tcell.EventKey {
+	// Any key dismisses why the last move was refused
+	a.board.ClearFlash()
+	// The key reference, from anywhere on the board but a comment being typed
+	if event.Key() == tcell.KeyRune && event.Rune() == '?' && !a.board.IsCommenting() {
+		a.showHelp()
+		return nil
+	}
+	// Guessing the next moves of a saved game
+	if a.board.IsGuessing() {
+		switch {
+		case event.Key() == tcell.KeyEnter:
+			if a.board.GuessShown() {
+				a.board.NextGuess()
+			} else if sel := a.board.SelectedTile(); sel != nil {
+				a.board.Guess(sel.X, sel.Y)
+			}
+		case event.Key() == tcell.KeyUp, event.Key() == tcell.KeyRune && event.Rune() == 'k':
+			a.board.MoveSelection(0, -1)
+		case event.Key() == tcell.KeyDown, event.Key() == tcell.KeyRune && event.Rune() == 'j':
+			a.board.MoveSelection(0, 1)
+		case event.Key() == tcell.KeyLeft, event.Key() == tcell.KeyRune && event.Rune() == 'h':
+			a.board.MoveSelection(-1, 0)
+		case event.Key() == tcell.KeyRight, event.Key() == tcell.KeyRune && event.Rune() == 'l':
+			a.board.MoveSelection(1, 0)
+		case event.Key() == tcell.KeyRune && event.Rune() == 'p':
+			a.board.Guess(-1, -1)
+		case event.Key() == tcell.KeyEscape, event.Key() == tcell.KeyRune && event.Rune() == 'g':
+			a.board.StopGuessing()
+		case event.Key() == tcell.KeyRune && event.Rune() == 'q':
+			a.board.StopReview()
+			a.pages.SwitchToPage(a.reviewReturnPage)
+		default:
+			return event
+		}
+		return nil
+	}
+	// Reviewing a saved game: the board only steps through it
+	if a.board.IsReviewing() {
+		switch {
+		case event.Key() == tcell.KeyLeft, event.Key() == tcell.KeyRune && event.Rune() == 'h':
+			a.board.ReviewStep(-1)
+		case event.Key() == tcell.KeyRight, event.Key() == tcell.KeyRune && event.Rune() == 'l':
+			a.board.ReviewStep(1)
+		case event.Key() == tcell.KeyHome:
+			a.board.ReviewGoto(0)
+		case event.Key() == tcell.KeyEnd:
+			a.board.ReviewGoto(len(a.board.MovesSnapshot()))
+		case event.Key() == tcell.KeyRune && event.Rune() == 'y':
+			a.board.CopyMoves(false)
+		case event.Key() == tcell.KeyRune && event.Rune() == 'Y':
+			a.board.CopyMoves(true)
+		case event.Key() == tcell.KeyRune && event.Rune() == 'c':
+			if !a.continuable(a.reviewedGame) {
+				break
+			}
+			a.board.StopReview()
+			a.loadGame(a.reviewedGame)
+		case event.Key() == tcell.KeyRune && event.Rune() == 's':
+			a.board.ScoreReview(a.reviewedGame.Komi, a.reviewedGame.Ruleset)
+		case event.Key() == tcell.KeyRune && event.Rune() == 'g':
+			a.startGuessing()
+		case event.Key() == tcell.KeyRune && event.Rune() == 'b':
+			a.branchGame(a.reviewedGame, a.board.ReviewPosition())
+		case event.Key() == tcell.KeyRune && event.Rune() == 'q':
+			a.board.StopReview()
+			a.pages.SwitchToPage(a.reviewReturnPage)
+		default:
+			return event
+		}
+		return nil
+	}
+	// The comment field takes all typing until it's saved or dropped
+	if a.board.IsCommenting() {
+		a.board.CommentKey(event)
+		return nil
+	}
+	// Digits repeat the movement key after them, as in vi
+	if a.board.CountKey(event) {
+		return nil
+	}
+	count := a.board.TakeCount()
+	if event.Key() == tcell.KeyRune && event.Rune() == 'q' {
+		if a.board.CancelConfirm() {
+			// Only the move waiting to be confirmed; the cursor stays
+		} else if a.board.SelectedTile() != nil {
+			a.board.ResetSelection()
+		} else {
+			a.board.Close()
+			a.pages.SwitchToPage("setup")
+		}
+		return nil
+	}
+	// While counting, Enter marks groups and only movement, focus and
+	// quit keep their usual meaning
+	if a.board.IsScoring() {
+		switch {
+		case event.Key() == tcell.KeyEnter:
+			if sel := a.board.SelectedTile(); sel != nil {
+				a.board.ToggleDead(sel.X, sel.Y)
+			}
+			return nil
+		case event.Key() == tcell.KeyEscape:
+			a.board.ResumePlay()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'c':
+			a.board.ConfirmScore()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'u':
+			a.board.UndoMark()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'U':
+			a.board.RedoMark()
+			return nil
+		case event.Key() == tcell.KeyRune && !strings.ContainsRune("hjklf", event.Rune()):
+			return event
+		}
+	}
+	switch event.Key() {
+	case tcell.KeyUp:
+		a.board.MoveSelectionBy(0, -1, count)
+	case tcell.KeyDown:
+		a.board.MoveSelectionBy(0, 1, count)
+	case tcell.KeyLeft:
+		a.board.MoveSelectionBy(-1, 0, count)
+	case tcell.KeyRight:
+		a.board.MoveSelectionBy(1, 0, count)
+	case tcell.KeyEnter:
+		if a.board.PassPromptActive() {
+			a.board.Pass()
+			return nil
+		}
+		selTile := a.board.SelectedTile()
+		if selTile == nil {
+			return nil
+		}
+		a.board.PlayMove(selTile.X, selTile.Y)
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'h':
+			a.board.MoveSelectionBy(-1, 0, count)
+		case 'j':
+			a.board.MoveSelectionBy(0, 1, count)
+		case 'k':
+			a.board.MoveSelectionBy(0, -1, count)
+		case 'l':
+			a.board.MoveSelectionBy(1, 0, count)
+		case 'p':
+			a.board.Pass()
+		case 'u':
+			a.board.UndoMove()
+		case 'R':
+			if a.board.CanResign() {
+				a.confirmResign()
+			}
+		case 'r':
+			a.board.ToggleRecording(a.cfg)
+			a.setRecording(a.board.IsRecording())
+		case 'f':
+			if a.board.ToggleFocusMode() {
+				ui.BuildFocusLayout(a.frame, a.board)
+			} else {
+				ui.RebuildNormalLayout(a.frame, a.board, a.hint)
+			}
+		case 't':
+			// Territory once a game is counted, the engine's candidates during it
+			if a.board.IsFinished() {
+				a.board.ToggleTerritory()
+			} else {
+				a.board.ToggleAnalysis()
+			}
+		case 'e':
+			a.board.ToggleEstimate()
+		case 'n':
+			if a.board.IsFinished() {
+				a.showMatchConfirm(rematchConfig(a.board.GameConfig()), "gameview")
+			}
+		case 'v':
+			if a.board.IsFinished() {
+				a.board.ShowReport()
+			}
+		case 'y':
+			a.board.CopyMoves(false)
+		case 'Y':
+			a.board.CopyMoves(true)
+		case ';':
+			a.board.StartComment()
+		case 'a':
+			a.board.TogglePlanningMode()
+		case 'A':
+			a.board.ResumeFromPlan()
+		case '[':
+			for i := 0; i < count && a.board.IsPlanningMode(); i++ {
+				a.board.PlanBack()
+			}
+		case ']':
+			for i := 0; i < count && a.board.IsPlanningMode(); i++ {
+				a.board.PlanForward()
+			}
+		case '{':
+			if a.board.IsPlanningMode() {
+				a.board.PlanPrevVariation()
+			}
+		case '}':
+			if a.board.IsPlanningMode() {
+				a.board.PlanNextVariation()
+			}
+		default:
+			return event
+		}
+	default:
+		return event
+	}
+	return nil
+}
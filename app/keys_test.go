@@ -0,0 +1,106 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+	"termsuji-local/ui"
+)
+
+// everyKey is a press of each printable character and of the keys a
+// terminal sends beside them.
+func everyKey() []*tcell.EventKey {
+	var events []*tcell.EventKey
+	for r := ' '; r <= '~'; r++ {
+		events = append(events, char(r))
+	}
+	for k := tcell.KeyCtrlA; k <= tcell.KeyCtrlZ; k++ {
+		events = append(events, key(k))
+	}
+	for _, k := range []tcell.Key{
+		tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight, tcell.KeyEscape, tcell.KeyBacktab,
+		tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyHome, tcell.KeyEnd, tcell.KeyInsert, tcell.KeyDelete,
+		tcell.KeyBackspace2, tcell.KeyF1, tcell.KeyF10,
+	} {
+		events = append(events, key(k))
+	}
+	return events
+}
+
+// listedKeys returns the names of the keys the key reference lists under
+// contexts.
+func listedKeys(contexts ...string) map[string]bool {
+	listed := map[string]bool{}
+	for _, g := range ui.Keymap() {
+		for _, c := range contexts {
+			if g.Context != c {
+				continue
+			}
+			for _, b := range g.Bindings {
+				for _, ev := range b.Events() {
+					listed[ev.Name()] = true
+				}
+			}
+		}
+	}
+	return listed
+}
+
+// Every key the board takes, in each of its modes, is in the key
+// reference.
+func TestBoardKeysListed(t *testing.T) {
+	a, engines := bootApp(t, noQuickStart, nil)
+	waitPage(t, a, "setup")
+	path := filepath.Join(t.TempDir(), "review.sgf")
+	if err := os.WriteFile(path, []byte("(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]RE[B+R])"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	game, err := sgf.ParseHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	play := func() { a.startGame(engine.GameConfig{BoardSize: 9, Komi: 6.5, PlayerColor: 1, EngineLevel: 1}) }
+	review := func() { a.reviewGame(*game, "setup") }
+
+	for _, mode := range []struct {
+		name     string
+		contexts []string // planning and counting keep some of play's keys
+		enter    func()
+	}{
+		{"playing", []string{"Playing", "Planning"}, play},
+		{"planning", []string{"Planning", "Playing"}, func() {
+			play()
+			a.board.TogglePlanningMode()
+		}},
+		{"counting", []string{"Counting", "Playing"}, func() {
+			play()
+			eng := <-engines
+			eng.scoring(eng.state)
+		}},
+		{"reviewing", []string{"Reviewing"}, review},
+		{"guessing", []string{"Guessing"}, func() {
+			review()
+			a.startGuessing()
+		}},
+	} {
+		listed := listedKeys(mode.contexts...)
+		for _, ev := range everyKey() {
+			var taken bool
+			a.tv.QueueUpdate(func() {
+				mode.enter()
+				taken = a.boardKey(ev) == nil
+			})
+			for len(engines) > 0 {
+				<-engines
+			}
+			if taken && !listed[ev.Name()] {
+				t.Errorf("%s: %s does something but isn't in the key reference", mode.name, ev.Name())
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"termsuji-local/config"
+)
+
+// Release is the subset of the GitHub release API response we use.
+type Release struct {
+	TagName string `json:"tag_name"`
+	Body    string `json:"body"`
+}
+
+// LatestRelease fetches the latest release from GitHub.
+func LatestRelease() (*Release, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get("https://api.github.com/repos/JollyGrin/termsuji-local/releases/latest")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("release API: HTTP %d", resp.StatusCode)
+	}
+
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// checkWhatsNew shows the release notes the first time a new version starts.
+// Notes cached by --update are used directly; otherwise they are fetched in
+// the background, and any failure just skips the screen.
+func (a *App) checkWhatsNew() {
+	version := a.opts.Version
+	if version == "dev" || version == "" {
+		return
+	}
+	state := config.LoadState()
+	if state.LastSeenVersion == version {
+		return
+	}
+
+	show := func(body string) {
+		a.whatsNew.SetNotes(version, body)
+		a.whatsNewReturnPage, _ = a.pages.GetFrontPage()
+		a.pages.SwitchToPage("whatsnew")
+	}
+
+	if state.ReleaseNotes.Version == version && state.ReleaseNotes.Body != "" {
+		show(state.ReleaseNotes.Body)
+		return
+	}
+	if state.LastSeenVersion == "" {
+		// Fresh install: nothing is new yet.
+		state.LastSeenVersion = version
+		state.Save()
+		return
+	}
+
+	go func() {
+		rel, err := LatestRelease()
+		if err != nil || rel.TagName != version || strings.TrimSpace(rel.Body) == "" {
+			return
+		}
+		state.ReleaseNotes = config.ReleaseNotes{Version: rel.TagName, Body: rel.Body}
+		state.Save()
+		a.tv.QueueUpdateDraw(func() {
+			// Don't pull the user out of a game that has already started.
+			if page, _ := a.pages.GetFrontPage(); page == "setup" {
+				show(rel.Body)
+			}
+		})
+	}()
+}
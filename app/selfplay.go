@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+)
+
+// naiveName is the player name a record gives the built-in engine.
+const naiveName = "termsuji-local naive"
+
+// SelfPlayOptions describe a game of the built-in engine against itself.
+type SelfPlayOptions struct {
+	BoardSize int
+	Seed      int64
+	Ruleset   string // a rules name, e.g. "Chinese"; unknown names are Japanese
+}
+
+// SelfPlay plays a game of the built-in engine against itself and records
+// it in dir, dated date, returning the record's path. The moves depend
+// only on opts, so two games with the same options and date write the
+// same SGF.
+func SelfPlay(dir string, opts SelfPlayOptions, date time.Time) (string, error) {
+	ruleset := rules.ByName(opts.Ruleset)
+	komi := ruleset.DefaultKomi()
+	moves, score := engine.SelfPlay(opts.BoardSize, opts.Seed, ruleset, komi)
+
+	rec, err := sgf.NewGameRecord(dir, opts.BoardSize, komi, 1, 0, sgf.RecordOptions{Ruleset: ruleset.Name()})
+	if err != nil {
+		return "", err
+	}
+	defer rec.Close()
+	rec.Begin()
+	rec.PlayerBlack, rec.PlayerWhite = naiveName, naiveName
+	rec.Date = date.Format("2006-01-02")
+	rec.SetGameType(sgf.Selfplay)
+	rec.SetSeed(opts.Seed)
+	for _, m := range moves {
+		rec.AddMove(m[1], m[2], m[0])
+	}
+	rec.AddComment(len(moves)-1, sgf.FormatScoreComment(score))
+	rec.SetResult(score.LocalResult())
+	if err := rec.Commit(); err != nil {
+		return "", err
+	}
+	return rec.FilePath, nil
+}
+
+// SelfPlayDeterministic plays the game of opts twice, the first into dir
+// and the second into a scratch directory, and returns an error unless
+// both records are the same. It returns the first record's path.
+func SelfPlayDeterministic(dir string, opts SelfPlayOptions) (string, error) {
+	date := time.Now()
+	path, err := SelfPlay(dir, opts, date)
+	if err != nil {
+		return "", err
+	}
+	scratch, err := os.MkdirTemp("", "termsuji-selfplay-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(scratch)
+	again, err := SelfPlay(scratch, opts, date)
+	if err != nil {
+		return "", err
+	}
+
+	first, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	second, err := os.ReadFile(again)
+	if err != nil {
+		return "", err
+	}
+	if string(first) != string(second) {
+		return path, fmt.Errorf("seed %d played two different games", opts.Seed)
+	}
+	return path, nil
+}
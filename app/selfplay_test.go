@@ -0,0 +1,27 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"termsuji-local/sgf"
+)
+
+func TestSelfPlayDeterministic(t *testing.T) {
+	opts := SelfPlayOptions{BoardSize: 9, Seed: 42, Ruleset: "Chinese"}
+	path, err := SelfPlayDeterministic(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("SelfPlayDeterministic: %v", err)
+	}
+
+	info, err := sgf.ParseHeader(path)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.Seed != 42 || info.GameType != sgf.Selfplay || info.Ruleset != "Chinese" {
+		t.Errorf("header: seed %d, type %q, rules %q", info.Seed, info.GameType, info.Ruleset)
+	}
+	if !strings.HasPrefix(info.Result, "B+") && !strings.HasPrefix(info.Result, "W+") && info.Result != "0" {
+		t.Errorf("result %q, want the count", info.Result)
+	}
+}
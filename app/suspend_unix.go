@@ -0,0 +1,67 @@
+//go:build !windows
+
+package app
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// installSuspendHandler makes ctrl-z and SIGTSTP stop the process with the
+// terminal restored, and repaints the whole screen on SIGCONT. SIGTSTP is
+// sent from outside (e.g. kill -TSTP); in raw mode the terminal doesn't
+// generate it for ctrl-z, which arrives as a key.
+func (a *App) installSuspendHandler() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTSTP)
+	go a.suspendOn(signals)
+
+	a.tv.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyCtrlZ {
+			a.suspend(signals)
+			return nil
+		}
+		return event
+	})
+}
+
+// suspendOn suspends the app for each signal received on signals.
+func (a *App) suspendOn(signals chan os.Signal) {
+	for range signals {
+		a.tv.QueueUpdate(func() { a.suspend(signals) })
+	}
+}
+
+// stopProcess stops the process until it is continued. Tests stand in for
+// it, so as not to stop themselves.
+var stopProcess = func() {
+	syscall.Kill(os.Getpid(), syscall.SIGTSTP)
+}
+
+// suspend hands the terminal back to the shell and stops the process until
+// it is continued; signals, SIGTSTP's channel, is left alone meanwhile. Must
+// be called from the application's event loop.
+func (a *App) suspend(signals chan os.Signal) {
+	a.tv.Suspend(func() {
+		// Let the default action (stop) apply while we signal ourselves.
+		signal.Reset(syscall.SIGTSTP)
+		stopProcess()
+		signal.Notify(signals, syscall.SIGTSTP)
+	})
+	a.resume()
+}
+
+// resume picks up after the process was continued: the time stopped isn't
+// charged to either clock, and the whole screen is repainted, as the
+// terminal may have been drawn over meanwhile. Must be called from the
+// application's event loop.
+func (a *App) resume() {
+	a.board.Resumed()
+	go func() {
+		a.tv.Sync()
+		a.tv.Draw()
+	}()
+}
@@ -0,0 +1,101 @@
+//go:build !windows
+
+package app
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+)
+
+// stubStop stands in for stopping the process, which then stays stopped
+// for d. Each stop is sent down the returned channel.
+func stubStop(t *testing.T, d time.Duration) <-chan struct{} {
+	t.Helper()
+	stopped := make(chan struct{}, 4)
+	saved := stopProcess
+	stopProcess = func() {
+		time.Sleep(d)
+		stopped <- struct{}{}
+	}
+	t.Cleanup(func() { stopProcess = saved })
+	return stopped
+}
+
+// waitStop waits for the process to be stopped.
+func waitStop(t *testing.T, stopped <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("not stopped")
+	}
+}
+
+// drawnText returns the text on the screen once the app next draws.
+func drawnText(a *App) string {
+	text := make(chan string, 1)
+	a.tv.QueueUpdateDraw(func() {
+		a.tv.SetAfterDrawFunc(func(screen tcell.Screen) {
+			a.tv.SetAfterDrawFunc(nil)
+			var b strings.Builder
+			w, h := screen.Size()
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					r, _, _, _ := screen.GetContent(x, y)
+					b.WriteRune(r)
+				}
+				b.WriteByte('\n')
+			}
+			text <- b.String()
+		})
+	})
+	return <-text
+}
+
+func TestSuspend(t *testing.T) {
+	stopped := stubStop(t, 0)
+	a, _ := bootApp(t, noQuickStart, nil)
+	waitPage(t, a, "setup")
+
+	// ctrl-z arrives as a key in raw mode, SIGTSTP from outside
+	press(a, key(tcell.KeyCtrlZ))
+	waitStop(t, stopped)
+	signals := make(chan os.Signal, 1)
+	go a.suspendOn(signals)
+	defer func() {
+		signal.Stop(signals) // suspend had it notified again
+		close(signals)
+	}()
+	signals <- syscall.SIGTSTP
+	waitStop(t, stopped)
+
+	// Continued, the app repaints and carries on where it was
+	if text := drawnText(a); !strings.Contains(text, "p play") {
+		t.Errorf("screen after resuming:\n%s", text)
+	}
+	waitPage(t, a, "setup")
+}
+
+func TestSuspendPausesClocks(t *testing.T) {
+	stopped := stubStop(t, 1500*time.Millisecond)
+	a, _ := bootApp(t, noQuickStart, nil)
+	waitPage(t, a, "setup")
+	a.tv.QueueUpdateDraw(func() {
+		a.startGame(engine.GameConfig{BoardSize: 9, Komi: 6.5, PlayerColor: 1, EngineLevel: 1, MainTime: time.Minute})
+	})
+	waitPage(t, a, "gameview")
+
+	press(a, key(tcell.KeyCtrlZ))
+	waitStop(t, stopped)
+	if text := drawnText(a); !strings.Contains(text, "● 1:00") {
+		t.Errorf("Black's clock charged for the time stopped:\n%s", text)
+	}
+}
@@ -0,0 +1,4 @@
+package app
+
+// installSuspendHandler is a no-op: Windows has no job-control stop signal.
+func (a *App) installSuspendHandler() {}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/sgf"
+)
+
+// showVerifyReport checks the game history and shows the result in a
+// modal over the history browser, offering to repair truncated files.
+func (a *App) showVerifyReport() {
+	reports, err := sgf.VerifyDir(config.HistoryDir())
+	var damaged, repairable []sgf.Report
+	for _, r := range reports {
+		if !r.OK() {
+			damaged = append(damaged, r)
+		}
+		if r.Repairable {
+			repairable = append(repairable, r)
+		}
+	}
+
+	var text string
+	switch {
+	case err != nil:
+		text = fmt.Sprintf("Could not check history:\n%s", err)
+	case len(damaged) == 0:
+		text = fmt.Sprintf("Checked %d games, no problems found.", len(reports))
+	default:
+		var b strings.Builder
+		fmt.Fprintf(&b, "Checked %d games, %d with problems:\n\n", len(reports), len(damaged))
+		const maxShown = 6
+		for i, r := range damaged {
+			if i == maxShown {
+				fmt.Fprintf(&b, "…and %d more (see 'termsuji-local verify')\n", len(damaged)-maxShown)
+				break
+			}
+			fmt.Fprintf(&b, "%s: %s\n", filepath.Base(r.FilePath), r.Problems[0])
+		}
+		text = b.String()
+	}
+
+	buttons := []string{"Close"}
+	if len(repairable) > 0 {
+		buttons = []string{fmt.Sprintf("Repair %d", len(repairable)), "Close"}
+	}
+
+	modal := tview.NewModal().SetText(text).AddButtons(buttons)
+	modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		if buttonLabel == "Close" || buttonIndex < 0 {
+			a.pages.RemovePage("verify")
+			return
+		}
+		repaired, failed := 0, 0
+		for _, r := range repairable {
+			if _, err := sgf.RepairFile(r.FilePath); err != nil {
+				failed++
+			} else {
+				repaired++
+			}
+		}
+		msg := fmt.Sprintf("Wrote %d repaired copies (*%s).\nThe originals were left as they were.", repaired, sgf.RepairedSuffix)
+		if failed > 0 {
+			msg += fmt.Sprintf("\n%d could not be repaired.", failed)
+		}
+		a.games.Invalidate()
+		a.history.Refresh()
+		modal.SetText(msg).ClearButtons().AddButtons([]string{"Close"}).SetFocus(0)
+	})
+	a.pages.AddPage("verify", modal, true, true)
+}
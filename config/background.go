@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Background is the terminal's background shade, which picks the theme
+// variant.
+type Background string
+
+const (
+	BackgroundAuto  Background = "auto" // detect, as "" does
+	BackgroundDark  Background = "dark"
+	BackgroundLight Background = "light"
+)
+
+// ResolveBackground decides the background to theme for. A setting of
+// dark or light is taken as is; otherwise the COLORFGBG value some
+// terminals export is asked, and dark is assumed when it can't tell.
+func ResolveBackground(setting Background, colorfgbg string) Background {
+	switch setting {
+	case BackgroundDark, BackgroundLight:
+		return setting
+	}
+	if bg, ok := DetectBackground(colorfgbg); ok {
+		return bg
+	}
+	return BackgroundDark
+}
+
+// DetectBackground reads a COLORFGBG value such as "15;0" or "0;default;15"
+// (foreground, then background, as ANSI color numbers). White (7) and the
+// bright colors but dark gray (9-15) are light.
+func DetectBackground(colorfgbg string) (Background, bool) {
+	fields := strings.Split(colorfgbg, ";")
+	if len(fields) < 2 {
+		return "", false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(fields[len(fields)-1]))
+	if err != nil || n < 0 || n > 15 {
+		return "", false
+	}
+	if n == 7 || n >= 9 {
+		return BackgroundLight, true
+	}
+	return BackgroundDark, true
+}
+
+// ThemeFor returns the default theme's variant for bg.
+func ThemeFor(bg Background) Theme {
+	if bg == BackgroundLight {
+		return LightTheme
+	}
+	return DefaultTheme
+}
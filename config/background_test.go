@@ -0,0 +1,70 @@
+package config
+
+import "testing"
+
+func TestResolveBackground(t *testing.T) {
+	tests := []struct {
+		setting   Background
+		colorfgbg string
+		want      Background
+	}{
+		{"", "", BackgroundDark},
+		{BackgroundAuto, "15;0", BackgroundDark},
+		{BackgroundAuto, "0;15", BackgroundLight},
+		{"", "0;7", BackgroundLight},
+		{"", "7;8", BackgroundDark},
+		{"", "0;default;15", BackgroundLight},
+		{"", "default;default", BackgroundDark},
+		{"", "0;231", BackgroundDark}, // not an ANSI color, so no guess
+		{"", "15", BackgroundDark},
+		// The config wins over the terminal
+		{BackgroundDark, "0;15", BackgroundDark},
+		{BackgroundLight, "15;0", BackgroundLight},
+		{BackgroundLight, "", BackgroundLight},
+	}
+	for _, tt := range tests {
+		if got := ResolveBackground(tt.setting, tt.colorfgbg); got != tt.want {
+			t.Errorf("ResolveBackground(%q, %q) = %q, want %q", tt.setting, tt.colorfgbg, got, tt.want)
+		}
+	}
+}
+
+func TestThemeFor(t *testing.T) {
+	if ThemeFor(BackgroundDark) != DefaultTheme {
+		t.Error("dark background should get the default theme")
+	}
+	light := ThemeFor(BackgroundLight)
+	if light == DefaultTheme || light.Symbols != DefaultTheme.Symbols || light.UseGridLines != DefaultTheme.UseGridLines {
+		t.Error("light variant should differ from the default only in its colors")
+	}
+}
+
+func TestValidateBackground(t *testing.T) {
+	for _, bg := range []Background{"", BackgroundAuto, BackgroundDark, BackgroundLight} {
+		cfg := DefaultConfig
+		cfg.Background = bg
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("background %q: %v", bg, err)
+		}
+	}
+	cfg := DefaultConfig
+	cfg.Background = "solarized"
+	if cfg.Validate() == nil {
+		t.Error("unknown background accepted")
+	}
+}
+
+func TestValidateMenuGlyphs(t *testing.T) {
+	for _, g := range []MenuGlyphs{"", MenuGlyphsAuto, MenuGlyphsUnicode, MenuGlyphsASCII} {
+		cfg := DefaultConfig
+		cfg.MenuGlyphs = g
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("menu_glyphs %q: %v", g, err)
+		}
+	}
+	cfg := DefaultConfig
+	cfg.MenuGlyphs = "emoji"
+	if cfg.Validate() == nil {
+		t.Error("unknown menu_glyphs accepted")
+	}
+}
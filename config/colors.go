@@ -0,0 +1,191 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RGBFlag marks a ConfigColors value as a 24-bit truecolor one, held in
+// the low bits, rather than an index into the 256-color palette.
+const RGBFlag = 1 << 24
+
+// RGB returns the 0xRRGGBB value of a truecolor ConfigColors value, and
+// false for a palette index.
+func RGB(v int) (int32, bool) {
+	if v&RGBFlag == 0 {
+		return 0, false
+	}
+	return int32(v & 0xffffff), true
+}
+
+// ParseColor reads a color as written in the config: a palette index such
+// as "180", or a hex truecolor such as "#d8b98a".
+func ParseColor(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if hex := strings.TrimPrefix(s, "#"); hex != s {
+		if len(hex) != 6 {
+			return 0, fmt.Errorf("%q is not a #rrggbb color", s)
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a #rrggbb color", s)
+		}
+		return RGBFlag | int(n), nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is neither a palette index nor a #rrggbb color", s)
+	}
+	return n, nil
+}
+
+// FormatColor writes v the way ParseColor reads it.
+func FormatColor(v int) string {
+	if rgb, ok := RGB(v); ok {
+		return fmt.Sprintf("#%06x", rgb)
+	}
+	return strconv.Itoa(v)
+}
+
+// fields pairs each color with its JSON name, in the order they are saved.
+func (c *ConfigColors) fields() []struct {
+	name string
+	v    *int
+} {
+	return []struct {
+		name string
+		v    *int
+	}{
+		{"board", &c.BoardColor},
+		{"board_alt", &c.BoardColorAlt},
+		{"black", &c.BlackColor},
+		{"black_alt", &c.BlackColorAlt},
+		{"white", &c.WhiteColor},
+		{"white_alt", &c.WhiteColorAlt},
+		{"line", &c.LineColor},
+		{"cursor_fg", &c.CursorColorFG},
+		{"cursor_bg", &c.CursorColorBG},
+		{"last_played_bg", &c.LastPlayedColorBG},
+		{"prev_played_bg", &c.PrevPlayedColorBG},
+		{"edge_bg", &c.EdgeColorBG},
+		{"territory_black", &c.TerritoryBlackBG},
+		{"territory_white", &c.TerritoryWhiteBG},
+		{"analysis_bg", &c.AnalysisColorBG},
+	}
+}
+
+// UnmarshalJSON takes each color as a palette index or a "#rrggbb" string.
+// Colors the JSON leaves out keep their value.
+func (c *ConfigColors) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for _, f := range c.fields() {
+		msg, ok := raw[f.name]
+		if !ok {
+			continue
+		}
+		n, err := unmarshalColor(f.name, msg)
+		if err != nil {
+			return err
+		}
+		*f.v = n
+	}
+	return nil
+}
+
+// MarshalJSON writes palette colors as numbers, so configs without
+// truecolor look as they always have, and truecolor ones as "#rrggbb".
+func (c ConfigColors) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range c.fields() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeColor(&buf, f.name, *f.v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unmarshalColor reads the color called name from msg, a number or a string.
+func unmarshalColor(name string, msg json.RawMessage) (int, error) {
+	var n int
+	if err := json.Unmarshal(msg, &n); err == nil {
+		return n, nil
+	}
+	var s string
+	if err := json.Unmarshal(msg, &s); err != nil {
+		return 0, fmt.Errorf("colors.%s: %s is neither a palette index nor a #rrggbb color", name, msg)
+	}
+	n, err := ParseColor(s)
+	if err != nil {
+		return 0, fmt.Errorf("colors.%s: %v", name, err)
+	}
+	return n, nil
+}
+
+// writeColor writes "name":v to buf, v as MarshalJSON writes it.
+func writeColor(buf *bytes.Buffer, name string, v int) {
+	fmt.Fprintf(buf, "%q:", name)
+	if _, ok := RGB(v); ok {
+		fmt.Fprintf(buf, "%q", FormatColor(v))
+	} else {
+		buf.WriteString(strconv.Itoa(v))
+	}
+}
+
+// ColorOverrides are theme colors set on top of a preset, by their names in
+// ConfigColors' JSON, such as "board".
+type ColorOverrides map[string]int
+
+// Apply sets the overridden colors in c. Unknown names are ignored.
+func (o ColorOverrides) Apply(c *ConfigColors) {
+	for _, f := range c.fields() {
+		if v, ok := o[f.name]; ok {
+			*f.v = v
+		}
+	}
+}
+
+// UnmarshalJSON takes colors as ConfigColors does.
+func (o *ColorOverrides) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*o = make(ColorOverrides, len(raw))
+	for name, msg := range raw {
+		n, err := unmarshalColor(name, msg)
+		if err != nil {
+			return err
+		}
+		(*o)[name] = n
+	}
+	return nil
+}
+
+// MarshalJSON writes colors as ConfigColors does, in name order.
+func (o ColorOverrides) MarshalJSON() ([]byte, error) {
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeColor(&buf, name, o[name])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
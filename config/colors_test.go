@@ -0,0 +1,64 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestColorsTakeIntsAndHex(t *testing.T) {
+	c := DefaultTheme.Colors
+	err := json.Unmarshal([]byte(`{"board": "#d8b98a", "line": 94, "black": "#000000"}`), &c)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if rgb, ok := RGB(c.BoardColor); !ok || rgb != 0xd8b98a {
+		t.Errorf("board = %#x, %v; want 0xd8b98a, true", rgb, ok)
+	}
+	if rgb, ok := RGB(c.BlackColor); !ok || rgb != 0 {
+		t.Errorf("black = %#x, %v; want 0, true", rgb, ok)
+	}
+	if c.LineColor != 94 {
+		t.Errorf("line = %d, want 94", c.LineColor)
+	}
+	// Left out, so still the default
+	if c.WhiteColor != DefaultTheme.Colors.WhiteColor {
+		t.Errorf("white = %d, want the default %d", c.WhiteColor, DefaultTheme.Colors.WhiteColor)
+	}
+
+	out, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	for _, want := range []string{`"board":"#d8b98a"`, `"line":94`, `"black":"#000000"`, `"white":255`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Marshal = %s, missing %s", out, want)
+		}
+	}
+	var back ConfigColors
+	if err := json.Unmarshal(out, &back); err != nil || back != c {
+		t.Errorf("round trip = %+v, %v; want %+v", back, err, c)
+	}
+}
+
+func TestIntColorsSaveUnchanged(t *testing.T) {
+	out, err := json.Marshal(DefaultTheme.Colors)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	// What the struct tags alone used to write
+	type plain ConfigColors
+	want, _ := json.Marshal(plain(DefaultTheme.Colors))
+	if string(out) != string(want) {
+		t.Errorf("Marshal = %s\nwant %s", out, want)
+	}
+}
+
+func TestColorsRejectBadValues(t *testing.T) {
+	for _, in := range []string{`{"board": "#d8b98"}`, `{"board": "tan"}`, `{"line": true}`} {
+		var c ConfigColors
+		if err := json.Unmarshal([]byte(in), &c); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want an error", in)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/adrg/xdg"
 )
@@ -22,6 +23,8 @@ func (e *InvalidConfig) Error() string {
 	return fmt.Sprintf("Config error: %s", e.err)
 }
 
+// ConfigColors are the board's colors. Each is an index into the
+// 256-color palette or, written as "#rrggbb", a truecolor; see RGB.
 type ConfigColors struct {
 	BoardColor        int `json:"board"`
 	BoardColorAlt     int `json:"board_alt"`
@@ -33,6 +36,11 @@ type ConfigColors struct {
 	CursorColorFG     int `json:"cursor_fg"`
 	CursorColorBG     int `json:"cursor_bg"`
 	LastPlayedColorBG int `json:"last_played_bg"`
+	PrevPlayedColorBG int `json:"prev_played_bg"`
+	EdgeColorBG       int `json:"edge_bg"`
+	TerritoryBlackBG  int `json:"territory_black"`
+	TerritoryWhiteBG  int `json:"territory_white"`
+	AnalysisColorBG   int `json:"analysis_bg"`
 }
 
 type ConfigSymbols struct {
@@ -49,6 +57,8 @@ type Theme struct {
 	DrawLastPlayedBackground bool          `json:"draw_last_played_bg"`
 	FullWidthLetters         bool          `json:"fullwidth_letters"`
 	UseGridLines             bool          `json:"use_grid_lines"`
+	NoColor                  bool          `json:"no_color"`
+	AsciiOnly                bool          `json:"ascii_only"` // draw the board and menus with ASCII alone
 	Colors                   ConfigColors  `json:"colors"`
 	Symbols                  ConfigSymbols `json:"symbols"`
 }
@@ -59,12 +69,33 @@ type GnuGoConfig struct {
 	DefaultBoardSize int     `json:"default_board_size"`
 	DefaultKomi      float64 `json:"default_komi"`
 	DefaultLevel     int     `json:"default_level"`
+
+	// Thinking budget: seconds a move may take before the level is
+	// stepped down, no lower than MinLevel; 0 leaves the level alone
+	MoveBudget int `json:"move_budget"`
+	MinLevel   int `json:"min_level"`
 }
 
 type Config struct {
-	Theme           Theme       `json:"theme"`
-	GnuGo           GnuGoConfig `json:"gnugo"`
-	EnableRecording bool        `json:"enable_recording"`
+	Theme           Theme          `json:"theme"`
+	ThemePreset     string         `json:"theme_preset"`           // named theme the board starts from; see ThemePresets
+	ThemeColors     ColorOverrides `json:"theme_colors,omitempty"` // colors set on top of the preset
+	GnuGo           GnuGoConfig    `json:"gnugo"`
+	EnableRecording bool           `json:"enable_recording"`
+	MaxUndosPerGame int            `json:"max_undos_per_game"`  // 0 = unlimited
+	PassAssist      bool           `json:"pass_assist"`         // suggest passing once the board looks settled
+	HighlightPrev   bool           `json:"highlight_prev_move"` // also mark the move before the last one
+	RowsFromTop     bool           `json:"rows_from_top"`       // number board rows from the top in labels and move lists
+	BeginnerHints   bool           `json:"beginner_hints"`      // tint the edge and nudge against low moves in the opening
+	QuickOpenings   bool           `json:"quick_openings"`      // answer standard openings from the built-in book instead of asking GnuGo
+	ConfirmMoves    bool           `json:"confirm_moves"`       // play a move only on a second Enter, showing it as a ghost stone first
+	Background      Background     `json:"background"`          // light, dark, or auto to go by the terminal
+	PlayerName      string         `json:"player_name"`         // your name in game records; "" for "Player"
+	MenuGlyphs      MenuGlyphs     `json:"menu_glyphs"`         // unicode, ascii, or auto to follow the board
+	WriteReports    bool           `json:"write_reports"`       // write a plain-text report beside each recorded game and open it when the game ends
+
+	// GTP engines to offer besides GnuGo
+	Engines []EngineProfile `json:"engines,omitempty"`
 }
 
 // HistoryDir returns the path for storing SGF game history files.
@@ -72,13 +103,27 @@ func HistoryDir() string {
 	return filepath.Join(xdg.ConfigHome, "termsuji-local", "history")
 }
 
+// ExportDir returns where games exported from the history go: the user's
+// download directory.
+func ExportDir() string {
+	return xdg.UserDirs.Download
+}
+
+// ConfigPath returns the path of the config file and whether it exists.
+// A missing file is where Save would create it.
+func ConfigPath() (string, bool) {
+	if absPath, err := xdg.SearchConfigFile(cfgFile); err == nil {
+		return absPath, true
+	}
+	return filepath.Join(xdg.ConfigHome, cfgFile), false
+}
+
 func InitConfig() (*Config, error) {
 	config := DefaultConfig
-	absPath, err := xdg.SearchConfigFile(cfgFile)
-	if err == nil {
+	if absPath, ok := ConfigPath(); ok {
 		readCfgFile(absPath, &config)
 	}
-	if err = config.Validate(); err != nil {
+	if err := config.Validate(); err != nil {
 		return nil, err
 	}
 	return &config, nil
@@ -90,9 +135,38 @@ func (c *Config) Validate() error {
 			return &InvalidConfig{"Unicode characters 1-31 and 127-159 are not allowed"}
 		}
 	}
+	switch c.Background {
+	case "", BackgroundAuto, BackgroundDark, BackgroundLight:
+	default:
+		return &InvalidConfig{fmt.Sprintf("background must be light, dark or auto, not %q", c.Background)}
+	}
+	switch c.MenuGlyphs {
+	case "", MenuGlyphsAuto, MenuGlyphsUnicode, MenuGlyphsASCII:
+	default:
+		return &InvalidConfig{fmt.Sprintf("menu_glyphs must be unicode, ascii or auto, not %q", c.MenuGlyphs)}
+	}
+	if _, ok := FindPreset(c.ThemePreset); !ok {
+		return &InvalidConfig{fmt.Sprintf("theme_preset must be one of %s, not %q", presetNames(), c.ThemePreset)}
+	}
+	if c.GnuGo.MoveBudget < 0 {
+		return &InvalidConfig{"move_budget can't be negative"}
+	}
+	if c.GnuGo.MinLevel < 0 || c.GnuGo.MinLevel > 10 {
+		return &InvalidConfig{"min_level must be between 1 and 10"}
+	}
+	for i, p := range c.Engines {
+		if p.Name == "" || p.Command == "" {
+			return &InvalidConfig{fmt.Sprintf("engine %d needs a name and a command", i+1)}
+		}
+	}
 	return nil
 }
 
+// MoveBudget is the thinking budget as a duration, 0 when there is none.
+func (c *Config) MoveBudget() time.Duration {
+	return time.Duration(c.GnuGo.MoveBudget) * time.Second
+}
+
 func (c *Config) Save() {
 	absPath, err := xdg.ConfigFile(cfgFile)
 	if err != nil {
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigWithoutEnableRecording(t *testing.T) {
+	// A config from before enable_recording was written out
+	path := filepath.Join(t.TempDir(), "config.json")
+	old := `{"gnugo": {"gnugo_path": "/usr/games/gnugo", "default_level": 3}, "pass_assist": true}`
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig
+	readCfgFile(path, &cfg)
+	if !cfg.EnableRecording {
+		t.Error("recording off for a config that never mentioned it")
+	}
+	if cfg.GnuGo.DefaultLevel != 3 || !cfg.PassAssist {
+		t.Errorf("old settings not loaded: level %d, pass assist %v", cfg.GnuGo.DefaultLevel, cfg.PassAssist)
+	}
+}
+
+func TestLoadConfigRecordingOff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"enable_recording": false}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig
+	readCfgFile(path, &cfg)
+	if cfg.EnableRecording {
+		t.Error("enable_recording false ignored")
+	}
+}
@@ -2,6 +2,20 @@ package config
 
 var DefaultConfig Config
 var DefaultTheme Theme
+var LightTheme Theme
+var NoColorTheme Theme
+
+// ThemePresets are the named themes offered on the colors screen.
+var ThemePresets []ThemePreset
+
+// ASCIISymbols stand in for a theme's symbols when it is ASCII-only
+var ASCIISymbols = ConfigSymbols{
+	BlackStone:  '#',
+	WhiteStone:  'O',
+	BoardSquare: '+',
+	Cursor:      '+',
+	LastPlayed:  '+',
+}
 
 func init() {
 	// Minimalist Zen theme - warm wood tones with subtle accents
@@ -22,6 +36,11 @@ func init() {
 			CursorColorFG:     30,  // Teal accent
 			CursorColorBG:     30,  // Teal cursor highlight
 			LastPlayedColorBG: 65,  // Soft green for last move
+			PrevPlayedColorBG: 108, // Paler green for the move before it
+			EdgeColorBG:       174, // Faintly redder wood for beginner edge hints
+			TerritoryBlackBG:  102, // Grey-brown over Black's area after counting
+			TerritoryWhiteBG:  230, // Cream over White's
+			AnalysisColorBG:   208, // Orange for the engine's best move, fading for the rest
 		},
 		Symbols: ConfigSymbols{
 			BlackStone:  '●',
@@ -32,14 +51,117 @@ func init() {
 		},
 	}
 
+	// The same for light terminals: a deeper board and darker lines and
+	// highlights, so they hold up against a white screen
+	LightTheme = DefaultTheme
+	LightTheme.Colors.BoardColor = 179
+	LightTheme.Colors.BoardColorAlt = 179
+	LightTheme.Colors.LineColor = 94
+	LightTheme.Colors.LastPlayedColorBG = 29
+	LightTheme.Colors.PrevPlayedColorBG = 72
+	LightTheme.Colors.TerritoryWhiteBG = 223
+
+	// Symbols-only theme for NO_COLOR / --no-color: terminal default colors,
+	// stones told apart by glyph, cursor drawn in reverse video
+	NoColorTheme = DefaultTheme
+	NoColorTheme.DrawCursorBackground = false
+	NoColorTheme.DrawLastPlayedBackground = false
+	NoColorTheme.NoColor = true
+	NoColorTheme.Symbols.WhiteStone = '○'
+
+	// The original termsuji look: no grid, each point a filled cell in a
+	// checkered board, stones as solid blocks of color
+	classic := Theme{
+		DrawStoneBackground:      true,
+		DrawCursorBackground:     true,
+		DrawLastPlayedBackground: true,
+		Colors: ConfigColors{
+			BoardColor:        180,
+			BoardColorAlt:     179,
+			BlackColor:        233,
+			BlackColorAlt:     234,
+			WhiteColor:        252,
+			WhiteColorAlt:     253,
+			LineColor:         137,
+			CursorColorFG:     30,
+			CursorColorBG:     30,
+			LastPlayedColorBG: 65,
+			PrevPlayedColorBG: 108,
+			EdgeColorBG:       174,
+			TerritoryBlackBG:  102,
+			TerritoryWhiteBG:  230,
+			AnalysisColorBG:   208,
+		},
+		Symbols: ConfigSymbols{
+			BlackStone:  ' ',
+			WhiteStone:  ' ',
+			BoardSquare: ' ',
+			Cursor:      ' ',
+			LastPlayed:  ' ',
+		},
+	}
+
+	// Grid lines on a slate board, easy on the eyes at night
+	dark := DefaultTheme
+	dark.Colors = ConfigColors{
+		BoardColor:        237,
+		BoardColorAlt:     237,
+		BlackColor:        16,
+		BlackColorAlt:     16,
+		WhiteColor:        253,
+		WhiteColorAlt:     253,
+		LineColor:         243,
+		CursorColorFG:     67,
+		CursorColorBG:     67,
+		LastPlayedColorBG: 24,
+		PrevPlayedColorBG: 60,
+		EdgeColorBG:       239,
+		TerritoryBlackBG:  234,
+		TerritoryWhiteBG:  246,
+		AnalysisColorBG:   130,
+	}
+
+	// Black on white, White stones drawn hollow, bright highlights
+	highContrast := DefaultTheme
+	highContrast.Colors = ConfigColors{
+		BoardColor:        231,
+		BoardColorAlt:     231,
+		BlackColor:        16,
+		BlackColorAlt:     16,
+		WhiteColor:        16,
+		WhiteColorAlt:     16,
+		LineColor:         16,
+		CursorColorFG:     33,
+		CursorColorBG:     33,
+		LastPlayedColorBG: 214,
+		PrevPlayedColorBG: 222,
+		EdgeColorBG:       189,
+		TerritoryBlackBG:  246,
+		TerritoryWhiteBG:  255,
+		AnalysisColorBG:   196,
+	}
+	highContrast.Symbols.WhiteStone = '○'
+
+	// Only zen has a separate light variant; the others bring their own
+	// board, which reads the same on either background
+	ThemePresets = []ThemePreset{
+		{Name: DefaultPreset, Description: "Grid lines on warm wood", Dark: DefaultTheme, Light: LightTheme},
+		{Name: "classic", Description: "Filled checkered board, no grid", Dark: classic, Light: classic},
+		{Name: "dark", Description: "Grid lines on a slate board", Dark: dark, Light: dark},
+		{Name: "high-contrast", Description: "Black on white, hollow White stones", Dark: highContrast, Light: highContrast},
+	}
+
 	DefaultConfig = Config{
-		Theme: DefaultTheme,
+		Theme:       DefaultTheme,
+		ThemePreset: DefaultPreset,
 		GnuGo: GnuGoConfig{
 			Path:             "gnugo",
 			DefaultBoardSize: 19,
 			DefaultKomi:      6.5,
 			DefaultLevel:     5,
+			MinLevel:         1,
 		},
 		EnableRecording: true,
+		HighlightPrev:   true,
 	}
 }
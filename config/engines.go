@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GnuGoProfile is the name of the built-in profile that runs GnuGo from
+// the gnugo section of the config.
+const GnuGoProfile = "GnuGo"
+
+// levelPlaceholder in an engine's args is replaced by the level chosen on
+// the setup card.
+const levelPlaceholder = "{level}"
+
+// EngineProfile describes a GTP engine to play against, such as KataGo,
+// Pachi or Leela Zero.
+type EngineProfile struct {
+	Name          string   `json:"name"`
+	Command       string   `json:"command"`
+	Args          []string `json:"args"`           // may contain {level}
+	SupportsLevel bool     `json:"supports_level"` // the setup card's strength setting applies
+}
+
+// CommandLine returns the command and arguments that start the engine at
+// level. Without level support, args containing {level} are kept out.
+func (p EngineProfile) CommandLine(level int) (string, []string) {
+	args := make([]string, 0, len(p.Args))
+	for _, a := range p.Args {
+		if strings.Contains(a, levelPlaceholder) {
+			if !p.SupportsLevel {
+				continue
+			}
+			a = strings.ReplaceAll(a, levelPlaceholder, strconv.Itoa(level))
+		}
+		args = append(args, a)
+	}
+	return p.Command, args
+}
+
+// EngineProfiles returns the engines offered on the setup card: GnuGo
+// first, then those configured under "engines". A configured profile named
+// like GnuGo's replaces it.
+func (c *Config) EngineProfiles() []EngineProfile {
+	gnugo := EngineProfile{
+		Name:          GnuGoProfile,
+		Command:       c.GnuGo.Path,
+		Args:          []string{"--mode", "gtp", "--level", levelPlaceholder, "--quiet"},
+		SupportsLevel: true,
+	}
+	if gnugo.Command == "" {
+		gnugo.Command = "gnugo"
+	}
+	profiles := []EngineProfile{gnugo}
+	for _, p := range c.Engines {
+		if p.Name == GnuGoProfile {
+			profiles[0] = p
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles
+}
+
+// EngineProfile returns the profile called name, or GnuGo's when there is
+// none.
+func (c *Config) EngineProfile(name string) EngineProfile {
+	profiles := c.EngineProfiles()
+	for _, p := range profiles {
+		if p.Name == name {
+			return p
+		}
+	}
+	return profiles[0]
+}
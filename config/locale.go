@@ -0,0 +1,17 @@
+package config
+
+import "strings"
+
+// UTF8Locale reports whether the locale the environment names uses UTF-8,
+// given LC_ALL, LC_CTYPE and LANG. The first that is set decides, as it
+// does for the C library; with none set the locale is "C", which doesn't.
+func UTF8Locale(lcAll, lcCtype, lang string) bool {
+	for _, v := range []string{lcAll, lcCtype, lang} {
+		if v == "" {
+			continue
+		}
+		v = strings.ToLower(v)
+		return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestUTF8Locale(t *testing.T) {
+	tests := []struct {
+		lcAll, lcCtype, lang string
+		want                 bool
+	}{
+		{"", "", "en_US.UTF-8", true},
+		{"", "", "de_DE.utf8", true},
+		{"", "", "", false},
+		{"", "", "C", false},
+		{"", "", "en_US.ISO-8859-1", false},
+		{"C", "", "en_US.UTF-8", false}, // LC_ALL overrides LANG
+		{"", "en_US.UTF-8", "C", true},
+		{"C.UTF-8", "POSIX", "", true},
+	}
+	for _, tt := range tests {
+		if got := UTF8Locale(tt.lcAll, tt.lcCtype, tt.lang); got != tt.want {
+			t.Errorf("UTF8Locale(%q, %q, %q) = %v, want %v", tt.lcAll, tt.lcCtype, tt.lang, got, tt.want)
+		}
+	}
+}
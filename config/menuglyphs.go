@@ -0,0 +1,11 @@
+package config
+
+// MenuGlyphs picks how the menus draw their decorative glyphs (⬡ ◈ ▸ ╭),
+// for fonts that have box drawing for the board but not those.
+type MenuGlyphs string
+
+const (
+	MenuGlyphsAuto    MenuGlyphs = "auto"    // as the board: ASCII with ascii_only or without a UTF-8 locale, as "" does
+	MenuGlyphsUnicode MenuGlyphs = "unicode" // always the glyphs
+	MenuGlyphsASCII   MenuGlyphs = "ascii"   // always ASCII stand-ins
+)
@@ -0,0 +1,69 @@
+package config
+
+import "strings"
+
+// DefaultPreset is the theme preset used when the config names none.
+const DefaultPreset = "zen"
+
+// ThemePreset is a named theme to start from, with a variant for light
+// terminal backgrounds.
+type ThemePreset struct {
+	Name        string
+	Description string
+	Dark        Theme
+	Light       Theme
+}
+
+// For returns the preset's variant for bg.
+func (p ThemePreset) For(bg Background) Theme {
+	if bg == BackgroundLight {
+		return p.Light
+	}
+	return p.Dark
+}
+
+// FindPreset looks up a preset by name; "" is DefaultPreset.
+func FindPreset(name string) (ThemePreset, bool) {
+	if name == "" {
+		name = DefaultPreset
+	}
+	for _, p := range ThemePresets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return ThemePreset{}, false
+}
+
+// presetNames lists the presets for error messages.
+func presetNames() string {
+	names := make([]string, len(ThemePresets))
+	for i, p := range ThemePresets {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// ApplyTheme sets the theme to the chosen preset's variant for bg, with the
+// config's color overrides on top. AsciiOnly is the config's own choice and
+// is kept.
+func (c *Config) ApplyTheme(bg Background) {
+	p, ok := FindPreset(c.ThemePreset)
+	if !ok {
+		p, _ = FindPreset(DefaultPreset)
+	}
+	asciiOnly := c.Theme.AsciiOnly
+	c.Theme = p.For(bg)
+	c.Theme.AsciiOnly = asciiOnly
+	c.ThemeColors.Apply(&c.Theme.Colors)
+}
+
+// SetThemeColor overrides the named theme color, such as "board", both in
+// the theme in use and in the overrides saved with the config.
+func (c *Config) SetThemeColor(name string, v int) {
+	if c.ThemeColors == nil {
+		c.ThemeColors = ColorOverrides{}
+	}
+	c.ThemeColors[name] = v
+	ColorOverrides{name: v}.Apply(&c.Theme.Colors)
+}
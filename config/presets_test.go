@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyTheme(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.ThemePreset = "classic"
+	cfg.Theme.AsciiOnly = true
+	cfg.ThemeColors = ColorOverrides{"board": RGBFlag | 0xd8b98a}
+	cfg.ApplyTheme(BackgroundDark)
+
+	if !cfg.Theme.DrawStoneBackground || cfg.Theme.UseGridLines {
+		t.Error("classic preset not applied")
+	}
+	if cfg.Theme.Colors.BoardColor != RGBFlag|0xd8b98a {
+		t.Errorf("board = %s, want the override #d8b98a", FormatColor(cfg.Theme.Colors.BoardColor))
+	}
+	if cfg.Theme.Colors.BoardColorAlt != 179 {
+		t.Errorf("board_alt = %d, want the preset's 179", cfg.Theme.Colors.BoardColorAlt)
+	}
+	if !cfg.Theme.AsciiOnly {
+		t.Error("ascii_only lost")
+	}
+
+	// The zen preset has its own light variant
+	cfg = DefaultConfig
+	cfg.ApplyTheme(BackgroundLight)
+	if cfg.Theme != LightTheme {
+		t.Error("zen on a light background should be the light theme")
+	}
+}
+
+func TestThemeChoiceSurvivesSave(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.ThemePreset = "dark"
+	cfg.SetThemeColor("line", 94)
+	data, err := json.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	loaded := DefaultConfig
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	loaded.ApplyTheme(BackgroundDark)
+	if loaded.ThemePreset != "dark" || loaded.Theme.Colors.LineColor != 94 {
+		t.Errorf("loaded preset %q, line %d; want dark, 94", loaded.ThemePreset, loaded.Theme.Colors.LineColor)
+	}
+	p, _ := FindPreset("dark")
+	if loaded.Theme.Colors.BoardColor != p.Dark.Colors.BoardColor {
+		t.Errorf("board = %d, want the dark preset's %d", loaded.Theme.Colors.BoardColor, p.Dark.Colors.BoardColor)
+	}
+}
+
+func TestValidatePreset(t *testing.T) {
+	for _, p := range ThemePresets {
+		cfg := DefaultConfig
+		cfg.ThemePreset = p.Name
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("preset %q: %v", p.Name, err)
+		}
+	}
+	cfg := DefaultConfig
+	cfg.ThemePreset = "solarized"
+	if cfg.Validate() == nil {
+		t.Error("unknown preset accepted")
+	}
+}
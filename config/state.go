@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+)
+
+var stateFile = "termsuji-local/state.json"
+
+// State holds small bits of app state that aren't user preferences.
+type State struct {
+	LastSeenVersion string       `json:"last_seen_version"`
+	ReleaseNotes    ReleaseNotes `json:"release_notes"`
+	SetupTab        string       `json:"setup_tab"`    // last tab used on the setup card
+	HistorySort     string       `json:"history_sort"` // order of the history list, as sgf.SortKey
+	// WelcomeDone is set once the first-run welcome card has been answered
+	WelcomeDone bool `json:"welcome_done"`
+}
+
+// ReleaseNotes caches the notes of a release so they can be shown offline
+// the first time that version starts.
+type ReleaseNotes struct {
+	Version string `json:"version"`
+	Body    string `json:"body"`
+}
+
+// StatePath returns the path of the state file and whether it exists.
+func StatePath() (string, bool) {
+	if absPath, err := xdg.SearchStateFile(stateFile); err == nil {
+		return absPath, true
+	}
+	return filepath.Join(xdg.StateHome, stateFile), false
+}
+
+// LoadState reads the state file. A missing or unreadable file yields an
+// empty state.
+func LoadState() *State {
+	state := &State{}
+	absPath, ok := StatePath()
+	if !ok {
+		return state
+	}
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return &State{}
+	}
+	return state
+}
+
+// Save writes the state file, creating its directory if needed.
+func (s *State) Save() error {
+	absPath, err := xdg.StateFile(stateFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(absPath, data, 0644)
+}
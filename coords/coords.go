@@ -0,0 +1,115 @@
+// Package coords converts between termsuji board coordinates and the
+// vertex notation used by GTP and for display.
+//
+// Board coordinates are 0-indexed from the top-left: x runs left to right,
+// y top to bottom, and (-1, -1) is a pass. Vertices use columns A-T
+// (skipping I) and rows numbered from the bottom, so on a 19x19 board
+// (3, 15) is "D4" and (15, 3) is "Q16".
+package coords
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ToGTP converts board coordinates to a GTP vertex.
+// For a 19x19 board: (0, 18) -> A1, (3, 15) -> D4, (15, 3) -> Q16
+func ToGTP(x, y, size int) string {
+	// Column: A-T, skipping I
+	col := 'A' + rune(x)
+	if x >= 8 {
+		col++ // Skip 'I'
+	}
+
+	// Row: 1-19 from bottom, so invert Y
+	row := size - y
+
+	return fmt.Sprintf("%c%d", col, row)
+}
+
+// FromGTP converts a GTP vertex to board coordinates.
+// For a 19x19 board: A1 -> (0, 18), D4 -> (3, 15), Q16 -> (15, 3)
+// Returns (-1, -1) for "pass" and (-2, -2) for "resign".
+func FromGTP(vertex string, size int) (int, int, error) {
+	vertex = strings.TrimSpace(strings.ToUpper(vertex))
+
+	// Handle pass
+	if vertex == "PASS" {
+		return -1, -1, nil
+	}
+
+	// Handle resign
+	if vertex == "RESIGN" {
+		return -2, -2, nil
+	}
+
+	if len(vertex) < 2 {
+		return 0, 0, fmt.Errorf("invalid vertex: %s", vertex)
+	}
+
+	// Parse column (A-T, no I)
+	col := int(vertex[0] - 'A')
+	if col < 0 || col > 19 {
+		return 0, 0, fmt.Errorf("invalid column in vertex: %s", vertex)
+	}
+	if col > 7 {
+		col-- // Account for skipped 'I'
+	}
+
+	// Parse row
+	row, err := strconv.Atoi(vertex[1:])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid row in vertex: %s", vertex)
+	}
+
+	// Convert row to Y coordinate (invert from bottom-up to top-down)
+	y := size - row
+
+	if col < 0 || col >= size || y < 0 || y >= size {
+		return 0, 0, fmt.Errorf("vertex out of bounds: %s", vertex)
+	}
+
+	return col, y, nil
+}
+
+// rowsFromTop numbers display rows from the top of the board instead of
+// the bottom. It only affects what people see and type; GTP and SGF
+// always use the standard orientation.
+var rowsFromTop bool
+
+// SetRowsFromTop chooses whether row 1 is shown at the top of the board
+// (as in some other Go and chess programs) rather than the bottom.
+func SetRowsFromTop(enabled bool) {
+	rowsFromTop = enabled
+}
+
+// DisplayRow returns the row number shown for board row y.
+func DisplayRow(y, size int) int {
+	if rowsFromTop {
+		return y + 1
+	}
+	return size - y
+}
+
+// Display formats a move for people: the vertex with rows numbered per
+// SetRowsFromTop, or "pass". With the default orientation it is the GTP
+// vertex.
+func Display(x, y, size int) string {
+	if x < 0 || y < 0 {
+		return "pass"
+	}
+	vertex := ToGTP(x, y, size)
+	return fmt.Sprintf("%s%d", vertex[:1], DisplayRow(y, size))
+}
+
+// ParseDisplay is the inverse of Display, for coordinates typed by the
+// user. It accepts "pass" and is case-insensitive.
+func ParseDisplay(vertex string, size int) (int, int, error) {
+	x, y, err := FromGTP(vertex, size)
+	if err != nil || x < 0 || !rowsFromTop {
+		return x, y, err
+	}
+	// FromGTP counted rows from the bottom; the user counted from the top.
+	return x, size - 1 - y, nil
+}
@@ -0,0 +1,102 @@
+package coords
+
+import "testing"
+
+func TestToGTP(t *testing.T) {
+	tests := []struct {
+		x, y, size int
+		want       string
+	}{
+		{0, 18, 19, "A1"},
+		{3, 15, 19, "D4"},
+		{15, 3, 19, "Q16"},
+		{7, 0, 9, "H9"},
+		{8, 0, 9, "J9"}, // I is skipped
+		{18, 0, 19, "T19"},
+	}
+	for _, tt := range tests {
+		if got := ToGTP(tt.x, tt.y, tt.size); got != tt.want {
+			t.Errorf("ToGTP(%d, %d, %d) = %q, want %q", tt.x, tt.y, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestFromGTP(t *testing.T) {
+	for _, size := range []int{9, 13, 19} {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				v := ToGTP(x, y, size)
+				gx, gy, err := FromGTP(v, size)
+				if err != nil || gx != x || gy != y {
+					t.Fatalf("FromGTP(%q, %d) = %d, %d, %v; want %d, %d", v, size, gx, gy, err, x, y)
+				}
+			}
+		}
+	}
+
+	if x, y, err := FromGTP("pass", 19); err != nil || x != -1 || y != -1 {
+		t.Errorf("FromGTP(pass) = %d, %d, %v", x, y, err)
+	}
+	if x, y, err := FromGTP("RESIGN", 19); err != nil || x != -2 || y != -2 {
+		t.Errorf("FromGTP(RESIGN) = %d, %d, %v", x, y, err)
+	}
+	for _, bad := range []string{"", "Z", "K10", "A0", "A20", "Ax"} {
+		if _, _, err := FromGTP(bad, 9); err == nil {
+			t.Errorf("FromGTP(%q, 9) should fail", bad)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	if got := Display(-1, -1, 19); got != "pass" {
+		t.Errorf("Display(pass) = %q", got)
+	}
+	if got := Display(3, 15, 19); got != "D4" {
+		t.Errorf("Display(3, 15) = %q, want D4", got)
+	}
+}
+
+func TestRowsFromTop(t *testing.T) {
+	defer SetRowsFromTop(false)
+
+	// The on-wire vertices with the standard orientation.
+	wire := map[[3]int]string{}
+	for _, size := range []int{9, 19} {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				wire[[3]int{x, y, size}] = ToGTP(x, y, size)
+			}
+		}
+	}
+
+	for _, fromTop := range []bool{false, true} {
+		SetRowsFromTop(fromTop)
+		for p, vertex := range wire {
+			x, y, size := p[0], p[1], p[2]
+			if got := ToGTP(x, y, size); got != vertex {
+				t.Fatalf("fromTop=%v: ToGTP(%d, %d) = %s, want %s", fromTop, x, y, got, vertex)
+			}
+			if gx, gy, err := FromGTP(vertex, size); err != nil || gx != x || gy != y {
+				t.Fatalf("fromTop=%v: FromGTP(%s) = (%d, %d, %v), want (%d, %d)", fromTop, vertex, gx, gy, err, x, y)
+			}
+			shown := Display(x, y, size)
+			if gx, gy, err := ParseDisplay(shown, size); err != nil || gx != x || gy != y {
+				t.Fatalf("fromTop=%v: ParseDisplay(%q) = (%d, %d, %v), want (%d, %d)", fromTop, shown, gx, gy, err, x, y)
+			}
+		}
+	}
+
+	SetRowsFromTop(true)
+	if got := Display(0, 0, 19); got != "A1" {
+		t.Errorf("top-left shown as %s, want A1", got)
+	}
+	if got := Display(3, 15, 19); got != "D16" {
+		t.Errorf("Display(3, 15) = %s, want D16", got)
+	}
+	if got := DisplayRow(18, 19); got != 19 {
+		t.Errorf("DisplayRow(18) = %d, want 19", got)
+	}
+	if x, y, _ := ParseDisplay("pass", 19); x != -1 || y != -1 {
+		t.Errorf("ParseDisplay(pass) = (%d, %d)", x, y)
+	}
+}
@@ -0,0 +1,122 @@
+package engine
+
+import "sync"
+
+// Book is an opening sequence that both sides follow until it runs out or
+// either side plays something else. The engine plays the book's replies
+// instead of generating its own; the UI asks before the player leaves it.
+//
+// All methods are safe on a nil *Book, which behaves as "no book".
+type Book struct {
+	mu     sync.Mutex
+	moves  [][3]int // {color, x, y}; x, y = -1 for a pass
+	played int      // moves played in the game so far
+	leftAt int      // move number where play left the book, 0 while in it
+}
+
+// NewBook creates a book from a main line of {color, x, y} moves.
+func NewBook(moves [][3]int) *Book {
+	return &Book{moves: moves}
+}
+
+// Len returns the number of moves in the book.
+func (b *Book) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.moves)
+}
+
+// Next returns the book move expected from color, if play is still in book
+// and it is that color's turn in the book.
+func (b *Book) Next(color int) (x, y int, ok bool) {
+	if b == nil {
+		return 0, 0, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.leftAt != 0 || b.played >= len(b.moves) {
+		return 0, 0, false
+	}
+	m := b.moves[b.played]
+	if m[0] != color {
+		return 0, 0, false
+	}
+	return m[1], m[2], true
+}
+
+// Record advances the book past a move played in the game. It returns true
+// if the game is still following the book afterwards.
+func (b *Book) Record(color, x, y int) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.record(color, x, y)
+	return b.leftAt == 0
+}
+
+func (b *Book) record(color, x, y int) {
+	if b.leftAt == 0 && (b.played >= len(b.moves) || b.moves[b.played] != [3]int{color, x, y}) {
+		b.leftAt = b.played + 1
+	}
+	b.played++
+	if b.leftAt == 0 && b.played == len(b.moves) {
+		b.leftAt = b.played + 1
+	}
+}
+
+// Undo takes back n moves. Undoing to before the point where play left the
+// book puts the game back in book.
+func (b *Book) Undo(n int) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.played -= n
+	if b.played < 0 {
+		b.played = 0
+	}
+	if b.played < b.leftAt {
+		b.leftAt = 0
+		if b.played == len(b.moves) {
+			b.leftAt = b.played + 1
+		}
+	}
+}
+
+// Replay resets the book and records moves from the start of the game.
+func (b *Book) Replay(moves [][3]int) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.played, b.leftAt = 0, 0
+	for _, m := range moves {
+		b.record(m[0], m[1], m[2])
+	}
+}
+
+// InBook returns true while the game is still following the book.
+func (b *Book) InBook() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.leftAt == 0
+}
+
+// LeftAt returns the move number at which play left the book, or 0 if it
+// hasn't (or there is no book).
+func (b *Book) LeftAt() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.leftAt
+}
@@ -0,0 +1,128 @@
+package engine
+
+import "testing"
+
+func testBook() *Book {
+	return NewBook([][3]int{
+		{1, 3, 3},   // B
+		{2, 15, 15}, // W
+		{1, 15, 3},  // B
+	})
+}
+
+func TestBookFollow(t *testing.T) {
+	b := testBook()
+
+	x, y, ok := b.Next(1)
+	if !ok || x != 3 || y != 3 {
+		t.Fatalf("Next(black) = %d,%d,%v, want 3,3,true", x, y, ok)
+	}
+	if _, _, ok := b.Next(2); ok {
+		t.Error("Next(white) should be false when black is to play in the book")
+	}
+
+	if !b.Record(1, 3, 3) || !b.Record(2, 15, 15) {
+		t.Fatal("following the book should stay in book")
+	}
+	if x, y, ok := b.Next(1); !ok || x != 15 || y != 3 {
+		t.Errorf("Next(black) = %d,%d,%v, want 15,3,true", x, y, ok)
+	}
+}
+
+func TestBookExhaustion(t *testing.T) {
+	b := testBook()
+	b.Record(1, 3, 3)
+	b.Record(2, 15, 15)
+	if b.Record(1, 15, 3) {
+		t.Error("playing the last book move should leave the book")
+	}
+	if got := b.LeftAt(); got != 4 {
+		t.Errorf("LeftAt() = %d, want 4", got)
+	}
+	if _, _, ok := b.Next(2); ok {
+		t.Error("Next should be false once the book has run out")
+	}
+
+	b.Record(2, 10, 10)
+	if got := b.LeftAt(); got != 4 {
+		t.Errorf("LeftAt() after further play = %d, want 4", got)
+	}
+}
+
+func TestBookDeviation(t *testing.T) {
+	b := testBook()
+	b.Record(1, 3, 3)
+	if b.Record(2, 4, 4) {
+		t.Error("deviating should leave the book")
+	}
+	if got := b.LeftAt(); got != 2 {
+		t.Errorf("LeftAt() = %d, want 2", got)
+	}
+	if _, _, ok := b.Next(1); ok {
+		t.Error("Next should be false after deviation")
+	}
+
+	// Deviating with a pass counts too.
+	b = testBook()
+	if b.Record(1, -1, -1) {
+		t.Error("passing instead of the book move should leave the book")
+	}
+	if got := b.LeftAt(); got != 1 {
+		t.Errorf("LeftAt() = %d, want 1", got)
+	}
+}
+
+func TestBookUndo(t *testing.T) {
+	b := testBook()
+	b.Record(1, 3, 3)
+	b.Record(2, 4, 4) // deviation at move 2
+	b.Record(1, 5, 5)
+
+	b.Undo(1)
+	if b.InBook() {
+		t.Error("undoing to after the deviation should stay out of book")
+	}
+	b.Undo(1)
+	if !b.InBook() {
+		t.Fatal("undoing the deviation should return to book")
+	}
+	if x, y, ok := b.Next(2); !ok || x != 15 || y != 15 {
+		t.Errorf("Next(white) = %d,%d,%v, want 15,15,true", x, y, ok)
+	}
+
+	// Undo from past the end stays exhausted at the end of the book.
+	b = testBook()
+	for _, m := range b.moves {
+		b.Record(m[0], m[1], m[2])
+	}
+	b.Record(2, 9, 9)
+	b.Undo(1)
+	if b.InBook() || b.LeftAt() != 4 {
+		t.Errorf("InBook() = %v, LeftAt() = %d; want false, 4", b.InBook(), b.LeftAt())
+	}
+	b.Undo(1)
+	if !b.InBook() {
+		t.Error("undoing the last book move should return to book")
+	}
+}
+
+func TestBookReplay(t *testing.T) {
+	b := testBook()
+	b.Record(1, 9, 9)
+	b.Replay([][3]int{{1, 3, 3}, {2, 15, 15}})
+	if !b.InBook() {
+		t.Error("replaying book moves should be in book")
+	}
+	if _, _, ok := b.Next(1); !ok {
+		t.Error("Next(black) should be the third book move")
+	}
+}
+
+func TestNilBook(t *testing.T) {
+	var b *Book
+	if _, _, ok := b.Next(1); ok || b.InBook() || b.Record(1, 0, 0) || b.LeftAt() != 0 {
+		t.Error("nil book should behave as no book")
+	}
+	b.Undo(1)
+	b.Replay(nil)
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+)
+
+// Time controls give each side MainTime, then byo-yomi: ByoYomiStones
+// moves to play in every ByoYomiTime, as GTP's time_settings has it. One
+// stone per period is Japanese byo-yomi with a single period; no byo-yomi
+// is absolute time.
+
+// Timed reports whether the game is played with time controls.
+func (c GameConfig) Timed() bool {
+	return c.MainTime > 0 || c.ByoYomiTime > 0 && c.ByoYomiStones > 0
+}
+
+// GameClock is the time one side has left.
+type GameClock struct {
+	Main   time.Duration // main time left
+	Period time.Duration // time left in the byo-yomi period
+	Stones int           // moves left to play in the period, 0 before byo-yomi
+
+	byoTime   time.Duration
+	byoStones int
+}
+
+// NewGameClock returns a full clock for cfg's time controls.
+func NewGameClock(cfg GameConfig) GameClock {
+	c := GameClock{Main: cfg.MainTime}
+	if cfg.ByoYomiTime > 0 && cfg.ByoYomiStones > 0 {
+		c.byoTime, c.byoStones = cfg.ByoYomiTime, cfg.ByoYomiStones
+		if c.Main == 0 {
+			c.Period, c.Stones = c.byoTime, c.byoStones
+		}
+	}
+	return c
+}
+
+// InByoYomi reports whether the main time is used up and byo-yomi started.
+func (c GameClock) InByoYomi() bool {
+	return c.Stones > 0
+}
+
+// Spend takes d off the clock, main time first, then the byo-yomi period.
+// It returns false once the time has run out.
+func (c *GameClock) Spend(d time.Duration) bool {
+	if !c.InByoYomi() {
+		if d < c.Main {
+			c.Main -= d
+			return true
+		}
+		d -= c.Main
+		c.Main = 0
+		if c.byoStones == 0 {
+			return false
+		}
+		c.Period, c.Stones = c.byoTime, c.byoStones
+	}
+	if d >= c.Period {
+		c.Period = 0
+		return false
+	}
+	c.Period -= d
+	return true
+}
+
+// Moved counts a move played. In byo-yomi, the period starts over once
+// its stones are played.
+func (c *GameClock) Moved() {
+	if !c.InByoYomi() {
+		return
+	}
+	if c.Stones--; c.Stones == 0 {
+		c.Period, c.Stones = c.byoTime, c.byoStones
+	}
+}
+
+// TimeLeft returns the arguments for GTP's time_left: the main time and
+// 0, or in byo-yomi what is left of the period and its stones.
+func (c GameClock) TimeLeft() (time.Duration, int) {
+	if c.InByoYomi() {
+		return c.Period, c.Stones
+	}
+	return c.Main, 0
+}
+
+// String shows the time left as "12:05", or "0:28 (5)" in byo-yomi with
+// the stones still to play.
+func (c GameClock) String() string {
+	left, stones := c.TimeLeft()
+	s := int((left + time.Second - 1) / time.Second) // rounded up, so 0:00 means out of time
+	text := fmt.Sprintf("%d:%02d", s/60, s%60)
+	if stones > 0 {
+		text += fmt.Sprintf(" (%d)", stones)
+	}
+	return text
+}
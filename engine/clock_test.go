@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGameClockByoYomi(t *testing.T) {
+	c := NewGameClock(GameConfig{MainTime: time.Minute, ByoYomiTime: 30 * time.Second, ByoYomiStones: 2})
+
+	if !c.Spend(50*time.Second) || c.String() != "0:10" {
+		t.Fatalf("after 50s of main time: %s", c)
+	}
+	c.Moved()
+	// Running over the main time starts byo-yomi with the rest.
+	if !c.Spend(15*time.Second) || !c.InByoYomi() || c.String() != "0:25 (2)" {
+		t.Fatalf("5s into byo-yomi: %s", c)
+	}
+	c.Moved()
+	if left, stones := c.TimeLeft(); left != 25*time.Second || stones != 1 {
+		t.Errorf("TimeLeft = %v, %d; want 25s, 1", left, stones)
+	}
+	// The period's last stone restores it in full.
+	c.Spend(20 * time.Second)
+	c.Moved()
+	if c.String() != "0:30 (2)" {
+		t.Errorf("new period: %s", c)
+	}
+	if c.Spend(30 * time.Second) {
+		t.Error("a whole period used should run the clock out")
+	}
+}
+
+func TestGameClockAbsolute(t *testing.T) {
+	c := NewGameClock(GameConfig{MainTime: 10 * time.Minute})
+	if !c.Spend(9*time.Minute + 59*time.Second) {
+		t.Fatal("time ran out early")
+	}
+	c.Moved()
+	if c.InByoYomi() || c.String() != "0:01" {
+		t.Errorf("absolute time: %s", c)
+	}
+	if c.Spend(time.Second) {
+		t.Error("absolute time should run out without byo-yomi")
+	}
+}
+
+func TestGameClockOnlyByoYomi(t *testing.T) {
+	cfg := GameConfig{ByoYomiTime: 30 * time.Second, ByoYomiStones: 1}
+	if !cfg.Timed() || (GameConfig{ByoYomiTime: time.Second}).Timed() {
+		t.Error("Timed needs byo-yomi stones as well as time")
+	}
+	if left, stones := NewGameClock(cfg).TimeLeft(); left != 30*time.Second || stones != 1 {
+		t.Errorf("TimeLeft = %v, %d; want a period straight away", left, stones)
+	}
+}
@@ -1,7 +1,27 @@
 // Package engine defines the interface for game engines.
 package engine
 
-import "termsuji-local/types"
+import (
+	"errors"
+	"time"
+
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+// ErrClosed is returned by an engine's methods once it has been closed,
+// including a replay that Close cut short. Callers can treat it as a no-op:
+// whoever closed the engine is done with the game.
+var ErrClosed = errors.New("engine closed")
+
+// Errors for moves the engine refuses. An illegal move wraps
+// ErrIllegalMove with the engine's own reason.
+var (
+	ErrGameOver    = errors.New("game is over")
+	ErrNotYourTurn = errors.New("not your turn")
+	ErrIllegalMove = errors.New("illegal move")
+)
 
 // GameEngine defines the interface for playing Go against an engine.
 type GameEngine interface {
@@ -28,6 +48,14 @@ type GameEngine interface {
 	// x, y are -1, -1 for a pass. boardState is passed directly to avoid lock contention.
 	OnMove(func(x, y, color int, boardState *types.BoardState))
 
+	// Resign concedes the game for the human player. It ends the game like
+	// any other finish, with an outcome such as "White wins by resignation".
+	Resign() error
+
+	// LoseOnTime ends the game as a loss on time for the human player,
+	// with an outcome such as "White wins by time".
+	LoseOnTime() error
+
 	// Undo undoes the last move (one ply). Call twice to undo a player+engine move pair.
 	Undo() error
 
@@ -35,22 +63,91 @@ type GameEngine interface {
 	// Each move is [3]int{color, x, y} where color is 1=black/2=white, and x=-1,y=-1 means pass.
 	ResetAndReplay(moves [][3]int) error
 
+	// TopMoves returns the engine's best moves for the side to move, best
+	// first. It is only available on the human player's turn.
+	TopMoves() ([]Candidate, error)
+
+	// EstimateDead returns the stones [x, y] the engine would call dead if
+	// the game ended now, without ending it. Like TopMoves, it is only
+	// available on the human player's turn.
+	EstimateDead() ([][2]int, error)
+
+	// CanEstimate reports whether EstimateDead gives the engine's opinion.
+	// An engine without one finds nothing dead.
+	CanEstimate() bool
+
 	// OnGameEnd registers a callback for when the game ends.
 	OnGameEnd(func(outcome string))
 
+	// OnScoring registers a callback for when both sides have passed. The
+	// board state carries the engine's dead stones, and the game waits in
+	// the "scoring" phase until ConfirmScore or ResumePlay. Without a
+	// callback, two passes end the game with the engine's score.
+	OnScoring(func(boardState *types.BoardState))
+
+	// ConfirmScore ends a game in the scoring phase with the given dead
+	// stones, [x, y] each.
+	ConfirmScore(dead [][2]int) error
+
+	// ResumePlay leaves the scoring phase and continues the game.
+	ResumePlay() error
+
+	// OnProgress registers a callback for replay progress while a loaded game
+	// or ResetAndReplay is being played onto the engine. It fires every few
+	// moves with a snapshot of the board so far, and once more with done == total.
+	OnProgress(func(done, total int, boardState *types.BoardState))
+
 	// Close shuts down the engine.
 	Close()
 }
 
+// Candidate is a move the engine rates, with the value it gives it.
+// Values are the engine's own and only comparable with each other.
+type Candidate struct {
+	X, Y  int
+	Value float64
+}
+
 // GameConfig holds configuration for starting a new game.
 type GameConfig struct {
-	BoardSize     int     // 9, 13, or 19
-	Komi          float64 // Typically 6.5 or 7.5
-	PlayerColor   int     // 1=black, 2=white
-	EngineLevel   int     // GnuGo level 1-10
-	EnginePath    string  // Path to GnuGo binary
-	LoadSGFPath   string  // Path to SGF file for GnuGo's loadsgf command
-	LoadMoveCount int     // Number of moves in the loaded SGF (for turn determination)
+	BoardSize     int      // 9, 13, or 19
+	Komi          float64  // Typically 6.5 or 7.5
+	PlayerColor   int      // 1=black, 2=white
+	EngineLevel   int      // GnuGo level 1-10
+	EngineName    string   // Profile the engine was picked by, "" for GnuGo
+	PlayerName    string   // The human's name in the game record, "" for "Player"
+	EnginePath    string   // Path to the engine binary
+	EngineArgs    []string // Arguments for EnginePath; nil runs it as GnuGo at EngineLevel
+	LoadSGFPath   string   // Path to SGF file for GnuGo's loadsgf command
+	LoadMoveCount int      // Number of moves in the loaded SGF (for turn determination)
+	LoadNextColor int      // Side to move in the loaded SGF (0 = after the last of LoadMoves, or by LoadMoveCount)
+	LoadMoves     [][3]int // Moves of the loaded SGF, replayed one by one after its setup (nil = loadsgf plays them)
+	Book          *Book    // Optional opening sequence both sides must follow
+	Replies       *Replies // Optional opening replies played instead of asking the engine
+	MaxUndos      int      // Undos allowed this game, 0 = unlimited
+	Handicap      int      // Black handicap stones, 0 or 2-9; White moves first when set
+	Ruleset       string   // Rules GnuGo, planning and local counting follow, by name; "" for Japanese
+	Seed          int64    // Random seed for engines that take one, as GnuGo's --seed; 0 for none
+
+	// Time controls, all zero for an untimed game
+	MainTime      time.Duration // each side's main time
+	ByoYomiTime   time.Duration // length of a byo-yomi period
+	ByoYomiStones int           // moves to play in each period
+
+	// Thinking budget: with MoveBudget set, the level is stepped down, to
+	// no lower than MinLevel, while the engine's moves take longer
+	MoveBudget time.Duration
+	MinLevel   int
+}
+
+// RecordOptions are the header properties a record of the game starts
+// with: its ruleset, and its time controls if it is timed.
+func (c GameConfig) RecordOptions() sgf.RecordOptions {
+	opts := sgf.RecordOptions{Ruleset: rules.ByName(c.Ruleset).Name()}
+	if c.Timed() {
+		opts.MainTime, opts.ByoYomiTime, opts.ByoYomiStones = c.MainTime, c.ByoYomiTime, c.ByoYomiStones
+	}
+	return opts
 }
 
 // DefaultConfig returns a reasonable default configuration.
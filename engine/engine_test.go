@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"termsuji-local/sgf"
+)
+
+func TestRecordOptions(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  GameConfig
+		want sgf.RecordOptions
+	}{
+		{GameConfig{}, sgf.RecordOptions{Ruleset: "Japanese"}},
+		{GameConfig{Ruleset: "chinese"}, sgf.RecordOptions{Ruleset: "Chinese"}},
+		{
+			GameConfig{MainTime: 10 * time.Minute, ByoYomiTime: 30 * time.Second, ByoYomiStones: 1},
+			sgf.RecordOptions{Ruleset: "Japanese", MainTime: 10 * time.Minute, ByoYomiTime: 30 * time.Second, ByoYomiStones: 1},
+		},
+		// Byo-yomi without stones to play in it isn't a time control
+		{GameConfig{ByoYomiTime: 30 * time.Second}, sgf.RecordOptions{Ruleset: "Japanese"}},
+	} {
+		if got := tc.cfg.RecordOptions(); got != tc.want {
+			t.Errorf("%+v: RecordOptions() = %+v, want %+v", tc.cfg, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package engine
+
+import "time"
+
+// Hysteresis of LevelGovernor: a step down takes overBudgetMoves moves
+// in a row over budget, a step back up underBudgetMoves in a row under
+// a comfortFraction of it. Moves in between start both counts over.
+const (
+	overBudgetMoves  = 3
+	underBudgetMoves = 5
+	comfortFraction  = 2 // "comfortably under" is under budget/comfortFraction
+)
+
+// LevelGovernor keeps an engine's moves within a thinking budget by
+// stepping its level down while it runs over and back up, no higher than
+// where it started, while it runs well under.
+type LevelGovernor struct {
+	budget      time.Duration
+	min, max    int
+	level       int
+	over, under int // moves in a row over budget, and comfortably under
+}
+
+// NewLevelGovernor returns a governor for an engine starting at level,
+// which is also as high as it goes. It goes no lower than min.
+func NewLevelGovernor(budget time.Duration, level, min int) *LevelGovernor {
+	if min < 1 {
+		min = 1
+	}
+	if min > level {
+		min = level
+	}
+	return &LevelGovernor{budget: budget, min: min, max: level, level: level}
+}
+
+// Level returns the level the engine should be playing at.
+func (g *LevelGovernor) Level() int {
+	return g.level
+}
+
+// Observe takes the time the engine spent on a move. It returns the level
+// for the next one, and whether that is a change.
+func (g *LevelGovernor) Observe(took time.Duration) (level int, changed bool) {
+	switch {
+	case took > g.budget:
+		g.over, g.under = g.over+1, 0
+	case took < g.budget/comfortFraction:
+		g.over, g.under = 0, g.under+1
+	default:
+		g.over, g.under = 0, 0
+	}
+
+	switch {
+	case g.over >= overBudgetMoves && g.level > g.min:
+		g.level--
+	case g.under >= underBudgetMoves && g.level < g.max:
+		g.level++
+	default:
+		return g.level, false
+	}
+	g.over, g.under = 0, 0
+	return g.level, true
+}
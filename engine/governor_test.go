@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+// observeAll feeds the governor move times in seconds and returns the
+// level after each.
+func observeAll(g *LevelGovernor, secs ...float64) []int {
+	levels := make([]int, len(secs))
+	for i, s := range secs {
+		levels[i], _ = g.Observe(time.Duration(s * float64(time.Second)))
+	}
+	return levels
+}
+
+func TestLevelGovernor(t *testing.T) {
+	tests := []struct {
+		name  string
+		secs  []float64
+		final int
+	}{
+		{"within budget", []float64{4, 4.5, 3, 4.9, 4, 4}, 8},
+		{"steps down after three slow moves", []float64{6, 7, 6}, 7},
+		{"a quick move in between resets", []float64{6, 7, 1, 6, 7}, 8},
+		{"six slow moves step down twice", []float64{6, 6, 6, 6, 6, 6}, 6},
+		{"no lower than the minimum", []float64{9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9}, 5},
+		{"back up after five quick moves", []float64{6, 6, 6, 1, 1, 1, 1, 1}, 8},
+		{"no higher than the start", []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, 8},
+		{"under budget but not comfortably", []float64{6, 6, 6, 3, 3, 3, 3, 3, 3}, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewLevelGovernor(5*time.Second, 8, 5)
+			levels := observeAll(g, tt.secs...)
+			if got := levels[len(levels)-1]; got != tt.final || g.Level() != got {
+				t.Errorf("levels %v, want to end at %d", levels, tt.final)
+			}
+		})
+	}
+}
+
+func TestLevelGovernorNoFlapping(t *testing.T) {
+	// Alternating around the budget never settles into a run either way.
+	g := NewLevelGovernor(5*time.Second, 8, 1)
+	for i := 0; i < 50; i++ {
+		secs := 6.0
+		if i%2 == 1 {
+			secs = 1
+		}
+		if level, changed := g.Observe(time.Duration(secs * float64(time.Second))); changed {
+			t.Fatalf("level changed to %d on move %d", level, i+1)
+		}
+	}
+
+	// Each change is reported once.
+	g = NewLevelGovernor(5*time.Second, 8, 1)
+	var changes int
+	for i := 0; i < 9; i++ {
+		if _, changed := g.Observe(10 * time.Second); changed {
+			changes++
+		}
+	}
+	if changes != 3 || g.Level() != 5 {
+		t.Errorf("%d changes to level %d, want 3 to 5", changes, g.Level())
+	}
+}
+
+func TestLevelGovernorBounds(t *testing.T) {
+	if g := NewLevelGovernor(time.Second, 3, 7); g.min != 3 {
+		t.Errorf("minimum above the start clamps to it, got %d", g.min)
+	}
+	if g := NewLevelGovernor(time.Second, 3, 0); g.min != 1 {
+		t.Errorf("minimum 0 means 1, got %d", g.min)
+	}
+}
@@ -0,0 +1,75 @@
+package gtp_test
+
+import (
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+// The engine is usable as a library through the engine.GameEngine interface.
+var _ engine.GameEngine = (*gtp.GTPEngine)(nil)
+
+func TestGTPEngineAPI(t *testing.T) {
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.EnginePath = fakeEnginePath()
+
+	var eng engine.GameEngine = gtp.NewGTPEngine(cfg)
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+
+	moved := make(chan [3]int, 4)
+	eng.OnMove(func(x, y, color int, bs *types.BoardState) {
+		moved <- [3]int{color, x, y}
+	})
+
+	if !eng.IsMyTurn() {
+		t.Fatal("black should move first")
+	}
+	if err := eng.PlayMove(4, 4); err != nil {
+		t.Fatalf("PlayMove: %v", err)
+	}
+	wait := func() [3]int {
+		select {
+		case m := <-moved:
+			return m
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a move")
+			return [3]int{}
+		}
+	}
+	if m := wait(); m != [3]int{1, 4, 4} {
+		t.Errorf("first move = %v, want black at 4,4", m)
+	}
+	if m := wait(); m != [3]int{2, 0, 0} {
+		t.Errorf("reply = %v, want white at 0,0", m)
+	}
+
+	// Wait for the turn to come back before inspecting state.
+	for i := 0; i < 100 && !eng.IsMyTurn(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	bs := eng.GetBoardState()
+	if bs.Board[4][4] != 1 || bs.Board[0][0] != 2 || bs.MoveNumber != 2 {
+		t.Errorf("board after two moves: center %d corner %d move %d", bs.Board[4][4], bs.Board[0][0], bs.MoveNumber)
+	}
+
+	if err := eng.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if err := eng.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	bs = eng.GetBoardState()
+	if bs.Board[4][4] != 0 || bs.Board[0][0] != 0 || bs.MoveNumber != 0 {
+		t.Error("undoing both moves should leave an empty board")
+	}
+	if err := eng.Undo(); err == nil {
+		t.Error("Undo on an empty game should fail")
+	}
+}
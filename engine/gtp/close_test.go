@@ -0,0 +1,106 @@
+package gtp_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+func TestMethodsAfterClose(t *testing.T) {
+	eng := connectFake(t)
+	eng.Close()
+	eng.Close()
+
+	calls := map[string]func() error{
+		"Connect":        eng.Connect,
+		"PlayMove":       func() error { return eng.PlayMove(2, 2) },
+		"Pass":           eng.Pass,
+		"Resign":         eng.Resign,
+		"Undo":           eng.Undo,
+		"ConfirmScore":   func() error { return eng.ConfirmScore(nil) },
+		"ResumePlay":     eng.ResumePlay,
+		"ResetAndReplay": func() error { return eng.ResetAndReplay(replayMoves(4)) },
+		"TopMoves":       func() error { _, err := eng.TopMoves(); return err },
+		"EstimateDead":   func() error { _, err := eng.EstimateDead(); return err },
+	}
+
+	var wg sync.WaitGroup
+	for name, call := range calls {
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(name string, call func() error) {
+				defer wg.Done()
+				if err := call(); !errors.Is(err, gtp.ErrClosed) {
+					t.Errorf("%s after Close = %v, want ErrClosed", name, err)
+				}
+			}(name, call)
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		eng.Close()
+	}()
+	wg.Wait()
+
+	if eng.IsMyTurn() {
+		t.Error("IsMyTurn after Close = true")
+	}
+}
+
+func TestCloseDuringSlowGenmove(t *testing.T) {
+	t.Setenv(fakeSlowEnv, "30s")
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.PlayerColor = 2 // the engine opens, so genmove starts straight away
+	cfg.EnginePath = fakeEnginePath()
+	eng := gtp.NewGTPEngine(cfg)
+	var moved int32
+	eng.OnMove(func(x, y, color int, bs *types.BoardState) { atomic.StoreInt32(&moved, 1) })
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond) // let genmove reach the engine
+
+	start := time.Now()
+	eng.Close()
+	if took := time.Since(start); took > 3*time.Second {
+		t.Errorf("Close took %v while the engine was thinking", took)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if atomic.LoadInt32(&moved) != 0 {
+		t.Error("move callback fired after Close")
+	}
+	if err := eng.PlayMove(2, 2); !errors.Is(err, gtp.ErrClosed) {
+		t.Errorf("PlayMove after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestCloseDuringStuckReplay(t *testing.T) {
+	t.Setenv(fakeSlowPlayEnv, "30s")
+	eng := connectFake(t)
+
+	replayed := make(chan error, 1)
+	go func() { replayed <- eng.ResetAndReplay(replayMoves(5)) }()
+	time.Sleep(100 * time.Millisecond) // let the first play reach the engine
+
+	start := time.Now()
+	eng.Close()
+	if took := time.Since(start); took > 3*time.Second {
+		t.Errorf("Close took %v while a replay's move was stuck", took)
+	}
+	select {
+	case err := <-replayed:
+		if err == nil {
+			t.Error("the replay finished despite Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the replay did not end after Close")
+	}
+}
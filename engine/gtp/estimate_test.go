@@ -0,0 +1,81 @@
+package gtp_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"termsuji-local/engine/gtp"
+)
+
+// playPair plays x, y for Black and waits for White's answer.
+func playPair(t *testing.T, eng *gtp.GTPEngine, x, y int) {
+	t.Helper()
+	if err := eng.PlayMove(x, y); err != nil {
+		t.Fatalf("PlayMove: %v", err)
+	}
+	for i := 0; i < 500 && !eng.IsMyTurn(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEstimateDeadAfterPasses(t *testing.T) {
+	t.Setenv(fakeDeadEnv, "1")
+	eng := connectFake(t)
+	defer eng.Close()
+
+	playPair(t, eng, 4, 4) // White answers at 0,0
+	before := eng.GetBoardState()
+
+	dead, err := eng.EstimateDead()
+	if err != nil {
+		t.Fatalf("EstimateDead: %v", err)
+	}
+	if want := [][2]int{{0, 0}}; !reflect.DeepEqual(dead, want) {
+		t.Errorf("EstimateDead = %v, want %v from the hypothetical passes", dead, want)
+	}
+
+	// The passes are gone: it is still Black's move in the same position,
+	// and undoing takes back the real moves.
+	if !eng.IsMyTurn() {
+		t.Fatal("the estimate should leave it the player's turn")
+	}
+	if bs := eng.GetBoardState(); bs.MoveNumber != before.MoveNumber || !reflect.DeepEqual(bs.Board, before.Board) {
+		t.Errorf("board changed by the estimate: move %d, want %d", bs.MoveNumber, before.MoveNumber)
+	}
+	if err := eng.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if err := eng.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if bs := eng.GetBoardState(); bs.Board[4][4] != 0 || bs.Board[0][0] != 0 {
+		t.Errorf("stones left after undoing both moves: %v", bs.Board)
+	}
+}
+
+func TestEstimateDeadFallback(t *testing.T) {
+	for _, tc := range []struct {
+		knows string
+		desc  string
+	}{
+		{"final_status_list", "no undo: asked as the game stands"},
+		{"", "no final_status_list: no opinion"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Setenv(fakeDeadEnv, "1")
+			t.Setenv(fakeKnowsEnv, tc.knows)
+			eng := connectFake(t)
+			defer eng.Close()
+
+			playPair(t, eng, 4, 4)
+			dead, err := eng.EstimateDead()
+			if err != nil || dead != nil {
+				t.Errorf("EstimateDead = %v, %v; want nothing dead without passes", dead, err)
+			}
+			if bs := eng.GetBoardState(); bs.MoveNumber != 2 || !eng.IsMyTurn() {
+				t.Errorf("after the estimate: move %d, my turn %v", bs.MoveNumber, eng.IsMyTurn())
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package gtp_test
+
+import (
+	"fmt"
+
+	"termsuji-local/coords"
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+func ExampleGTPEngine_Connect() {
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.EnginePath = fakeEnginePath() // normally "gnugo"
+
+	eng := gtp.NewGTPEngine(cfg)
+	if err := eng.Connect(); err != nil {
+		fmt.Println("connect:", err)
+		return
+	}
+	defer eng.Close()
+
+	replied := make(chan string, 1)
+	eng.OnMove(func(x, y, color int, bs *types.BoardState) {
+		if color != eng.GetPlayerColor() {
+			replied <- coords.Display(x, y, cfg.BoardSize)
+		}
+	})
+
+	eng.PlayMove(4, 4) // E5
+	fmt.Println("engine replied", <-replied)
+	// Output:
+	// engine replied A9
+}
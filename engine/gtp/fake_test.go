@@ -0,0 +1,238 @@
+package gtp_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"termsuji-local/coords"
+)
+
+// fakeEngineEnv makes the test binary act as a minimal GTP engine, so
+// GTPEngine can be exercised without GnuGo installed.
+const fakeEngineEnv = "TERMSUJI_FAKE_GTP"
+
+// fakeKnowsEnv, when set, is the space-separated list of commands the fake
+// engine admits to through known_command; by default it knows all it
+// answers. fakeDeadEnv set to "1" makes final_status_list dead report
+// White's stones, but only right after two passes. fakeSlowEnv, a
+// duration, makes genmove think that long first, like GnuGo at a high
+// level; fakeSlowPlayEnv does the same for play. fakeLooseEnv set to "1" answers genmove the way some other
+// engines do: in lowercase, padded, and with a stray blank line after.
+// fakeLogEnv names a file the fake engine appends each command to.
+// fakeTenthsEnv set to "1" keeps komi to one decimal, as an engine that
+// rounds it would. fakeArgsEnv names a file the fake engine writes its
+// command-line arguments to. fakeHangupEnv set to "1" makes it exit when
+// asked to play, without answering.
+const (
+	fakeKnowsEnv    = "TERMSUJI_FAKE_GTP_KNOWS"
+	fakeDeadEnv     = "TERMSUJI_FAKE_GTP_DEAD"
+	fakeSlowEnv     = "TERMSUJI_FAKE_GTP_SLOW"
+	fakeSlowPlayEnv = "TERMSUJI_FAKE_GTP_SLOW_PLAY"
+	fakeLooseEnv    = "TERMSUJI_FAKE_GTP_LOOSE"
+	fakeLogEnv      = "TERMSUJI_FAKE_GTP_LOG"
+	fakeTenthsEnv   = "TERMSUJI_FAKE_GTP_TENTHS"
+	fakeArgsEnv     = "TERMSUJI_FAKE_GTP_ARGS"
+	fakeHangupEnv   = "TERMSUJI_FAKE_GTP_HANGUP"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeEngineEnv) == "1" {
+		runFakeEngine(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+	os.Setenv(fakeEngineEnv, "1") // inherited by engine subprocesses
+	os.Exit(m.Run())
+}
+
+// fakeEnginePath returns the "engine binary" to put in GameConfig.EnginePath.
+func fakeEnginePath() string {
+	return os.Args[0]
+}
+
+// runFakeEngine answers GTP commands. It never captures anything and
+// genmove takes the first empty point in reading order, or passes right
+// after a pass.
+func runFakeEngine(in io.Reader, out io.Writer) {
+	size := 19
+	komi := "0"
+	var moves []string // "black D4", in play order
+	stones := map[string]string{}
+
+	reply := func(s string) { fmt.Fprintf(out, "= %s\n\n", s) }
+	known, limited := os.LookupEnv(fakeKnowsEnv)
+	if !limited {
+		known = "boardsize clear_board play genmove top_moves_black top_moves_white undo fixed_handicap " +
+			"set_free_handicap list_stones captures final_score known_command final_status_list komi get_komi time_settings time_left level quit"
+	}
+	deadAfterPasses := os.Getenv(fakeDeadEnv) == "1"
+	think, _ := time.ParseDuration(os.Getenv(fakeSlowEnv))
+	playWait, _ := time.ParseDuration(os.Getenv(fakeSlowPlayEnv))
+	hangup := os.Getenv(fakeHangupEnv) == "1"
+	replyMove := reply
+	if os.Getenv(fakeLooseEnv) == "1" {
+		replyMove = func(s string) { fmt.Fprintf(out, "=  %s \n\n\n", strings.ToLower(s)) }
+	}
+
+	var log io.Writer = io.Discard
+	if path := os.Getenv(fakeLogEnv); path != "" {
+		if f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err == nil {
+			defer f.Close()
+			log = f
+		}
+	}
+
+	if path := os.Getenv(fakeArgsEnv); path != "" {
+		os.WriteFile(path, []byte(strings.Join(os.Args[1:], " ")), 0644)
+	}
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fmt.Fprintln(log, scanner.Text())
+		f := strings.Fields(scanner.Text())
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "boardsize":
+			fmt.Sscanf(f[1], "%d", &size)
+			reply("")
+		case "clear_board":
+			moves, stones = nil, map[string]string{}
+			reply("")
+		case "play":
+			time.Sleep(playWait)
+			if hangup {
+				return
+			}
+			if v := strings.ToUpper(f[2]); stones[v] != "" {
+				fmt.Fprint(out, "? illegal move\n\n")
+				continue
+			} else if v != "PASS" {
+				stones[v] = f[1]
+			}
+			moves = append(moves, f[1]+" "+strings.ToUpper(f[2]))
+			reply("")
+		case "genmove":
+			time.Sleep(think)
+			vertex := "PASS"
+			if len(moves) > 0 && strings.HasSuffix(moves[len(moves)-1], " PASS") {
+				moves = append(moves, f[1]+" "+vertex)
+				replyMove(vertex)
+				continue
+			}
+		search:
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					if v := coords.ToGTP(x, y, size); stones[v] == "" {
+						vertex = v
+						break search
+					}
+				}
+			}
+			if vertex != "PASS" {
+				stones[vertex] = f[1]
+			}
+			moves = append(moves, f[1]+" "+vertex)
+			replyMove(vertex)
+		case "top_moves_black", "top_moves_white":
+			// The first empty points in reading order, in falling value
+			var pairs []string
+			for y := 0; y < size && len(pairs) < 3; y++ {
+				for x := 0; x < size && len(pairs) < 3; x++ {
+					if v := coords.ToGTP(x, y, size); stones[v] == "" {
+						pairs = append(pairs, fmt.Sprintf("%s %.2f", v, 9.5-2*float64(len(pairs))))
+					}
+				}
+			}
+			reply(strings.Join(pairs, " "))
+		case "undo":
+			if len(moves) == 0 {
+				fmt.Fprint(out, "? cannot undo\n\n")
+				continue
+			}
+			last := strings.Fields(moves[len(moves)-1])
+			delete(stones, last[1])
+			moves = moves[:len(moves)-1]
+			reply("")
+		case "fixed_handicap":
+			// Corner points, enough for small handicaps
+			var n int
+			fmt.Sscanf(f[1], "%d", &n)
+			far := size - 3
+			var vs []string
+			for _, p := range [][2]int{{far, 2}, {2, far}, {far, far}, {2, 2}}[:n] {
+				v := coords.ToGTP(p[0], p[1], size)
+				stones[v] = "black"
+				vs = append(vs, v)
+			}
+			reply(strings.Join(vs, " "))
+		case "set_free_handicap":
+			for _, v := range f[1:] {
+				stones[strings.ToUpper(v)] = "black"
+			}
+			reply("")
+		case "list_stones":
+			var vs []string
+			for v, c := range stones {
+				if c == f[1] {
+					vs = append(vs, v)
+				}
+			}
+			reply(strings.Join(vs, " "))
+		case "captures":
+			reply("0")
+		case "final_score":
+			reply("W+0.5")
+		case "quit":
+			reply("")
+			return
+		case "known_command":
+			fmt.Fprintf(out, "= %v\n\n", strings.Contains(" "+known+" ", " "+f[1]+" "))
+		case "final_status_list":
+			n := len(moves)
+			if !deadAfterPasses || n < 2 || !strings.HasSuffix(moves[n-1], " PASS") || !strings.HasSuffix(moves[n-2], " PASS") {
+				reply("")
+				continue
+			}
+			var vs []string
+			for v, c := range stones {
+				if c == "white" {
+					vs = append(vs, v)
+				}
+			}
+			reply(strings.Join(vs, " "))
+		case "komi":
+			komi = f[1]
+			if os.Getenv(fakeTenthsEnv) == "1" {
+				var k float64
+				fmt.Sscanf(komi, "%g", &k)
+				komi = fmt.Sprintf("%.1f", k)
+			}
+			reply("")
+		case "get_komi":
+			reply(komi)
+		case "loadsgf":
+			// The file isn't read: the board is left empty, and the side
+			// to play before the move numbered is told as if the game
+			// alternated from Black
+			var n int
+			if len(f) > 2 {
+				fmt.Sscanf(f[2], "%d", &n)
+			}
+			if n > 0 && n%2 == 0 {
+				reply("white")
+			} else {
+				reply("black")
+			}
+		case "time_settings", "time_left", "level":
+			reply("")
+		default:
+			fmt.Fprint(out, "? unknown command\n\n")
+		}
+	}
+}
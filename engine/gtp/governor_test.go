@@ -0,0 +1,55 @@
+package gtp_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+func TestMoveBudget(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "gtp.log")
+	t.Setenv(fakeLogEnv, log)
+	t.Setenv(fakeSlowEnv, "60ms")
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.EnginePath = fakeEnginePath()
+	cfg.MoveBudget = 20 * time.Millisecond
+	cfg.MinLevel = 4
+	eng := gtp.NewGTPEngine(cfg)
+	changes := make(chan int, 10)
+	eng.OnMove(func(x, y, color int, bs *types.BoardState) {
+		if color == 2 {
+			changes <- bs.LevelChange
+		}
+	})
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+
+	// Three slow replies step the level down one, and no further
+	var got []int
+	for i := 0; i < 4; i++ {
+		if err := eng.PlayMove(8, 8-i); err != nil {
+			t.Fatalf("PlayMove: %v", err)
+		}
+		got = append(got, <-changes)
+	}
+	if got[0] != 0 || got[1] != 0 || got[2] != 4 || got[3] != 0 {
+		t.Errorf("level changes on the engine's moves: %v, want [0 0 4 0]", got)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent := string(data); strings.Count(sent, "level 4\n") != 1 || strings.Contains(sent, "level 3") {
+		t.Errorf("engine not sent level 4 once; got:\n%s", sent)
+	}
+}
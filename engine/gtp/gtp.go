@@ -2,27 +2,56 @@ package gtp
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"termsuji-local/engine"
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
 	"termsuji-local/types"
 )
 
-var debugLog *log.Logger
+var debugLog = log.New(io.Discard, "", 0)
 
-func init() {
-	f, _ := os.Create("/tmp/termsuji-debug.log")
-	debugLog = log.New(f, "", log.Ltime|log.Lmicroseconds)
+// SetDebugLog sends a trace of the GTP conversation to w. Tracing is off
+// by default.
+func SetDebugLog(w io.Writer) {
+	debugLog = log.New(w, "", log.Ltime|log.Lmicroseconds)
 }
 
-// GTPEngine implements the GameEngine interface using GnuGo via GTP protocol.
+// ErrClosed is engine.ErrClosed, returned by every method that talks to
+// GnuGo once Close has been called.
+var ErrClosed = engine.ErrClosed
+
+// replayStep is how many replayed moves pass between progress reports.
+const replayStep = 10
+
+// closeGrace is how long Close waits for a move GnuGo is thinking about
+// before killing it. A high level can think for tens of seconds.
+const closeGrace = 500 * time.Millisecond
+
+// replyError is a failure response from the engine, "? message", as
+// opposed to the conversation with it failing.
+type replyError struct {
+	msg string
+}
+
+func (e *replyError) Error() string {
+	return "GTP error: " + e.msg
+}
+
+// GTPEngine implements the GameEngine interface over GTP, using GnuGo
+// unless the config names another engine.
 type GTPEngine struct {
 	cmd    *exec.Cmd
 	stdin  io.WriteCloser
@@ -33,12 +62,30 @@ type GTPEngine struct {
 	myTurn      bool
 	passCount   int
 	gameOver    bool
-	playerColor int // Human's color (1=black, 2=white)
-
-	moveCallback func(x, y, color int, boardState *types.BoardState)
-	endCallback  func(outcome string)
-
-	mu sync.Mutex
+	closed      bool             // set by Close; GnuGo is gone and every call fails with ErrClosed
+	scoring     bool             // both sides passed; waiting for ConfirmScore or ResumePlay
+	playerColor int              // Human's color (1=black, 2=white)
+	handicap    []string         // vertices of the handicap stones, placed again after clear_board
+	localBoard  bool             // the engine can't list its stones; the board comes from tracker
+	canEstimate bool             // the engine knows final_status_list
+	komiNote    string           // how get_komi differed from the komi sent, "" if it didn't
+	timed       bool             // the engine was given the time controls
+	clock       engine.GameClock // the engine's time left, for time_left
+	tracker     moveTracker
+
+	// governor steps the level to keep genmove within MoveBudget; nil
+	// without a budget
+	governor *engine.LevelGovernor
+
+	moveCallback     func(x, y, color int, boardState *types.BoardState)
+	endCallback      func(outcome string)
+	scoringCallback  func(boardState *types.BoardState)
+	progressCallback func(done, total int, boardState *types.BoardState)
+
+	mu        sync.Mutex
+	replayMu  sync.Mutex    // held for the duration of a replay, so Close can wait it out
+	closing   chan struct{} // closed by Close to cancel a running replay
+	closeOnce sync.Once
 }
 
 // NewGTPEngine creates a new GTP engine with the given configuration.
@@ -47,18 +94,34 @@ func NewGTPEngine(cfg engine.GameConfig) *GTPEngine {
 		config:      cfg,
 		playerColor: cfg.PlayerColor,
 		boardState:  types.NewBoardState(cfg.BoardSize),
+		closing:     make(chan struct{}),
 	}
 }
 
 // Connect starts the GnuGo subprocess and initializes the game.
 func (g *GTPEngine) Connect() error {
-	// Start GnuGo process
-	args := []string{
-		"--mode", "gtp",
-		"--level", fmt.Sprintf("%d", g.config.EngineLevel),
-		"--quiet",
+	g.mu.Lock()
+	closed := g.closed
+	g.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	// Start the engine process
+	args := g.config.EngineArgs
+	if args == nil {
+		args = []string{
+			"--mode", "gtp",
+			"--level", fmt.Sprintf("%d", g.config.EngineLevel),
+			"--quiet",
+			rules.GnuGoFlag(g.config.Ruleset),
+		}
+		if g.config.Seed != 0 {
+			args = append(args, "--seed", strconv.FormatInt(g.config.Seed, 10))
+		}
 	}
 	g.cmd = exec.Command(g.config.EnginePath, args...)
+	ownProcessGroup(g.cmd)
 
 	var err error
 	g.stdin, err = g.cmd.StdinPipe()
@@ -76,9 +139,13 @@ func (g *GTPEngine) Connect() error {
 	g.cmd.Stderr = nil
 
 	if err := g.cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start GnuGo: %w", err)
+		return fmt.Errorf("failed to start %s: %w", g.engineName(), err)
 	}
 
+	// Engines other than GnuGo may not list their stones
+	g.localBoard = !g.knowsCommand("list_stones")
+	g.canEstimate = g.knowsCommand("final_status_list")
+
 	// Initialize the board
 	if _, err := g.sendCommand(fmt.Sprintf("boardsize %d", g.config.BoardSize)); err != nil {
 		return fmt.Errorf("failed to set board size: %w", err)
@@ -88,38 +155,65 @@ func (g *GTPEngine) Connect() error {
 		return fmt.Errorf("failed to clear board: %w", err)
 	}
 
-	if _, err := g.sendCommand(fmt.Sprintf("komi %.1f", g.config.Komi)); err != nil {
-		return fmt.Errorf("failed to set komi: %w", err)
+	if err := g.setKomi(g.config.Komi); err != nil {
+		return err
+	}
+
+	// Time controls, for engines that plan their time by them
+	if g.config.Timed() && g.knowsCommand("time_settings") {
+		g.clock = engine.NewGameClock(g.config)
+		byoTime, byoStones := 0, 0
+		if g.config.ByoYomiTime > 0 && g.config.ByoYomiStones > 0 {
+			byoTime, byoStones = int(g.config.ByoYomiTime.Seconds()), g.config.ByoYomiStones
+		}
+		_, err := g.sendCommand(fmt.Sprintf("time_settings %d %d %d", int(g.config.MainTime.Seconds()), byoTime, byoStones))
+		g.timed = err == nil
+	}
+
+	// A thinking budget needs the level changed mid-game
+	if g.config.MoveBudget > 0 && g.config.EngineLevel > 0 && g.knowsCommand("level") {
+		g.governor = engine.NewLevelGovernor(g.config.MoveBudget, g.config.EngineLevel, g.config.MinLevel)
 	}
 
 	// Load SGF if resuming a game
 	if g.config.LoadSGFPath != "" {
-		// GTP is space-delimited with no quoting support, so paths with spaces
-		// (e.g. ~/Library/Application Support/...) break loadsgf. Copy to a
-		// temp file with a safe path as a workaround.
-		sgfPath := g.config.LoadSGFPath
-		if strings.Contains(sgfPath, " ") {
-			data, err := os.ReadFile(sgfPath)
-			if err != nil {
-				return fmt.Errorf("failed to read SGF file: %w", err)
-			}
-			tmpPath := filepath.Join(os.TempDir(), "termsuji-load.sgf")
-			if err := os.WriteFile(tmpPath, data, 0644); err != nil {
-				return fmt.Errorf("failed to write temp SGF file: %w", err)
-			}
-			defer os.Remove(tmpPath)
-			sgfPath = tmpPath
+		sgfPath, cleanup, err := loadPath(g.config.LoadSGFPath)
+		if err != nil {
+			return err
 		}
-		if _, err := g.sendCommand(fmt.Sprintf("loadsgf %s", sgfPath)); err != nil {
+		defer cleanup()
+		// With the moves known up front, load only the setup ("until move 1")
+		// and replay the rest ourselves so progress can be shown.
+		loadCmd := fmt.Sprintf("loadsgf %s", sgfPath)
+		if g.config.LoadMoves != nil {
+			loadCmd += " 1"
+		}
+		if _, err := g.sendCommand(loadCmd); err != nil {
 			return fmt.Errorf("failed to load SGF: %w", err)
 		}
+		if g.config.LoadMoves != nil {
+			g.mu.Lock()
+			err := g.replayMoves(g.config.LoadMoves, g.config.LoadMoveCount-len(g.config.LoadMoves))
+			g.mu.Unlock()
+			if err != nil {
+				return err
+			}
+		}
 		g.updateBoardFromGnuGo()
 		g.boardState.MoveNumber = g.config.LoadMoveCount
 
-		// Determine whose turn: black if even move count, white if odd
-		nextColor := 1 // black
-		if g.config.LoadMoveCount%2 != 0 {
-			nextColor = 2 // white
+		// Determine whose turn: from the record when it says, otherwise
+		// the other side from the last replayed move, whatever the parity,
+		// and only without moves black if even move count, white if odd
+		nextColor := g.config.LoadNextColor
+		if n := len(g.config.LoadMoves); nextColor == 0 && n > 0 {
+			nextColor = oppositeColor(g.config.LoadMoves[n-1][0])
+		}
+		if nextColor == 0 {
+			nextColor = 1 // black
+			if g.config.LoadMoveCount%2 != 0 {
+				nextColor = 2 // white
+			}
 		}
 		g.boardState.PlayerToMove = nextColor
 
@@ -129,14 +223,23 @@ func (g *GTPEngine) Connect() error {
 			g.myTurn = false
 			go g.triggerEngineMove()
 		}
+		if g.config.LoadMoves != nil {
+			g.mu.Lock()
+			g.reportProgress(len(g.config.LoadMoves), len(g.config.LoadMoves))
+			g.mu.Unlock()
+		}
 	} else {
-		// Determine who plays first
-		// Black always plays first in Go
-		if g.playerColor == 1 {
-			// Human is black, human's turn first
+		firstColor := 1 // Black plays first in an even game
+		if g.config.Handicap >= 2 {
+			if err := g.placeHandicap(g.config.Handicap); err != nil {
+				return err
+			}
+			firstColor = 2 // White answers the handicap stones
+		}
+		g.boardState.PlayerToMove = firstColor
+		if g.playerColor == firstColor {
 			g.myTurn = true
 		} else {
-			// Human is white, engine (black) plays first
 			g.myTurn = false
 			go g.triggerEngineMove()
 		}
@@ -145,6 +248,67 @@ func (g *GTPEngine) Connect() error {
 	return nil
 }
 
+// loadPath returns a path to sgfPath that loadsgf can be given, and a
+// function to call once the engine has read it. GTP is space-delimited
+// with no quoting support, so paths with spaces (e.g. ~/Library/Application
+// Support/...) break loadsgf; such a file is copied to a temp file with a
+// safe path as a workaround.
+func loadPath(sgfPath string) (string, func(), error) {
+	if !strings.Contains(sgfPath, " ") {
+		return sgfPath, func() {}, nil
+	}
+	data, err := os.ReadFile(sgfPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read SGF file: %w", err)
+	}
+	tmpPath := filepath.Join(os.TempDir(), "termsuji-load.sgf")
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return "", nil, fmt.Errorf("failed to write temp SGF file: %w", err)
+	}
+	return tmpPath, func() { os.Remove(tmpPath) }, nil
+}
+
+// engineName names the engine in errors: its profile, or GnuGo.
+func (g *GTPEngine) engineName() string {
+	if g.config.EngineName != "" {
+		return g.config.EngineName
+	}
+	return "GnuGo"
+}
+
+// placeHandicap asks the engine for n fixed handicap stones and puts the
+// vertices it returns on the board.
+func (g *GTPEngine) placeHandicap(n int) error {
+	response, err := g.sendCommand(fmt.Sprintf("fixed_handicap %d", n))
+	if err != nil {
+		return fmt.Errorf("failed to place %d handicap stones: %w", n, err)
+	}
+	g.handicap = nil
+	for _, vertex := range strings.Fields(response) {
+		x, y, err := gtpToPos(vertex, g.config.BoardSize)
+		if err != nil || x < 0 {
+			return fmt.Errorf("engine returned bad handicap vertex %q", vertex)
+		}
+		g.boardState.Board[y][x] = 1
+		g.handicap = append(g.handicap, vertex)
+	}
+	return nil
+}
+
+// HandicapStones returns the [x, y] points of the handicap stones placed
+// by Connect, for the game record.
+func (g *GTPEngine) HandicapStones() [][2]int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var stones [][2]int
+	for _, vertex := range g.handicap {
+		if x, y, err := gtpToPos(vertex, g.config.BoardSize); err == nil {
+			stones = append(stones, [2]int{x, y})
+		}
+	}
+	return stones
+}
+
 // sendCommand sends a GTP command and returns the response.
 func (g *GTPEngine) sendCommand(cmd string) (string, error) {
 	debugLog.Printf("sendCommand: sending '%s'", cmd)
@@ -170,8 +334,12 @@ func (g *GTPEngine) sendCommand(cmd string) (string, error) {
 		line = strings.TrimRight(line, "\r\n")
 		debugLog.Printf("sendCommand: read line '%s'", line)
 
-		// Empty line signals end of response
+		// Empty line signals end of response. Some engines send an extra
+		// one after the last response; it is skipped.
 		if line == "" {
+			if response.Len() == 0 {
+				continue
+			}
 			break
 		}
 
@@ -186,11 +354,13 @@ func (g *GTPEngine) sendCommand(cmd string) (string, error) {
 
 	// Check for error response (starts with '?')
 	if strings.HasPrefix(result, "?") {
-		return "", fmt.Errorf("GTP error: %s", strings.TrimPrefix(result, "? "))
+		return "", &replyError{strings.TrimPrefix(result, "? ")}
 	}
 
 	// Success response starts with '='
-	return strings.TrimPrefix(strings.TrimPrefix(result, "="), " "), nil
+	result = strings.TrimSpace(strings.TrimPrefix(result, "="))
+	g.tracker.observe(cmd, result, g.config.BoardSize)
+	return result, nil
 }
 
 // GetBoardState returns the current board state.
@@ -206,14 +376,18 @@ func (g *GTPEngine) PlayMove(x, y int) error {
 	g.mu.Lock()
 	debugLog.Printf("PlayMove: acquired lock")
 
+	if g.closed {
+		g.mu.Unlock()
+		return ErrClosed
+	}
 	if g.gameOver {
 		g.mu.Unlock()
-		return fmt.Errorf("game is over")
+		return engine.ErrGameOver
 	}
 
 	if !g.myTurn {
 		g.mu.Unlock()
-		return fmt.Errorf("not your turn")
+		return engine.ErrNotYourTurn
 	}
 
 	vertex := posToGTP(x, y, g.config.BoardSize)
@@ -224,7 +398,13 @@ func (g *GTPEngine) PlayMove(x, y int) error {
 	if err != nil {
 		debugLog.Printf("PlayMove: play command failed: %v", err)
 		g.mu.Unlock()
-		return fmt.Errorf("illegal move: %w", err)
+		// Only the engine turning the move down makes it illegal; a
+		// broken pipe says nothing about the move
+		var reply *replyError
+		if errors.As(err, &reply) && strings.HasPrefix(reply.msg, "illegal move") {
+			return fmt.Errorf("%w: %v", engine.ErrIllegalMove, err)
+		}
+		return err
 	}
 	debugLog.Printf("PlayMove: play command succeeded")
 
@@ -232,9 +412,12 @@ func (g *GTPEngine) PlayMove(x, y int) error {
 	g.boardState.Board[y][x] = g.playerColor
 	g.boardState.LastMove.X = x
 	g.boardState.LastMove.Y = y
+	g.boardState.BookMove = false
+	g.boardState.LevelChange = 0
 	g.boardState.MoveNumber++
 	g.boardState.PlayerToMove = oppositeColor(g.playerColor)
 	g.passCount = 0
+	g.config.Book.Record(g.playerColor, x, y)
 
 	// Update captures by refreshing board state from GnuGo
 	debugLog.Printf("PlayMove: updating board from GnuGo")
@@ -268,14 +451,19 @@ func (g *GTPEngine) PlayMove(x, y int) error {
 func (g *GTPEngine) Pass() error {
 	g.mu.Lock()
 
+	if g.closed {
+		g.mu.Unlock()
+		return ErrClosed
+	}
+
 	if g.gameOver {
 		g.mu.Unlock()
-		return fmt.Errorf("game is over")
+		return engine.ErrGameOver
 	}
 
 	if !g.myTurn {
 		g.mu.Unlock()
-		return fmt.Errorf("not your turn")
+		return engine.ErrNotYourTurn
 	}
 
 	color := colorToGTP(g.playerColor)
@@ -288,10 +476,13 @@ func (g *GTPEngine) Pass() error {
 
 	g.boardState.LastMove.X = -1
 	g.boardState.LastMove.Y = -1
+	g.boardState.BookMove = false
+	g.boardState.LevelChange = 0
 	g.boardState.MoveNumber++
 	g.boardState.PlayerToMove = oppositeColor(g.playerColor)
 	g.passCount++
 	passCount := g.passCount
+	g.config.Book.Record(g.playerColor, -1, -1)
 
 	g.myTurn = false
 	playerColor := g.playerColor
@@ -305,7 +496,7 @@ func (g *GTPEngine) Pass() error {
 
 	// Check for double pass
 	if passCount >= 2 {
-		g.handleGameEnd()
+		g.endByPasses()
 		return nil
 	}
 
@@ -315,33 +506,329 @@ func (g *GTPEngine) Pass() error {
 	return nil
 }
 
+// Resign concedes the game for the human player. It may be called while
+// the engine is thinking; the resignation then follows the engine's move.
+func (g *GTPEngine) Resign() error {
+	g.mu.Lock()
+
+	if g.closed {
+		g.mu.Unlock()
+		return ErrClosed
+	}
+
+	if g.gameOver {
+		g.mu.Unlock()
+		return engine.ErrGameOver
+	}
+
+	outcome := g.resign(g.playerColor)
+	g.mu.Unlock()
+
+	if g.endCallback != nil {
+		g.endCallback(outcome)
+	}
+	return nil
+}
+
+// LoseOnTime ends the game as a loss on time for the player.
+func (g *GTPEngine) LoseOnTime() error {
+	g.mu.Lock()
+
+	if g.closed {
+		g.mu.Unlock()
+		return ErrClosed
+	}
+
+	if g.gameOver {
+		g.mu.Unlock()
+		return engine.ErrGameOver
+	}
+
+	outcome := g.lose(g.playerColor, "time")
+	g.mu.Unlock()
+
+	if g.endCallback != nil {
+		g.endCallback(outcome)
+	}
+	return nil
+}
+
+// resign ends the game with loser conceding and returns the outcome.
+// Must be called while holding the lock.
+func (g *GTPEngine) resign(loser int) string {
+	return g.lose(loser, "resignation")
+}
+
+// lose ends the game as a loss for loser, by how, and returns the outcome.
+// Must be called while holding the lock.
+func (g *GTPEngine) lose(loser int, how string) string {
+	g.gameOver = true
+	g.boardState.Phase = "finished"
+	winner := "Black"
+	if loser == 1 {
+		winner = "White"
+	}
+	g.boardState.Outcome = fmt.Sprintf("%s wins by %s", winner, how)
+	return g.boardState.Outcome
+}
+
+// TopMoves asks GnuGo for its best moves for the player, best first.
+// Only on the player's turn: otherwise the engine may be thinking, and
+// waiting on the lock would stall the caller until it has moved.
+func (g *GTPEngine) TopMoves() ([]engine.Candidate, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return nil, ErrClosed
+	}
+	if g.gameOver || g.scoring || !g.myTurn {
+		return nil, engine.ErrNotYourTurn
+	}
+
+	resp, err := g.sendCommand("top_moves_" + colorToGTP(g.playerColor))
+	if err != nil {
+		return nil, fmt.Errorf("top_moves failed: %w", err)
+	}
+	return parseTopMoves(resp, g.config.BoardSize)
+}
+
+// TopMovesAt loads the position after the first n moves of the game
+// saved at sgfPath and asks GnuGo for the best moves of the side to move
+// there, best first. It replaces the engine's board, so it is for an
+// engine kept to analyse a saved game, not one playing a game. Only the
+// first game of a collection can be loaded.
+func (g *GTPEngine) TopMovesAt(sgfPath string, n int) ([]engine.Candidate, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return nil, ErrClosed
+	}
+	path, cleanup, err := loadPath(sgfPath)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	// loadsgf stops before the move numbered, and says who is to play
+	color, err := g.sendCommand(fmt.Sprintf("loadsgf %s %d", path, n+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SGF: %w", err)
+	}
+	resp, err := g.sendCommand("top_moves_" + colorToGTP(gtpToColor(color)))
+	if err != nil {
+		return nil, fmt.Errorf("top_moves failed: %w", err)
+	}
+	return parseTopMoves(resp, g.config.BoardSize)
+}
+
+// parseTopMoves reads a top_moves reply, "D4 12.34 C3 10.5 ...", in the
+// order GnuGo gives it.
+func parseTopMoves(resp string, size int) ([]engine.Candidate, error) {
+	f := strings.Fields(resp)
+	if len(f)%2 != 0 {
+		return nil, fmt.Errorf("malformed top_moves reply %q", resp)
+	}
+	var moves []engine.Candidate
+	for i := 0; i < len(f); i += 2 {
+		x, y, err := gtpToPos(f[i], size)
+		if err != nil {
+			return nil, fmt.Errorf("malformed top_moves reply %q: %w", resp, err)
+		}
+		value, err := strconv.ParseFloat(f[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed top_moves reply %q: %w", resp, err)
+		}
+		moves = append(moves, engine.Candidate{X: x, Y: y, Value: value})
+	}
+	return moves, nil
+}
+
+// EstimateDead returns the stones GnuGo would call dead if the game ended
+// now, without ending it. Only on the player's turn, like TopMoves. An
+// engine without final_status_list has no opinion, and nil is returned
+// for everything to count as alive.
+func (g *GTPEngine) EstimateDead() ([][2]int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return nil, ErrClosed
+	}
+	if g.gameOver || g.scoring || !g.myTurn {
+		return nil, engine.ErrNotYourTurn
+	}
+
+	if !g.knowsCommand("final_status_list") {
+		return nil, nil
+	}
+	if g.knowsCommand("undo") {
+		dead, err := g.deadAfterPasses()
+		if !errors.Is(err, errNoPasses) {
+			return dead, err
+		}
+	}
+	resp, err := g.sendCommand("final_status_list dead")
+	if err != nil {
+		return nil, fmt.Errorf("final_status_list failed: %w", err)
+	}
+	return parseVertices(resp, g.config.BoardSize), nil
+}
+
+// CanEstimate reports whether the engine knows final_status_list, which
+// EstimateDead asks.
+func (g *GTPEngine) CanEstimate() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.canEstimate
+}
+
+// errNoPasses is deadAfterPasses refusing the hypothetical passes, which
+// leaves the position as it was.
+var errNoPasses = errors.New("engine refused the passes")
+
+// deadAfterPasses asks for the dead stones as if both sides had just
+// passed, which is when an engine settles them most carefully, then takes
+// the passes back. The stones are compared before and after, so a take-back
+// gone wrong is reported rather than left to corrupt the game. Must be
+// called while holding the lock.
+func (g *GTPEngine) deadAfterPasses() ([][2]int, error) {
+	before := g.stoneList()
+	passed := 0
+	var resp string
+	var err error
+	for _, color := range []int{g.playerColor, oppositeColor(g.playerColor)} {
+		if _, err = g.sendCommand("play " + colorToGTP(color) + " pass"); err != nil {
+			err = errNoPasses
+			break
+		}
+		passed++
+	}
+	if err == nil {
+		if resp, err = g.sendCommand("final_status_list dead"); err != nil {
+			err = fmt.Errorf("final_status_list failed: %w", err)
+		}
+	}
+	for ; passed > 0; passed-- {
+		if _, uerr := g.sendCommand("undo"); uerr != nil {
+			return nil, fmt.Errorf("taking back the estimate's passes: %w", uerr)
+		}
+	}
+	if g.stoneList() != before {
+		return nil, fmt.Errorf("position changed while taking back the estimate's passes")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseVertices(resp, g.config.BoardSize), nil
+}
+
+// KomiNote says how the engine's komi differs from the game's, as with an
+// engine that rounds it; "" when it took the komi it was given.
+func (g *GTPEngine) KomiNote() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.komiNote
+}
+
+// setKomi sends komi and, where the engine can say, checks it was taken
+// as sent rather than rounded.
+func (g *GTPEngine) setKomi(komi float64) error {
+	want := sgf.FormatKomi(komi)
+	if _, err := g.sendCommand("komi " + want); err != nil {
+		return fmt.Errorf("failed to set komi: %w", err)
+	}
+	if !g.knowsCommand("get_komi") {
+		return nil
+	}
+	resp, err := g.sendCommand("get_komi")
+	if err != nil {
+		return nil
+	}
+	// An engine that counts other komi can still play the game; the
+	// player is told rather than kept from starting it
+	if got, err := sgf.ParseKomi(strings.TrimSpace(resp)); err != nil || got != komi {
+		g.komiNote = fmt.Sprintf("engine has komi %s, not %s", strings.TrimSpace(resp), want)
+		debugLog.Printf("setKomi: %s", g.komiNote)
+	}
+	return nil
+}
+
+// knowsCommand returns true if the engine implements a GTP command.
+// Must be called while holding the lock.
+func (g *GTPEngine) knowsCommand(name string) bool {
+	resp, err := g.sendCommand("known_command " + name)
+	return err == nil && strings.TrimSpace(resp) == "true"
+}
+
+// stoneList returns the stones on the engine's board in a form that can be
+// compared. Must be called while holding the lock.
+func (g *GTPEngine) stoneList() string {
+	if g.localBoard {
+		return fmt.Sprint(g.tracker.moves)
+	}
+	var lists []string
+	for _, color := range []string{"black", "white"} {
+		resp, _ := g.sendCommand("list_stones " + color)
+		f := strings.Fields(resp)
+		sort.Strings(f)
+		lists = append(lists, color+": "+strings.Join(f, " "))
+	}
+	return strings.Join(lists, "; ")
+}
+
 // triggerEngineMove asks the engine to generate and play a move.
 func (g *GTPEngine) triggerEngineMove() {
 	g.mu.Lock()
 
-	if g.gameOver {
+	if g.gameOver || g.closed {
 		g.mu.Unlock()
 		return
 	}
 
 	engineColor := oppositeColor(g.playerColor)
-	response, err := g.sendCommand(fmt.Sprintf("genmove %s", colorToGTP(engineColor)))
-	if err != nil {
+	response := g.playBookMove(engineColor)
+	if response == "" {
+		response = g.playReply(engineColor)
+	}
+	fromBook := response != ""
+	levelChange := 0
+	if response == "" {
+		if g.timed {
+			left, stones := g.clock.TimeLeft()
+			g.sendCommand(fmt.Sprintf("time_left %s %d %d", colorToGTP(engineColor), int(left.Seconds()), stones))
+		}
+		start := time.Now()
+		var err error
+		response, err = g.sendCommand(fmt.Sprintf("genmove %s", colorToGTP(engineColor)))
+		if err != nil {
+			g.mu.Unlock()
+			return
+		}
+		took := time.Since(start)
+		if g.timed {
+			g.clock.Spend(took)
+		}
+		levelChange = g.governLevel(took)
+	}
+	if g.timed {
+		g.clock.Moved()
+	}
+	select {
+	case <-g.closing:
+		// Close is waiting on this move; nobody wants it any more
 		g.mu.Unlock()
 		return
+	default:
 	}
 
-	response = strings.TrimSpace(strings.ToUpper(response))
+	// Engines differ in case and spacing: "pass", " D4", "resign\t"
+	if f := strings.Fields(strings.ToUpper(response)); len(f) > 0 {
+		response = f[0]
+	}
 
 	if response == "RESIGN" {
-		g.gameOver = true
-		g.boardState.Phase = "finished"
-		winner := "Black"
-		if g.playerColor == 2 {
-			winner = "White"
-		}
-		g.boardState.Outcome = fmt.Sprintf("%s wins by resignation", winner)
-		outcome := g.boardState.Outcome
+		outcome := g.resign(engineColor)
 		g.mu.Unlock()
 
 		if g.endCallback != nil {
@@ -353,10 +840,13 @@ func (g *GTPEngine) triggerEngineMove() {
 	if response == "PASS" {
 		g.boardState.LastMove.X = -1
 		g.boardState.LastMove.Y = -1
+		g.boardState.BookMove = fromBook
+		g.boardState.LevelChange = levelChange
 		g.boardState.MoveNumber++
 		g.boardState.PlayerToMove = g.playerColor
 		g.passCount++
 		passCount := g.passCount
+		g.config.Book.Record(engineColor, -1, -1)
 
 		g.myTurn = true
 		boardStateCopy := g.copyBoardState()
@@ -369,14 +859,15 @@ func (g *GTPEngine) triggerEngineMove() {
 
 		// Check for double pass
 		if passCount >= 2 {
-			g.handleGameEnd()
+			g.endByPasses()
 		}
 		return
 	}
 
 	// Parse the move
 	x, y, err := gtpToPos(response, g.config.BoardSize)
-	if err != nil {
+	if err != nil || x < 0 {
+		debugLog.Printf("triggerEngineMove: can't read the engine's move %q: %v", response, err)
 		g.mu.Unlock()
 		return
 	}
@@ -385,9 +876,12 @@ func (g *GTPEngine) triggerEngineMove() {
 	g.boardState.Board[y][x] = engineColor
 	g.boardState.LastMove.X = x
 	g.boardState.LastMove.Y = y
+	g.boardState.BookMove = fromBook
+	g.boardState.LevelChange = levelChange
 	g.boardState.MoveNumber++
 	g.boardState.PlayerToMove = g.playerColor
 	g.passCount = 0
+	g.config.Book.Record(engineColor, x, y)
 
 	// Update captures
 	g.updateBoardFromGnuGo()
@@ -402,8 +896,66 @@ func (g *GTPEngine) triggerEngineMove() {
 	}
 }
 
+// governLevel tells the governor how long a genmove took, and sets the
+// level it asks for. It returns the new level, or 0 if it stays. Must be
+// called while holding the lock.
+func (g *GTPEngine) governLevel(took time.Duration) int {
+	if g.governor == nil {
+		return 0
+	}
+	level, changed := g.governor.Observe(took)
+	if !changed {
+		return 0
+	}
+	if _, err := g.sendCommand(fmt.Sprintf("level %d", level)); err != nil {
+		debugLog.Printf("governLevel: level %d refused: %v", level, err)
+		return 0
+	}
+	return level
+}
+
+// playBookMove plays the opening book's reply for color instead of asking
+// GnuGo. It returns the vertex played ("PASS" for a pass), or "" if the
+// book has nothing for this turn. Must be called while holding the lock.
+func (g *GTPEngine) playBookMove(color int) string {
+	x, y, ok := g.config.Book.Next(color)
+	if !ok {
+		return ""
+	}
+	vertex := "PASS"
+	if x >= 0 && y >= 0 {
+		vertex = posToGTP(x, y, g.config.BoardSize)
+	}
+	if _, err := g.sendCommand(fmt.Sprintf("play %s %s", colorToGTP(color), vertex)); err != nil {
+		debugLog.Printf("playBookMove: book move %s rejected: %v", vertex, err)
+		return ""
+	}
+	return vertex
+}
+
+// playReply plays the opening replies' answer for color to the position,
+// if they have one. Like playBookMove it returns the vertex played or "",
+// and a reply GnuGo rejects is dropped for genmove to take over. Must be
+// called while holding the lock.
+func (g *GTPEngine) playReply(color int) string {
+	x, y, ok := g.config.Replies.Reply(g.boardState.Board, color, g.boardState.MoveNumber)
+	if !ok {
+		return ""
+	}
+	vertex := posToGTP(x, y, g.config.BoardSize)
+	if _, err := g.sendCommand(fmt.Sprintf("play %s %s", colorToGTP(color), vertex)); err != nil {
+		debugLog.Printf("playReply: opening reply %s rejected: %v", vertex, err)
+		return ""
+	}
+	return vertex
+}
+
 // updateBoardFromGnuGo refreshes the board state by parsing GnuGo's showboard output.
 func (g *GTPEngine) updateBoardFromGnuGo() {
+	if g.localBoard {
+		g.updateFromTracker()
+		return
+	}
 	// Use list_stones to get accurate positions
 	blackStones, _ := g.sendCommand("list_stones black")
 	whiteStones, _ := g.sendCommand("list_stones white")
@@ -430,11 +982,154 @@ func (g *GTPEngine) updateBoardFromGnuGo() {
 			g.boardState.Board[y][x] = 2
 		}
 	}
+	g.boardState.Hash = sgf.ZobristHash(g.boardState.Board, g.config.BoardSize)
+
+	g.updateCaptures()
+}
+
+// updateCaptures reads how many stones each side has captured. GnuGo keeps
+// the count through undo and replay, so it is asked rather than worked out
+// from the board. Must be called while holding the lock.
+func (g *GTPEngine) updateCaptures() {
+	if g.localBoard {
+		g.updateFromTracker()
+		return
+	}
+	if resp, err := g.sendCommand("captures black"); err == nil {
+		g.boardState.CapturesBlack, _ = strconv.Atoi(strings.TrimSpace(resp))
+	}
+	if resp, err := g.sendCommand("captures white"); err == nil {
+		g.boardState.CapturesWhite, _ = strconv.Atoi(strings.TrimSpace(resp))
+	}
+}
+
+// updateFromTracker sets the board and captures from the moves sent to an
+// engine that can't list its stones. Must be called while holding the lock.
+func (g *GTPEngine) updateFromTracker() {
+	board, black, white := g.tracker.board(g.config.BoardSize, rules.ByName(g.config.Ruleset))
+	for y := range board {
+		copy(g.boardState.Board[y], board[y])
+	}
+	g.boardState.Hash = sgf.ZobristHash(g.boardState.Board, g.config.BoardSize)
+	g.boardState.CapturesBlack, g.boardState.CapturesWhite = black, white
+}
+
+// endByPasses follows two passes in a row: into the scoring phase when
+// someone is listening for it, otherwise straight to the engine's score.
+func (g *GTPEngine) endByPasses() {
+	if g.scoringCallback == nil {
+		g.handleGameEnd()
+		return
+	}
+
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return
+	}
+	g.scoring = true
+	g.myTurn = false
+	g.boardState.Phase = "scoring"
+	g.collectScoreDetails()
+	boardStateCopy := g.copyBoardState()
+	g.mu.Unlock()
+
+	g.scoringCallback(boardStateCopy)
+}
+
+// ConfirmScore ends a game in the scoring phase with dead as the dead
+// stones. If they are the engine's own, its score is used; otherwise the
+// board is counted here with them removed.
+func (g *GTPEngine) ConfirmScore(dead [][2]int) error {
+	g.mu.Lock()
+
+	if g.closed {
+		g.mu.Unlock()
+		return ErrClosed
+	}
+
+	if !g.scoring {
+		g.mu.Unlock()
+		return fmt.Errorf("not scoring")
+	}
+	g.scoring = false
+	g.gameOver = true
+	g.boardState.Phase = "finished"
+
+	outcome := ""
+	if sameStones(dead, g.boardState.DeadStones) {
+		if score, err := g.sendCommand("final_score"); err == nil {
+			outcome = score
+		}
+	}
+	if outcome == "" {
+		bs := g.boardState
+		outcome = rules.ByName(g.config.Ruleset).Score(bs.Board, dead, bs.CapturesBlack, bs.CapturesWhite, g.config.Komi).LocalResult()
+	}
+	g.boardState.DeadStones = append([][2]int(nil), dead...)
+	g.boardState.Outcome = outcome
+	g.mu.Unlock()
+
+	if g.endCallback != nil {
+		g.endCallback(outcome)
+	}
+	return nil
+}
+
+// ResumePlay leaves the scoring phase and continues the game. GTP has no
+// notion of the phase, so the side to move simply plays; if that is the
+// engine, it is asked for a move.
+func (g *GTPEngine) ResumePlay() error {
+	g.mu.Lock()
+
+	if g.closed {
+		g.mu.Unlock()
+		return ErrClosed
+	}
+
+	if !g.scoring {
+		g.mu.Unlock()
+		return fmt.Errorf("not scoring")
+	}
+	g.scoring = false
+	g.passCount = 0
+	g.boardState.Phase = "playing"
+	g.boardState.Scored = false
+	g.boardState.DeadStones = nil
+	g.myTurn = g.boardState.PlayerToMove == g.playerColor
+	myTurn := g.myTurn
+	g.mu.Unlock()
+
+	if !myTurn {
+		go g.triggerEngineMove()
+	}
+	return nil
+}
+
+// sameStones returns true if a and b hold the same points in any order.
+func sameStones(a, b [][2]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[[2]int]bool, len(a))
+	for _, p := range a {
+		set[p] = true
+	}
+	for _, p := range b {
+		if !set[p] {
+			return false
+		}
+	}
+	return true
 }
 
 // handleGameEnd calculates the final score and ends the game.
 func (g *GTPEngine) handleGameEnd() {
 	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return
+	}
 
 	g.gameOver = true
 	g.boardState.Phase = "finished"
@@ -445,6 +1140,7 @@ func (g *GTPEngine) handleGameEnd() {
 		g.boardState.Outcome = "Game ended"
 	} else {
 		g.boardState.Outcome = score
+		g.collectScoreDetails()
 	}
 
 	outcome := g.boardState.Outcome
@@ -456,40 +1152,77 @@ func (g *GTPEngine) handleGameEnd() {
 	}
 }
 
+// collectScoreDetails records captures and dead stones for the final result.
+// Must be called while holding the lock.
+func (g *GTPEngine) collectScoreDetails() {
+	g.boardState.Scored = true
+	g.updateCaptures()
+
+	g.boardState.DeadStones = nil
+	if dead, err := g.sendCommand("final_status_list dead"); err == nil {
+		g.boardState.DeadStones = parseVertices(dead, g.config.BoardSize)
+	}
+}
+
+// parseVertices reads a space-separated list of vertices, skipping any
+// that aren't points on the board.
+func parseVertices(resp string, size int) [][2]int {
+	var points [][2]int
+	for _, vertex := range strings.Fields(resp) {
+		x, y, err := gtpToPos(vertex, size)
+		if err == nil && x >= 0 && y >= 0 {
+			points = append(points, [2]int{x, y})
+		}
+	}
+	return points
+}
+
 // Undo undoes the last move (one ply) in GnuGo.
 func (g *GTPEngine) Undo() error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if g.closed {
+		return ErrClosed
+	}
 	if g.gameOver {
-		return fmt.Errorf("game is over")
+		return engine.ErrGameOver
+	}
+	if g.scoring {
+		return fmt.Errorf("counting the score; resume play first")
 	}
 	if g.boardState.MoveNumber == 0 {
 		return fmt.Errorf("no moves to undo")
 	}
 
+	// The side whose move is taken back plays again; without the move
+	// tracked, as after loadsgf, turns are taken to alternate
+	toMove := oppositeColor(g.boardState.PlayerToMove)
+	if n := len(g.tracker.moves); n > 0 {
+		toMove = g.tracker.moves[n-1][0]
+	}
+
 	if _, err := g.sendCommand("undo"); err != nil {
 		return fmt.Errorf("undo failed: %w", err)
 	}
 
 	g.boardState.MoveNumber--
-	g.passCount = 0
+	// The passes that now end the game count again, as after a stone
+	// taken back that followed a pass
+	g.passCount = g.tracker.passes()
+	g.config.Book.Undo(1)
 
 	// Resync board from GnuGo
 	g.updateBoardFromGnuGo()
 
-	// Toggle whose turn it is
-	if g.boardState.PlayerToMove == g.playerColor {
-		g.boardState.PlayerToMove = oppositeColor(g.playerColor)
-		g.myTurn = false
-	} else {
-		g.boardState.PlayerToMove = g.playerColor
-		g.myTurn = true
-	}
+	g.boardState.PlayerToMove = toMove
+	g.myTurn = toMove == g.playerColor
 
 	// Clear last move indicator (we don't know the previous last move)
 	g.boardState.LastMove.X = -1
 	g.boardState.LastMove.Y = -1
+	g.boardState.BookMove = false
+	g.boardState.LevelChange = 0
 
 	return nil
 }
@@ -500,34 +1233,36 @@ func (g *GTPEngine) ResetAndReplay(moves [][3]int) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if g.closed {
+		return ErrClosed
+	}
 	if _, err := g.sendCommand("clear_board"); err != nil {
 		return fmt.Errorf("clear_board failed: %w", err)
 	}
-
-	for _, m := range moves {
-		color := colorToGTP(m[0])
-		if m[1] == -1 && m[2] == -1 {
-			if _, err := g.sendCommand(fmt.Sprintf("play %s pass", color)); err != nil {
-				return fmt.Errorf("replay pass failed: %w", err)
-			}
-		} else {
-			vertex := posToGTP(m[1], m[2], g.config.BoardSize)
-			if _, err := g.sendCommand(fmt.Sprintf("play %s %s", color, vertex)); err != nil {
-				return fmt.Errorf("replay move failed: %w", err)
-			}
+	if len(g.handicap) > 0 {
+		if _, err := g.sendCommand("set_free_handicap " + strings.Join(g.handicap, " ")); err != nil {
+			return fmt.Errorf("failed to restore handicap stones: %w", err)
 		}
 	}
 
+	if err := g.replayMoves(moves, 0); err != nil {
+		return err
+	}
+
 	g.updateBoardFromGnuGo()
 	g.boardState.MoveNumber = len(moves)
-	g.passCount = 0
+	g.config.Book.Replay(moves)
+	g.passCount = g.tracker.passes()
 	g.gameOver = false
+	g.scoring = false
 	g.boardState.Phase = "playing"
 
 	// Determine whose turn it is
 	if len(moves) > 0 {
 		lastColor := moves[len(moves)-1][0]
 		g.boardState.PlayerToMove = oppositeColor(lastColor)
+	} else if len(g.handicap) > 0 {
+		g.boardState.PlayerToMove = 2 // white answers the handicap
 	} else {
 		g.boardState.PlayerToMove = 1 // black plays first
 	}
@@ -539,6 +1274,8 @@ func (g *GTPEngine) ResetAndReplay(moves [][3]int) error {
 	}
 
 	// Set last move indicator
+	g.boardState.BookMove = false
+	g.boardState.LevelChange = 0
 	if len(moves) > 0 {
 		last := moves[len(moves)-1]
 		g.boardState.LastMove.X = last[1]
@@ -548,16 +1285,71 @@ func (g *GTPEngine) ResetAndReplay(moves [][3]int) error {
 		g.boardState.LastMove.Y = -1
 	}
 
+	g.reportProgress(len(moves), len(moves))
 	return nil
 }
 
+// replayMoves plays moves onto the engine's board, numbering them from
+// base+1. The board state is refreshed and progress reported every
+// replayStep moves, and the replay stops with ErrClosed if Close is called.
+// Must be called with the lock held; the lock is released around each
+// progress callback. Leaves it not being anyone's turn.
+func (g *GTPEngine) replayMoves(moves [][3]int, base int) error {
+	g.replayMu.Lock()
+	defer g.replayMu.Unlock()
+
+	g.myTurn = false
+	g.reportProgress(0, len(moves))
+	for i, m := range moves {
+		select {
+		case <-g.closing:
+			return ErrClosed
+		default:
+		}
+
+		color := colorToGTP(m[0])
+		if m[1] == -1 && m[2] == -1 {
+			if _, err := g.sendCommand(fmt.Sprintf("play %s pass", color)); err != nil {
+				return fmt.Errorf("replay pass failed: %w", err)
+			}
+		} else {
+			vertex := posToGTP(m[1], m[2], g.config.BoardSize)
+			if _, err := g.sendCommand(fmt.Sprintf("play %s %s", color, vertex)); err != nil {
+				return fmt.Errorf("replay move failed: %w", err)
+			}
+		}
+
+		if done := i + 1; done%replayStep == 0 && done < len(moves) {
+			g.updateBoardFromGnuGo()
+			g.boardState.MoveNumber = base + done
+			g.boardState.LastMove.X = m[1]
+			g.boardState.LastMove.Y = m[2]
+			g.reportProgress(done, len(moves))
+		}
+	}
+	return nil
+}
+
+// reportProgress sends a board snapshot to the progress callback.
+// Must be called with the lock held; the callback runs without it.
+func (g *GTPEngine) reportProgress(done, total int) {
+	if g.progressCallback == nil {
+		return
+	}
+	callback := g.progressCallback
+	state := g.copyBoardState()
+	g.mu.Unlock()
+	callback(done, total, state)
+	g.mu.Lock()
+}
+
 // IsMyTurn returns true if it's the human player's turn.
 func (g *GTPEngine) IsMyTurn() bool {
 	debugLog.Printf("IsMyTurn: trying to acquire lock")
 	g.mu.Lock()
 	debugLog.Printf("IsMyTurn: lock acquired")
 	defer g.mu.Unlock()
-	result := g.myTurn && !g.gameOver
+	result := g.myTurn && !g.gameOver && !g.closed
 	debugLog.Printf("IsMyTurn: returning %v", result)
 	return result
 }
@@ -582,12 +1374,19 @@ func (g *GTPEngine) copyBoardState() *types.BoardState {
 		copy(boardCopy[i], g.boardState.Board[i])
 	}
 	return &types.BoardState{
-		MoveNumber:   g.boardState.MoveNumber,
-		PlayerToMove: g.boardState.PlayerToMove,
-		Phase:        g.boardState.Phase,
-		Board:        boardCopy,
-		Outcome:      g.boardState.Outcome,
-		LastMove:     g.boardState.LastMove,
+		MoveNumber:    g.boardState.MoveNumber,
+		PlayerToMove:  g.boardState.PlayerToMove,
+		Phase:         g.boardState.Phase,
+		Board:         boardCopy,
+		Outcome:       g.boardState.Outcome,
+		LastMove:      g.boardState.LastMove,
+		BookMove:      g.boardState.BookMove,
+		LevelChange:   g.boardState.LevelChange,
+		Hash:          g.boardState.Hash,
+		Scored:        g.boardState.Scored,
+		CapturesBlack: g.boardState.CapturesBlack,
+		CapturesWhite: g.boardState.CapturesWhite,
+		DeadStones:    append([][2]int(nil), g.boardState.DeadStones...),
 	}
 }
 
@@ -596,12 +1395,62 @@ func (g *GTPEngine) OnGameEnd(callback func(outcome string)) {
 	g.endCallback = callback
 }
 
-// Close shuts down the GnuGo subprocess.
+// OnScoring registers a callback for when both sides have passed. Without
+// one, two passes end the game with the engine's score.
+func (g *GTPEngine) OnScoring(callback func(boardState *types.BoardState)) {
+	g.scoringCallback = callback
+}
+
+// OnProgress registers a callback for replay progress.
+func (g *GTPEngine) OnProgress(callback func(done, total int, boardState *types.BoardState)) {
+	g.progressCallback = callback
+}
+
+// Close shuts down the GnuGo subprocess. A replay in progress is
+// cancelled first, and a move the engine is thinking about is given
+// closeGrace to finish before the process is killed; either way the move
+// is dropped without a callback. From then on every method returns
+// ErrClosed. Closing twice is harmless.
 func (g *GTPEngine) Close() {
+	g.closeOnce.Do(func() { close(g.closing) })
+
+	// A replay stops at its next move, so wait it out before taking the
+	// lock; one stuck in a command is killed with the rest below.
+	locked := make(chan struct{})
+	go func() {
+		g.replayMu.Lock()
+		g.replayMu.Unlock()
+		g.mu.Lock()
+		close(locked)
+	}()
+	killed := false
+	select {
+	case <-locked:
+	case <-time.After(closeGrace):
+		// Still in genmove, or a replay's move: GnuGo won't read quit
+		// until it has answered. Killing it makes the pending read fail,
+		// which releases the lock.
+		debugLog.Printf("Close: engine busy after %v, killing it", closeGrace)
+		if g.cmd != nil && g.cmd.Process != nil {
+			killProcess(g.cmd)
+			killed = true
+		}
+		<-locked
+	}
+	if g.closed {
+		g.mu.Unlock()
+		return
+	}
+	g.closed = true
+	g.myTurn = false
 	if g.stdin != nil {
-		g.sendCommand("quit")
+		if !killed {
+			g.sendCommand("quit")
+		}
 		g.stdin.Close()
 	}
+	g.mu.Unlock()
+
 	if g.cmd != nil && g.cmd.Process != nil {
 		g.cmd.Wait()
 	}
@@ -0,0 +1,87 @@
+package gtp_test
+
+import (
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+func newHandicapEngine(playerColor, handicap int) *gtp.GTPEngine {
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.EnginePath = fakeEnginePath()
+	cfg.PlayerColor = playerColor
+	cfg.Handicap = handicap
+	return gtp.NewGTPEngine(cfg)
+}
+
+func TestHandicapHumanBlack(t *testing.T) {
+	eng := newHandicapEngine(1, 3)
+	moved := make(chan [3]int, 1)
+	eng.OnMove(func(x, y, color int, bs *types.BoardState) {
+		moved <- [3]int{color, x, y}
+	})
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+
+	stones := eng.HandicapStones()
+	if len(stones) != 3 {
+		t.Fatalf("HandicapStones = %v, want 3", stones)
+	}
+	// With a handicap White moves first, so the engine plays without being asked.
+	select {
+	case m := <-moved:
+		if m[0] != 2 {
+			t.Errorf("first move %v, want White", m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for White's first move")
+	}
+	for i := 0; i < 100 && !eng.IsMyTurn(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	bs := eng.GetBoardState()
+	for _, p := range stones {
+		if bs.Board[p[1]][p[0]] != 1 {
+			t.Errorf("no black stone at handicap point %v", p)
+		}
+	}
+	if bs.MoveNumber != 1 || bs.PlayerToMove != 1 {
+		t.Errorf("after White's reply: move %d, to play %d", bs.MoveNumber, bs.PlayerToMove)
+	}
+}
+
+func TestHandicapHumanWhite(t *testing.T) {
+	eng := newHandicapEngine(2, 2)
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+
+	if !eng.IsMyTurn() {
+		t.Fatal("the human's White should move first after the engine's handicap")
+	}
+	bs := eng.GetBoardState()
+	if bs.PlayerToMove != 2 || bs.MoveNumber != 0 {
+		t.Errorf("start: to play %d, move %d", bs.PlayerToMove, bs.MoveNumber)
+	}
+
+	// Replaying from scratch keeps the stones and White's turn.
+	if err := eng.ResetAndReplay(nil); err != nil {
+		t.Fatalf("ResetAndReplay: %v", err)
+	}
+	bs = eng.GetBoardState()
+	for _, p := range eng.HandicapStones() {
+		if bs.Board[p[1]][p[0]] != 1 {
+			t.Errorf("handicap stone at %v lost by ResetAndReplay", p)
+		}
+	}
+	if !eng.IsMyTurn() || bs.PlayerToMove != 2 {
+		t.Errorf("after ResetAndReplay: my turn %v, to play %d", eng.IsMyTurn(), bs.PlayerToMove)
+	}
+}
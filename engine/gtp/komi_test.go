@@ -0,0 +1,73 @@
+package gtp_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+)
+
+func TestKomiSentVerbatim(t *testing.T) {
+	for _, tc := range []struct {
+		komi float64
+		sent string
+	}{
+		{0, "komi 0\n"},
+		{0.5, "komi 0.5\n"},
+		{6.5, "komi 6.5\n"},
+		{7, "komi 7\n"},
+		{6.25, "komi 6.25\n"},
+	} {
+		log := filepath.Join(t.TempDir(), "gtp.log")
+		t.Setenv(fakeLogEnv, log)
+		cfg := engine.DefaultConfig()
+		cfg.BoardSize = 9
+		cfg.Komi = tc.komi
+		cfg.EnginePath = fakeEnginePath()
+		eng := gtp.NewGTPEngine(cfg)
+		if err := eng.Connect(); err != nil {
+			t.Fatalf("Connect with komi %v: %v", tc.komi, err)
+		}
+		eng.Close()
+
+		data, err := os.ReadFile(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sent := string(data); !strings.Contains(sent, tc.sent) || !strings.Contains(sent, "get_komi\n") {
+			t.Errorf("komi %v: engine not sent %q and get_komi; got:\n%s", tc.komi, tc.sent, sent)
+		}
+	}
+}
+
+// An engine that rounds the komi it's given is caught by get_komi, and the
+// game goes on with a note rather than counting differently unremarked.
+func TestKomiRounded(t *testing.T) {
+	t.Setenv(fakeTenthsEnv, "1")
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.EnginePath = fakeEnginePath()
+
+	cfg.Komi = 6.5
+	eng := gtp.NewGTPEngine(cfg)
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect with komi 6.5: %v", err)
+	}
+	if note := eng.KomiNote(); note != "" {
+		t.Errorf("komi 6.5 taken as given, but noted %q", note)
+	}
+	eng.Close()
+
+	cfg.Komi = 6.25
+	eng = gtp.NewGTPEngine(cfg)
+	defer eng.Close()
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect with komi 6.25 to a rounding engine: %v", err)
+	}
+	if note := eng.KomiNote(); !strings.Contains(note, "komi 6.2, not 6.25") {
+		t.Errorf("komi 6.25 to a rounding engine noted %q", note)
+	}
+}
@@ -0,0 +1,67 @@
+package gtp_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/sgf"
+)
+
+// Records whose moves don't alternate replay with each move's own color,
+// and the engine moves for the side after the last mover, not by parity.
+func TestLoadIrregularRecord(t *testing.T) {
+	for _, tc := range []struct {
+		file   string
+		player int
+		sent   []string
+	}{
+		// B W B B: White to play, the engine as White
+		{"double-black.sgf", 1, []string{"play black G3\nplay black C3\n", "genmove white\n"}},
+		// W B W: Black to play, the engine as Black
+		{"white-first.sgf", 2, []string{"play white E5\nplay black C7\nplay white G3\n", "genmove black\n"}},
+	} {
+		path := filepath.Join("..", "..", "sgf", "testdata", "irregular", tc.file)
+		game, err := sgf.ParseHeader(path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.file, err)
+		}
+		moves, err := sgf.ParseMovesAsEntries(path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.file, err)
+		}
+
+		log := filepath.Join(t.TempDir(), "gtp.log")
+		t.Setenv(fakeLogEnv, log)
+		cfg := engine.DefaultConfig()
+		cfg.BoardSize = 9
+		cfg.PlayerColor = tc.player
+		cfg.EnginePath = fakeEnginePath()
+		cfg.LoadSGFPath = path
+		cfg.LoadMoveCount = game.MoveCount
+		cfg.LoadNextColor = game.NextColor
+		cfg.LoadMoves = moves
+		eng := gtp.NewGTPEngine(cfg)
+		if err := eng.Connect(); err != nil {
+			t.Fatalf("%s: Connect: %v", tc.file, err)
+		}
+		waitTurn(t, eng)
+		if bs := eng.GetBoardState(); bs.PlayerToMove != tc.player {
+			t.Errorf("%s: %d to move after the engine's reply, want %d", tc.file, bs.PlayerToMove, tc.player)
+		}
+		eng.Close()
+
+		data, err := os.ReadFile(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tc.sent {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("%s: engine not sent %q; got:\n%s", tc.file, want, data)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package gtp_test
+
+import (
+	"errors"
+	"testing"
+
+	"termsuji-local/engine"
+)
+
+// A move the engine turns down is illegal; an engine that went away has
+// said nothing about the move.
+func TestPlayMoveErrors(t *testing.T) {
+	eng := connectFake(t)
+	defer eng.Close()
+	if err := eng.PlayMove(4, 4); err != nil {
+		t.Fatalf("PlayMove: %v", err)
+	}
+	waitTurn(t, eng)
+	if err := eng.PlayMove(4, 4); !errors.Is(err, engine.ErrIllegalMove) {
+		t.Errorf("play on a stone: %v, want ErrIllegalMove", err)
+	}
+
+	t.Setenv(fakeHangupEnv, "1")
+	gone := connectFake(t)
+	defer gone.Close()
+	err := gone.PlayMove(4, 4)
+	if err == nil || errors.Is(err, engine.ErrIllegalMove) {
+		t.Errorf("play to an engine that hung up: %v, want the broken pipe", err)
+	}
+}
@@ -0,0 +1,11 @@
+package gtp
+
+import "os/exec"
+
+// ownProcessGroup is a no-op: Windows has no process groups to signal.
+func ownProcessGroup(cmd *exec.Cmd) {}
+
+// killProcess kills cmd's process.
+func killProcess(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
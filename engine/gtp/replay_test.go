@@ -0,0 +1,94 @@
+package gtp_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+// replayMoves returns n alternating moves filling a 9x9 board row by row.
+func replayMoves(n int) [][3]int {
+	moves := make([][3]int, n)
+	for i := range moves {
+		moves[i] = [3]int{i%2 + 1, i % 9, i / 9}
+	}
+	return moves
+}
+
+func connectFake(t *testing.T) *gtp.GTPEngine {
+	t.Helper()
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.EnginePath = fakeEnginePath()
+	eng := gtp.NewGTPEngine(cfg)
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	return eng
+}
+
+func TestResetAndReplayProgress(t *testing.T) {
+	eng := connectFake(t)
+	defer eng.Close()
+
+	var reports [][2]int
+	eng.OnProgress(func(done, total int, bs *types.BoardState) {
+		reports = append(reports, [2]int{done, total})
+		if done == 20 && (bs.MoveNumber != 20 || bs.Board[2][1] != 2) {
+			t.Errorf("snapshot at 20: move %d, stone at 1,2 = %d", bs.MoveNumber, bs.Board[2][1])
+		}
+		if done < total && eng.IsMyTurn() { // would deadlock if the lock were held
+			t.Error("human's turn in the middle of a replay")
+		}
+	})
+
+	if err := eng.ResetAndReplay(replayMoves(25)); err != nil {
+		t.Fatalf("ResetAndReplay: %v", err)
+	}
+	want := [][2]int{{0, 25}, {10, 25}, {20, 25}, {25, 25}}
+	if len(reports) != len(want) {
+		t.Fatalf("progress reports = %v, want %v", reports, want)
+	}
+	for i := range want {
+		if reports[i] != want[i] {
+			t.Errorf("report %d = %v, want %v", i, reports[i], want[i])
+		}
+	}
+	if bs := eng.GetBoardState(); bs.MoveNumber != 25 || bs.Board[2][6] != 1 {
+		t.Errorf("final board: move %d, stone at 6,2 = %d", bs.MoveNumber, bs.Board[2][6])
+	}
+}
+
+func TestCloseCancelsReplay(t *testing.T) {
+	eng := connectFake(t)
+
+	closed := make(chan struct{})
+	last := 0
+	eng.OnProgress(func(done, total int, bs *types.BoardState) {
+		last = done
+		if done == 10 {
+			go func() {
+				eng.Close()
+				close(closed)
+			}()
+			time.Sleep(100 * time.Millisecond) // let Close signal before the replay resumes
+		}
+	})
+
+	err := eng.ResetAndReplay(replayMoves(60))
+	if !errors.Is(err, gtp.ErrClosed) {
+		t.Fatalf("ResetAndReplay after Close = %v, want ErrClosed", err)
+	}
+	if last != 10 {
+		t.Errorf("last progress = %d, want the replay to stop after 10", last)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return after cancelling the replay")
+	}
+}
@@ -0,0 +1,51 @@
+package gtp_test
+
+import (
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+func TestOpeningReplies(t *testing.T) {
+	cfg := engine.DefaultConfig()
+	cfg.EnginePath = fakeEnginePath()
+	cfg.Replies = engine.OpeningReplies()
+	eng := gtp.NewGTPEngine(cfg)
+	moved := make(chan *types.BoardState, 4)
+	eng.OnMove(func(x, y, color int, bs *types.BoardState) {
+		if color == 2 {
+			moved <- bs
+		}
+	})
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+
+	reply := func(x, y int) *types.BoardState {
+		t.Helper()
+		if err := eng.PlayMove(x, y); err != nil {
+			t.Fatalf("PlayMove: %v", err)
+		}
+		select {
+		case bs := <-moved:
+			return bs
+		case <-time.After(5 * time.Second):
+			t.Fatal("no reply")
+			return nil
+		}
+	}
+
+	// D4 is in the book: White answers Q16 without asking the engine,
+	// which would take the first empty point.
+	if bs := reply(3, 15); bs.LastMove.X != 15 || bs.LastMove.Y != 3 || !bs.BookMove {
+		t.Errorf("reply to D4 at %d,%d, book %v; want Q16 from the book", bs.LastMove.X, bs.LastMove.Y, bs.BookMove)
+	}
+	// Off the book, the engine plays as usual.
+	if bs := reply(9, 9); bs.LastMove.X != 0 || bs.LastMove.Y != 0 || bs.BookMove {
+		t.Errorf("reply off book at %d,%d, book %v; want the engine's A19", bs.LastMove.X, bs.LastMove.Y, bs.BookMove)
+	}
+}
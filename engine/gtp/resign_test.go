@@ -0,0 +1,51 @@
+package gtp_test
+
+import (
+	"errors"
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+)
+
+func TestResign(t *testing.T) {
+	for _, tc := range []struct {
+		player int
+		want   string
+	}{
+		{1, "White wins by resignation"},
+		{2, "Black wins by resignation"},
+	} {
+		cfg := engine.DefaultConfig()
+		cfg.BoardSize = 9
+		cfg.EnginePath = fakeEnginePath()
+		cfg.PlayerColor = tc.player
+		eng := gtp.NewGTPEngine(cfg)
+		ended := make(chan string, 1)
+		eng.OnGameEnd(func(outcome string) { ended <- outcome })
+		if err := eng.Connect(); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+
+		if err := eng.Resign(); err != nil {
+			t.Fatalf("Resign as %d: %v", tc.player, err)
+		}
+		if got := <-ended; got != tc.want {
+			t.Errorf("resigning as %d: outcome %q, want %q", tc.player, got, tc.want)
+		}
+		bs := eng.GetBoardState()
+		if bs.Phase != "finished" || bs.Outcome != tc.want {
+			t.Errorf("board state after resigning: phase %q outcome %q", bs.Phase, bs.Outcome)
+		}
+		if err := eng.Resign(); err == nil {
+			t.Error("resigning a finished game should fail")
+		}
+		if err := eng.Pass(); err == nil {
+			t.Error("passing after resigning should fail")
+		}
+		if err := eng.PlayMove(4, 4); !errors.Is(err, engine.ErrGameOver) {
+			t.Errorf("playing after resigning: %v, want ErrGameOver", err)
+		}
+		eng.Close()
+	}
+}
@@ -0,0 +1,54 @@
+package gtp_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+)
+
+// GnuGo is started with the rules the game is played by.
+func TestRulesFlag(t *testing.T) {
+	for _, tc := range []struct {
+		ruleset string
+		flag    string
+	}{
+		{"", "--japanese-rules"},
+		{"Japanese", "--japanese-rules"},
+		{"Chinese", "--chinese-rules"},
+		{"New Zealand", "--chinese-rules"}, // GnuGo's nearest: area counting
+	} {
+		args := filepath.Join(t.TempDir(), "args")
+		t.Setenv(fakeArgsEnv, args)
+		cfg := engine.DefaultConfig()
+		cfg.BoardSize = 9
+		cfg.Ruleset = tc.ruleset
+		cfg.EnginePath = fakeEnginePath()
+		eng := gtp.NewGTPEngine(cfg)
+		if err := eng.Connect(); err != nil {
+			t.Fatalf("Connect with %q rules: %v", tc.ruleset, err)
+		}
+		eng.Close()
+
+		data, err := os.ReadFile(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Fields(string(data)); !contains(got, tc.flag) {
+			t.Errorf("%q rules: started with %q, want %s", tc.ruleset, got, tc.flag)
+		}
+	}
+}
+
+// contains reports whether args has arg.
+func contains(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
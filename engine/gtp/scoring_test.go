@@ -0,0 +1,114 @@
+package gtp_test
+
+import (
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+func newScoringEngine(t *testing.T) *gtp.GTPEngine {
+	t.Helper()
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.EnginePath = fakeEnginePath()
+	return gtp.NewGTPEngine(cfg)
+}
+
+func waitTurn(t *testing.T, eng *gtp.GTPEngine) {
+	t.Helper()
+	for i := 0; i < 500 && !eng.IsMyTurn(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !eng.IsMyTurn() {
+		t.Fatal("timed out waiting for the turn")
+	}
+}
+
+func TestScoringPhase(t *testing.T) {
+	eng := newScoringEngine(t)
+	scoring := make(chan *types.BoardState, 1)
+	ended := make(chan string, 1)
+	eng.OnScoring(func(bs *types.BoardState) { scoring <- bs })
+	eng.OnGameEnd(func(outcome string) { ended <- outcome })
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+
+	waitScoring := func() *types.BoardState {
+		t.Helper()
+		select {
+		case bs := <-scoring:
+			return bs
+		case <-time.After(5 * time.Second):
+			t.Fatal("no scoring phase after two passes")
+			return nil
+		}
+	}
+
+	if err := eng.PlayMove(4, 4); err != nil {
+		t.Fatalf("PlayMove: %v", err)
+	}
+	waitTurn(t, eng) // White answers at 0,0
+	if err := eng.Pass(); err != nil {
+		t.Fatalf("Pass: %v", err)
+	}
+	if bs := waitScoring(); bs.Phase != "scoring" {
+		t.Errorf("phase %q after two passes, want scoring", bs.Phase)
+	}
+	if eng.IsMyTurn() {
+		t.Error("no moves while scoring")
+	}
+
+	// Back to play: White passed last, so it's Black's move.
+	if err := eng.ResumePlay(); err != nil {
+		t.Fatalf("ResumePlay: %v", err)
+	}
+	if !eng.IsMyTurn() || eng.GetBoardState().Phase != "playing" {
+		t.Fatalf("after ResumePlay: my turn %v, phase %q", eng.IsMyTurn(), eng.GetBoardState().Phase)
+	}
+	if err := eng.Pass(); err != nil {
+		t.Fatalf("Pass: %v", err)
+	}
+	waitScoring()
+
+	// Marking White's corner stone dead makes the board Black's.
+	if err := eng.ConfirmScore([][2]int{{0, 0}}); err != nil {
+		t.Fatalf("ConfirmScore: %v", err)
+	}
+	if got := <-ended; got != "B+74.5" { // 80 points and a prisoner, less 6.5 komi
+		t.Errorf("outcome %q, want B+74.5", got)
+	}
+	bs := eng.GetBoardState()
+	if !bs.Finished() || len(bs.DeadStones) != 1 {
+		t.Errorf("after ConfirmScore: phase %q, dead %v", bs.Phase, bs.DeadStones)
+	}
+	if err := eng.ResumePlay(); err == nil {
+		t.Error("ResumePlay after the game ended should fail")
+	}
+}
+
+func TestDoublePassWithoutScoring(t *testing.T) {
+	eng := newScoringEngine(t)
+	ended := make(chan string, 1)
+	eng.OnGameEnd(func(outcome string) { ended <- outcome })
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+
+	if err := eng.Pass(); err != nil {
+		t.Fatalf("Pass: %v", err)
+	}
+	select {
+	case got := <-ended:
+		if got != "W+0.5" {
+			t.Errorf("outcome %q, want the engine's W+0.5", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("two passes should end the game straight away")
+	}
+}
@@ -0,0 +1,53 @@
+package gtp_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+)
+
+func TestTimeControls(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "gtp.log")
+	t.Setenv(fakeLogEnv, log)
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.PlayerColor = 2 // the engine opens, with its clock full
+	cfg.EnginePath = fakeEnginePath()
+	cfg.MainTime = 10 * time.Minute
+	cfg.ByoYomiTime = 30 * time.Second
+	cfg.ByoYomiStones = 1
+	eng := gtp.NewGTPEngine(cfg)
+	ended := make(chan string, 1)
+	eng.OnGameEnd(func(outcome string) { ended <- outcome })
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+	waitTurn(t, eng)
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := string(data)
+	for _, want := range []string{"time_settings 600 30 1\n", "time_left black 600 0\ngenmove black\n"} {
+		if !strings.Contains(sent, want) {
+			t.Errorf("engine not sent %q; got:\n%s", want, sent)
+		}
+	}
+
+	if err := eng.LoseOnTime(); err != nil {
+		t.Fatalf("LoseOnTime: %v", err)
+	}
+	if got := <-ended; got != "Black wins by time" {
+		t.Errorf("outcome %q, want Black wins by time", got)
+	}
+	if err := eng.PlayMove(4, 4); err == nil {
+		t.Error("playing on after losing on time should fail")
+	}
+}
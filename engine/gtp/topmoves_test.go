@@ -0,0 +1,95 @@
+package gtp_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+)
+
+func TestTopMoves(t *testing.T) {
+	eng := connectFake(t)
+	defer eng.Close()
+
+	got, err := eng.TopMoves()
+	if err != nil {
+		t.Fatalf("TopMoves: %v", err)
+	}
+	want := []engine.Candidate{{X: 0, Y: 0, Value: 9.5}, {X: 1, Y: 0, Value: 7.5}, {X: 2, Y: 0, Value: 5.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopMoves = %v, want %v", got, want)
+	}
+
+	// The query leaves the game as it was: the player still moves, and
+	// the engine's reply takes the next free point.
+	if err := eng.PlayMove(0, 0); err != nil {
+		t.Fatalf("PlayMove: %v", err)
+	}
+	for i := 0; i < 100 && !eng.IsMyTurn(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if bs := eng.GetBoardState(); bs.MoveNumber != 2 || bs.Board[0][1] != 2 {
+		t.Fatalf("after one move each: move %d, stone at 1,0 = %d", bs.MoveNumber, bs.Board[0][1])
+	}
+	got, err = eng.TopMoves()
+	if err != nil {
+		t.Fatalf("TopMoves: %v", err)
+	}
+	if len(got) == 0 || got[0].X != 2 || got[0].Y != 0 {
+		t.Errorf("TopMoves after two moves = %v, want C9 first", got)
+	}
+
+	if err := eng.Resign(); err != nil {
+		t.Fatalf("Resign: %v", err)
+	}
+	if _, err := eng.TopMoves(); err == nil {
+		t.Error("TopMoves after the game ended should fail")
+	}
+}
+
+// TopMovesAt loads the saved game up to the position asked for, through a
+// path without spaces, and asks for the side to move there.
+func TestTopMovesAt(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "gtp.log")
+	t.Setenv(fakeLogEnv, log)
+	eng := connectFake(t)
+	defer eng.Close()
+
+	src := filepath.Join("..", "..", "sgf", "testdata", "irregular", "white-first.sgf")
+	spaced := filepath.Join(t.TempDir(), "Application Support", "game.sgf")
+	if err := os.MkdirAll(filepath.Dir(spaced), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(spaced, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := eng.TopMovesAt(spaced, 3)
+	if err != nil {
+		t.Fatalf("TopMovesAt: %v", err)
+	}
+	want := []engine.Candidate{{X: 0, Y: 0, Value: 9.5}, {X: 1, Y: 0, Value: 7.5}, {X: 2, Y: 0, Value: 5.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopMovesAt = %v, want %v", got, want)
+	}
+	eng.Close()
+
+	sent, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sent), " 4\ntop_moves_white\n") || strings.Contains(string(sent), "Application Support") {
+		t.Errorf("want loadsgf of a safe path until move 4, then White's top moves; got:\n%s", sent)
+	}
+	if _, err := eng.TopMovesAt(spaced, 0); err == nil {
+		t.Error("TopMovesAt after Close should fail")
+	}
+}
@@ -0,0 +1,99 @@
+package gtp
+
+import (
+	"errors"
+	"strings"
+
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+)
+
+// moveTracker follows the stones and moves sent to the engine, so the
+// board can be worked out here for engines that can't list their stones:
+// list_stones and captures are GnuGo extensions that KataGo, Pachi and
+// Leela Zero lack. It can't follow loadsgf.
+type moveTracker struct {
+	setup [][2]int // handicap stones, [x, y]
+	moves [][3]int // color, x, y since clear_board; -1, -1 for a pass
+}
+
+// observe updates the tracker after the engine accepted cmd with resp.
+func (t *moveTracker) observe(cmd, resp string, size int) {
+	f := strings.Fields(cmd)
+	if len(f) == 0 {
+		return
+	}
+	switch strings.ToLower(f[0]) {
+	case "clear_board", "boardsize":
+		t.setup, t.moves = nil, nil
+	case "play":
+		if len(f) == 3 {
+			t.add(f[1], f[2], size)
+		}
+	case "genmove":
+		if len(f) == 2 {
+			t.add(f[1], resp, size)
+		}
+	case "undo":
+		if len(t.moves) > 0 {
+			t.moves = t.moves[:len(t.moves)-1]
+		}
+	case "fixed_handicap", "place_free_handicap":
+		t.addSetup(strings.Fields(resp), size)
+	case "set_free_handicap":
+		t.addSetup(f[1:], size)
+	}
+}
+
+// add records a move; a resignation, or a vertex that can't be read, is
+// not one.
+func (t *moveTracker) add(color, vertex string, size int) {
+	x, y, err := gtpToPos(vertex, size)
+	if err != nil || x < -1 {
+		return
+	}
+	t.moves = append(t.moves, [3]int{gtpToColor(color), x, y})
+}
+
+// addSetup records black stones put down before the first move.
+func (t *moveTracker) addSetup(vertices []string, size int) {
+	for _, v := range vertices {
+		if x, y, err := gtpToPos(v, size); err == nil && x >= 0 {
+			t.setup = append(t.setup, [2]int{x, y})
+		}
+	}
+}
+
+// passes counts the passes at the end of the moves, since the last stone.
+func (t *moveTracker) passes() int {
+	n := 0
+	for i := len(t.moves) - 1; i >= 0 && t.moves[i][1] < 0; i-- {
+		n++
+	}
+	return n
+}
+
+// board plays the moves out from the setup stones by rs, returning the
+// position and how many stones each side has captured.
+func (t *moveTracker) board(size int, rs rules.Ruleset) (board [][]int, capturesBlack, capturesWhite int) {
+	setup := sgf.MakeBoard(size)
+	for _, p := range t.setup {
+		setup[p[1]][p[0]] = 1
+	}
+	pos := rules.NewPosition(setup)
+	for _, m := range t.moves {
+		color, x, y := m[0], m[1], m[2]
+		if x < 0 {
+			pos.Pass()
+			continue
+		}
+		_, err := rs.ApplyMove(pos, x, y, color)
+		if err != nil && !errors.Is(err, rules.ErrOffBoard) && !errors.Is(err, rules.ErrOccupied) {
+			// The engine took it, so it counts ko or suicide its own
+			// way; the stone goes down all the same
+			pos.Board[y][x] = color
+			pos.Captures[color] += sgf.RemoveCaptures(pos.Board, size, x, y, color)
+		}
+	}
+	return pos.Board, pos.Captures[1], pos.Captures[2]
+}
@@ -0,0 +1,68 @@
+package gtp_test
+
+import (
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+// An engine without GnuGo's list_stones and captures, answering in
+// lowercase, still gets a board, captures and a pass.
+func TestEngineWithoutListStones(t *testing.T) {
+	t.Setenv(fakeKnowsEnv, "boardsize clear_board play genmove undo komi quit")
+	t.Setenv(fakeLooseEnv, "1")
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.EngineName = "Fake"
+	cfg.EnginePath = fakeEnginePath()
+	cfg.EngineArgs = []string{}
+	eng := gtp.NewGTPEngine(cfg)
+	scoring := make(chan *types.BoardState, 1)
+	eng.OnScoring(func(bs *types.BoardState) { scoring <- bs })
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+	if eng.CanEstimate() {
+		t.Error("CanEstimate without final_status_list")
+	}
+
+	// White answers at A9, then C9; Black's A8 takes the A9 stone.
+	for _, p := range [][2]int{{4, 4}, {1, 0}} {
+		if err := eng.PlayMove(p[0], p[1]); err != nil {
+			t.Fatalf("PlayMove %v: %v", p, err)
+		}
+		waitTurn(t, eng)
+	}
+	if bs := eng.GetBoardState(); bs.Board[0][0] != 2 || bs.Board[0][2] != 2 {
+		t.Fatalf("White's stones missing: A9 %d, C9 %d", bs.Board[0][0], bs.Board[0][2])
+	}
+	if err := eng.PlayMove(0, 1); err != nil {
+		t.Fatalf("PlayMove: %v", err)
+	}
+	waitTurn(t, eng)
+	bs := eng.GetBoardState()
+	if bs.Board[0][0] != 0 || bs.CapturesBlack != 1 {
+		t.Errorf("after A8: A9 %d, Black captured %d; want it taken", bs.Board[0][0], bs.CapturesBlack)
+	}
+	if bs.Hash == 0 || bs.Hash != sgf.ZobristHash(bs.Board, 9) {
+		t.Errorf("hash %x doesn't match the board", bs.Hash)
+	}
+
+	// The engine's lowercase "pass" after ours ends play.
+	if err := eng.Pass(); err != nil {
+		t.Fatalf("Pass: %v", err)
+	}
+	select {
+	case bs := <-scoring:
+		if bs.Phase != "scoring" {
+			t.Errorf("phase %q after two passes, want scoring", bs.Phase)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("engine's pass wasn't read")
+	}
+}
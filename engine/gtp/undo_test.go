@@ -0,0 +1,110 @@
+package gtp_test
+
+import (
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/engine/gtp"
+	"termsuji-local/types"
+)
+
+// Playing White, undoing the engine's reply and the player's move leaves
+// the engine's opening move and the player to answer it.
+func TestUndoAsWhite(t *testing.T) {
+	cfg := engine.DefaultConfig()
+	cfg.BoardSize = 9
+	cfg.PlayerColor = 2
+	cfg.EnginePath = fakeEnginePath()
+	eng := gtp.NewGTPEngine(cfg)
+	if err := eng.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer eng.Close()
+	waitTurn(t, eng) // Black opens at A9
+
+	if err := eng.PlayMove(4, 4); err != nil {
+		t.Fatalf("PlayMove: %v", err)
+	}
+	waitTurn(t, eng) // and answers at B9
+	for i := 0; i < 2; i++ {
+		if err := eng.Undo(); err != nil {
+			t.Fatalf("Undo %d: %v", i+1, err)
+		}
+	}
+
+	bs := eng.GetBoardState()
+	if !eng.IsMyTurn() || bs.PlayerToMove != 2 || bs.MoveNumber != 1 {
+		t.Errorf("after undoing two: my turn %v, %d to move, move %d; want White to answer move 1",
+			eng.IsMyTurn(), bs.PlayerToMove, bs.MoveNumber)
+	}
+	if bs.Board[0][0] != 1 || bs.Board[4][4] != 0 || bs.Board[0][1] != 0 {
+		t.Errorf("board after undo: A9 %d, E5 %d, B9 %d", bs.Board[0][0], bs.Board[4][4], bs.Board[0][1])
+	}
+}
+
+// Undo gives the turn back to whoever played the move taken back, even
+// when the moves didn't alternate.
+func TestUndoTurnFollowsMoves(t *testing.T) {
+	eng := connectFake(t)
+	defer eng.Close()
+	if err := eng.ResetAndReplay([][3]int{{1, 4, 4}, {2, 0, 0}, {2, 1, 0}}); err != nil {
+		t.Fatalf("ResetAndReplay: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := eng.Undo(); err != nil {
+			t.Fatalf("Undo %d: %v", i+1, err)
+		}
+		if bs := eng.GetBoardState(); bs.PlayerToMove != 2 || eng.IsMyTurn() {
+			t.Errorf("after undo %d: %d to move, my turn %v; want White's", i+1, bs.PlayerToMove, eng.IsMyTurn())
+		}
+	}
+}
+
+// Taking back the moves after the engine passed leaves that pass standing:
+// the player's pass then ends the game, without asking the engine to move.
+func TestUndoBackToAPass(t *testing.T) {
+	t.Setenv(fakeSlowEnv, "2s") // a genmove would leave the game playing
+	eng := connectFake(t)
+	defer eng.Close()
+	scoring := make(chan struct{}, 1)
+	eng.OnScoring(func(*types.BoardState) { scoring <- struct{}{} })
+
+	// Black E5, White passes, Black A9, White B9
+	if err := eng.ResetAndReplay([][3]int{{1, 4, 4}, {2, -1, -1}, {1, 0, 0}, {2, 1, 0}}); err != nil {
+		t.Fatalf("ResetAndReplay: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := eng.Undo(); err != nil {
+			t.Fatalf("Undo %d: %v", i+1, err)
+		}
+	}
+	if err := eng.Pass(); err != nil {
+		t.Fatalf("Pass: %v", err)
+	}
+	select {
+	case <-scoring:
+	default:
+		t.Errorf("phase %q after passing back, want scoring", eng.GetBoardState().Phase)
+	}
+}
+
+// A replay that ends in a pass counts it.
+func TestReplayEndingInAPass(t *testing.T) {
+	t.Setenv(fakeSlowEnv, "2s")
+	eng := connectFake(t)
+	defer eng.Close()
+	scoring := make(chan struct{}, 1)
+	eng.OnScoring(func(*types.BoardState) { scoring <- struct{}{} })
+
+	if err := eng.ResetAndReplay([][3]int{{1, 4, 4}, {2, -1, -1}}); err != nil {
+		t.Fatalf("ResetAndReplay: %v", err)
+	}
+	if err := eng.Pass(); err != nil {
+		t.Fatalf("Pass: %v", err)
+	}
+	select {
+	case <-scoring:
+	default:
+		t.Errorf("phase %q after a pass to the replayed one, want scoring", eng.GetBoardState().Phase)
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"math/rand"
+
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+)
+
+// Naive is the built-in engine, for games that have to run without GnuGo:
+// it plays a random legal move that doesn't fill one of its own eyes, and
+// passes when none is left. The seed decides every choice, so the same
+// seed plays the same game.
+type Naive struct {
+	rng   *rand.Rand
+	rules rules.Ruleset
+}
+
+// NewNaive returns a naive engine playing by ruleset, its choices drawn
+// from seed.
+func NewNaive(seed int64, ruleset rules.Ruleset) *Naive {
+	return &Naive{rng: rand.New(rand.NewSource(seed)), rules: ruleset}
+}
+
+// GenMove picks color's move in pos; ok is false for a pass.
+func (n *Naive) GenMove(pos *rules.Position, color int) (x, y int, ok bool) {
+	var moves [][2]int
+	for y := range pos.Board {
+		for x := range pos.Board[y] {
+			if pos.Board[y][x] == 0 && !ownEye(pos.Board, x, y, color) && n.rules.IsLegal(pos, x, y, color) {
+				moves = append(moves, [2]int{x, y})
+			}
+		}
+	}
+	if len(moves) == 0 {
+		return -1, -1, false
+	}
+	p := moves[n.rng.Intn(len(moves))]
+	return p[0], p[1], true
+}
+
+// ownEye returns true if every point next to x, y is color's.
+func ownEye(board [][]int, x, y, color int) bool {
+	size := len(board)
+	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		nx, ny := x+d[0], y+d[1]
+		if nx >= 0 && nx < size && ny >= 0 && ny < size && board[ny][nx] != color {
+			return false
+		}
+	}
+	return true
+}
+
+// SelfPlay plays a game of the naive engine against itself on an empty
+// board of size, until both sides pass or the board has been filled three
+// times over. It returns the moves as [color, x, y], a pass at -1, -1,
+// and the count of the final board with every stone alive.
+func SelfPlay(size int, seed int64, ruleset rules.Ruleset, komi float64) ([][3]int, sgf.ScoreBreakdown) {
+	n := NewNaive(seed, ruleset)
+	pos := rules.NewPosition(sgf.MakeBoard(size))
+	var moves [][3]int
+	color, passes := 1, 0
+	for passes < 2 && len(moves) < 3*size*size {
+		x, y, ok := n.GenMove(pos, color)
+		if ok {
+			_, err := ruleset.ApplyMove(pos, x, y, color)
+			ok = err == nil
+		}
+		if ok {
+			passes = 0
+		} else {
+			x, y = -1, -1
+			pos.Pass()
+			passes++
+		}
+		moves = append(moves, [3]int{color, x, y})
+		color = 3 - color
+	}
+	return moves, ruleset.Score(pos.Board, nil, pos.Captures[1], pos.Captures[2], komi)
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"termsuji-local/rules"
+)
+
+func TestSelfPlaySeed(t *testing.T) {
+	moves, score := SelfPlay(9, 42, rules.Japanese, 6.5)
+	again, _ := SelfPlay(9, 42, rules.Japanese, 6.5)
+	if !reflect.DeepEqual(moves, again) {
+		t.Fatal("the same seed played two different games")
+	}
+	other, _ := SelfPlay(9, 43, rules.Japanese, 6.5)
+	if reflect.DeepEqual(moves, other) {
+		t.Error("another seed played the same game")
+	}
+
+	n := len(moves)
+	if n < 2 || moves[n-1][1] != -1 || moves[n-2][1] != -1 {
+		t.Errorf("game of %d moves didn't end in two passes", n)
+	}
+	if score.LocalResult() == "" || score.Komi != 6.5 {
+		t.Errorf("score %+v", score)
+	}
+}
+
+func TestNaiveKeepsItsEyes(t *testing.T) {
+	// Black's only empty points are its own eyes, so it passes
+	board := [][]int{
+		{0, 1, 0},
+		{1, 1, 1},
+		{0, 1, 0},
+	}
+	n := NewNaive(1, rules.Japanese)
+	if x, y, ok := n.GenMove(rules.NewPosition(board), 1); ok {
+		t.Errorf("Black filled its eye at %d,%d", x, y)
+	}
+	if _, _, ok := n.GenMove(rules.NewPosition(board), 2); ok {
+		t.Error("White played a suicide")
+	}
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"termsuji-local/coords"
+)
+
+// replyDepth is how many moves into a game the opening replies are used.
+const replyDepth = 12
+
+// Replies is a small book of standard opening replies, looked up by
+// position. Positions are compared up to rotation and reflection, so one
+// orientation of each line covers all eight.
+//
+// All methods are safe on a nil *Replies, which has no replies.
+type Replies struct {
+	replies map[string][2]int // canonical position and side to move -> reply, in the same orientation
+}
+
+//go:embed openings.txt
+var openings string
+
+var (
+	openingReplies     *Replies
+	openingRepliesOnce sync.Once
+)
+
+// OpeningReplies returns the replies built into termsuji.
+func OpeningReplies() *Replies {
+	openingRepliesOnce.Do(func() {
+		r, err := ParseReplies(strings.NewReader(openings))
+		if err != nil {
+			panic("engine: built-in opening replies: " + err.Error())
+		}
+		openingReplies = r
+	})
+	return openingReplies
+}
+
+// ParseReplies reads replies in the format of openings.txt: lines of a
+// board size followed by GTP vertices, Black first, with # comments.
+func ParseReplies(r io.Reader) (*Replies, error) {
+	book := &Replies{replies: map[string][2]int{}}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		f := strings.Fields(scanner.Text())
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		size, err := strconv.Atoi(f[0])
+		if err != nil || size < 2 || size > 25 {
+			return nil, fmt.Errorf("line %d: bad board size %q", n, f[0])
+		}
+		board := make([][]int, size)
+		for y := range board {
+			board[y] = make([]int, size)
+		}
+		color := 1
+		for _, vertex := range f[1:] {
+			x, y, err := coords.FromGTP(vertex, size)
+			if err != nil || x < 0 || y < 0 {
+				return nil, fmt.Errorf("line %d: bad move %q", n, vertex)
+			}
+			if board[y][x] != 0 {
+				return nil, fmt.Errorf("line %d: %s is already taken", n, vertex)
+			}
+			key, sym := canonical(board, color)
+			if _, ok := book.replies[key]; !ok {
+				cx, cy := sym.apply(x, y, size)
+				book.replies[key] = [2]int{cx, cy}
+			}
+			board[y][x] = color
+			color = 3 - color
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// Len returns the number of positions with a reply.
+func (r *Replies) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.replies)
+}
+
+// Reply returns the book's answer for color to play on board, which is
+// move moveNumber (counting from 0) of the game. There is none past the
+// opening, or for a position the book doesn't know.
+func (r *Replies) Reply(board [][]int, color, moveNumber int) (x, y int, ok bool) {
+	if r == nil || moveNumber >= replyDepth {
+		return 0, 0, false
+	}
+	key, sym := canonical(board, color)
+	reply, ok := r.replies[key]
+	if !ok {
+		return 0, 0, false
+	}
+	x, y = sym.invert(reply[0], reply[1], len(board))
+	if board[y][x] != 0 {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// symmetry is one of the eight ways a square board maps onto itself: an
+// optional swap of x and y, then optional flips of each axis.
+type symmetry struct {
+	swap, flipX, flipY bool
+}
+
+// symmetries lists all eight, the identity first.
+var symmetries = func() []symmetry {
+	var syms []symmetry
+	for _, swap := range []bool{false, true} {
+		for _, flipX := range []bool{false, true} {
+			for _, flipY := range []bool{false, true} {
+				syms = append(syms, symmetry{swap, flipX, flipY})
+			}
+		}
+	}
+	return syms
+}()
+
+// apply maps x, y on a size board to where the symmetry takes it.
+func (s symmetry) apply(x, y, size int) (int, int) {
+	if s.swap {
+		x, y = y, x
+	}
+	if s.flipX {
+		x = size - 1 - x
+	}
+	if s.flipY {
+		y = size - 1 - y
+	}
+	return x, y
+}
+
+// invert undoes apply.
+func (s symmetry) invert(x, y, size int) (int, int) {
+	if s.flipX {
+		x = size - 1 - x
+	}
+	if s.flipY {
+		y = size - 1 - y
+	}
+	if s.swap {
+		x, y = y, x
+	}
+	return x, y
+}
+
+// canonical returns the key shared by board and all its rotations and
+// reflections with color to play, and the symmetry that turns board into
+// the orientation the key describes.
+func canonical(board [][]int, color int) (string, symmetry) {
+	size := len(board)
+	var best string
+	var bestSym symmetry
+	cells := make([]byte, size*size+1)
+	for i, sym := range symmetries {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				tx, ty := sym.apply(x, y, size)
+				cells[ty*size+tx] = ".XO"[board[y][x]]
+			}
+		}
+		cells[size*size] = "-BW"[color]
+		if key := string(cells); i == 0 || key < best {
+			best, bestSym = key, sym
+		}
+	}
+	return best, bestSym
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"termsuji-local/coords"
+)
+
+func emptyBoard(size int) [][]int {
+	b := make([][]int, size)
+	for y := range b {
+		b[y] = make([]int, size)
+	}
+	return b
+}
+
+func TestSymmetryInvert(t *testing.T) {
+	for _, sym := range symmetries {
+		for _, p := range [][2]int{{0, 0}, {3, 15}, {18, 2}, {9, 9}} {
+			x, y := sym.apply(p[0], p[1], 19)
+			if bx, by := sym.invert(x, y, 19); bx != p[0] || by != p[1] {
+				t.Errorf("%+v: %v -> %d,%d -> %d,%d", sym, p, x, y, bx, by)
+			}
+		}
+	}
+}
+
+func TestCanonicalSymmetry(t *testing.T) {
+	board := emptyBoard(9)
+	board[2][6], board[6][3] = 1, 2 // G7 black, D3 white
+	want, _ := canonical(board, 1)
+
+	for _, sym := range symmetries {
+		turned := emptyBoard(9)
+		for y := range board {
+			for x := range board[y] {
+				tx, ty := sym.apply(x, y, 9)
+				turned[ty][tx] = board[y][x]
+			}
+		}
+		if got, _ := canonical(turned, 1); got != want {
+			t.Errorf("%+v: key differs from the original's", sym)
+		}
+	}
+	if other, _ := canonical(board, 2); other == want {
+		t.Error("the side to move should be part of the key")
+	}
+}
+
+func TestRepliesBySymmetry(t *testing.T) {
+	book, err := ParseReplies(strings.NewReader("# test\n19 Q16 D4 R4\n"))
+	if err != nil {
+		t.Fatalf("ParseReplies: %v", err)
+	}
+	if book.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", book.Len())
+	}
+
+	// Black's opening move in the lower left instead of the upper right:
+	// the answer is turned the same way.
+	board := emptyBoard(19)
+	x, y, _ := coords.FromGTP("D4", 19)
+	board[y][x] = 1
+	rx, ry, ok := book.Reply(board, 2, 1)
+	if got := coords.ToGTP(rx, ry, 19); !ok || got != "Q16" {
+		t.Errorf("reply to D4 = %s, %v; want Q16", got, ok)
+	}
+
+	// Past the opening, or with the other color to move, there's no reply.
+	if _, _, ok := book.Reply(board, 2, replyDepth); ok {
+		t.Error("no replies after the opening")
+	}
+	if _, _, ok := book.Reply(board, 1, 1); ok {
+		t.Error("no reply for the wrong side to move")
+	}
+	var none *Replies
+	if _, _, ok := none.Reply(board, 2, 1); ok {
+		t.Error("a nil book has no replies")
+	}
+}
+
+func TestParseRepliesErrors(t *testing.T) {
+	for _, in := range []string{
+		"nineteen Q16",
+		"19 Q16 Q16",
+		"9 Q16",
+		"19 Q16 pass",
+	} {
+		if _, err := ParseReplies(strings.NewReader(in)); err == nil {
+			t.Errorf("ParseReplies(%q) should fail", in)
+		}
+	}
+}
+
+func TestOpeningReplies(t *testing.T) {
+	book := OpeningReplies()
+	if book.Len() == 0 {
+		t.Fatal("no built-in replies")
+	}
+	x, y, ok := book.Reply(emptyBoard(19), 1, 0)
+	if got := coords.ToGTP(x, y, 19); !ok || got != "Q16" {
+		t.Errorf("opening move = %s, %v", got, ok)
+	}
+}
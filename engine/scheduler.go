@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDebounce is how long the position must stay put before it is
+// evaluated, so scrubbing through a game doesn't evaluate every step.
+const DefaultDebounce = 300 * time.Millisecond
+
+// Clock schedules the scheduler's timers; tests substitute a fake.
+type Clock interface {
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending AfterFunc call.
+type Timer interface {
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// Scheduler coalesces evaluation requests (estimates, analysis) for an
+// engine that can only do one at a time. Only the latest requested
+// position is evaluated, once no newer request has arrived for the
+// debounce delay. At most one request waits while an evaluation runs;
+// a newer one replaces it.
+//
+// Each request gets a generation number. Evaluations may finish after
+// the position has moved on, so callers check Current before showing a
+// result.
+type Scheduler struct {
+	clock    Clock
+	delay    time.Duration
+	evaluate func(gen uint64, moves [][3]int)
+
+	mu      sync.Mutex
+	gen     uint64
+	pending [][3]int // latest requested position, waiting to be evaluated
+	waiting bool     // pending holds a request
+	due     bool     // the debounce delay has passed for it
+	running bool     // an evaluation is in progress
+	timer   Timer
+	stopped bool
+}
+
+// NewScheduler creates a scheduler that calls evaluate for each position
+// that survives the debounce delay. evaluate runs on a timer goroutine,
+// never concurrently with itself. A nil clock uses real time.
+func NewScheduler(delay time.Duration, clock Clock, evaluate func(gen uint64, moves [][3]int)) *Scheduler {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &Scheduler{clock: clock, delay: delay, evaluate: evaluate}
+}
+
+// Request asks for the position after moves to be evaluated, superseding
+// any earlier request. It returns the request's generation.
+func (s *Scheduler) Request(moves [][3]int) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return s.gen
+	}
+
+	s.gen++
+	s.pending, s.waiting, s.due = moves, true, false
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	gen := s.gen
+	s.timer = s.clock.AfterFunc(s.delay, func() { s.fire(gen) })
+	return gen
+}
+
+// Current returns true if gen is the latest request, i.e. its result is
+// still wanted.
+func (s *Scheduler) Current(gen uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return gen == s.gen && !s.stopped
+}
+
+// Stop drops any waiting request and refuses new ones. An evaluation
+// already running finishes, but its result is no longer Current.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	s.waiting = false
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
+
+// Cancel drops any waiting request and makes the result of one running
+// no longer Current, as when the position has changed under it. Unlike
+// Stop, later requests are still taken.
+func (s *Scheduler) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	s.waiting = false
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
+
+// fire runs when request gen has waited out the delay.
+func (s *Scheduler) fire(gen uint64) {
+	s.mu.Lock()
+	if gen != s.gen || !s.waiting || s.stopped {
+		s.mu.Unlock()
+		return // superseded; its own timer will fire
+	}
+	s.due = true
+	if s.running {
+		// The running evaluation picks it up when it finishes.
+		s.mu.Unlock()
+		return
+	}
+
+	s.running = true
+	for s.waiting && s.due && !s.stopped {
+		moves, gen := s.pending, s.gen
+		s.pending, s.waiting, s.due = nil, false, false
+		s.mu.Unlock()
+		s.evaluate(gen, moves)
+		s.mu.Lock()
+	}
+	s.running = false
+	s.mu.Unlock()
+}
@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+// fakeClock fires timers when Advance moves time past them.
+type fakeClock struct {
+	now    time.Duration
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Duration
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	was := !t.stopped
+	t.stopped = true
+	return was
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{at: c.now + d, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves time forward by d, running due timers in order. Timers may
+// schedule or advance further; the callback runs without any clock state held.
+func (c *fakeClock) Advance(d time.Duration) {
+	end := c.now + d
+	for {
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at < c.timers[j].at })
+		if len(c.timers) == 0 || c.timers[0].at > end {
+			break
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		if t.stopped {
+			continue
+		}
+		c.now = t.at
+		t.stopped = true
+		t.f()
+	}
+	if end > c.now {
+		c.now = end
+	}
+}
+
+// position returns a distinct move list for request n.
+func position(n int) [][3]int {
+	return [][3]int{{1, n, 0}}
+}
+
+func TestSchedulerDebounce(t *testing.T) {
+	clock := &fakeClock{}
+	var evaluated []uint64
+	s := NewScheduler(DefaultDebounce, clock, func(gen uint64, moves [][3]int) {
+		evaluated = append(evaluated, gen)
+		if moves[0][1] != 5 {
+			t.Errorf("evaluated position %v, want the last one requested", moves)
+		}
+	})
+
+	// Scrubbing: a step every 100ms never leaves the position alone long enough.
+	for i := 1; i <= 5; i++ {
+		s.Request(position(i))
+		clock.Advance(100 * time.Millisecond)
+	}
+	if len(evaluated) != 0 {
+		t.Fatalf("evaluated %v while scrubbing", evaluated)
+	}
+
+	clock.Advance(200 * time.Millisecond)
+	if len(evaluated) != 1 || evaluated[0] != 5 {
+		t.Fatalf("evaluated %v after the pause, want only generation 5", evaluated)
+	}
+
+	clock.Advance(time.Second)
+	if len(evaluated) != 1 {
+		t.Errorf("evaluated %v, want no repeat", evaluated)
+	}
+}
+
+func TestSchedulerStaleResult(t *testing.T) {
+	clock := &fakeClock{}
+	var shown []uint64
+	var s *Scheduler
+	s = NewScheduler(DefaultDebounce, clock, func(gen uint64, moves [][3]int) {
+		if gen == 1 {
+			// The user moves on while the engine is thinking.
+			s.Request(position(2))
+		}
+		if s.Current(gen) {
+			shown = append(shown, gen)
+		}
+	})
+
+	s.Request(position(1))
+	clock.Advance(DefaultDebounce)
+	if len(shown) != 0 {
+		t.Fatalf("showed %v; generation 1 was stale when it finished", shown)
+	}
+	clock.Advance(DefaultDebounce)
+	if len(shown) != 1 || shown[0] != 2 {
+		t.Errorf("showed %v, want generation 2", shown)
+	}
+}
+
+func TestSchedulerOnePending(t *testing.T) {
+	clock := &fakeClock{}
+	var evaluated []uint64
+	running := false
+	var s *Scheduler
+	s = NewScheduler(DefaultDebounce, clock, func(gen uint64, moves [][3]int) {
+		if running {
+			t.Error("evaluations overlapped")
+		}
+		running = true
+		evaluated = append(evaluated, gen)
+		if gen == 1 {
+			// Two more positions settle while the first is still being evaluated.
+			s.Request(position(2))
+			clock.Advance(DefaultDebounce)
+			s.Request(position(3))
+			clock.Advance(DefaultDebounce)
+		}
+		running = false
+	})
+
+	s.Request(position(1))
+	clock.Advance(DefaultDebounce)
+	if len(evaluated) != 2 || evaluated[0] != 1 || evaluated[1] != 3 {
+		t.Errorf("evaluated %v, want 1 then only the latest waiting request, 3", evaluated)
+	}
+}
+
+func TestSchedulerStop(t *testing.T) {
+	clock := &fakeClock{}
+	calls := 0
+	s := NewScheduler(DefaultDebounce, clock, func(gen uint64, moves [][3]int) { calls++ })
+
+	gen := s.Request(position(1))
+	s.Stop()
+	clock.Advance(time.Second)
+	s.Request(position(2))
+	clock.Advance(time.Second)
+	if calls != 0 {
+		t.Errorf("%d evaluations after Stop", calls)
+	}
+	if s.Current(gen) {
+		t.Error("nothing is current after Stop")
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	clock := &fakeClock{}
+	var evaluated []uint64
+	s := NewScheduler(DefaultDebounce, clock, func(gen uint64, moves [][3]int) { evaluated = append(evaluated, gen) })
+
+	gen := s.Request(position(1))
+	s.Cancel()
+	clock.Advance(time.Second)
+	if len(evaluated) != 0 || s.Current(gen) {
+		t.Fatalf("evaluated %v after Cancel, request current %v", evaluated, s.Current(gen))
+	}
+
+	gen = s.Request(position(2))
+	clock.Advance(DefaultDebounce)
+	if len(evaluated) != 1 || evaluated[0] != gen || !s.Current(gen) {
+		t.Errorf("evaluated %v, want the request after Cancel, %d", evaluated, gen)
+	}
+}
@@ -0,0 +1,43 @@
+package engine
+
+import "fmt"
+
+// HandicapKomi is the komi conventionally used in handicap games: White
+// still gets half a point so there are no draws.
+const HandicapKomi = 0.5
+
+// Colors as GameConfig.PlayerColor gives them.
+const (
+	Black = 1
+	White = 2
+)
+
+// SetupCheck is the result of checking a color/handicap/komi combination
+// before a game starts.
+type SetupCheck struct {
+	SuggestedKomi       float64  // komi to use; equals the input when it is fine
+	KomiMismatch        bool     // komi doesn't suit the handicap; keeping it needs confirmation
+	EngineTakesHandicap bool     // the human is White, so the engine gets the stones
+	Notes               []string // explanations to show on the setup card
+}
+
+// CheckSetup validates a game setup. Handicap stones are always Black's, so
+// with the human as White they go to the engine, and a handicap game with
+// full komi compensates White twice.
+func CheckSetup(playerColor, handicap int, komi float64) SetupCheck {
+	check := SetupCheck{SuggestedKomi: komi}
+	if handicap < 2 {
+		return check
+	}
+
+	if komi != HandicapKomi {
+		check.SuggestedKomi = HandicapKomi
+		check.KomiMismatch = true
+		check.Notes = append(check.Notes, fmt.Sprintf("komi %.1f with H%d: handicap games use %.1f", komi, handicap, HandicapKomi))
+	}
+	if playerColor == White {
+		check.EngineTakesHandicap = true
+		check.Notes = append(check.Notes, "you play White: the engine takes the stones")
+	}
+	return check
+}
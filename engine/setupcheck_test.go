@@ -0,0 +1,43 @@
+package engine
+
+import "testing"
+
+func TestCheckSetup(t *testing.T) {
+	tests := []struct {
+		name        string
+		color       int
+		handicap    int
+		komi        float64
+		wantKomi    float64
+		wantConfirm bool
+		wantEngine  bool
+		wantNotes   int
+	}{
+		{"even black", 1, 0, 6.5, 6.5, false, false, 0},
+		{"even white", 2, 0, 7.5, 7.5, false, false, 0},
+		{"even no komi", 1, 0, 0, 0, false, false, 0},
+		{"H2 black proper komi", 1, 2, 0.5, 0.5, false, false, 0},
+		{"H4 black full komi", 1, 4, 6.5, 0.5, true, false, 1},
+		{"H4 black zero komi", 1, 4, 0, 0.5, true, false, 1},
+		{"H9 white proper komi", 2, 9, 0.5, 0.5, false, true, 1},
+		{"H3 white full komi", 2, 3, 6.5, 0.5, true, true, 2},
+		{"H1 treated as even", 1, 1, 6.5, 6.5, false, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckSetup(tt.color, tt.handicap, tt.komi)
+			if got.SuggestedKomi != tt.wantKomi {
+				t.Errorf("SuggestedKomi = %.1f, want %.1f", got.SuggestedKomi, tt.wantKomi)
+			}
+			if got.KomiMismatch != tt.wantConfirm {
+				t.Errorf("KomiMismatch = %v, want %v", got.KomiMismatch, tt.wantConfirm)
+			}
+			if got.EngineTakesHandicap != tt.wantEngine {
+				t.Errorf("EngineTakesHandicap = %v, want %v", got.EngineTakesHandicap, tt.wantEngine)
+			}
+			if len(got.Notes) != tt.wantNotes {
+				t.Errorf("Notes = %q, want %d", got.Notes, tt.wantNotes)
+			}
+		})
+	}
+}
@@ -5,13 +5,13 @@ go 1.18
 require (
 	github.com/adrg/xdg v0.4.0
 	github.com/gdamore/tcell/v2 v2.5.2
+	github.com/mattn/go-runewidth v0.0.13
 	github.com/rivo/tview v0.0.0-20220805210617-37ad0bb93703
 )
 
 require (
 	github.com/gdamore/encoding v1.0.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/rivo/uniseg v0.3.4 // indirect
 	golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f // indirect
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
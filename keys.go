@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"termsuji-local/ui"
+)
+
+// runKeys implements "termsuji-local keys [-width n]": it prints every key
+// binding as a plain-text cheat sheet, for printing.
+func runKeys(args []string) int {
+	fs := flag.NewFlagSet("keys", flag.ExitOnError)
+	width := fs.Int("width", ui.KeysWidth, "Wrap the sheet to this many columns")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: termsuji-local keys [-width n]")
+		fmt.Fprintln(fs.Output(), "Prints the keyboard shortcuts, grouped by screen, as plain text.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	fmt.Print(ui.FormatKeys(ui.Keymap(), *width))
+	return 0
+}
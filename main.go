@@ -2,28 +2,33 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
-	"os/exec"
 	"runtime"
 	"strings"
 
-	"github.com/gdamore/tcell/v2"
-	"github.com/rivo/tview"
-
+	"termsuji-local/app"
 	"termsuji-local/config"
 	"termsuji-local/engine"
 	"termsuji-local/engine/gtp"
+	"termsuji-local/server"
 	"termsuji-local/sgf"
 	"termsuji-local/ui"
 )
 
-// Version is set at build time via ldflags
-var Version = "dev"
+// Version, Commit and BuildDate are set at build time via ldflags
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// debugLogPath is where the GTP traffic of the current session is logged.
+const debugLogPath = "/tmp/termsuji-debug.log"
 
 // Command-line flags
 var (
@@ -31,28 +36,45 @@ var (
 	flagColor      = flag.String("color", "", "Player color (black or white)")
 	flagDifficulty = flag.Int("difficulty", 0, "GnuGo difficulty level (1-10)")
 	flagKomi       = flag.Float64("komi", -1, "Komi value")
+	flagHandicap   = flag.Int("handicap", 0, "Handicap stones for Black (2-9)")
 	flagQuickStart = flag.Bool("play", false, "Start game immediately with defaults")
 	flagFocus      = flag.Bool("focus", false, "Start in focus mode (fullscreen board)")
 	flagVersion    = flag.Bool("version", false, "Print version and exit")
 	flagUpdate     = flag.Bool("update", false, "Update to the latest version")
+	flagNoColor    = flag.Bool("no-color", false, "Disable colors (also enabled by the NO_COLOR env var)")
+	flagBook       = flag.String("book", "", "SGF whose main line both sides follow as an opening book")
+	flagHTTP       = flag.String("http", "", "Serve the move log over HTTP on this address (e.g. 127.0.0.1:7777)")
+	flagLoad       = flag.String("load", "", "Open an SGF file from anywhere for review")
+	flagContinue   = flag.Bool("continue", false, "Continue the last unfinished game")
+	flagNoRecord   = flag.Bool("no-record", false, "Don't record games as SGF this session")
+	flagSeed       = flag.Int64("seed", 0, "Random seed for GnuGo, so the same moves get the same replies")
 )
 
-var app *tview.Application
-var rootPage *tview.Pages
-var gameBoard *ui.GoBoardUI
-var gameFrame *tview.Flex
-var gameHint *tview.TextView
-var cfg *config.Config
-
 func main() {
 	flag.Parse()
+	sgf.SetAppVersion(Version)
+
+	// Handle the verify subcommand
+	if flag.Arg(0) == "verify" {
+		os.Exit(runVerify(flag.Args()[1:]))
+	}
+
+	// Handle the keys subcommand
+	if flag.Arg(0) == "keys" {
+		os.Exit(runKeys(flag.Args()[1:]))
+	}
+
+	// Handle the selfplay subcommand
+	if flag.Arg(0) == "selfplay" {
+		os.Exit(runSelfplay(flag.Args()[1:]))
+	}
 
 	// Handle --version
 	if *flagVersion {
-		latest, err := getLatestVersion()
+		rel, err := app.LatestRelease()
 		if err != nil {
 			fmt.Printf("termsuji-local %s\n", Version)
-		} else if latest != Version && Version != "dev" {
+		} else if latest := rel.TagName; latest != Version && Version != "dev" {
 			fmt.Printf("termsuji-local %s (update available: %s)\n", Version, latest)
 			fmt.Println("Run 'termsuji-local --update' to update")
 		} else {
@@ -70,17 +92,19 @@ func main() {
 		return
 	}
 
-	var err error
-	cfg, err = config.InitConfig()
+	_, hadConfig := config.ConfigPath()
+	cfg, err := config.InitConfig()
 	if err != nil {
 		panic(err)
 	}
+	// Write the defaults on first run, so the file is there to edit and
+	// saving colors later doesn't create it unexpectedly
+	if !hadConfig {
+		cfg.Save()
+	}
 
-	// Always use the default theme (lines theme) on startup
-	cfg.Theme = config.DefaultTheme
-
-	// Check if GnuGo is available
-	if err := checkGnuGo(); err != nil {
+	// Check if GnuGo is available, unless other engines are configured
+	if _, err := app.GnuGoPath(cfg); err != nil && len(cfg.Engines) == 0 {
 		fmt.Println("Error: GnuGo not found.")
 		fmt.Println("Please install GnuGo:")
 		fmt.Println("  macOS:  brew install gnu-go")
@@ -89,333 +113,68 @@ func main() {
 		return
 	}
 
-	// Check if quick start requested
-	quickStart := *flagQuickStart || *flagBoardSize > 0 || *flagColor != "" || *flagDifficulty > 0 || *flagKomi >= 0 || *flagFocus
-
-	app = tview.NewApplication()
-	rootPage = tview.NewPages()
-	rootPage.SetBorder(true).SetTitle(" ⬡ termsuji ")
-
-	// Draw "f to toggle" on the bottom border when in focus mode
-	rootPage.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
-		if gameBoard != nil && gameBoard.IsFocusMode() {
-			title := " f to toggle "
-			titleX := x + (width-len(title))/2
-			titleY := y + height - 1 // bottom border line
-			for i, r := range title {
-				screen.SetContent(titleX+i, titleY, r, nil, tcell.StyleDefault)
-			}
-		}
-		return x, y, width, height
-	})
-
-	// Game view setup - compact horizontal status bar
-	gameHint = tview.NewTextView()
-	gameHint.SetBorder(false)
-	gameHint.SetDynamicColors(true)
-	gameBoard = ui.NewGoBoard(app, cfg, gameHint)
-
-	// Create game layout with centered board and side panel
-	gameFrame = ui.CreateGameLayout(gameBoard, gameHint)
-
-	// Game board input handling
-	gameBoard.Box.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyRune && event.Rune() == 'q' {
-			if gameBoard.SelectedTile() != nil {
-				gameBoard.ResetSelection()
-			} else {
-				gameBoard.Close()
-				rootPage.SwitchToPage("setup")
-			}
-			return nil
-		}
-		switch event.Key() {
-		case tcell.KeyUp:
-			gameBoard.MoveSelection(0, -1)
-		case tcell.KeyDown:
-			gameBoard.MoveSelection(0, 1)
-		case tcell.KeyLeft:
-			gameBoard.MoveSelection(-1, 0)
-		case tcell.KeyRight:
-			gameBoard.MoveSelection(1, 0)
-		case tcell.KeyEnter:
-			selTile := gameBoard.SelectedTile()
-			if selTile == nil {
-				return nil
-			}
-			gameBoard.PlayMove(selTile.X, selTile.Y)
-		case tcell.KeyRune:
-			switch event.Rune() {
-			case 'h':
-				gameBoard.MoveSelection(-1, 0)
-			case 'j':
-				gameBoard.MoveSelection(0, 1)
-			case 'k':
-				gameBoard.MoveSelection(0, -1)
-			case 'l':
-				gameBoard.MoveSelection(1, 0)
-			case 'p':
-				gameBoard.Pass()
-			case 'u':
-				gameBoard.UndoMove()
-			case 'r':
-				gameBoard.ToggleRecording(cfg)
-			case 'f':
-				if gameBoard.ToggleFocusMode() {
-					ui.BuildFocusLayout(gameFrame, gameBoard)
-				} else {
-					ui.RebuildNormalLayout(gameFrame, gameBoard, gameHint)
-				}
-			case 'a':
-				gameBoard.TogglePlanningMode()
-			case 'A':
-				gameBoard.ResumeFromPlan()
-			case '[':
-				if gameBoard.IsPlanningMode() {
-					gameBoard.PlanBack()
-				}
-			case ']':
-				if gameBoard.IsPlanningMode() {
-					gameBoard.PlanForward()
-				}
-			case '{':
-				if gameBoard.IsPlanningMode() {
-					gameBoard.PlanPrevVariation()
-				}
-			case '}':
-				if gameBoard.IsPlanningMode() {
-					gameBoard.PlanNextVariation()
-				}
-			}
-		}
-		return event
-	})
-
-	// History browser screen
-	historyBrowser := ui.NewHistoryBrowser(func() {
-		rootPage.SwitchToPage("setup")
-	}, func(game sgf.GameInfo) {
-		loadGame(game)
+	if f, err := os.Create(debugLogPath); err == nil {
+		gtp.SetDebugLog(f)
+		sgf.SetDebugLog(f)
+		ui.SetDebugLog(f)
+	}
+
+	a, err := app.New(cfg, app.Options{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		DebugLog:  debugLogPath,
+
+		FirstRun:  !hadConfig,
+		NoColor:   *flagNoColor || os.Getenv("NO_COLOR") != "",
+		ColorFGBG: os.Getenv("COLORFGBG"),
+		NoUTF8:    !config.UTF8Locale(os.Getenv("LC_ALL"), os.Getenv("LC_CTYPE"), os.Getenv("LANG")),
+
+		Play:       *flagQuickStart,
+		BoardSize:  *flagBoardSize,
+		Color:      *flagColor,
+		Difficulty: *flagDifficulty,
+		Komi:       *flagKomi,
+		Handicap:   *flagHandicap,
+		Focus:      *flagFocus,
+
+		Book:     *flagBook,
+		Load:     *flagLoad,
+		Continue: *flagContinue,
+		NoRecord: *flagNoRecord,
+		Seed:     *flagSeed,
+	}, func(gameCfg engine.GameConfig) engine.GameEngine {
+		return gtp.NewGTPEngine(gameCfg)
 	})
-
-	// Game setup screen
-	setupUI := ui.NewGameSetup(
-		func(gameCfg engine.GameConfig) {
-			startGame(gameCfg)
-		},
-		func() {
-			app.Stop()
-		},
-		func() {
-			rootPage.SwitchToPage("colors")
-		},
-		func() {
-			historyBrowser.Refresh()
-			rootPage.SwitchToPage("history")
-		},
-	)
-
-	// Color configuration screen
-	colorConfig := ui.NewColorConfig(cfg, func() {
-		// Refresh the game board with new colors
-		gameBoard.SetConfig(cfg)
-		rootPage.SwitchToPage("setup")
-	})
-	colorConfig.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEsc || (event.Key() == tcell.KeyRune && event.Rune() == 'q') {
-			rootPage.SwitchToPage("setup")
-			return nil
-		}
-		if event.Key() == tcell.KeyTab {
-			colorConfig.ToggleMode()
-			return nil
-		}
-		return event
-	})
-
-	// Add pages - start on setup by default, or gameview if quick start
-	rootPage.AddPage("setup", setupUI.Form(), true, !quickStart)
-	rootPage.AddPage("gameview", gameFrame, true, quickStart)
-	rootPage.AddPage("colors", colorConfig.Flex(), true, false)
-	rootPage.AddPage("history", historyBrowser.Flex(), true, false)
-
-	// Quick start if flags provided
-	if quickStart {
-		gameCfg := buildGameConfigFromFlags()
-		startGame(gameCfg)
-		// Enter focus mode if requested
-		if *flagFocus {
-			gameBoard.SetFocusMode(true)
-			ui.BuildFocusLayout(gameFrame, gameBoard)
-		}
-	}
-
-	if err := app.SetRoot(rootPage, true).Run(); err != nil {
-		panic(err)
-	}
-}
-
-// startGame starts a game with the given configuration.
-func startGame(gameCfg engine.GameConfig) {
-	// Use configured GnuGo path
-	gameCfg.EnginePath = cfg.GnuGo.Path
-
-	// Set komi on info panel
-	gameBoard.SetKomi(gameCfg.Komi)
-
-	// Start the game
-	eng := gtp.NewGTPEngine(gameCfg)
-	if err := gameBoard.ConnectEngine(eng); err != nil {
-		// Show error modal
-		modal := tview.NewModal().
-			SetText(fmt.Sprintf("Failed to start game:\n%s", err.Error())).
-			AddButtons([]string{"OK"}).
-			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-				rootPage.HidePage("error")
-			})
-		rootPage.AddPage("error", modal, true, true)
-		return
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		os.Exit(1)
 	}
 
-	// Set up SGF recording
-	gameBoard.SetGameConfig(gameCfg)
-	if cfg.EnableRecording {
-		rec, err := sgf.NewGameRecord(config.HistoryDir(), gameCfg.BoardSize, gameCfg.Komi, gameCfg.PlayerColor, gameCfg.EngineLevel)
-		if err == nil {
-			gameBoard.SetRecorder(rec)
+	// Local HTTP API for dashboards; fail before the TUI takes the terminal
+	if *flagHTTP != "" {
+		ln, err := net.Listen("tcp", *flagHTTP)
+		if err != nil {
+			fmt.Printf("Error: cannot listen on %s: %s\n", *flagHTTP, err)
+			os.Exit(1)
 		}
+		go http.Serve(ln, server.New(a.MoveSnapshot))
 	}
 
-	rootPage.SwitchToPage("gameview")
-}
-
-// loadGame loads a saved game from history for continued play.
-func loadGame(game sgf.GameInfo) {
-	// Determine player color: if PB contains "GnuGo", human is white
-	playerColor := 1
-	if strings.Contains(game.PlayerBlack, "GnuGo") {
-		playerColor = 2
-	}
-
-	// Parse engine level from player name (e.g., "GnuGo Level 5")
-	engineLevel := 5 // default
-	engineName := game.PlayerWhite
-	if playerColor == 2 {
-		engineName = game.PlayerBlack
-	}
-	if strings.Contains(engineName, "GnuGo Level ") {
-		fmt.Sscanf(engineName, "GnuGo Level %d", &engineLevel)
-	}
-
-	gameCfg := engine.GameConfig{
-		BoardSize:     game.BoardSize,
-		Komi:          game.Komi,
-		PlayerColor:   playerColor,
-		EngineLevel:   engineLevel,
-		EnginePath:    cfg.GnuGo.Path,
-		LoadSGFPath:   game.FilePath,
-		LoadMoveCount: game.MoveCount,
-	}
-
-	gameBoard.SetKomi(gameCfg.Komi)
-
-	eng := gtp.NewGTPEngine(gameCfg)
-	if err := gameBoard.ConnectEngine(eng); err != nil {
-		modal := tview.NewModal().
-			SetText(fmt.Sprintf("Failed to load game:\n%s", err.Error())).
-			AddButtons([]string{"OK"}).
-			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-				rootPage.HidePage("error")
-			})
-		rootPage.AddPage("error", modal, true, true)
-		return
-	}
-
-	gameBoard.SetGameConfig(gameCfg)
-
-	// Rebuild move history from SGF so undo and move list work
-	moves, err := sgf.ParseMovesAsEntries(game.FilePath)
-	if err == nil {
-		gameBoard.SetMoveHistory(moves)
-	}
-
-	// Open existing SGF for continued recording
-	rec, err := sgf.OpenGameRecord(game.FilePath)
-	if err == nil {
-		gameBoard.SetRecorder(rec)
-	}
-
-	rootPage.SwitchToPage("gameview")
-}
-
-// buildGameConfigFromFlags creates a GameConfig from command-line flags.
-func buildGameConfigFromFlags() engine.GameConfig {
-	// Start with defaults
-	gameCfg := engine.GameConfig{
-		BoardSize:   cfg.GnuGo.DefaultBoardSize,
-		Komi:        cfg.GnuGo.DefaultKomi,
-		PlayerColor: 1, // Black by default
-		EngineLevel: cfg.GnuGo.DefaultLevel,
-		EnginePath:  cfg.GnuGo.Path,
-	}
-
-	// Override with flags
-	if *flagBoardSize == 9 || *flagBoardSize == 13 || *flagBoardSize == 19 {
-		gameCfg.BoardSize = *flagBoardSize
-	}
-
-	if *flagColor == "black" || *flagColor == "b" {
-		gameCfg.PlayerColor = 1
-	} else if *flagColor == "white" || *flagColor == "w" {
-		gameCfg.PlayerColor = 2
-	}
-
-	if *flagDifficulty >= 1 && *flagDifficulty <= 10 {
-		gameCfg.EngineLevel = *flagDifficulty
-	}
-
-	if *flagKomi >= 0 {
-		gameCfg.Komi = *flagKomi
-	}
-
-	return gameCfg
-}
-
-// checkGnuGo verifies that GnuGo is installed and accessible.
-func checkGnuGo() error {
-	path := cfg.GnuGo.Path
-	if path == "" {
-		path = "gnugo"
-	}
-	_, err := exec.LookPath(path)
-	return err
-}
-
-// getLatestVersion fetches the latest release version from GitHub.
-func getLatestVersion() (string, error) {
-	resp, err := http.Get("https://api.github.com/repos/JollyGrin/termsuji-local/releases/latest")
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var release struct {
-		TagName string `json:"tag_name"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return "", err
+	if err := a.Run(); err != nil {
+		panic(err)
 	}
-	return release.TagName, nil
 }
 
 // selfUpdate downloads and installs the latest version.
 func selfUpdate() error {
 	fmt.Println("Checking for updates...")
 
-	latest, err := getLatestVersion()
+	rel, err := app.LatestRelease()
 	if err != nil {
 		return fmt.Errorf("failed to check for updates: %w", err)
 	}
+	latest := rel.TagName
 
 	if latest == Version {
 		fmt.Printf("Already at latest version (%s)\n", Version)
@@ -486,6 +245,11 @@ func selfUpdate() error {
 		return fmt.Errorf("failed to replace binary: %w", err)
 	}
 
+	// Cache the notes so the new version can show them on first start.
+	state := config.LoadState()
+	state.ReleaseNotes = config.ReleaseNotes{Version: latest, Body: rel.Body}
+	state.Save()
+
 	fmt.Printf("Updated to %s\n", latest)
 	return nil
 }
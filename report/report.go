@@ -0,0 +1,124 @@
+// Package report writes the plain-text summary of a finished game that
+// goes beside its record, for a journal of games that reads without an
+// SGF viewer.
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"termsuji-local/config"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+	"termsuji-local/ui/render"
+)
+
+// Questionable is a move of the player's that the engine's analysis, asked
+// for before it, didn't list among its candidates.
+type Questionable struct {
+	Move   int    // 1-based move number
+	Color  int    // 1=black, 2=white
+	Played string // e.g. "E5", or "pass"
+	Best   string // the engine's first choice
+}
+
+// Summary is what the board knows at the end of a game, all a report
+// needs.
+type Summary struct {
+	Game          string    // the record's file name
+	Result        string    // RE[] value or the engine's outcome
+	Started       time.Time // when the game began
+	Ended         time.Time // when it ended
+	BoardSize     int
+	Komi          float64
+	Black, White  string // player names
+	Moves         int
+	CapturesBlack int // stones Black took
+	CapturesWhite int // stones White took
+	UndosUsed     int
+	Analyzed      bool           // the engine's analysis was asked for during the game
+	Questionable  []Questionable // meaningful only when Analyzed
+	Board         [][]int        // the final position, board[y][x]
+}
+
+// Format lays s out as the report's text: a block of facts, the
+// questionable moves when the game was analyzed, and the final position
+// in ASCII, so the file reads the same in any editor.
+func Format(s Summary) string {
+	var b strings.Builder
+	b.WriteString("Game report\n")
+	b.WriteString("===========\n\n")
+
+	field := func(name, value string) {
+		fmt.Fprintf(&b, "%-10s%s\n", name, value)
+	}
+	if s.Game != "" {
+		field("Game", s.Game)
+	}
+	played := s.Started.Format("2006-01-02 15:04")
+	if d := s.Ended.Sub(s.Started); !s.Started.IsZero() && d > 0 {
+		played += ", " + formatDuration(d)
+	}
+	field("Played", played)
+	field("Board", fmt.Sprintf("%dx%d, komi %g", s.BoardSize, s.BoardSize, s.Komi))
+	field("Black", s.Black)
+	field("White", s.White)
+	field("Result", sgf.FormatResult(s.Result))
+	field("Moves", fmt.Sprint(s.Moves))
+	field("Captures", fmt.Sprintf("Black took %d, White took %d", s.CapturesBlack, s.CapturesWhite))
+	field("Undos", fmt.Sprint(s.UndosUsed))
+
+	if s.Analyzed {
+		b.WriteString("\nQuestionable moves\n")
+		if len(s.Questionable) == 0 {
+			b.WriteString("  none; every move checked was one of the engine's\n")
+		}
+		for _, q := range s.Questionable {
+			fmt.Fprintf(&b, "  %3d  %s %-4s the engine preferred %s\n", q.Move, colorLetter(q.Color), q.Played, q.Best)
+		}
+	}
+
+	if len(s.Board) > 0 {
+		b.WriteString("\nFinal position\n")
+		state := &types.BoardState{Board: s.Board}
+		for _, line := range render.BoardToLines(state, render.RenderOptions{
+			Symbols:     config.ASCIISymbols,
+			ASCII:       true,
+			GridLines:   true,
+			Coordinates: true,
+		}) {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// Write writes text, a Format report, beside the record at sgfPath in
+// place of any earlier one, and returns where it went.
+func Write(sgfPath, text string) (string, error) {
+	path := sgf.ReportPath(sgfPath)
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return "", fmt.Errorf("write report: %w", err)
+	}
+	return path, nil
+}
+
+// formatDuration writes d to the second, e.g. "1h 02m 05s" or "24m 10s".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, sec := int(d/time.Hour), int(d/time.Minute)%60, int(d/time.Second)%60
+	if h > 0 {
+		return fmt.Sprintf("%dh %02dm %02ds", h, m, sec)
+	}
+	return fmt.Sprintf("%dm %02ds", m, sec)
+}
+
+func colorLetter(color int) string {
+	if color == 2 {
+		return "W"
+	}
+	return "B"
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"termsuji-local/sgf"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites it with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs; got:\n%s", name, got)
+	}
+}
+
+// testBoard is a 9x9 position with a few stones of each color.
+func testBoard() [][]int {
+	board := sgf.MakeBoard(9)
+	board[2][2], board[2][6], board[6][4] = 1, 1, 1
+	board[6][2], board[6][6], board[4][4] = 2, 2, 2
+	return board
+}
+
+func TestFormatCounted(t *testing.T) {
+	started := time.Date(2026, 3, 14, 15, 9, 0, 0, time.UTC)
+	checkGolden(t, "counted.golden", Format(Summary{
+		Game:          "game-20260314-150900.sgf",
+		Result:        "W+3.5",
+		Started:       started,
+		Ended:         started.Add(24*time.Minute + 10*time.Second),
+		BoardSize:     9,
+		Komi:          6.5,
+		Black:         "Player",
+		White:         "GnuGo L5",
+		Moves:         87,
+		CapturesBlack: 4,
+		CapturesWhite: 7,
+		UndosUsed:     2,
+		Analyzed:      true,
+		Questionable: []Questionable{
+			{Move: 12, Color: 1, Played: "E5", Best: "D4"},
+			{Move: 40, Color: 1, Played: "pass", Best: "C7"},
+		},
+		Board: testBoard(),
+	}))
+}
+
+func TestFormatResigned(t *testing.T) {
+	started := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	checkGolden(t, "resigned.golden", Format(Summary{
+		Result:    "B+R",
+		Started:   started,
+		Ended:     started.Add(time.Hour + 2*time.Minute + 5*time.Second),
+		BoardSize: 9,
+		Komi:      0.5,
+		Black:     "GnuGo L3",
+		White:     "Player",
+		Moves:     31,
+		Board:     testBoard(),
+	}))
+}
+
+func TestFormatAnalyzedClean(t *testing.T) {
+	got := Format(Summary{Result: "B+1.5", BoardSize: 9, Analyzed: true})
+	if !strings.Contains(got, "Questionable moves\n  none;") {
+		t.Errorf("an analyzed game without questionable moves should say so:\n%s", got)
+	}
+	if got := Format(Summary{Result: "B+1.5", BoardSize: 9}); strings.Contains(got, "Questionable") {
+		t.Errorf("a game never analyzed has no questionable moves to list:\n%s", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	record := filepath.Join(dir, "game.sgf")
+	path, err := Write(record, Format(Summary{Result: "W+R", BoardSize: 9}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "game.report.txt"); path != want {
+		t.Errorf("report written to %s, want %s", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "White wins by resignation") {
+		t.Errorf("report reads %q, %v", data, err)
+	}
+}
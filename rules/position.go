@@ -0,0 +1,53 @@
+package rules
+
+import "termsuji-local/sgf"
+
+// Position is a board mid-game with what the rules need to know about how
+// it came about.
+type Position struct {
+	Board    [][]int // Board[y][x]: 0 empty, 1 black, 2 white
+	Ko       [2]int  // point the side to move may not retake at, (-1,-1) if none
+	Captures [3]int  // stones captured by each color (1=black, 2=white)
+
+	seen map[uint64]bool // hashes of the positions so far, for superko
+}
+
+// NewPosition starts from a copy of board, with no ko and no captures.
+func NewPosition(board [][]int) *Position {
+	p := &Position{Board: sgf.MakeBoard(len(board)), Ko: [2]int{-1, -1}}
+	for y := range board {
+		copy(p.Board[y], board[y])
+	}
+	p.seen = map[uint64]bool{sgf.ZobristHash(p.Board, p.Size()): true}
+	return p
+}
+
+// Size returns the board's width.
+func (p *Position) Size() int {
+	return len(p.Board)
+}
+
+// Remember adds the hash of an earlier position in the game, one a
+// superko ruleset won't let come back.
+func (p *Position) Remember(hash uint64) {
+	if hash != 0 {
+		p.seen[hash] = true
+	}
+}
+
+// Pass plays a pass, which lifts any ko.
+func (p *Position) Pass() {
+	p.Ko = [2]int{-1, -1}
+}
+
+// clone returns a copy to try moves on.
+func (p *Position) clone() *Position {
+	c := &Position{Board: sgf.MakeBoard(p.Size()), Ko: p.Ko, Captures: p.Captures, seen: make(map[uint64]bool, len(p.seen))}
+	for y := range p.Board {
+		copy(c.Board[y], p.Board[y])
+	}
+	for h := range p.seen {
+		c.seen[h] = true
+	}
+	return c
+}
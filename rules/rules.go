@@ -0,0 +1,158 @@
+// Package rules decides which moves are legal and how a finished board is
+// counted. The rulesets it knows differ in three ways that matter here:
+// whether a move may take its own group off the board, whether any earlier
+// position may come back or only an immediate ko retake is barred, and
+// whether territory or area is counted.
+package rules
+
+import (
+	"errors"
+	"strings"
+
+	"termsuji-local/sgf"
+)
+
+// Reasons ApplyMove refuses a move.
+var (
+	ErrOffBoard = errors.New("off the board")
+	ErrOccupied = errors.New("point is taken")
+	ErrSuicide  = errors.New("suicide: the stone would have no liberties")
+	ErrKo       = errors.New("ko: play elsewhere before retaking")
+	ErrSuperko  = errors.New("superko: that repeats an earlier position")
+)
+
+// Ruleset is a set of rules to play and count a game by.
+type Ruleset interface {
+	// Name is the ruleset's name as shown, e.g. "Japanese".
+	Name() string
+	// DefaultKomi is the komi of an even game: 6.5 counting territory,
+	// 7.5 counting area.
+	DefaultKomi() float64
+	// IsLegal reports whether color may play at (x, y) in pos.
+	IsLegal(pos *Position, x, y, color int) bool
+	// ApplyMove plays color at (x, y) in pos and returns what it took off
+	// the board, or why the move isn't allowed, leaving pos as it was.
+	ApplyMove(pos *Position, x, y, color int) (Capture, error)
+	// Score counts a finished board with the dead stones taken off.
+	Score(board [][]int, dead [][2]int, capturesBlack, capturesWhite int, komi float64) sgf.ScoreBreakdown
+}
+
+// Capture is what a move took off the board.
+type Capture struct {
+	Stones int // the opponent's
+	Own    int // the mover's, by a suicide where that is allowed
+}
+
+// ruleset is a Ruleset described by how it settles the three questions.
+type ruleset struct {
+	name    string
+	suicide bool // a move may take its own group off
+	superko bool // no earlier position may come back
+	area    bool // stones on the board count, prisoners don't
+}
+
+// The rulesets known. Japanese is GnuGo's default.
+var (
+	Japanese   Ruleset = ruleset{name: "Japanese"}
+	Chinese    Ruleset = ruleset{name: "Chinese", superko: true, area: true}
+	NewZealand Ruleset = ruleset{name: "New Zealand", suicide: true, superko: true, area: true}
+)
+
+// All returns the known rulesets, Japanese first.
+func All() []Ruleset {
+	return []Ruleset{Japanese, Chinese, NewZealand}
+}
+
+// ByName finds a ruleset by name, ignoring case; "NZ" is New Zealand.
+// An empty or unknown name gets Japanese.
+func ByName(name string) Ruleset {
+	if strings.EqualFold(name, "nz") {
+		return NewZealand
+	}
+	for _, r := range All() {
+		if strings.EqualFold(name, r.Name()) {
+			return r
+		}
+	}
+	return Japanese
+}
+
+// GnuGoFlag is GnuGo's command-line flag for the named ruleset. GnuGo
+// knows only Japanese and Chinese rules; any ruleset counting area gets
+// Chinese.
+func GnuGoFlag(name string) string {
+	if ByName(name) == Japanese {
+		return "--japanese-rules"
+	}
+	return "--chinese-rules"
+}
+
+func (r ruleset) Name() string {
+	return r.name
+}
+
+func (r ruleset) DefaultKomi() float64 {
+	if r.area {
+		return 7.5
+	}
+	return 6.5
+}
+
+func (r ruleset) IsLegal(pos *Position, x, y, color int) bool {
+	_, err := r.ApplyMove(pos.clone(), x, y, color)
+	return err == nil
+}
+
+func (r ruleset) ApplyMove(pos *Position, x, y, color int) (Capture, error) {
+	size := pos.Size()
+	if x < 0 || x >= size || y < 0 || y >= size {
+		return Capture{}, ErrOffBoard
+	}
+	if pos.Board[y][x] != 0 {
+		return Capture{}, ErrOccupied
+	}
+	if x == pos.Ko[0] && y == pos.Ko[1] {
+		return Capture{}, ErrKo
+	}
+
+	// Play it out on a copy, so a refused move leaves pos alone
+	board := sgf.MakeBoard(size)
+	for i := range board {
+		copy(board[i], pos.Board[i])
+	}
+	board[y][x] = color
+	var c Capture
+	c.Stones = sgf.RemoveCaptures(board, size, x, y, color)
+	if !sgf.HasLiberty(board, size, x, y, color) {
+		if !r.suicide {
+			return Capture{}, ErrSuicide
+		}
+		for _, p := range sgf.Group(board, x, y) {
+			board[p[1]][p[0]] = 0
+			c.Own++
+		}
+	}
+	hash := sgf.ZobristHash(board, size)
+	if r.superko && pos.seen[hash] {
+		return Capture{}, ErrSuperko
+	}
+
+	for i := range board {
+		copy(pos.Board[i], board[i])
+	}
+	pos.Captures[color] += c.Stones
+	pos.Captures[3-color] += c.Own
+	pos.Ko = [2]int{-1, -1}
+	if kx, ky, ok := sgf.KoPoint(pos.Board, size, x, y, color, c.Stones); ok {
+		pos.Ko = [2]int{kx, ky}
+	}
+	pos.seen[hash] = true
+	return c, nil
+}
+
+func (r ruleset) Score(board [][]int, dead [][2]int, capturesBlack, capturesWhite int, komi float64) sgf.ScoreBreakdown {
+	if r.area {
+		return sgf.ComputeAreaScore(board, dead, komi)
+	}
+	return sgf.ComputeScore(board, dead, capturesBlack, capturesWhite, komi)
+}
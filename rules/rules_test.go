@@ -0,0 +1,246 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"termsuji-local/sgf"
+)
+
+// board builds a board from rows of '.', 'X' (black) and 'O' (white).
+func board(rows ...string) [][]int {
+	b := make([][]int, len(rows))
+	for y, row := range rows {
+		b[y] = make([]int, len(row))
+		for x, c := range row {
+			switch c {
+			case 'X':
+				b[y][x] = 1
+			case 'O':
+				b[y][x] = 2
+			}
+		}
+	}
+	return b
+}
+
+// play applies moves of {color, x, y} in order, failing on any refused
+// before the last, and returns the last one's result.
+func play(t *testing.T, r Ruleset, pos *Position, moves ...[3]int) (Capture, error) {
+	t.Helper()
+	for i, m := range moves {
+		c, err := r.ApplyMove(pos, m[1], m[2], m[0])
+		if i == len(moves)-1 {
+			return c, err
+		}
+		if err != nil {
+			t.Fatalf("%s: move %d %v refused: %v", r.Name(), i+1, m, err)
+		}
+	}
+	return Capture{}, nil
+}
+
+func TestByName(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want Ruleset
+	}{
+		{"", Japanese},
+		{"japanese", Japanese},
+		{"Chinese", Chinese},
+		{"NZ", NewZealand},
+		{"new zealand", NewZealand},
+		{"Ing", Japanese},
+	} {
+		if got := ByName(tt.name); got != tt.want {
+			t.Errorf("ByName(%q) = %s, want %s", tt.name, got.Name(), tt.want.Name())
+		}
+	}
+}
+
+func TestSuicide(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []string
+		move  [3]int
+		want  map[Ruleset]error
+		taken int // stones the mover loses where it's allowed
+	}{
+		{
+			name: "single stone",
+			rows: []string{".X...", "X....", ".....", ".....", "....."},
+			move: [3]int{2, 0, 0},
+			// Taking a lone stone off leaves the board as it was
+			want: map[Ruleset]error{Japanese: ErrSuicide, Chinese: ErrSuicide, NewZealand: ErrSuperko},
+		},
+		{
+			name:  "two stones",
+			rows:  []string{"OX...", ".X...", "X....", ".....", "....."},
+			move:  [3]int{2, 0, 1},
+			want:  map[Ruleset]error{Japanese: ErrSuicide, Chinese: ErrSuicide, NewZealand: nil},
+			taken: 2,
+		},
+		{
+			name: "capturing first",
+			rows: []string{".XO..", "XO...", "O....", ".....", "....."},
+			move: [3]int{2, 0, 0},
+			want: map[Ruleset]error{Japanese: nil, Chinese: nil, NewZealand: nil},
+		},
+	}
+	for _, tt := range tests {
+		for r, want := range tt.want {
+			pos := NewPosition(board(tt.rows...))
+			c, err := r.ApplyMove(pos, tt.move[1], tt.move[2], tt.move[0])
+			if !errors.Is(err, want) {
+				t.Errorf("%s, %s: err %v, want %v", tt.name, r.Name(), err, want)
+				continue
+			}
+			if legal := r.IsLegal(NewPosition(board(tt.rows...)), tt.move[1], tt.move[2], tt.move[0]); legal != (want == nil) {
+				t.Errorf("%s, %s: IsLegal %v", tt.name, r.Name(), legal)
+			}
+			if err == nil && c.Own != tt.taken {
+				t.Errorf("%s, %s: lost %d own stones, want %d", tt.name, r.Name(), c.Own, tt.taken)
+			}
+			if err == nil && c.Own > 0 && (pos.Board[0][0] != 0 || pos.Captures[1] != c.Own) {
+				t.Errorf("%s, %s: suicide left %v, Black credited %d", tt.name, r.Name(), pos.Board[:2], pos.Captures[1])
+			}
+			if err != nil && pos.Board[tt.move[2]][tt.move[1]] != 0 {
+				t.Errorf("%s, %s: refused move left a stone", tt.name, r.Name())
+			}
+		}
+	}
+}
+
+// koRows has a ko on the top edge Black can take at (3,0).
+var koRows = []string{
+	".XO.O....",
+	"..XO.....",
+	".........",
+	".........",
+	".........",
+	".........",
+	".........",
+	".........",
+	".........",
+}
+
+func TestSimpleKo(t *testing.T) {
+	for _, r := range All() {
+		pos := NewPosition(board(koRows...))
+		if c, err := play(t, r, pos, [3]int{1, 3, 0}); err != nil || c.Stones != 1 || pos.Ko != [2]int{2, 0} {
+			t.Fatalf("%s: capture took %d (%v), ko at %v", r.Name(), c.Stones, err, pos.Ko)
+		}
+		if _, err := r.ApplyMove(pos, 2, 0, 2); !errors.Is(err, ErrKo) {
+			t.Errorf("%s: immediate retake: %v, want ErrKo", r.Name(), err)
+		}
+		// A threat and an answer, and it can be taken back
+		if _, err := play(t, r, pos, [3]int{2, 7, 7}, [3]int{1, 7, 6}, [3]int{2, 2, 0}); err != nil {
+			t.Errorf("%s: retake after a threat: %v", r.Name(), err)
+		}
+		pos.Pass()
+		if pos.Ko != [2]int{-1, -1} {
+			t.Errorf("%s: ko at %v after a pass", r.Name(), pos.Ko)
+		}
+	}
+}
+
+// tripleKoRows has three kos: Black can take at (3,0) and (3,8), White at
+// (0,4).
+var tripleKoRows = []string{
+	".XO.O....",
+	"..XO.....",
+	".........",
+	"X........",
+	".X.......",
+	"XO.......",
+	"O........",
+	"..XO.....",
+	".XO.O....",
+}
+
+func TestTripleKo(t *testing.T) {
+	cycle := [][3]int{{1, 3, 0}, {2, 0, 4}, {1, 3, 8}, {2, 2, 0}, {1, 0, 5}, {2, 2, 8}}
+	want := map[Ruleset]error{Japanese: nil, Chinese: ErrSuperko, NewZealand: ErrSuperko}
+	for r, wantErr := range want {
+		pos := NewPosition(board(tripleKoRows...))
+		if _, err := play(t, r, pos, cycle...); !errors.Is(err, wantErr) {
+			t.Errorf("%s: closing the cycle: %v, want %v", r.Name(), err, wantErr)
+		}
+	}
+
+	// Positions from before the position started count too.
+	pos := NewPosition(board(tripleKoRows...))
+	start := NewPosition(board(tripleKoRows...))
+	play(t, Chinese, start, cycle[0])
+	pos.Remember(hashOf(start))
+	if _, err := Chinese.ApplyMove(pos, 3, 0, 1); !errors.Is(err, ErrSuperko) {
+		t.Errorf("repeating a remembered position: %v", err)
+	}
+}
+
+func TestScore(t *testing.T) {
+	rows := []string{
+		"..XO.",
+		".OXO.",
+		"..XO.",
+		"..XOO",
+		"..XO.",
+	}
+	dead := [][2]int{{1, 1}}
+	// Territory: B 10 + 2 captured + 1 dead / W 4 + 1 captured
+	// Area: B 10 + 5 stones / W 4 + 6 stones
+	for _, tt := range []struct {
+		r    Ruleset
+		want string
+	}{
+		{Japanese, "B+7.5"},
+		{Chinese, "B+4.5"},
+		{NewZealand, "B+4.5"},
+	} {
+		if got := tt.r.Score(board(rows...), dead, 2, 1, 0.5).LocalResult(); got != tt.want {
+			t.Errorf("%s: %s, want %s", tt.r.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestScoreNeutralPoints(t *testing.T) {
+	// Column 0 is Black's, column 4 White's; column 2 touches both and
+	// counts for nobody either way.
+	rows := []string{
+		".X.O.",
+		".X.O.",
+		".X.O.",
+		".X.O.",
+		".X.O.",
+	}
+	// Territory: B 5 + 3 captured / W 5; area: B 5 + 5 stones / W 5 + 5
+	for _, tt := range []struct {
+		r    Ruleset
+		want string
+	}{
+		{Japanese, "B+2.5"},
+		{Chinese, "W+0.5"},
+	} {
+		s := tt.r.Score(board(rows...), nil, 3, 0, 0.5)
+		if got := s.LocalResult(); got != tt.want {
+			t.Errorf("%s: %s, want %s", tt.r.Name(), got, tt.want)
+		}
+		if s.TerritoryBlack != 5 || s.TerritoryWhite != 5 {
+			t.Errorf("%s: territory B %d / W %d, want 5 each", tt.r.Name(), s.TerritoryBlack, s.TerritoryWhite)
+		}
+	}
+}
+
+func TestDefaultKomi(t *testing.T) {
+	if k := Japanese.DefaultKomi(); k != 6.5 {
+		t.Errorf("Japanese komi %.1f, want 6.5", k)
+	}
+	if k := Chinese.DefaultKomi(); k != 7.5 {
+		t.Errorf("Chinese komi %.1f, want 7.5", k)
+	}
+}
+
+// hashOf returns the hash of pos's board.
+func hashOf(pos *Position) uint64 {
+	return sgf.ZobristHash(pos.Board, pos.Size())
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"termsuji-local/app"
+	"termsuji-local/config"
+)
+
+// runSelfplay implements "termsuji-local selfplay [-seed n] [-deterministic]
+// ...": the built-in engine plays itself without a terminal or GnuGo, and
+// the game is recorded. It returns the exit status: 1 when the game can't
+// be recorded, or -deterministic finds two runs playing different games.
+func runSelfplay(args []string) int {
+	fs := flag.NewFlagSet("selfplay", flag.ExitOnError)
+	size := fs.Int("boardsize", 9, "Board size")
+	seed := fs.Int64("seed", 0, "Random seed for the built-in engine (default: from the clock)")
+	ruleset := fs.String("rules", "Japanese", "Rules to play by")
+	dir := fs.String("dir", config.HistoryDir(), "Directory to record the game in")
+	deterministic := fs.Bool("deterministic", false, "Play the game twice and fail unless both records are the same")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: termsuji-local selfplay [-seed n] [-deterministic] [-boardsize n] [-rules name] [-dir path]")
+		fmt.Fprintln(fs.Output(), "Records a game of the built-in engine against itself, which needs no GnuGo.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	opts := app.SelfPlayOptions{BoardSize: *size, Seed: *seed, Ruleset: *ruleset}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	var path string
+	var err error
+	if *deterministic {
+		path, err = app.SelfPlayDeterministic(*dir, opts)
+	} else {
+		path, err = app.SelfPlay(*dir, opts, time.Now())
+	}
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+	fmt.Printf("%s (seed %d)\n", path, opts.Seed)
+	return 0
+}
@@ -0,0 +1,103 @@
+// Package server exposes the running game over a small local HTTP API so
+// dashboards and scripts can follow along.
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"termsuji-local/sgf"
+)
+
+// Move is one entry of the game's move log.
+type Move struct {
+	Color int           // 1=black, 2=white
+	X, Y  int           // -1,-1 for a pass
+	At    time.Time     // zero when unknown (moves loaded from SGF)
+	Think time.Duration // time since the previous move
+}
+
+// MoveSource returns a snapshot of the current game's moves. It is called
+// from HTTP handler goroutines and must be safe for that.
+type MoveSource func() []Move
+
+// moveJSON is the wire format of a move in GET /moves.
+type moveJSON struct {
+	Index   int        `json:"index"`
+	Color   string     `json:"color"` // "B" or "W"
+	X       int        `json:"x"`
+	Y       int        `json:"y"`
+	Pass    bool       `json:"pass,omitempty"`
+	Time    *time.Time `json:"time,omitempty"`
+	ThinkMS int64      `json:"think_ms,omitempty"`
+}
+
+type movesResponse struct {
+	Moves []moveJSON `json:"moves"`
+	Next  int        `json:"next"` // value of since for the next poll
+}
+
+// New returns the API handler.
+func New(moves MoveSource) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/moves", movesHandler(moves))
+	return mux
+}
+
+// movesHandler serves GET /moves?since=N[&format=sgf]: the moves with
+// index >= N, or 204 No Content when there are none yet.
+func movesHandler(source MoveSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		since := 0
+		if v := r.URL.Query().Get("since"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "since must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			since = n
+		}
+
+		moves := source()
+		if since >= len(moves) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		moves = moves[since:]
+
+		if r.URL.Query().Get("format") == "sgf" {
+			var b strings.Builder
+			for _, m := range moves {
+				b.WriteString(sgf.MoveNode(m.Color, m.X, m.Y))
+			}
+			w.Header().Set("Content-Type", "application/x-go-sgf")
+			w.Write([]byte(b.String()))
+			return
+		}
+
+		resp := movesResponse{Moves: make([]moveJSON, len(moves)), Next: since + len(moves)}
+		for i, m := range moves {
+			mj := moveJSON{Index: since + i, Color: "B", X: m.X, Y: m.Y}
+			if m.Color == 2 {
+				mj.Color = "W"
+			}
+			mj.Pass = m.X == -1 && m.Y == -1
+			if !m.At.IsZero() {
+				at := m.At
+				mj.Time = &at
+				mj.ThinkMS = m.Think.Milliseconds()
+			}
+			resp.Moves[i] = mj
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
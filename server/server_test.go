@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testMoves() []Move {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []Move{
+		{Color: 1, X: 3, Y: 3}, // loaded from SGF, no timing
+		{Color: 2, X: 15, Y: 15, At: t0, Think: 1500 * time.Millisecond},              // engine
+		{Color: 1, X: -1, Y: -1, At: t0.Add(4 * time.Second), Think: 4 * time.Second}, // pass
+	}
+}
+
+func get(t *testing.T, url string) *httptest.ResponseRecorder {
+	t.Helper()
+	h := New(func() []Move { return testMoves() })
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) movesResponse {
+	t.Helper()
+	var resp movesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+func TestMovesSinceZero(t *testing.T) {
+	rec := get(t, "/moves?since=0")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	resp := decode(t, rec)
+	if len(resp.Moves) != 3 || resp.Next != 3 {
+		t.Fatalf("got %d moves, next %d; want 3, 3", len(resp.Moves), resp.Next)
+	}
+	if m := resp.Moves[0]; m.Color != "B" || m.X != 3 || m.Time != nil {
+		t.Errorf("first move = %+v", m)
+	}
+	if m := resp.Moves[1]; m.Color != "W" || m.ThinkMS != 1500 || m.Time == nil {
+		t.Errorf("second move = %+v", m)
+	}
+	if !resp.Moves[2].Pass {
+		t.Error("third move should be a pass")
+	}
+}
+
+func TestMovesMidGame(t *testing.T) {
+	resp := decode(t, get(t, "/moves?since=2"))
+	if len(resp.Moves) != 1 || resp.Moves[0].Index != 2 || resp.Next != 3 {
+		t.Errorf("got %+v, want only index 2 and next 3", resp)
+	}
+
+	// No since means from the start.
+	if resp := decode(t, get(t, "/moves")); len(resp.Moves) != 3 {
+		t.Errorf("got %d moves without since, want 3", len(resp.Moves))
+	}
+}
+
+func TestMovesSinceEnd(t *testing.T) {
+	for _, url := range []string{"/moves?since=3", "/moves?since=50", "/moves?since=3&format=sgf"} {
+		rec := get(t, url)
+		if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+			t.Errorf("%s: status %d body %q, want 204 and empty", url, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestMovesSGF(t *testing.T) {
+	rec := get(t, "/moves?since=1&format=sgf")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Body.String(); got != ";W[pp];B[]" {
+		t.Errorf("body = %q, want %q", got, ";W[pp];B[]")
+	}
+}
+
+func TestMovesBadRequest(t *testing.T) {
+	for _, url := range []string{"/moves?since=-1", "/moves?since=abc"} {
+		if rec := get(t, url); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", url, rec.Code)
+		}
+	}
+
+	h := New(func() []Move { return nil })
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/moves", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package sgf_test
+
+import (
+	"testing"
+
+	"termsuji-local/sgf"
+)
+
+// TestPublicAPI exercises the exported surface the way another program
+// would, without anything from the TUI.
+func TestPublicAPI(t *testing.T) {
+	dir := t.TempDir()
+
+	rec, err := sgf.NewGameRecord(dir, 9, 6.5, 2, 3, sgf.RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.AddMove(2, 2, 1)
+	rec.AddMove(6, 6, 2)
+	rec.AddMove(2, 6, 1)
+	rec.UndoMoves(1)
+	rec.AddComment(rec.MoveCount()-1, "good shape")
+	rec.SetComment("practice game")
+	rec.SetResult("W+3.5")
+	rec.Close()
+
+	games, err := sgf.ListGames(dir)
+	if err != nil || len(games) != 1 {
+		t.Fatalf("ListGames = %d games, %v", len(games), err)
+	}
+	g := games[0]
+	if g.BoardSize != 9 || g.MoveCount != 2 || g.Result != "W+3.5" || g.PlayerWhite != "Player" || g.Comment != "practice game" {
+		t.Errorf("header = %+v", g)
+	}
+
+	moves, err := sgf.ParseMovesAsEntries(g.FilePath)
+	if err != nil || len(moves) != 2 || moves[1] != [3]int{2, 6, 6} {
+		t.Errorf("ParseMovesAsEntries = %v, %v", moves, err)
+	}
+
+	board, n, err := sgf.ReplayToEnd(g.FilePath)
+	if err != nil || n != 2 || board[2][2] != 1 || board[6][6] != 2 {
+		t.Errorf("ReplayToEnd: %d moves, err %v", n, err)
+	}
+
+	reopened, err := sgf.OpenGameRecord(g.FilePath)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	reopened.AddMove(4, 4, 1)
+	reopened.Close()
+	if info, _ := sgf.ParseHeader(g.FilePath); info.MoveCount != 3 {
+		t.Errorf("MoveCount after continuing = %d, want 3", info.MoveCount)
+	}
+
+	tree := sgf.NewGameTree()
+	tree.AddMove(sgf.MoveNode(1, 3, 3))
+	if got := tree.PathFromRoot(); len(got) != 1 || got[0] != ";B[dd]" {
+		t.Errorf("GameTree path = %v", got)
+	}
+
+	score := sgf.ComputeScore(sgf.MakeBoard(9), nil, 0, 0, 6.5)
+	if score.LocalResult() != "W+6.5" {
+		t.Errorf("empty board result = %s, want W+6.5", score.LocalResult())
+	}
+}
@@ -0,0 +1,51 @@
+package sgf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ArchiveDir is the folder in the history directory that archived games
+// are kept in, out of the way of the list. ListGames doesn't look inside
+// it; listing it lists the archive.
+const ArchiveDir = "archive"
+
+// ArchiveGame moves the game at path into the archive folder beside it,
+// under a name of its own if an archived game has the name already, and
+// returns where it went. Its report goes with it, and its modification
+// time stays, so the archive keeps the games' order.
+func ArchiveGame(path string) (string, error) {
+	dir := filepath.Join(filepath.Dir(path), ArchiveDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create archive: %w", err)
+	}
+	to := freePath(dir, filepath.Base(path))
+	if err := moveGame(path, to); err != nil {
+		return "", err
+	}
+	return to, nil
+}
+
+// UnarchiveGame moves the archived game at path back into the history
+// directory and returns where it went. It won't overwrite a game of the
+// same name there.
+func UnarchiveGame(path string) (string, error) {
+	to := filepath.Join(filepath.Dir(filepath.Dir(path)), filepath.Base(path))
+	if _, err := os.Lstat(to); err == nil {
+		return "", fmt.Errorf("%s already exists", filepath.Base(to))
+	}
+	if err := moveGame(path, to); err != nil {
+		return "", err
+	}
+	return to, nil
+}
+
+// moveGame renames the game at from to to, and its report if it has one.
+func moveGame(from, to string) error {
+	if err := os.Rename(from, to); err != nil {
+		return err
+	}
+	os.Rename(ReportPath(from), ReportPath(to))
+	return nil
+}
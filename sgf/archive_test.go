@@ -0,0 +1,60 @@
+package sgf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveGame(t *testing.T) {
+	dir := t.TempDir()
+	name := "2026-01-10_100000_9x9.sgf"
+	path := writeTempSGF(t, dir, name, `(;GM[1]SZ[9]RE[B+R];B[ee])`)
+	os.WriteFile(ReportPath(path), []byte("report"), 0644)
+	old := time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC)
+	os.Chtimes(path, old, old)
+
+	archived, err := ArchiveGame(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, ArchiveDir, name); archived != want {
+		t.Errorf("archived to %s, want %s", archived, want)
+	}
+	if fi, err := os.Stat(archived); err != nil || !fi.ModTime().Equal(old) {
+		t.Errorf("archived game: %v, %v; want its old modification time", fi, err)
+	}
+	if _, err := os.Stat(ReportPath(archived)); err != nil {
+		t.Errorf("report not archived with the game: %v", err)
+	}
+
+	// The history no longer lists it, and doesn't look into the archive
+	if games, _ := ListGames(dir); len(games) != 0 {
+		t.Errorf("history lists %d games after archiving", len(games))
+	}
+	if games, _ := ListGames(filepath.Join(dir, ArchiveDir)); len(games) != 1 || !games[0].HasReport {
+		t.Errorf("archive lists %+v", games)
+	}
+
+	// A second game of the same name gets a name of its own
+	writeTempSGF(t, dir, name, `(;GM[1]SZ[9]RE[W+R];B[cc])`)
+	second, err := ArchiveGame(path)
+	if err != nil || filepath.Base(second) != "2026-01-10_100000_9x9-2.sgf" {
+		t.Fatalf("second archived as %s, %v", second, err)
+	}
+
+	back, err := UnarchiveGame(archived)
+	if err != nil || back != path {
+		t.Fatalf("unarchived to %s, %v; want %s", back, err, path)
+	}
+	if _, err := os.Stat(ReportPath(path)); err != nil {
+		t.Errorf("report not back with the game: %v", err)
+	}
+
+	// Back again, it won't overwrite the game now in the history
+	writeTempSGF(t, filepath.Join(dir, ArchiveDir), name, `(;GM[1]SZ[9])`)
+	if _, err := UnarchiveGame(filepath.Join(dir, ArchiveDir, name)); err == nil {
+		t.Error("unarchived over a game in the history")
+	}
+}
@@ -0,0 +1,89 @@
+package sgf
+
+import (
+	"fmt"
+	"os"
+)
+
+// ParseCollection reads an SGF file and returns the header of each game in
+// it, in file order. Most files hold one game; a collection holds several,
+// one game tree after another, "(;...)(;...)". Each header has GameIndex
+// set to its place and Games to how many there are.
+func ParseCollection(filePath string) ([]GameInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+	if err := checkReadable(content); err != nil {
+		return nil, err
+	}
+	texts := splitCollection(content)
+	games := make([]GameInfo, 0, len(texts))
+	for i, text := range texts {
+		info, err := parseHeader(filePath, text)
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		info.GameIndex, info.Games = i, len(texts)
+		games = append(games, *info)
+	}
+	return games, nil
+}
+
+// splitCollection returns the text of each game tree at the top level of
+// content, "(" to its ")", in order. A game cut off by the end of content
+// runs to the end.
+func splitCollection(content string) []string {
+	var games []string
+	depth, start := 0, 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '[':
+			end, _ := valueEnd(content, i)
+			i = end - 1
+		case '(':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case ')':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				games = append(games, content[start:i+1])
+			}
+		}
+	}
+	if depth > 0 {
+		games = append(games, content[start:])
+	}
+	return games
+}
+
+// readGame reads the SGF file at filePath and returns the text of one of
+// its games, as pickGame does.
+func readGame(filePath string, game []int) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return pickGame(string(data), game)
+}
+
+// pickGame returns the text of the game game[0] of content, or of the
+// first game when game is empty: the optional game index the readers
+// take. The first game is content itself, as the readers all stop at the
+// end of the first game tree.
+func pickGame(content string, game []int) (string, error) {
+	if len(game) == 0 || game[0] == 0 {
+		return content, nil
+	}
+	games := splitCollection(content)
+	if game[0] < 0 || game[0] >= len(games) {
+		return "", fmt.Errorf("no game %d in the file, which has %d", game[0]+1, len(games))
+	}
+	return games[game[0]], nil
+}
@@ -0,0 +1,107 @@
+package sgf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// collectionSGF is three games in one file, the second on a smaller board.
+const collectionSGF = `(;GM[1]FF[4]SZ[9]PB[ann]PW[bo]RE[B+R];B[ee];W[cc])
+(;GM[1]FF[4]SZ[7]PB[cy]PW[di]RE[W+2.5];B[dd];W[cc];B[ee])
+(;GM[1]FF[4]SZ[9]PB[ed]PW[fa]AB[aa:ba];W[ii])`
+
+func TestParseCollection(t *testing.T) {
+	path := writeTempSGF(t, t.TempDir(), "three.sgf", collectionSGF)
+	games, err := ParseCollection(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 3 {
+		t.Fatalf("%d games, want 3", len(games))
+	}
+	for i, want := range []struct {
+		black  string
+		size   int
+		moves  int
+		result string
+	}{{"ann", 9, 2, "B+R"}, {"cy", 7, 3, "W+2.5"}, {"ed", 9, 1, ""}} {
+		g := games[i]
+		if g.PlayerBlack != want.black || g.BoardSize != want.size || g.MoveCount != want.moves || g.Result != want.result {
+			t.Errorf("game %d: %s %dx%d, %d moves, %q", i+1, g.PlayerBlack, g.BoardSize, g.BoardSize, g.MoveCount, g.Result)
+		}
+		if g.GameIndex != i || g.Games != 3 || g.FilePath != path {
+			t.Errorf("game %d: index %d of %d, path %s", i+1, g.GameIndex, g.Games, g.FilePath)
+		}
+	}
+
+	// A single game is a collection of one
+	single := writeTempSGF(t, t.TempDir(), "one.sgf", testSGF)
+	if games, err := ParseCollection(single); err != nil || len(games) != 1 || games[0].Games != 1 {
+		t.Errorf("ParseCollection of one game = %d games, %v", len(games), err)
+	}
+}
+
+func TestCollectionGameIndex(t *testing.T) {
+	path := writeTempSGF(t, t.TempDir(), "three.sgf", collectionSGF)
+
+	info, err := ParseHeader(path)
+	if err != nil || info.PlayerBlack != "ann" || info.GameIndex != 0 || info.Games != 3 {
+		t.Errorf("ParseHeader = %+v, %v; want the first of 3 games", info, err)
+	}
+	if info, err := ParseHeader(path, 1); err != nil || info.PlayerBlack != "cy" || info.GameIndex != 1 {
+		t.Errorf("ParseHeader(1) = %+v, %v; want the second game", info, err)
+	}
+	if _, err := ParseHeader(path, 3); err == nil || !strings.Contains(err.Error(), "no game 4") {
+		t.Errorf("ParseHeader(3) error %v, want no game 4", err)
+	}
+
+	// By default only the first game's moves are on the board
+	board, moves, err := ReplayToEnd(path)
+	if err != nil || moves != 2 || len(board) != 9 || board[3][3] != 0 {
+		t.Errorf("ReplayToEnd: %dx%d, %d moves, dd %v, %v; want game 1 alone", len(board), len(board), moves, board[3][3], err)
+	}
+	board, moves, err = ReplayToEnd(path, 1)
+	if err != nil || moves != 3 || len(board) != 7 || board[3][3] != 1 {
+		t.Errorf("ReplayToEnd(1): %dx%d, %d moves, %v", len(board), len(board), moves, err)
+	}
+	if blacks, _, err := ParseSetupPositions(path, 2); err != nil || strings.Join(blacks, " ") != "aa ba" {
+		t.Errorf("ParseSetupPositions(2) = %v, %v", blacks, err)
+	}
+	if entries, err := ParseMovesAsEntries(path, 2); err != nil || len(entries) != 1 || entries[0] != [3]int{2, 8, 8} {
+		t.Errorf("ParseMovesAsEntries(2) = %v, %v", entries, err)
+	}
+	r, err := OpenReplayer(path, 1)
+	if err != nil || r.Size() != 7 || r.Len() != 3 {
+		t.Fatalf("OpenReplayer(1): %v", err)
+	}
+}
+
+func TestBranchFromCollection(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempSGF(t, dir, "three.sgf", collectionSGF)
+	rec, err := BranchGameRecord(path, dir, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Close()
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil || info.BoardSize != 7 || info.MoveCount != 2 || info.Games != 1 {
+		t.Fatalf("branch = %+v, %v", info, err)
+	}
+	if !strings.Contains(info.GameComment, "game 2 of three.sgf at move 2") {
+		t.Errorf("branch GC %q", info.GameComment)
+	}
+
+	// Played on in place, the first game would be written back alone
+	if open, err := OpenGameRecord(path); !errors.Is(err, ErrCollection) {
+		if open != nil {
+			open.Close()
+		}
+		t.Errorf("OpenGameRecord on a collection: %v, want ErrCollection", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != collectionSGF {
+		t.Errorf("collection after OpenGameRecord:\n%s", data)
+	}
+}
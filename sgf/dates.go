@@ -0,0 +1,108 @@
+package sgf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// nameLayout is the timestamp a record's file name starts with, and
+// dateLayout the format of its DT property.
+const (
+	nameLayout = "2006-01-02_150405"
+	dateLayout = "2006-01-02"
+)
+
+// maxClockSkew is how far a file name's timestamp may be from DT before
+// the name is taken to be wrong. DT has no time of day, so anything
+// within a day either side is the same game day.
+const maxClockSkew = 24 * time.Hour
+
+// RecordName returns the file name for a game started at t.
+func RecordName(t time.Time, boardSize int) string {
+	return fmt.Sprintf("%s_%dx%d.sgf", t.Format(nameLayout), boardSize, boardSize)
+}
+
+// NameTime parses the timestamp at the start of a record's file name,
+// e.g. "2026-01-15_093000_19x19.sgf".
+func NameTime(name string) (time.Time, bool) {
+	if len(name) < len(nameLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(nameLayout, name[:len(nameLayout)])
+	return t, err == nil
+}
+
+// parseDT reads the first date of a DT property. SGF allows a list of
+// days and partial dates; only a full first date is used.
+func parseDT(dt string) (time.Time, bool) {
+	if len(dt) < len(dateLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(dateLayout, dt[:len(dateLayout)])
+	return t, err == nil
+}
+
+// GameDate is when a game was played, as decided by ResolveDate.
+type GameDate struct {
+	Time  time.Time
+	Note  string // what was adjusted and why; empty when the name was right
+	SetDT bool   // the game has no DT; write Time's date into it
+}
+
+// ResolveDate decides when a game was played from its DT property, the
+// file name's timestamp and the file's modification time:
+//
+//   - DT and the name's timestamp agree to within a day: the name's
+//     timestamp, which also has the time of day.
+//   - They are further apart, or the name has no timestamp: DT's day, at
+//     the name's time of day if it has one.
+//   - No DT at all: the modification time, and DT should be written.
+//   - A DT that can't be read: the name's timestamp, else the
+//     modification time. The DT is left as it is.
+func ResolveDate(dt, name string, modTime time.Time) GameDate {
+	named, hasName := NameTime(name)
+	if strings.TrimSpace(dt) == "" {
+		return GameDate{
+			Time:  modTime,
+			Note:  fmt.Sprintf("no DT; using the file time %s", modTime.Format(dateLayout)),
+			SetDT: true,
+		}
+	}
+	day, ok := parseDT(dt)
+	if !ok {
+		note := fmt.Sprintf("unreadable DT[%s]", dt)
+		if hasName {
+			return GameDate{Time: named, Note: note}
+		}
+		return GameDate{Time: modTime, Note: note + fmt.Sprintf("; using the file time %s", modTime.Format(dateLayout))}
+	}
+	if !hasName {
+		return GameDate{Time: day}
+	}
+
+	namedDay := time.Date(named.Year(), named.Month(), named.Day(), 0, 0, 0, 0, time.UTC)
+	skew := namedDay.Sub(day)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew <= maxClockSkew {
+		return GameDate{Time: named}
+	}
+	clock := named.Sub(namedDay)
+	return GameDate{
+		Time: day.Add(clock),
+		Note: fmt.Sprintf("name says %s but DT is %s; using DT", named.Format(dateLayout), day.Format(dateLayout)),
+	}
+}
+
+// SetDate adds a DT property for t to the root node of an SGF game. Used
+// for games that have none, so later reads agree on the date.
+func SetDate(content string, t time.Time) string {
+	start, end, ok := rootSpan(content)
+	if !ok {
+		return content
+	}
+	end = start + len(strings.TrimRight(content[start:end], " \t\r\n"))
+	return content[:end] + fmt.Sprintf("DT[%s]", t.Format(dateLayout)) + content[end:]
+}
@@ -0,0 +1,79 @@
+package sgf
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveDate(t *testing.T) {
+	mtime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse("2006-01-02 15:04:05", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		name, dt, file string
+		want           time.Time
+		note           string // substring; "" means no note
+		setDT          bool
+	}{
+		{"agree", "2026-01-15", "2026-01-15_093000_9x9.sgf", at("2026-01-15 09:30:00"), "", false},
+		{"a day out", "2026-01-15", "2026-01-16_003000_9x9.sgf", at("2026-01-16 00:30:00"), "", false},
+		{"skewed", "2026-01-15", "2026-02-24_093000_9x9.sgf", at("2026-01-15 09:30:00"), "using DT", false},
+		{"skewed back", "2026-02-24", "2026-01-15_093000_9x9.sgf", at("2026-02-24 09:30:00"), "using DT", false},
+		{"date list", "2026-01-15,16", "2026-01-15_093000_9x9.sgf", at("2026-01-15 09:30:00"), "", false},
+		{"plain name", "2026-01-15", "game.sgf", at("2026-01-15 00:00:00"), "", false},
+		{"no DT", "", "2026-01-15_093000_9x9.sgf", mtime, "no DT", true},
+		{"no DT plain name", "", "game.sgf", mtime, "no DT", true},
+		{"bad DT", "last week", "2026-01-15_093000_9x9.sgf", at("2026-01-15 09:30:00"), "unreadable", false},
+		{"bad DT plain name", "2026", "game.sgf", mtime, "file time", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ResolveDate(tt.dt, tt.file, mtime)
+			if !d.Time.Equal(tt.want) {
+				t.Errorf("Time = %v, want %v", d.Time, tt.want)
+			}
+			if tt.note == "" && d.Note != "" || !strings.Contains(d.Note, tt.note) {
+				t.Errorf("Note = %q, want %q", d.Note, tt.note)
+			}
+			if d.SetDT != tt.setDT {
+				t.Errorf("SetDT = %v, want %v", d.SetDT, tt.setDT)
+			}
+		})
+	}
+}
+
+func TestSetDate(t *testing.T) {
+	in := "(;GM[1]SZ[9]C[a;b]\n;B[ee])"
+	got := SetDate(in, time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC))
+	if want := "(;GM[1]SZ[9]C[a;b]DT[2026-01-15]\n;B[ee])"; got != want {
+		t.Errorf("SetDate = %q, want %q", got, want)
+	}
+	if d := parseProperties(got).get("DT"); d != "2026-01-15" {
+		t.Errorf("DT read back as %q", d)
+	}
+}
+
+func TestVerifyWarnsOnClockSkew(t *testing.T) {
+	dir := t.TempDir()
+	const game = "(;GM[1]FF[4]SZ[9]DT[2026-01-15];B[ee])"
+	skewed := writeTempSGF(t, dir, "2026-03-01_100000_9x9.sgf", game)
+	fine := writeTempSGF(t, dir, "2026-01-15_100000_9x9.sgf", game)
+
+	r, err := Verify(skewed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.OK() || len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], "DT is 2026-01-15") {
+		t.Errorf("skewed file: problems %q, warnings %q", r.Problems, r.Warnings)
+	}
+	if r, _ := Verify(fine); len(r.Warnings) != 0 {
+		t.Errorf("matching file warned: %q", r.Warnings)
+	}
+}
@@ -0,0 +1,57 @@
+package sgf
+
+import "fmt"
+
+// DiffKind classifies one point when comparing two positions.
+type DiffKind int
+
+const (
+	DiffEmpty   DiffKind = iota // empty in both
+	DiffSame                    // same stone in both
+	DiffOnlyA                   // stone only in A
+	DiffOnlyB                   // stone only in B
+	DiffChanged                 // black in one, white in the other
+)
+
+// PositionDiff compares two positions of the same size point by point.
+type PositionDiff struct {
+	A, B  [][]int      // the positions compared (board[y][x])
+	Kinds [][]DiffKind // Kinds[y][x]
+
+	Same, OnlyA, OnlyB, Changed int // number of points of each kind
+}
+
+// DiffPositions compares positions a and b, typically the final boards of
+// two games. Boards of different sizes can't be compared.
+func DiffPositions(a, b [][]int) (*PositionDiff, error) {
+	if len(a) != len(b) {
+		return nil, fmt.Errorf("board sizes differ: %dx%d vs %dx%d", len(a), len(a), len(b), len(b))
+	}
+	d := &PositionDiff{A: a, B: b, Kinds: make([][]DiffKind, len(a))}
+	for y := range a {
+		if len(a[y]) != len(b[y]) {
+			return nil, fmt.Errorf("board row %d differs in length", y)
+		}
+		d.Kinds[y] = make([]DiffKind, len(a[y]))
+		for x := range a[y] {
+			sa, sb := a[y][x], b[y][x]
+			switch {
+			case sa == 0 && sb == 0:
+				continue
+			case sa == sb:
+				d.Kinds[y][x] = DiffSame
+				d.Same++
+			case sb == 0:
+				d.Kinds[y][x] = DiffOnlyA
+				d.OnlyA++
+			case sa == 0:
+				d.Kinds[y][x] = DiffOnlyB
+				d.OnlyB++
+			default:
+				d.Kinds[y][x] = DiffChanged
+				d.Changed++
+			}
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,53 @@
+package sgf
+
+import "testing"
+
+func TestDiffPositions(t *testing.T) {
+	a := boardFromRows(
+		"XX.",
+		"O..",
+		".O.",
+	)
+	b := boardFromRows(
+		"X.X",
+		"O..",
+		".X.",
+	)
+	d, err := DiffPositions(a, b)
+	if err != nil {
+		t.Fatalf("DiffPositions: %v", err)
+	}
+	if d.Same != 2 || d.OnlyA != 1 || d.OnlyB != 1 || d.Changed != 1 {
+		t.Errorf("counts: same %d, only A %d, only B %d, changed %d; want 2, 1, 1, 1",
+			d.Same, d.OnlyA, d.OnlyB, d.Changed)
+	}
+	want := [][]DiffKind{
+		{DiffSame, DiffOnlyA, DiffOnlyB},
+		{DiffSame, DiffEmpty, DiffEmpty},
+		{DiffEmpty, DiffChanged, DiffEmpty},
+	}
+	for y := range want {
+		for x := range want[y] {
+			if d.Kinds[y][x] != want[y][x] {
+				t.Errorf("Kinds[%d][%d] = %d, want %d", y, x, d.Kinds[y][x], want[y][x])
+			}
+		}
+	}
+}
+
+func TestDiffPositionsIdentical(t *testing.T) {
+	a := boardFromRows("X.", ".O")
+	d, err := DiffPositions(a, boardFromRows("X.", ".O"))
+	if err != nil {
+		t.Fatalf("DiffPositions: %v", err)
+	}
+	if d.Same != 2 || d.OnlyA+d.OnlyB+d.Changed != 0 {
+		t.Errorf("identical boards: %+v", d)
+	}
+}
+
+func TestDiffPositionsSizeMismatch(t *testing.T) {
+	if _, err := DiffPositions(MakeBoard(9), MakeBoard(13)); err == nil {
+		t.Error("comparing 9x9 with 13x13 should fail")
+	}
+}
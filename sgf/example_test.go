@@ -0,0 +1,48 @@
+package sgf_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"termsuji-local/sgf"
+)
+
+func ExampleParseHeader() {
+	dir, _ := os.MkdirTemp("", "sgf-example")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "game.sgf")
+	os.WriteFile(path, []byte("(;GM[1]FF[4]SZ[9]KM[6.5]PB[Alice]PW[Bob]RE[W+2.5];B[ee];W[cc])"), 0644)
+
+	info, err := sgf.ParseHeader(path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%dx%d, komi %.1f, %s vs %s, %s after %d moves\n",
+		info.BoardSize, info.BoardSize, info.Komi, info.PlayerBlack, info.PlayerWhite, info.Result, info.MoveCount)
+	// Output:
+	// 9x9, komi 6.5, Alice vs Bob, W+2.5 after 2 moves
+}
+
+func ExampleNewGameRecord() {
+	dir, _ := os.MkdirTemp("", "sgf-example")
+	defer os.RemoveAll(dir)
+
+	// Human plays black against a level 5 engine.
+	rec, err := sgf.NewGameRecord(dir, 9, 6.5, 1, 5, sgf.RecordOptions{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	rec.AddMove(4, 4, 1)
+	rec.AddMove(-1, -1, 2) // pass
+	rec.SetResult("Black wins by resignation")
+	rec.Close()
+
+	board, moves, _ := sgf.ReplayToEnd(rec.FilePath)
+	fmt.Println(moves, "moves, center stone:", board[4][4], "result:", rec.Result)
+	// Output:
+	// 2 moves, center stone: 1 result: B+R
+}
@@ -0,0 +1,117 @@
+package sgf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// rootModeled are the root properties GameRecord writes itself; any other
+// root property is carried over as it was.
+var rootModeled = map[string]bool{
+	"GM": true, "FF": true, "CA": true, "AP": true, "SZ": true, "KM": true, "HA": true,
+	"PB": true, "PW": true, "DT": true, "RE": true, "AB": true, "AW": true, "AE": true, "PL": true, "C": true,
+	"TM": true, "OT": true, "GC": true, "RU": true, "GN": true, "EV": true,
+}
+
+// moveModeled and setupModeled are the same for move nodes and the setup
+// node before the first move. Of the nodes without a move later on, only
+// the setup stones are modeled, as the setup node takes them.
+var (
+	moveModeled  = map[string]bool{"B": true, "W": true, "C": true, "AB": true, "AW": true, "AE": true}
+	setupModeled = map[string]bool{"AB": true, "AW": true, "AE": true, "PL": true}
+	laterModeled = map[string]bool{"AB": true, "AW": true, "AE": true}
+)
+
+// rawProp is a property as a file has it: the identifier, and the text from
+// the identifier through its last value, escapes and all.
+type rawProp struct {
+	key  string
+	text string
+}
+
+// rawProperties splits a node into its properties, keeping their text.
+func rawProperties(node string) []rawProp {
+	var props []rawProp
+	for _, p := range tokenizeNode(node) {
+		props = append(props, rawProp{key: p.key, text: p.text})
+	}
+	return props
+}
+
+// unmodeled returns the text of the properties in node not in modeled, run
+// together, and their identifiers.
+func unmodeled(node string, modeled map[string]bool) (text string, keys []string) {
+	var b strings.Builder
+	for _, p := range rawProperties(node) {
+		if !modeled[p.key] {
+			b.WriteString(p.text)
+			keys = append(keys, p.key)
+		}
+	}
+	return b.String(), keys
+}
+
+// recordExtras is what an SGF file holds beyond what GameRecord models.
+type recordExtras struct {
+	root    string         // unmodeled root properties, as written
+	setup   string         // unmodeled properties of the setup node
+	moves   map[int]string // unmodeled move node properties, keyed by move index
+	after   map[int]string // nodes without a move after the move of that index, ";" and all
+	dropped []string       // what can't be written back
+}
+
+// readExtras collects the properties of content that GameRecord would
+// otherwise drop, and describes the ones it has to.
+func readExtras(content string) recordExtras {
+	var x recordExtras
+	x.root, _ = unmodeled(rootNode(content), rootModeled)
+
+	// Only the first game counts; readGameRecord notes any others
+	if games := splitCollection(content); len(games) > 0 && scanStructure(games[0]).trees > 1 {
+		x.dropped = append(x.dropped, "variations")
+	}
+	lost := map[string]bool{}
+	moves, setupSeen := 0, false
+	for _, node := range parseNodes(content) {
+		if _, _, _, ok := parseMoveNode(node); ok {
+			if text, _ := unmodeled(node, moveModeled); text != "" {
+				if x.moves == nil {
+					x.moves = make(map[int]string)
+				}
+				x.moves[moves] = text
+			}
+			moves++
+			continue
+		}
+		if moves > 0 {
+			// A closing comment or markup on its own node, kept after
+			// the move before it
+			if text, _ := unmodeled(node, laterModeled); text != "" {
+				if x.after == nil {
+					x.after = make(map[int]string)
+				}
+				x.after[moves-1] += ";" + text
+			}
+			continue
+		}
+		text, keys := unmodeled(node, setupModeled)
+		if !setupSeen {
+			// The one setup node is written back, with these
+			x.setup, setupSeen = text, true
+			continue
+		}
+		for _, k := range keys {
+			lost[k] = true
+		}
+	}
+	if len(lost) > 0 {
+		keys := make([]string, 0, len(lost))
+		for k := range lost {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		x.dropped = append(x.dropped, fmt.Sprintf("%s before the first move", strings.Join(keys, ", ")))
+	}
+	return x
+}
@@ -0,0 +1,137 @@
+package sgf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// richSGF has properties GameRecord doesn't model on the root, the setup
+// node and the moves, with escapes and spacing to keep.
+const richSGF = "(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]BR[5k]WR[3d]OT[5x30 byo-yomi]" +
+	"GN[club \\] night]DT[2026-01-15]RE[B+R]\n" +
+	";AB[cc]PL[W]MN[10]\n" +
+	";W[ee]LB[ee:A] [gg:B];B[gc]TR[gc][cg]C[tesuji?];W[cg]SQ[dd])\n"
+
+// untouched are the property texts the file must still hold, byte for byte.
+var untouched = []string{
+	"BR[5k]", "WR[3d]", "OT[5x30 byo-yomi]", "GN[club \\] night]",
+	"MN[10]", "LB[ee:A] [gg:B]", "TR[gc][cg]",
+}
+
+func openRich(t *testing.T, content string) *GameRecord {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rich.sgf")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := OpenGameRecord(path)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	return rec
+}
+
+func TestUnknownPropertiesSurviveContinue(t *testing.T) {
+	rec := openRich(t, richSGF)
+	if d := rec.Dropped(); len(d) != 0 {
+		t.Errorf("Dropped = %v, want nothing", d)
+	}
+	rec.AddComment(1, "slack")
+	rec.UndoMoves(1) // the SQ move goes, and its SQ with it
+	rec.AddMove(3, 3, 2)
+	rec.SetResult("W+2.5")
+	rec.Close()
+
+	data, err := os.ReadFile(rec.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, prop := range untouched {
+		if strings.Count(got, prop) != 1 {
+			t.Errorf("%s not kept once in:\n%s", prop, got)
+		}
+	}
+	if strings.Contains(got, "SQ[") || strings.Contains(got, "tesuji?") {
+		t.Errorf("undone move and replaced comment still there:\n%s", got)
+	}
+	if !strings.Contains(got, ";B[gc]TR[gc][cg]C[slack]") {
+		t.Errorf("commented move lost its marks:\n%s", got)
+	}
+
+	// A second cycle leaves the file as it was.
+	again, err := OpenGameRecord(rec.FilePath)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	again.SetResult("W+2.5")
+	again.Close()
+	if data, _ := os.ReadFile(rec.FilePath); string(data) != got {
+		t.Errorf("second save changed the file:\n%s\nwas:\n%s", data, got)
+	}
+}
+
+func TestDroppedProperties(t *testing.T) {
+	for _, tc := range []struct {
+		name, content string
+		want          []string
+	}{
+		{"variations", "(;GM[1]SZ[9];B[ee](;W[cc])(;W[gg]))", []string{"variations"}},
+		{"second setup node", "(;GM[1]SZ[9];AB[cc];LB[cc:x]TR[dd];B[ee])", []string{"LB, TR before the first move"}},
+	} {
+		rec := openRich(t, tc.content)
+		if got := rec.Dropped(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Dropped = %q, want %q", tc.name, got, tc.want)
+		}
+		rec.Close()
+	}
+}
+
+func TestRawProperties(t *testing.T) {
+	got := rawProperties("AddBlack[aa] [bb]C[a\\]b]LB[cc:1]")
+	want := []rawProp{{"AB", "AddBlack[aa] [bb]"}, {"C", "C[a\\]b]"}, {"LB", "LB[cc:1]"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rawProperties = %q, want %q", got, want)
+	}
+}
+
+func TestContinueChangesOnlyTheMoves(t *testing.T) {
+	// As the record writes it, so that only what continuing adds differs
+	before := "(;GM[1]FF[4]CA[UTF-8]AP[termsuji-local:dev]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]DT[2026-01-15]RE[?]" +
+		"RU[Japanese]GN[club night]C[annotated elsewhere]PC[the club]\n" +
+		";B[ee]LB[ee:A][cc:B]C[the plan: A then B];W[cc]SQ[cc]" +
+		";C[White should have played B\\]];LB[dd:x])\n"
+	rec := openRich(t, before)
+	if d := rec.Dropped(); len(d) != 0 {
+		t.Errorf("Dropped = %v, want nothing", d)
+	}
+	rec.AddMove(6, 6, 1)
+	rec.AddMove(2, 6, 2)
+	rec.Close()
+
+	data, err := os.ReadFile(rec.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(before, ")\n", ";B[gg];W[cg])\n", 1)
+	if string(data) != want {
+		t.Errorf("after two moves:\n%s\nwant:\n%s", data, want)
+	}
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil || info.MoveCount != 4 || info.MoveComments[0] != "the plan: A then B" {
+		t.Errorf("reread = %+v, %v", info, err)
+	}
+}
+
+func TestUndoDropsLaterNodes(t *testing.T) {
+	rec := openRich(t, "(;GM[1]SZ[9];B[ee];C[after ee];W[cc];C[after cc]LB[cc:x])")
+	rec.UndoMoves(1)
+	rec.Close()
+	data, _ := os.ReadFile(rec.FilePath)
+	if got := string(data); !strings.Contains(got, ";B[ee];C[after ee])") || strings.Contains(got, "after cc") {
+		t.Errorf("after undoing W cc:\n%s", got)
+	}
+}
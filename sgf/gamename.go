@@ -0,0 +1,88 @@
+package sgf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetGameName names the game at path with GN[] in its root node, in place
+// of any name it had; an empty name removes it. The rest of the file is
+// kept byte for byte. The new file is written beside the old one and
+// renamed over it, so a failed write leaves the game as it was, and it
+// keeps the old modification time, so naming a game doesn't move it in
+// the history.
+func SetGameName(path, name string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(data)
+	start, end, ok := rootSpan(content)
+	if !ok {
+		return fmt.Errorf("%s has no game in it", filepath.Base(path))
+	}
+	named := renameRoot(content[start:end], name)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".rename-*.sgf")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // a no-op once renamed
+	if _, err := tmp.WriteString(content[:start] + named + content[end:]); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	os.Chmod(tmp.Name(), fi.Mode().Perm())
+	os.Chtimes(tmp.Name(), fi.ModTime(), fi.ModTime())
+	return os.Rename(tmp.Name(), path)
+}
+
+// renameRoot returns the root node text root with its GN[] set to name:
+// where the first one was, or at the end, and without any other. An empty
+// name removes them all.
+func renameRoot(root, name string) string {
+	// A name is one line of SimpleText
+	name = strings.Join(strings.Fields(name), " ")
+	gn := ""
+	if name != "" {
+		gn = "GN[" + escapeText(name) + "]"
+	}
+
+	var b strings.Builder
+	at, pos := 0, 0 // written up to at, found properties up to pos
+	for _, p := range rawProperties(root) {
+		// Properties are in order, with nothing but space between them
+		i := strings.Index(root[pos:], p.text)
+		if i == -1 {
+			break
+		}
+		i += pos
+		pos = i + len(p.text)
+		if p.key != "GN" {
+			continue
+		}
+		b.WriteString(root[at:i])
+		b.WriteString(gn)
+		gn = ""
+		at = pos
+	}
+	rest := root[at:]
+	body := strings.TrimRight(rest, " \t\r\n")
+	b.WriteString(body)
+	b.WriteString(gn)
+	b.WriteString(rest[len(body):])
+	return b.String()
+}
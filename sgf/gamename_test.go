@@ -0,0 +1,61 @@
+package sgf
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetGameName(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempSGF(t, dir, "game.sgf", testSGF)
+	played := time.Date(2026, 1, 15, 12, 30, 0, 0, time.Local)
+	os.Chtimes(path, played, played)
+
+	if err := SetGameName(path, "club night]"); err != nil {
+		t.Fatalf("SetGameName: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if got := strings.Replace(string(data), `GN[club night\]]`, "", 1); got != testSGF {
+		t.Errorf("named file:\n%s\nwant the original with GN[] added", data)
+	}
+	info, err := ParseHeader(path)
+	if err != nil || info.GameName != "club night]" {
+		t.Errorf("GameName = %+v, %v", info, err)
+	}
+	if fi, _ := os.Stat(path); !fi.ModTime().Equal(played) {
+		t.Errorf("modified %s, want %s as before", fi.ModTime(), played)
+	}
+
+	// Renaming replaces the name where it was; no name removes it
+	if err := SetGameName(path, "ladder\n study"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := ParseHeader(path); info.GameName != "ladder study" {
+		t.Errorf("renamed to %q", info.GameName)
+	}
+	if err := SetGameName(path, ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != testSGF {
+		t.Errorf("unnamed file:\n%s\nwant the original", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("%d files left in the directory, want just the game", len(entries))
+	}
+}
+
+func TestRenameRoot(t *testing.T) {
+	tests := []struct{ root, name, want string }{
+		{"GM[1]SZ[9]\n", "a", "GM[1]SZ[9]GN[a]\n"},
+		{"GM[1]GN[old]SZ[9]", "new", "GM[1]GN[new]SZ[9]"},
+		{"GN[x]C[GN[x\\]]GN[y]", "z", "GN[z]C[GN[x\\]]"},
+		{"GM[1] GN [old] SZ[9]", "", "GM[1]  SZ[9]"},
+	}
+	for _, tt := range tests {
+		if got := renameRoot(tt.root, tt.name); got != tt.want {
+			t.Errorf("renameRoot(%q, %q) = %q, want %q", tt.root, tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,8 @@ package sgf
 
 // GameNode represents a single position in the game tree.
 type GameNode struct {
-	Move     string      // ";B[pd]" or "" for root
+	Move     string // ";B[pd]", or "" for the root and other nodes without a move
+	Text     string // the node as read by ReadTree, ";B[pd]C[...]"; "" for nodes added with AddMove
 	Parent   *GameNode
 	Children []*GameNode // First child = main line
 }
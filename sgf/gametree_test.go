@@ -247,7 +247,7 @@ func TestDeepTree(t *testing.T) {
 		if i%2 == 1 {
 			color = "W"
 		}
-		tree.AddMove(";"+color+"[aa]")
+		tree.AddMove(";" + color + "[aa]")
 	}
 	path := tree.PathFromRoot()
 	if len(path) != 10 {
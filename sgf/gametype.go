@@ -0,0 +1,46 @@
+package sgf
+
+import "strings"
+
+// GameType says who was at the board. The zero value is the usual game, a
+// human against an engine; the others are games the human didn't play
+// against the engine, which stats should leave out.
+type GameType string
+
+const (
+	AgainstEngine GameType = ""
+	Hotseat       GameType = "hotseat"  // two humans taking turns at one keyboard
+	Rengo         GameType = "rengo"    // teams alternating within each side
+	Selfplay      GameType = "selfplay" // the engine against itself
+)
+
+// VsEngine reports whether t is a human's game against an engine.
+func (t GameType) VsEngine() bool {
+	return t == AgainstEngine
+}
+
+// gameTypePrefix starts the GC[] line naming a game's type, e.g.
+// "mode: hotseat". Games against an engine have no such line.
+const gameTypePrefix = "mode: "
+
+// joinGameType prepends the game type line to the rest of a GC[] value.
+func joinGameType(t GameType, gc string) string {
+	if t == AgainstEngine {
+		return gc
+	}
+	line := gameTypePrefix + string(t)
+	if gc == "" {
+		return line
+	}
+	return line + "\n" + gc
+}
+
+// splitGameType is the inverse of joinGameType. Types it doesn't know are
+// kept as they are, so they still count as not against the engine.
+func splitGameType(gc string) (GameType, string) {
+	if !strings.HasPrefix(gc, gameTypePrefix) {
+		return AgainstEngine, gc
+	}
+	line, rest, _ := strings.Cut(gc, "\n")
+	return GameType(strings.TrimSpace(strings.TrimPrefix(line, gameTypePrefix))), rest
+}
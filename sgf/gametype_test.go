@@ -0,0 +1,79 @@
+package sgf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSplitGameType(t *testing.T) {
+	tests := []struct {
+		gc       string
+		wantType GameType
+		wantRest string
+	}{
+		{"", AgainstEngine, ""},
+		{"club night", AgainstEngine, "club night"},
+		{"mode: hotseat", Hotseat, ""},
+		{"mode: rengo\nteams of two", Rengo, "teams of two"},
+		{"mode: pair go", GameType("pair go"), ""},
+	}
+	for _, tt := range tests {
+		gameType, rest := splitGameType(tt.gc)
+		if gameType != tt.wantType || rest != tt.wantRest {
+			t.Errorf("splitGameType(%q) = %q, %q; want %q, %q", tt.gc, gameType, rest, tt.wantType, tt.wantRest)
+		}
+		if back := joinGameType(gameType, rest); back != tt.gc {
+			t.Errorf("joinGameType undoes %q as %q", tt.gc, back)
+		}
+	}
+}
+
+func TestGameTypeSurvivesContinue(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.AddMove(4, 4, 1)
+	rec.SetGameType(Hotseat)
+	rec.Close()
+
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.GameType != Hotseat || info.GameType.VsEngine() {
+		t.Fatalf("GameType = %q, want hotseat", info.GameType)
+	}
+
+	// Continued and saved again, it is still a hotseat game
+	again, err := OpenGameRecord(rec.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again.AddMove(2, 2, 2)
+	again.SetResult("B+R")
+	again.Close()
+	data, err := os.ReadFile(rec.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "GC[mode: hotseat]"); n != 1 {
+		t.Errorf("mode written %d times, want once:\n%s", n, data)
+	}
+}
+
+func TestGameCommentKept(t *testing.T) {
+	rec := openRich(t, strings.Replace(richSGF, "RE[B+R]", "RE[B+R]GC[mode: rengo\nteam \\] A]", 1))
+	if rec.GameType != Rengo || rec.GameComment != "team ] A" {
+		t.Errorf("GameType, GameComment = %q, %q", rec.GameType, rec.GameComment)
+	}
+	rec.Close()
+	data, err := os.ReadFile(rec.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "GC[mode: rengo\nteam \\] A]") {
+		t.Errorf("game comment not written back:\n%s", data)
+	}
+}
@@ -0,0 +1,74 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// boardSizes are the sizes a game can be played or reviewed on.
+var boardSizes = []int{9, 13, 19}
+
+// CheckImport reads an SGF from outside the history directory, such as a
+// download from a go server, and returns its header. The error says why
+// it can't be opened: damage, a board size other than 9, 13 or 19, or
+// variations, which the move list can't follow.
+func CheckImport(filePath string) (*GameInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+	name := filepath.Base(filePath)
+
+	if problems, _ := VerifyContent(content); len(problems) > 0 {
+		return nil, fmt.Errorf("%s: %s", name, strings.Join(problems, "; "))
+	}
+	if scanStructure(content).trees > 1 {
+		return nil, fmt.Errorf("%s has variations or more than one game; only a single line of play can be loaded", name)
+	}
+	info, err := ParseHeader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	supported := false
+	for _, n := range boardSizes {
+		supported = supported || info.BoardSize == n
+	}
+	if !supported {
+		return nil, fmt.Errorf("%s is %dx%d; only 9x9, 13x13 and 19x19 are supported", name, info.BoardSize, info.BoardSize)
+	}
+	return info, nil
+}
+
+// ImportGame copies the SGF at filePath into dir under a record name for
+// now, so it lists with the saved games. It returns the new path.
+func ImportGame(filePath, dir string, boardSize int) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create history dir: %w", err)
+	}
+	src, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	path := filepath.Join(dir, RecordName(time.Now(), boardSize))
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("%s already exists; try again in a second", filepath.Base(path))
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, dst.Close()
+}
@@ -0,0 +1,52 @@
+package sgf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckImport(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, content string
+		wantErr       string // empty for a file that loads
+	}{
+		{"ok.sgf", "(;GM[1]FF[4]SZ[19]KM[6.5]PB[alice]PW[bob];B[pd];W[dp])", ""},
+		{"small.sgf", "(;GM[1]FF[4]SZ[7];B[dd];W[cc])", "only 9x9, 13x13 and 19x19"},
+		{"branches.sgf", "(;GM[1]FF[4]SZ[9];B[ee](;W[cc])(;W[gc]))", "variations"},
+		{"cut.sgf", "(;GM[1]FF[4]SZ[9];B[ee];W[c", "truncated"},
+	}
+	for _, tt := range tests {
+		path := writeTempSGF(t, dir, tt.name, tt.content)
+		info, err := CheckImport(path)
+		if tt.wantErr == "" {
+			if err != nil || info.PlayerBlack != "alice" || info.MoveCount != 2 {
+				t.Errorf("%s: CheckImport = %+v, %v", tt.name, info, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error %v, want one mentioning %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestImportGame(t *testing.T) {
+	src := writeTempSGF(t, t.TempDir(), "download.sgf", testSGF)
+	dir := filepath.Join(t.TempDir(), "history")
+
+	path, err := ImportGame(src, dir, 9)
+	if err != nil {
+		t.Fatalf("ImportGame: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != testSGF {
+		t.Errorf("copy reads %q, %v; want the original", data, err)
+	}
+	games, err := ListGames(dir)
+	if err != nil || len(games) != 1 || games[0].FilePath != path {
+		t.Errorf("ListGames after import = %v, %v", games, err)
+	}
+}
@@ -0,0 +1,15 @@
+package sgf
+
+import "strconv"
+
+// FormatKomi writes komi the way KM[] and GTP's komi command take it: as
+// few digits as it needs and always with a dot, so 7 is "7" and a quarter
+// komi keeps its second decimal.
+func FormatKomi(komi float64) string {
+	return strconv.FormatFloat(komi, 'f', -1, 64)
+}
+
+// ParseKomi reads komi written by FormatKomi, or by an engine's get_komi.
+func ParseKomi(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,41 @@
+package sgf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Komi goes into KM[] as written and comes back from the header unchanged.
+func TestKomiRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		komi float64
+		km   string
+	}{
+		{0, "KM[0]"},
+		{0.5, "KM[0.5]"},
+		{6.5, "KM[6.5]"},
+		{7, "KM[7]"},
+		{6.25, "KM[6.25]"},
+	} {
+		rec, err := NewGameRecord(t.TempDir(), 9, tc.komi, 1, 5, RecordOptions{})
+		if err != nil {
+			t.Fatalf("NewGameRecord: %v", err)
+		}
+		rec.Close()
+		content, _ := os.ReadFile(rec.FilePath)
+		if !strings.Contains(string(content), tc.km) {
+			t.Errorf("komi %v not written as %s:\n%s", tc.komi, tc.km, content)
+		}
+		info, err := ParseHeader(rec.FilePath)
+		if err != nil {
+			t.Fatalf("ParseHeader: %v", err)
+		}
+		if info.Komi != tc.komi {
+			t.Errorf("%s read back as %v", tc.km, info.Komi)
+		}
+		if got, err := ParseKomi(FormatKomi(tc.komi)); err != nil || got != tc.komi {
+			t.Errorf("ParseKomi(FormatKomi(%v)) = %v, %v", tc.komi, got, err)
+		}
+	}
+}
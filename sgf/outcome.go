@@ -0,0 +1,73 @@
+package sgf
+
+import "strings"
+
+// Outcome is how a game ended for the human: won, lost, drawn, or not
+// known.
+type Outcome int
+
+const (
+	OutcomeUnknown Outcome = iota // unfinished, void, no winner recorded, or not the human's game
+	OutcomeWin
+	OutcomeLoss
+	OutcomeDraw
+)
+
+// String returns "win", "loss", "draw" or "unknown".
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeWin:
+		return "win"
+	case OutcomeLoss:
+		return "loss"
+	case OutcomeDraw:
+		return "draw"
+	}
+	return "unknown"
+}
+
+// ResultFor classifies a result, an RE[] value or an engine's outcome such
+// as "White wins by resignation", for the player of color (1=black,
+// 2=white). Wins by resignation, on time or by forfeit count as any other;
+// a void or unknown result, or a color that is neither, is
+// OutcomeUnknown.
+func ResultFor(result string, color int) Outcome {
+	re := parseResult(result)
+	switch re {
+	case "0", "Jigo", "Draw":
+		return OutcomeDraw
+	case "Void", "?":
+		return OutcomeUnknown
+	}
+	var winner int
+	switch re[0] {
+	case 'B':
+		winner = 1
+	case 'W':
+		winner = 2
+	}
+	switch {
+	case color != 1 && color != 2:
+		return OutcomeUnknown
+	case winner == color:
+		return OutcomeWin
+	}
+	return OutcomeLoss
+}
+
+// OutcomeFor classifies game's result for the human, who plays under
+// "Player" or one of names. Games the human didn't play against the
+// engine, and imported games where neither side is the human or GnuGo,
+// are OutcomeUnknown.
+func (game GameInfo) OutcomeFor(names ...string) Outcome {
+	if !game.GameType.VsEngine() {
+		return OutcomeUnknown
+	}
+	known := func(name string) bool {
+		return isHuman(name, names) || strings.HasPrefix(name, "GnuGo")
+	}
+	if !known(game.PlayerBlack) && !known(game.PlayerWhite) {
+		return OutcomeUnknown
+	}
+	return ResultFor(game.Result, InferSetup(game, names...).PlayerColor)
+}
@@ -0,0 +1,58 @@
+package sgf
+
+import "testing"
+
+func TestResultFor(t *testing.T) {
+	for _, tc := range []struct {
+		result string
+		color  int
+		want   Outcome
+	}{
+		{"B+3.5", 1, OutcomeWin},
+		{"B+3.5", 2, OutcomeLoss},
+		{"W+R", 2, OutcomeWin}, // resignation
+		{"W+R", 1, OutcomeLoss},
+		{"B+T", 1, OutcomeWin}, // time
+		{"W+T", 1, OutcomeLoss},
+		{"B+F", 2, OutcomeLoss}, // forfeit
+		{"W+F", 2, OutcomeWin},
+		{"B+?", 1, OutcomeWin}, // winner without a margin
+		{"0", 1, OutcomeDraw},  // jigo
+		{"Jigo", 2, OutcomeDraw},
+		{"Draw", 1, OutcomeDraw},
+		{"W+0", 1, OutcomeDraw},
+		{"Void", 1, OutcomeUnknown},
+		{"?", 1, OutcomeUnknown},
+		{"", 2, OutcomeUnknown},                      // unfinished
+		{"White wins by resignation", 2, OutcomeWin}, // an engine's outcome
+		{"Black wins on time", 2, OutcomeLoss},
+		{"Black wins by 12,5 points", 1, OutcomeWin},
+		{"The game is a draw.", 2, OutcomeDraw},
+		{"B+3.5", 0, OutcomeUnknown}, // nobody's color
+	} {
+		if got := ResultFor(tc.result, tc.color); got != tc.want {
+			t.Errorf("ResultFor(%q, %d) = %v, want %v", tc.result, tc.color, got, tc.want)
+		}
+	}
+}
+
+func TestOutcomeFor(t *testing.T) {
+	for _, tc := range []struct {
+		game  GameInfo
+		names []string
+		want  Outcome
+	}{
+		{GameInfo{PlayerBlack: "Player", PlayerWhite: "GnuGo Level 5", Result: "B+R"}, nil, OutcomeWin},
+		{GameInfo{PlayerBlack: "GnuGo Level 5", PlayerWhite: "Player", Result: "B+R"}, nil, OutcomeLoss},
+		{GameInfo{PlayerBlack: "KataGo", PlayerWhite: "Ada", Result: "W+1.5"}, []string{"Ada"}, OutcomeWin},
+		{GameInfo{PlayerBlack: "Player", PlayerWhite: "GnuGo Level 5", Result: "0"}, nil, OutcomeDraw},
+		{GameInfo{PlayerBlack: "Player", PlayerWhite: "GnuGo Level 5"}, nil, OutcomeUnknown},
+		{GameInfo{PlayerBlack: "Lee Sedol", PlayerWhite: "AlphaGo", Result: "W+R"}, nil, OutcomeUnknown},
+		{GameInfo{PlayerBlack: "Player", PlayerWhite: "Player", Result: "B+2.5", GameType: Hotseat}, nil, OutcomeUnknown},
+	} {
+		if got := tc.game.OutcomeFor(tc.names...); got != tc.want {
+			t.Errorf("OutcomeFor(PB %q, PW %q, RE %q, %q) = %v, want %v",
+				tc.game.PlayerBlack, tc.game.PlayerWhite, tc.game.Result, tc.game.GameType, got, tc.want)
+		}
+	}
+}
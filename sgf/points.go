@@ -0,0 +1,35 @@
+package sgf
+
+// expandPoints returns the points a setup value stands for: the point
+// itself, e.g. "dd", or every point of a compressed rectangle, e.g.
+// "aa:cb" for aa ba ca ab bb cb, row by row. It returns nil for a value
+// that is neither.
+func expandPoints(value string) []string {
+	if len(value) == 2 {
+		return []string{value}
+	}
+	if len(value) != 5 || value[2] != ':' || !isLower(value[0]) || !isLower(value[1]) || !isLower(value[3]) || !isLower(value[4]) {
+		return nil
+	}
+	// The corners are meant to be upper left and lower right; take any two
+	x0, x1 := ordered(value[0], value[3])
+	y0, y1 := ordered(value[1], value[4])
+	points := make([]string, 0, int(x1-x0+1)*int(y1-y0+1))
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			points = append(points, string([]byte{x, y}))
+		}
+	}
+	return points
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func ordered(a, b byte) (byte, byte) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
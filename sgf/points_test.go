@@ -0,0 +1,67 @@
+package sgf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandPoints(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"dd", []string{"dd"}},
+		{"aa:cb", []string{"aa", "ba", "ca", "ab", "bb", "cb"}},
+		{"cb:aa", []string{"aa", "ba", "ca", "ab", "bb", "cb"}},
+		{"dd:dd", []string{"dd"}},
+		{"bc:bf", []string{"bc", "bd", "be", "bf"}},
+		{"", nil},
+		{"ddd", nil},
+		{"aa-cc", nil},
+		{"AA:cc", nil},
+	}
+	for _, tt := range tests {
+		if got := expandPoints(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandPoints(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCompressedSetup(t *testing.T) {
+	// A 3x2 block and a plain stone for Black, a column and a plain stone
+	// for White, then AE empties a corner of the block
+	content := "(;GM[1]FF[4]SZ[9]AB[aa:cb][ee]AW[gc:gf]AW[ii];AE[aa][gf];B[hh])"
+	path := writeTempSGF(t, t.TempDir(), "setup.sgf", content)
+
+	blacks, whites, err := ParseSetupPositions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(blacks, " "), "ba ca ab bb cb ee"; got != want {
+		t.Errorf("black setup %s, want %s", got, want)
+	}
+	if got, want := strings.Join(whites, " "), "gc gd ge ii"; got != want {
+		t.Errorf("white setup %s, want %s", got, want)
+	}
+
+	board, moves, err := ReplayToEnd(path)
+	if err != nil || moves != 1 {
+		t.Fatalf("ReplayToEnd: %d moves, %v", moves, err)
+	}
+	for _, p := range []struct {
+		point string
+		color int
+	}{{"aa", 0}, {"ba", 1}, {"cb", 1}, {"ee", 1}, {"gc", 2}, {"ge", 2}, {"gf", 0}, {"ii", 2}, {"hh", 1}} {
+		x, y := int(p.point[0]-'a'), int(p.point[1]-'a')
+		if board[y][x] != p.color {
+			t.Errorf("%s is %d, want %d", p.point, board[y][x], p.color)
+		}
+	}
+	if problems, _ := VerifyContent(content); len(problems) != 0 {
+		t.Errorf("VerifyContent: %v", problems)
+	}
+	if problems, _ := VerifyContent("(;GM[1]SZ[9]AB[aa:kk];B[ee])"); len(problems) != 1 {
+		t.Errorf("a rectangle off the board: %v", problems)
+	}
+}
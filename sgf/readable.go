@@ -0,0 +1,48 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotSGF is the error for a file that holds no SGF game: it doesn't
+// start with a game tree, "(;" or "(" and ";" with whitespace between.
+var ErrNotSGF = errors.New("not an SGF file")
+
+// ErrTruncated is the error for a game cut off before its end, as a crash
+// while it was written leaves it. Verify describes the damage and Repair
+// can often fix it.
+var ErrTruncated = errors.New("truncated")
+
+// maxBoardSize is the largest board SGF can describe.
+const maxBoardSize = 52
+
+// checkReadable returns ErrNotSGF or ErrTruncated for content that isn't
+// a whole game, nil for one that is.
+func checkReadable(content string) error {
+	trimmed := strings.TrimLeft(content, " \t\r\n\uFEFF")
+	if !strings.HasPrefix(trimmed, "(") || treeStart(content) == -1 {
+		return ErrNotSGF
+	}
+	if s := scanStructure(content); s.inValue || s.dangling || s.depth > 0 {
+		return ErrTruncated
+	}
+	return nil
+}
+
+// boardSize returns the board size SZ[] gives, 19 without one, or an error
+// for a size no board has.
+func boardSize(props properties) (int, error) {
+	sz := strings.TrimSpace(props.get("SZ"))
+	if sz == "" {
+		return 19, nil
+	}
+	n, err := strconv.Atoi(sz)
+	if err != nil || n < 1 || n > maxBoardSize {
+		// Rectangular boards, "19:13", are beyond this reader too
+		return 0, fmt.Errorf("bad board size SZ[%s]", sz)
+	}
+	return n, nil
+}
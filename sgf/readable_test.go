@@ -0,0 +1,59 @@
+package sgf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnreadableFiles(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    error
+	}{
+		{"truncated.sgf", "(;GM[1]SZ[9];B[ee];W[cc", ErrTruncated},
+		{"unclosed.sgf", "(;GM[1]SZ[9];B[ee];W[cc]", ErrTruncated},
+		{"dangling.sgf", "(;GM[1]SZ[9];B[ee];W", ErrTruncated},
+		{"notes.sgf", "a note saved with the wrong name\n", ErrNotSGF},
+		{"empty.sgf", "", ErrNotSGF},
+		{"no-node.sgf", "(GM[1]SZ[9])", ErrNotSGF},
+	}
+	for _, tt := range tests {
+		path := writeTempSGF(t, dir, tt.name, tt.content)
+		if _, err := ParseHeader(path); !errors.Is(err, tt.want) {
+			t.Errorf("ParseHeader(%s) error %v, want %v", tt.name, err, tt.want)
+		}
+		if _, _, err := ReplayToEnd(path); !errors.Is(err, tt.want) {
+			t.Errorf("ReplayToEnd(%s) error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRootOnlyFile(t *testing.T) {
+	path := writeTempSGF(t, t.TempDir(), "root.sgf", "\uFEFF\n(;GM[1]FF[4]SZ[13]KM[6.5])\n")
+	info, err := ParseHeader(path)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.BoardSize != 13 || info.MoveCount != 0 {
+		t.Errorf("got %dx%d with %d moves, want 13x13 with none", info.BoardSize, info.BoardSize, info.MoveCount)
+	}
+	board, moves, err := ReplayToEnd(path)
+	if err != nil || len(board) != 13 || moves != 0 {
+		t.Errorf("ReplayToEnd: %d rows, %d moves, %v; want an empty 13x13 board", len(board), moves, err)
+	}
+}
+
+func TestBadBoardSize(t *testing.T) {
+	dir := t.TempDir()
+	for _, sz := range []string{"-3", "0", "99", "19:13"} {
+		path := writeTempSGF(t, dir, "bad.sgf", "(;GM[1]SZ["+sz+"];B[aa])")
+		if _, err := ParseHeader(path); err == nil {
+			t.Errorf("ParseHeader accepted SZ[%s]", sz)
+		}
+		if _, _, err := ReplayToEnd(path); err == nil {
+			t.Errorf("ReplayToEnd accepted SZ[%s]", sz)
+		}
+	}
+}
@@ -6,80 +6,288 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // GameInfo holds metadata parsed from an SGF file header.
 type GameInfo struct {
 	FilePath    string
 	FileName    string
+	ModTime     time.Time // the file's modification time; set by ListGames
+	FileSize    int64     // the file's size in bytes; set by ListGames
 	BoardSize   int
 	Komi        float64
 	PlayerBlack string
 	PlayerWhite string
 	Date        string
 	Result      string
-	MoveCount   int
+	Comment     string        // root node C[], unescaped
+	MoveCount   int           // moves in the game, including any played before recording began
+	StartMove   int           // moves played before recording began (setup-based records)
+	NextColor   int           // side to move after the last recorded move: 1=black, 2=white
+	OutOfTurn   int           // moves played by the side not to move, e.g. a second Black move in a row
+	Handicap    int           // HA[], 0 for an even game
+	TimeLimit   time.Duration // TM[], each side's main time; 0 if untimed
+	Overtime    string        // OT[], unescaped, e.g. "1x30 byo-yomi"
+	GameType    GameType      // from the "mode: " line of GC[]; AgainstEngine without one
+	Seed        int64         // from the "seed: " line of GC[]; 0 without one
+	GameComment string        // GC[], unescaped, less the mode and seed lines
+	GameName    string        // GN[], unescaped; "" for an unnamed game
+	Ruleset     string        // RU[], unescaped, e.g. "Japanese"; "" if unsaid
+	Event       string        // EV[], unescaped
+	HasReport   bool          // a report was written beside the file; set by ListGames
+	GameIndex   int           // which game of the file this is, 0 for the first
+	Games       int           // games in the file: more than 1 for a collection
+	ReadErr     error         // why the file can't be read as a game, e.g. ErrTruncated; nil for one that can
+
+	// C[] on move nodes, unescaped, keyed by the move's index among the
+	// recorded moves (0 is the first move in the file)
+	MoveComments map[int]string
+
+	setupToPlay int // PL[] before the first move, 0 if absent
 }
 
 // ParseHeader reads an SGF file and extracts metadata from the root node.
-func ParseHeader(filePath string) (*GameInfo, error) {
+// In a collection of games it reads the first, or game[0] if given; see
+// ParseCollection.
+func ParseHeader(filePath string, game ...int) (*GameInfo, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
 	content := string(data)
+	if err := checkReadable(content); err != nil {
+		return nil, err
+	}
+	text, err := pickGame(content, game)
+	if err != nil {
+		return nil, err
+	}
+	info, err := parseHeader(filePath, text)
+	if err != nil {
+		return nil, err
+	}
+	if len(game) > 0 {
+		info.GameIndex = game[0]
+	}
+	info.Games = len(splitCollection(content))
+	return info, nil
+}
+
+// parseHeader is ParseHeader for the game in content, read from filePath.
+func parseHeader(filePath, content string) (*GameInfo, error) {
 	props := parseProperties(content)
 
-	boardSize := 19
-	if v, ok := props["SZ"]; ok {
-		if n, err := strconv.Atoi(v); err == nil {
-			boardSize = n
-		}
+	boardSize, err := boardSize(props)
+	if err != nil {
+		return nil, err
 	}
 
 	komi := 0.0
-	if v, ok := props["KM"]; ok {
-		if f, err := strconv.ParseFloat(v, 64); err == nil {
-			komi = f
+	if f, err := ParseKomi(strings.TrimSpace(props.get("KM"))); err == nil {
+		komi = f
+	}
+
+	handicap := 0
+	if n, err := strconv.Atoi(props.get("HA")); err == nil && n >= 2 {
+		handicap = n
+	}
+
+	startMove, comment := splitStartMove(unescapeText(props.get("C")))
+	gameType, gameComment := splitGameType(unescapeText(props.get("GC")))
+	seed, gameComment := splitSeed(gameComment)
+
+	var timeLimit time.Duration
+	if f, err := strconv.ParseFloat(props.get("TM"), 64); err == nil && f > 0 {
+		timeLimit = time.Duration(f * float64(time.Second))
+	}
+
+	// Walk the nodes for the move count, the last mover, and any PL set up
+	// before the first move (recordings started mid-game) or after the
+	// last.
+	toPlay := colorFromLetter(props.get("PL"))
+	endToPlay := 0
+	moves, lastColor := 0, 0
+	var comments map[int]string
+	var movers []int
+	for _, node := range parseNodes(content) {
+		if color, _, _, ok := parseMoveNode(node); ok {
+			if c := nodeComment(node); c != "" {
+				if comments == nil {
+					comments = make(map[int]string)
+				}
+				comments[moves] = c
+			}
+			moves++
+			lastColor = color
+			movers = append(movers, color)
+			endToPlay = 0
+		}
+		if !strings.Contains(node, "PL[") {
+			continue
+		}
+		setup := make(properties)
+		extractProps(node, setup)
+		if c := colorFromLetter(setup.get("PL")); c != 0 {
+			if moves == 0 {
+				toPlay = c
+			} else {
+				endToPlay = c
+			}
 		}
 	}
 
+	// Turn order comes from the record, not parity: a PL after the last
+	// move says who is next, otherwise it's the other side from the last
+	// mover, otherwise whoever PL names, otherwise White in a handicap game
+	// and Black in an even one.
+	firstColor := 1
+	switch {
+	case toPlay != 0:
+		firstColor = toPlay
+	case handicap > 0:
+		firstColor = 2
+	}
+	nextColor := firstColor
+	switch {
+	case endToPlay != 0:
+		nextColor = endToPlay
+	case lastColor != 0:
+		nextColor = 3 - lastColor
+	}
+
 	info := &GameInfo{
 		FilePath:    filePath,
 		FileName:    filepath.Base(filePath),
 		BoardSize:   boardSize,
 		Komi:        komi,
-		PlayerBlack: props["PB"],
-		PlayerWhite: props["PW"],
-		Date:        props["DT"],
-		Result:      props["RE"],
-		MoveCount:   countMoves(content),
+		PlayerBlack: unescapeText(props.get("PB")),
+		PlayerWhite: unescapeText(props.get("PW")),
+		Date:        props.get("DT"),
+		Result:      props.get("RE"),
+		Comment:     comment,
+		MoveCount:   startMove + moves,
+		StartMove:   startMove,
+		NextColor:   nextColor,
+		OutOfTurn:   outOfTurn(movers, firstColor),
+		Handicap:    handicap,
+		TimeLimit:   timeLimit,
+		Overtime:    unescapeText(props.get("OT")),
+		GameType:    gameType,
+		Seed:        seed,
+		GameComment: gameComment,
+		GameName:    unescapeText(props.get("GN")),
+		Ruleset:     unescapeText(props.get("RU")),
+		Event:       unescapeText(props.get("EV")),
+
+		MoveComments: comments,
+		setupToPlay:  toPlay,
 	}
 
 	return info, nil
 }
 
+// outOfTurn counts the moves in movers, their colors in order, that weren't
+// by the side to move, starting with first. Each move passes the turn to
+// the other side, whoever played it.
+func outOfTurn(movers []int, first int) int {
+	n := 0
+	next := first
+	for _, c := range movers {
+		if c != next {
+			n++
+		}
+		next = 3 - c
+	}
+	return n
+}
+
+// startMovePrefix starts the root comment line recording how many moves
+// were played before a mid-game recording began.
+const startMovePrefix = "recording started after move "
+
+// joinStartMove prepends the start-move line to a root comment.
+func joinStartMove(startMove int, comment string) string {
+	if startMove <= 0 {
+		return comment
+	}
+	line := startMovePrefix + strconv.Itoa(startMove)
+	if comment == "" {
+		return line
+	}
+	return line + "\n" + comment
+}
+
+// splitStartMove is the inverse of joinStartMove.
+func splitStartMove(comment string) (int, string) {
+	if !strings.HasPrefix(comment, startMovePrefix) {
+		return 0, comment
+	}
+	line, rest, _ := strings.Cut(comment, "\n")
+	n, err := strconv.Atoi(strings.TrimPrefix(line, startMovePrefix))
+	if err != nil {
+		return 0, comment
+	}
+	return n, rest
+}
+
+// colorFromLetter converts an SGF color value ("B"/"W") to 1/2, or 0.
+func colorFromLetter(v string) int {
+	switch strings.ToUpper(strings.TrimSpace(v)) {
+	case "B":
+		return 1
+	case "W":
+		return 2
+	}
+	return 0
+}
+
 // ReplayToEnd parses an SGF file and replays all moves to produce the final board position.
+// In a collection it replays the first game, or game[0] if given.
 // Returns the board (board[y][x], 0=empty, 1=black, 2=white), the move count, and any error.
-func ReplayToEnd(filePath string) ([][]int, int, error) {
-	data, err := os.ReadFile(filePath)
+func ReplayToEnd(filePath string, game ...int) ([][]int, int, error) {
+	pos, err := ReplayFinal(filePath, game...)
 	if err != nil {
 		return nil, 0, err
 	}
+	return pos.Board, pos.Moves, nil
+}
 
-	content := string(data)
+// FinalPosition is where a game's record leaves it.
+type FinalPosition struct {
+	Board         [][]int // board[y][x], 0=empty, 1=black, 2=white
+	Moves         int     // moves in the record, passes included
+	LastX, LastY  int     // the last move; -1, -1 for a pass or no move
+	LastColor     int     // who played it: 1=black, 2=white, 0 with no moves
+	CapturesBlack int     // white stones captured by Black
+	CapturesWhite int     // black stones captured by White
+}
+
+// ReplayFinal replays the game at filePath as ReplayToEnd does, keeping
+// the last move and the captures on the way.
+func ReplayFinal(filePath string, game ...int) (FinalPosition, error) {
+	pos := FinalPosition{LastX: -1, LastY: -1}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return pos, err
+	}
+
+	if err := checkReadable(string(data)); err != nil {
+		return pos, err
+	}
+	content, err := pickGame(string(data), game)
+	if err != nil {
+		return pos, err
+	}
 	props := parseProperties(content)
 
-	boardSize := 19
-	if v, ok := props["SZ"]; ok {
-		if n, err := strconv.Atoi(v); err == nil {
-			boardSize = n
-		}
+	boardSize, err := boardSize(props)
+	if err != nil {
+		return pos, err
 	}
 
 	board := MakeBoard(boardSize)
-	moveCount := 0
 
 	// Apply setup positions (AB/AW)
 	applySetup(content, board, boardSize)
@@ -91,18 +299,26 @@ func ReplayToEnd(filePath string) ([][]int, int, error) {
 		if !ok {
 			continue
 		}
-		moveCount++
+		pos.Moves++
+		pos.LastX, pos.LastY, pos.LastColor = -1, -1, color
 		if x == -1 && y == -1 {
 			continue // pass
 		}
 		if x < 0 || x >= boardSize || y < 0 || y >= boardSize {
 			continue
 		}
+		pos.LastX, pos.LastY = x, y
 		board[y][x] = color
-		RemoveCaptures(board, boardSize, x, y, color)
+		taken := RemoveCaptures(board, boardSize, x, y, color)
+		if color == 1 {
+			pos.CapturesBlack += taken
+		} else {
+			pos.CapturesWhite += taken
+		}
 	}
 
-	return board, moveCount, nil
+	pos.Board = board
+	return pos, nil
 }
 
 // MakeBoard creates an empty boardSize x boardSize board.
@@ -115,146 +331,81 @@ func MakeBoard(size int) [][]int {
 }
 
 // parseProperties extracts KEY[value] pairs from the root node of an SGF string.
-func parseProperties(content string) map[string]string {
-	props := make(map[string]string)
-
-	// Find the root node: starts after "(;"
-	start := strings.Index(content, "(;")
-	if start == -1 {
-		return props
-	}
-	start += 2 // skip "(;"
-
-	// Root node ends at the next ";" or ")"
-	end := len(content)
-	for i := start; i < len(content); i++ {
-		if content[i] == ';' || content[i] == ')' {
-			end = i
-			break
-		}
-	}
-
-	root := content[start:end]
-	extractProps(root, props)
+func parseProperties(content string) properties {
+	props := make(properties)
+	extractProps(rootNode(content), props)
 	return props
 }
 
-// extractProps parses KEY[value] pairs from a node string into the map.
-func extractProps(node string, props map[string]string) {
-	i := 0
-	for i < len(node) {
-		// Skip whitespace
-		for i < len(node) && (node[i] == ' ' || node[i] == '\n' || node[i] == '\r' || node[i] == '\t') {
-			i++
-		}
-		if i >= len(node) {
-			break
-		}
-
-		// Read property identifier (uppercase letters)
-		keyStart := i
-		for i < len(node) && node[i] >= 'A' && node[i] <= 'Z' {
-			i++
-		}
-		if i == keyStart {
-			i++
-			continue
-		}
-		key := node[keyStart:i]
-
-		// Read all property values (e.g., AB[aa][bb][cc])
-		for i < len(node) && node[i] == '[' {
-			i++ // skip '['
-			valStart := i
-			for i < len(node) && node[i] != ']' {
-				if node[i] == '\\' && i+1 < len(node) {
-					i++ // skip escaped char
-				}
-				i++
-			}
-			val := node[valStart:i]
-			if i < len(node) {
-				i++ // skip ']'
-			}
-			props[key] = val // last value wins for simple props
-		}
+// rootNode returns the text of the root node, after its leading ";".
+func rootNode(content string) string {
+	start, end, ok := rootSpan(content)
+	if !ok {
+		return ""
 	}
+	return content[start:end]
 }
 
-// countMoves counts the number of move nodes (;B[...] or ;W[...]) in the SGF.
-func countMoves(content string) int {
-	count := 0
-	i := 0
-	for i < len(content) {
-		if content[i] == ';' && i+1 < len(content) {
-			next := content[i+1]
-			if (next == 'B' || next == 'W') && i+2 < len(content) && content[i+2] == '[' {
-				count++
-			}
-		}
-		i++
+// properties maps each property identifier in a node to its values, in
+// file order. Values are raw: text escapes are left in place.
+type properties map[string][]string
+
+// get returns the value of a single-valued property such as PB or SZ
+// (the last one if it was repeated), or "" if it is absent.
+func (p properties) get(key string) string {
+	values := p[key]
+	if len(values) == 0 {
+		return ""
 	}
-	return count
+	return values[len(values)-1]
 }
 
-// parseNodes returns all node strings after the root node.
-func parseNodes(content string) []string {
-	var nodes []string
-
-	// Find first ";" after "(;"
-	start := strings.Index(content, "(;")
-	if start == -1 {
-		return nodes
+// extractProps parses KEY[value][value]... pairs from a node string into
+// props, as tokenizeNode reads them.
+func extractProps(node string, props properties) {
+	for _, p := range tokenizeNode(node) {
+		props[p.key] = append(props[p.key], p.values...)
 	}
-	start += 2
+}
 
-	// Skip root node to find subsequent ";"
-	i := start
-	for i < len(content) {
-		if content[i] == ';' {
-			break
-		}
-		if content[i] == '[' {
-			// Skip value
-			i++
-			for i < len(content) && content[i] != ']' {
-				if content[i] == '\\' && i+1 < len(content) {
-					i++
-				}
-				i++
-			}
-		}
-		i++
+// unescapeText removes SGF text escapes: a backslash keeps the character after it.
+func unescapeText(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
 	}
-
-	// Now parse subsequent nodes
-	for i < len(content) {
-		if content[i] == ';' {
-			nodeStart := i
-			i++
-			// Read until next ';' or ')'
-			for i < len(content) && content[i] != ';' && content[i] != ')' {
-				if content[i] == '[' {
-					i++
-					for i < len(content) && content[i] != ']' {
-						if content[i] == '\\' && i+1 < len(content) {
-							i++
-						}
-						i++
-					}
-				}
-				i++
-			}
-			nodes = append(nodes, content[nodeStart:i])
-		} else {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
 			i++
 		}
+		b.WriteByte(s[i])
 	}
+	return b.String()
+}
 
+// parseNodes returns the node strings of the main line after the root
+// node, leaving out the variations.
+func parseNodes(content string) []string {
+	root := parseTree(content)
+	if root == nil {
+		return nil
+	}
+	var nodes []string
+	for _, n := range root.MainLine()[1:] {
+		nodes = append(nodes, n.Text)
+	}
 	return nodes
 }
 
-// parseMoveNode extracts color and coordinates from a move node like ";B[pd]".
+// nodeComment returns the unescaped C[] text of a node, or "".
+func nodeComment(node string) string {
+	props := make(properties)
+	extractProps(node, props)
+	return unescapeText(props.get("C"))
+}
+
+// parseMoveNode extracts color and coordinates from a move node like ";B[pd]":
+// its first B[] or W[] property, wherever it is in the node.
 // Returns color (1=black, 2=white), x, y, and whether it's a valid move node.
 // Pass moves return x=-1, y=-1.
 func parseMoveNode(node string) (color, x, y int, ok bool) {
@@ -263,92 +414,96 @@ func parseMoveNode(node string) (color, x, y int, ok bool) {
 		return 0, 0, 0, false
 	}
 
-	ch := node[1]
-	if ch != 'B' && ch != 'W' {
-		return 0, 0, 0, false
-	}
-
-	color = 1
-	if ch == 'W' {
-		color = 2
-	}
-
-	// Find the value in brackets
-	bracketStart := strings.Index(node, "[")
-	bracketEnd := strings.Index(node, "]")
-	if bracketStart == -1 || bracketEnd == -1 || bracketEnd <= bracketStart {
-		return 0, 0, 0, false
-	}
-
-	coord := node[bracketStart+1 : bracketEnd]
-	if coord == "" {
-		// Pass
-		return color, -1, -1, true
-	}
-
-	if len(coord) != 2 {
-		return 0, 0, 0, false
+	for _, p := range tokenizeNode(node) {
+		switch p.key {
+		case "B":
+			color = 1
+		case "W":
+			color = 2
+		default:
+			continue
+		}
+		coord := strings.TrimSpace(p.values[0])
+		if coord == "" {
+			// Pass
+			return color, -1, -1, true
+		}
+		if len(coord) != 2 {
+			return 0, 0, 0, false
+		}
+		x = int(coord[0] - 'a')
+		y = int(coord[1] - 'a')
+		return color, x, y, true
 	}
-
-	x = int(coord[0] - 'a')
-	y = int(coord[1] - 'a')
-	return color, x, y, true
+	return 0, 0, 0, false
 }
 
-// applySetup applies AB[]/AW[] setup properties from the SGF content.
+// applySetup applies the AB[]/AW[]/AE[] setup properties from the SGF
+// content.
 func applySetup(content string, board [][]int, boardSize int) {
-	// Find setup node (second node with AB/AW)
-	// It could also be in the root node or a subsequent node
-	i := strings.Index(content, "(;")
-	if i == -1 {
-		return
-	}
-
-	// Scan through all nodes looking for AB/AW
-	for i < len(content) {
-		if content[i] == 'A' && i+1 < len(content) && (content[i+1] == 'B' || content[i+1] == 'W') {
-			color := 1
-			if content[i+1] == 'W' {
-				color = 2
+	blacks, whites := setupPoints(content)
+	for color, points := range map[int][]string{1: blacks, 2: whites} {
+		for _, p := range points {
+			x, y := int(p[0]-'a'), int(p[1]-'a')
+			if x >= 0 && x < boardSize && y >= 0 && y < boardSize {
+				board[y][x] = color
 			}
-			i += 2
-
-			// Read all coordinate values
-			for i < len(content) && content[i] == '[' {
-				i++ // skip '['
-				if i+1 < len(content) && content[i+1] != ']' {
-					coordStr := ""
-					start := i
-					for i < len(content) && content[i] != ']' {
-						i++
-					}
-					coordStr = content[start:i]
-					if len(coordStr) == 2 {
-						x := int(coordStr[0] - 'a')
-						y := int(coordStr[1] - 'a')
-						if x >= 0 && x < boardSize && y >= 0 && y < boardSize {
-							board[y][x] = color
-						}
+		}
+	}
+}
+
+// setupColors are the setup properties and the color each leaves on its
+// points, 0 for AE[], which empties them.
+var setupColors = []struct {
+	key   string
+	color int
+}{{"AB", 1}, {"AW", 2}, {"AE", 0}}
+
+// setupPoints collects the stones the AB[]/AW[] properties of the root and
+// the other nodes of the main line set up, with compressed point lists
+// expanded, less those a later AE[] takes away. Points come in the order
+// they were first set up; values that aren't points are skipped.
+func setupPoints(content string) (blacks, whites []string) {
+	if treeStart(content) == -1 {
+		return nil, nil
+	}
+	colors := make(map[string]int)
+	var order []string
+	for _, node := range append([]string{rootNode(content)}, parseNodes(content)...) {
+		props := make(properties)
+		extractProps(node, props)
+		for _, setup := range setupColors {
+			for _, v := range props[setup.key] {
+				for _, p := range expandPoints(v) {
+					if _, seen := colors[p]; !seen {
+						order = append(order, p)
 					}
-				}
-				if i < len(content) {
-					i++ // skip ']'
+					colors[p] = setup.color
 				}
 			}
-		} else {
-			i++
 		}
 	}
+	for _, p := range order {
+		switch colors[p] {
+		case 1:
+			blacks = append(blacks, p)
+		case 2:
+			whites = append(whites, p)
+		}
+	}
+	return blacks, whites
 }
 
 // RemoveCaptures checks and removes any opponent groups adjacent to (x, y) that have zero liberties.
-func RemoveCaptures(board [][]int, size, x, y, color int) {
+// It returns the number of stones removed.
+func RemoveCaptures(board [][]int, size, x, y, color int) int {
 	opponent := 1
 	if color == 1 {
 		opponent = 2
 	}
 
 	// Check all four neighbors
+	removed := 0
 	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
 		nx, ny := x+d[0], y+d[1]
 		if nx < 0 || nx >= size || ny < 0 || ny >= size {
@@ -356,10 +511,38 @@ func RemoveCaptures(board [][]int, size, x, y, color int) {
 		}
 		if board[ny][nx] == opponent {
 			if !hasLiberties(board, size, nx, ny, opponent) {
-				removeGroup(board, size, nx, ny, opponent)
+				removed += removeGroup(board, size, nx, ny, opponent)
 			}
 		}
 	}
+	return removed
+}
+
+// KoPoint returns the point the opponent may not retake at straight away
+// after color played (x, y) and captured stones: where the single stone
+// taken was, when the stone that took it stands alone in atari there.
+func KoPoint(board [][]int, size, x, y, color, captured int) (kx, ky int, ok bool) {
+	if captured != 1 {
+		return -1, -1, false
+	}
+	liberties := 0
+	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		nx, ny := x+d[0], y+d[1]
+		if nx < 0 || nx >= size || ny < 0 || ny >= size {
+			continue
+		}
+		switch board[ny][nx] {
+		case color:
+			return -1, -1, false
+		case 0:
+			liberties++
+			kx, ky = nx, ny
+		}
+	}
+	if liberties != 1 {
+		return -1, -1, false
+	}
+	return kx, ky, true
 }
 
 // HasLiberty checks if the group at (x, y) has any liberties using flood fill.
@@ -400,105 +583,66 @@ func hasLibertiesDFS(board [][]int, visited [][]bool, size, x, y, color int) boo
 	return false
 }
 
-// removeGroup removes all stones in the group at (x, y) of the given color.
-func removeGroup(board [][]int, size, x, y, color int) {
+// removeGroup removes all stones in the group at (x, y) of the given color
+// and returns how many there were.
+func removeGroup(board [][]int, size, x, y, color int) int {
 	if x < 0 || x >= size || y < 0 || y >= size {
-		return
+		return 0
 	}
 	if board[y][x] != color {
-		return
+		return 0
 	}
 	board[y][x] = 0
+	n := 1
 	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
-		removeGroup(board, size, x+d[0], y+d[1], color)
+		n += removeGroup(board, size, x+d[0], y+d[1], color)
 	}
+	return n
 }
 
 // ParseMovesForRecord parses an SGF file and returns moves in the format used by GameRecord.moves
-// (e.g., ";B[pd]", ";W[]" for passes).
-func ParseMovesForRecord(filePath string) ([]string, error) {
-	data, err := os.ReadFile(filePath)
+// (e.g., ";B[pd]", ";W[]" for passes), of the first game or game[0].
+func ParseMovesForRecord(filePath string, game ...int) ([]string, error) {
+	content, err := readGame(filePath, game)
 	if err != nil {
 		return nil, err
 	}
 
-	content := string(data)
 	nodes := parseNodes(content)
 
 	var moves []string
 	for _, node := range nodes {
-		color, x, y, ok := parseMoveNode(node)
-		if !ok {
-			continue
-		}
-		colorChar := "B"
-		if color == 2 {
-			colorChar = "W"
-		}
-		if x == -1 && y == -1 {
-			moves = append(moves, fmt.Sprintf(";%s[]", colorChar))
-		} else {
-			moves = append(moves, fmt.Sprintf(";%s[%s]", colorChar, string(rune('a'+x))+string(rune('a'+y))))
+		if move := moveText(node); move != "" {
+			moves = append(moves, move)
 		}
 	}
 
 	return moves, nil
 }
 
-// ParseSetupPositions parses AB[]/AW[] setup positions from an SGF file.
-// Returns black coords and white coords in SGF letter-pair format (e.g., "dd", "pp").
-func ParseSetupPositions(filePath string) ([]string, []string, error) {
-	data, err := os.ReadFile(filePath)
+// ParseSetupPositions parses AB[]/AW[] setup positions from an SGF file,
+// less the points AE[] empties.
+// Returns black coords and white coords in SGF letter-pair format (e.g., "dd", "pp"),
+// with compressed point lists such as "aa:cc" expanded. In a collection it
+// reads the first game, or game[0] if given.
+func ParseSetupPositions(filePath string, game ...int) ([]string, []string, error) {
+	content, err := readGame(filePath, game)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	content := string(data)
-	var blacks, whites []string
-
-	i := strings.Index(content, "(;")
-	if i == -1 {
-		return blacks, whites, nil
-	}
-
-	for i < len(content) {
-		if content[i] == 'A' && i+1 < len(content) && (content[i+1] == 'B' || content[i+1] == 'W') {
-			isBlack := content[i+1] == 'B'
-			i += 2
-
-			for i < len(content) && content[i] == '[' {
-				i++ // skip '['
-				start := i
-				for i < len(content) && content[i] != ']' {
-					i++
-				}
-				coord := content[start:i]
-				if i < len(content) {
-					i++ // skip ']'
-				}
-				if len(coord) == 2 {
-					if isBlack {
-						blacks = append(blacks, coord)
-					} else {
-						whites = append(whites, coord)
-					}
-				}
-			}
-		} else {
-			i++
-		}
-	}
-
+	blacks, whites := setupPoints(content)
 	return blacks, whites, nil
 }
 
-// ParseMovesAsEntries returns all moves as (color, x, y) triples.
-func ParseMovesAsEntries(filePath string) ([][3]int, error) {
-	data, err := os.ReadFile(filePath)
+// ParseMovesAsEntries returns the main line's moves as (color, x, y)
+// triples, of the first game or game[0].
+func ParseMovesAsEntries(filePath string, game ...int) ([][3]int, error) {
+	content, err := readGame(filePath, game)
 	if err != nil {
 		return nil, err
 	}
-	nodes := parseNodes(string(data))
+	nodes := parseNodes(content)
 	var result [][3]int
 	for _, node := range nodes {
 		color, x, y, ok := parseMoveNode(node)
@@ -513,6 +657,27 @@ func ParseMovesAsEntries(filePath string) ([][3]int, error) {
 // ListGames scans a directory for .sgf files and returns their parsed headers,
 // sorted newest-first (by filename, which contains timestamps).
 func ListGames(dir string) ([]GameInfo, error) {
+	files, err := ListGameFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var games []GameInfo
+	for _, f := range files {
+		info, err := ParseHeader(f.FilePath)
+		if err != nil {
+			continue
+		}
+		info.ModTime, info.FileSize, info.HasReport = f.ModTime, f.FileSize, f.HasReport
+		games = append(games, *info)
+	}
+	return games, nil
+}
+
+// ListGameFiles lists the .sgf files in dir as ListGames does, without
+// reading them: each GameInfo has only FilePath, FileName, ModTime,
+// FileSize, HasReport and, from a record name, Date. BoardSize is 0 until
+// the header is read.
+func ListGameFiles(dir string) ([]GameInfo, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -521,19 +686,57 @@ func ListGames(dir string) ([]GameInfo, error) {
 		return nil, fmt.Errorf("read history dir: %w", err)
 	}
 
-	var games []GameInfo
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	var files []GameInfo
 	for i := len(entries) - 1; i >= 0; i-- {
 		e := entries[i]
 		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sgf") {
 			continue
 		}
-		path := filepath.Join(dir, e.Name())
-		info, err := ParseHeader(path)
+		f := GameInfo{FilePath: filepath.Join(dir, e.Name()), FileName: e.Name()}
+		f.HasReport = names[filepath.Base(ReportPath(e.Name()))]
+		if fi, err := e.Info(); err == nil {
+			f.ModTime, f.FileSize = fi.ModTime(), fi.Size()
+		}
+		if t, ok := NameTime(e.Name()); ok {
+			f.Date = t.Format(dateLayout)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// LastUnfinished returns the newest game in dir that was left in progress:
+// its result still "?", at least one move played, and not ended by two
+// passes. Games Verify finds damaged are passed over.
+func LastUnfinished(dir string) (GameInfo, bool) {
+	games, err := ListGames(dir)
+	if err != nil {
+		return GameInfo{}, false
+	}
+	return Unfinished(games)
+}
+
+// Unfinished is LastUnfinished over games already listed, newest first.
+func Unfinished(games []GameInfo) (GameInfo, bool) {
+	for _, game := range games {
+		if game.Result != "?" || game.MoveCount == 0 {
+			continue
+		}
+		if r, err := Verify(game.FilePath); err != nil || !r.OK() {
+			continue
+		}
+		moves, err := ParseMovesAsEntries(game.FilePath)
 		if err != nil {
 			continue
 		}
-		games = append(games, *info)
+		if n := len(moves); n >= 2 && moves[n-1][1] == -1 && moves[n-2][1] == -1 {
+			continue
+		}
+		return game, true
 	}
-
-	return games, nil
+	return GameInfo{}, false
 }
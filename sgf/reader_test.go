@@ -1,6 +1,7 @@
 package sgf
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -308,11 +309,34 @@ func TestListGamesNonexistentDir(t *testing.T) {
 	}
 }
 
+func TestLastUnfinished(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := LastUnfinished(dir); ok {
+		t.Fatal("an empty history has nothing to continue")
+	}
+
+	writeTempSGF(t, dir, "2026-01-10_100000_9x9.sgf", `(;GM[1]FF[4]SZ[9]KM[6.5]PB[GnuGo Level 3]PW[Player]RE[?];B[ee];W[cc])`)
+	writeTempSGF(t, dir, "2026-01-11_100000_9x9.sgf", `(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]RE[B+3.5];B[ee];W[cc])`)
+	writeTempSGF(t, dir, "2026-01-12_100000_9x9.sgf", `(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]RE[?];B[ee];W[cc];B[];W[])`)
+	writeTempSGF(t, dir, "2026-01-13_100000_9x9.sgf", `(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]RE[?];B[ee];W[cc];B[gg`)
+	writeTempSGF(t, dir, "2026-01-14_100000_9x9.sgf", `(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]RE[?])`)
+
+	// Finished, ended by passes, truncated and moveless games are all
+	// passed over for the oldest
+	game, ok := LastUnfinished(dir)
+	if !ok || game.FileName != "2026-01-10_100000_9x9.sgf" {
+		t.Fatalf("LastUnfinished = %q, %v", game.FileName, ok)
+	}
+	if game.MoveCount != 2 || InferSetup(game).PlayerColor != 2 || InferSetup(game).EngineLevel != 3 {
+		t.Errorf("move %d, setup %+v", game.MoveCount, InferSetup(game))
+	}
+}
+
 func TestWriterThenReader(t *testing.T) {
 	dir := t.TempDir()
 
 	// Write a game using the writer
-	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -358,3 +382,231 @@ func TestWriterThenReader(t *testing.T) {
 		t.Errorf("board[6][6] = %d, want 1", board[6][6])
 	}
 }
+
+func TestParsePropertiesMultiValue(t *testing.T) {
+	props := parseProperties("(;GM[1]SZ[19]AB[dd][pp]\n[dp]AW[pd];B[qq])")
+	if got := props["AB"]; len(got) != 3 || got[0] != "dd" || got[1] != "pp" || got[2] != "dp" {
+		t.Errorf("AB = %q, want [dd pp dp]", got)
+	}
+	if got := props.get("AW"); got != "pd" {
+		t.Errorf("AW = %q, want pd", got)
+	}
+	if _, ok := props["B"]; ok {
+		t.Error("move node leaked into the root properties")
+	}
+}
+
+func TestParsePropertiesWhitespaceBeforeValue(t *testing.T) {
+	props := parseProperties("(;GM[1]\nSZ [13]\nPB\n  [Player]KM\t[6.5])")
+	for key, want := range map[string]string{"SZ": "13", "PB": "Player", "KM": "6.5"} {
+		if got := props.get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestParsePropertiesLowercaseNoise(t *testing.T) {
+	// FF[3] allowed lowercase letters in identifiers; they don't count.
+	props := parseProperties("(;GaMe[1]SiZe[9]ff FF[4] AddBlack[cc][gg]PlayerWhite[GnuGo])")
+	for key, want := range map[string]string{"GM": "1", "SZ": "9", "FF": "4", "PW": "GnuGo"} {
+		if got := props.get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if got := props["AB"]; len(got) != 2 {
+		t.Errorf("AB = %q, want 2 points", got)
+	}
+	if _, ok := props["ff"]; ok {
+		t.Error("lowercase noise became a property")
+	}
+}
+
+func TestSetupPointsFromParsedNodes(t *testing.T) {
+	dir := t.TempDir()
+	content := "(;GM[1]SZ[9]AB[cc]\n[gg]AW [cg];AB[ee]PL[W];W[gc])"
+	path := writeTempSGF(t, dir, "setup.sgf", content)
+
+	blacks, whites, err := ParseSetupPositions(path)
+	if err != nil {
+		t.Fatalf("ParseSetupPositions: %v", err)
+	}
+	if len(blacks) != 3 || len(whites) != 1 {
+		t.Errorf("setup = B%q W%q, want 3 black and 1 white", blacks, whites)
+	}
+
+	board, _, err := ReplayToEnd(path)
+	if err != nil {
+		t.Fatalf("ReplayToEnd: %v", err)
+	}
+	for _, p := range [][3]int{{2, 2, 1}, {6, 6, 1}, {4, 4, 1}, {2, 6, 2}, {6, 2, 2}} {
+		if board[p[1]][p[0]] != p[2] {
+			t.Errorf("board[%d][%d] = %d, want %d", p[1], p[0], board[p[1]][p[0]], p[2])
+		}
+	}
+}
+
+func TestKoPoint(t *testing.T) {
+	// Black takes the white stone at (2,1) from (3,1): a ko White can't
+	// retake at once.
+	board := boardFromRows(
+		".XO..",
+		"XO.O.",
+		".XO..",
+		".....",
+		".....",
+	)
+	board[1][2] = 1
+	n := RemoveCaptures(board, 5, 2, 1, 1)
+	if kx, ky, ok := KoPoint(board, 5, 2, 1, 1, n); !ok || kx != 1 || ky != 1 {
+		t.Errorf("KoPoint = (%d,%d) %v, want (1,1)", kx, ky, ok)
+	}
+
+	// Taking two stones is no ko.
+	board = boardFromRows(
+		".XXO.",
+		"XOO.O",
+		".XXO.",
+		".....",
+		".....",
+	)
+	board[1][3] = 1
+	n = RemoveCaptures(board, 5, 3, 1, 1)
+	if _, _, ok := KoPoint(board, 5, 3, 1, 1, n); n != 2 || ok {
+		t.Errorf("took %d stones, ko %v; want 2 and no ko", n, ok)
+	}
+
+	// Nor is a capture by a stone joined to its group.
+	board = boardFromRows(
+		".X...",
+		"XOX..",
+		"X....",
+		".....",
+		".....",
+	)
+	board[2][1] = 1
+	n = RemoveCaptures(board, 5, 1, 2, 1)
+	if _, _, ok := KoPoint(board, 5, 1, 2, 1, n); n != 1 || ok {
+		t.Errorf("a capture that leaves the stone with its group is no ko")
+	}
+
+	// Nor one that leaves the capturing stone more than one liberty.
+	board = boardFromRows(
+		"XO...",
+		".....",
+		".....",
+		".....",
+		".....",
+	)
+	board[1][0] = 2
+	n = RemoveCaptures(board, 5, 0, 1, 2)
+	if _, _, ok := KoPoint(board, 5, 0, 1, 2, n); n != 1 || ok {
+		t.Errorf("took %d, ko %v; want 1 and no ko", n, ok)
+	}
+}
+
+func TestRemoveCapturesCount(t *testing.T) {
+	board := boardFromRows(
+		"OOX..",
+		"XX...",
+		".....",
+		"..O..",
+		".XOX.",
+	)
+	// A move at (2,1) takes nothing: the corner pair has no liberties,
+	// but it isn't next to the move.
+	if n := RemoveCaptures(board, 5, 2, 1, 1); n != 0 {
+		t.Errorf("removed %d stones with no neighbour in atari", n)
+	}
+	if n := RemoveCaptures(board, 5, 1, 1, 1); n != 2 || board[0][0] != 0 || board[0][1] != 0 {
+		t.Errorf("removed %d, corner %v; want the pair of 2 taken", n, board[0][:2])
+	}
+	board[3][1], board[3][3] = 1, 1
+	board[2][2] = 1
+	if n := RemoveCaptures(board, 5, 2, 2, 1); n != 2 {
+		t.Errorf("removed %d, want the pair of 2 in the middle", n)
+	}
+}
+
+func TestParseHeaderIrregularTurns(t *testing.T) {
+	tests := []struct {
+		file      string
+		next      int
+		outOfTurn int
+		moves     [][3]int
+	}{
+		{"double-black.sgf", 2, 1, [][3]int{{1, 4, 4}, {2, 2, 2}, {1, 6, 6}, {1, 2, 6}}},
+		{"white-first.sgf", 1, 1, [][3]int{{2, 4, 4}, {1, 2, 2}, {2, 6, 6}}},
+	}
+	for _, tt := range tests {
+		path := filepath.Join("testdata", "irregular", tt.file)
+		info, err := ParseHeader(path)
+		if err != nil {
+			t.Fatalf("%s: ParseHeader: %v", tt.file, err)
+		}
+		if info.NextColor != tt.next || info.OutOfTurn != tt.outOfTurn {
+			t.Errorf("%s: NextColor = %d, OutOfTurn = %d; want %d, %d", tt.file, info.NextColor, info.OutOfTurn, tt.next, tt.outOfTurn)
+		}
+		// Each move keeps the color it was recorded with
+		moves, err := ParseMovesAsEntries(path)
+		if err != nil {
+			t.Fatalf("%s: ParseMovesAsEntries: %v", tt.file, err)
+		}
+		if fmt.Sprint(moves) != fmt.Sprint(tt.moves) {
+			t.Errorf("%s: moves = %v, want %v", tt.file, moves, tt.moves)
+		}
+	}
+}
+
+func TestParseHeaderPlayerToMoveAtEnd(t *testing.T) {
+	path := writeTempSGF(t, t.TempDir(), "pl.sgf", "(;SZ[9];B[ee];W[cc];PL[W])")
+	info, err := ParseHeader(path)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.NextColor != 2 || info.OutOfTurn != 0 {
+		t.Errorf("NextColor = %d, OutOfTurn = %d; want 2 from PL, 0", info.NextColor, info.OutOfTurn)
+	}
+}
+
+func TestListGameFilesReports(t *testing.T) {
+	dir := t.TempDir()
+	writeTempSGF(t, dir, "2026-01-10_100000_9x9.sgf", `(;GM[1]SZ[9]RE[B+R])`)
+	writeTempSGF(t, dir, "2026-01-11_100000_9x9.sgf", `(;GM[1]SZ[9]RE[W+R])`)
+	report := ReportPath(filepath.Join(dir, "2026-01-10_100000_9x9.sgf"))
+	if filepath.Base(report) != "2026-01-10_100000_9x9.report.txt" {
+		t.Fatalf("ReportPath = %s", report)
+	}
+	os.WriteFile(report, []byte("report"), 0644)
+
+	files, err := ListGameFiles(dir)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("ListGameFiles: %v, %v", files, err)
+	}
+	if files[0].HasReport || !files[1].HasReport {
+		t.Errorf("HasReport %v, %v; want only the game of Jan 10", files[0].HasReport, files[1].HasReport)
+	}
+}
+
+func TestReplayFinal(t *testing.T) {
+	dir := t.TempDir()
+	// White captures the black stone at aa, then Black passes
+	path := writeTempSGF(t, dir, "capture.sgf", `(;GM[1]SZ[9];B[aa];W[ba];B[ee];W[ab];B[])`)
+	pos, err := ReplayFinal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos.Moves != 5 || pos.Board[0][0] != 0 || pos.CapturesWhite != 1 || pos.CapturesBlack != 0 {
+		t.Errorf("moves %d, aa %d, captures B %d W %d", pos.Moves, pos.Board[0][0], pos.CapturesBlack, pos.CapturesWhite)
+	}
+	if pos.LastX != -1 || pos.LastY != -1 || pos.LastColor != 1 {
+		t.Errorf("last move %d,%d by %d; want Black's pass", pos.LastX, pos.LastY, pos.LastColor)
+	}
+
+	path = writeTempSGF(t, dir, "move.sgf", `(;GM[1]SZ[9];B[ee];W[cg])`)
+	if pos, _ = ReplayFinal(path); pos.LastX != 2 || pos.LastY != 6 || pos.LastColor != 2 {
+		t.Errorf("last move %d,%d by %d; want White's at 2,6", pos.LastX, pos.LastY, pos.LastColor)
+	}
+	if pos, _ = ReplayFinal(writeTempSGF(t, dir, "empty.sgf", `(;GM[1]SZ[9])`)); pos.LastColor != 0 || pos.LastX != -1 {
+		t.Errorf("a game without moves has last move %d,%d by %d", pos.LastX, pos.LastY, pos.LastColor)
+	}
+}
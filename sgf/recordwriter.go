@@ -0,0 +1,245 @@
+package sgf
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+var debugLog = log.New(io.Discard, "", 0)
+
+// SetDebugLog sends notes on changes a RecordWriter refuses to w. They are
+// off by default.
+func SetDebugLog(w io.Writer) {
+	debugLog = log.New(w, "", log.Ltime|log.Lmicroseconds)
+}
+
+// writeQueueSize is how many changes may wait for the disk before callers
+// block. Each change is written and synced as a whole, so a slow disk falls
+// behind by whole moves, never by partial ones.
+const writeQueueSize = 256
+
+// fileCheckInterval is how often a RecordWriter checks, between changes,
+// that its file is still there. Tests shorten it.
+var fileCheckInterval = 5 * time.Second
+
+// RecordStatus is how writing a record is going.
+type RecordStatus struct {
+	Err       error // why the last write failed, nil once one succeeds
+	Recreated bool  // the last write found the file gone and created it again
+}
+
+// RecordWriter applies changes to a GameRecord on a dedicated goroutine,
+// so the engine and UI goroutines that report moves never wait on file
+// I/O. Changes are written in the order they were made.
+//
+// Write errors don't reach the caller of the change that caused them;
+// Close returns the first one, and Status how the last write went. The
+// file is checked every fileCheckInterval and written again if it was
+// deleted meanwhile.
+type RecordWriter struct {
+	FilePath string
+	Comment  string // root comment when the writer was created
+
+	ops  chan func(*GameRecord) error
+	done chan struct{}
+
+	mu     sync.Mutex // guards moves, result and closed, and orders sends on ops
+	moves  int
+	result bool // SetResult has given the game an outcome
+	closed bool
+
+	errMu    sync.Mutex // guards err, status and onStatus
+	err      error
+	status   RecordStatus
+	onStatus func()
+}
+
+// NewRecordWriter takes over rec; it must not be used directly afterwards.
+func NewRecordWriter(rec *GameRecord) *RecordWriter {
+	w := &RecordWriter{
+		FilePath: rec.FilePath,
+		Comment:  rec.Comment,
+		ops:      make(chan func(*GameRecord) error, writeQueueSize),
+		done:     make(chan struct{}),
+		moves:    rec.MoveCount(),
+	}
+	go w.run(rec)
+	return w
+}
+
+func (w *RecordWriter) run(rec *GameRecord) {
+	defer close(w.done)
+	tick := time.NewTicker(fileCheckInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case op, ok := <-w.ops:
+			if !ok {
+				rec.Close()
+				return
+			}
+			if err := op(rec); err != nil {
+				w.errMu.Lock()
+				if w.err == nil {
+					w.err = err
+				}
+				w.errMu.Unlock()
+			}
+		case <-tick.C:
+			rec.checkFile()
+		}
+		w.setStatus(RecordStatus{Err: rec.writeErr, Recreated: rec.recreated})
+	}
+}
+
+// setStatus records st, telling the OnStatus callback if it changed.
+func (w *RecordWriter) setStatus(st RecordStatus) {
+	w.errMu.Lock()
+	changed := st.Recreated != w.status.Recreated || errText(st.Err) != errText(w.status.Err)
+	w.status = st
+	notify := w.onStatus
+	w.errMu.Unlock()
+	if changed && notify != nil {
+		notify()
+	}
+}
+
+// errText is err's message, "" for none.
+func errText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// Status returns how the last write went.
+func (w *RecordWriter) Status() RecordStatus {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	return w.status
+}
+
+// OnStatus has f called, on the writer's goroutine, whenever Status
+// changes: a write fails, succeeds again, or has to recreate the file.
+func (w *RecordWriter) OnStatus(f func()) {
+	w.errMu.Lock()
+	w.onStatus = f
+	w.errMu.Unlock()
+}
+
+// send queues op, first calling check (if any) under the same lock to
+// update the writer's state; op is dropped if check returns an error.
+// Changes after Close are dropped too.
+func (w *RecordWriter) send(check func() error, op func(*GameRecord) error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	w.ops <- op
+	return nil
+}
+
+// AddMove queues a move; see GameRecord.AddMove. Once SetResult has been
+// called the move is refused with ErrMoveAfterResult, and logged, before
+// it reaches the queue.
+func (w *RecordWriter) AddMove(x, y, color int) error {
+	err := w.send(func() error {
+		if w.result {
+			return ErrMoveAfterResult
+		}
+		w.moves++
+		return nil
+	}, func(r *GameRecord) error { return r.AddMove(x, y, color) })
+	if err != nil {
+		debugLog.Printf("%s: refused %s: %v", w.FilePath, MoveNode(color, x, y), err)
+	}
+	return err
+}
+
+// AddSetupPosition queues a setup node; see GameRecord.AddSetupPosition.
+// board is copied, so the caller may keep changing it.
+func (w *RecordWriter) AddSetupPosition(board [][]int, toPlay, movesPlayed int) {
+	snapshot := make([][]int, len(board))
+	for y := range board {
+		snapshot[y] = append([]int(nil), board[y]...)
+	}
+	w.send(nil, func(r *GameRecord) error { return r.AddSetupPosition(snapshot, toPlay, movesPlayed) })
+}
+
+// AddComment queues a comment on the move at moveIndex, counting moves
+// still waiting to be written.
+func (w *RecordWriter) AddComment(moveIndex int, text string) error {
+	if moves := w.MoveCount(); moveIndex < 0 || moveIndex >= moves {
+		return fmt.Errorf("no move at index %d", moveIndex)
+	}
+	w.send(nil, func(r *GameRecord) error { return r.AddComment(moveIndex, text) })
+	return nil
+}
+
+// MoveCount returns the number of moves recorded, including ones not yet
+// written.
+func (w *RecordWriter) MoveCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.moves
+}
+
+// UndoMoves queues removing the last n moves.
+func (w *RecordWriter) UndoMoves(n int) {
+	w.send(func() error {
+		if n > w.moves {
+			n = w.moves
+		}
+		w.moves -= n
+		return nil
+	}, func(r *GameRecord) error { return r.UndoMoves(n) })
+}
+
+// Begin queues the start of a batch; see GameRecord.Begin. Every Begin
+// needs a Commit, or the changes in between reach the disk only on Close.
+func (w *RecordWriter) Begin() {
+	w.send(nil, func(r *GameRecord) error { r.Begin(); return nil })
+}
+
+// Commit queues the end of a batch, which writes its changes at once.
+func (w *RecordWriter) Commit() {
+	w.send(nil, func(r *GameRecord) error { return r.Commit() })
+}
+
+// SetComment queues setting the root comment.
+func (w *RecordWriter) SetComment(text string) {
+	w.send(nil, func(r *GameRecord) error { return r.SetComment(text) })
+}
+
+// SetResult queues setting the result; see GameRecord.SetResult.
+func (w *RecordWriter) SetResult(outcome string) {
+	w.send(func() error {
+		w.result = parseResult(outcome) != "?"
+		return nil
+	}, func(r *GameRecord) error { return r.SetResult(outcome) })
+}
+
+// Close writes every queued change, closes the file and returns the first
+// write error. Calling it again is harmless.
+func (w *RecordWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.ops)
+	}
+	w.mu.Unlock()
+	<-w.done
+
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	return w.err
+}
@@ -0,0 +1,269 @@
+package sgf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowDisk makes every write take delay until the test ends.
+func slowDisk(t *testing.T, delay time.Duration) *int32 {
+	t.Helper()
+	var writes int32
+	orig := syncFile
+	syncFile = func(f *os.File) error {
+		time.Sleep(delay)
+		atomic.AddInt32(&writes, 1)
+		return orig(f)
+	}
+	t.Cleanup(func() { syncFile = orig })
+	return &writes
+}
+
+func TestRecordWriterDoesNotBlockOnSlowDisk(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	const delay = 50 * time.Millisecond
+	writes := slowDisk(t, delay)
+	w := NewRecordWriter(rec)
+
+	// What the engine's OnMove callback pays for recording a move.
+	var slowest time.Duration
+	for i := 0; i < 6; i++ {
+		start := time.Now()
+		w.AddMove(i, i, i%2+1)
+		if d := time.Since(start); d > slowest {
+			slowest = d
+		}
+	}
+	if slowest >= delay/2 {
+		t.Errorf("AddMove took %v with a %v disk; it should not wait for the write", slowest, delay)
+	}
+	if w.MoveCount() != 6 {
+		t.Errorf("MoveCount = %d, want 6 before the writes land", w.MoveCount())
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if n := atomic.LoadInt32(writes); n != 6 { // six moves; the final flush has nothing new
+		t.Errorf("%d writes before Close returned, want 6", n)
+	}
+	moves, err := ParseMovesForRecord(w.FilePath)
+	if err != nil {
+		t.Fatalf("ParseMovesForRecord: %v", err)
+	}
+	if len(moves) != 6 {
+		t.Errorf("file has %d moves after Close, want 6", len(moves))
+	}
+}
+
+func TestRecordWriterOrder(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	slowDisk(t, time.Millisecond)
+	w := NewRecordWriter(rec)
+
+	w.AddMove(4, 4, 1) // B[ee]
+	w.AddMove(2, 2, 2) // W[cc]
+	w.AddMove(6, 6, 1) // B[gg]
+	w.UndoMoves(2)
+	w.AddMove(3, 3, 2) // W[dd]
+	if err := w.AddComment(1, "reply"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := w.AddComment(2, "nope"); err == nil {
+		t.Error("AddComment past the last queued move should fail")
+	}
+	w.SetResult("Black wins by resign")
+	w.Close()
+
+	content, _ := os.ReadFile(w.FilePath)
+	s := string(content)
+	if !strings.Contains(s, ";B[ee];W[dd]C[reply])") {
+		t.Errorf("changes applied out of order:\n%s", s)
+	}
+	if !strings.Contains(s, "RE[B+R]") {
+		t.Errorf("missing result:\n%s", s)
+	}
+
+	// Changes after Close are dropped rather than panicking.
+	w.AddMove(5, 5, 1)
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestRecordWriterReportsWriteError(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	orig := syncFile
+	syncFile = func(*os.File) error { return errors.New("disk full") }
+	t.Cleanup(func() { syncFile = orig })
+
+	w := NewRecordWriter(rec)
+	changed := make(chan struct{}, 1)
+	w.OnStatus(func() { changed <- struct{}{} })
+	w.AddMove(4, 4, 1)
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("no status change after a failed write")
+	}
+	if st := w.Status(); st.Err == nil || st.Err.Error() != "disk full" {
+		t.Errorf("Status = %+v, want the write error", st)
+	}
+	if err := w.Close(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Close = %v, want the write error", err)
+	}
+}
+
+func TestRecordWriterRefusesMoveAfterResult(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	var logged bytes.Buffer
+	SetDebugLog(&logged)
+	t.Cleanup(func() { SetDebugLog(io.Discard) })
+	w := NewRecordWriter(rec)
+
+	if err := w.AddMove(4, 4, 1); err != nil {
+		t.Fatalf("AddMove before the result: %v", err)
+	}
+	w.SetResult("White wins by resign")
+	if err := w.AddMove(2, 2, 2); !errors.Is(err, ErrMoveAfterResult) {
+		t.Errorf("AddMove after the result = %v, want ErrMoveAfterResult", err)
+	}
+	if n := w.MoveCount(); n != 1 {
+		t.Errorf("MoveCount = %d, want 1", n)
+	}
+	if !strings.Contains(logged.String(), ";W[cc]") {
+		t.Errorf("refused move not logged: %q", logged.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	content, _ := os.ReadFile(w.FilePath)
+	if s := string(content); strings.Contains(s, "W[cc]") || !strings.Contains(s, "RE[W+R]") {
+		t.Errorf("record after a refused move:\n%s", s)
+	}
+}
+
+func TestGameRecordRefusesMoveAfterResult(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	defer rec.Close()
+	rec.SetResult("B+3.5")
+	if err := rec.AddMove(4, 4, 1); !errors.Is(err, ErrMoveAfterResult) {
+		t.Errorf("AddMove = %v, want ErrMoveAfterResult", err)
+	}
+	if n := rec.MoveCount(); n != 0 {
+		t.Errorf("MoveCount = %d, want 0", n)
+	}
+}
+
+func TestRecordWriterBatch(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	writes := slowDisk(t, 0)
+	w := NewRecordWriter(rec)
+
+	w.Begin()
+	for i := 0; i < 300; i++ {
+		w.AddMove(i%9, i/9%9, i%2+1)
+	}
+	w.UndoMoves(298)
+	w.SetComment("undos used: 1")
+	w.Commit()
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	// The Commit; Close finds nothing left to write
+	if n := atomic.LoadInt32(writes); n != 1 {
+		t.Errorf("%d writes, want 1", n)
+	}
+	content, _ := os.ReadFile(w.FilePath)
+	if s := string(content); !strings.Contains(s, ";B[aa];W[ba])") || !strings.Contains(s, "undos used: 1") {
+		t.Errorf("batch not written:\n%s", s)
+	}
+
+	// A batch left open is still written on Close
+	rec, err = NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	w = NewRecordWriter(rec)
+	w.Begin()
+	w.AddMove(4, 4, 1)
+	w.Close()
+	content, _ = os.ReadFile(w.FilePath)
+	if !strings.Contains(string(content), ";B[ee])") {
+		t.Errorf("open batch lost on Close:\n%s", content)
+	}
+}
+
+func TestRecordWriterRecreatesDeletedFile(t *testing.T) {
+	orig := fileCheckInterval
+	fileCheckInterval = 10 * time.Millisecond
+	t.Cleanup(func() { fileCheckInterval = orig })
+
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	w := NewRecordWriter(rec)
+	changed := make(chan RecordStatus, 4)
+	w.OnStatus(func() { changed <- w.Status() })
+	w.AddMove(4, 4, 1)
+	for i := 0; i < 100; i++ {
+		if moves, _ := ParseMovesForRecord(w.FilePath); len(moves) == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Deleted between moves, it is back at the next check
+	if err := os.Remove(w.FilePath); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case st := <-changed:
+		if !st.Recreated || st.Err != nil {
+			t.Errorf("status after the delete = %+v", st)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("deleted file not noticed")
+	}
+	if moves, err := ParseMovesForRecord(w.FilePath); err != nil || len(moves) != 1 {
+		t.Errorf("recreated file has moves %v, %v; want the one played", moves, err)
+	}
+
+	// The next write goes to the new file, and the notice clears
+	w.AddMove(2, 2, 2)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if st := w.Status(); st.Recreated {
+		t.Errorf("status after the next write = %+v", st)
+	}
+	if moves, err := ParseMovesForRecord(w.FilePath); err != nil || len(moves) != 2 {
+		t.Errorf("file has moves %v, %v; want both", moves, err)
+	}
+}
@@ -0,0 +1,205 @@
+package sgf
+
+import (
+	"strconv"
+)
+
+// Replayer steps through a recorded game a move at a time, in either
+// direction. Stepping back puts captured stones back on the board.
+type Replayer struct {
+	size     int
+	moves    [][3]int // color, x, y; (-1,-1) is a pass
+	board    [][]int
+	steps    []replayStep // one per move applied, to undo it
+	captures [3]int       // stones captured by each color (1=black, 2=white) so far
+	comments map[int]string
+}
+
+// replayStep records what a move changed.
+type replayStep struct {
+	placed   bool     // a stone went on the board; false for passes and off-board moves
+	prev     int      // what the point held before, empty unless the record is odd
+	captured [][2]int // opponent stones the move took off
+}
+
+// NewReplayer starts at the position setup, which may be nil for an empty
+// board, before the first of moves.
+func NewReplayer(size int, setup [][]int, moves [][3]int) *Replayer {
+	r := &Replayer{size: size, moves: moves, board: MakeBoard(size)}
+	for y := range setup {
+		copy(r.board[y], setup[y])
+	}
+	return r
+}
+
+// OpenReplayer reads the SGF at filePath and starts before its first move,
+// with any AB/AW stones already placed. In a collection it replays the
+// first game, or game[0] if given.
+func OpenReplayer(filePath string, game ...int) (*Replayer, error) {
+	content, err := readGame(filePath, game)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 19
+	if n, err := strconv.Atoi(parseProperties(content).get("SZ")); err == nil {
+		size = n
+	}
+	setup := MakeBoard(size)
+	applySetup(content, setup, size)
+
+	var moves [][3]int
+	comments := make(map[int]string)
+	for _, node := range parseNodes(content) {
+		if color, x, y, ok := parseMoveNode(node); ok {
+			if c := nodeComment(node); c != "" {
+				comments[len(moves)] = c
+			}
+			moves = append(moves, [3]int{color, x, y})
+		}
+	}
+	r := NewReplayer(size, setup, moves)
+	r.comments = comments
+	return r, nil
+}
+
+// Size returns the board size.
+func (r *Replayer) Size() int {
+	return r.size
+}
+
+// Len returns the number of moves in the game.
+func (r *Replayer) Len() int {
+	return len(r.moves)
+}
+
+// Position returns how many moves have been played on the board.
+func (r *Replayer) Position() int {
+	return len(r.steps)
+}
+
+// Moves returns every move of the game as (color, x, y).
+func (r *Replayer) Moves() [][3]int {
+	return r.moves
+}
+
+// Comments returns the comments on the game's moves, keyed by move index
+// (0 is the first move). It must not be modified.
+func (r *Replayer) Comments() map[int]string {
+	return r.comments
+}
+
+// Board returns the current position, indexed board[y][x]. It changes as
+// the replayer moves and must not be modified.
+func (r *Replayer) Board() [][]int {
+	return r.board
+}
+
+// Captures returns how many stones each side has captured so far.
+func (r *Replayer) Captures() (black, white int) {
+	return r.captures[1], r.captures[2]
+}
+
+// LastMove returns the move that led to the current position, or
+// ok false before the first move.
+func (r *Replayer) LastMove() (color, x, y int, ok bool) {
+	if len(r.steps) == 0 {
+		return 0, -1, -1, false
+	}
+	m := r.moves[len(r.steps)-1]
+	return m[0], m[1], m[2], true
+}
+
+// Forward plays the next move. It returns false at the end of the game.
+func (r *Replayer) Forward() bool {
+	if len(r.steps) == len(r.moves) {
+		return false
+	}
+	m := r.moves[len(r.steps)]
+	color, x, y := m[0], m[1], m[2]
+	var step replayStep
+	if x >= 0 && x < r.size && y >= 0 && y < r.size {
+		step.placed = true
+		step.prev = r.board[y][x]
+		opponent := oppositeStone(color)
+		before := r.neighborGroups(x, y, opponent)
+		r.board[y][x] = color
+		if RemoveCaptures(r.board, r.size, x, y, color) > 0 {
+			for _, p := range before {
+				if r.board[p[1]][p[0]] == 0 {
+					step.captured = append(step.captured, p)
+				}
+			}
+			r.captures[color] += len(step.captured)
+		}
+	}
+	r.steps = append(r.steps, step)
+	return true
+}
+
+// Back takes the last move off the board, restoring anything it captured.
+// It returns false at the start of the game.
+func (r *Replayer) Back() bool {
+	if len(r.steps) == 0 {
+		return false
+	}
+	n := len(r.steps) - 1
+	step := r.steps[n]
+	color, x, y := r.moves[n][0], r.moves[n][1], r.moves[n][2]
+	if step.placed {
+		r.board[y][x] = step.prev
+		for _, p := range step.captured {
+			r.board[p[1]][p[0]] = oppositeStone(color)
+		}
+		r.captures[color] -= len(step.captured)
+	}
+	r.steps = r.steps[:n]
+	return true
+}
+
+// Goto moves to the position after n moves, clamped to the game.
+func (r *Replayer) Goto(n int) {
+	if n < 0 {
+		n = 0
+	}
+	if n > len(r.moves) {
+		n = len(r.moves)
+	}
+	for len(r.steps) > n {
+		r.Back()
+	}
+	for len(r.steps) < n {
+		r.Forward()
+	}
+}
+
+// neighborGroups lists the stones of color in the groups touching x, y:
+// everything a move there could capture.
+func (r *Replayer) neighborGroups(x, y, color int) [][2]int {
+	var stones [][2]int
+	seen := map[[2]int]bool{}
+	var walk func(x, y int)
+	walk = func(x, y int) {
+		p := [2]int{x, y}
+		if x < 0 || x >= r.size || y < 0 || y >= r.size || seen[p] || r.board[y][x] != color {
+			return
+		}
+		seen[p] = true
+		stones = append(stones, p)
+		for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			walk(x+d[0], y+d[1])
+		}
+	}
+	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		walk(x+d[0], y+d[1])
+	}
+	return stones
+}
+
+// oppositeStone returns the other color.
+func oppositeStone(color int) int {
+	if color == 1 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,82 @@
+package sgf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReplayerStepsBackOverCaptures(t *testing.T) {
+	sgf := `(;GM[1]FF[4]SZ[9]KM[6.5]AB[ii]
+;B[aa];W[ba];B[ca];W[ee];B[bb];W[])`
+	path := writeTempSGF(t, t.TempDir(), "review.sgf", sgf)
+
+	r, err := OpenReplayer(path)
+	if err != nil {
+		t.Fatalf("OpenReplayer: %v", err)
+	}
+	if r.Size() != 9 || r.Len() != 6 || r.Position() != 0 {
+		t.Fatalf("size %d, %d moves at %d; want 9, 6 at 0", r.Size(), r.Len(), r.Position())
+	}
+	if r.Board()[8][8] != 1 {
+		t.Errorf("setup stone missing before the first move")
+	}
+
+	r.Goto(5)
+	if r.Board()[0][1] != 0 {
+		t.Errorf("B[bb] left the white stone at (1,0)")
+	}
+	if b, w := r.Captures(); b != 1 || w != 0 {
+		t.Errorf("captures = %d, %d; want 1, 0", b, w)
+	}
+
+	r.Back()
+	if r.Board()[0][1] != 2 || r.Board()[1][1] != 0 {
+		t.Errorf("stepping back did not restore the captured stone")
+	}
+	if b, _ := r.Captures(); b != 0 {
+		t.Errorf("black captures after stepping back = %d, want 0", b)
+	}
+
+	r.Goto(100)
+	if r.Position() != 6 || r.Forward() {
+		t.Errorf("Goto past the end stopped at %d", r.Position())
+	}
+	if color, x, y, ok := r.LastMove(); !ok || color != 2 || x != -1 || y != -1 {
+		t.Errorf("LastMove = %d (%d,%d) %v, want White's pass", color, x, y, ok)
+	}
+	r.Goto(-1)
+	if r.Position() != 0 || r.Back() {
+		t.Errorf("Goto before the start stopped at %d", r.Position())
+	}
+	if _, _, _, ok := r.LastMove(); ok {
+		t.Errorf("LastMove before the first move reports one")
+	}
+}
+
+func TestReplayerMissingFile(t *testing.T) {
+	if _, err := OpenReplayer("/nonexistent/game.sgf"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestPropertyReplayerMatchesReplay(t *testing.T) {
+	checkProperty(t, func(seed int64) error {
+		game := playRandom(seed, 80)
+		r := NewReplayer(game.size, nil, game.moves)
+		// Walk to the end, back to the middle and forward again; every
+		// stop must match a fresh replay of that many moves.
+		stops := []int{len(game.moves), len(game.moves) / 2, 0, len(game.moves)}
+		for _, n := range stops {
+			r.Goto(n)
+			want := replay(game.size, game.moves[:n])
+			for y := range want {
+				for x := range want[y] {
+					if r.Board()[y][x] != want[y][x] {
+						return fmt.Errorf("after Goto(%d), (%d,%d) = %d, want %d", n, x, y, r.Board()[y][x], want[y][x])
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,15 @@
+package sgf
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ReportSuffix ends the name of the plain-text report written beside a
+// recorded game: game.sgf's is game.report.txt.
+const ReportSuffix = ".report.txt"
+
+// ReportPath returns where the report of the game at path goes.
+func ReportPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ReportSuffix
+}
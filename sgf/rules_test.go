@@ -0,0 +1,211 @@
+package sgf
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+// Property tests for RemoveCaptures and HasLiberty. Each property is
+// checked over random games; a failure names the seed, and
+// replaying it with playRandom gives the same game again.
+
+// randomGame is a game of random legal moves on a small board.
+type randomGame struct {
+	size  int
+	moves [][3]int // color, x, y; passes are left out
+	board [][]int
+}
+
+// playRandom plays n turns from seed on a board of 3 to 9 lines.
+// Each turn tries a few empty points and passes if none is legal, so
+// suicides are never played.
+func playRandom(seed int64, n int) *randomGame {
+	rng := rand.New(rand.NewSource(seed))
+	game := &randomGame{size: 3 + rng.Intn(7)}
+	game.board = MakeBoard(game.size)
+	color := 1
+	for turn := 0; turn < n; turn++ {
+		for try := 0; try < 8; try++ {
+			x, y := rng.Intn(game.size), rng.Intn(game.size)
+			if game.board[y][x] != 0 {
+				continue
+			}
+			if playLegal(game.board, game.size, x, y, color) {
+				game.moves = append(game.moves, [3]int{color, x, y})
+				break
+			}
+		}
+		color = 3 - color
+	}
+	return game
+}
+
+// playLegal places a stone the way planning mode does, taking it back
+// if it would be suicide. It returns whether the stone stayed.
+func playLegal(board [][]int, size, x, y, color int) bool {
+	board[y][x] = color
+	RemoveCaptures(board, size, x, y, color)
+	if !HasLiberty(board, size, x, y, color) {
+		board[y][x] = 0
+		return false
+	}
+	return true
+}
+
+// replay plays moves on an empty board.
+func replay(size int, moves [][3]int) [][]int {
+	board := MakeBoard(size)
+	for _, m := range moves {
+		board[m[2]][m[1]] = m[0]
+		RemoveCaptures(board, size, m[1], m[2], m[0])
+	}
+	return board
+}
+
+// countLiberties counts the liberties of the group at x, y with a
+// breadth-first walk, independently of hasLibertiesDFS.
+func countLiberties(board [][]int, x, y int) int {
+	size := len(board)
+	color := board[y][x]
+	seen := map[[2]int]bool{{x, y}: true}
+	libs := map[[2]int]bool{}
+	queue := [][2]int{{x, y}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, n := range [][2]int{{p[0] - 1, p[1]}, {p[0] + 1, p[1]}, {p[0], p[1] - 1}, {p[0], p[1] + 1}} {
+			if n[0] < 0 || n[0] >= size || n[1] < 0 || n[1] >= size {
+				continue
+			}
+			switch board[n[1]][n[0]] {
+			case 0:
+				libs[n] = true
+			case color:
+				if !seen[n] {
+					seen[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+	}
+	return len(libs)
+}
+
+func countStones(board [][]int) int {
+	n := 0
+	for _, row := range board {
+		for _, v := range row {
+			if v != 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// checkProperty runs prop over random seeds, reporting any failure with
+// the seed that reproduces it.
+func checkProperty(t *testing.T, prop func(seed int64) error) {
+	t.Helper()
+	count := 200
+	if testing.Short() {
+		count = 20
+	}
+	f := func(seed int64) bool {
+		if err := prop(seed); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: count}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyNoGroupWithoutLiberties(t *testing.T) {
+	checkProperty(t, func(seed int64) error {
+		game := playRandom(seed, 120)
+		board := MakeBoard(game.size)
+		for i, m := range game.moves {
+			board[m[2]][m[1]] = m[0]
+			RemoveCaptures(board, game.size, m[1], m[2], m[0])
+			for y, row := range board {
+				for x, v := range row {
+					if v != 0 && countLiberties(board, x, y) == 0 {
+						return fmt.Errorf("after move %d the group at (%d,%d) has no liberties", i+1, x, y)
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func TestPropertyStonesNeverExceedMoves(t *testing.T) {
+	checkProperty(t, func(seed int64) error {
+		game := playRandom(seed, 120)
+		board := MakeBoard(game.size)
+		removed := 0
+		for i, m := range game.moves {
+			board[m[2]][m[1]] = m[0]
+			removed += RemoveCaptures(board, game.size, m[1], m[2], m[0])
+			stones := countStones(board)
+			if stones > i+1 {
+				return fmt.Errorf("%d stones on the board after %d moves", stones, i+1)
+			}
+			if stones != i+1-removed {
+				return fmt.Errorf("%d stones after %d moves and %d captured", stones, i+1, removed)
+			}
+		}
+		return nil
+	})
+}
+
+func TestPropertyReplayIsDeterministic(t *testing.T) {
+	checkProperty(t, func(seed int64) error {
+		game := playRandom(seed, 120)
+		first := replay(game.size, game.moves)
+		second := replay(game.size, game.moves)
+		for y := range first {
+			for x := range first[y] {
+				if first[y][x] != second[y][x] {
+					return fmt.Errorf("replays differ at (%d,%d): %d vs %d", x, y, first[y][x], second[y][x])
+				}
+				if first[y][x] != game.board[y][x] {
+					return fmt.Errorf("replay differs from the game at (%d,%d)", x, y)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func TestPropertyHasLibertyAgreesWithCount(t *testing.T) {
+	checkProperty(t, func(seed int64) error {
+		// Fill the board at random without captures, so groups without
+		// liberties turn up too.
+		rng := rand.New(rand.NewSource(seed))
+		size := 3 + rng.Intn(7)
+		board := MakeBoard(size)
+		for y := range board {
+			for x := range board[y] {
+				board[y][x] = rng.Intn(3)
+			}
+		}
+		for y, row := range board {
+			for x, v := range row {
+				if v == 0 {
+					continue
+				}
+				want := countLiberties(board, x, y) > 0
+				if got := HasLiberty(board, size, x, y, v); got != want {
+					return fmt.Errorf("HasLiberty(%d,%d) = %v, but the group has %d liberties", x, y, got, countLiberties(board, x, y))
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,310 @@
+package sgf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ScoreBreakdown holds the components of a counted (territory scoring) result.
+type ScoreBreakdown struct {
+	TerritoryBlack int
+	TerritoryWhite int
+	PrisonersBlack int // stones captured by Black, dead white stones included
+	PrisonersWhite int // stones captured by White, dead black stones included
+	Komi           float64
+	EngineResult   string // result reported by the engine (e.g. "W+0.5"), empty if unknown
+
+	// Area counting: each side's stones left on the board count with its
+	// territory, and prisoners don't
+	Area        bool
+	StonesBlack int
+	StonesWhite int
+}
+
+// ComputeScore counts territory on the final board after removing dead stones.
+// dead holds [x, y] positions of stones agreed dead; they are added to the
+// opponent's prisoners. capturesBlack/capturesWhite are the stones each color
+// captured during play.
+func ComputeScore(board [][]int, dead [][2]int, capturesBlack, capturesWhite int, komi float64) ScoreBreakdown {
+	size := len(board)
+	b := MakeBoard(size)
+	for y := range board {
+		copy(b[y], board[y])
+	}
+
+	s := ScoreBreakdown{
+		PrisonersBlack: capturesBlack,
+		PrisonersWhite: capturesWhite,
+		Komi:           komi,
+	}
+	for _, d := range dead {
+		x, y := d[0], d[1]
+		if x < 0 || x >= size || y < 0 || y >= size {
+			continue
+		}
+		switch b[y][x] {
+		case 1:
+			s.PrisonersWhite++
+		case 2:
+			s.PrisonersBlack++
+		}
+		b[y][x] = 0
+	}
+
+	s.TerritoryBlack, s.TerritoryWhite = countTerritory(b, size)
+	return s
+}
+
+// ComputeAreaScore counts area on the final board after removing dead
+// stones: each side's living stones plus the points they surround.
+func ComputeAreaScore(board [][]int, dead [][2]int, komi float64) ScoreBreakdown {
+	size := len(board)
+	b := MakeBoard(size)
+	for y := range board {
+		copy(b[y], board[y])
+	}
+	for _, d := range dead {
+		if x, y := d[0], d[1]; x >= 0 && x < size && y >= 0 && y < size {
+			b[y][x] = 0
+		}
+	}
+
+	s := ScoreBreakdown{Komi: komi, Area: true}
+	s.TerritoryBlack, s.TerritoryWhite = countTerritory(b, size)
+	for _, row := range b {
+		for _, stone := range row {
+			switch stone {
+			case 1:
+				s.StonesBlack++
+			case 2:
+				s.StonesWhite++
+			}
+		}
+	}
+	return s
+}
+
+// Territory returns who owns each point once the dead stones are taken
+// off: 1 for Black, 2 for White, 0 for stones and neutral points. A dead
+// stone's point belongs to whoever owns the region around it.
+func Territory(board [][]int, dead [][2]int) [][]int {
+	size := len(board)
+	b := MakeBoard(size)
+	for y := range board {
+		copy(b[y], board[y])
+	}
+	for _, d := range dead {
+		if x, y := d[0], d[1]; x >= 0 && x < size && y >= 0 && y < size {
+			b[y][x] = 0
+		}
+	}
+	return territoryMap(b, size)
+}
+
+// countTerritory counts the points each color owns on a board with the
+// dead stones already removed.
+func countTerritory(board [][]int, size int) (black, white int) {
+	for _, row := range territoryMap(board, size) {
+		for _, owner := range row {
+			switch owner {
+			case 1:
+				black++
+			case 2:
+				white++
+			}
+		}
+	}
+	return black, white
+}
+
+// territoryMap flood-fills each empty region and credits it to a color
+// when every bordering stone is of that color. Mixed regions are neutral.
+func territoryMap(board [][]int, size int) [][]int {
+	owners := MakeBoard(size)
+	visited := make([][]bool, size)
+	for i := range visited {
+		visited[i] = make([]bool, size)
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if board[y][x] != 0 || visited[y][x] {
+				continue
+			}
+			var region [][2]int
+			borders := 0 // bitmask: 1=black seen, 2=white seen
+			stack := [][2]int{{x, y}}
+			visited[y][x] = true
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				region = append(region, p)
+				for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+					nx, ny := p[0]+d[0], p[1]+d[1]
+					if nx < 0 || nx >= size || ny < 0 || ny >= size {
+						continue
+					}
+					switch board[ny][nx] {
+					case 0:
+						if !visited[ny][nx] {
+							visited[ny][nx] = true
+							stack = append(stack, [2]int{nx, ny})
+						}
+					case 1:
+						borders |= 1
+					case 2:
+						borders |= 2
+					}
+				}
+			}
+			if borders == 1 || borders == 2 {
+				for _, p := range region {
+					owners[p[1]][p[0]] = borders
+				}
+			}
+		}
+	}
+	return owners
+}
+
+// IsSettled is an endgame heuristic: it reports whether every empty region
+// is enclosed by a single color (no dame left to fill) and no group is in
+// atari. On a settled board, further moves only fill one's own territory or
+// invade what is already decided. It knows nothing about life and death, so
+// callers should present it as a suggestion, never act on it alone.
+func IsSettled(board [][]int) bool {
+	size := len(board)
+	visited := make([][]bool, size)
+	for i := range visited {
+		visited[i] = make([]bool, size)
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if visited[y][x] {
+				continue
+			}
+			color := board[y][x]
+			// Flood the region or group containing (x, y), collecting what
+			// borders it: colors for an empty region, liberties for a group.
+			borders := 0
+			liberties := map[[2]int]bool{}
+			stack := [][2]int{{x, y}}
+			visited[y][x] = true
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+					nx, ny := p[0]+d[0], p[1]+d[1]
+					if nx < 0 || nx >= size || ny < 0 || ny >= size {
+						continue
+					}
+					n := board[ny][nx]
+					switch {
+					case n == color:
+						if !visited[ny][nx] {
+							visited[ny][nx] = true
+							stack = append(stack, [2]int{nx, ny})
+						}
+					case color == 0:
+						borders |= n
+					case n == 0:
+						liberties[[2]int{nx, ny}] = true
+					}
+				}
+			}
+			if color == 0 && borders != 1 && borders != 2 {
+				return false // dame, or an empty board
+			}
+			if color != 0 && len(liberties) == 1 {
+				return false // atari: something is still at stake
+			}
+		}
+	}
+	return true
+}
+
+// LocalResult returns the SGF result implied by the breakdown, e.g. "W+0.5".
+func (s ScoreBreakdown) LocalResult() string {
+	margin := float64(s.TerritoryBlack+s.PrisonersBlack) - float64(s.TerritoryWhite+s.PrisonersWhite) - s.Komi
+	if s.Area {
+		margin = float64(s.TerritoryBlack+s.StonesBlack) - float64(s.TerritoryWhite+s.StonesWhite) - s.Komi
+	}
+	switch {
+	case margin > 0:
+		return "B+" + formatPoints(margin)
+	case margin < 0:
+		return "W+" + formatPoints(-margin)
+	default:
+		return "0"
+	}
+}
+
+// FormatScoreComment composes the comment stored on the final node, e.g.
+// "territory B 32 / W 28, prisoners B 6 / W 3, komi 6.5 → W+0.5", or for
+// area counting "area B 45 / W 36, komi 7.5 → B+1.5".
+// A disagreement with the engine's result is flagged rather than hidden.
+func FormatScoreComment(s ScoreBreakdown) string {
+	local := s.LocalResult()
+	text := fmt.Sprintf("territory B %d / W %d, prisoners B %d / W %d, komi %s → %s",
+		s.TerritoryBlack, s.TerritoryWhite, s.PrisonersBlack, s.PrisonersWhite, formatPoints(s.Komi), local)
+	if s.Area {
+		text = fmt.Sprintf("area B %d / W %d, komi %s → %s",
+			s.TerritoryBlack+s.StonesBlack, s.TerritoryWhite+s.StonesWhite, formatPoints(s.Komi), local)
+	}
+
+	if s.Disagrees() {
+		text += fmt.Sprintf(" (mismatch: engine says %s)", parseResult(s.EngineResult))
+	}
+	return text
+}
+
+// Disagrees reports whether the engine's result differs from the count.
+// Without an engine result there is nothing to disagree with.
+func (s ScoreBreakdown) Disagrees() bool {
+	return s.EngineResult != "" && !sameResult(parseResult(s.EngineResult), s.LocalResult())
+}
+
+// sameResult compares two SGF results numerically, so "B+4" equals "B+4.0".
+func sameResult(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if len(a) < 3 || len(b) < 3 || a[:2] != b[:2] {
+		return false
+	}
+	fa, errA := strconv.ParseFloat(a[2:], 64)
+	fb, errB := strconv.ParseFloat(b[2:], 64)
+	return errA == nil && errB == nil && math.Abs(fa-fb) < 1e-9
+}
+
+// formatPoints formats a score without trailing zeros ("6.5", "7", "0.25").
+func formatPoints(v float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(v, 'f', -1, 64), ".0")
+}
+
+// Group returns the points of the chain of stones at x, y, or nil if the
+// point is empty.
+func Group(board [][]int, x, y int) [][2]int {
+	size := len(board)
+	if x < 0 || x >= size || y < 0 || y >= size || board[y][x] == 0 {
+		return nil
+	}
+	color := board[y][x]
+	seen := map[[2]int]bool{{x, y}: true}
+	group := [][2]int{{x, y}}
+	for i := 0; i < len(group); i++ {
+		p := group[i]
+		for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			n := [2]int{p[0] + d[0], p[1] + d[1]}
+			if n[0] < 0 || n[0] >= size || n[1] < 0 || n[1] >= size || seen[n] || board[n[1]][n[0]] != color {
+				continue
+			}
+			seen[n] = true
+			group = append(group, n)
+		}
+	}
+	return group
+}
@@ -0,0 +1,205 @@
+package sgf
+
+import "testing"
+
+// boardFromRows builds a board from rows of '.', 'X' (black), 'O' (white).
+func boardFromRows(rows ...string) [][]int {
+	b := MakeBoard(len(rows))
+	for y, row := range rows {
+		for x, ch := range row {
+			switch ch {
+			case 'X':
+				b[y][x] = 1
+			case 'O':
+				b[y][x] = 2
+			}
+		}
+	}
+	return b
+}
+
+func TestComputeScore(t *testing.T) {
+	board := boardFromRows(
+		"..XO.",
+		"..XO.",
+		"..XO.",
+		"..XOO",
+		"..XO.",
+	)
+	s := ComputeScore(board, nil, 2, 1, 0.5)
+	if s.TerritoryBlack != 10 || s.TerritoryWhite != 4 {
+		t.Errorf("territory = B %d / W %d, want B 10 / W 4", s.TerritoryBlack, s.TerritoryWhite)
+	}
+	if s.PrisonersBlack != 2 || s.PrisonersWhite != 1 {
+		t.Errorf("prisoners = B %d / W %d, want B 2 / W 1", s.PrisonersBlack, s.PrisonersWhite)
+	}
+	if got := s.LocalResult(); got != "B+6.5" {
+		t.Errorf("LocalResult() = %q, want B+6.5", got)
+	}
+}
+
+func TestComputeScoreDeadStones(t *testing.T) {
+	board := boardFromRows(
+		"..XO.",
+		".OXO.",
+		"..XO.",
+		"..XO.",
+		"..XO.",
+	)
+	// The white stone at (1,1) is dead inside Black's area.
+	s := ComputeScore(board, [][2]int{{1, 1}}, 0, 0, 6.5)
+	if s.TerritoryBlack != 10 {
+		t.Errorf("TerritoryBlack = %d, want 10", s.TerritoryBlack)
+	}
+	if s.PrisonersBlack != 1 {
+		t.Errorf("PrisonersBlack = %d, want 1", s.PrisonersBlack)
+	}
+	if board[1][1] != 2 {
+		t.Error("ComputeScore should not modify the input board")
+	}
+}
+
+func TestComputeScoreNeutral(t *testing.T) {
+	board := boardFromRows(
+		"X.O",
+		"...",
+		"...",
+	)
+	s := ComputeScore(board, nil, 0, 0, 0)
+	if s.TerritoryBlack != 0 || s.TerritoryWhite != 0 {
+		t.Errorf("shared region should be neutral, got B %d / W %d", s.TerritoryBlack, s.TerritoryWhite)
+	}
+	if got := s.LocalResult(); got != "0" {
+		t.Errorf("LocalResult() = %q, want 0", got)
+	}
+}
+
+func TestComputeAreaScore(t *testing.T) {
+	board := boardFromRows(
+		"..XO.",
+		".OXO.",
+		"..XO.",
+		"..XOO",
+		"..XO.",
+	)
+	// The white stone at (1,1) is dead; prisoners don't count.
+	s := ComputeAreaScore(board, [][2]int{{1, 1}}, 0.5)
+	if s.TerritoryBlack != 10 || s.StonesBlack != 5 || s.TerritoryWhite != 4 || s.StonesWhite != 6 {
+		t.Errorf("area = B %d+%d / W %d+%d, want B 10+5 / W 4+6", s.TerritoryBlack, s.StonesBlack, s.TerritoryWhite, s.StonesWhite)
+	}
+	if got := s.LocalResult(); got != "B+4.5" {
+		t.Errorf("LocalResult() = %q, want B+4.5", got)
+	}
+	if got := FormatScoreComment(s); got != "area B 15 / W 10, komi 0.5 → B+4.5" {
+		t.Errorf("FormatScoreComment = %q", got)
+	}
+}
+
+func TestFormatScoreComment(t *testing.T) {
+	s := ScoreBreakdown{
+		TerritoryBlack: 32, TerritoryWhite: 28,
+		PrisonersBlack: 6, PrisonersWhite: 3,
+		Komi: 6.5,
+	}
+	tests := []struct {
+		engine string
+		want   string
+	}{
+		{"", "territory B 32 / W 28, prisoners B 6 / W 3, komi 6.5 → B+0.5"},
+		{"B+0.5", "territory B 32 / W 28, prisoners B 6 / W 3, komi 6.5 → B+0.5"},
+		{"Black wins by 0.5 points", "territory B 32 / W 28, prisoners B 6 / W 3, komi 6.5 → B+0.5"},
+		{"W+2.5", "territory B 32 / W 28, prisoners B 6 / W 3, komi 6.5 → B+0.5 (mismatch: engine says W+2.5)"},
+	}
+	for _, tt := range tests {
+		s.EngineResult = tt.engine
+		if got := FormatScoreComment(s); got != tt.want {
+			t.Errorf("FormatScoreComment(engine=%q) = %q, want %q", tt.engine, got, tt.want)
+		}
+	}
+}
+
+func TestSameResult(t *testing.T) {
+	if !sameResult("B+4", "B+4.0") {
+		t.Error("B+4 and B+4.0 should match")
+	}
+	if sameResult("B+4", "W+4") {
+		t.Error("B+4 and W+4 should differ")
+	}
+}
+
+func TestIsSettled(t *testing.T) {
+	tests := []struct {
+		name  string
+		board [][]int
+		want  bool
+	}{
+		{"empty board", boardFromRows(
+			".....",
+			".....",
+			".....",
+			".....",
+			".....",
+		), false},
+		{"walls, no dame", boardFromRows(
+			"..XO.",
+			"..XO.",
+			"..XO.",
+			"..XO.",
+			"..XO.",
+		), true},
+		{"one dame left", boardFromRows(
+			"..XO.",
+			"..XO.",
+			"..X..",
+			"..XO.",
+			"..XO.",
+		), false},
+		{"one-eyed group in atari", boardFromRows(
+			"X.XO.",
+			"XXXO.",
+			"OOOO.",
+			".....",
+			".....",
+		), false},
+		{"invasion stone leaves dame", boardFromRows(
+			"..XO.",
+			"..XO.",
+			"..XOX",
+			"..XO.",
+			"..XO.",
+		), false},
+	}
+	for _, tt := range tests {
+		if got := IsSettled(tt.board); got != tt.want {
+			t.Errorf("%s: IsSettled() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTerritory(t *testing.T) {
+	board := boardFromRows(
+		"O.X..",
+		"..X..",
+		"XXX.O",
+		".....",
+		"OOOOO",
+	)
+	owners := Territory(board, [][2]int{{0, 0}})
+	want := [][]int{
+		{1, 1, 0, 0, 0},
+		{1, 1, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+	}
+	for y := range want {
+		for x := range want[y] {
+			if owners[y][x] != want[y][x] {
+				t.Errorf("owner of %d,%d = %d, want %d", x, y, owners[y][x], want[y][x])
+			}
+		}
+	}
+	if board[0][0] != 2 {
+		t.Error("Territory changed the board it was given")
+	}
+}
@@ -0,0 +1,36 @@
+package sgf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// seedPrefix starts the GC[] line giving the engine's random seed, e.g.
+// "seed: 42", after any game type line. Games without a seed have none.
+const seedPrefix = "seed: "
+
+// joinSeed prepends the seed line to the rest of a GC[] value.
+func joinSeed(seed int64, gc string) string {
+	if seed == 0 {
+		return gc
+	}
+	line := seedPrefix + strconv.FormatInt(seed, 10)
+	if gc == "" {
+		return line
+	}
+	return line + "\n" + gc
+}
+
+// splitSeed is the inverse of joinSeed. A seed line that isn't a number
+// is left in the comment.
+func splitSeed(gc string) (int64, string) {
+	if !strings.HasPrefix(gc, seedPrefix) {
+		return 0, gc
+	}
+	line, rest, _ := strings.Cut(gc, "\n")
+	seed, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, seedPrefix)), 10, 64)
+	if err != nil || seed == 0 {
+		return 0, gc
+	}
+	return seed, rest
+}
@@ -0,0 +1,63 @@
+package sgf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// engineNameFormat is the player name a record gives GnuGo.
+const engineNameFormat = "GnuGo Level %d"
+
+// humanName is the player name a record gives the human.
+const humanName = "Player"
+
+// Setup is how a game was set up, as far as its record tells.
+type Setup struct {
+	BoardSize   int
+	Komi        float64
+	Handicap    int
+	PlayerColor int    // the human's color, 1=black, 2=white
+	EngineLevel int    // GnuGo's level, 0 if the opponent wasn't GnuGo
+	Opponent    string // the other side's name, just "GnuGo" when it was GnuGo at a level
+}
+
+// InferSetup reads a game's setup from its header. The human is whichever
+// side isn't GnuGo, or against another engine the side called "Player" or
+// one of names, the names the human plays under; Black when that can't be
+// told, as in a game imported from elsewhere.
+func InferSetup(game GameInfo, names ...string) Setup {
+	s := Setup{
+		BoardSize:   game.BoardSize,
+		Komi:        game.Komi,
+		Handicap:    game.Handicap,
+		PlayerColor: 1,
+	}
+	human := func(name string) bool { return isHuman(name, names) }
+	engineName := game.PlayerWhite
+	if strings.HasPrefix(game.PlayerBlack, "GnuGo") && !strings.HasPrefix(game.PlayerWhite, "GnuGo") ||
+		human(game.PlayerWhite) && !human(game.PlayerBlack) {
+		s.PlayerColor = 2
+		engineName = game.PlayerBlack
+	}
+	var level int
+	if n, _ := fmt.Sscanf(engineName, engineNameFormat, &level); n == 1 && level >= 1 && level <= 10 {
+		s.EngineLevel = level
+		engineName = "GnuGo"
+	}
+	s.Opponent = engineName
+	return s
+}
+
+// isHuman reports whether name is one the human plays under: "Player", or
+// one of names.
+func isHuman(name string, names []string) bool {
+	if name == humanName {
+		return true
+	}
+	for _, n := range names {
+		if n != "" && name == n {
+			return true
+		}
+	}
+	return false
+}
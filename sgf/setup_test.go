@@ -0,0 +1,46 @@
+package sgf
+
+import "testing"
+
+func TestInferSetup(t *testing.T) {
+	for _, tc := range []struct {
+		black, white string
+		color, level int
+		opponent     string
+	}{
+		{"Player", "GnuGo Level 3", 1, 3, "GnuGo"},
+		{"GnuGo Level 10", "Player", 2, 10, "GnuGo"},
+		{"GnuGo", "Player", 2, 0, "GnuGo"},                   // no level in the name
+		{"Player", "GnuGo Level 42", 1, 0, "GnuGo Level 42"}, // not a level GnuGo has
+		{"Lee Sedol", "AlphaGo", 1, 0, "AlphaGo"},            // imported
+		{"KataGo", "Player", 2, 0, "KataGo"},                 // another engine as Black
+		{"", "", 1, 0, ""},
+	} {
+		game := GameInfo{BoardSize: 13, Komi: 0.5, Handicap: 2, PlayerBlack: tc.black, PlayerWhite: tc.white}
+		got := InferSetup(game)
+		want := Setup{BoardSize: 13, Komi: 0.5, Handicap: 2, PlayerColor: tc.color, EngineLevel: tc.level, Opponent: tc.opponent}
+		if got != want {
+			t.Errorf("InferSetup(PB %q, PW %q) = %+v, want %+v", tc.black, tc.white, got, want)
+		}
+	}
+}
+
+func TestInferSetupWithName(t *testing.T) {
+	for _, tc := range []struct {
+		black, white string
+		color        int
+		opponent     string
+	}{
+		{"Ada", "GnuGo Level 3", 1, "GnuGo"},
+		{"GnuGo Level 3", "Ada", 2, "GnuGo"},
+		{"KataGo", "Ada", 2, "KataGo"},
+		{"Ada", "KataGo", 1, "KataGo"},
+		{"KataGo", "Player", 2, "KataGo"}, // from before the name was set
+	} {
+		game := GameInfo{BoardSize: 9, PlayerBlack: tc.black, PlayerWhite: tc.white}
+		got := InferSetup(game, "Ada")
+		if got.PlayerColor != tc.color || got.Opponent != tc.opponent {
+			t.Errorf("InferSetup(PB %q, PW %q) = color %d vs %q, want %d vs %q", tc.black, tc.white, got.PlayerColor, got.Opponent, tc.color, tc.opponent)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package sgf
+
+import "sort"
+
+// SortKey is an order for a list of games.
+type SortKey string
+
+const (
+	SortNewest SortKey = "newest" // last modified first, as the files were
+	SortOldest SortKey = "oldest" // last modified last
+	SortSize   SortKey = "size"   // smallest board first
+	SortMoves  SortKey = "moves"  // longest game first
+	SortResult SortKey = "result" // by result, unfinished games last
+)
+
+// SortKeys are the orders in the sequence Next cycles through.
+var SortKeys = []SortKey{SortNewest, SortOldest, SortSize, SortMoves, SortResult}
+
+// Next returns the order after k in SortKeys, wrapping around. An unknown
+// key counts as SortNewest.
+func (k SortKey) Next() SortKey {
+	for i, key := range SortKeys {
+		if key == k {
+			return SortKeys[(i+1)%len(SortKeys)]
+		}
+	}
+	return SortNewest.Next()
+}
+
+// String describes the order for a title, e.g. "newest first".
+func (k SortKey) String() string {
+	switch k {
+	case SortOldest:
+		return "oldest first"
+	case SortSize:
+		return "by board size"
+	case SortMoves:
+		return "most moves first"
+	case SortResult:
+		return "by result"
+	}
+	return "newest first"
+}
+
+// SortGames puts games in the order key gives, an unknown key being
+// SortNewest. Games the key doesn't tell apart are newest first; games
+// modified at the same time, by file name, descending.
+func SortGames(games []GameInfo, key SortKey) {
+	newer := func(a, b GameInfo) bool {
+		if !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.After(b.ModTime)
+		}
+		return a.FileName > b.FileName
+	}
+	var less func(a, b GameInfo) bool
+	switch key {
+	case SortOldest:
+		less = func(a, b GameInfo) bool { return newer(b, a) }
+	case SortSize:
+		less = func(a, b GameInfo) bool {
+			if a.BoardSize != b.BoardSize {
+				return a.BoardSize < b.BoardSize
+			}
+			return newer(a, b)
+		}
+	case SortMoves:
+		less = func(a, b GameInfo) bool {
+			if a.MoveCount != b.MoveCount {
+				return a.MoveCount > b.MoveCount
+			}
+			return newer(a, b)
+		}
+	case SortResult:
+		less = func(a, b GameInfo) bool {
+			ra, rb := resultOrder(a.Result), resultOrder(b.Result)
+			if ra != rb {
+				return ra < rb
+			}
+			return newer(a, b)
+		}
+	default:
+		less = newer
+	}
+	sort.SliceStable(games, func(i, j int) bool { return less(games[i], games[j]) })
+}
+
+// resultOrder is the sort key SortResult gives a result: Black's wins,
+// then White's, then draws and void games, then unfinished ones.
+func resultOrder(re string) string {
+	re = parseResult(re)
+	switch {
+	case re == "?":
+		return "3"
+	case re[0] == 'B' || re[0] == 'W':
+		return "1" + re[:1]
+	}
+	return "2" + re
+}
@@ -0,0 +1,73 @@
+package sgf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSortGames(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	games := []GameInfo{
+		{FileName: "a.sgf", ModTime: base, BoardSize: 19, MoveCount: 120, Result: "W+R"},
+		{FileName: "b.sgf", ModTime: base.Add(time.Hour), BoardSize: 9, MoveCount: 40, Result: "?"},
+		{FileName: "c.sgf", ModTime: base.Add(2 * time.Hour), BoardSize: 9, MoveCount: 60, Result: "B+3.5"},
+		{FileName: "d.sgf", ModTime: base.Add(2 * time.Hour), BoardSize: 13, MoveCount: 60, Result: "0"},
+		{FileName: "e.sgf", ModTime: base.Add(-time.Hour), BoardSize: 13, MoveCount: 200, Result: "B+R"},
+	}
+	for _, tt := range []struct {
+		key  SortKey
+		want string
+	}{
+		{SortNewest, "[d c b a e]"}, // same time: by name, descending
+		{SortOldest, "[e a b c d]"},
+		{SortSize, "[c b d e a]"},
+		{SortMoves, "[e a d c b]"},
+		{SortResult, "[c e a d b]"}, // Black's wins, White's, the draw, unfinished
+		{"bogus", "[d c b a e]"},
+	} {
+		sorted := append([]GameInfo(nil), games...)
+		SortGames(sorted, tt.key)
+		var names []string
+		for _, g := range sorted {
+			names = append(names, g.FileName[:1])
+		}
+		if got := fmt.Sprint(names); got != tt.want {
+			t.Errorf("SortGames(%s) = %s, want %s", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSortKeyNext(t *testing.T) {
+	key := SortNewest
+	for range SortKeys {
+		key = key.Next()
+	}
+	if key != SortNewest {
+		t.Errorf("cycling through all keys ends at %q", key)
+	}
+	if SortKey("").Next() != SortOldest {
+		t.Errorf("the unset key is followed by %q, want oldest", SortKey("").Next())
+	}
+}
+
+func TestListGamesModTime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "copied-in.sgf")
+	if err := os.WriteFile(path, []byte("(;GM[1]SZ[9])"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2025, 6, 1, 8, 0, 0, 0, time.Local)
+	if err := os.Chtimes(path, when, when); err != nil {
+		t.Fatal(err)
+	}
+	games, err := ListGames(dir)
+	if err != nil || len(games) != 1 {
+		t.Fatalf("ListGames = %d games, %v", len(games), err)
+	}
+	if !games[0].ModTime.Equal(when) {
+		t.Errorf("ModTime = %v, want %v", games[0].ModTime, when)
+	}
+}
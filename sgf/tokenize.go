@@ -0,0 +1,117 @@
+package sgf
+
+// The readers all go through these: valueEnd to step over a property
+// value, tokenizeNode to split a node into its properties, and treeStart
+// and rootSpan to find the game. Nothing else looks inside values, so a
+// comment that mentions "AB[dd]" or holds an escaped "\]" is only a comment.
+
+// valueEnd returns the index just past the "]" closing the value that
+// opens at s[open], a "[", and whether there was one. Within a value
+// a backslash escapes the character after it, "]" and "\" included, and
+// newlines are text like any other.
+func valueEnd(s string, open int) (end int, closed bool) {
+	for i := open + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ']':
+			return i + 1, true
+		}
+	}
+	return len(s), false
+}
+
+// nodeProp is a property as a node has it: the identifier, the raw values
+// with their escapes, and the text from the identifier through its last
+// value.
+type nodeProp struct {
+	key    string
+	values []string
+	text   string
+}
+
+// tokenizeNode splits a node into its properties, in order. Whitespace may
+// separate an identifier from its values and the values from each other,
+// and lowercase letters in identifiers (old FF[3] style, e.g. "AddBlack")
+// are dropped. Values with no identifier before them are skipped.
+func tokenizeNode(node string) []nodeProp {
+	var props []nodeProp
+	key, keyStart := "", 0
+	inKey, spaced := false, false
+	open := false // the next value belongs to the last property in props
+	for i := 0; i < len(node); i++ {
+		c := node[i]
+		switch {
+		case c == '[':
+			end, closed := valueEnd(node, i)
+			value := node[i+1 : end]
+			if closed {
+				value = node[i+1 : end-1]
+			}
+			switch {
+			case inKey && key != "":
+				props = append(props, nodeProp{key: key, values: []string{value}, text: node[keyStart:end]})
+				open = true
+			case open:
+				last := &props[len(props)-1]
+				last.values = append(last.values, value)
+				last.text = node[keyStart:end]
+			}
+			inKey = false
+			i = end - 1
+		case c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+			if !inKey || spaced {
+				key, keyStart = "", i
+				inKey, spaced, open = true, false, false
+			}
+			if c >= 'A' && c <= 'Z' {
+				key += string(c)
+			}
+		case c == ' ' || c == '\n' || c == '\r' || c == '\t':
+			spaced = inKey
+		default:
+			inKey, open = false, false
+		}
+	}
+	return props
+}
+
+// treeStart returns the index of the "(" opening the first game tree in
+// content, one followed by a node with only whitespace between, or -1 if
+// there is none.
+func treeStart(content string) int {
+	for i := 0; i < len(content); i++ {
+		if content[i] != '(' {
+			continue
+		}
+		j := i + 1
+		for j < len(content) && isSpace(content[j]) {
+			j++
+		}
+		if j < len(content) && content[j] == ';' {
+			return i
+		}
+	}
+	return -1
+}
+
+// rootSpan returns where the root node's properties are in content: from
+// just after its ";" up to the next node or game tree. ok is false when
+// content has no game tree.
+func rootSpan(content string) (start, end int, ok bool) {
+	open := treeStart(content)
+	if open == -1 {
+		return 0, 0, false
+	}
+	p := treeParser{s: content, i: open + 1}
+	for content[p.i] != ';' {
+		p.i++
+	}
+	start = p.i + 1
+	p.node()
+	return start, p.i, true
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
@@ -0,0 +1,121 @@
+package sgf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenizeNode(t *testing.T) {
+	node := ";B[dd]C[try AB[ee\\] or W[cc\\]\nnext line]LB[dd:a\\\\] AddWhite[aa]\n  [bb]x[zz]"
+	got := tokenizeNode(node)
+	want := []nodeProp{
+		{"B", []string{"dd"}, "B[dd]"},
+		{"C", []string{"try AB[ee\\] or W[cc\\]\nnext line"}, "C[try AB[ee\\] or W[cc\\]\nnext line]"},
+		{"LB", []string{"dd:a\\\\"}, "LB[dd:a\\\\]"},
+		{"AW", []string{"aa", "bb"}, "AddWhite[aa]\n  [bb]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenizeNode =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestValueEnd(t *testing.T) {
+	tests := []struct {
+		s      string
+		end    int
+		closed bool
+	}{
+		{"[dd]B", 4, true},
+		{"[a\\]b]", 6, true},
+		{"[a\\\\]b]", 5, true},
+		{"[cut", 4, false},
+		{"[cut\\", 5, false},
+	}
+	for _, tt := range tests {
+		if end, closed := valueEnd(tt.s, 0); end != tt.end || closed != tt.closed {
+			t.Errorf("valueEnd(%q) = %d, %v; want %d, %v", tt.s, end, closed, tt.end, tt.closed)
+		}
+	}
+}
+
+// talkativeSGF has comments that mention coordinates and property names,
+// escaped brackets and backslashes, and a root spread over several lines.
+const talkativeSGF = `(
+  ;GM[1]FF[4]
+  SZ[9]
+  KM[6.5]
+  PB[Black \] player]
+  PW[C:\\games\\]
+  C[Setup: AB[cc\] would be a handicap stone;
+W[ee\] is not a move (nor ;B[ff\])]
+  AB[gg]
+;
+  BL[30]B[ee]C[why not AW[dd\]?]
+;C[a comment before the move]W[cc]
+;B[]
+)`
+
+func TestTalkativeComments(t *testing.T) {
+	path := writeTempSGF(t, t.TempDir(), "talk.sgf", talkativeSGF)
+	info, err := ParseHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.BoardSize != 9 || info.Komi != 6.5 || info.MoveCount != 3 || info.NextColor != 2 {
+		t.Errorf("header %dx%d komi %v, %d moves, next %d; want 9x9 6.5, 3 moves, White next",
+			info.BoardSize, info.BoardSize, info.Komi, info.MoveCount, info.NextColor)
+	}
+	if info.PlayerBlack != "Black ] player" || info.PlayerWhite != `C:\games\` {
+		t.Errorf("players %q, %q", info.PlayerBlack, info.PlayerWhite)
+	}
+	if !strings.HasPrefix(info.Comment, "Setup: AB[cc] would be") || !strings.HasSuffix(info.Comment, "(nor ;B[ff])") {
+		t.Errorf("root comment %q", info.Comment)
+	}
+	if info.MoveComments[0] != "why not AW[dd]?" || info.MoveComments[1] != "a comment before the move" {
+		t.Errorf("move comments %q", info.MoveComments)
+	}
+
+	board, moves, err := ReplayToEnd(path)
+	if err != nil || moves != 3 {
+		t.Fatalf("ReplayToEnd: %d moves, %v", moves, err)
+	}
+	var stones []string
+	for y, row := range board {
+		for x, c := range row {
+			if c != 0 {
+				stones = append(stones, string([]byte{'a' + byte(x), 'a' + byte(y)})+"BW"[c-1:c])
+			}
+		}
+	}
+	if got, want := strings.Join(stones, " "), "ccW eeB ggB"; got != want {
+		t.Errorf("stones %s, want %s", got, want)
+	}
+	entries, err := ParseMovesAsEntries(path)
+	if want := [][3]int{{1, 4, 4}, {2, 2, 2}, {1, -1, -1}}; err != nil || !reflect.DeepEqual(entries, want) {
+		t.Errorf("ParseMovesAsEntries = %v, %v; want %v", entries, err, want)
+	}
+	if problems, _ := VerifyContent(talkativeSGF); len(problems) != 0 {
+		t.Errorf("VerifyContent: %v", problems)
+	}
+}
+
+func TestMultilineRootEdits(t *testing.T) {
+	content := "(\n;GM[1]SZ[9]\nPB[a]\n;B[ee])"
+	if got := rootNode(content); got != "GM[1]SZ[9]\nPB[a]\n" {
+		t.Errorf("rootNode = %q", got)
+	}
+	dated := SetDate(content, time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC))
+	if !strings.Contains(dated, "PB[a]DT[") || !strings.HasSuffix(dated, "\n;B[ee])") {
+		t.Errorf("SetDate = %q", dated)
+	}
+	path := writeTempSGF(t, t.TempDir(), "root.sgf", content)
+	if err := SetGameName(path, "lines"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := ParseHeader(path)
+	if err != nil || info.GameName != "lines" || info.MoveCount != 1 {
+		t.Errorf("after SetGameName: %+v, %v", info, err)
+	}
+}
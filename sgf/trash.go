@@ -0,0 +1,107 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TrashDir is the folder in the history directory that deleted games are
+// moved to. ListGames doesn't look inside it.
+const TrashDir = ".trash"
+
+// TrashMaxAge is how long a deleted game stays in the trash before
+// PurgeTrash removes it.
+const TrashMaxAge = 30 * 24 * time.Hour
+
+// Trashed is a game moved to the trash, with what RestoreGame needs to put
+// it back as it was.
+type Trashed struct {
+	Path    string    // where it is in the trash
+	From    string    // where it was
+	ModTime time.Time // its modification time before it was trashed
+}
+
+// TrashGame moves the game at path into the trash folder beside it,
+// under a name of its own if an earlier game of that name is there. The
+// file's modification time becomes the time it was trashed, which
+// PurgeTrash ages it by.
+func TrashGame(path string) (Trashed, error) {
+	t := Trashed{From: path}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return t, err
+	}
+	t.ModTime = fi.ModTime()
+
+	dir := filepath.Join(filepath.Dir(path), TrashDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return t, fmt.Errorf("create trash: %w", err)
+	}
+	t.Path = freePath(dir, filepath.Base(path))
+	if err := os.Rename(path, t.Path); err != nil {
+		return t, err
+	}
+	now := time.Now()
+	os.Chtimes(t.Path, now, now)
+	return t, nil
+}
+
+// freePath returns the path for name in dir, or if a file has it, for
+// name with -2, -3 and so on before its extension.
+func freePath(dir, name string) string {
+	ext := filepath.Ext(name)
+	path := filepath.Join(dir, name)
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext))
+	}
+}
+
+// RestoreGame moves a trashed game back where it was, with its old
+// modification time. It won't overwrite a game saved there since.
+func RestoreGame(t Trashed) error {
+	if _, err := os.Lstat(t.From); err == nil {
+		return fmt.Errorf("%s already exists", filepath.Base(t.From))
+	}
+	if err := os.Rename(t.Path, t.From); err != nil {
+		return err
+	}
+	if !t.ModTime.IsZero() {
+		os.Chtimes(t.From, t.ModTime, t.ModTime)
+	}
+	return nil
+}
+
+// PurgeTrash removes the games trashed in dir more than maxAge before now,
+// and returns how many it removed. A history without a trash has nothing
+// to purge.
+func PurgeTrash(dir string, maxAge time.Duration, now time.Time) (int, error) {
+	trash := filepath.Join(dir, TrashDir)
+	entries, err := os.ReadDir(trash)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read trash: %w", err)
+	}
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil || now.Sub(fi.ModTime()) <= maxAge {
+			continue
+		}
+		if os.Remove(filepath.Join(trash, e.Name())) == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,72 @@
+package sgf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempSGF(t, dir, "2026-01-15_120000_9x9.sgf", testSGF)
+	played := time.Date(2026, 1, 15, 12, 30, 0, 0, time.Local)
+	os.Chtimes(path, played, played)
+
+	first, err := TrashGame(path)
+	if err != nil {
+		t.Fatalf("TrashGame: %v", err)
+	}
+	if games, _ := ListGames(dir); len(games) != 0 {
+		t.Errorf("trashed game still listed: %v", games)
+	}
+	if filepath.Dir(first.Path) != filepath.Join(dir, TrashDir) {
+		t.Errorf("trashed to %s", first.Path)
+	}
+
+	// A second game of the same name doesn't overwrite the first
+	writeTempSGF(t, dir, "2026-01-15_120000_9x9.sgf", testSGF)
+	second, err := TrashGame(path)
+	if err != nil || second.Path == first.Path {
+		t.Fatalf("second TrashGame = %s, %v", second.Path, err)
+	}
+
+	if err := RestoreGame(first); err != nil {
+		t.Fatalf("RestoreGame: %v", err)
+	}
+	if err := RestoreGame(second); err == nil {
+		t.Error("restore over a game in the way succeeded")
+	}
+	if fi, err := os.Stat(path); err != nil || !fi.ModTime().Equal(played) {
+		t.Errorf("restored game: %v, %v; want it modified at %s", fi, err, played)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	dir := t.TempDir()
+	if n, err := PurgeTrash(dir, TrashMaxAge, time.Now()); n != 0 || err != nil {
+		t.Errorf("PurgeTrash without a trash = %d, %v", n, err)
+	}
+
+	old, err := TrashGame(writeTempSGF(t, dir, "old.sgf", testSGF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recent, err := TrashGame(writeTempSGF(t, dir, "recent.sgf", testSGF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	then := now.Add(-TrashMaxAge - time.Hour)
+	os.Chtimes(old.Path, then, then)
+
+	if n, err := PurgeTrash(dir, TrashMaxAge, now); n != 1 || err != nil {
+		t.Errorf("PurgeTrash = %d, %v; want 1 removed", n, err)
+	}
+	if _, err := os.Stat(old.Path); !os.IsNotExist(err) {
+		t.Error("old game still in the trash")
+	}
+	if _, err := os.Stat(recent.Path); err != nil {
+		t.Errorf("recent game purged: %v", err)
+	}
+}
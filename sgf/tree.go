@@ -0,0 +1,142 @@
+package sgf
+
+import (
+	"fmt"
+	"os"
+)
+
+// ReadTree parses the game at filePath into its tree of nodes, variations
+// included, with Current at the root. Each node's first child continues
+// the main line, as in the file. In a collection it reads the first game,
+// or game[0] if given.
+func ReadTree(filePath string, game ...int) (*GameTree, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReadable(string(data)); err != nil {
+		return nil, err
+	}
+	content, err := pickGame(string(data), game)
+	if err != nil {
+		return nil, err
+	}
+	root := parseTree(content)
+	return &GameTree{Root: root, Current: root}, nil
+}
+
+// MainLine returns n and the nodes after it along the main line, first
+// children all the way down.
+func (n *GameNode) MainLine() []*GameNode {
+	var line []*GameNode
+	for ; n != nil; n = n.firstChild() {
+		line = append(line, n)
+	}
+	return line
+}
+
+func (n *GameNode) firstChild() *GameNode {
+	if len(n.Children) == 0 {
+		return nil
+	}
+	return n.Children[0]
+}
+
+// Values returns the raw values of property key in the node as read, e.g.
+// the points of "AB"; nil for a node added with AddMove.
+func (n *GameNode) Values(key string) []string {
+	props := make(properties)
+	extractProps(n.Text, props)
+	return props[key]
+}
+
+// parseTree parses the first game tree in content, from its "(", into
+// nodes and returns the root, or nil if there is no game tree. A tree cut
+// off by the end of content ends there.
+func parseTree(content string) *GameNode {
+	start := treeStart(content)
+	if start == -1 {
+		return nil
+	}
+	p := treeParser{s: content, i: start + 1}
+	return p.gameTree(nil)
+}
+
+// treeParser reads SGF game trees: "(" then a sequence of ";" nodes, then
+// the variations, each a game tree itself, then ")".
+type treeParser struct {
+	s string
+	i int
+}
+
+// gameTree reads the rest of a game tree whose "(" has been read, hanging
+// its nodes below parent, and returns its first node.
+func (p *treeParser) gameTree(parent *GameNode) *GameNode {
+	var first *GameNode
+	last := parent
+	for p.i < len(p.s) {
+		switch p.s[p.i] {
+		case ';':
+			n := &GameNode{Parent: last, Text: p.node()}
+			n.Move = moveText(n.Text)
+			if last != nil {
+				last.Children = append(last.Children, n)
+			}
+			if first == nil {
+				first = n
+			}
+			last = n
+		case '(':
+			p.i++
+			p.gameTree(last)
+		case ')':
+			p.i++
+			return first
+		case '[':
+			// A value outside any node
+			p.skipValue()
+		default:
+			p.i++
+		}
+	}
+	return first
+}
+
+// node reads a node from its ";" up to the next node, variation or end of
+// tree, and returns its text.
+func (p *treeParser) node() string {
+	start := p.i
+	for p.i++; p.i < len(p.s); p.i++ {
+		switch p.s[p.i] {
+		case '[':
+			p.skipValue()
+			p.i--
+		case ';', '(', ')':
+			return p.s[start:p.i]
+		}
+	}
+	return p.s[start:]
+}
+
+// skipValue moves past the "[...]" value at p.i, or to the end of a value
+// cut off by the end of the file.
+func (p *treeParser) skipValue() {
+	p.i, _ = valueEnd(p.s, p.i)
+}
+
+// moveText returns the move a node plays as GameRecord writes it, ";B[pd]"
+// or ";W[]" for a pass, or "" for a node that plays none.
+func moveText(node string) string {
+	color, x, y, ok := parseMoveNode(node)
+	if !ok {
+		return ""
+	}
+	colorChar := "B"
+	if color == 2 {
+		colorChar = "W"
+	}
+	if x == -1 && y == -1 {
+		return fmt.Sprintf(";%s[]", colorChar)
+	}
+	return fmt.Sprintf(";%s[%c%c]", colorChar, 'a'+x, 'a'+y)
+}
@@ -0,0 +1,128 @@
+package sgf
+
+import (
+	"strings"
+	"testing"
+)
+
+// variationSGF has a main line B ee, W cc, B gg, W gc with a variation
+// at each of the first two moves, one of them nested.
+const variationSGF = `(;GM[1]FF[4]SZ[9]KM[6.5]
+;B[ee]
+(;W[cc];B[gg]
+  (;W[gc]C[main])
+  (;W[cg];B[cf]))
+(;W[gg]C[try this]
+  (;B[cc])
+  (;B[gc];W[cc])))`
+
+func TestReadTreeVariations(t *testing.T) {
+	path := writeTempSGF(t, t.TempDir(), "var.sgf", variationSGF)
+	tree, err := ReadTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var main []string
+	for _, n := range tree.Root.MainLine()[1:] {
+		main = append(main, n.Move)
+	}
+	if got, want := strings.Join(main, ""), ";B[ee];W[cc];B[gg];W[gc]"; got != want {
+		t.Errorf("main line %s, want %s", got, want)
+	}
+	if v := tree.Root.Values("SZ"); len(v) != 1 || v[0] != "9" {
+		t.Errorf("root SZ %v", v)
+	}
+
+	// Into the second variation at move 2, then its second branch
+	tree.Forward(0)
+	if !tree.Forward(1) || tree.NumVariations() != 2 {
+		t.Fatalf("no second variation at move 2")
+	}
+	if c := tree.Current.Values("C"); len(c) != 1 || c[0] != "try this" {
+		t.Errorf("variation comment %v", c)
+	}
+	tree.Forward(1)
+	tree.Forward(0)
+	if got, want := strings.Join(tree.PathFromRoot(), ""), ";B[ee];W[gg];B[gc];W[cc]"; got != want {
+		t.Errorf("path %s, want %s", got, want)
+	}
+	if tree.HasChildren() {
+		t.Errorf("the variation goes on past its end")
+	}
+}
+
+func TestMainLineOnly(t *testing.T) {
+	path := writeTempSGF(t, t.TempDir(), "var.sgf", variationSGF)
+	board, moves, err := ReplayToEnd(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moves != 4 {
+		t.Errorf("ReplayToEnd counted %d moves, want the main line's 4", moves)
+	}
+	stones := 0
+	for _, row := range board {
+		for _, c := range row {
+			if c != 0 {
+				stones++
+			}
+		}
+	}
+	if stones != 4 || board[2][6] != 2 || board[6][2] != 0 {
+		t.Errorf("final position has %d stones, gc %d, cg %d; want the main line's 4", stones, board[2][6], board[6][2])
+	}
+
+	entries, err := ParseMovesAsEntries(path)
+	if err != nil || len(entries) != 4 || entries[3] != [3]int{2, 6, 2} {
+		t.Errorf("ParseMovesAsEntries = %v, %v", entries, err)
+	}
+	info, err := ParseHeader(path)
+	if err != nil || info.MoveCount != 4 || info.NextColor != 1 {
+		t.Fatalf("ParseHeader = %+v, %v", info, err)
+	}
+	if len(info.MoveComments) != 1 || info.MoveComments[3] != "main" {
+		t.Errorf("move comments %v, want only the main line's", info.MoveComments)
+	}
+}
+
+func TestVariationSetupIgnored(t *testing.T) {
+	// The second variation sets up stones; the main line doesn't see them
+	content := `(;GM[1]SZ[9]AB[aa]
+(;B[ee];W[cc])
+(;AB[bb][cb]AW[ba];B[dd]))`
+	path := writeTempSGF(t, t.TempDir(), "setup.sgf", content)
+	board, moves, err := ReplayToEnd(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moves != 2 || board[0][0] != 1 || board[1][1] != 0 || board[0][1] != 0 {
+		t.Errorf("%d moves, aa %d bb %d ba %d; want the root setup and the main line only", moves, board[0][0], board[1][1], board[0][1])
+	}
+	blacks, whites, err := ParseSetupPositions(path)
+	if err != nil || len(blacks) != 1 || len(whites) != 0 {
+		t.Errorf("setup %v %v, %v; want only AB[aa]", blacks, whites, err)
+	}
+
+	tree, err := ReadTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Root.Children) != 2 {
+		t.Fatalf("root has %d children, want 2", len(tree.Root.Children))
+	}
+	setup := tree.Root.Children[1]
+	if setup.Move != "" || len(setup.Values("AB")) != 2 || len(setup.Values("AW")) != 1 {
+		t.Errorf("setup node %q: move %q, AB %v, AW %v", setup.Text, setup.Move, setup.Values("AB"), setup.Values("AW"))
+	}
+}
+
+func TestReadTreeTruncated(t *testing.T) {
+	path := writeTempSGF(t, t.TempDir(), "cut.sgf", "(;GM[1]SZ[9];B[ee](;W[cc]")
+	if _, err := ReadTree(path); err != ErrTruncated {
+		t.Errorf("ReadTree error %v, want ErrTruncated", err)
+	}
+	// The readers that don't check still take what there is
+	if root := parseTree("(;GM[1]SZ[9];B[ee](;W[cc]"); len(root.MainLine()) != 3 {
+		t.Errorf("cut-off tree has %d nodes on the main line, want 3", len(root.MainLine()))
+	}
+}
@@ -0,0 +1,250 @@
+package sgf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RepairedSuffix replaces ".sgf" in the name of a file written by RepairFile.
+const RepairedSuffix = ".repaired.sgf"
+
+// Report describes what Verify found wrong with one SGF file.
+type Report struct {
+	FilePath   string
+	Problems   []string
+	Repairable bool     // truncated in a way Repair can fix
+	Warnings   []string // worth knowing but not damage, e.g. clock skew
+}
+
+// OK returns true if no problems were found.
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify reads an SGF file and checks it for damage. The error is only
+// for a file that can't be read; problems with its contents go in the report.
+func Verify(filePath string) (*Report, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	problems, repairable := VerifyContent(string(data))
+	r := &Report{FilePath: filePath, Problems: problems, Repairable: repairable}
+	if fi, err := os.Stat(filePath); err == nil {
+		dt := parseProperties(string(data)).get("DT")
+		if d := ResolveDate(dt, filepath.Base(filePath), fi.ModTime()); d.Note != "" {
+			r.Warnings = append(r.Warnings, d.Note)
+		}
+	}
+	return r, nil
+}
+
+// VerifyDir verifies every .sgf file in dir, in name order.
+func VerifyDir(dir string) ([]Report, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history dir: %w", err)
+	}
+	var reports []Report
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sgf") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		r, err := Verify(path)
+		if err != nil {
+			r = &Report{FilePath: path, Problems: []string{err.Error()}}
+		}
+		reports = append(reports, *r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].FilePath < reports[j].FilePath })
+	return reports, nil
+}
+
+// VerifyContent checks SGF text for unbalanced parentheses, truncated
+// values, a missing SZ, coordinates off the board, and an empty game.
+// repairable is true when Repair can fix the structure.
+func VerifyContent(content string) (problems []string, repairable bool) {
+	if treeStart(content) == -1 {
+		return []string{"not an SGF game (no \"(;\")"}, false
+	}
+
+	s := scanStructure(content)
+	switch {
+	case s.inValue:
+		problems = append(problems, "truncated inside a property value")
+	case s.dangling:
+		problems = append(problems, "truncated inside a node")
+	}
+	if s.depth > 0 {
+		problems = append(problems, fmt.Sprintf("unbalanced parentheses: %d left open", s.depth))
+	} else if s.extraClose > 0 {
+		problems = append(problems, fmt.Sprintf("unbalanced parentheses: %d unmatched ')'", s.extraClose))
+	}
+	// The remaining checks look at what a repair would keep, so a cut-off
+	// last move isn't also reported as a bad coordinate.
+	if len(problems) > 0 {
+		var fixed string
+		if fixed, repairable = Repair(content); repairable {
+			content = fixed
+		}
+	}
+
+	props := parseProperties(content)
+	size := 19
+	sz := props.get("SZ")
+	if len(props["SZ"]) == 0 {
+		problems = append(problems, "missing SZ (board size)")
+	} else if n, err := strconv.Atoi(sz); err != nil || n < 2 || n > 25 {
+		problems = append(problems, fmt.Sprintf("bad board size SZ[%s]", sz))
+	} else {
+		size = n
+	}
+
+	// Setup stones may sit in the root or any node before the first move.
+	nodes := append([]string{rootNode(content)}, parseNodes(content)...)
+	moves := 0
+	for i, node := range nodes {
+		values := make(properties)
+		extractProps(node, values)
+		for _, key := range []string{"AB", "AW", "AE"} {
+			for _, v := range values[key] {
+				points := expandPoints(v)
+				bad := len(points) == 0
+				for _, p := range points {
+					bad = bad || !validPoint(p, size)
+				}
+				if bad {
+					problems = append(problems, fmt.Sprintf("bad setup coordinate %s[%s]", key, v))
+				}
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		for _, key := range []string{"B", "W"} {
+			for _, v := range values[key] {
+				moves++
+				if v != "" && !(v == "tt" && size <= 19) && !validPoint(v, size) {
+					problems = append(problems, fmt.Sprintf("bad coordinate %s[%s] at move %d", key, v, moves))
+				}
+			}
+		}
+	}
+	if moves == 0 {
+		problems = append(problems, "no moves")
+	}
+
+	return problems, repairable
+}
+
+// Repair fixes a truncated game: a trailing partial node is dropped and
+// unclosed parentheses are closed. It returns false if there is nothing
+// it can fix, including damage that isn't truncation.
+func Repair(content string) (string, bool) {
+	s := scanStructure(content)
+	if s.extraClose > 0 || (s.depth == 0 && !s.inValue && !s.dangling) {
+		return content, false
+	}
+
+	fixed := content
+	if s.inValue || s.dangling {
+		if start, _, _ := rootSpan(content); s.lastNodeStart < start {
+			return content, false // the root node itself is cut off
+		}
+		fixed = content[:s.lastNodeStart]
+	}
+	// A variation opened right before the cut would be empty.
+	fixed = strings.TrimRight(fixed, " \t\r\n(")
+
+	s = scanStructure(fixed)
+	if s.inValue || s.dangling || s.extraClose > 0 {
+		return content, false
+	}
+	return fixed + strings.Repeat(")", s.depth) + "\n", true
+}
+
+// RepairFile repairs the file at filePath and writes the result next to
+// it with RepairedSuffix, leaving the original alone. It returns the path
+// written.
+func RepairFile(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	fixed, ok := Repair(string(data))
+	if !ok {
+		return "", fmt.Errorf("%s: no automatic repair for this damage", filepath.Base(filePath))
+	}
+	out := strings.TrimSuffix(filePath, ".sgf") + RepairedSuffix
+	if err := os.WriteFile(out, []byte(fixed), 0644); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// structure is what scanStructure learns about a file's nesting.
+type structure struct {
+	depth         int  // parentheses still open at the end
+	trees         int  // '(' seen: more than one means variations or several games
+	extraClose    int  // ')' with nothing to close
+	inValue       bool // ended inside [...]
+	dangling      bool // ended after a property name with no value
+	lastNodeStart int  // index of the last ';' outside a value
+}
+
+// scanStructure walks content tracking parentheses, nodes and values.
+func scanStructure(content string) structure {
+	var s structure
+	lastValueEnd := -1
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '[':
+			end, closed := valueEnd(content, i)
+			s.inValue = !closed
+			i = end - 1
+			lastValueEnd = i
+		case c == ';':
+			s.lastNodeStart = i
+		case c == '(':
+			s.depth++
+			s.trees++
+		case c == ')':
+			if s.depth == 0 {
+				s.extraClose++
+			} else {
+				s.depth--
+			}
+		}
+	}
+
+	// In an unclosed game, anything but whitespace after the last value of
+	// the last node is a property name that lost its value.
+	if !s.inValue && s.depth > 0 {
+		tail := content[s.lastNodeStart:]
+		if lastValueEnd > s.lastNodeStart {
+			tail = content[lastValueEnd+1:]
+		} else if len(tail) > 0 {
+			tail = tail[1:]
+		}
+		tail = strings.TrimRight(tail, " \t\r\n()")
+		s.dangling = strings.TrimSpace(tail) != ""
+	}
+	return s
+}
+
+// validPoint returns true for a two-letter SGF point on a board of size.
+func validPoint(v string, size int) bool {
+	if len(v) != 2 {
+		return false
+	}
+	x, y := int(v[0]-'a'), int(v[1]-'a')
+	return x >= 0 && x < size && y >= 0 && y < size
+}
@@ -0,0 +1,122 @@
+package sgf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "corrupt", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return string(data)
+}
+
+func TestVerifyContent(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		want       []string // substrings, one per expected problem
+		repairable bool
+	}{
+		{"valid.sgf", nil, false},
+		{"truncated-value.sgf", []string{"inside a property value", "1 left open"}, true},
+		{"truncated-node.sgf", []string{"inside a node", "1 left open"}, true},
+		{"missing-paren.sgf", []string{"1 left open"}, true},
+		{"truncated-variation.sgf", []string{"inside a property value", "2 left open"}, true},
+		{"truncated-root.sgf", []string{"inside a property value", "1 left open", "no moves"}, false},
+		{"bad-coords.sgf", []string{"W[zz] at move 2", "B[e] at move 3"}, false},
+		{"no-size.sgf", []string{"missing SZ"}, false},
+		{"no-moves.sgf", []string{"no moves"}, false},
+		{"extra-paren.sgf", []string{"1 unmatched ')'"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			problems, repairable := VerifyContent(readFixture(t, tt.fixture))
+			if len(problems) != len(tt.want) {
+				t.Fatalf("problems = %q, want %d matching %q", problems, len(tt.want), tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i], want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i, problems[i], want)
+				}
+			}
+			if repairable != tt.repairable {
+				t.Errorf("repairable = %v, want %v", repairable, tt.repairable)
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	const header = "(;GM[1]FF[4]CA[UTF-8]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]DT[2026-01-15]RE[?]\n"
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{"truncated-value.sgf", header + ";B[ee];W[cc];B[gg])\n"},
+		{"truncated-node.sgf", header + ";B[ee];W[cc];B[gg])\n"},
+		{"missing-paren.sgf", header + ";B[ee];W[cc];B[gg])\n"},
+		{"truncated-variation.sgf", "(;GM[1]FF[4]SZ[9]KM[6.5]\n;B[ee](;W[cc];B[gg])(;W[gc]))\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, ok := Repair(readFixture(t, tt.fixture))
+			if !ok {
+				t.Fatal("Repair refused a truncated file")
+			}
+			if got != tt.want {
+				t.Errorf("Repair =\n%s\nwant\n%s", got, tt.want)
+			}
+			if problems, _ := VerifyContent(got); len(problems) != 0 {
+				t.Errorf("repaired file still has problems: %q", problems)
+			}
+		})
+	}
+
+	for _, name := range []string{"valid.sgf", "truncated-root.sgf", "extra-paren.sgf", "bad-coords.sgf"} {
+		if _, ok := Repair(readFixture(t, name)); ok {
+			t.Errorf("Repair(%s) should refuse", name)
+		}
+	}
+}
+
+func TestRepairFile(t *testing.T) {
+	dir := t.TempDir()
+	damaged := readFixture(t, "truncated-value.sgf")
+	path := writeTempSGF(t, dir, "2026-01-15_1200_9x9.sgf", damaged)
+	writeTempSGF(t, dir, "2026-01-14_1200_9x9.sgf", readFixture(t, "valid.sgf"))
+
+	reports, err := VerifyDir(dir)
+	if err != nil {
+		t.Fatalf("VerifyDir: %v", err)
+	}
+	if len(reports) != 2 || !reports[0].OK() || reports[1].OK() || !reports[1].Repairable {
+		t.Fatalf("reports = %+v, want the older file clean and the newer repairable", reports)
+	}
+
+	out, err := RepairFile(path)
+	if err != nil {
+		t.Fatalf("RepairFile: %v", err)
+	}
+	if want := filepath.Join(dir, "2026-01-15_1200_9x9"+RepairedSuffix); out != want {
+		t.Errorf("repaired path = %q, want %q", out, want)
+	}
+	if data, _ := os.ReadFile(path); string(data) != damaged {
+		t.Error("RepairFile modified the original")
+	}
+	info, err := ParseHeader(out)
+	if err != nil {
+		t.Fatalf("ParseHeader(repaired): %v", err)
+	}
+	if info.MoveCount != 3 || info.BoardSize != 9 {
+		t.Errorf("repaired game: %d moves on %d, want 3 on 9", info.MoveCount, info.BoardSize)
+	}
+
+	if _, err := RepairFile(reports[0].FilePath); err == nil {
+		t.Error("RepairFile on a clean file should fail")
+	}
+}
@@ -2,6 +2,7 @@
 package sgf
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,34 @@ import (
 	"time"
 )
 
+// ErrMoveAfterResult is returned by AddMove once the game has a result,
+// as the move would come after the game ended.
+var ErrMoveAfterResult = errors.New("move after the game's result")
+
+// ErrCollection is returned by OpenGameRecord for a file holding more than
+// one game: written back, it would hold only the one played on.
+var ErrCollection = errors.New("the file holds more games than the one played on")
+
+// appVersion is the version written in AP[] after the program's name.
+var appVersion = "dev"
+
+// SetAppVersion sets the version records name as the one that wrote them,
+// e.g. "v1.4.0". It is "dev" until set.
+func SetAppVersion(v string) {
+	appVersion = v
+}
+
+// RecordOptions are root properties for NewGameRecord to write from the
+// start. Each is left out while empty, so RecordOptions{} writes none.
+type RecordOptions struct {
+	Ruleset       string        // RU[], e.g. "Japanese"
+	MainTime      time.Duration // TM[]
+	ByoYomiTime   time.Duration // OT[] with ByoYomiStones, as SetTimeControl
+	ByoYomiStones int
+	GameName      string // GN[]
+	Event         string // EV[]
+}
+
 // GameRecord tracks a game in progress and writes it as SGF.
 type GameRecord struct {
 	FilePath    string
@@ -18,30 +47,52 @@ type GameRecord struct {
 	PlayerWhite string
 	Date        string
 	Result      string
-	moves       []string // ";B[pd]", ";W[dp]", ...
-	setupBlack  []string // AB coords for mid-game toggle
-	setupWhite  []string // AW coords
+	Comment     string         // root node C[]
+	StartMove   int            // moves played before recording began (0 = from the start)
+	Handicap    int            // HA[], 0 for an even game
+	TimeLimit   time.Duration  // TM[], each side's main time; 0 if untimed
+	Overtime    string         // OT[], e.g. "1x30 byo-yomi"
+	Ruleset     string         // RU[], "" to leave it out
+	GameName    string         // GN[]
+	Event       string         // EV[]
+	GameType    GameType       // written as the first line of GC[] unless AgainstEngine
+	Seed        int64          // the engine's random seed, a GC[] line after the type; 0 for none
+	GameComment string         // the rest of GC[]
+	human       int            // the human's color, 1 or 2; 0 in an opened record
+	handicap    []string       // AB coords of the handicap stones, in the root node
+	moves       []string       // ";B[pd]", ";W[dp]", ...
+	setupBlack  []string       // AB coords for mid-game toggle
+	setupWhite  []string       // AW coords
+	setupToPlay int            // PL in the setup node, 0 if unset
+	comments    map[int]string // C[] text keyed by move index
+	extras      recordExtras   // properties of an opened file kept as they were
+	batch       int            // Begin calls not yet matched by Commit
+	dirty       bool           // changed during a batch, so Commit must write
 	file        *os.File
+	content     string      // what the file holds as of the last write, "" before the first
+	written     os.FileInfo // the file as last written, to tell if it went missing since
+	writeErr    error       // why the last write failed, nil once one succeeds
+	recreated   bool        // the last write found the file gone and created it again
 }
 
 // NewGameRecord creates a new SGF file in dir and writes the initial header.
-// playerColor is 1=black, 2=white (the human player's color).
-func NewGameRecord(dir string, boardSize int, komi float64, playerColor, engineLevel int) (*GameRecord, error) {
+// playerColor is 1=black, 2=white (the human player's color). opts adds
+// to the header.
+func NewGameRecord(dir string, boardSize int, komi float64, playerColor, engineLevel int, opts RecordOptions) (*GameRecord, error) {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("create history dir: %w", err)
 	}
 
 	now := time.Now()
-	filename := fmt.Sprintf("%s_%dx%d.sgf", now.Format("2006-01-02_150405"), boardSize, boardSize)
-	path := filepath.Join(dir, filename)
+	path := filepath.Join(dir, RecordName(now, boardSize))
 
 	f, err := os.Create(path)
 	if err != nil {
 		return nil, fmt.Errorf("create sgf file: %w", err)
 	}
 
-	human := "Player"
-	engine := fmt.Sprintf("GnuGo Level %d", engineLevel)
+	human := humanName
+	engine := fmt.Sprintf(engineNameFormat, engineLevel)
 
 	var pb, pw string
 	if playerColor == 1 {
@@ -56,10 +107,13 @@ func NewGameRecord(dir string, boardSize int, komi float64, playerColor, engineL
 		Komi:        komi,
 		PlayerBlack: pb,
 		PlayerWhite: pw,
-		Date:        now.Format("2006-01-02"),
+		Date:        now.Format(dateLayout),
 		Result:      "?",
+		human:       playerColor,
 		file:        f,
 	}
+	rec.Ruleset, rec.GameName, rec.Event = opts.Ruleset, opts.GameName, opts.Event
+	rec.timeControl(opts.MainTime, opts.ByoYomiTime, opts.ByoYomiStones)
 
 	if err := rec.flush(); err != nil {
 		f.Close()
@@ -71,29 +125,129 @@ func NewGameRecord(dir string, boardSize int, komi float64, playerColor, engineL
 
 // OpenGameRecord opens an existing SGF file for continued play.
 // It parses the header, moves, and setup positions, then opens the file for writing.
-// The Result is reset to "?" to allow continued play.
+// The Result is reset to "?" to allow continued play. Properties the
+// record doesn't model, such as LB labels or ranks, are written back as
+// they were; see Dropped for what can't be. A collection of games can't
+// be played on in place; see ErrCollection.
 func OpenGameRecord(filePath string) (*GameRecord, error) {
 	info, err := ParseHeader(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("parse header: %w", err)
 	}
+	if info.Games > 1 {
+		return nil, fmt.Errorf("%s has %d games: %w", filepath.Base(filePath), info.Games, ErrCollection)
+	}
+	rec, err := readGameRecord(filePath, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filePath, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open sgf file: %w", err)
+	}
+	rec.file = f
+
+	if err := rec.flush(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// maxNameTries is how many seconds on BranchGameRecord looks for a free
+// file name.
+const maxNameTries = 60
+
+// BranchGameRecord starts a new record in dir from the first n moves of
+// the game at srcPath, to play it differently from there: the first game
+// in a collection, or game[0] if given. The source file is only read. The
+// new record is dated now, has no result, and notes in GC[] which game it
+// branched from and where.
+func BranchGameRecord(srcPath, dir string, n int, game ...int) (*GameRecord, error) {
+	index := 0
+	if len(game) > 0 {
+		index = game[0]
+	}
+	rec, err := readGameRecord(srcPath, index)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > len(rec.moves) {
+		return nil, fmt.Errorf("no move %d to branch at, the game has %d", n, len(rec.moves))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create history dir: %w", err)
+	}
+
+	// Never over another game, the source included: a name taken by a
+	// game started the same second moves on a second
+	now := time.Now()
+	var path string
+	var f *os.File
+	for i := 0; ; i++ {
+		path = filepath.Join(dir, RecordName(now.Add(time.Duration(i)*time.Second), rec.BoardSize))
+		f, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if !errors.Is(err, os.ErrExist) || i == maxNameTries {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create sgf file: %w", err)
+	}
+
+	rec.FilePath = path
+	rec.Date = now.Format(dateLayout)
+	rec.Comment = ""
+	rec.Seed = 0 // the new game isn't played with it
+	rec.GameComment = fmt.Sprintf("Branched from %s at move %d", filepath.Base(srcPath), rec.StartMove+n)
+	if index > 0 {
+		rec.GameComment = fmt.Sprintf("Branched from game %d of %s at move %d", index+1, filepath.Base(srcPath), rec.StartMove+n)
+	}
+	rec.file = f
+	// UndoMoves drops the comments and kept properties of the later moves
+	// too, and writes the file
+	if err := rec.UndoMoves(len(rec.moves) - n); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// readGameRecord parses game index of the SGF file at filePath into a
+// record without opening it for writing.
+func readGameRecord(filePath string, index int) (*GameRecord, error) {
+	info, err := ParseHeader(filePath, index)
+	if err != nil {
+		return nil, fmt.Errorf("parse header: %w", err)
+	}
 
-	moves, err := ParseMovesForRecord(filePath)
+	moves, err := ParseMovesForRecord(filePath, index)
 	if err != nil {
 		return nil, fmt.Errorf("parse moves: %w", err)
 	}
 
-	blacks, whites, err := ParseSetupPositions(filePath)
+	blacks, whites, err := ParseSetupPositions(filePath, index)
 	if err != nil {
 		return nil, fmt.Errorf("parse setup: %w", err)
 	}
+	// In a handicap game recorded from the start, the black setup stones
+	// are the handicap.
+	var handicap []string
+	if info.Handicap > 0 && info.StartMove == 0 {
+		handicap, blacks = blacks, nil
+	}
 
-	f, err := os.OpenFile(filePath, os.O_RDWR, 0644)
+	content, err := readGame(filePath, []int{index})
 	if err != nil {
-		return nil, fmt.Errorf("open sgf file: %w", err)
+		return nil, err
 	}
+	extras := readExtras(content)
 
-	rec := &GameRecord{
+	return &GameRecord{
 		FilePath:    filePath,
 		BoardSize:   info.BoardSize,
 		Komi:        info.Komi,
@@ -101,18 +255,25 @@ func OpenGameRecord(filePath string) (*GameRecord, error) {
 		PlayerWhite: info.PlayerWhite,
 		Date:        info.Date,
 		Result:      "?",
+		Comment:     info.Comment,
+		StartMove:   info.StartMove,
+		Handicap:    info.Handicap,
+		TimeLimit:   info.TimeLimit,
+		Overtime:    info.Overtime,
+		Ruleset:     info.Ruleset,
+		GameName:    info.GameName,
+		Event:       info.Event,
+		GameType:    info.GameType,
+		Seed:        info.Seed,
+		GameComment: info.GameComment,
+		handicap:    handicap,
 		moves:       moves,
 		setupBlack:  blacks,
 		setupWhite:  whites,
-		file:        f,
-	}
-
-	if err := rec.flush(); err != nil {
-		f.Close()
-		return nil, err
-	}
-
-	return rec, nil
+		setupToPlay: info.setupToPlay,
+		comments:    info.MoveComments,
+		extras:      extras,
+	}, nil
 }
 
 // sgfCoord converts 0-indexed board coordinates to SGF letter pair.
@@ -121,29 +282,44 @@ func sgfCoord(x, y int) string {
 	return string(rune('a'+x)) + string(rune('a'+y))
 }
 
-// AddMove appends a move to the record. Pass is indicated by x==-1 && y==-1.
-func (r *GameRecord) AddMove(x, y, color int) error {
-	colorChar := "B"
+// colorLetter returns the SGF color letter for 1=black, 2=white.
+func colorLetter(color int) string {
 	if color == 2 {
-		colorChar = "W"
+		return "W"
 	}
+	return "B"
+}
 
-	var node string
+// MoveNode formats a move as an SGF node, e.g. ";B[pd]" or ";W[]" for a pass.
+func MoveNode(color, x, y int) string {
+	colorChar := colorLetter(color)
 	if x == -1 && y == -1 {
-		node = fmt.Sprintf(";%s[]", colorChar)
-	} else {
-		node = fmt.Sprintf(";%s[%s]", colorChar, sgfCoord(x, y))
+		return fmt.Sprintf(";%s[]", colorChar)
 	}
+	return fmt.Sprintf(";%s[%s]", colorChar, sgfCoord(x, y))
+}
 
-	r.moves = append(r.moves, node)
+// AddMove appends a move to the record. Pass is indicated by x==-1 && y==-1.
+// Once SetResult has given the game an outcome it returns
+// ErrMoveAfterResult and records nothing.
+func (r *GameRecord) AddMove(x, y, color int) error {
+	if r.hasResult() {
+		return ErrMoveAfterResult
+	}
+	r.moves = append(r.moves, MoveNode(color, x, y))
 	return r.flush()
 }
 
-// AddSetupPosition scans a board and records AB[]/AW[] setup properties.
-// board is indexed as board[y][x] where 1=black, 2=white.
-func (r *GameRecord) AddSetupPosition(board [][]int) error {
+// AddSetupPosition scans a board and records AB[]/AW[] setup properties,
+// for recordings started mid-game. board is indexed as board[y][x] where
+// 1=black, 2=white. toPlay (1 or 2) is written as PL[] so the record replays
+// with the right side to move, and movesPlayed notes how far the game was.
+func (r *GameRecord) AddSetupPosition(board [][]int, toPlay, movesPlayed int) error {
 	r.setupBlack = nil
 	r.setupWhite = nil
+	r.setupToPlay = toPlay
+	r.StartMove = movesPlayed
+	r.extras.setup = ""
 	for y := range board {
 		for x := range board[y] {
 			switch board[y][x] {
@@ -157,12 +333,139 @@ func (r *GameRecord) AddSetupPosition(board [][]int) error {
 	return r.flush()
 }
 
+// SetHandicap records the handicap stones as HA[] and AB[] in the root
+// node. stones are [x, y] points.
+func (r *GameRecord) SetHandicap(stones [][2]int) error {
+	r.Handicap = len(stones)
+	r.handicap = nil
+	for _, p := range stones {
+		r.handicap = append(r.handicap, sgfCoord(p[0], p[1]))
+	}
+	return r.flush()
+}
+
+// AddComment attaches a C[] comment to the move at moveIndex (0-based),
+// replacing any existing comment on that node. Empty text removes it.
+func (r *GameRecord) AddComment(moveIndex int, text string) error {
+	if moveIndex < 0 || moveIndex >= len(r.moves) {
+		return fmt.Errorf("no move at index %d", moveIndex)
+	}
+	if text == "" {
+		delete(r.comments, moveIndex)
+		return r.flush()
+	}
+	if r.comments == nil {
+		r.comments = make(map[int]string)
+	}
+	r.comments[moveIndex] = text
+	return r.flush()
+}
+
+// MoveCount returns the number of moves recorded so far.
+func (r *GameRecord) MoveCount() int {
+	return len(r.moves)
+}
+
 // UndoMoves removes the last n moves from the record.
 func (r *GameRecord) UndoMoves(n int) error {
 	if n > len(r.moves) {
 		n = len(r.moves)
 	}
 	r.moves = r.moves[:len(r.moves)-n]
+	for i := range r.comments {
+		if i >= len(r.moves) {
+			delete(r.comments, i)
+		}
+	}
+	for i := range r.extras.moves {
+		if i >= len(r.moves) {
+			delete(r.extras.moves, i)
+		}
+	}
+	for i := range r.extras.after {
+		if i >= len(r.moves) {
+			delete(r.extras.after, i)
+		}
+	}
+	return r.flush()
+}
+
+// Dropped describes what the opened file had that the record can't write
+// back, such as variations. It is empty when nothing is lost.
+func (r *GameRecord) Dropped() []string {
+	return r.extras.dropped
+}
+
+// SetOpponent names the engine side for a game against an engine other
+// than GnuGo, replacing the "GnuGo Level N" the record starts with.
+func (r *GameRecord) SetOpponent(name string) error {
+	if r.humanIsBlack() {
+		r.PlayerWhite = name
+	} else {
+		r.PlayerBlack = name
+	}
+	return r.flush()
+}
+
+// SetPlayerName names the human side, replacing the "Player" the record
+// starts with. An empty name keeps "Player".
+func (r *GameRecord) SetPlayerName(name string) error {
+	if name == "" {
+		name = humanName
+	}
+	if r.humanIsBlack() {
+		r.PlayerBlack = name
+	} else {
+		r.PlayerWhite = name
+	}
+	return r.flush()
+}
+
+// humanIsBlack reports whether the human plays Black: by the color the
+// record was made with, or in an opened record by the name "Player".
+func (r *GameRecord) humanIsBlack() bool {
+	if r.human != 0 {
+		return r.human == 1
+	}
+	return r.PlayerBlack == humanName
+}
+
+// SetTimeControl records the time controls as TM[] and OT[]: main time,
+// then byoStones moves in every byoTime.
+func (r *GameRecord) SetTimeControl(main, byoTime time.Duration, byoStones int) error {
+	r.timeControl(main, byoTime, byoStones)
+	return r.flush()
+}
+
+// timeControl is SetTimeControl without the write.
+func (r *GameRecord) timeControl(main, byoTime time.Duration, byoStones int) {
+	r.TimeLimit = main
+	r.Overtime = ""
+	switch {
+	case byoTime <= 0 || byoStones <= 0:
+	case byoStones == 1:
+		r.Overtime = fmt.Sprintf("1x%s byo-yomi", formatPoints(byoTime.Seconds()))
+	default:
+		r.Overtime = fmt.Sprintf("%d/%s Canadian", byoStones, formatPoints(byoTime.Seconds()))
+	}
+}
+
+// SetGameType marks the record as a game of type t, e.g. Hotseat.
+func (r *GameRecord) SetGameType(t GameType) error {
+	r.GameType = t
+	return r.flush()
+}
+
+// SetSeed records the random seed the engine was started with, so the
+// game can be played again the same way.
+func (r *GameRecord) SetSeed(seed int64) error {
+	r.Seed = seed
+	return r.flush()
+}
+
+// SetComment sets the root node comment.
+func (r *GameRecord) SetComment(text string) error {
+	r.Comment = text
 	return r.flush()
 }
 
@@ -174,37 +477,101 @@ func (r *GameRecord) SetResult(outcome string) error {
 	return r.flush()
 }
 
-// Close performs a final flush and closes the file handle.
+// hasResult reports whether the game has an outcome; "?" is none yet.
+func (r *GameRecord) hasResult() bool {
+	return r.Result != "" && r.Result != "?"
+}
+
+// Begin starts a batch: changes until the matching Commit are kept in
+// memory and written with a single write. Batches may nest.
+func (r *GameRecord) Begin() {
+	r.batch++
+}
+
+// Commit ends a batch started by Begin. The outermost Commit writes the
+// file if anything changed, so it is valid SGF again from here on.
+func (r *GameRecord) Commit() error {
+	if r.batch == 0 {
+		return nil
+	}
+	r.batch--
+	if r.batch > 0 || !r.dirty {
+		return nil
+	}
+	return r.flush()
+}
+
+// Close performs a final flush and closes the file handle. A batch left
+// open is written as if committed.
 func (r *GameRecord) Close() {
 	if r.file == nil {
 		return
 	}
+	r.batch = 0
 	r.flush()
 	r.file.Close()
 	r.file = nil
 }
 
-// flush rewrites the complete SGF file from scratch.
+// flush writes the record to its file, or during a batch notes that Commit
+// has to. See writeContent for how.
 func (r *GameRecord) flush() error {
 	if r.file == nil {
 		return fmt.Errorf("file already closed")
 	}
+	if r.batch > 0 {
+		r.dirty = true
+		return nil
+	}
+	r.dirty = false
 
 	var b strings.Builder
 
 	// Root node
 	b.WriteString("(;GM[1]FF[4]CA[UTF-8]")
-	b.WriteString(fmt.Sprintf("AP[termsuji-local:1.0]"))
+	// AP is name:version, so a colon in the version is escaped
+	b.WriteString("AP[termsuji-local:" + strings.ReplaceAll(escapeText(appVersion), ":", `\:`) + "]")
 	b.WriteString(fmt.Sprintf("SZ[%d]", r.BoardSize))
-	b.WriteString(fmt.Sprintf("KM[%.1f]", r.Komi))
-	b.WriteString(fmt.Sprintf("PB[%s]", r.PlayerBlack))
-	b.WriteString(fmt.Sprintf("PW[%s]", r.PlayerWhite))
+	b.WriteString("KM[" + FormatKomi(r.Komi) + "]")
+	if r.Handicap > 0 {
+		b.WriteString(fmt.Sprintf("HA[%d]", r.Handicap))
+	}
+	b.WriteString(fmt.Sprintf("PB[%s]", escapeText(r.PlayerBlack)))
+	b.WriteString(fmt.Sprintf("PW[%s]", escapeText(r.PlayerWhite)))
 	b.WriteString(fmt.Sprintf("DT[%s]", r.Date))
 	b.WriteString(fmt.Sprintf("RE[%s]", r.Result))
+	if r.Ruleset != "" {
+		b.WriteString(fmt.Sprintf("RU[%s]", escapeText(r.Ruleset)))
+	}
+	if r.TimeLimit > 0 || r.Overtime != "" {
+		b.WriteString(fmt.Sprintf("TM[%s]", formatPoints(r.TimeLimit.Seconds())))
+	}
+	if r.Overtime != "" {
+		b.WriteString(fmt.Sprintf("OT[%s]", escapeText(r.Overtime)))
+	}
+	if len(r.handicap) > 0 {
+		b.WriteString("AB")
+		for _, c := range r.handicap {
+			b.WriteString(fmt.Sprintf("[%s]", c))
+		}
+	}
+	if r.GameName != "" {
+		b.WriteString(fmt.Sprintf("GN[%s]", escapeText(r.GameName)))
+	}
+	if r.Event != "" {
+		b.WriteString(fmt.Sprintf("EV[%s]", escapeText(r.Event)))
+	}
+	if gc := joinGameType(r.GameType, joinSeed(r.Seed, r.GameComment)); gc != "" {
+		b.WriteString(fmt.Sprintf("GC[%s]", escapeText(gc)))
+	}
+	if comment := joinStartMove(r.StartMove, r.Comment); comment != "" {
+		b.WriteString(fmt.Sprintf("C[%s]", escapeText(comment)))
+	}
+	b.WriteString(r.extras.root)
 	b.WriteString("\n")
 
-	// Setup node (AB/AW for mid-game toggle-on)
-	if len(r.setupBlack) > 0 || len(r.setupWhite) > 0 {
+	// Setup node (AB/AW/PL for mid-game toggle-on)
+	if len(r.setupBlack) > 0 || len(r.setupWhite) > 0 || r.setupToPlay != 0 || r.extras.setup != "" {
 		b.WriteString(";")
 		if len(r.setupBlack) > 0 {
 			b.WriteString("AB")
@@ -218,40 +585,173 @@ func (r *GameRecord) flush() error {
 				b.WriteString(fmt.Sprintf("[%s]", c))
 			}
 		}
+		if r.setupToPlay != 0 {
+			b.WriteString(fmt.Sprintf("PL[%s]", colorLetter(r.setupToPlay)))
+		}
+		b.WriteString(r.extras.setup)
 		b.WriteString("\n")
 	}
 
 	// Move nodes
-	for _, m := range r.moves {
+	for i, m := range r.moves {
 		b.WriteString(m)
+		b.WriteString(r.extras.moves[i])
+		if c, ok := r.comments[i]; ok {
+			b.WriteString(fmt.Sprintf("C[%s]", escapeText(c)))
+		}
+		b.WriteString(r.extras.after[i])
 	}
 
 	b.WriteString(")\n")
 
-	// Rewrite file from start
-	if _, err := r.file.Seek(0, 0); err != nil {
+	r.writeErr = r.ensureFile()
+	if r.writeErr == nil {
+		r.writeErr = r.writeContent(b.String())
+	}
+	if r.writeErr == nil {
+		r.written, _ = r.file.Stat()
+	}
+	return r.writeErr
+}
+
+// writeContent brings the file from what it held to content by writing a
+// new file beside it and renaming that into place, so a crash at any
+// point finds one complete record or the other. A record is a few
+// kilobytes at most, so the rename costs far more than the writing, and a
+// move costs about the same however long the game is.
+func (r *GameRecord) writeContent(content string) error {
+	if content == r.content {
+		return nil
+	}
+	if err := r.replaceFile(content); err != nil {
+		return err
+	}
+	r.content = content
+	return nil
+}
+
+// replaceFile writes content to a temporary file in the record's directory
+// and renames it over FilePath, leaving the handle on the new file.
+func (r *GameRecord) replaceFile(content string) error {
+	mode := os.FileMode(0644)
+	if fi, err := r.file.Stat(); err == nil && !r.recreated {
+		mode = fi.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.FilePath), ".record-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // a no-op once renamed
+	if err := writeFile(tmp, content); err != nil {
+		tmp.Close()
 		return err
 	}
-	if err := r.file.Truncate(0); err != nil {
+	// Synced before the rename, so the name never points at a file
+	// whose contents haven't reached the disk
+	if err := syncFile(tmp); err != nil {
+		tmp.Close()
 		return err
 	}
-	content := b.String()
-	if _, err := r.file.WriteString(content); err != nil {
+	// The record matters more than its mode, which a file system without
+	// permissions, as some synced folders are, won't take
+	if err := tmp.Chmod(mode); err != nil {
+		debugLog.Printf("%s: can't keep mode %v: %v", r.FilePath, mode, err)
+	}
+	if err := os.Rename(tmp.Name(), r.FilePath); err != nil {
+		tmp.Close()
 		return err
 	}
-	return r.file.Sync()
+	r.file.Close()
+	r.file = tmp
+	return nil
+}
+
+// ensureFile makes sure the handle still writes to the file at FilePath.
+// A sync tool or the user may have deleted or replaced the file since the
+// last write, leaving the handle on a file nobody sees; the next write then
+// creates the file again from the whole record.
+func (r *GameRecord) ensureFile() error {
+	r.recreated = false
+	if r.written == nil {
+		return nil
+	}
+	if fi, err := os.Stat(r.FilePath); err == nil && os.SameFile(fi, r.written) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(r.FilePath), 0755); err != nil {
+		return fmt.Errorf("recreate sgf file: %w", err)
+	}
+	debugLog.Printf("%s: gone since the last write, created it again", r.FilePath)
+	r.content = ""
+	r.recreated = true
+	return nil
+}
+
+// checkFile writes the record again if its file went missing or was cut
+// short since the last write.
+func (r *GameRecord) checkFile() error {
+	if r.file == nil || r.written == nil {
+		return nil
+	}
+	fi, err := os.Stat(r.FilePath)
+	if err == nil && os.SameFile(fi, r.written) && fi.Size() == r.written.Size() {
+		return nil
+	}
+	r.content = "" // not what was written any more
+	return r.flush()
 }
 
+// writeFile writes content to f. Tests swap it out to simulate a write
+// cut short by a crash or a full disk.
+var writeFile = func(f *os.File, content string) error {
+	_, err := f.WriteString(content)
+	return err
+}
+
+// syncFile syncs f to disk, once for every write. Tests swap it out to
+// simulate a slow or full disk.
+var syncFile = func(f *os.File) error {
+	return f.Sync()
+}
+
+// escapeText escapes backslashes and "]" for use inside an SGF text value.
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "]", `\]`)
+}
+
+// drawPhrases are outcomes, lower-cased, that mean the game was drawn;
+// "draw (jigo)" is FormatResult's own.
+var drawPhrases = []string{"draw", "drawn", "jigo", "tie", "a tie", "game is a draw", "the game is a draw", "the game is drawn", "draw (jigo)"}
+
+// voidPhrases are outcomes, lower-cased, that mean the game has no result.
+var voidPhrases = []string{"void", "no result", "no contest"}
+
 // parseResult converts various outcome formats to SGF RE[] value.
+// Spelled-out draws become "0", and a decimal comma from a localized engine becomes
+// a point.
 func parseResult(outcome string) string {
 	o := strings.TrimSpace(outcome)
+	low := strings.ToLower(strings.TrimSuffix(o, "."))
 
-	// Already in SGF format
-	if isValidSGFResult(o) {
-		return o
+	// Already in SGF format, perhaps with a comma: "W+5,5", "B+0"
+	if sgf := strings.Replace(o, ",", ".", 1); isValidSGFResult(sgf) {
+		if len(sgf) > 2 && sgf[1] == '+' && isZero(sgf[2:]) {
+			return "0"
+		}
+		return sgf
 	}
 
-	low := strings.ToLower(o)
+	for _, phrase := range drawPhrases {
+		if low == phrase {
+			return "0"
+		}
+	}
+	for _, phrase := range voidPhrases {
+		if low == phrase {
+			return "Void"
+		}
+	}
 
 	// "White wins by 5.5 points" / "Black wins by 5.5 points"
 	// "White wins by resign" / "Black wins by resignation"
@@ -281,26 +781,14 @@ func parseResult(outcome string) string {
 		return winner + "+F"
 	}
 
-	// Try to extract numeric score: "5.5 points" or "5.5"
+	// Try to extract numeric score: "5.5 points", "12,5 points" or "5.5"
 	parts := strings.Fields(rest)
 	if len(parts) > 0 {
-		score := parts[0]
-		// Validate it looks like a number
-		valid := true
-		dotSeen := false
-		for _, ch := range score {
-			if ch == '.' {
-				if dotSeen {
-					valid = false
-					break
-				}
-				dotSeen = true
-			} else if ch < '0' || ch > '9' {
-				valid = false
-				break
+		score := strings.Replace(parts[0], ",", ".", 1)
+		if isScore(score) {
+			if isZero(score) {
+				return "0" // "wins by 0 points" is jigo
 			}
-		}
-		if valid && len(score) > 0 {
 			return winner + "+" + score
 		}
 	}
@@ -310,7 +798,7 @@ func parseResult(outcome string) string {
 
 // isValidSGFResult checks if a string is already a valid SGF result.
 func isValidSGFResult(s string) bool {
-	if s == "?" || s == "Jigo" || s == "Void" || s == "0" {
+	if s == "?" || s == "Jigo" || s == "Draw" || s == "Void" || s == "0" {
 		return true
 	}
 	if len(s) < 3 {
@@ -323,9 +811,14 @@ func isValidSGFResult(s string) bool {
 	if rest == "R" || rest == "T" || rest == "F" || rest == "?" {
 		return true
 	}
-	// Check for numeric score
+	return isScore(rest)
+}
+
+// isScore returns true for a margin of digits with at most one decimal
+// point, e.g. "5.5".
+func isScore(s string) bool {
 	dotSeen := false
-	for _, ch := range rest {
+	for _, ch := range s {
 		if ch == '.' {
 			if dotSeen {
 				return false
@@ -335,5 +828,58 @@ func isValidSGFResult(s string) bool {
 			return false
 		}
 	}
-	return len(rest) > 0
+	return len(s) > 0 && s != "."
+}
+
+// isZero returns true for a margin of nothing, e.g. "0" or "0.0".
+func isZero(score string) bool {
+	return strings.Trim(score, "0.") == ""
+}
+
+// FormatResult describes a result in a sentence for display, e.g.
+// "W+5.5" becomes "White wins by 5.5 points". It accepts anything
+// SetResult does, including the engine's own phrasing.
+func FormatResult(re string) string {
+	re = parseResult(re)
+	switch re {
+	case "0", "Jigo", "Draw":
+		return "Draw (jigo)"
+	case "Void":
+		return "No result"
+	case "?":
+		return "Result unknown"
+	}
+
+	winner := "Black"
+	if re[0] == 'W' {
+		winner = "White"
+	}
+	switch margin := re[2:]; margin {
+	case "R":
+		return winner + " wins by resignation"
+	case "T":
+		return winner + " wins on time"
+	case "F":
+		return winner + " wins by forfeit"
+	case "?":
+		return winner + " wins"
+	case "1":
+		return winner + " wins by 1 point"
+	default:
+		return winner + " wins by " + margin + " points"
+	}
+}
+
+// ShortResult is a result as a list shows it: the SGF result, but "Draw"
+// for any way of writing one and "No result" for a void game, e.g. "0"
+// becomes "Draw" and "White wins by 5.5 points" "W+5.5".
+func ShortResult(re string) string {
+	re = parseResult(re)
+	switch re {
+	case "0", "Jigo", "Draw":
+		return "Draw"
+	case "Void":
+		return "No result"
+	}
+	return re
 }
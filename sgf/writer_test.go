@@ -1,10 +1,15 @@
 package sgf
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestSgfCoord(t *testing.T) {
@@ -15,8 +20,8 @@ func TestSgfCoord(t *testing.T) {
 		{0, 0, "aa"},
 		{3, 4, "de"},
 		{18, 18, "ss"},
-		{15, 3, "pd"},  // common star point
-		{3, 15, "dp"},  // common star point
+		{15, 3, "pd"}, // common star point
+		{3, 15, "dp"}, // common star point
 	}
 	for _, tt := range tests {
 		got := sgfCoord(tt.x, tt.y)
@@ -46,8 +51,30 @@ func TestParseResult(t *testing.T) {
 		{"White wins by time", "W+T"},
 		{"Black wins by forfeit", "B+F"},
 
+		// Draws
+		{"Draw", "Draw"},
+		{"draw", "0"},
+		{"The game is a draw.", "0"},
+		{"jigo", "0"},
+		{"0", "0"},
+		{"B+0", "0"},
+		{"W+0.0", "0"},
+		{"White wins by 0 points", "0"},
+		{"tie", "0"},
+		{"Draw (jigo)", "0"}, // FormatResult's own
+
+		// No result
+		{"Void", "Void"},
+		{"void", "Void"},
+		{"No result.", "Void"},
+
+		// Localized decimal comma
+		{"Black wins by 12,5 points", "B+12.5"},
+		{"W+3,5", "W+3.5"},
+
 		// Edge cases
 		{"White wins by 0.5 points", "W+0.5"},
+		{"White wins by . points", "W+?"},
 		{"Black wins", "B+?"},
 		{"something else", "?"},
 		{"", "?"},
@@ -60,9 +87,60 @@ func TestParseResult(t *testing.T) {
 	}
 }
 
+func TestFormatResult(t *testing.T) {
+	tests := []struct {
+		re   string
+		want string
+	}{
+		{"W+5.5", "White wins by 5.5 points"},
+		{"B+1", "Black wins by 1 point"},
+		{"B+R", "Black wins by resignation"},
+		{"W+T", "White wins on time"},
+		{"B+F", "Black wins by forfeit"},
+		{"W+?", "White wins"},
+		{"0", "Draw (jigo)"},
+		{"Jigo", "Draw (jigo)"},
+		{"Draw", "Draw (jigo)"},
+		{"Void", "No result"},
+		{"?", "Result unknown"},
+		{"", "Result unknown"},
+
+		// Engine phrasing goes through parseResult first
+		{"Black wins by 12,5 points", "Black wins by 12.5 points"},
+		{"White wins by 0 points", "Draw (jigo)"},
+	}
+	for _, tt := range tests {
+		if got := FormatResult(tt.re); got != tt.want {
+			t.Errorf("FormatResult(%q) = %q, want %q", tt.re, got, tt.want)
+		}
+	}
+}
+
+func TestShortResult(t *testing.T) {
+	for _, tt := range []struct {
+		re   string
+		want string
+	}{
+		{"0", "Draw"},
+		{"Jigo", "Draw"},
+		{"Draw", "Draw"},
+		{"W+0.0", "Draw"},
+		{"The game is a draw.", "Draw"},
+		{"Void", "No result"},
+		{"no result", "No result"},
+		{"W+5.5", "W+5.5"},
+		{"Black wins by resignation", "B+R"},
+		{"?", "?"},
+	} {
+		if got := ShortResult(tt.re); got != tt.want {
+			t.Errorf("ShortResult(%q) = %q, want %q", tt.re, got, tt.want)
+		}
+	}
+}
+
 func TestNewGameRecord(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 19, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 19, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -98,7 +176,7 @@ func TestNewGameRecord(t *testing.T) {
 
 func TestNewGameRecordWhitePlayer(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 9, 7.5, 2, 3)
+	rec, err := NewGameRecord(dir, 9, 7.5, 2, 3, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -123,7 +201,7 @@ func TestNewGameRecordWhitePlayer(t *testing.T) {
 
 func TestAddMove(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 19, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 19, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -146,13 +224,13 @@ func TestAddMove(t *testing.T) {
 
 func TestAddMovePass(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
 	defer rec.Close()
 
-	rec.AddMove(4, 4, 1) // B[ee]
+	rec.AddMove(4, 4, 1)   // B[ee]
 	rec.AddMove(-1, -1, 2) // W[] pass
 	rec.AddMove(-1, -1, 1) // B[] pass
 
@@ -176,7 +254,7 @@ func TestAddMovePass(t *testing.T) {
 
 func TestSetResult(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 19, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 19, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -195,7 +273,7 @@ func TestSetResult(t *testing.T) {
 
 func TestAddSetupPosition(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -209,7 +287,7 @@ func TestAddSetupPosition(t *testing.T) {
 	board[2][3] = 1 // black at (3,2) = "dc"
 	board[4][5] = 2 // white at (5,4) = "fe"
 
-	rec.AddSetupPosition(board)
+	rec.AddSetupPosition(board, 1, 2)
 
 	content, _ := os.ReadFile(rec.FilePath)
 	s := string(content)
@@ -220,11 +298,139 @@ func TestAddSetupPosition(t *testing.T) {
 	if !strings.Contains(s, "AW[fe]") {
 		t.Errorf("Missing AW[fe] setup in:\n%s", s)
 	}
+	if !strings.Contains(s, "PL[B]") {
+		t.Errorf("Missing PL[B] setup in:\n%s", s)
+	}
+}
+
+func TestSetupRecordingRoundtrip(t *testing.T) {
+	dir := t.TempDir()
+	// Playing White; recording toggled on after 17 moves, so White is to move.
+	rec, err := NewGameRecord(dir, 9, 6.5, 2, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	board := make([][]int, 9)
+	for i := range board {
+		board[i] = make([]int, 9)
+	}
+	board[2][2] = 1
+	board[6][6] = 2
+	if err := rec.AddSetupPosition(board, 2, 17); err != nil {
+		t.Fatalf("AddSetupPosition: %v", err)
+	}
+	rec.SetComment("undos used: 1")
+	rec.AddMove(4, 4, 2)
+	rec.AddMove(-1, -1, 1)
+	rec.AddMove(3, 3, 2)
+	rec.Close()
+
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.MoveCount != 20 || info.StartMove != 17 {
+		t.Errorf("MoveCount = %d, StartMove = %d, want 20, 17", info.MoveCount, info.StartMove)
+	}
+	if info.NextColor != 1 {
+		t.Errorf("NextColor = %d, want 1 (black after white's move)", info.NextColor)
+	}
+	if info.Comment != "undos used: 1" {
+		t.Errorf("Comment = %q", info.Comment)
+	}
+
+	// Reopen, continue the game and make sure the setup survives the rewrite.
+	reopened, err := OpenGameRecord(rec.FilePath)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	reopened.AddMove(5, 5, 1)
+	reopened.Close()
+
+	content, _ := os.ReadFile(rec.FilePath)
+	s := string(content)
+	for _, want := range []string{"AB[cc]", "AW[gg]", "PL[W]", "recording started after move 17"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q after reopen in:\n%s", want, s)
+		}
+	}
+	info, err = ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.MoveCount != 21 || info.NextColor != 2 {
+		t.Errorf("after continuing: MoveCount = %d, NextColor = %d, want 21, 2", info.MoveCount, info.NextColor)
+	}
+}
+
+func TestSetupRecordingNoMoves(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewGameRecord(dir, 9, 6.5, 2, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	board := make([][]int, 9)
+	for i := range board {
+		board[i] = make([]int, 9)
+	}
+	board[0][0] = 1
+	// Black to play after an odd count (e.g. a pass was undone); parity would say White.
+	rec.AddSetupPosition(board, 1, 17)
+	rec.Close()
+
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.MoveCount != 17 || info.NextColor != 1 {
+		t.Errorf("MoveCount = %d, NextColor = %d, want 17, 1", info.MoveCount, info.NextColor)
+	}
+}
+
+func TestHandicapRoundtrip(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewGameRecord(dir, 9, 0.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.SetHandicap([][2]int{{6, 2}, {2, 6}})
+	rec.Close()
+
+	content, _ := os.ReadFile(rec.FilePath)
+	root := strings.SplitN(string(content), "\n", 2)[0]
+	if !strings.Contains(root, "HA[2]") || !strings.Contains(root, "AB[gc][cg]") {
+		t.Errorf("root node should hold HA and the handicap stones:\n%s", content)
+	}
+
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.Handicap != 2 || info.NextColor != 2 {
+		t.Errorf("Handicap = %d, NextColor = %d, want 2, 2 (White moves first)", info.Handicap, info.NextColor)
+	}
+
+	// Continuing the game keeps the stones in the root, not a setup node.
+	reopened, err := OpenGameRecord(rec.FilePath)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	reopened.AddMove(4, 4, 2)
+	reopened.Close()
+	content, _ = os.ReadFile(rec.FilePath)
+	root = strings.SplitN(string(content), "\n", 2)[0]
+	if !strings.Contains(root, "HA[2]") || !strings.Contains(root, "AB[gc][cg]") || strings.Contains(string(content), ";AB") {
+		t.Errorf("handicap not preserved on reopen:\n%s", content)
+	}
+	blacks, _, _ := ParseSetupPositions(rec.FilePath)
+	if len(blacks) != 2 {
+		t.Errorf("setup blacks = %v, want the 2 handicap stones", blacks)
+	}
 }
 
 func TestFullGameRoundtrip(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -280,7 +486,7 @@ func TestFullGameRoundtrip(t *testing.T) {
 
 func TestFilenameFormat(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 13, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 13, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -297,7 +503,7 @@ func TestFilenameFormat(t *testing.T) {
 
 func TestCloseIdempotent(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -306,9 +512,126 @@ func TestCloseIdempotent(t *testing.T) {
 	rec.Close() // Should not panic
 }
 
+func TestAddComment(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	defer rec.Close()
+
+	rec.AddMove(4, 4, 1) // B[ee]
+	rec.AddMove(2, 2, 2) // W[cc]
+
+	if err := rec.AddComment(1, `W+0.5 [local] c:\go`); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := rec.AddComment(5, "nope"); err == nil {
+		t.Error("AddComment past the last move should fail")
+	}
+
+	content, _ := os.ReadFile(rec.FilePath)
+	want := `;W[cc]C[W+0.5 [local\] c:\\go]`
+	if !strings.Contains(string(content), want) {
+		t.Errorf("SGF missing %s in:\n%s", want, content)
+	}
+
+	// The comment goes away with its move.
+	rec.UndoMoves(1)
+	rec.AddMove(6, 6, 2) // W[gg]
+	content, _ = os.ReadFile(rec.FilePath)
+	if strings.Contains(string(content), "C[") {
+		t.Errorf("comment should be dropped on undo:\n%s", content)
+	}
+
+	// Comments don't disturb move parsing.
+	rec.AddComment(1, "end; (of game)")
+	moves, err := ParseMovesForRecord(rec.FilePath)
+	if err != nil {
+		t.Fatalf("ParseMovesForRecord: %v", err)
+	}
+	if len(moves) != 2 {
+		t.Errorf("got %d moves, want 2: %v", len(moves), moves)
+	}
+}
+
+func TestMoveCommentRoundtrip(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.AddMove(4, 4, 1)
+	rec.AddMove(2, 2, 2)
+	rec.AddMove(6, 6, 1)
+	rec.AddComment(0, `this was the losing move \o/ [sic]`)
+	rec.AddComment(2, "removed")
+	rec.AddComment(2, "")
+	rec.Close()
+
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	want := map[int]string{0: `this was the losing move \o/ [sic]`}
+	if !reflect.DeepEqual(info.MoveComments, want) {
+		t.Errorf("MoveComments = %q, want %q", info.MoveComments, want)
+	}
+
+	// Continuing the game keeps them.
+	rec, err = OpenGameRecord(rec.FilePath)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	rec.AddMove(3, 3, 2)
+	rec.Close()
+	if info, _ = ParseHeader(rec.FilePath); !reflect.DeepEqual(info.MoveComments, want) {
+		t.Errorf("after continuing, MoveComments = %q, want %q", info.MoveComments, want)
+	}
+
+	rep, err := OpenReplayer(rec.FilePath)
+	if err != nil {
+		t.Fatalf("OpenReplayer: %v", err)
+	}
+	if !reflect.DeepEqual(rep.Comments(), want) {
+		t.Errorf("Replayer.Comments() = %q, want %q", rep.Comments(), want)
+	}
+}
+
+func TestRootCommentRoundtrip(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.AddMove(4, 4, 1)
+	rec.SetComment("undos used: 3; [clean]")
+	rec.Close()
+
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.Comment != "undos used: 3; [clean]" {
+		t.Errorf("Comment = %q", info.Comment)
+	}
+	if info.PlayerBlack != "Player" || info.Result != "?" {
+		t.Errorf("root props after comment: PB=%q RE=%q", info.PlayerBlack, info.Result)
+	}
+
+	reopened, err := OpenGameRecord(rec.FilePath)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.Comment != info.Comment {
+		t.Errorf("reopened Comment = %q, want %q", reopened.Comment, info.Comment)
+	}
+}
+
 func TestUndoMoves(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -354,7 +677,7 @@ func TestUndoMoves(t *testing.T) {
 
 func TestUndoMovesAll(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -382,7 +705,7 @@ func TestUndoMovesAll(t *testing.T) {
 
 func TestCrashSafety(t *testing.T) {
 	dir := t.TempDir()
-	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5)
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
 	if err != nil {
 		t.Fatalf("NewGameRecord: %v", err)
 	}
@@ -408,5 +731,423 @@ func TestCrashSafety(t *testing.T) {
 		t.Error("File should contain moves even without Close()")
 	}
 
+	// A write cut short, by a crash or a full disk, leaves the record as
+	// it was before the move
+	orig := writeFile
+	writeFile = func(f *os.File, content string) error {
+		f.WriteString(content[:len(content)/2])
+		return errors.New("short write")
+	}
+	t.Cleanup(func() { writeFile = orig })
+	if err := rec.AddMove(6, 6, 1); err == nil {
+		t.Error("AddMove succeeded despite the short write")
+	}
+	after, _ := os.ReadFile(rec.FilePath)
+	if string(after) != s {
+		t.Errorf("record after a short write:\n%s\nwant it as before:\n%s", after, s)
+	}
+	if err := checkReadable(string(after)); err != nil {
+		t.Errorf("record after a short write reads as %v", err)
+	}
+
+	// The next write brings it up to date
+	writeFile = orig
+	rec.AddMove(2, 6, 2)
+	after, _ = os.ReadFile(rec.FilePath)
+	if !strings.HasSuffix(string(after), ";B[ee];W[cc];B[gg];W[cg])\n") {
+		t.Errorf("record after recovering:\n%s", after)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(rec.FilePath)); len(entries) != 1 {
+		t.Errorf("%d files in the directory, want only the record", len(entries))
+	}
+
+	rec.Close()
+}
+
+func TestRecordReplacedWhole(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewGameRecord(dir, 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	defer rec.Close()
+	rec.AddMove(4, 4, 1)
+	before, _ := os.Stat(rec.FilePath)
+
+	// Every change, a move as much as an undo or a result, writes a new
+	// file in the record's place, leaving nothing beside it
+	rec.AddMove(2, 2, 2)
+	rec.AddComment(1, "hm")
+	rec.AddMove(-1, -1, 1)
+	after, _ := os.Stat(rec.FilePath)
+	if os.SameFile(before, after) {
+		t.Error("a move was written into the old file")
+	}
+	content, _ := os.ReadFile(rec.FilePath)
+	if s := string(content); !strings.HasSuffix(s, ";B[ee];W[cc]C[hm];B[])\n") {
+		t.Errorf("moves not written:\n%s", s)
+	}
+
+	rec.UndoMoves(2)
+	rec.SetResult("B+R")
+	content, _ = os.ReadFile(rec.FilePath)
+	if s := string(content); !strings.Contains(s, "RE[B+R]") || !strings.HasSuffix(s, ";B[ee])\n") {
+		t.Errorf("undo and result not written:\n%s", s)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("%d files in the directory, want only the record", len(entries))
+	}
+}
+
+// A record cut off part way through a move, as a crash could leave one
+// written in place by an older version, is closed by Repair after the
+// move before.
+func TestTornRecordRepairs(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.AddMove(4, 4, 1)
+	rec.AddMove(2, 2, 2)
+	rec.Close()
+	content, _ := os.ReadFile(rec.FilePath)
+	torn := strings.TrimSuffix(string(content), "c])\n") // ;W[c
+
+	if err := checkReadable(torn); !errors.Is(err, ErrTruncated) {
+		t.Errorf("torn record reads as %v, want ErrTruncated", err)
+	}
+	fixed, ok := Repair(torn)
+	if !ok || !strings.HasSuffix(fixed, ";B[ee])\n") {
+		t.Errorf("Repair = %q, %v; want the game up to B[ee]", fixed, ok)
+	}
+}
+
+func TestTimeControlRoundtrip(t *testing.T) {
+	for _, tc := range []struct {
+		byoTime   time.Duration
+		byoStones int
+		want      string
+	}{
+		{30 * time.Second, 1, "TM[600]OT[1x30 byo-yomi]"},
+		{5 * time.Minute, 25, "TM[600]OT[25/300 Canadian]"},
+		{0, 0, "TM[600]"},
+	} {
+		rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+		if err != nil {
+			t.Fatalf("NewGameRecord: %v", err)
+		}
+		rec.SetTimeControl(10*time.Minute, tc.byoTime, tc.byoStones)
+		rec.Close()
+
+		data, _ := os.ReadFile(rec.FilePath)
+		if !strings.Contains(string(data), tc.want) {
+			t.Errorf("record lacks %s:\n%s", tc.want, data)
+		}
+		info, err := ParseHeader(rec.FilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reopened, err := OpenGameRecord(rec.FilePath)
+		if err != nil {
+			t.Fatalf("OpenGameRecord: %v", err)
+		}
+		reopened.Close()
+		if info.TimeLimit != 10*time.Minute || reopened.TimeLimit != info.TimeLimit || reopened.Overtime != info.Overtime {
+			t.Errorf("read back TM %v OT %q, reopened TM %v OT %q", info.TimeLimit, info.Overtime, reopened.TimeLimit, reopened.Overtime)
+		}
+	}
+}
+
+func TestRecordOptions(t *testing.T) {
+	SetAppVersion("v1.2:rc")
+	defer SetAppVersion("dev")
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{
+		Ruleset:       "Chinese",
+		MainTime:      5 * time.Minute,
+		ByoYomiTime:   30 * time.Second,
+		ByoYomiStones: 1,
+		GameName:      "Friday [lunch]",
+		Event:         "Club league",
+	})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.Close()
+
+	data, _ := os.ReadFile(rec.FilePath)
+	for _, want := range []string{`AP[termsuji-local:v1.2\:rc]`, "RU[Chinese]", "TM[300]OT[1x30 byo-yomi]", `GN[Friday [lunch\]]`, "EV[Club league]"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("record lacks %s:\n%s", want, data)
+		}
+	}
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Ruleset != "Chinese" || info.GameName != "Friday [lunch]" || info.Event != "Club league" || info.TimeLimit != 5*time.Minute {
+		t.Errorf("read back %+v", info)
+	}
+
+	// Continuing keeps them, written where the record writes them
+	reopened, err := OpenGameRecord(rec.FilePath)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	reopened.AddMove(4, 4, 1)
+	reopened.Close()
+	again, _ := os.ReadFile(rec.FilePath)
+	if want := strings.Replace(string(data), ")\n", ";B[ee])\n", 1); string(again) != want {
+		t.Errorf("after continuing:\n%s\nwant:\n%s", again, want)
+	}
+
+	// Without options, none of them
+	plain, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	plain.Close()
+	data, _ = os.ReadFile(plain.FilePath)
+	for _, prop := range []string{"RU[", "TM[", "OT[", "GN[", "EV["} {
+		if strings.Contains(string(data), prop) {
+			t.Errorf("record without options has %s:\n%s", prop, data)
+		}
+	}
+}
+
+func TestBatchWritesOnce(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	defer rec.Close()
+	rec.AddMove(4, 4, 1) // B[ee]
+	writes := slowDisk(t, 0)
+
+	rec.Begin()
+	rec.UndoMoves(1)
+	rec.Begin()          // nested
+	rec.AddMove(2, 2, 1) // B[cc]
+	rec.AddMove(6, 6, 2) // W[gg]
+	if err := rec.Commit(); err != nil {
+		t.Fatalf("inner Commit: %v", err)
+	}
+	if n := atomic.LoadInt32(writes); n != 0 {
+		t.Fatalf("%d rewrites before the outer Commit, want 0", n)
+	}
+	content, _ := os.ReadFile(rec.FilePath)
+	if !strings.Contains(string(content), ";B[ee])") {
+		t.Errorf("file changed mid-batch:\n%s", content)
+	}
+
+	if err := rec.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if n := atomic.LoadInt32(writes); n != 1 {
+		t.Errorf("%d rewrites, want 1", n)
+	}
+	content, _ = os.ReadFile(rec.FilePath)
+	if !strings.Contains(string(content), ";B[cc];W[gg])") {
+		t.Errorf("batch not written:\n%s", content)
+	}
+
+	// Nothing changed, nothing written
+	rec.Begin()
+	rec.Commit()
+	if n := atomic.LoadInt32(writes); n != 1 {
+		t.Errorf("empty batch rewrote the file")
+	}
+}
+
+// BenchmarkResume replays a 300-move record over itself, as resuming from
+// planning does, move by move and as one batch.
+func BenchmarkResume(b *testing.B) {
+	const moves = 300
+	resume := func(rec *GameRecord) {
+		rec.UndoMoves(moves)
+		for i := 0; i < moves; i++ {
+			rec.AddMove(i%19, i/19, i%2+1)
+		}
+	}
+	run := func(b *testing.B, batched bool) {
+		rec, err := NewGameRecord(b.TempDir(), 19, 6.5, 1, 5, RecordOptions{})
+		if err != nil {
+			b.Fatalf("NewGameRecord: %v", err)
+		}
+		defer rec.Close()
+		resume(rec)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if batched {
+				rec.Begin()
+			}
+			resume(rec)
+			if batched {
+				rec.Commit()
+			}
+		}
+	}
+	b.Run("per-move", func(b *testing.B) { run(b, false) })
+	b.Run("batched", func(b *testing.B) { run(b, true) })
+}
+
+// BenchmarkAddMove records moves into games of different lengths. Each
+// move writes the record again, but the file is small next to the cost of
+// replacing it, so the cost hardly grows with the game.
+func BenchmarkAddMove(b *testing.B) {
+	for _, length := range []int{10, 300} {
+		b.Run(fmt.Sprintf("%d-moves", length), func(b *testing.B) {
+			rec, err := NewGameRecord(b.TempDir(), 19, 6.5, 1, 5, RecordOptions{})
+			if err != nil {
+				b.Fatalf("NewGameRecord: %v", err)
+			}
+			defer rec.Close()
+			rec.Begin()
+			for i := 0; i < length; i++ {
+				rec.AddMove(i%19, i/19%19, i%2+1)
+			}
+			rec.Commit()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if rec.MoveCount() == length+50 {
+					b.StopTimer()
+					rec.UndoMoves(50)
+					b.StartTimer()
+				}
+				n := rec.MoveCount()
+				rec.AddMove(n%19, n/19%19, n%2+1)
+			}
+		})
+	}
+}
+
+func TestBranchGameRecord(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewGameRecord(dir, 9, 6.5, 2, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.AddMove(4, 4, 1)   // B[ee]
+	rec.AddMove(2, 2, 2)   // W[cc]
+	rec.AddMove(-1, -1, 1) // B[]
+	rec.AddMove(6, 6, 2)   // W[gg]
+	rec.AddComment(1, "kept")
+	rec.AddComment(3, "dropped")
+	rec.SetComment("undos used: 2")
+	rec.SetResult("W+R")
 	rec.Close()
+	original, _ := os.ReadFile(rec.FilePath)
+
+	// Branching on a pass: Black passed, so White is to play
+	branchDir := filepath.Join(dir, "branches")
+	branch, err := BranchGameRecord(rec.FilePath, branchDir, 3)
+	if err != nil {
+		t.Fatalf("BranchGameRecord: %v", err)
+	}
+	branch.Close()
+	if after, _ := os.ReadFile(rec.FilePath); string(after) != string(original) {
+		t.Errorf("the source changed:\n%s", after)
+	}
+	info, err := ParseHeader(branch.FilePath)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.MoveCount != 3 || info.NextColor != 2 || info.Result != "?" {
+		t.Errorf("branch has %d moves, %d to play, result %q; want 3, 2, ?", info.MoveCount, info.NextColor, info.Result)
+	}
+	if info.PlayerWhite != humanName || info.Comment != "" {
+		t.Errorf("branch PW %q, comment %q; want the source's player and no undo count", info.PlayerWhite, info.Comment)
+	}
+	want := "Branched from " + filepath.Base(rec.FilePath) + " at move 3"
+	if info.GameComment != want {
+		t.Errorf("GC %q, want %q", info.GameComment, want)
+	}
+	if info.MoveComments[1] != "kept" || len(info.MoveComments) != 1 {
+		t.Errorf("move comments %v, want only the one before the branch", info.MoveComments)
+	}
+
+	// From the start, and past the end
+	start, err := BranchGameRecord(rec.FilePath, t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("BranchGameRecord at 0: %v", err)
+	}
+	start.Close()
+	if start.MoveCount() != 0 {
+		t.Errorf("branch at 0 has %d moves", start.MoveCount())
+	}
+	if _, err := BranchGameRecord(rec.FilePath, t.TempDir(), 5); err == nil {
+		t.Error("branching past the last move should fail")
+	}
+}
+
+func TestSetPlayerName(t *testing.T) {
+	for _, color := range []int{1, 2} {
+		rec, err := NewGameRecord(t.TempDir(), 9, 6.5, color, 5, RecordOptions{})
+		if err != nil {
+			t.Fatalf("NewGameRecord: %v", err)
+		}
+		// Either order: the opponent doesn't take the human's side
+		rec.SetPlayerName("Ada ]")
+		rec.SetOpponent("KataGo")
+		rec.Close()
+		info, err := ParseHeader(rec.FilePath)
+		if err != nil {
+			t.Fatalf("ParseHeader: %v", err)
+		}
+		human, engine := info.PlayerBlack, info.PlayerWhite
+		if color == 2 {
+			human, engine = engine, human
+		}
+		if human != "Ada ]" || engine != "KataGo" {
+			t.Errorf("color %d: human %q, engine %q", color, human, engine)
+		}
+		if setup := InferSetup(*info, "Ada ]"); setup.PlayerColor != color {
+			t.Errorf("color %d read back as %d", color, setup.PlayerColor)
+		}
+	}
+
+	// No name keeps "Player"
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.SetPlayerName("")
+	rec.Close()
+	if info, _ := ParseHeader(rec.FilePath); info.PlayerBlack != humanName {
+		t.Errorf("PB %q, want %q", info.PlayerBlack, humanName)
+	}
+}
+
+func TestSeedRoundtrip(t *testing.T) {
+	rec, err := NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, RecordOptions{})
+	if err != nil {
+		t.Fatalf("NewGameRecord: %v", err)
+	}
+	rec.SetGameType(Selfplay)
+	rec.SetSeed(42)
+	rec.GameComment = "seed: in the comment too"
+	rec.AddMove(4, 4, 1)
+	rec.Close()
+
+	data, _ := os.ReadFile(rec.FilePath)
+	if !strings.Contains(string(data), "GC[mode: selfplay\nseed: 42\nseed: in the comment too]") {
+		t.Errorf("GC not written as expected:\n%s", data)
+	}
+	info, err := ParseHeader(rec.FilePath)
+	if err != nil {
+		t.Fatalf("ParseHeader: %v", err)
+	}
+	if info.Seed != 42 || info.GameType != Selfplay || info.GameComment != "seed: in the comment too" {
+		t.Errorf("read seed %d, type %q, comment %q", info.Seed, info.GameType, info.GameComment)
+	}
+
+	// Continuing the game keeps the line once
+	reopened, err := OpenGameRecord(rec.FilePath)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	reopened.AddMove(2, 2, 2)
+	reopened.Close()
+	if again, _ := os.ReadFile(rec.FilePath); strings.Count(string(again), "seed: 42") != 1 {
+		t.Errorf("seed line repeated:\n%s", again)
+	}
 }
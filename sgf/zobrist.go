@@ -0,0 +1,55 @@
+package sgf
+
+import "sync"
+
+// Zobrist hashing gives each whole-board position a 64-bit key, so a
+// repeated position can be spotted without keeping the boards: every point
+// has a random key per color, and a position's hash is the XOR of the keys
+// of its stones. The keys come from a fixed seed per board size, so hashes
+// agree from one run to the next.
+
+var (
+	zobristMu     sync.Mutex
+	zobristTables = map[int][][2]uint64{}
+)
+
+// zobristTable returns the keys for a board of size, a black and a white
+// one for each point in row order.
+func zobristTable(size int) [][2]uint64 {
+	zobristMu.Lock()
+	defer zobristMu.Unlock()
+	if keys, ok := zobristTables[size]; ok {
+		return keys
+	}
+	keys := make([][2]uint64, size*size)
+	state := uint64(size)
+	for i := range keys {
+		keys[i] = [2]uint64{splitmix64(&state), splitmix64(&state)}
+	}
+	zobristTables[size] = keys
+	return keys
+}
+
+// splitmix64 steps state and returns the next number in its sequence.
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// ZobristHash returns the hash of the position on board. The empty board
+// hashes to 0; who is to move doesn't count.
+func ZobristHash(board [][]int, size int) uint64 {
+	keys := zobristTable(size)
+	var h uint64
+	for y := 0; y < size && y < len(board); y++ {
+		for x := 0; x < size && x < len(board[y]); x++ {
+			if c := board[y][x]; c == 1 || c == 2 {
+				h ^= keys[y*size+x][c-1]
+			}
+		}
+	}
+	return h
+}
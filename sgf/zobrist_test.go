@@ -0,0 +1,75 @@
+package sgf
+
+import "testing"
+
+func TestZobristHash(t *testing.T) {
+	board := MakeBoard(9)
+	if h := ZobristHash(board, 9); h != 0 {
+		t.Errorf("empty board hashes to %x", h)
+	}
+
+	board[2][3] = 1
+	black := ZobristHash(board, 9)
+	board[2][3] = 2
+	white := ZobristHash(board, 9)
+	if black == 0 || black == white {
+		t.Errorf("black stone %x, white stone %x; want distinct and non-zero", black, white)
+	}
+
+	// The same position hashes the same however it came about, and the
+	// table is fixed per size.
+	other := MakeBoard(9)
+	other[2][3] = 2
+	if ZobristHash(other, 9) != white {
+		t.Error("equal boards hash differently")
+	}
+	if ZobristHash(MakeBoard(13), 13) != 0 || ZobristHash(boardFromRows("X.", ".."), 2) == ZobristHash(boardFromRows(".X", ".."), 2) {
+		t.Error("points share a key")
+	}
+}
+
+// tripleKo is a 9x9 board with three kos: on the top and bottom edges
+// Black can take at (3,0) and (3,8), and on the left edge White can take
+// at (0,4).
+func tripleKo() [][]int {
+	return boardFromRows(
+		".XO.O....",
+		"..XO.....",
+		".........",
+		"X........",
+		".X.......",
+		"XO.......",
+		"O........",
+		"..XO.....",
+		".XO.O....",
+	)
+}
+
+func TestZobristTripleKo(t *testing.T) {
+	board := tripleKo()
+	start := ZobristHash(board, 9)
+	seen := map[uint64]int{start: 0}
+
+	// Each side takes a ko in turn, never the one just taken. After six
+	// captures every ko is back where it started.
+	moves := [][3]int{{1, 3, 0}, {2, 0, 4}, {1, 3, 8}, {2, 2, 0}, {1, 0, 5}, {2, 2, 8}}
+	for i, m := range moves {
+		color, x, y := m[0], m[1], m[2]
+		if board[y][x] != 0 {
+			t.Fatalf("move %d at (%d,%d) is on a stone", i+1, x, y)
+		}
+		board[y][x] = color
+		if n := RemoveCaptures(board, 9, x, y, color); n != 1 {
+			t.Fatalf("move %d took %d stones, want 1", i+1, n)
+		}
+		h := ZobristHash(board, 9)
+		if prev, ok := seen[h]; ok {
+			if i < len(moves)-1 || prev != 0 {
+				t.Fatalf("move %d repeats the position after move %d", i+1, prev)
+			}
+			return
+		}
+		seen[h] = i + 1
+	}
+	t.Error("six captures didn't come back to the start")
+}
@@ -0,0 +1,148 @@
+// Package training scores guess-the-move practice: the player guesses the
+// next move of a recorded game and is shown how the guess compares with the
+// game move and, when analysis is available, the engine's preferred moves.
+package training
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"termsuji-local/coords"
+)
+
+// Credit awarded for a guess.
+const (
+	FullCredit     = 1.0
+	NearMissCredit = 0.5 // the guess touches the game move
+)
+
+// MaxAlternatives is how many of the engine's candidates a review shows.
+const MaxAlternatives = 3
+
+// Point is a board position; (-1, -1) is a pass.
+type Point struct{ X, Y int }
+
+// Pass is the Point for a pass.
+var Pass = Point{-1, -1}
+
+// IsPass returns true for a pass.
+func (p Point) IsPass() bool {
+	return p.X < 0 || p.Y < 0
+}
+
+// Candidate is a move the analysing engine considered, with its score
+// estimate in points from the mover's point of view.
+type Candidate struct {
+	Point
+	Score float64
+}
+
+// MarkKind distinguishes the overlay marks drawn after a guess.
+type MarkKind int
+
+const (
+	MarkGuess       MarkKind = iota // where the player guessed
+	MarkGameMove                    // what was actually played
+	MarkEngine                      // the engine's first choice
+	MarkAlternative                 // another move the engine considered
+)
+
+// Mark is one overlay mark for the review. A point can carry several.
+type Mark struct {
+	Point
+	Kind MarkKind
+}
+
+// Review is what to show after a guess.
+type Review struct {
+	Credit      float64
+	Marks       []Mark
+	Explanation string // one line for the hint bar
+}
+
+// ScoreGuess returns the credit for guessing guess when actual was played:
+// full credit for the game move, partial credit for a point touching it
+// (including diagonally), none otherwise. A pass only matches a pass.
+func ScoreGuess(guess, actual Point) float64 {
+	if guess == actual {
+		return FullCredit
+	}
+	if guess.IsPass() || actual.IsPass() {
+		return 0
+	}
+	if abs(guess.X-actual.X) <= 1 && abs(guess.Y-actual.Y) <= 1 {
+		return NearMissCredit
+	}
+	return 0
+}
+
+// ReviewGuess scores a guess and builds the marks and explanation for it.
+// candidates may be nil when analysis is disabled, in which case only the
+// guess and the game move are shown; otherwise the best MaxAlternatives of
+// them are marked and the explanation gives score deltas against the
+// engine's choice.
+func ReviewGuess(guess, actual Point, candidates []Candidate, size int) Review {
+	r := Review{Credit: ScoreGuess(guess, actual)}
+	r.Marks = append(r.Marks, Mark{actual, MarkGameMove})
+	if guess != actual {
+		r.Marks = append(r.Marks, Mark{guess, MarkGuess})
+	}
+
+	var verdict string
+	switch r.Credit {
+	case FullCredit:
+		verdict = "Correct"
+	case NearMissCredit:
+		verdict = "Near miss (half credit)"
+	default:
+		verdict = "Missed"
+	}
+	parts := []string{fmt.Sprintf("%s: game move %s", verdict, coords.Display(actual.X, actual.Y, size))}
+
+	top := append([]Candidate(nil), candidates...)
+	sort.SliceStable(top, func(i, j int) bool { return top[i].Score > top[j].Score })
+	if len(top) > MaxAlternatives {
+		top = top[:MaxAlternatives]
+	}
+	if len(top) == 0 {
+		if guess != actual {
+			parts = append(parts, "you played "+coords.Display(guess.X, guess.Y, size))
+		}
+		r.Explanation = strings.Join(parts, ", ")
+		return r
+	}
+
+	best := top[0]
+	for i, c := range top {
+		kind := MarkAlternative
+		if i == 0 {
+			kind = MarkEngine
+		}
+		r.Marks = append(r.Marks, Mark{c.Point, kind})
+	}
+
+	// Deltas are only known for moves the engine looked at.
+	delta := func(p Point) string {
+		for _, c := range candidates {
+			if c.Point == p {
+				return fmt.Sprintf(" (%+.1f)", c.Score-best.Score)
+			}
+		}
+		return ""
+	}
+	parts[0] += delta(actual)
+	if guess != actual {
+		parts = append(parts, "you "+coords.Display(guess.X, guess.Y, size)+delta(guess))
+	}
+	parts = append(parts, "engine prefers "+coords.Display(best.X, best.Y, size))
+	r.Explanation = strings.Join(parts, ", ")
+	return r
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
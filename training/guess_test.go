@@ -0,0 +1,86 @@
+package training
+
+import "testing"
+
+func TestScoreGuess(t *testing.T) {
+	center := Point{4, 4}
+	tests := []struct {
+		name          string
+		guess, actual Point
+		want          float64
+	}{
+		{"exact", center, center, FullCredit},
+		{"left", Point{3, 4}, center, NearMissCredit},
+		{"below", Point{4, 5}, center, NearMissCredit},
+		{"diagonal", Point{5, 3}, center, NearMissCredit},
+		{"two away", Point{6, 4}, center, 0},
+		{"knight's move", Point{5, 6}, center, 0},
+		{"pass for pass", Pass, Pass, FullCredit},
+		{"pass for move", Pass, center, 0},
+		{"move for pass", Point{0, 0}, Pass, 0},
+	}
+	for _, tt := range tests {
+		if got := ScoreGuess(tt.guess, tt.actual); got != tt.want {
+			t.Errorf("%s: ScoreGuess(%v, %v) = %v, want %v", tt.name, tt.guess, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func countMarks(r Review, kind MarkKind) int {
+	n := 0
+	for _, m := range r.Marks {
+		if m.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestReviewGuessWithAnalysis(t *testing.T) {
+	// 9x9: (2,6) is C3, (6,2) is G7, (2,2) is C7, (4,4) is E5, (3,6) is D3.
+	candidates := []Candidate{
+		{Point{2, 2}, 1.0},
+		{Point{6, 2}, 3.5},
+		{Point{2, 6}, 2.0},
+		{Point{4, 4}, -4.0},
+	}
+	r := ReviewGuess(Point{3, 6}, Point{2, 6}, candidates, 9)
+
+	if r.Credit != NearMissCredit {
+		t.Errorf("Credit = %v, want near miss", r.Credit)
+	}
+	if countMarks(r, MarkGameMove) != 1 || countMarks(r, MarkGuess) != 1 ||
+		countMarks(r, MarkEngine) != 1 || countMarks(r, MarkAlternative) != 2 {
+		t.Errorf("marks = %+v, want game move, guess, engine choice and 2 alternatives", r.Marks)
+	}
+	for _, m := range r.Marks {
+		if m.Kind == MarkEngine && m.Point != (Point{6, 2}) {
+			t.Errorf("engine mark at %v, want the best candidate G7", m.Point)
+		}
+		if m.Point == (Point{4, 4}) {
+			t.Error("only the top 3 candidates should be marked")
+		}
+	}
+	want := "Near miss (half credit): game move C3 (-1.5), you D3, engine prefers G7"
+	if r.Explanation != want {
+		t.Errorf("Explanation = %q, want %q", r.Explanation, want)
+	}
+}
+
+func TestReviewGuessWithoutAnalysis(t *testing.T) {
+	r := ReviewGuess(Point{0, 0}, Point{4, 4}, nil, 9)
+	if r.Credit != 0 {
+		t.Errorf("Credit = %v, want 0", r.Credit)
+	}
+	if len(r.Marks) != 2 || countMarks(r, MarkGameMove) != 1 || countMarks(r, MarkGuess) != 1 {
+		t.Errorf("marks = %+v, want only the game move and the guess", r.Marks)
+	}
+	if want := "Missed: game move E5, you played A9"; r.Explanation != want {
+		t.Errorf("Explanation = %q, want %q", r.Explanation, want)
+	}
+
+	r = ReviewGuess(Point{4, 4}, Point{4, 4}, nil, 9)
+	if r.Credit != FullCredit || len(r.Marks) != 1 || r.Explanation != "Correct: game move E5" {
+		t.Errorf("correct guess review = %+v", r)
+	}
+}
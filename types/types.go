@@ -15,6 +15,15 @@ type BoardState struct {
 		X int `json:"x"`
 		Y int `json:"y"`
 	} `json:"last_move"`
+	BookMove    bool   `json:"book_move"`    // the last move came from an opening book, not the engine
+	LevelChange int    `json:"level_change"` // the engine's new level when its last move changed it, else 0
+	Hash        uint64 `json:"hash"`         // Zobrist hash of Board, for spotting a repeated position
+
+	// Filled in when the game ends by counting (after two passes).
+	Scored        bool     `json:"scored"`
+	CapturesBlack int      `json:"captures_black"` // white stones captured by Black
+	CapturesWhite int      `json:"captures_white"` // black stones captured by White
+	DeadStones    [][2]int `json:"dead_stones"`    // [x, y] of stones marked dead
 }
 
 // Finished returns true if the game is over.
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// logTailLines is how much of the debug log the About page shows.
+const logTailLines = 200
+
+// AboutInfo is what the About page shows. The caller probes it at the
+// time the page is opened.
+type AboutInfo struct {
+	Version   string
+	Commit    string
+	BuildDate string
+	GoVersion string
+	Platform  string // GOOS/GOARCH
+
+	ConfigPath   string
+	ConfigExists bool
+	StatePath    string
+	HistoryDir   string
+	HistoryGames int
+	HistoryErr   error
+
+	EnginePath    string // resolved binary, empty if not found
+	EngineVersion string
+	EngineErr     error
+
+	DebugLog  string
+	Colors    int // colors the screen reports
+	TrueColor bool
+	Mouse     bool
+}
+
+// AboutUI shows build info, paths and diagnostics, and can switch to the
+// tail of the debug log.
+type AboutUI struct {
+	flex    *tview.Flex
+	text    *tview.TextView
+	hint    *tview.TextView
+	info    AboutInfo
+	showLog bool
+	onClose func()
+}
+
+// NewAbout creates the About page.
+func NewAbout(onClose func()) *AboutUI {
+	a := &AboutUI{onClose: onClose}
+
+	a.text = tview.NewTextView()
+	a.text.SetDynamicColors(true)
+	a.text.SetWrap(true)
+	a.text.SetBorder(true)
+	a.text.SetBorderPadding(0, 0, 1, 1)
+	a.text.SetInputCapture(a.handleInput)
+
+	a.hint = tview.NewTextView()
+	a.hint.SetDynamicColors(true)
+	a.hint.SetBorder(false)
+
+	a.flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.text, 0, 1, true).
+		AddItem(a.hint, 1, 0, false)
+	return a
+}
+
+// Flex returns the flex container for this UI.
+func (a *AboutUI) Flex() *tview.Flex {
+	return a.flex
+}
+
+// SetInfo shows info, starting on the About view.
+func (a *AboutUI) SetInfo(info AboutInfo) {
+	a.info = info
+	a.showLog = false
+	a.render()
+}
+
+func (a *AboutUI) render() {
+	if a.showLog {
+		a.text.SetTitle(" Debug log ")
+		a.text.SetText(tview.Escape(tailFile(a.info.DebugLog, logTailLines)))
+		a.text.ScrollToEnd()
+		a.hint.SetText("  " + keyHints("jk", "scroll", "l", "about", "q", "back"))
+		return
+	}
+	a.text.SetTitle(" About ")
+	a.text.SetText(renderAbout(a.info))
+	a.text.ScrollToBeginning()
+	a.hint.SetText("  " + keyHints("jk", "scroll", "l", "debug log", "q", "back"))
+}
+
+func (a *AboutUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEsc:
+		a.onClose()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q':
+			a.onClose()
+			return nil
+		case 'l':
+			a.showLog = !a.showLog
+			a.render()
+			return nil
+		}
+	}
+	return event
+}
+
+// renderAbout lays out info as label/value lines grouped in sections.
+func renderAbout(info AboutInfo) string {
+	var b strings.Builder
+	section := func(title string) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(tag("::b", title) + "\n")
+	}
+	line := func(label, value string) {
+		fmt.Fprintf(&b, "  %s %s\n", tag("dimgray", fmt.Sprintf("%-10s", label)), tview.Escape(value))
+	}
+	orNone := func(s string) string {
+		if s == "" {
+			return "unknown"
+		}
+		return s
+	}
+
+	section("termsuji-local")
+	line("version", info.Version)
+	line("commit", orNone(info.Commit))
+	line("built", orNone(info.BuildDate))
+	line("go", info.GoVersion+" "+info.Platform)
+
+	section("Files")
+	config := info.ConfigPath
+	if !info.ConfigExists {
+		config += " (not created yet, using defaults)"
+	}
+	line("config", config)
+	line("state", info.StatePath)
+	history := fmt.Sprintf("%s (%d games)", info.HistoryDir, info.HistoryGames)
+	if info.HistoryErr != nil {
+		history = fmt.Sprintf("%s (%s)", info.HistoryDir, info.HistoryErr)
+	}
+	line("history", history)
+	line("debug log", info.DebugLog)
+
+	section("Engine")
+	if info.EnginePath == "" {
+		line("path", "not found")
+	} else {
+		line("path", info.EnginePath)
+	}
+	if info.EngineErr != nil {
+		line("version", "error: "+info.EngineErr.Error())
+	} else {
+		line("version", orNone(info.EngineVersion))
+	}
+
+	section("Terminal")
+	colors := fmt.Sprintf("%d", info.Colors)
+	if info.TrueColor {
+		colors += " (truecolor)"
+	}
+	line("colors", colors)
+	line("mouse", map[bool]string{true: "on", false: "off"}[info.Mouse])
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// tailFile returns the last n lines of the file at path, or a note saying
+// why it can't.
+func tailFile(path string, n int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("(%s)", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return "(empty)"
+	}
+	return strings.Join(lines, "\n")
+}
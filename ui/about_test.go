@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderAbout(t *testing.T) {
+	info := AboutInfo{
+		Version:       "v1.4.0",
+		Commit:        "abc1234",
+		GoVersion:     "go1.22.1",
+		Platform:      "linux/amd64",
+		ConfigPath:    "/home/u/.config/termsuji-local/config.json",
+		HistoryDir:    "/home/u/.config/termsuji-local/history",
+		HistoryGames:  12,
+		EnginePath:    "/usr/bin/gnugo",
+		EngineVersion: "GNU Go 3.8",
+		Colors:        1 << 24,
+		TrueColor:     true,
+	}
+	out := renderAbout(info)
+	for _, want := range []string{
+		"v1.4.0", "abc1234", "go1.22.1 linux/amd64",
+		"config.json (not created yet, using defaults)",
+		"history (12 games)", "/usr/bin/gnugo", "GNU Go 3.8",
+		"16777216 (truecolor)", "off",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("about page is missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, "built") || !strings.Contains(out, "unknown") {
+		t.Errorf("missing build date should read unknown:\n%s", out)
+	}
+
+	info.EnginePath = ""
+	info.EngineErr = errors.New("exec: not found")
+	out = renderAbout(info)
+	if !strings.Contains(out, "not found") || !strings.Contains(out, "error: exec: not found") {
+		t.Errorf("missing engine not reported:\n%s", out)
+	}
+}
+
+func TestTailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.log")
+	var lines []string
+	for i := 1; i <= 5; i++ {
+		lines = append(lines, strings.Repeat("x", i))
+	}
+	os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+
+	if got := tailFile(path, 2); got != "xxxx\nxxxxx" {
+		t.Errorf("tailFile(2) = %q", got)
+	}
+	if got := tailFile(path, 10); got != strings.Join(lines, "\n") {
+		t.Errorf("tailFile(10) = %q", got)
+	}
+	if got := tailFile(filepath.Join(t.TempDir(), "missing.log"), 2); !strings.HasPrefix(got, "(") {
+		t.Errorf("missing file = %q, want a note", got)
+	}
+}
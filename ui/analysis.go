@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"errors"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+)
+
+// Analysis asks the engine for its best moves on the player's turn and
+// shades them on the board, brightest for the best, until a move is
+// played.
+
+// maxCandidates is how many of the engine's moves are shown.
+const maxCandidates = 5
+
+// analysisStyle is the index of the best candidate's shade in styles; the
+// others follow it in order.
+const analysisStyle = 14
+
+// analysisShades returns maxCandidates backgrounds fading from best
+// towards board.
+func analysisShades(best, board tcell.Color) []tcell.Color {
+	br, bg, bb := best.RGB()
+	wr, wg, wb := board.RGB()
+	shades := make([]tcell.Color, maxCandidates)
+	for i := range shades {
+		f := float64(i) / float64(maxCandidates)
+		mix := func(from, to int32) int32 { return from + int32(f*float64(to-from)) }
+		shades[i] = tcell.NewRGBColor(mix(br, wr), mix(bg, wg), mix(bb, wb))
+	}
+	return shades
+}
+
+// CanAnalyze returns true if the engine can be asked for candidates now.
+func (g *GoBoardUI) CanAnalyze() bool {
+	return g.eng != nil && !g.finished && !g.scoring && !g.planningMode && !g.IsLoading() && g.eng.IsMyTurn()
+}
+
+// ToggleAnalysis asks the engine for its candidates, or hides them if
+// they are showing. The engine may take a while, so it is asked off the
+// UI goroutine; an answer for a position that has since changed is
+// dropped.
+func (g *GoBoardUI) ToggleAnalysis() {
+	if g.analysis != nil {
+		g.clearAnalysis()
+		g.refreshHint()
+		return
+	}
+	if g.analyzing || !g.CanAnalyze() {
+		return
+	}
+	g.analyzing = true
+	g.refreshHint()
+	eng, asked := g.eng, g.BoardState
+	go func() {
+		moves, err := eng.TopMoves()
+		g.app.QueueUpdateDraw(func() {
+			g.analyzing = false
+			if g.BoardState != asked || g.eng != eng || errors.Is(err, engine.ErrClosed) {
+				return
+			}
+			if err != nil {
+				g.notice = "no analysis: " + err.Error()
+			} else {
+				g.setAnalysis(moves)
+			}
+			g.refreshHint()
+		})
+	}()
+}
+
+// setAnalysis shows the first maxCandidates of moves.
+func (g *GoBoardUI) setAnalysis(moves []engine.Candidate) {
+	if len(moves) > maxCandidates {
+		moves = moves[:maxCandidates]
+	}
+	g.analysis = append([]engine.Candidate{}, moves...)
+	g.analyzed = true
+	if g.infoPanel != nil {
+		g.infoPanel.SetCandidates(g.analysis)
+	}
+}
+
+// clearAnalysis hides the candidates, once they no longer fit the board.
+func (g *GoBoardUI) clearAnalysis() {
+	g.analysis = nil
+	if g.infoPanel != nil {
+		g.infoPanel.SetCandidates(nil)
+	}
+}
+
+// analysisRank returns where x, y stands among the candidates on show,
+// 0 for the best, or -1 if it is not one of them.
+func (g *GoBoardUI) analysisRank(x, y int) int {
+	if g.planningMode {
+		return -1
+	}
+	for i, c := range g.analysis {
+		if c.X == x && c.Y == y {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+	"termsuji-local/types"
+)
+
+func TestAnalysisShades(t *testing.T) {
+	best, board := tcell.PaletteColor(208), tcell.PaletteColor(180)
+	shades := analysisShades(best, board)
+	if len(shades) != maxCandidates {
+		t.Fatalf("%d shades, want %d", len(shades), maxCandidates)
+	}
+	br, bg, bb := best.RGB()
+	if r, g, b := shades[0].RGB(); r != br || g != bg || b != bb {
+		t.Errorf("best shade = %d,%d,%d, want the analysis color %d,%d,%d", r, g, b, br, bg, bb)
+	}
+	if shades[maxCandidates-1] == shades[0] || shades[maxCandidates-1] == board {
+		t.Error("the weakest shade should sit between the analysis and board colors")
+	}
+}
+
+func TestAnalysisOverlay(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	if !g.CanAnalyze() {
+		t.Fatal("analysis should be available on the player's turn")
+	}
+
+	moves := make([]engine.Candidate, 7)
+	for i := range moves {
+		moves[i] = engine.Candidate{X: i, Y: 2, Value: float64(10 - i)}
+	}
+	g.setAnalysis(moves)
+	if len(g.analysis) != maxCandidates {
+		t.Errorf("%d candidates on show, want %d", len(g.analysis), maxCandidates)
+	}
+	if r := g.analysisRank(0, 2); r != 0 {
+		t.Errorf("rank of the best move = %d", r)
+	}
+	if r := g.analysisRank(4, 2); r != 4 {
+		t.Errorf("rank of the fifth move = %d", r)
+	}
+	if r := g.analysisRank(5, 2); r != -1 {
+		t.Errorf("rank of a move past the top %d = %d", maxCandidates, r)
+	}
+
+	// Any move played makes the candidates stale.
+	bs := types.NewBoardState(9)
+	bs.Board[2][0] = 1
+	eng.onMove(0, 2, 1, bs)
+	if g.analysis != nil {
+		t.Errorf("candidates left after a move: %v", g.analysis)
+	}
+
+	// Toggling hides them without asking the engine again.
+	g.setAnalysis(moves[:2])
+	g.ToggleAnalysis()
+	if g.analysis != nil || g.analyzing {
+		t.Errorf("after hiding: candidates %v, analyzing %v", g.analysis, g.analyzing)
+	}
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+)
+
+// asciiOnly is true when the UI must be drawn with ASCII alone, for
+// terminals and fonts that garble box drawing and symbols. A board whose
+// theme is AsciiOnly draws that way too.
+var asciiOnly bool
+
+// SetASCIIOnly switches the board and menus to ASCII stand-ins for their
+// lines, stones, bullets and arrows. Like SetNoColor, it is meant to be
+// called once at startup.
+func SetASCIIOnly(enabled bool) {
+	asciiOnly = enabled
+	setBorders()
+}
+
+// IsASCIIOnly returns true if the board and menus draw with ASCII alone.
+func IsASCIIOnly() bool {
+	return asciiOnly
+}
+
+// menuGlyphs overrides asciiOnly for the menus' glyphs alone; auto, or "",
+// follows it.
+var menuGlyphs config.MenuGlyphs
+
+// SetMenuGlyphs picks the menus' glyphs apart from the board's: the
+// Unicode ones, their ASCII stand-ins, or auto to draw them as the board
+// is drawn. Like SetASCIIOnly, it is meant to be called once at startup.
+func SetMenuGlyphs(g config.MenuGlyphs) {
+	menuGlyphs = g
+	setBorders()
+}
+
+// Glyphs returns s as the menus draw it, for text drawn outside this
+// package such as a page title.
+func Glyphs(s string) string {
+	return glyphs(s)
+}
+
+// defaultBorders are tview's box borders, restored when the menus go back
+// to their glyphs.
+var defaultBorders = tview.Borders
+
+// setBorders has tview draw the borders of its boxes in ASCII when the
+// menus are, as MenuCard draws its own.
+func setBorders() {
+	tview.Borders = defaultBorders
+	if !menuASCII() {
+		return
+	}
+	b := &tview.Borders
+	b.Horizontal, b.Vertical = '-', '|'
+	b.HorizontalFocus, b.VerticalFocus = '=', '|'
+	for _, r := range []*rune{&b.TopLeft, &b.TopRight, &b.BottomLeft, &b.BottomRight,
+		&b.LeftT, &b.RightT, &b.TopT, &b.BottomT, &b.Cross,
+		&b.TopLeftFocus, &b.TopRightFocus, &b.BottomLeftFocus, &b.BottomRightFocus} {
+		*r = '+'
+	}
+}
+
+// menuASCII reports whether the menus draw ASCII stand-ins for their
+// glyphs.
+func menuASCII() bool {
+	switch menuGlyphs {
+	case config.MenuGlyphsASCII:
+		return true
+	case config.MenuGlyphsUnicode:
+		return false
+	}
+	return asciiOnly
+}
+
+// asciiGlyphs are the stand-ins for the glyphs the board and menus draw.
+// Each takes one cell, as the glyph it replaces does, so layouts hold.
+var asciiGlyphs = map[rune]rune{
+	'┌': '+', '┐': '+', '└': '+', '┘': '+',
+	'┬': '+', '┴': '+', '├': '+', '┤': '+', '┼': '+',
+	'╭': '+', '╮': '+', '╰': '+', '╯': '+',
+	'─': '-', '│': '|',
+	'◦': '*', // hoshi
+	'▫': 'o', // ko a plan can't retake yet
+	'×': 'x', // Black's point, without color
+	'·': '.', // White's point, without color
+	'⬡': '#', '◈': '*', '○': 'o', '●': '*',
+	'◀': '<', '▶': '>', '▸': '>', '▾': 'v', '░': '-', '█': '#', '…': '~',
+	'—': '-', '↑': '^', '↓': 'v', '←': '<', '→': '>',
+	'✎': '~', // a move with a comment
+	'◌': 'o', // the engine thinking
+	'⏎': '<', '•': '*',
+}
+
+// asciiRune returns the ASCII stand-in for r, or r when it has none.
+func asciiRune(r rune) rune {
+	if a, ok := asciiGlyphs[r]; ok {
+		return a
+	}
+	return r
+}
+
+// glyph returns r for the menus, or its stand-in when the menus are
+// drawn in ASCII.
+func glyph(r rune) rune {
+	if menuASCII() {
+		return asciiRune(r)
+	}
+	return r
+}
+
+// glyphs is glyph over each rune of s, for text such as key hints.
+func glyphs(s string) string {
+	if !menuASCII() {
+		return s
+	}
+	return asciiText(s)
+}
+
+// textWidth is how many cells s takes on screen: two for a wide rune such
+// as 囲, so layouts measured by it line up whatever the glyphs.
+func textWidth(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// drawRunes writes s from (x, y), a wide rune taking two cells, and
+// returns the column after it.
+func drawRunes(screen tcell.Screen, x, y int, s string, style tcell.Style) int {
+	for _, r := range s {
+		screen.SetContent(x, y, r, nil, style)
+		x += runewidth.RuneWidth(r)
+	}
+	return x
+}
+
+// asciiText is s with its glyphs swapped for stand-ins. Text isn't laid
+// out cell by cell, so ⏎ gets spelled out.
+func asciiText(s string) string {
+	return strings.Map(asciiRune, strings.ReplaceAll(s, "⏎", "enter"))
+}
+
+// ascii reports whether the board is drawn in ASCII, by its theme or
+// SetASCIIOnly.
+func (g *GoBoardUI) ascii() bool {
+	return g.cfg.Theme.AsciiOnly || asciiOnly
+}
+
+// symbols returns the board's stone and marker glyphs: the theme's, or
+// ASCII ones when the board is drawn in ASCII.
+func (g *GoBoardUI) symbols() config.ConfigSymbols {
+	if g.ascii() {
+		return config.ASCIISymbols
+	}
+	return g.cfg.Theme.Symbols
+}
@@ -0,0 +1,304 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+	"termsuji-local/ui/render"
+)
+
+// assertASCII fails if any cell on the screen holds a non-ASCII rune.
+func assertASCII(t *testing.T, screen tcell.SimulationScreen) {
+	t.Helper()
+	w, h := screen.Size()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if r, _, _, _ := screen.GetContent(x, y); r > 127 {
+				t.Fatalf("cell (%d,%d) is %q, want ASCII", x, y, r)
+			}
+		}
+	}
+}
+
+// drawTestBoard draws a 9x9 board with a stone of each color in the given
+// theme, returning the screen.
+func drawTestBoard(t *testing.T, theme config.Theme) tcell.SimulationScreen {
+	t.Helper()
+	cfg := config.DefaultConfig
+	cfg.Theme = theme
+	board := NewGoBoard(tview.NewApplication(), &cfg, tview.NewTextView())
+	board.BoardState = types.NewBoardState(9)
+	board.BoardState.Board[4][4] = 1
+	board.BoardState.Board[2][2] = 2
+	board.BoardState.LastMove.X, board.BoardState.LastMove.Y = 4, 4
+
+	screen := newTestScreen(t, 80, 12)
+	t.Cleanup(screen.Fini)
+	board.Box.SetRect(0, 0, 80, 12)
+	board.Box.Draw(screen)
+	return screen
+}
+
+func TestASCIIBoard(t *testing.T) {
+	theme := config.DefaultTheme
+	theme.AsciiOnly = true
+	screen := drawTestBoard(t, theme)
+	assertASCII(t, screen)
+
+	// Same cells as the Unicode board, with stand-ins in them
+	unicode := drawTestBoard(t, config.DefaultTheme)
+	for _, c := range []struct {
+		x, y      int
+		want, was rune
+	}{
+		{4 + 4*2, 4, '#', '●'},
+		{4 + 2*2, 2, 'O', '●'},
+		{4 + 6*2, 6, '*', '◦'},
+		{4 + 0*2, 0, '+', '┌'},
+		{4 + 0*2 + 1, 0, '-', '─'},
+	} {
+		if r, _, _, _ := screen.GetContent(c.x, c.y); r != c.want {
+			t.Errorf("cell (%d,%d) = %q, want %q", c.x, c.y, r, c.want)
+		}
+		if r, _, _, _ := unicode.GetContent(c.x, c.y); r != c.was {
+			t.Errorf("Unicode cell (%d,%d) = %q, want %q", c.x, c.y, r, c.was)
+		}
+	}
+}
+
+func TestASCIIOnlyFollowsSetting(t *testing.T) {
+	SetASCIIOnly(true)
+	defer SetASCIIOnly(false)
+	// The theme needn't ask for it once SetASCIIOnly has
+	assertASCII(t, drawTestBoard(t, config.DefaultTheme))
+}
+
+func TestASCIISetup(t *testing.T) {
+	SetASCIIOnly(true)
+	defer SetASCIIOnly(false)
+
+	setup := NewGameSetup(func(engine.GameConfig) {}, func() {}, nil, nil)
+	setup.SetLastGame("last: 9x9 L5 — B+3.5, 20m ago", sgf.OutcomeWin, func() {})
+	setup.SetNotice("drawing in ASCII")
+
+	screen := newTestScreen(t, 80, 40)
+	defer screen.Fini()
+	setup.Form().SetRect(0, 0, 80, 40)
+	setup.Form().Draw(screen)
+	assertASCII(t, screen)
+}
+
+func TestASCIIText(t *testing.T) {
+	got := asciiText("⏎ play · ↑↓ scroll — 9×9…")
+	if want := "enter play . ^v scroll - 9x9~"; got != want {
+		t.Errorf("asciiText = %q, want %q", got, want)
+	}
+}
+
+// TestBoardMatchesRender holds the board widget to the text render, so
+// the two can't drift apart.
+func TestBoardMatchesRender(t *testing.T) {
+	classic, _ := config.FindPreset("classic")
+	for _, theme := range []config.Theme{config.DefaultTheme, classic.Dark} {
+		for _, ascii := range []bool{false, true} {
+			theme.AsciiOnly = ascii
+			screen := drawTestBoard(t, theme)
+			state := types.NewBoardState(9)
+			state.Board[4][4] = 1
+			state.Board[2][2] = 2
+			lines := render.BoardToLines(state, render.RenderOptions{
+				Symbols:   theme.Symbols,
+				GridLines: theme.UseGridLines,
+				ASCII:     ascii,
+			})
+			for y, want := range lines {
+				var row []rune
+				for x := 3; x < 4+2*9; x++ {
+					r, _, _, _ := screen.GetContent(x, y)
+					row = append(row, r)
+				}
+				if got := strings.TrimRight(string(row), " "); got != want {
+					t.Errorf("ascii %v, row %d:\nscreen %q\nrender %q", ascii, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestMenuGlyphProfiles(t *testing.T) {
+	defer SetMenuGlyphs("")
+	for _, tt := range []struct {
+		profile              config.MenuGlyphs
+		tl, tr, bl, br, l, r rune
+		accent, tviewCorner  rune
+	}{
+		{config.MenuGlyphsUnicode, '╭', '╮', '╰', '╯', '├', '┤', '⬡', '┌'},
+		{config.MenuGlyphsASCII, '+', '+', '+', '+', '+', '+', '#', '+'},
+	} {
+		SetMenuGlyphs(tt.profile)
+		screen := newTestScreen(t, 30, 10)
+		card := NewMenuCard("囲碁")
+		card.SetRect(0, 0, 30, 10)
+		card.Draw(screen)
+
+		for _, c := range []struct {
+			x, y int
+			want rune
+		}{
+			{0, 0, tt.tl}, {29, 0, tt.tr}, {0, 9, tt.bl}, {29, 9, tt.br},
+			{0, 4, tt.l}, {29, 4, tt.r},
+			// "⬡  囲碁" is 7 cells wide, each of the title's runes two
+			{11, 2, tt.accent}, {14, 2, '囲'}, {16, 2, '碁'},
+		} {
+			if got, _, _, _ := screen.GetContent(c.x, c.y); got != c.want {
+				t.Errorf("%s: cell (%d,%d) = %q, want %q", tt.profile, c.x, c.y, got, c.want)
+			}
+		}
+
+		// A button's width is the cells it draws
+		b := NewMenuButton("碁 Go", true, nil)
+		b.Draw(screen, 2, 7)
+		if got, _, _, _ := screen.GetContent(2+b.Width()-1, 7); got != ']' {
+			t.Errorf("%s: button of width %d ends in %q", tt.profile, b.Width(), got)
+		}
+
+		// tview's own boxes follow
+		if tview.Borders.TopLeft != tt.tviewCorner {
+			t.Errorf("%s: tview corner %q, want %q", tt.profile, tview.Borders.TopLeft, tt.tviewCorner)
+		}
+		screen.Fini()
+	}
+
+	// The menus' profile leaves the board as it is
+	SetMenuGlyphs(config.MenuGlyphsASCII)
+	if r, _, _, _ := drawTestBoard(t, config.DefaultTheme).GetContent(4, 0); r != '┌' {
+		t.Errorf("board corner %q with ASCII menus, want the Unicode one", r)
+	}
+}
+
+func TestMenuGlyphsAuto(t *testing.T) {
+	SetASCIIOnly(true)
+	defer SetASCIIOnly(false)
+	if glyph('◈') != '*' || tview.Borders.Horizontal != '-' {
+		t.Error("auto menus not in ASCII with the board")
+	}
+	SetMenuGlyphs(config.MenuGlyphsUnicode)
+	defer SetMenuGlyphs("")
+	if glyph('◈') != '◈' || tview.Borders.Horizontal != '─' {
+		t.Error("unicode menus drawn in ASCII")
+	}
+}
+
+// The match card and the history's comparison follow the menus' profile.
+func TestMenuGlyphProfileViews(t *testing.T) {
+	defer SetMenuGlyphs("")
+	for _, tt := range []struct {
+		profile    config.MenuGlyphs
+		help, diff string
+	}{
+		{config.MenuGlyphsUnicode, "↑↓ change · Tab next", "● only A: 0"},
+		{config.MenuGlyphsASCII, "^v change . Tab next", "* only A: 0"},
+	} {
+		SetMenuGlyphs(tt.profile)
+		screen := newTestScreen(t, 100, 30)
+
+		m := NewMatchConfirm(func(engine.GameConfig) {}, func() {})
+		m.SetGameConfig(engine.GameConfig{BoardSize: 9, Komi: 6.5, PlayerColor: 1, EngineLevel: 5})
+		m.Flex().SetRect(0, 0, 100, 30)
+		m.Flex().Draw(screen)
+		screen.Show()
+		if text := screenText(screen); !strings.Contains(text, tt.help) {
+			t.Errorf("%s: match card without %q:\n%s", tt.profile, tt.help, text)
+		}
+		if tt.profile == config.MenuGlyphsASCII {
+			assertASCII(t, screen)
+		}
+
+		hb := NewHistoryBrowser(NewGameList(writeHistory(t, []int{9, 9}, []string{"B+R", "W+R"})), nil, nil, nil, nil, nil)
+		hb.Refresh()
+		hb.handleInput(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone))
+		hb.gameList.SetCurrentItem(1)
+		hb.handleInput(tcell.NewEventKey(tcell.KeyRune, '=', tcell.ModNone))
+		screen.Clear()
+		hb.flex.SetRect(0, 0, 100, 30)
+		hb.flex.Draw(screen)
+		screen.Show()
+		if text := screenText(screen); !strings.Contains(text, tt.diff) {
+			t.Errorf("%s: comparison without %q:\n%s", tt.profile, tt.diff, text)
+		}
+		if tt.profile == config.MenuGlyphsASCII {
+			assertASCII(t, screen)
+		}
+		screen.Fini()
+	}
+}
+
+// thinkingEngine is a fake engine that is always on its own move.
+type thinkingEngine struct {
+	*fakeEngine
+}
+
+func (thinkingEngine) IsMyTurn() bool { return false }
+
+func TestASCIIHint(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.Theme.AsciiOnly = true
+	cfg.ConfirmMoves = true
+	g := NewGoBoard(tview.NewApplication(), &cfg, tview.NewTextView().SetDynamicColors(true))
+	if err := g.ConnectEngine(thinkingEngine{newFakeEngine(9)}); err != nil {
+		t.Fatalf("ConnectEngine: %v", err)
+	}
+	g.refreshHint()
+	if text := g.hint.GetText(true); !strings.Contains(text, "o Thinking") {
+		t.Errorf("hint = %q, want the thinking marker's stand-in", text)
+	}
+	assertASCIIText(t, g.hint.GetText(true))
+
+	g.eng = newFakeEngine(9)
+	g.confirmMove(4, 4)
+	if text := g.hint.GetText(true); !strings.Contains(text, "confirm E5? enter / q") {
+		t.Errorf("hint = %q, want the confirm prompt", text)
+	}
+	assertASCIIText(t, g.hint.GetText(true))
+}
+
+// assertASCIIText fails if s holds a non-ASCII rune.
+func assertASCIIText(t *testing.T, s string) {
+	t.Helper()
+	for _, r := range s {
+		if r > 127 {
+			t.Fatalf("%q in %q, want ASCII", r, s)
+		}
+	}
+}
+
+func TestASCIIPanel(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.Theme.AsciiOnly = true
+	g := NewGoBoard(tview.NewApplication(), &cfg, tview.NewTextView())
+	if err := g.ConnectEngine(newFakeEngine(9)); err != nil {
+		t.Fatalf("ConnectEngine: %v", err)
+	}
+	g.infoPanel = NewGameInfoPanel()
+	g.infoPanel.SetMoveHistory(&g.moveHistory, 9)
+	g.SetMoveHistory([][3]int{{1, 4, 4}, {2, 2, 2}})
+	g.moveHistory[0].Comment = "a fine start"
+	g.infoPanel.SetClocks("5:00", "4:59", 1)
+	g.refreshHint()
+
+	screen := newTestScreen(t, 26, 30)
+	defer screen.Fini()
+	g.infoPanel.Box().SetRect(0, 0, 26, 30)
+	g.infoPanel.Box().Draw(screen)
+	assertASCII(t, screen)
+	if text := g.infoPanel.Box().GetText(true); !strings.Contains(text, "------") || !strings.Contains(text, "* 5:00  o 4:59") || !strings.Contains(text, "E5 ~") {
+		t.Errorf("panel = %q, want the rule, clock stones and comment mark in ASCII", text)
+	}
+}
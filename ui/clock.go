@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"time"
+
+	"termsuji-local/engine"
+)
+
+// clockTick is how often the side to move is charged and the clocks
+// redrawn.
+const clockTick = 200 * time.Millisecond
+
+// startClocks sets both clocks to gc's time controls and starts charging
+// the side to move. An untimed game has no clocks.
+func (g *GoBoardUI) startClocks(gc engine.GameConfig) {
+	g.stopClocks()
+	g.clockMu.Lock()
+	g.clocks = [3]engine.GameClock{}
+	if gc.Timed() {
+		g.clocks[1], g.clocks[2] = engine.NewGameClock(gc), engine.NewGameClock(gc)
+	}
+	g.clockAt = time.Now()
+	g.clockMu.Unlock()
+	if !gc.Timed() {
+		g.showClocks()
+		return
+	}
+
+	stop := make(chan struct{})
+	g.clockStop = stop
+	go func() {
+		t := time.NewTicker(clockTick)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				g.app.QueueUpdateDraw(func() {
+					select {
+					case <-stop:
+					default:
+						g.tickClocks()
+					}
+				})
+			}
+		}
+	}()
+	g.showClocks()
+}
+
+// stopClocks stops the ticker, leaving the clocks as they are.
+func (g *GoBoardUI) stopClocks() {
+	if g.clockStop != nil {
+		close(g.clockStop)
+		g.clockStop = nil
+	}
+}
+
+// tickClocks charges the side to move for the time since it was last
+// charged, and ends the game once the player's time runs out. The clocks
+// stand still while counting or replaying a game. Runs on the UI
+// goroutine.
+func (g *GoBoardUI) tickClocks() {
+	if g.clockStop == nil || g.eng == nil || g.BoardState == nil {
+		return
+	}
+	paused := g.finished || g.scoring || g.IsLoading()
+	side := g.BoardState.PlayerToMove
+
+	g.clockMu.Lock()
+	now := time.Now()
+	spent := now.Sub(g.clockAt)
+	g.clockAt = now
+	inTime := true
+	if !paused && (side == 1 || side == 2) {
+		inTime = g.clocks[side].Spend(spent)
+	}
+	g.clockMu.Unlock()
+
+	g.showClocks()
+	if !inTime && side == g.eng.GetPlayerColor() {
+		g.stopClocks()
+		g.eng.LoseOnTime()
+	}
+}
+
+// Resumed restarts the clocks from now after the process was stopped, as
+// by ctrl-z: the time it spent stopped was no one's to think. Runs on the
+// UI goroutine.
+func (g *GoBoardUI) Resumed() {
+	g.clockMu.Lock()
+	g.clockAt = time.Now()
+	g.clockMu.Unlock()
+}
+
+// chargeMove charges color for the move it just played. Called from the
+// engine's move callback.
+func (g *GoBoardUI) chargeMove(color int) {
+	if color != 1 && color != 2 {
+		return
+	}
+	g.clockMu.Lock()
+	defer g.clockMu.Unlock()
+	if g.clocks[color] == (engine.GameClock{}) {
+		return // untimed, or out of time already
+	}
+	now := time.Now()
+	g.clocks[color].Spend(now.Sub(g.clockAt))
+	g.clocks[color].Moved()
+	g.clockAt = now
+}
+
+// showClocks puts the clocks in the info panel, or clears them for an
+// untimed game.
+func (g *GoBoardUI) showClocks() {
+	if g.infoPanel == nil {
+		return
+	}
+	if !g.gameConfig.Timed() {
+		g.infoPanel.SetClocks("", "", 0)
+		return
+	}
+	toMove := 0
+	if g.clockStop != nil && g.BoardState != nil {
+		toMove = g.BoardState.PlayerToMove
+	}
+	g.clockMu.Lock()
+	black, white := g.clocks[1].String(), g.clocks[2].String()
+	g.clockMu.Unlock()
+	g.infoPanel.SetClocks(black, white, toMove)
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+)
+
+func TestClocks(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.infoPanel = NewGameInfoPanel()
+	g.infoPanel.SetBoardState(g.BoardState)
+	g.SetGameConfig(engine.GameConfig{BoardSize: 9, MainTime: time.Minute, ByoYomiTime: 30 * time.Second, ByoYomiStones: 1})
+	defer g.stopClocks()
+	g.BoardState.PlayerToMove = 1
+
+	// Black's move took 20 seconds.
+	g.clockAt = time.Now().Add(-20 * time.Second)
+	g.chargeMove(1)
+	g.BoardState.PlayerToMove = 2
+	g.tickClocks()
+	if text := g.infoPanel.Box().GetText(true); !strings.Contains(text, "● 0:40") || !strings.Contains(text, "○ 1:00") {
+		t.Errorf("panel clocks after Black's move:\n%s", text)
+	}
+
+	// White running out is not the player losing.
+	g.clockAt = time.Now().Add(-2 * time.Minute)
+	g.tickClocks()
+	if eng.lostOnTime {
+		t.Fatal("the engine's clock running out lost the player the game")
+	}
+
+	// The player's main time and period go by: a loss on time.
+	g.BoardState.PlayerToMove = 1
+	g.clockAt = time.Now().Add(-71 * time.Second)
+	g.tickClocks()
+	if !eng.lostOnTime || g.clockStop != nil {
+		t.Errorf("after Black's time ran out: lost %v, ticking %v", eng.lostOnTime, g.clockStop != nil)
+	}
+}
+
+// The time the process spent stopped, as by ctrl-z, isn't charged.
+func TestClocksResumed(t *testing.T) {
+	g, _ := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.infoPanel = NewGameInfoPanel()
+	g.infoPanel.SetBoardState(g.BoardState)
+	g.SetGameConfig(engine.GameConfig{BoardSize: 9, MainTime: time.Minute})
+	defer g.stopClocks()
+	g.BoardState.PlayerToMove = 1
+
+	g.clockAt = time.Now().Add(-30 * time.Second) // stopped since the last tick
+	g.Resumed()
+	g.tickClocks()
+	if text := g.infoPanel.Box().GetText(true); !strings.Contains(text, "● 1:00") {
+		t.Errorf("panel clocks after resuming:\n%s", text)
+	}
+}
@@ -12,18 +12,30 @@ import (
 
 // ColorConfigUI provides a color configuration screen with live preview.
 type ColorConfigUI struct {
-	flex       *tview.Flex
-	colorList  *tview.List
-	preview    *tview.Box
-	cfg        *config.Config
-	onDone     func()
+	flex      *tview.Flex
+	colorList *tview.List
+	preview   *tview.Box
+	cfg       *config.Config
+	onDone    func()
+	onPreset  func()
 
 	// Current selection
 	selectedBoardColor int
 	selectedLineColor  int
-	editingLine        bool // true = editing line color, false = editing board color
+	selectedPreset     int // index into config.ThemePresets
+	mode               colorMode
 }
 
+// colorMode is the list the color screen shows; Tab steps through them.
+type colorMode int
+
+const (
+	editBoard colorMode = iota
+	editLine
+	pickPreset
+	colorModes
+)
+
 // Common board colors to choose from (warm wood-like tones)
 var boardColors = []struct {
 	code int
@@ -80,7 +92,11 @@ func NewColorConfig(cfg *config.Config, onDone func()) *ColorConfigUI {
 		onDone:             onDone,
 		selectedBoardColor: cfg.Theme.Colors.BoardColor,
 		selectedLineColor:  cfg.Theme.Colors.LineColor,
-		editingLine:        false,
+	}
+	for i, p := range config.ThemePresets {
+		if p.Name == cfg.ThemePreset {
+			cc.selectedPreset = i
+		}
 	}
 
 	// Create the color list
@@ -93,43 +109,54 @@ func NewColorConfig(cfg *config.Config, onDone func()) *ColorConfigUI {
 
 	// Handle selection change (preview)
 	cc.colorList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
-		if cc.editingLine {
+		switch cc.mode {
+		case editLine:
 			if index >= 0 && index < len(lineColors) {
 				cc.selectedLineColor = lineColors[index].code
 				cc.updatePreview()
 			}
-		} else {
+		case editBoard:
 			if index >= 0 && index < len(boardColors) {
 				cc.selectedBoardColor = boardColors[index].code
 				cc.updatePreview()
 			}
+		case pickPreset:
+			if index >= 0 && index < len(config.ThemePresets) {
+				cc.selectedPreset = index
+				cc.updatePreview()
+			}
 		}
 	})
 
 	// Handle selection confirm (apply)
 	cc.colorList.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
-		if cc.editingLine {
+		switch cc.mode {
+		case editLine:
 			if index >= 0 && index < len(lineColors) {
-				cc.cfg.Theme.Colors.LineColor = cc.selectedLineColor
+				cc.cfg.SetThemeColor("line", cc.selectedLineColor)
 				cc.cfg.Save()
 				// Switch back to board color selection
-				cc.editingLine = false
+				cc.mode = editBoard
 				cc.populateColorList()
 			}
-		} else {
+		case editBoard:
 			if index >= 0 && index < len(boardColors) {
-				cc.cfg.Theme.Colors.BoardColor = cc.selectedBoardColor
-				cc.cfg.Theme.Colors.BoardColorAlt = cc.selectedBoardColor
+				cc.cfg.SetThemeColor("board", cc.selectedBoardColor)
+				cc.cfg.SetThemeColor("board_alt", cc.selectedBoardColor)
 				cc.cfg.Save()
 				onDone()
 			}
+		case pickPreset:
+			if index >= 0 && index < len(config.ThemePresets) {
+				cc.applyPreset(config.ThemePresets[index])
+			}
 		}
 	})
 
 	// Create preview box
 	cc.preview = tview.NewBox()
 	cc.preview.SetBorder(true)
-	cc.preview.SetTitle(" Board Preview ")
+	cc.preview.SetTitle(" Board Preview · " + themeVariant() + " ")
 	cc.preview.SetDrawFunc(cc.drawPreview)
 
 	// Layout: list on left, preview on right
@@ -140,12 +167,62 @@ func NewColorConfig(cfg *config.Config, onDone func()) *ColorConfigUI {
 	return cc
 }
 
+// themeVariant names the theme variant in use, which follows the terminal's
+// background unless the config's background setting picks one.
+func themeVariant() string {
+	if IsLightBackground() {
+		return "light variant"
+	}
+	return "dark variant"
+}
+
+// SetPresetFunc sets what to do once a preset has been applied, such as
+// redrawing the board in it.
+func (cc *ColorConfigUI) SetPresetFunc(f func()) {
+	cc.onPreset = f
+}
+
+// applyPreset switches the theme to p, dropping the colors picked on top of
+// the last one, and saves the choice. Without colors only the choice is
+// kept, for when they come back.
+func (cc *ColorConfigUI) applyPreset(p config.ThemePreset) {
+	cc.cfg.ThemePreset = p.Name
+	cc.cfg.ThemeColors = nil
+	if !IsNoColor() {
+		bg := config.BackgroundDark
+		if IsLightBackground() {
+			bg = config.BackgroundLight
+		}
+		cc.cfg.ApplyTheme(bg)
+	}
+	cc.cfg.Save()
+	cc.selectedBoardColor = cc.cfg.Theme.Colors.BoardColor
+	cc.selectedLineColor = cc.cfg.Theme.Colors.LineColor
+	cc.populateColorList()
+	if cc.onPreset != nil {
+		cc.onPreset()
+	}
+}
+
 // populateColorList fills the list with appropriate colors based on editing mode.
 func (cc *ColorConfigUI) populateColorList() {
 	cc.colorList.Clear()
 
-	if cc.editingLine {
-		cc.colorList.SetTitle(" Select Line Color (Tab: switch to board) ")
+	switch cc.mode {
+	case pickPreset:
+		cc.colorList.SetTitle(" Select Theme Preset (Tab: switch to board) ")
+		for i, p := range config.ThemePresets {
+			name := p.Name
+			if name == cc.cfg.ThemePreset {
+				name += " ✓"
+			}
+			cc.colorList.AddItem(fmt.Sprintf("[#%06x]████[-] %s",
+				themeColor(p.Dark.Colors.BoardColor).Hex(), name),
+				"", rune('a'+i), nil)
+		}
+		cc.colorList.SetCurrentItem(cc.selectedPreset)
+	case editLine:
+		cc.colorList.SetTitle(" Select Line Color (Tab: switch to preset) ")
 		for i, c := range lineColors {
 			cc.colorList.AddItem(fmt.Sprintf("[#%06x]████[-] %s (%d)",
 				tcell.PaletteColor(c.code).Hex(), c.name, c.code),
@@ -158,7 +235,7 @@ func (cc *ColorConfigUI) populateColorList() {
 				break
 			}
 		}
-	} else {
+	default:
 		cc.colorList.SetTitle(" Select Board Color (Tab: switch to line) ")
 		for i, c := range boardColors {
 			cc.colorList.AddItem(fmt.Sprintf("[#%06x]████[-] %s (%d)",
@@ -185,11 +262,17 @@ func (cc *ColorConfigUI) updatePreview() {
 }
 
 func (cc *ColorConfigUI) drawPreview(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
-	// Draw a mini Go board preview with the selected colors
-	boardColor := tcell.PaletteColor(cc.selectedBoardColor)
-	blackColor := tcell.PaletteColor(cc.cfg.Theme.Colors.BlackColor)
-	whiteColor := tcell.PaletteColor(cc.cfg.Theme.Colors.WhiteColor)
-	lineColor := tcell.PaletteColor(cc.selectedLineColor)
+	// Draw a mini Go board preview with the selected colors, or the
+	// highlighted preset's
+	colors := cc.cfg.Theme.Colors
+	colors.BoardColor, colors.LineColor = cc.selectedBoardColor, cc.selectedLineColor
+	if cc.mode == pickPreset && cc.selectedPreset < len(config.ThemePresets) {
+		colors = config.ThemePresets[cc.selectedPreset].Dark.Colors
+	}
+	boardColor := themeColor(colors.BoardColor)
+	blackColor := themeColor(colors.BlackColor)
+	whiteColor := themeColor(colors.WhiteColor)
+	lineColor := themeColor(colors.LineColor)
 
 	boardStyle := tcell.StyleDefault.Background(boardColor).Foreground(lineColor)
 	blackStyle := tcell.StyleDefault.Background(boardColor).Foreground(blackColor)
@@ -276,10 +359,14 @@ func (cc *ColorConfigUI) drawPreview(screen tcell.Screen, x, y, width, height in
 	// Draw color info
 	infoStyle := tcell.StyleDefault
 	var info string
-	if cc.editingLine {
-		info = fmt.Sprintf("Line: %d  Board: %d", cc.selectedLineColor, cc.selectedBoardColor)
-	} else {
-		info = fmt.Sprintf("Board: %d  Line: %d", cc.selectedBoardColor, cc.selectedLineColor)
+	switch cc.mode {
+	case pickPreset:
+		p := config.ThemePresets[cc.selectedPreset]
+		info = fmt.Sprintf("%s: %s", p.Name, p.Description)
+	case editLine:
+		info = fmt.Sprintf("Line: %s  Board: %s", config.FormatColor(cc.selectedLineColor), config.FormatColor(cc.selectedBoardColor))
+	default:
+		info = fmt.Sprintf("Board: %s  Line: %s", config.FormatColor(cc.selectedBoardColor), config.FormatColor(cc.selectedLineColor))
 	}
 	for i, ch := range info {
 		if startX+i < x+width-1 {
@@ -300,8 +387,9 @@ func (cc *ColorConfigUI) SetInputCapture(capture func(event *tcell.EventKey) *tc
 	cc.colorList.SetInputCapture(capture)
 }
 
-// ToggleMode switches between board color and line color editing.
+// ToggleMode steps from board color to line color editing to the presets,
+// and back to the board.
 func (cc *ColorConfigUI) ToggleMode() {
-	cc.editingLine = !cc.editingLine
+	cc.mode = (cc.mode + 1) % colorModes
 	cc.populateColorList()
 }
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Comments annotate the game as it's played: ; opens a one-line field in
+// the hint bar for a note on the last move, saved as the move's C[] when
+// the game is recorded.
+
+// StartComment opens the comment field for the last move, filled with its
+// current comment. It does nothing before the first move, in focus mode,
+// which hides the hint bar, or while planning, reviewing, counting or
+// loading.
+func (g *GoBoardUI) StartComment() {
+	if g.focusMode || g.planningMode || g.review != nil || g.scoring || g.IsLoading() {
+		return
+	}
+	g.histMu.Lock()
+	n := len(g.moveHistory)
+	var text string
+	if n > 0 {
+		text = g.moveHistory[n-1].Comment
+	}
+	g.histMu.Unlock()
+	if n == 0 {
+		return
+	}
+	// Pin the move now: the engine may answer while the note is typed.
+	g.commentMove = n - 1
+	g.commentRec = -1
+	if g.recorder != nil && g.recorder.MoveCount() > 0 {
+		g.commentRec = g.recorder.MoveCount() - 1
+	}
+	g.commentText = []rune(text)
+	g.commenting = true
+	g.refreshHint()
+}
+
+// IsCommenting returns true while the comment field is open.
+func (g *GoBoardUI) IsCommenting() bool {
+	return g.commenting
+}
+
+// CommentKey edits the comment: Enter saves it, Escape drops it.
+func (g *GoBoardUI) CommentKey(event *tcell.EventKey) {
+	switch event.Key() {
+	case tcell.KeyEnter:
+		g.saveComment()
+		return
+	case tcell.KeyEscape:
+		g.commenting = false
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(g.commentText) > 0 {
+			g.commentText = g.commentText[:len(g.commentText)-1]
+		}
+	case tcell.KeyCtrlU:
+		g.commentText = nil
+	case tcell.KeyRune:
+		g.commentText = append(g.commentText, event.Rune())
+	}
+	g.refreshHint()
+}
+
+// saveComment attaches the typed text to the pinned move and, when the
+// game is recorded, to its node in the SGF. Empty text removes the comment.
+func (g *GoBoardUI) saveComment() {
+	g.commenting = false
+	text := strings.TrimSpace(string(g.commentText))
+	g.histMu.Lock()
+	if g.commentMove < len(g.moveHistory) {
+		g.moveHistory[g.commentMove].Comment = text
+	}
+	g.histMu.Unlock()
+	switch {
+	case g.commentRec >= 0 && g.recorder != nil:
+		if err := g.recorder.AddComment(g.commentRec, text); err != nil {
+			g.notice = "comment not saved: " + err.Error()
+		}
+	case g.recorder == nil && text != "":
+		g.notice = "not recording: comment kept for this session only"
+	}
+	g.refreshHint()
+}
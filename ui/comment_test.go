@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/sgf"
+)
+
+func TestCommentLastMove(t *testing.T) {
+	cfg := config.DefaultConfig
+	g := NewGoBoard(tview.NewApplication(), &cfg, tview.NewTextView())
+	g.infoPanel = NewGameInfoPanel()
+	g.infoPanel.SetMoveHistory(&g.moveHistory, 9)
+
+	g.StartComment()
+	if g.IsCommenting() {
+		t.Fatal("StartComment before the first move should do nothing")
+	}
+
+	rec, err := sgf.NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, sgf.RecordOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetRecorder(rec)
+	g.SetMoveHistory([][3]int{{1, 4, 4}, {2, 2, 2}})
+	g.recorder.AddMove(4, 4, 1)
+	g.recorder.AddMove(2, 2, 2)
+
+	key := func(k tcell.Key) { g.CommentKey(tcell.NewEventKey(k, 0, tcell.ModNone)) }
+	g.StartComment()
+	typeKeys(func(ev *tcell.EventKey) *tcell.EventKey { g.CommentKey(ev); return nil }, "slack]x")
+	key(tcell.KeyBackspace2)
+	if !strings.Contains(g.hint.GetText(true), "slack]") {
+		t.Errorf("hint while typing = %q", g.hint.GetText(true))
+	}
+	key(tcell.KeyEnter)
+
+	if g.IsCommenting() {
+		t.Error("Enter should close the comment field")
+	}
+	if got := g.MovesSnapshot()[1].Comment; got != "slack]" {
+		t.Errorf("move comment = %q, want %q", got, "slack]")
+	}
+	if !strings.Contains(g.infoPanel.Box().GetText(true), "✎") {
+		t.Error("move list should mark the commented move")
+	}
+
+	// Escape leaves the comment as it was.
+	g.StartComment()
+	key(tcell.KeyCtrlU)
+	key(tcell.KeyEscape)
+	if got := g.MovesSnapshot()[1].Comment; got != "slack]" {
+		t.Errorf("after Escape, comment = %q", got)
+	}
+
+	path := g.recorder.FilePath
+	if err := g.recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := sgf.ParseHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.MoveComments[1]; got != "slack]" {
+		t.Errorf("saved comment = %q, want %q", got, "slack]")
+	}
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"termsuji-local/coords"
+	"termsuji-local/types"
+)
+
+// confirmMove returns true if the move at x, y may be played now. With
+// ConfirmMoves on, the first Enter only shows a ghost stone there, and a
+// second Enter on the same point plays it. A point already taken goes
+// straight through, to be refused with the reason.
+func (g *GoBoardUI) confirmMove(x, y int) bool {
+	if !g.cfg.ConfirmMoves || g.BoardState == nil || g.BoardState.Board[y][x] != 0 {
+		return true
+	}
+	if g.pending != nil && g.pending.X == x && g.pending.Y == y {
+		g.pending = nil
+		return true
+	}
+	g.pending = &types.BoardPos{X: x, Y: y}
+	g.refreshHint()
+	return false
+}
+
+// CancelConfirm drops a move waiting for its second Enter, and reports
+// whether there was one.
+func (g *GoBoardUI) CancelConfirm() bool {
+	if g.pending == nil {
+		return false
+	}
+	g.pending = nil
+	g.refreshHint()
+	return true
+}
+
+// ghostAt reports whether x, y holds the ghost stone of a move waiting to
+// be confirmed.
+func (g *GoBoardUI) ghostAt(x, y int) bool {
+	return g.pending != nil && g.pending.X == x && g.pending.Y == y
+}
+
+// confirmPrompt asks for the second Enter in the hint bar, or is "" with
+// no move waiting.
+func (g *GoBoardUI) confirmPrompt() string {
+	if g.pending == nil {
+		return ""
+	}
+	return tag("yellow", "confirm "+coords.Display(g.pending.X, g.pending.Y, g.BoardState.Width())+"? ⏎ / q")
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+)
+
+// newConfirmBoard returns a board asking for a second Enter, whose engine
+// refuses every move so that reaching it shows as a flash.
+func newConfirmBoard(t *testing.T) (*GoBoardUI, *fakeEngine) {
+	t.Helper()
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.cfg.ConfirmMoves = true
+	g.BoardState = eng.state
+	eng.playErr = fmt.Errorf("%w: reached the engine", engine.ErrIllegalMove)
+	return g, eng
+}
+
+func TestConfirmMove(t *testing.T) {
+	g, _ := newConfirmBoard(t)
+
+	g.PlayMove(4, 4)
+	if g.flash != "" {
+		t.Fatalf("first Enter reached the engine: %q", g.flash)
+	}
+	if !g.ghostAt(4, 4) {
+		t.Errorf("no ghost stone at E5 after the first Enter")
+	}
+	if hint := g.hint.GetText(true); !strings.Contains(hint, "confirm E5?") {
+		t.Errorf("hint = %q, want the confirm prompt", hint)
+	}
+
+	g.PlayMove(4, 4)
+	if g.flash == "" {
+		t.Fatalf("second Enter didn't reach the engine")
+	}
+	if g.pending != nil {
+		t.Errorf("move still pending after it was played")
+	}
+}
+
+func TestConfirmMoveElsewhere(t *testing.T) {
+	g, _ := newConfirmBoard(t)
+
+	// Enter on another point moves the ghost rather than playing
+	g.PlayMove(4, 4)
+	g.PlayMove(2, 2)
+	if g.flash != "" {
+		t.Fatalf("Enter on a second point reached the engine: %q", g.flash)
+	}
+	if g.ghostAt(4, 4) || !g.ghostAt(2, 2) {
+		t.Errorf("ghost not moved to C7")
+	}
+}
+
+func TestConfirmMoveCancels(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func(g *GoBoardUI)
+	}{
+		{"cursor moved", func(g *GoBoardUI) { g.MoveSelection(1, 0) }},
+		{"selection reset", func(g *GoBoardUI) { g.ResetSelection() }},
+		{"q", func(g *GoBoardUI) {
+			if !g.CancelConfirm() {
+				t.Errorf("CancelConfirm() = false with a move pending")
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newConfirmBoard(t)
+			g.selX, g.selY = 4, 4
+			g.PlayMove(4, 4)
+			tt.cancel(g)
+			if g.pending != nil {
+				t.Fatalf("move still pending")
+			}
+			if hint := g.hint.GetText(true); strings.Contains(hint, "confirm") {
+				t.Errorf("hint = %q, still asking to confirm", hint)
+			}
+			if g.CancelConfirm() {
+				t.Errorf("CancelConfirm() = true with nothing pending")
+			}
+		})
+	}
+}
+
+func TestConfirmMoveOccupied(t *testing.T) {
+	g, eng := newConfirmBoard(t)
+	eng.state.Board[4][4] = 2
+
+	// Taken points are refused at once, with the reason
+	g.PlayMove(4, 4)
+	if g.pending != nil {
+		t.Errorf("occupied point left pending")
+	}
+	if want := "Illegal move: E5 is occupied"; g.flash != want {
+		t.Errorf("flash = %q, want %q", g.flash, want)
+	}
+}
+
+func TestConfirmMoveOff(t *testing.T) {
+	g, _ := newConfirmBoard(t)
+	g.cfg.ConfirmMoves = false
+
+	g.PlayMove(4, 4)
+	if g.pending != nil || g.flash == "" {
+		t.Errorf("with confirm_moves off the first Enter should play")
+	}
+}
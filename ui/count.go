@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// countTimeout is how long a count waits for the key it applies to.
+const countTimeout = 2 * time.Second
+
+// maxCount caps a count; no board is wider than this.
+const maxCount = 99
+
+// CountKey takes digits typed before a movement key, so "5l" moves five
+// points right as in vi. A leading 0 isn't a count, and Escape drops a
+// pending one. It reports whether it used the key.
+func (g *GoBoardUI) CountKey(ev *tcell.EventKey) bool {
+	if g.count > 0 && time.Since(g.countAt) > countTimeout {
+		g.count = 0
+	}
+	if ev.Key() == tcell.KeyEscape && g.count > 0 {
+		g.count = 0
+		g.refreshHint()
+		return true
+	}
+	if ev.Key() != tcell.KeyRune || ev.Rune() < '0' || ev.Rune() > '9' || g.count == 0 && ev.Rune() == '0' {
+		return false
+	}
+	g.count = g.count*10 + int(ev.Rune()-'0')
+	if g.count > maxCount {
+		g.count = maxCount
+	}
+	at := time.Now()
+	g.countAt = at
+	g.refreshHint()
+
+	// Drop the count from the hint bar once it has gone stale
+	time.AfterFunc(countTimeout, func() {
+		g.app.QueueUpdateDraw(func() {
+			if g.count > 0 && g.countAt == at {
+				g.count = 0
+				g.refreshHint()
+			}
+		})
+	})
+	return true
+}
+
+// TakeCount returns the pending count, or 1 without one, and clears it.
+// It is called for every key CountKey passes on, so a count only applies
+// to the key right after it.
+func (g *GoBoardUI) TakeCount() int {
+	n := g.count
+	if n == 0 || time.Since(g.countAt) > countTimeout {
+		n = 1
+	}
+	if g.count > 0 {
+		g.count = 0
+		g.refreshHint()
+	}
+	return n
+}
+
+// MoveSelectionBy moves the cursor n points along h, v, stopping at the
+// edge of the board.
+func (g *GoBoardUI) MoveSelectionBy(h, v, n int) {
+	for i := 0; i < n; i++ {
+		g.MoveSelection(h, v)
+	}
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+)
+
+func TestCountPrefix(t *testing.T) {
+	g, _ := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.selX, g.selY = 4, 4
+
+	// The board input handler, cut down to counts and movement
+	press := func(ev *tcell.EventKey) *tcell.EventKey {
+		if g.CountKey(ev) {
+			return nil
+		}
+		n := g.TakeCount()
+		switch ev.Rune() {
+		case 'h':
+			g.MoveSelectionBy(-1, 0, n)
+		case 'j':
+			g.MoveSelectionBy(0, 1, n)
+		case 'k':
+			g.MoveSelectionBy(0, -1, n)
+		case 'l':
+			g.MoveSelectionBy(1, 0, n)
+		}
+		return nil
+	}
+
+	typeKeys(press, "12")
+	if !strings.Contains(g.hint.GetText(true), "12…") {
+		t.Errorf("pending count missing from hint %q", g.hint.GetText(true))
+	}
+	typeKeys(press, "j")
+	if g.selX != 4 || g.selY != 8 {
+		t.Errorf("12j from 4,4 = %d,%d; want 4,8 at the edge", g.selX, g.selY)
+	}
+	typeKeys(press, "3k")
+	if g.selY != 5 {
+		t.Errorf("3k = row %d, want 5", g.selY)
+	}
+
+	// Escape drops the count; the next key moves once.
+	typeKeys(press, "5")
+	press(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if g.count != 0 || strings.Contains(g.hint.GetText(true), "5…") {
+		t.Errorf("count %d left after Escape", g.count)
+	}
+	typeKeys(press, "l")
+	if g.selX != 5 {
+		t.Errorf("l after Escape = column %d, want 5", g.selX)
+	}
+
+	// A key that isn't movement uses the count up; 0 alone isn't one.
+	typeKeys(press, "4p0h")
+	if g.selX != 4 || g.count != 0 {
+		t.Errorf("4p0h = column %d, count %d; want 4, 0", g.selX, g.count)
+	}
+}
@@ -0,0 +1,58 @@
+package ui
+
+// Rules of thumb behind the beginner hints: early in the game, a move on
+// the first or second line away from everything else is usually a waste.
+
+const (
+	openingMoves = 20 // moves played before the opening is over
+	maxEdgeHints = 3  // nudges shown per session before going quiet
+)
+
+// inOpening returns true while the game is young enough for the hints.
+func inOpening(moveNumber int) bool {
+	return moveNumber < openingMoves
+}
+
+// edgeLine returns which line from the nearest edge x, y is on, 1 for the
+// edge itself.
+func edgeLine(x, y, size int) int {
+	line := x
+	for _, d := range []int{y, size - 1 - x, size - 1 - y} {
+		if d < line {
+			line = d
+		}
+	}
+	return line + 1
+}
+
+// isolated returns true if none of the points next to x, y hold a stone.
+// board is the position before the move, so a capture still counts as
+// contact.
+func isolated(board [][]int, x, y int) bool {
+	for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		nx, ny := x+d[0], y+d[1]
+		if ny < 0 || ny >= len(board) || nx < 0 || nx >= len(board[ny]) {
+			continue
+		}
+		if board[ny][nx] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// edgeHint returns the nudge for playing x, y as move moveNumber (counting
+// from 0) on board, or "" if the move is fine by these rules. A move
+// touching other stones is a fight, not a low move, so it passes.
+func edgeHint(board [][]int, x, y, moveNumber int) string {
+	if !inOpening(moveNumber) || x < 0 || y < 0 || !isolated(board, x, y) {
+		return ""
+	}
+	switch edgeLine(x, y, len(board)) {
+	case 1:
+		return "first-line moves are rarely good this early"
+	case 2:
+		return "second-line moves are usually too low this early"
+	}
+	return ""
+}
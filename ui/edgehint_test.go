@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/types"
+)
+
+func TestInOpening(t *testing.T) {
+	if !inOpening(0) || !inOpening(openingMoves-1) {
+		t.Error("early moves should be in the opening")
+	}
+	if inOpening(openingMoves) {
+		t.Errorf("move %d should be past the opening", openingMoves)
+	}
+}
+
+func TestEdgeLine(t *testing.T) {
+	tests := []struct{ x, y, size, want int }{
+		{0, 0, 9, 1},
+		{4, 0, 9, 1},
+		{8, 4, 9, 1},
+		{1, 4, 9, 2},
+		{4, 7, 9, 2},
+		{2, 2, 9, 3},
+		{4, 4, 9, 5},
+		{3, 15, 19, 4},
+	}
+	for _, tt := range tests {
+		if got := edgeLine(tt.x, tt.y, tt.size); got != tt.want {
+			t.Errorf("edgeLine(%d, %d, %d) = %d, want %d", tt.x, tt.y, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestIsolated(t *testing.T) {
+	board := types.NewBoardState(9).Board
+	board[2][2] = 2
+	if !isolated(board, 0, 0) || !isolated(board, 1, 1) {
+		t.Error("a diagonal stone isn't contact")
+	}
+	if isolated(board, 2, 1) || isolated(board, 3, 2) {
+		t.Error("a stone next door is contact")
+	}
+}
+
+func TestEdgeHint(t *testing.T) {
+	board := types.NewBoardState(9).Board
+	board[1][4] = 2
+	tests := []struct {
+		x, y, move int
+		want       string // substring; "" means no hint
+	}{
+		{4, 8, 3, "first-line"},
+		{1, 5, 3, "second-line"},
+		{2, 2, 3, ""},            // third line is fine
+		{4, 8, openingMoves, ""}, // too late to matter
+		{4, 0, 3, ""},            // answers the stone at 4,1
+		{-1, -1, 3, ""},          // a pass
+	}
+	for _, tt := range tests {
+		got := edgeHint(board, tt.x, tt.y, tt.move)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("edgeHint(%d, %d, move %d) = %q, want %q", tt.x, tt.y, tt.move, got, tt.want)
+		}
+	}
+}
+
+func TestBeginnerTipLimit(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.cfg.BeginnerHints = true
+
+	play := func(x, y, color int) {
+		bs := types.NewBoardState(9)
+		bs.MoveNumber = 1
+		bs.Board[y][x] = color
+		eng.onMove(x, y, color, bs)
+		g.BoardState = types.NewBoardState(9) // keep the position empty
+	}
+	for i := 0; i < maxEdgeHints; i++ {
+		play(0, 4, 1)
+		if g.tip == "" {
+			t.Fatalf("no tip for edge move %d", i+1)
+		}
+		play(4, 4, 2) // the engine's reply leaves the tip up
+		if g.tip == "" {
+			t.Fatal("the engine's move cleared the tip")
+		}
+	}
+	play(0, 4, 1)
+	if g.tip != "" || g.edgeHints != maxEdgeHints {
+		t.Errorf("tip %q after %d hints, want none", g.tip, g.edgeHints)
+	}
+}
@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"termsuji-local/config"
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+)
+
+// Before passing, the player can ask where the engine thinks the borders
+// run. Its dead stones are dimmed and the rim of each side's area is
+// tinted, labelled as the engine's view rather than a count.
+
+// borders keeps the points of owner that lie on the rim of an area: owned
+// points next to anything that isn't the same side's area.
+func borders(owner [][]int) [][]int {
+	rim := sgf.MakeBoard(len(owner))
+	for y, row := range owner {
+		for x, o := range row {
+			if o == 0 {
+				continue
+			}
+			for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				nx, ny := x+d[0], y+d[1]
+				if ny < 0 || ny >= len(owner) || nx < 0 || nx >= len(owner[ny]) {
+					continue
+				}
+				if owner[ny][nx] != o {
+					rim[y][x] = o
+					break
+				}
+			}
+		}
+	}
+	return rim
+}
+
+// ToggleEstimate asks the engine for its dead stones and shows the borders
+// that follow, or hides them if they are showing. The engine is asked
+// through the estimate scheduler, off the UI goroutine; an answer for a
+// position that has since changed is dropped.
+func (g *GoBoardUI) ToggleEstimate() {
+	if g.estimate != nil {
+		g.clearEstimate()
+		g.refreshHint()
+		return
+	}
+	if g.estimating || !g.CanAnalyze() {
+		return
+	}
+	g.estimating = true
+	g.estimateSched.Request(g.historyMoves())
+	g.refreshHint()
+}
+
+// evaluateEstimate returns the estimate scheduler's evaluation for eng,
+// which is already at the position asked about.
+func (g *GoBoardUI) evaluateEstimate(eng engine.GameEngine) func(gen uint64, moves [][3]int) {
+	return func(gen uint64, moves [][3]int) {
+		can := eng.CanEstimate()
+		dead, err := eng.EstimateDead()
+		g.app.QueueUpdateDraw(func() {
+			g.showEstimate(eng, gen, dead, err, can)
+		})
+	}
+}
+
+// showEstimate takes the engine's dead stones for request gen: as borders
+// if the player asked for them, and as the result of the engine's pass if
+// that was waiting on them. A stale answer, for a position since left or
+// from an engine since replaced, is dropped.
+func (g *GoBoardUI) showEstimate(eng engine.GameEngine, gen uint64, dead [][2]int, err error, canEstimate bool) {
+	if g.eng != eng || g.estimateSched == nil || !g.estimateSched.Current(gen) {
+		return
+	}
+	wantBorders, wantPass := g.estimating, g.passEstimating
+	g.estimating, g.passEstimating = false, false
+	if errors.Is(err, engine.ErrClosed) {
+		return
+	}
+	if wantBorders {
+		if err != nil {
+			g.notice = "no estimate: " + err.Error()
+		} else {
+			g.setEstimate(dead)
+		}
+	}
+	// Engines that can't say find nothing dead, which is no estimate
+	if wantPass && err == nil && canEstimate {
+		bs := g.BoardState
+		g.passEstimate = g.ruleset().Score(bs.Board, dead, bs.CapturesBlack, bs.CapturesWhite, g.gameConfig.Komi).LocalResult()
+	}
+	g.refreshHint()
+}
+
+// historyMoves returns the moves played so far as (color, x, y).
+func (g *GoBoardUI) historyMoves() [][3]int {
+	g.histMu.Lock()
+	defer g.histMu.Unlock()
+	moves := make([][3]int, len(g.moveHistory))
+	for i, m := range g.moveHistory {
+		moves[i] = [3]int{m.Color, m.X, m.Y}
+	}
+	return moves
+}
+
+// setEstimate shows the borders of the current position with dead taken
+// off the board.
+func (g *GoBoardUI) setEstimate(dead [][2]int) {
+	g.estimateDead = make(map[[2]int]bool, len(dead))
+	for _, p := range dead {
+		g.estimateDead[p] = true
+	}
+	g.estimate = borders(sgf.Territory(g.BoardState.Board, dead))
+}
+
+// clearEstimate hides the engine's view, and drops any request for it
+// not yet answered.
+func (g *GoBoardUI) clearEstimate() {
+	g.estimate = nil
+	g.estimateDead = nil
+	g.estimating = false
+	g.passEstimating = false
+	if g.estimateSched != nil {
+		g.estimateSched.Cancel()
+	}
+}
+
+// estimateOverlay returns the border owners to tint, or nil when there is
+// no estimate on show.
+func (g *GoBoardUI) estimateOverlay() [][]int {
+	if g.planningMode {
+		return nil
+	}
+	return g.estimate
+}
+
+// estimateResult is the result the engine's view would give, for the hint
+// bar.
+func (g *GoBoardUI) estimateResult() string {
+	dead := make([][2]int, 0, len(g.estimateDead))
+	for p := range g.estimateDead {
+		dead = append(dead, p)
+	}
+	bs := g.BoardState
+	return g.ruleset().Score(bs.Board, dead, bs.CapturesBlack, bs.CapturesWhite, g.gameConfig.Komi).LocalResult()
+}
+
+// lateGameMove is the move number after which the engine passing may mean
+// the game is over rather than a tactical choice: a third of the points.
+func lateGameMove(size int) int {
+	return size * size / 3
+}
+
+// passMessage is the hint bar's note on the engine passing at moveNumber,
+// given the result it estimates. It is "" for a pass too early to read
+// anything into, or without an estimate; the plain note applies then.
+func passMessage(engineName string, moveNumber, size int, estimate string) string {
+	if estimate == "" || moveNumber <= lateGameMove(size) {
+		return ""
+	}
+	return fmt.Sprintf("%s passed — it thinks the game is over (est. %s). Pass to score, or keep playing.", engineName, estimate)
+}
+
+// estimatePass asks the engine, which just passed, what the result would
+// be, for passMessage. Like ToggleEstimate, it goes through the estimate
+// scheduler.
+func (g *GoBoardUI) estimatePass() {
+	g.passEstimating = true
+	g.estimateSched.Request(g.historyMoves())
+}
+
+// engineName names the opponent in messages.
+func (g *GoBoardUI) engineName() string {
+	if g.gameConfig.EngineName != "" {
+		return g.gameConfig.EngineName
+	}
+	return config.GnuGoProfile
+}
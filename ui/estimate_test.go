@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/types"
+)
+
+func TestBorders(t *testing.T) {
+	owner := [][]int{
+		{1, 1, 1, 0, 2},
+		{1, 1, 1, 0, 2},
+		{1, 1, 1, 0, 2},
+		{0, 0, 0, 0, 2},
+		{2, 2, 2, 2, 2},
+	}
+	want := [][]int{
+		{0, 0, 1, 0, 2},
+		{0, 0, 1, 0, 2},
+		{1, 1, 1, 0, 2},
+		{0, 0, 0, 0, 2},
+		{2, 2, 2, 2, 0},
+	}
+	if got := borders(owner); !reflect.DeepEqual(got, want) {
+		t.Errorf("borders =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestEstimateOverlay(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, Komi: 6.5}, 0)
+	bs := types.NewBoardState(9)
+	for y := 0; y < 9; y++ {
+		bs.Board[y][4] = 1 // a wall down the middle
+	}
+	bs.Board[0][0] = 2
+	g.BoardState = bs
+
+	g.setEstimate([][2]int{{0, 0}})
+	if !g.estimateDead[[2]int{0, 0}] {
+		t.Error("the engine's dead stone should be marked")
+	}
+	// With the white stone dead the whole board is Black's.
+	g.refreshHint()
+	if hint := g.hint.GetText(true); !strings.Contains(hint, "engine's view — not final") || !strings.Contains(hint, "Black wins") {
+		t.Errorf("hint %q", hint)
+	}
+	// Only the points along the wall are a rim; the dead stone's point is
+	// inside Black's area.
+	if g.estimate[4][3] != 1 || g.estimate[4][5] != 1 || g.estimate[0][0] != 0 || g.estimate[4][2] != 0 {
+		t.Errorf("rim: %v", g.estimate)
+	}
+
+	eng.onMove(3, 3, 1, types.NewBoardState(9))
+	if g.estimate != nil || g.estimateDead != nil {
+		t.Error("a move should clear the engine's view")
+	}
+}
+
+func TestPassMessage(t *testing.T) {
+	for _, tc := range []struct {
+		move, size int
+		estimate   string
+		rich       bool
+	}{
+		{150, 19, "W+12", true},
+		{40, 19, "W+12", false}, // an early tactical pass
+		{121, 19, "B+3.5", true},
+		{120, 19, "B+3.5", false}, // right at the threshold
+		{30, 9, "B+3.5", true},
+		{30, 13, "B+3.5", false},
+		{200, 19, "", false}, // no estimate from the engine
+	} {
+		got := passMessage("GnuGo", tc.move, tc.size, tc.estimate)
+		if tc.rich != (got != "") {
+			t.Errorf("passMessage at move %d on %d×%d, estimate %q = %q", tc.move, tc.size, tc.size, tc.estimate, got)
+		}
+	}
+	want := "GnuGo passed — it thinks the game is over (est. W+12). Pass to score, or keep playing."
+	if got := passMessage("GnuGo", 150, 19, "W+12"); got != want {
+		t.Errorf("passMessage = %q, want %q", got, want)
+	}
+}
+
+// Estimates go through the scheduler, whose generations count from 1 with
+// each request or cancellation; an answer for one since superseded is
+// dropped.
+func TestEstimateDropsStaleAnswers(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, Komi: 6.5}, 0)
+	dead := [][2]int{{0, 0}}
+
+	g.ToggleEstimate() // generation 1
+	if !g.estimating {
+		t.Fatal("ToggleEstimate should wait on the engine")
+	}
+	eng.onMove(3, 3, 1, types.NewBoardState(9)) // cancelled: 2
+	if g.estimating {
+		t.Error("a move should drop the request")
+	}
+	g.showEstimate(eng, 1, dead, nil, true)
+	if g.estimate != nil {
+		t.Error("an answer for the position before the move was shown")
+	}
+
+	g.ToggleEstimate() // 3
+	g.showEstimate(eng, 3, dead, nil, true)
+	if g.estimate == nil || !g.estimateDead[[2]int{0, 0}] || g.estimating {
+		t.Errorf("current answer not shown: estimate %v, still waiting %v", g.estimate, g.estimating)
+	}
+
+	// The engine passing late in the game asks too, for the pass message
+	late := types.NewBoardState(9)
+	late.MoveNumber = lateGameMove(9) + 1
+	eng.onMove(-1, -1, 2, late) // cancelled: 4, then asked: 5
+	if !g.passEstimating {
+		t.Fatal("a late pass should ask for the engine's estimate")
+	}
+	g.showEstimate(eng, 5, nil, nil, true)
+	if g.passEstimate == "" || g.estimate != nil {
+		t.Errorf("pass estimate %q, borders %v; want only the pass estimate", g.passEstimate, g.estimate)
+	}
+}
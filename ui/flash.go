@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"termsuji-local/coords"
+	"termsuji-local/engine"
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+)
+
+// flashDuration is how long a refused move's message stays in the hint
+// bar if no key is pressed first.
+const flashDuration = 2 * time.Second
+
+// flashError shows why a move at (x, y) was refused, tinting the point,
+// until the next key or flashDuration, whichever comes first. x is -1
+// for a refusal that isn't about a point. Runs on the UI goroutine.
+func (g *GoBoardUI) flashError(msg string, x, y int) {
+	g.flashNote = false
+	g.showFlash(msg, x, y)
+}
+
+// flashNotice shows msg, e.g. that something was copied, for as long as
+// flashError would, but as news rather than a refusal.
+func (g *GoBoardUI) flashNotice(msg string) {
+	g.flashNote = true
+	g.showFlash(msg, -1, -1)
+}
+
+func (g *GoBoardUI) showFlash(msg string, x, y int) {
+	g.flash = msg
+	g.flashAt = [2]int{x, y}
+	g.flashSeq++
+	seq := g.flashSeq
+	g.refreshHint()
+	if g.app == nil {
+		return
+	}
+	time.AfterFunc(flashDuration, func() {
+		g.app.QueueUpdateDraw(func() {
+			// A later flash keeps its own two seconds
+			if g.flashSeq == seq {
+				g.ClearFlash()
+			}
+		})
+	})
+}
+
+// ClearFlash drops the message about a refused move, if one is showing.
+func (g *GoBoardUI) ClearFlash() {
+	if g.flash == "" {
+		return
+	}
+	g.flash = ""
+	g.flashAt = [2]int{-1, -1}
+	g.refreshHint()
+}
+
+// flashing reports whether (x, y) is the point of a refused move.
+func (g *GoBoardUI) flashing(x, y int) bool {
+	return g.flash != "" && g.flashAt == [2]int{x, y}
+}
+
+// moveError says why the engine refused the player's move at (x, y).
+// For an illegal move the board itself usually tells why; otherwise the
+// engine's own words are passed on.
+func (g *GoBoardUI) moveError(err error, x, y int) string {
+	switch {
+	case errors.Is(err, engine.ErrNotYourTurn):
+		return "Not your turn: " + g.engineName() + " is thinking"
+	case errors.Is(err, engine.ErrGameOver):
+		return "The game is over"
+	}
+	if g.BoardState == nil {
+		return "Move refused: " + err.Error()
+	}
+	bs := g.BoardState
+	point := coords.Display(x, y, bs.Width())
+	pos := rules.NewPosition(bs.Board)
+	switch _, why := g.ruleset().ApplyMove(pos, x, y, g.eng.GetPlayerColor()); {
+	case errors.Is(why, rules.ErrOccupied):
+		return fmt.Sprintf("Illegal move: %s is occupied", point)
+	case errors.Is(why, rules.ErrSuicide):
+		return fmt.Sprintf("Illegal move: %s would be suicide", point)
+	case why != nil:
+	case pos.Ko[0] >= 0 && pos.Ko == [2]int{bs.LastMove.X, bs.LastMove.Y}:
+		// Taking back the stone that just took ours
+		return fmt.Sprintf("Illegal move: %s retakes the ko; play elsewhere first", point)
+	default:
+		if n := g.repeatOf(sgf.ZobristHash(pos.Board, pos.Size())); n > 0 {
+			return fmt.Sprintf("Illegal move: %s repeats the position after move %d", point, n)
+		}
+	}
+	reason := strings.TrimPrefix(err.Error(), engine.ErrIllegalMove.Error()+": ")
+	return fmt.Sprintf("Illegal move at %s: %s", point, reason)
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+)
+
+func TestRefusedMoveSaysWhy(t *testing.T) {
+	refused := fmt.Errorf("%w: GTP error: illegal move", engine.ErrIllegalMove)
+	tests := []struct {
+		name   string
+		stones [][3]int // x, y, color
+		last   [2]int
+		x, y   int
+		err    error
+		want   string
+	}{
+		{"occupied", [][3]int{{2, 2, 2}}, [2]int{2, 2}, 2, 2, refused, "Illegal move: C7 is occupied"},
+		{"suicide", [][3]int{{1, 0, 2}, {0, 1, 2}}, [2]int{0, 1}, 0, 0, refused, "Illegal move: A9 would be suicide"},
+		// White's C8 just took the black stone on D8
+		{"ko", [][3]int{{2, 1, 2}, {3, 0, 2}, {4, 1, 2}, {3, 2, 2}, {2, 0, 1}, {1, 1, 1}, {2, 2, 1}},
+			[2]int{2, 1}, 3, 1, refused, "Illegal move: D8 retakes the ko; play elsewhere first"},
+		{"engine's reason", nil, [2]int{-1, -1}, 4, 4, refused, "Illegal move at E5: GTP error: illegal move"},
+		{"not your turn", nil, [2]int{-1, -1}, 4, 4, engine.ErrNotYourTurn, "Not your turn: GnuGo is thinking"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+			for _, s := range tt.stones {
+				eng.state.Board[s[1]][s[0]] = s[2]
+			}
+			eng.state.LastMove.X, eng.state.LastMove.Y = tt.last[0], tt.last[1]
+			g.BoardState = eng.state
+			eng.playErr = tt.err
+
+			g.PlayMove(tt.x, tt.y)
+			if g.flash != tt.want {
+				t.Errorf("flash = %q, want %q", g.flash, tt.want)
+			}
+			if !strings.Contains(g.hint.GetText(true), tt.want) {
+				t.Errorf("hint = %q, want the flash in it", g.hint.GetText(true))
+			}
+			illegal := tt.err != engine.ErrNotYourTurn
+			if g.flashing(tt.x, tt.y) != illegal {
+				t.Errorf("flashing(%d, %d) = %v, want %v", tt.x, tt.y, !illegal, illegal)
+			}
+
+			g.ClearFlash()
+			if g.flash != "" || strings.Contains(g.hint.GetText(true), tt.want) {
+				t.Errorf("flash left after ClearFlash: %q", g.hint.GetText(true))
+			}
+		})
+	}
+}
+
+func TestMoveAfterGameOver(t *testing.T) {
+	g, _ := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.finished = true
+	g.PlayMove(4, 4)
+	if g.flash != "The game is over" {
+		t.Errorf("flash = %q, want the game over", g.flash)
+	}
+
+	// A new game starts without it
+	g.resetGame()
+	if g.flash != "" {
+		t.Errorf("flash = %q after reset", g.flash)
+	}
+}
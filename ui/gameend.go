@@ -0,0 +1,22 @@
+package ui
+
+import (
+	"io"
+	"log"
+)
+
+var debugLog = log.New(io.Discard, "", 0)
+
+// SetDebugLog sends notes on engine reports the board drops, such as a move
+// that arrives after the game ended, to w. They are off by default.
+func SetDebugLog(w io.Writer) {
+	debugLog = log.New(w, "", log.Ltime|log.Lmicroseconds)
+}
+
+// ended reports whether the game is over, for the UI goroutine while an
+// engine callback may be ending it.
+func (g *GoBoardUI) ended() bool {
+	g.stateMu.Lock()
+	defer g.stateMu.Unlock()
+	return g.finished
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+// racingEngine reports moves the way the GTP engine does: it takes the
+// move under its own lock, then calls back outside it, so the game can end
+// in between.
+type racingEngine struct {
+	*fakeEngine
+	mu    sync.Mutex
+	over  bool
+	moves int
+}
+
+func (r *racingEngine) PlayMove(x, y int) error {
+	r.mu.Lock()
+	if r.over {
+		r.mu.Unlock()
+		return engine.ErrGameOver
+	}
+	r.moves++
+	bs := types.NewBoardState(9)
+	bs.MoveNumber = r.moves
+	r.mu.Unlock()
+
+	r.onMove(x, y, r.moves%2+1, bs)
+	return nil
+}
+
+func (r *racingEngine) IsMyTurn() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.over
+}
+
+// resign ends the game as the player's resignation does, off the UI
+// goroutine.
+func (r *racingEngine) resign() {
+	r.mu.Lock()
+	r.over = true
+	r.mu.Unlock()
+	r.onGameEnd("White wins by resignation")
+}
+
+func TestGameEndRacesMoves(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		eng := &racingEngine{fakeEngine: newFakeEngine(9)}
+		g, _ := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+		if err := g.ConnectEngine(eng); err != nil {
+			t.Fatalf("ConnectEngine: %v", err)
+		}
+		rec, err := sgf.NewGameRecord(t.TempDir(), 9, 6.5, 1, 5, sgf.RecordOptions{})
+		if err != nil {
+			t.Fatalf("NewGameRecord: %v", err)
+		}
+		path := rec.FilePath
+		g.SetRecorder(rec)
+
+		// A burst of moves reported by the engine, the resignation on
+		// another goroutine
+		var atEnd int
+		var wg sync.WaitGroup
+		wg.Add(1)
+		delay := time.Duration(rng.Intn(300)) * time.Microsecond
+		go func() {
+			defer wg.Done()
+			time.Sleep(delay)
+			eng.resign()
+			atEnd = len(g.MovesSnapshot())
+		}()
+		for i := 0; i < 81; i++ {
+			eng.PlayMove(i%9, i/9)
+		}
+		wg.Wait()
+
+		if !g.IsFinished() {
+			t.Fatalf("round %d: game not finished", round)
+		}
+		history := g.MovesSnapshot()
+		if len(history) != atEnd {
+			t.Fatalf("round %d: %d moves in the history after the game ended with %d", round, len(history), atEnd)
+		}
+		g.Close()
+		recorded, err := sgf.ParseMovesAsEntries(path)
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if len(recorded) != len(history) {
+			t.Fatalf("round %d: record has %d moves, history %d", round, len(recorded), len(history))
+		}
+		info, err := sgf.ParseHeader(path)
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if info.Result != "W+R" {
+			t.Fatalf("round %d: result %q, want W+R", round, info.Result)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"termsuji-local/sgf"
+)
+
+// GameList is the games in a history directory, listed on first use and
+// kept until Invalidate, so the screens that show them share one scan.
+// Headers are read as they are asked for and cached by path, modification
+// time and size, so a scan after Invalidate reads only the files that
+// changed.
+type GameList struct {
+	dir string
+
+	mu     sync.Mutex
+	files  []sgf.GameInfo // as ListGameFiles has them, newest first
+	err    error
+	loaded bool
+	cache  map[string]sgf.GameInfo // headers read, by path; with ReadErr set for a file that didn't parse
+}
+
+// NewGameList returns the list for dir, not yet scanned.
+func NewGameList(dir string) *GameList {
+	return &GameList{dir: dir, cache: make(map[string]sgf.GameInfo)}
+}
+
+// Files returns the history's files newest first, listing the directory if
+// it hasn't been since the list was made or last invalidated. Files whose
+// headers are cached come with them; the rest have BoardSize 0 until
+// Header reads them.
+func (l *GameList) Files() ([]sgf.GameInfo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.loaded {
+		l.files, l.err = sgf.ListGameFiles(l.dir)
+		l.loaded = true
+	}
+	files := make([]sgf.GameInfo, len(l.files))
+	for i, f := range l.files {
+		files[i] = f
+		if c, ok := l.cache[f.FilePath]; ok && sameFile(c, f) {
+			files[i] = c
+			files[i].HasReport = f.HasReport
+		}
+	}
+	return files, l.err
+}
+
+// Header returns the header of the game in file, from Files, reading it
+// unless it is cached from the file as it is now. It returns false for a
+// file that isn't a readable game record, as file with ReadErr saying why.
+func (l *GameList) Header(file sgf.GameInfo) (sgf.GameInfo, bool) {
+	l.mu.Lock()
+	c, ok := l.cache[file.FilePath]
+	l.mu.Unlock()
+	if ok && sameFile(c, file) {
+		c.HasReport = file.HasReport
+		return c, c.ReadErr == nil
+	}
+
+	if info, err := sgf.ParseHeader(file.FilePath); err == nil {
+		c = *info
+		c.ModTime, c.FileSize = file.ModTime, file.FileSize
+	} else {
+		c = file
+		c.ReadErr = err
+	}
+	c.HasReport = file.HasReport
+	l.mu.Lock()
+	l.cache[file.FilePath] = c
+	l.mu.Unlock()
+	return c, c.ReadErr == nil
+}
+
+// sameFile reports whether a header cached as c is still that of file.
+func sameFile(c, file sgf.GameInfo) bool {
+	return c.ModTime.Equal(file.ModTime) && c.FileSize == file.FileSize
+}
+
+// Games returns the games newest first, reading the headers not yet
+// cached.
+func (l *GameList) Games() ([]sgf.GameInfo, error) {
+	files, err := l.Files()
+	var games []sgf.GameInfo
+	for _, f := range files {
+		if g, ok := l.Header(f); ok {
+			games = append(games, g)
+		}
+	}
+	return games, err
+}
+
+// Invalidate makes the next Files list the directory again, after a game
+// is recorded, deleted or repaired.
+func (l *GameList) Invalidate() {
+	l.mu.Lock()
+	l.loaded = false
+	l.mu.Unlock()
+}
+
+// Latest returns the newest game, if there is one, reading only as many
+// headers as it takes to find it.
+func (l *GameList) Latest() (sgf.GameInfo, bool) {
+	files, err := l.Files()
+	if err != nil {
+		return sgf.GameInfo{}, false
+	}
+	for _, f := range files {
+		if g, ok := l.Header(f); ok {
+			return g, true
+		}
+	}
+	return sgf.GameInfo{}, false
+}
+
+// LastGameLabel sums up a game for the setup card, e.g.
+// "last: 9x9 L5 — B+3.5, 20m ago", as of now.
+func LastGameLabel(game sgf.GameInfo, now time.Time) string {
+	label := fmt.Sprintf("last: %dx%d", game.BoardSize, game.BoardSize)
+	if setup := sgf.InferSetup(game); setup.EngineLevel > 0 {
+		label += fmt.Sprintf(" L%d", setup.EngineLevel)
+	} else if setup.Opponent != "" {
+		label += " vs " + setup.Opponent
+	}
+	result := sgf.ShortResult(game.Result)
+	if game.Result == "" || game.Result == "?" {
+		result = "unfinished"
+	}
+	label += " — " + result
+	if named, ok := sgf.NameTime(game.FileName); ok {
+		// Records are named in local time
+		played := time.Date(named.Year(), named.Month(), named.Day(), named.Hour(), named.Minute(), named.Second(), 0, now.Location())
+		label += ", " + timeAgo(now.Sub(played))
+	}
+	return label
+}
+
+// resultSpan returns where the result is in a label from LastGameLabel,
+// as rune offsets: after the dash, up to the comma before the time.
+func resultSpan(label string) (from, to int) {
+	r := []rune(label)
+	for i := 0; i+1 < len(r); i++ {
+		if r[i] == '—' && r[i+1] == ' ' {
+			from = i + 2
+			break
+		}
+	}
+	if from == 0 {
+		return 0, 0
+	}
+	for to = from; to < len(r) && r[to] != ','; to++ {
+	}
+	return from, to
+}
+
+// timeAgo says how long ago something was, in its largest whole unit.
+func timeAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
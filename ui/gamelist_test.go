@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"termsuji-local/sgf"
+)
+
+func TestGameListScansOnce(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, result string) {
+		t.Helper()
+		data := "(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]RE[" + result + "];B[ee])"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("2026-01-15_093000_9x9.sgf", "B+3.5")
+
+	list := NewGameList(dir)
+	if games, err := list.Games(); err != nil || len(games) != 1 {
+		t.Fatalf("Games() = %d games, %v; want 1", len(games), err)
+	}
+
+	// A game recorded since isn't seen until the list is invalidated
+	write("2026-01-16_101500_9x9.sgf", "W+R")
+	if games, _ := list.Games(); len(games) != 1 {
+		t.Errorf("Games() rescanned: %d games", len(games))
+	}
+	list.Invalidate()
+	latest, ok := list.Latest()
+	if !ok || latest.Result != "W+R" {
+		t.Errorf("Latest() = %q, %v after invalidating; want the W+R game", latest.Result, ok)
+	}
+
+	if _, ok := NewGameList(filepath.Join(dir, "none")).Latest(); ok {
+		t.Error("Latest() found a game in a missing directory")
+	}
+}
+
+func TestGameListCachesHeaders(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "2026-01-15_093000_9x9.sgf")
+	write := func(result string) {
+		t.Helper()
+		data := "(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5]RE[" + result + "];B[ee])"
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("B+3.5")
+
+	list := NewGameList(dir)
+	files, _ := list.Files()
+	if len(files) != 1 || files[0].BoardSize != 0 || files[0].Date != "2026-01-15" {
+		t.Fatalf("Files() = %+v; want the file unread, dated by its name", files)
+	}
+	if g, ok := list.Header(files[0]); !ok || g.Result != "B+3.5" {
+		t.Errorf("Header() = %q, %v", g.Result, ok)
+	}
+
+	// Listed again, the read header comes with the file
+	list.Invalidate()
+	if files, _ := list.Files(); files[0].Result != "B+3.5" {
+		t.Errorf("cached header not listed: %+v", files[0])
+	}
+
+	// Until the file changes
+	write("W+R")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	list.Invalidate()
+	files, _ = list.Files()
+	if files[0].BoardSize != 0 {
+		t.Errorf("stale header listed for a changed file: %+v", files[0])
+	}
+	if g, _ := list.Header(files[0]); g.Result != "W+R" {
+		t.Errorf("Header() = %q after the change, want W+R", g.Result)
+	}
+}
+
+func TestLastGameLabel(t *testing.T) {
+	now := time.Date(2026, 1, 15, 9, 50, 0, 0, time.Local)
+	tests := []struct {
+		game sgf.GameInfo
+		want string
+	}{
+		{sgf.GameInfo{FileName: "2026-01-15_093000_9x9.sgf", BoardSize: 9, PlayerBlack: "Player", PlayerWhite: "GnuGo Level 5", Result: "B+3.5"},
+			"last: 9x9 L5 — B+3.5, 20m ago"},
+		{sgf.GameInfo{FileName: "2026-01-12_200000_19x19.sgf", BoardSize: 19, PlayerBlack: "KataGo", PlayerWhite: "Player", Result: "?"},
+			"last: 19x19 vs KataGo — unfinished, 2d ago"},
+		{sgf.GameInfo{FileName: "imported.sgf", BoardSize: 13, PlayerBlack: "GnuGo Level 3", PlayerWhite: "Player", Result: "W+R"},
+			"last: 13x13 L3 — W+R"},
+		{sgf.GameInfo{FileName: "jigo.sgf", BoardSize: 9, PlayerBlack: "Player", PlayerWhite: "GnuGo Level 5", Result: "0"},
+			"last: 9x9 L5 — Draw"},
+	}
+	for _, tt := range tests {
+		if got := LastGameLabel(tt.game, now); got != tt.want {
+			t.Errorf("LastGameLabel(%s) = %q, want %q", tt.game.FileName, got, tt.want)
+		}
+	}
+}
+
+func TestResultSpan(t *testing.T) {
+	for _, tt := range []struct {
+		label  string
+		result string
+	}{
+		{"last: 9x9 L5 — B+3.5, 20m ago", "B+3.5"},
+		{"last: 13x13 L3 — W+R", "W+R"},
+		{"no result here", ""},
+	} {
+		from, to := resultSpan(tt.label)
+		if got := string([]rune(tt.label)[from:to]); got != tt.result {
+			t.Errorf("resultSpan(%q) covers %q, want %q", tt.label, got, tt.result)
+		}
+	}
+}
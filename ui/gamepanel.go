@@ -5,7 +5,8 @@ import (
 
 	"github.com/rivo/tview"
 
-	"termsuji-local/engine/gtp"
+	"termsuji-local/coords"
+	"termsuji-local/engine"
 	"termsuji-local/sgf"
 	"termsuji-local/types"
 )
@@ -18,13 +19,28 @@ type GameInfoPanel struct {
 	moveHistory *[]MoveEntry
 	boardSize   int
 	planTree    *sgf.GameTree // non-nil when in planning mode
+	// the engine's suggestions on show, best first
+	candidates []engine.Candidate
+	// stones taken off the board by black and by white
+	captures [2]int
+	// moves up to the one marked in the list; -1 marks the latest
+	current int
+	// time left for black and white, "" in an untimed game, and whose
+	// clock is running
+	clocks    [2]string
+	clockTurn int
+	// who the game is against and how it was set up, "" for nothing
+	header string
+	// draw with ASCII stand-ins, as the board is drawn
+	ascii bool
 }
 
 // NewGameInfoPanel creates a new game info panel.
 func NewGameInfoPanel() *GameInfoPanel {
 	panel := &GameInfoPanel{
-		box:  tview.NewTextView(),
-		komi: 6.5,
+		box:     tview.NewTextView(),
+		komi:    6.5,
+		current: -1,
 	}
 
 	panel.box.SetDynamicColors(true)
@@ -45,6 +61,13 @@ func (p *GameInfoPanel) SetBoardState(state *types.BoardState) {
 	p.refresh()
 }
 
+// SetHeader sets the line shown above the game info, naming the
+// opponent and the game's settings.
+func (p *GameInfoPanel) SetHeader(header string) {
+	p.header = header
+	p.refresh()
+}
+
 // SetKomi sets the komi value for display.
 func (p *GameInfoPanel) SetKomi(komi float64) {
 	p.komi = komi
@@ -67,6 +90,37 @@ func (p *GameInfoPanel) ClearPlanningMode() {
 	p.planTree = nil
 }
 
+// SetCaptures sets the capture counts shown, the plan board's in planning
+// mode.
+func (p *GameInfoPanel) SetCaptures(black, white int) {
+	p.captures = [2]int{black, white}
+}
+
+// SetClocks shows the time each side has left, toMove's clock running;
+// empty strings hide them.
+func (p *GameInfoPanel) SetClocks(black, white string, toMove int) {
+	p.clocks = [2]string{black, white}
+	p.clockTurn = toMove
+	p.refresh()
+}
+
+// SetCurrentMove marks the nth move in the list, keeping it in view, or
+// the latest for -1.
+func (p *GameInfoPanel) SetCurrentMove(n int) {
+	p.current = n
+}
+
+// setASCII has the panel draw its rules, stones and marks in ASCII.
+func (p *GameInfoPanel) setASCII(on bool) {
+	p.ascii = on
+}
+
+// SetCandidates lists the engine's suggested moves, or none for nil.
+func (p *GameInfoPanel) SetCandidates(moves []engine.Candidate) {
+	p.candidates = moves
+	p.refresh()
+}
+
 // refresh updates the panel text.
 func (p *GameInfoPanel) refresh() {
 	if p.boardState == nil {
@@ -76,29 +130,58 @@ func (p *GameInfoPanel) refresh() {
 
 	var text string
 
+	// Who this game is against
+	if p.header != "" {
+		text += tag("yellow::b", tview.Escape(p.header)) + "\n\n"
+	}
+
 	// Game Info section
-	text += "[white::b]Game Info[-:-:-]\n"
-	text += "[dimgray]──────────────────────[-:-:-]\n"
+	text += tag("white::b", "Game Info") + "\n"
+	text += tag("dimgray", "──────────────────────") + "\n"
 
 	// Komi
-	text += fmt.Sprintf("[white]Komi:[-:-:-] %.1f\n", p.komi)
+	text += fmt.Sprintf("%s %s\n", tag("white", "Komi:"), sgf.FormatKomi(p.komi))
 
 	// Move count
-	text += fmt.Sprintf("[white]Move:[-:-:-] %d\n", p.boardState.MoveNumber)
+	text += fmt.Sprintf("%s %d\n", tag("white", "Move:"), p.boardState.MoveNumber)
+
+	// Prisoners taken by each side
+	text += fmt.Sprintf("%s B %d / W %d\n", tag("white", "Captures:"), p.captures[0], p.captures[1])
+
+	// Time left, the running clock highlighted
+	if p.clocks[0] != "" {
+		clock := func(color int, stone string) string {
+			if p.clockTurn == color {
+				return tag("yellow::b", stone+" "+p.clocks[color-1])
+			}
+			return stone + " " + p.clocks[color-1]
+		}
+		text += fmt.Sprintf("%s %s  %s\n", tag("white", "Clock:"), clock(1, "●"), clock(2, "○"))
+	}
+
+	// Analysis: the engine's candidates with its values for them
+	if len(p.candidates) > 0 && p.planTree == nil {
+		text += "\n" + tag("yellow::b", "Candidates") + "\n"
+		text += tag("dimgray", "──────────────────────") + "\n"
+		for i, c := range p.candidates {
+			text += fmt.Sprintf(" %s %-4s %s\n", tag("dimgray", fmt.Sprintf("%d.", i+1)),
+				coords.Display(c.X, c.Y, p.boardState.Width()), tag("dimgray", fmt.Sprintf("%.2f", c.Value)))
+		}
+	}
 
 	// Planning mode: show exploration path
 	if p.planTree != nil {
-		text += "\n[yellow::b]PLAN[-:-:-]\n"
-		text += "[dimgray]──────────────────────[-:-:-]\n"
+		text += "\n" + tag("yellow::b", "PLAN") + "\n"
+		text += tag("dimgray", "──────────────────────") + "\n"
 
 		// Show variation info
 		if p.planTree.NumVariations() > 1 {
-			text += fmt.Sprintf("[dimgray]var %d/%d[-]\n", p.planTree.VariationIndex()+1, p.planTree.NumVariations())
+			text += tag("dimgray", fmt.Sprintf("var %d/%d", p.planTree.VariationIndex()+1, p.planTree.NumVariations())) + "\n"
 		}
 
 		path := p.planTree.PathFromRoot()
 		if len(path) == 0 {
-			text += "[dimgray]  (no moves)[-]\n"
+			text += tag("dimgray", "  (no moves)") + "\n"
 		} else {
 			maxVisible := 12
 			start := 0
@@ -113,83 +196,107 @@ func (p *GameInfoPanel) refresh() {
 				color, x, y := parsePlanMoveForPanel(path[i])
 				moveNum := i + 1
 
-				colorStr := "[white]B[-]"
+				colorStr := tag("white", colorLetter(color))
 				if color == 2 {
-					colorStr = "[dimgray]W[-]"
+					colorStr = tag("dimgray", colorLetter(color))
 				}
 
-				coord := "pass"
-				if x >= 0 && y >= 0 {
-					size := p.boardSize
-					if p.boardState != nil && p.boardState.Width() > 0 {
-						size = p.boardState.Width()
-					}
-					if size > 0 {
-						coord = gtp.PosToGTPDisplay(x, y, size)
-					}
-				}
+				coord := moveCoord(x, y, p.size())
 
 				marker := " "
 				if i == currentIdx {
-					marker = "[yellow]>[-]"
+					marker = tag("yellow", ">")
 				}
 
-				text += fmt.Sprintf("%s[dimgray]%3d.[-] %s %s\n", marker, moveNum, colorStr, coord)
+				text += fmt.Sprintf("%s%s %s %s\n", marker, tag("dimgray", fmt.Sprintf("%3d.", moveNum)), colorStr, coord)
 			}
 
 			if start > 0 {
-				text += fmt.Sprintf("[dimgray]  ··· %d earlier[-]\n", start)
+				text += tag("dimgray", fmt.Sprintf("  ··· %d earlier", start)) + "\n"
 			}
 		}
 	} else if p.moveHistory != nil && len(*p.moveHistory) > 0 {
 		// Normal mode: show move history
-		text += "\n[white::b]Moves[-:-:-]\n"
-		text += "[dimgray]──────────────────────[-:-:-]\n"
+		text += "\n" + tag("white::b", "Moves") + "\n"
+		text += tag("dimgray", "──────────────────────") + "\n"
 
 		moves := *p.moveHistory
-		// Show last N moves that fit, with scroll
+		current := len(moves) - 1
+		if p.current >= 0 {
+			current = p.current - 1
+		}
+		// Show last N moves that fit, with scroll, or those around the
+		// marked move
 		maxVisible := 12
 		start := 0
 		if len(moves) > maxVisible {
 			start = len(moves) - maxVisible
+			if current < start {
+				start = current - maxVisible/2
+				if start < 0 {
+					start = 0
+				}
+			}
+		}
+		end := start + maxVisible
+		if end > len(moves) {
+			end = len(moves)
 		}
 
-		for i := start; i < len(moves); i++ {
+		for i := start; i < end; i++ {
 			m := moves[i]
 			moveNum := i + 1
 
-			colorStr := "[white]B[-]"
+			colorStr := tag("white", colorLetter(m.Color))
 			if m.Color == 2 {
-				colorStr = "[dimgray]W[-]"
+				colorStr = tag("dimgray", colorLetter(m.Color))
 			}
 
-			coord := "pass"
-			if m.X >= 0 && m.Y >= 0 {
-				size := p.boardSize
-				if p.boardState != nil && p.boardState.Width() > 0 {
-					size = p.boardState.Width()
-				}
-				if size > 0 {
-					coord = gtp.PosToGTPDisplay(m.X, m.Y, size)
-				}
+			coord := moveCoord(m.X, m.Y, p.size())
+
+			if m.Book {
+				coord += " " + tag("dimgray", "(book)")
+			}
+			if m.Comment != "" {
+				coord += " " + tag("yellow", "✎")
 			}
 
 			marker := " "
-			if i == len(moves)-1 {
-				marker = "[white]>[-]"
+			if i == current {
+				marker = tag("white", ">")
 			}
 
-			text += fmt.Sprintf("%s[dimgray]%3d.[-] %s %s\n", marker, moveNum, colorStr, coord)
+			text += fmt.Sprintf("%s%s %s %s\n", marker, tag("dimgray", fmt.Sprintf("%3d.", moveNum)), colorStr, coord)
 		}
 
 		if start > 0 {
-			text += fmt.Sprintf("[dimgray]  ··· %d earlier[-]\n", start)
+			text += tag("dimgray", fmt.Sprintf("  ··· %d earlier", start)) + "\n"
+		}
+		if end < len(moves) {
+			text += tag("dimgray", fmt.Sprintf("  ··· %d later", len(moves)-end)) + "\n"
+		}
+
+		// The marked move's comment, in full
+		if current >= 0 && current < len(moves) && moves[current].Comment != "" {
+			text += "\n" + tag("white::b", fmt.Sprintf("Comment on %d", current+1)) + "\n"
+			text += tview.Escape(moves[current].Comment) + "\n"
 		}
 	}
 
+	if p.ascii {
+		text = asciiText(text)
+	}
 	p.box.SetText(text)
 }
 
+// size is the board's width, from its state once there is one.
+func (p *GameInfoPanel) size() int {
+	if p.boardState != nil && p.boardState.Width() > 0 {
+		return p.boardState.Width()
+	}
+	return p.boardSize
+}
+
 // parsePlanMoveForPanel extracts color, x, y from an SGF move string like ";B[pd]".
 func parsePlanMoveForPanel(move string) (color, x, y int) {
 	if len(move) < 3 {
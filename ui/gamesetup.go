@@ -2,33 +2,98 @@
 package ui
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
 	"termsuji-local/engine"
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+)
+
+// defaultKomi is the komi of an even game under the first of setupRules.
+const defaultKomi = 6.5
+
+// setupRules are the rulesets on offer, the ones GnuGo plays by.
+var setupRules = []rules.Ruleset{rules.Japanese, rules.Chinese}
+
+// Names of the setup card's tabs, as passed to SetTab and the tab callback.
+const (
+	SetupTabQuick    = "quick"
+	SetupTabAdvanced = "advanced"
 )
 
+// setupTabs are the tab names in display order; tab indexes follow it.
+var setupTabs = []string{SetupTabQuick, SetupTabAdvanced}
+
+// timeControl is a choice of time controls on the card.
+type timeControl struct {
+	label     string
+	main      time.Duration
+	byoTime   time.Duration
+	byoStones int
+}
+
+// timeControls are the time controls on offer, untimed first.
+var timeControls = []timeControl{
+	{"none", 0, 0, 0},
+	{"10 min", 10 * time.Minute, 0, 0},
+	{"30 min", 30 * time.Minute, 0, 0},
+	{"10 min + 30s byo-yomi", 10 * time.Minute, 30 * time.Second, 1},
+	{"20 min + 5 min/25", 20 * time.Minute, 5 * time.Minute, 25},
+}
+
+// setupOption is a setting on the card: it takes focus and draws itself,
+// returning the rows used.
+type setupOption interface {
+	focusableComponent
+	Draw(screen tcell.Screen, x, y, width int) int
+}
+
 // GameSetupUI provides a styled card UI for configuring a new game.
 type GameSetupUI struct {
-	box      *tview.Box
-	flex     *tview.Flex
-	onStart  func(engine.GameConfig)
-	onCancel func()
-	onColors func()
+	box       *tview.Box
+	flex      *tview.Flex
+	innerFlex *tview.Flex
+	helpText  *tview.TextView // the keys under the card, after any notice
+	onStart   func(engine.GameConfig)
+	onCancel  func()
+	onColors  func()
 	onHistory func()
+	onAbout   func()
+	onHelp    func()
+	onLoad    func()
+	onTab     func(string)
 
 	// Components
-	card          *MenuCard
-	boardSelect   *RadioSelect
-	colorSelect   *RadioSelect
-	levelSlider   *LevelSlider
-	komiInput     *KomiInput
-	playButton    *MenuButton
-	historyButton *MenuButton
-	colorButton   *MenuButton
-	quitButton    *MenuButton
-
-	// Focus management
+	card           *MenuCard
+	boardSelect    *RadioSelect
+	colorSelect    *RadioSelect
+	levelSlider    *LevelSlider
+	handicapSel    *ValueSelect
+	komiInput      *KomiInput
+	timeSelect     *ValueSelect
+	rulesSelect    *ValueSelect
+	engineSelect   *ValueSelect // only on the card when more than one engine is configured
+	confirmSel     *ValueSelect // last on the Advanced tab once SetConfirmMoves gives it a callback
+	recordSel      *ValueSelect // after confirmSel once SetRecordGames gives it a callback
+	nameInput      *NameInput   // last on the Advanced tab once SetPlayerName gives it a callback
+	continueButton *MenuButton  // above the tabs while there is a game to continue
+	lastGame       string       // summary of the newest game, under the buttons; "" when there is none
+	onLastGame     func()       // opens the newest game for review, on l
+	lastOutcome    sgf.Outcome  // how the newest game went, to tint its result
+	playButton     *MenuButton
+	historyButton  *MenuButton
+	colorButton    *MenuButton
+	quitButton     *MenuButton
+
+	// Tabs: the settings shown on each, and the active one
+	tabOptions [][]setupOption
+	tab        int
+
+	// Focus management; rebuilt from the active tab's options
 	focusIndex int
 	focusables []focusableComponent
 
@@ -36,7 +101,13 @@ type GameSetupUI struct {
 	boardSize   int
 	playerColor int
 	level       int
+	handicap    int
 	komi        float64
+	timeControl int      // index into timeControls
+	rules       int      // index into setupRules
+	engines     []string // names of the engines on offer; empty means GnuGo only
+	engine      int      // index into engines
+	keepKomi    bool     // PLAY was pressed with a komi unsuited to the handicap; pressing again keeps it
 }
 
 // focusableComponent wraps different component types for focus management.
@@ -55,7 +126,7 @@ func NewGameSetup(onStart func(engine.GameConfig), onCancel func(), onColors fun
 		boardSize:   19,
 		playerColor: 1,
 		level:       5,
-		komi:        6.5,
+		komi:        defaultKomi,
 	}
 
 	// Create card container
@@ -85,6 +156,7 @@ func NewGameSetup(onStart func(engine.GameConfig), onCancel func(), onColors fun
 	}
 	setup.colorSelect = NewRadioSelect("Your Color", colorOptions, 0, func(idx int) {
 		setup.playerColor = idx + 1 // 1=black, 2=white
+		setup.setupChanged()
 	})
 
 	// Level slider
@@ -92,20 +164,54 @@ func NewGameSetup(onStart func(engine.GameConfig), onCancel func(), onColors fun
 		setup.level = level
 	})
 
+	// Handicap: none or 2-9 stones, always Black's
+	setup.handicapSel = NewValueSelect("Handicap", []int{0, 2, 3, 4, 5, 6, 7, 8, 9}, 0, setup.formatHandicap, setup.setHandicap)
+
 	// Komi input
-	setup.komiInput = NewKomiInput("Komi", 6.5, func(komi float64) {
+	setup.komiInput = NewKomiInput("Komi", defaultKomi, func(komi float64) {
 		setup.komi = komi
+		setup.setupChanged()
 	})
 
+	// Time controls, by index into timeControls
+	timeValues := make([]int, len(timeControls))
+	for i := range timeValues {
+		timeValues[i] = i
+	}
+	setup.timeSelect = NewValueSelect("Time", timeValues, 0, func(i int) string { return timeControls[i].label }, func(i int) {
+		setup.timeControl = i
+	})
+
+	// Rules, by index into setupRules; komi follows them as it does the
+	// handicap
+	rulesValues := make([]int, len(setupRules))
+	for i := range rulesValues {
+		rulesValues[i] = i
+	}
+	setup.rulesSelect = NewValueSelect("Rules", rulesValues, 0, func(i int) string { return setupRules[i].Name() }, setup.setRules)
+
 	// Buttons
 	setup.playButton = NewMenuButton("(P)LAY", true, func() {
+		// A komi that doesn't suit the handicap takes a second PLAY
+		if engine.CheckSetup(setup.playerColor, setup.handicap, setup.komi).KomiMismatch && !setup.keepKomi {
+			setup.keepKomi = true
+			setup.resizeCard()
+			return
+		}
 		cfg := engine.GameConfig{
 			BoardSize:   setup.boardSize,
 			Komi:        setup.komi,
 			PlayerColor: setup.playerColor,
 			EngineLevel: setup.level,
 			EnginePath:  "gnugo",
+			Handicap:    setup.handicap,
+			Ruleset:     setupRules[setup.rules].Name(),
 		}
+		if setup.engine < len(setup.engines) {
+			cfg.EngineName = setup.engines[setup.engine]
+		}
+		tc := timeControls[setup.timeControl]
+		cfg.MainTime, cfg.ByoYomiTime, cfg.ByoYomiStones = tc.main, tc.byoTime, tc.byoStones
 		onStart(cfg)
 	})
 
@@ -125,19 +231,12 @@ func NewGameSetup(onStart func(engine.GameConfig), onCancel func(), onColors fun
 		onCancel()
 	})
 
-	// Set up focus chain
-	setup.focusables = []focusableComponent{
-		setup.boardSelect,
-		setup.colorSelect,
-		setup.levelSlider,
-		setup.komiInput,
-		setup.playButton,
-		setup.historyButton,
-		setup.colorButton,
-		setup.quitButton,
+	// Quick holds what most games change; Advanced the rest
+	setup.tabOptions = [][]setupOption{
+		{setup.boardSelect, setup.colorSelect, setup.levelSlider},
+		setup.advancedOptions(),
 	}
-	setup.focusIndex = 0
-	setup.boardSelect.SetFocused(true)
+	setup.buildFocusChain()
 
 	// Create the main box with custom draw function
 	setup.box = tview.NewBox()
@@ -145,28 +244,41 @@ func NewGameSetup(onStart func(engine.GameConfig), onCancel func(), onColors fun
 	setup.box.SetInputCapture(setup.handleInput)
 
 	// Create help text
-	helpText := tview.NewTextView().
-		SetText("↑↓ options · Tab next · p play · ctrl-c quit").
+	setup.helpText = tview.NewTextView().
+		SetText(glyphs(setupKeys)).
 		SetTextAlign(tview.AlignCenter)
-	helpText.SetTextColor(MenuColors.Hint)
-	helpText.SetBackgroundColor(tcell.ColorDefault)
+	setup.helpText.SetTextColor(MenuColors.Hint)
+	setup.helpText.SetBackgroundColor(tcell.ColorDefault)
 
 	// Create inner flex layout with box and help text
-	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(nil, 0, 1, false).        // Top spacer
-		AddItem(setup.box, 20, 0, true).  // Card (fixed height)
-		AddItem(nil, 0, 1, false).        // Bottom spacer
-		AddItem(helpText, 1, 0, false)
+	setup.innerFlex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).                       // Top spacer
+		AddItem(setup.box, setup.cardHeight(), 0, true). // Card (sized to the tab)
+		AddItem(nil, 0, 1, false).                       // Bottom spacer
+		AddItem(setup.helpText, 2, 0, false)
 
 	// Center horizontally
 	setup.flex = tview.NewFlex().SetDirection(tview.FlexColumn).
-		AddItem(nil, 0, 1, false).        // Left spacer
-		AddItem(innerFlex, 48, 0, true).  // Card (fixed width)
-		AddItem(nil, 0, 1, false)         // Right spacer
+		AddItem(nil, 0, 1, false).             // Left spacer
+		AddItem(setup.innerFlex, 48, 0, true). // Card (fixed width)
+		AddItem(nil, 0, 1, false)              // Right spacer
 
 	return setup
 }
 
+// setupKeys are the keys listed under the card.
+const setupKeys = "↑↓ options · Tab next · PgUp/PgDn page\np play · o open SGF · a about · ? keys · ctrl-c quit"
+
+// SetNotice shows msg above the keys under the card, or nothing for "".
+func (s *GameSetupUI) SetNotice(msg string) {
+	text, rows := glyphs(setupKeys), 2
+	if msg != "" {
+		text, rows = msg+"\n"+text, 3
+	}
+	s.helpText.SetText(text)
+	s.innerFlex.ResizeItem(s.helpText, rows, 0)
+}
+
 // draw renders all components onto the screen.
 func (s *GameSetupUI) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
 	// Fill background
@@ -180,30 +292,60 @@ func (s *GameSetupUI) draw(screen tcell.Screen, x, y, width, height int) (int, i
 	// Draw card border and title
 	s.drawCard(screen, x, y, width, height)
 
-	// Content starts after title
+	// The game to continue, if any, leads; then the tab header and the
+	// active tab's settings
+	tabY := y + 4
+	if s.continueButton != nil {
+		s.continueButton.Draw(screen, x+(width-s.continueButton.Width())/2, tabY)
+		tabY += 2
+	}
+	s.drawTabs(screen, x, tabY, width)
+
 	contentX := x + 4
-	contentY := y + 4
+	contentY := tabY + 2
 	contentWidth := width - 8
 
-	// Draw board size selector
-	rows := s.boardSelect.Draw(screen, contentX, contentY, contentWidth)
-	contentY += rows + 1
-
-	// Draw color selector
-	rows = s.colorSelect.Draw(screen, contentX, contentY, contentWidth)
-	contentY += rows + 1
-
-	// Draw level slider
-	rows = s.levelSlider.Draw(screen, contentX, contentY, contentWidth)
-	contentY += rows + 1
-
-	// Draw komi input
-	rows = s.komiInput.Draw(screen, contentX, contentY, contentWidth)
-	contentY += rows + 2 // spacing before buttons
+	for _, opt := range s.tabOptions[s.tab] {
+		rows := opt.Draw(screen, contentX, contentY, contentWidth)
+		contentY += rows + 1
+	}
+	contentY++ // spacing before buttons
 
 	// Draw buttons centered
 	s.drawButtons(screen, x, contentY, width)
 
+	// Notes on the setup, then the newest game, a key away from review
+	footerY := contentY + 2
+	if notes := s.setupNotes(); len(notes) > 0 {
+		noteStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
+		for _, note := range notes {
+			note = glyphs(note)
+			drawText(screen, x+(width-textWidth(note))/2, footerY, note, noteStyle)
+			footerY++
+		}
+		footerY++
+	}
+	if s.lastGame != "" {
+		footer := []rune(glyphs(s.lastGame + "  (l)"))
+		if textWidth(string(footer)) > width-4 {
+			for len(footer) > 0 && textWidth(string(footer)) > width-5 {
+				footer = footer[:len(footer)-1]
+			}
+			footer = append(footer, glyph('…'))
+		}
+		footerStyle := tcell.StyleDefault.Foreground(MenuColors.Hint).Background(MenuColors.CardBG)
+		resultStyle := outcomeStyle(s.lastOutcome, footerStyle)
+		from, to := resultSpan(s.lastGame)
+		col := x + (width-textWidth(string(footer)))/2
+		for i, ch := range footer {
+			style := footerStyle
+			if i >= from && i < to {
+				style = resultStyle
+			}
+			col = drawRunes(screen, col, footerY, string(ch), style)
+		}
+	}
+
 	return x, y, width, height
 }
 
@@ -213,44 +355,92 @@ func (s *GameSetupUI) drawCard(screen tcell.Screen, x, y, width, height int) {
 	borderStyle := tcell.StyleDefault.Foreground(borderColor).Background(MenuColors.CardBG)
 
 	// Top border: ╭───╮
-	screen.SetContent(x, y, '╭', nil, borderStyle)
+	screen.SetContent(x, y, glyph('╭'), nil, borderStyle)
 	for col := x + 1; col < x+width-1; col++ {
-		screen.SetContent(col, y, '─', nil, borderStyle)
+		screen.SetContent(col, y, glyph('─'), nil, borderStyle)
 	}
-	screen.SetContent(x+width-1, y, '╮', nil, borderStyle)
+	screen.SetContent(x+width-1, y, glyph('╮'), nil, borderStyle)
 
 	// Side borders
 	for row := y + 1; row < y+height-1; row++ {
-		screen.SetContent(x, row, '│', nil, borderStyle)
-		screen.SetContent(x+width-1, row, '│', nil, borderStyle)
+		screen.SetContent(x, row, glyph('│'), nil, borderStyle)
+		screen.SetContent(x+width-1, row, glyph('│'), nil, borderStyle)
 	}
 
 	// Bottom border: ╰───╯
-	screen.SetContent(x, y+height-1, '╰', nil, borderStyle)
+	screen.SetContent(x, y+height-1, glyph('╰'), nil, borderStyle)
 	for col := x + 1; col < x+width-1; col++ {
-		screen.SetContent(col, y+height-1, '─', nil, borderStyle)
+		screen.SetContent(col, y+height-1, glyph('─'), nil, borderStyle)
 	}
-	screen.SetContent(x+width-1, y+height-1, '╯', nil, borderStyle)
+	screen.SetContent(x+width-1, y+height-1, glyph('╯'), nil, borderStyle)
 
 	// Title centered with decoration: ⬡ T E R M S U J I
 	titleStyle := tcell.StyleDefault.Foreground(MenuColors.Title).Background(MenuColors.CardBG).Bold(true)
 	accentStyle := tcell.StyleDefault.Foreground(MenuColors.TitleAccent).Background(MenuColors.CardBG)
 
 	title := "T E R M S U J I"
-	fullTitle := "⬡  " + title
-	titleLen := len([]rune(fullTitle))
-	titleX := x + (width-titleLen)/2
+	accent := string(glyph('⬡'))
+	titleX := x + (width-textWidth(accent+"  "+title))/2
 	titleY := y + 2
 
-	// Draw decoration
-	screen.SetContent(titleX, titleY, '⬡', nil, accentStyle)
-	titleX += 3
+	// Draw decoration, then the title after two spaces
+	titleX = drawRunes(screen, titleX, titleY, accent, accentStyle) + 2
+	drawRunes(screen, titleX, titleY, title, titleStyle)
+}
+
+// drawTabs draws the tab names centered, the active one highlighted.
+func (s *GameSetupUI) drawTabs(screen tcell.Screen, x, y, width int) {
+	activeStyle := emphasis(tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG)).Underline(true)
+	inactiveStyle := tcell.StyleDefault.Foreground(MenuColors.Unselected).Background(MenuColors.CardBG)
+	sepStyle := tcell.StyleDefault.Foreground(MenuColors.Border).Background(MenuColors.CardBG)
+
+	labels := []string{"Quick", "Advanced"}
+	sep := glyphs("  │  ")
+	total := textWidth(sep) * (len(labels) - 1)
+	for _, label := range labels {
+		total += textWidth(label)
+	}
+
+	col := x + (width-total)/2
+	for i, label := range labels {
+		if i > 0 {
+			col = drawRunes(screen, col, y, sep, sepStyle)
+		}
+		style := inactiveStyle
+		if i == s.tab {
+			style = activeStyle
+		}
+		col = drawRunes(screen, col, y, label, style)
+	}
+}
 
-	// Draw title
-	for _, ch := range title {
-		screen.SetContent(titleX, titleY, ch, nil, titleStyle)
-		titleX++
+// cardHeight returns the rows the card needs for the active tab: border,
+// title, any continue button and tab header, the settings with a blank row
+// after each, then a blank row, the buttons, any notes on the setup, any
+// last game and the bottom border.
+func (s *GameSetupUI) cardHeight() int {
+	height := 6
+	if s.continueButton != nil {
+		height += 2
+	}
+	if s.lastGame != "" {
+		height += 2
+	}
+	if notes := s.setupNotes(); len(notes) > 0 {
+		height += len(notes) + 1
 	}
+	for _, opt := range s.tabOptions[s.tab] {
+		height += optionRows(opt) + 1
+	}
+	return height + 3
+}
+
+// optionRows returns how many rows a setting draws.
+func optionRows(opt setupOption) int {
+	if r, ok := opt.(*RadioSelect); ok {
+		return len(r.options) + 1 // label, then one row per option
+	}
+	return 1
 }
 
 // drawButtons draws the action buttons centered.
@@ -279,6 +469,16 @@ func (s *GameSetupUI) drawButtons(screen tcell.Screen, x, y, width int) {
 
 // handleInput processes keyboard input for focus management and delegation.
 func (s *GameSetupUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	// Page keys switch tabs whatever has focus
+	switch event.Key() {
+	case tcell.KeyPgDn:
+		s.switchTab(1)
+		return nil
+	case tcell.KeyPgUp:
+		s.switchTab(-1)
+		return nil
+	}
+
 	// Let current focused component try to handle the key first
 	if s.focusIndex >= 0 && s.focusIndex < len(s.focusables) {
 		if s.focusables[s.focusIndex].HandleKey(event) {
@@ -296,25 +496,25 @@ func (s *GameSetupUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
 		return nil
 	case tcell.KeyDown:
 		// Move to next component if current doesn't handle down
-		if s.focusIndex < 4 { // Not in buttons
+		if s.focusIndex < s.firstButton() { // Not in buttons
 			s.cycleFocus(1)
 			return nil
 		}
 	case tcell.KeyUp:
 		// Move to previous component if current doesn't handle up
-		if s.focusIndex > 0 && s.focusIndex <= 4 {
+		if s.focusIndex > 0 && s.focusIndex <= s.firstButton() {
 			s.cycleFocus(-1)
 			return nil
 		}
 	case tcell.KeyLeft:
 		// Handle left arrow in button row
-		if s.focusIndex > 4 {
+		if s.focusIndex > s.firstButton() {
 			s.cycleFocus(-1)
 			return nil
 		}
 	case tcell.KeyRight:
 		// Handle right arrow in button row
-		if s.focusIndex >= 4 && s.focusIndex < 7 {
+		if s.focusIndex >= s.firstButton() && s.focusIndex < len(s.focusables)-1 {
 			s.cycleFocus(1)
 			return nil
 		}
@@ -322,16 +522,179 @@ func (s *GameSetupUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
 		s.onCancel()
 		return nil
 	case tcell.KeyRune:
-		// Hotkey 'p' to play (unless in komi input)
-		if event.Rune() == 'p' && s.focusIndex != 3 {
+		// Hotkey 'p' to play (unless typing in a field)
+		if event.Rune() == 'p' && !s.typing() {
 			s.playButton.HandleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
 			return nil
 		}
+		if event.Rune() == 'a' && !s.typing() && s.onAbout != nil {
+			s.onAbout()
+			return nil
+		}
+		if event.Rune() == '?' && !s.typing() && s.onHelp != nil {
+			s.onHelp()
+			return nil
+		}
+		if event.Rune() == 'o' && !s.typing() && s.onLoad != nil {
+			s.onLoad()
+			return nil
+		}
+		if event.Rune() == 'l' && !s.typing() && s.onLastGame != nil {
+			s.onLastGame()
+			return nil
+		}
 	}
 
 	return event
 }
 
+// firstButton returns the focus index of the PLAY button; the options
+// come before it and the other buttons after.
+func (s *GameSetupUI) firstButton() int {
+	for i, f := range s.focusables {
+		if f == s.playButton {
+			return i
+		}
+	}
+	return len(s.focusables)
+}
+
+// typing returns true when the komi or name field has focus. They take
+// typed characters, so letter hotkeys are off there.
+func (s *GameSetupUI) typing() bool {
+	f := s.focusables[s.focusIndex]
+	return f == s.komiInput || s.nameInput != nil && f == s.nameInput
+}
+
+// suggestedKomi is the komi for a game with handicap under the rules at
+// index r of setupRules: the rules' full komi for an even game, a token
+// one with stones.
+func (s *GameSetupUI) suggestedKomi(handicap, r int) float64 {
+	return engine.CheckSetup(s.playerColor, handicap, setupRules[r].DefaultKomi()).SuggestedKomi
+}
+
+// setHandicap applies a handicap change. The komi follows the handicap
+// unless the user has typed their own.
+func (s *GameSetupUI) setHandicap(handicap int) {
+	custom := s.komi != s.suggestedKomi(s.handicap, s.rules)
+	s.handicap = handicap
+	if !custom {
+		s.komiInput.SetValue(s.suggestedKomi(handicap, s.rules))
+	}
+	s.setupChanged()
+}
+
+// setupChanged takes back a PLAY pressed for a komi unsuited to the
+// handicap once the color, handicap or komi changes, and sizes the card
+// to the notes on the new setup.
+func (s *GameSetupUI) setupChanged() {
+	s.keepKomi = false
+	s.resizeCard()
+}
+
+// setupNotes explains what is unusual about the setup, under the buttons:
+// a komi unsuited to the handicap, stones going to the engine, and once
+// PLAY has been pressed for such a komi, how to keep it.
+func (s *GameSetupUI) setupNotes() []string {
+	notes := engine.CheckSetup(s.playerColor, s.handicap, s.komi).Notes
+	if s.keepKomi {
+		notes = append(notes, fmt.Sprintf("p again to keep komi %s", sgf.FormatKomi(s.komi)))
+	}
+	return notes
+}
+
+// setRules applies a change of rules, index r into setupRules. The komi
+// follows, 6.5 to 7.5 for an even game, unless the user has typed their
+// own.
+func (s *GameSetupUI) setRules(r int) {
+	custom := s.komi != s.suggestedKomi(s.handicap, s.rules)
+	s.rules = r
+	if !custom {
+		s.komiInput.SetValue(s.suggestedKomi(s.handicap, r))
+	}
+}
+
+// formatHandicap describes a handicap for the selector, noting when the
+// stones go to the engine.
+func (s *GameSetupUI) formatHandicap(handicap int) string {
+	if handicap < 2 {
+		return "none"
+	}
+	if engine.CheckSetup(s.playerColor, handicap, s.komi).EngineTakesHandicap {
+		return fmt.Sprintf("%d stones (engine's)", handicap)
+	}
+	return fmt.Sprintf("%d stones", handicap)
+}
+
+// buildFocusChain makes the focus chain any continue button, the active
+// tab's settings, then the buttons. Focus stays on the same component when
+// it is still in the chain (a button), otherwise it moves to the tab's
+// first setting.
+func (s *GameSetupUI) buildFocusChain() {
+	var focused focusableComponent
+	if s.focusIndex < len(s.focusables) {
+		focused = s.focusables[s.focusIndex]
+		focused.SetFocused(false)
+	}
+
+	s.focusables = s.focusables[:0]
+	if s.continueButton != nil {
+		s.focusables = append(s.focusables, s.continueButton)
+	}
+	first := len(s.focusables)
+	for _, opt := range s.tabOptions[s.tab] {
+		s.focusables = append(s.focusables, opt)
+	}
+	s.focusables = append(s.focusables, s.playButton, s.historyButton, s.colorButton, s.quitButton)
+
+	s.focusIndex = first
+	for i, f := range s.focusables {
+		if f == focused {
+			s.focusIndex = i
+		}
+	}
+	s.focusables[s.focusIndex].SetFocused(true)
+}
+
+// showTab makes tab active, rebuilding the focus chain and resizing the
+// card. Settings keep their values; only what's shown changes.
+func (s *GameSetupUI) showTab(tab int) {
+	s.tab = tab
+	s.buildFocusChain()
+	s.resizeCard()
+}
+
+// resizeCard fits the card to what it now shows.
+func (s *GameSetupUI) resizeCard() {
+	if s.innerFlex != nil {
+		s.innerFlex.ResizeItem(s.box, s.cardHeight(), 0)
+	}
+}
+
+// switchTab moves delta tabs along, wrapping, and reports the new tab.
+func (s *GameSetupUI) switchTab(delta int) {
+	s.showTab((s.tab + delta + len(setupTabs)) % len(setupTabs))
+	if s.onTab != nil {
+		s.onTab(setupTabs[s.tab])
+	}
+}
+
+// SetTab shows the named tab, e.g. the one remembered from the last run.
+// Unknown names are ignored.
+func (s *GameSetupUI) SetTab(name string) {
+	for i, tab := range setupTabs {
+		if tab == name {
+			s.showTab(i)
+		}
+	}
+}
+
+// SetTabFunc sets the callback run with the tab's name when the user
+// switches tabs.
+func (s *GameSetupUI) SetTabFunc(onTab func(string)) {
+	s.onTab = onTab
+}
+
 // cycleFocus moves focus to the next/previous component.
 func (s *GameSetupUI) cycleFocus(delta int) {
 	// Unfocus current
@@ -353,6 +716,152 @@ func (s *GameSetupUI) Form() *tview.Flex {
 	return s.flex
 }
 
+// SetAboutFunc sets the callback for the 'a' hotkey, which opens the
+// About page.
+func (s *GameSetupUI) SetAboutFunc(onAbout func()) {
+	s.onAbout = onAbout
+}
+
+// SetHelpFunc sets the callback for the '?' hotkey, which shows the key
+// reference.
+func (s *GameSetupUI) SetHelpFunc(onHelp func()) {
+	s.onHelp = onHelp
+}
+
+// SetLoadFunc sets the callback for the 'o' hotkey, which asks for an SGF
+// file to open.
+func (s *GameSetupUI) SetLoadFunc(onLoad func()) {
+	s.onLoad = onLoad
+}
+
+// SetContinue offers the unfinished game described by label, run by
+// onContinue, above the tabs; a nil onContinue takes the offer away. A
+// newly offered game takes focus, so Enter resumes it.
+func (s *GameSetupUI) SetContinue(label string, onContinue func()) {
+	offered := s.continueButton != nil
+	s.continueButton = nil
+	if onContinue != nil {
+		s.continueButton = NewMenuButton(label, false, onContinue)
+	}
+	s.showTab(s.tab)
+	if s.continueButton != nil && !offered {
+		s.focusables[s.focusIndex].SetFocused(false)
+		s.focusIndex = 0
+		s.continueButton.SetFocused(true)
+	}
+}
+
+// SetLastGame shows label, a summary of the newest game, under the
+// buttons, its result tinted by outcome, with l running onOpen; an empty
+// label takes it away.
+func (s *GameSetupUI) SetLastGame(label string, outcome sgf.Outcome, onOpen func()) {
+	s.lastGame, s.lastOutcome, s.onLastGame = label, outcome, onOpen
+	if label == "" {
+		s.onLastGame = nil
+	}
+	s.resizeCard()
+}
+
+// SetEngines lists the engines to choose from on the Advanced tab, the
+// first being the default. With one engine or none there is no choice to
+// show.
+func (s *GameSetupUI) SetEngines(names []string) {
+	s.engines = names
+	s.engine = 0
+	s.engineSelect = nil
+	if len(names) > 1 {
+		values := make([]int, len(names))
+		for i := range values {
+			values[i] = i
+		}
+		s.engineSelect = NewValueSelect("Engine", values, 0, func(i int) string { return names[i] }, func(i int) {
+			s.engine = i
+		})
+	}
+	s.tabOptions[1] = s.advancedOptions()
+	s.showTab(s.tab)
+}
+
+// advancedOptions lists the Advanced tab's settings, led by the engine
+// when there is a choice of one.
+func (s *GameSetupUI) advancedOptions() []setupOption {
+	opts := []setupOption{s.handicapSel, s.komiInput, s.timeSelect, s.rulesSelect}
+	if s.engineSelect != nil {
+		opts = append([]setupOption{s.engineSelect}, opts...)
+	}
+	if s.confirmSel != nil {
+		opts = append(opts, s.confirmSel)
+	}
+	if s.recordSel != nil {
+		opts = append(opts, s.recordSel)
+	}
+	if s.nameInput != nil {
+		opts = append(opts, s.nameInput)
+	}
+	return opts
+}
+
+// onOff names the values of an on/off switch.
+func onOff(v int) string {
+	if v == 1 {
+		return "on"
+	}
+	return "off"
+}
+
+// SetConfirmMoves puts the two-step move confirmation switch on the
+// Advanced tab, set to on, and calls onChange when the user flips it.
+func (s *GameSetupUI) SetConfirmMoves(on bool, onChange func(bool)) {
+	initial := 0
+	if on {
+		initial = 1
+	}
+	s.confirmSel = NewValueSelect("Confirm moves", []int{0, 1}, initial, onOff, func(v int) {
+		onChange(v == 1)
+	})
+	s.tabOptions[1] = s.advancedOptions()
+	s.showTab(s.tab)
+}
+
+// SetRecordGames puts the switch for recording new games on the Advanced
+// tab, set to on, and calls onChange when the user flips it.
+func (s *GameSetupUI) SetRecordGames(on bool, onChange func(bool)) {
+	initial := 0
+	if on {
+		initial = 1
+	}
+	s.recordSel = NewValueSelect("Record games", []int{0, 1}, initial, onOff, func(v int) {
+		onChange(v == 1)
+	})
+	s.tabOptions[1] = s.advancedOptions()
+	s.showTab(s.tab)
+}
+
+// ShowRecordGames flips the recording switch to on, for when recording was
+// switched elsewhere, such as with r during a game. onChange is called only
+// if the switch moves.
+func (s *GameSetupUI) ShowRecordGames(on bool) {
+	if s.recordSel == nil {
+		return
+	}
+	v := 0
+	if on {
+		v = 1
+	}
+	if s.recordSel.Value() != v {
+		s.recordSel.SetValue(v)
+	}
+}
+
+// SetPlayerName puts the field for the name game records give you on the
+// Advanced tab, holding name, and calls onChange with the new name once it
+// has been edited. An empty name stands for "Player".
+func (s *GameSetupUI) SetPlayerName(name string, onChange func(string)) {
+	s.nameInput = NewNameInput("Your name", name, "Player", onChange)
+	s.tabOptions[1] = s.advancedOptions()
+	s.showTab(s.tab)
+}
+
 // SetInputCapture sets the input capture function for the form.
 func (s *GameSetupUI) SetInputCapture(capture func(event *tcell.EventKey) *tcell.EventKey) {
 	originalCapture := s.box.GetInputCapture()
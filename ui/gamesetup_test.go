@@ -0,0 +1,302 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+)
+
+func TestSetupHandicapKomi(t *testing.T) {
+	var started engine.GameConfig
+	s := NewGameSetup(func(gc engine.GameConfig) { started = gc }, func() {}, nil, nil)
+
+	s.handicapSel.SetValue(3)
+	if s.komi != engine.HandicapKomi {
+		t.Errorf("komi with H3 = %.1f, want %.1f", s.komi, engine.HandicapKomi)
+	}
+	s.handicapSel.SetValue(0)
+	if s.komi != defaultKomi {
+		t.Errorf("komi back to an even game = %.1f, want %.1f", s.komi, defaultKomi)
+	}
+
+	// A komi the user typed is left alone.
+	s.komiInput.SetValue(3.5)
+	s.handicapSel.SetValue(4)
+	if s.komi != 3.5 {
+		t.Errorf("custom komi changed to %.1f", s.komi)
+	}
+
+	// It doesn't suit the handicap, so PLAY asks to be pressed again
+	height := s.cardHeight()
+	s.playButton.onSelect()
+	if started.Handicap != 0 {
+		t.Fatal("a komi unsuited to the handicap started without confirming")
+	}
+	notes := s.setupNotes()
+	if len(notes) != 2 || notes[1] != "p again to keep komi 3.5" || s.cardHeight() != height+1 {
+		t.Fatalf("notes %q, height %d (was %d)", notes, s.cardHeight(), height)
+	}
+	s.playButton.onSelect()
+	if started.Handicap != 4 || started.Komi != 3.5 {
+		t.Errorf("started with handicap %d komi %.1f, want 4 and 3.5", started.Handicap, started.Komi)
+	}
+
+	// A change takes the confirmation back
+	started = engine.GameConfig{}
+	s.komiInput.SetValue(2.5)
+	s.playButton.onSelect()
+	if started.Handicap != 0 || len(s.setupNotes()) != 2 {
+		t.Errorf("after changing komi: started %v, notes %q", started.Handicap != 0, s.setupNotes())
+	}
+}
+
+func TestSetupHandicapLabel(t *testing.T) {
+	s := NewGameSetup(func(engine.GameConfig) {}, func() {}, nil, nil)
+	if got := s.formatHandicap(0); got != "none" {
+		t.Errorf("formatHandicap(0) = %q", got)
+	}
+	if got := s.formatHandicap(2); got != "2 stones" {
+		t.Errorf("formatHandicap(2) as Black = %q", got)
+	}
+	s.colorSelect.SetSelected(1)
+	if got := s.formatHandicap(2); got != "2 stones (engine's)" {
+		t.Errorf("formatHandicap(2) as White = %q", got)
+	}
+}
+
+func TestSetupTabs(t *testing.T) {
+	var started engine.GameConfig
+	s := NewGameSetup(func(gc engine.GameConfig) { started = gc }, func() {}, nil, nil)
+	var reported []string
+	s.SetTabFunc(func(tab string) { reported = append(reported, tab) })
+	pgDn := tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone)
+	quickHeight := s.cardHeight()
+
+	s.levelSlider.SetValue(8)
+	s.handleInput(pgDn)
+	if s.tab != 1 || len(reported) != 1 || reported[0] != SetupTabAdvanced {
+		t.Fatalf("PgDn: tab %d, reported %v", s.tab, reported)
+	}
+	if s.focusables[s.focusIndex] != s.handicapSel || s.firstButton() != 4 {
+		t.Errorf("Advanced focus chain starts at %T, PLAY at %d", s.focusables[s.focusIndex], s.firstButton())
+	}
+	if s.cardHeight() >= quickHeight {
+		t.Errorf("Advanced card height %d, Quick %d; it should shrink", s.cardHeight(), quickHeight)
+	}
+
+	// A button keeps focus across the switch; settings keep their values.
+	s.handicapSel.SetValue(2)
+	s.cycleFocus(5) // past komi, time, rules and PLAY to HISTORY
+	s.handleInput(pgDn)
+	if s.tab != 0 || s.focusables[s.focusIndex] != s.historyButton {
+		t.Errorf("back on Quick: tab %d, focus %T", s.tab, s.focusables[s.focusIndex])
+	}
+	s.handleInput(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone))
+	if started.EngineLevel != 8 || started.Handicap != 2 {
+		t.Errorf("started with level %d handicap %d, want 8 and 2", started.EngineLevel, started.Handicap)
+	}
+
+	// The remembered tab is restored silently; unknown names are ignored.
+	s.SetTab(SetupTabAdvanced)
+	s.SetTab("bogus")
+	if s.tab != 1 || len(reported) != 2 {
+		t.Errorf("SetTab: tab %d, reported %v", s.tab, reported)
+	}
+}
+
+func TestSetupEngines(t *testing.T) {
+	var started engine.GameConfig
+	s := NewGameSetup(func(gc engine.GameConfig) { started = gc }, func() {}, nil, nil)
+	s.SetEngines([]string{"GnuGo"})
+	if s.engineSelect != nil || len(s.tabOptions[1]) != 4 {
+		t.Error("a lone engine needs no selector")
+	}
+
+	s.SetEngines([]string{"GnuGo", "KataGo"})
+	if s.tabOptions[1][0] != s.engineSelect {
+		t.Fatal("engine selector should lead the Advanced tab")
+	}
+	s.engineSelect.SetValue(1)
+	s.playButton.onSelect()
+	if started.EngineName != "KataGo" {
+		t.Errorf("started against %q, want KataGo", started.EngineName)
+	}
+}
+
+func TestSetupRules(t *testing.T) {
+	var started engine.GameConfig
+	s := NewGameSetup(func(gc engine.GameConfig) { started = gc }, func() {}, nil, nil)
+	s.playButton.onSelect()
+	if started.Ruleset != "Japanese" || started.Komi != 6.5 {
+		t.Errorf("started with %q rules, komi %.1f; want Japanese and 6.5", started.Ruleset, started.Komi)
+	}
+
+	// Komi follows the rules, and the handicap under them
+	s.rulesSelect.SetValue(1)
+	if s.komi != 7.5 {
+		t.Errorf("komi with Chinese rules = %.1f, want 7.5", s.komi)
+	}
+	s.handicapSel.SetValue(2)
+	s.handicapSel.SetValue(0)
+	if s.komi != 7.5 {
+		t.Errorf("komi back to an even game = %.1f, want 7.5", s.komi)
+	}
+	s.playButton.onSelect()
+	if started.Ruleset != "Chinese" || started.Komi != 7.5 {
+		t.Errorf("started with %q rules, komi %.1f; want Chinese and 7.5", started.Ruleset, started.Komi)
+	}
+
+	// A komi the user typed is left alone
+	s.komiInput.SetValue(5.5)
+	s.rulesSelect.SetValue(0)
+	if s.komi != 5.5 {
+		t.Errorf("custom komi changed to %.1f", s.komi)
+	}
+}
+
+func TestSetupTimeControls(t *testing.T) {
+	var started engine.GameConfig
+	s := NewGameSetup(func(gc engine.GameConfig) { started = gc }, func() {}, nil, nil)
+	s.playButton.onSelect()
+	if started.Timed() {
+		t.Errorf("games are untimed by default: %+v", started)
+	}
+	s.timeSelect.SetValue(3)
+	s.playButton.onSelect()
+	if started.MainTime != 10*time.Minute || started.ByoYomiTime != 30*time.Second || started.ByoYomiStones != 1 {
+		t.Errorf("10 min + 30s byo-yomi started as %v + %v/%d", started.MainTime, started.ByoYomiTime, started.ByoYomiStones)
+	}
+}
+
+func TestSetupContinue(t *testing.T) {
+	s := NewGameSetup(func(engine.GameConfig) {}, func() {}, nil, nil)
+	height := s.cardHeight()
+
+	continued := false
+	s.SetContinue("Continue last game (move 47, 19x19)", func() { continued = true })
+	if s.focusables[s.focusIndex] != s.continueButton || s.cardHeight() != height+2 {
+		t.Fatalf("offered game: focus %T, height %d (was %d)", s.focusables[s.focusIndex], s.cardHeight(), height)
+	}
+	s.handleInput(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if !continued {
+		t.Error("Enter on the offer should continue the game")
+	}
+
+	// Down leads into the tab; switching tabs keeps the offer first
+	s.handleInput(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	if s.focusables[s.focusIndex] != s.boardSelect {
+		t.Errorf("Down from the offer: focus %T", s.focusables[s.focusIndex])
+	}
+	s.handleInput(tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone))
+	if s.focusables[0] != s.continueButton || s.focusables[s.focusIndex] != s.handicapSel {
+		t.Errorf("Advanced chain: %T first, focus %T", s.focusables[0], s.focusables[s.focusIndex])
+	}
+
+	s.SetContinue("", nil)
+	if s.continueButton != nil || s.focusables[0] != s.handicapSel || s.cardHeight() >= height+2 {
+		t.Error("withdrawn offer still on the card")
+	}
+}
+
+func TestSetupLastGame(t *testing.T) {
+	s := NewGameSetup(func(engine.GameConfig) {}, func() {}, nil, nil)
+	height := s.cardHeight()
+	l := tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone)
+	if s.handleInput(l) == nil {
+		t.Error("l handled with no last game")
+	}
+
+	opened := false
+	s.SetLastGame("last: 9x9 L5 — B+3.5, 20m ago", sgf.OutcomeWin, func() { opened = true })
+	if s.cardHeight() != height+2 {
+		t.Errorf("height %d with a last game, was %d", s.cardHeight(), height)
+	}
+	s.handleInput(l)
+	if !opened {
+		t.Error("l should open the last game")
+	}
+
+	s.SetLastGame("", sgf.OutcomeUnknown, nil)
+	if s.cardHeight() != height || s.onLastGame != nil {
+		t.Error("last game still on the card")
+	}
+}
+
+func TestSetupConfirmMoves(t *testing.T) {
+	s := NewGameSetup(func(engine.GameConfig) {}, func() {}, nil, nil)
+	s.SetEngines([]string{"GnuGo", "KataGo"})
+	var got []bool
+	s.SetConfirmMoves(true, func(on bool) { got = append(got, on) })
+	opts := s.tabOptions[1]
+	if opts[len(opts)-1] != s.confirmSel || s.confirmSel.Value() != 1 {
+		t.Fatal("confirm switch should end the Advanced tab, on")
+	}
+
+	// Engines set later keep the switch
+	s.SetEngines([]string{"GnuGo"})
+	if opts := s.tabOptions[1]; opts[len(opts)-1] != s.confirmSel {
+		t.Error("confirm switch lost when the engines changed")
+	}
+	s.confirmSel.HandleKey(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone))
+	if len(got) != 1 || got[0] {
+		t.Errorf("flipping off reported %v", got)
+	}
+}
+
+func TestSetupRecordGames(t *testing.T) {
+	s := NewGameSetup(func(engine.GameConfig) {}, func() {}, nil, nil)
+	var got []bool
+	s.SetConfirmMoves(false, func(bool) {})
+	s.SetRecordGames(true, func(on bool) { got = append(got, on) })
+	opts := s.tabOptions[1]
+	if opts[len(opts)-1] != s.recordSel || s.recordSel.Value() != 1 {
+		t.Fatal("record switch should end the Advanced tab, on")
+	}
+
+	s.recordSel.HandleKey(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone))
+	if len(got) != 1 || got[0] {
+		t.Errorf("flipping off reported %v", got)
+	}
+
+	// Switched from a game: shown, and reported only when it moves
+	s.ShowRecordGames(false)
+	s.ShowRecordGames(true)
+	if s.recordSel.Value() != 1 || len(got) != 2 || !got[1] {
+		t.Errorf("after showing on: value %d, reported %v", s.recordSel.Value(), got)
+	}
+}
+
+func TestSetupPlayerName(t *testing.T) {
+	started := false
+	s := NewGameSetup(func(engine.GameConfig) { started = true }, func() {}, nil, nil)
+	var got []string
+	s.SetPlayerName("", func(name string) { got = append(got, name) })
+	s.SetTab(SetupTabAdvanced)
+	for s.focusables[s.focusIndex] != s.nameInput {
+		s.cycleFocus(1)
+	}
+
+	// Letters are typed, not hotkeys, and nothing is saved until leaving
+	for _, r := range " Ada p" {
+		s.handleInput(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	s.handleInput(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone))
+	if started || len(got) != 0 {
+		t.Fatalf("typing started a game (%v) or saved %v", started, got)
+	}
+	s.handleInput(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	if len(got) != 1 || got[0] != "Ada" {
+		t.Errorf("leaving the field saved %q, want [Ada]", got)
+	}
+
+	// Back and out again unchanged saves nothing
+	s.cycleFocus(-1)
+	s.cycleFocus(1)
+	if len(got) != 1 {
+		t.Errorf("unchanged name saved again: %q", got)
+	}
+}
@@ -2,23 +2,40 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
 	"termsuji-local/config"
+	"termsuji-local/coords"
 	"termsuji-local/engine"
+	"termsuji-local/report"
+	"termsuji-local/rules"
 	"termsuji-local/sgf"
+	"termsuji-local/training"
 	"termsuji-local/types"
+	"termsuji-local/ui/render"
 )
 
 // MoveEntry records a single move for the history panel.
 type MoveEntry struct {
-	X, Y  int // -1,-1 for pass
-	Color int // 1=black, 2=white
+	X, Y    int           // -1,-1 for pass
+	Color   int           // 1=black, 2=white
+	At      time.Time     // when the move was played; zero for moves loaded from SGF
+	Think   time.Duration // time since the previous move
+	Book    bool          // answered from an opening book rather than by the engine
+	Comment string        // note on the move, saved as its C[] in the SGF
+	Hash    uint64        // Zobrist hash of the position after it; 0 if not known
 }
 
+// koMarker marks the point a plan can't retake the ko at just yet.
+const koMarker = '▫'
+
 type GoBoardUI struct {
 	Box          *tview.Box
 	BoardState   *types.BoardState
@@ -33,14 +50,98 @@ type GoBoardUI struct {
 	styles       []tcell.Color
 	infoPanel    *GameInfoPanel
 	focusMode    bool
-	recorder     *sgf.GameRecord
+	recorder     *sgf.RecordWriter
 	gameConfig   engine.GameConfig
 	moveHistory  []MoveEntry
+	histMu       sync.Mutex // guards moveHistory writes against MovesSnapshot
+	stateMu      sync.Mutex // guards finished, moveHistory and recorder together; taken before histMu
+	lastMoveAt   time.Time
+	offBook      *types.BoardPos // move awaiting confirmation to leave the opening book
+	pending      *types.BoardPos // move awaiting its second Enter under ConfirmMoves, drawn as a ghost stone
+	undosUsed    int
+	notice       string // one-off message for the hint bar, cleared on the next move
+	flash        string // why a move was just refused, cleared on the next key or after flashDuration
+	flashAt      [2]int // point of the refused move, tinted while flash shows
+	flashSeq     int    // counts flashes, so an old timer leaves a newer one alone
+	flashNote    bool   // flash is news, such as a copy, rather than a refusal
+	tip          string // beginner nudge about the player's last move, cleared on their next
+	edgeHints    int    // edge nudges shown this session, up to maxEdgeHints
+	passPrompt   bool   // pass assist: the board looks settled, Enter passes
+	loadLabel    string // what the engine is busy replaying, e.g. "Loading game"
+	loadDone     int    // replay progress, moves applied
+	loadTotal    int    // replay progress, moves to apply; 0 when not loading
+
+	// Counting: dead stones marked after two passes, and who owns what
+	// once a counted game is over
+	scoring       bool
+	deadMarks     map[[2]int]bool
+	markUndo      []markToggle // toggles of deadMarks this scoring, for u
+	markRedo      []markToggle // toggles undone, for U
+	territory     [][]int      // owner of each point, nil unless the game was counted
+	hideTerritory bool         // the player turned the territory overlay off
+
+	// Analysis: the engine's candidates for the player's move, shown
+	// until a move is played
+	analysis  []engine.Candidate
+	analyzing bool // waiting on the engine for them
+
+	// Estimate: the engine's dead stones and the borders they give, also
+	// shown until a move is played
+	estimate       [][]int // owner of each point on an area's rim
+	estimateDead   map[[2]int]bool
+	estimating     bool
+	passEstimating bool              // waiting on the engine's dead stones for its pass
+	passEstimate   string            // the engine's result when it passed late in the game, "" if none
+	estimateSched  *engine.Scheduler // debounces asking the engine for its dead stones; one per engine
+
+	// Report: what the summary written beside the record needs, gathered
+	// as the game goes, and the summary once the game is over
+	startedAt    time.Time
+	analyzed     bool                  // the player asked for the engine's candidates this game
+	questionable []report.Questionable // the player's moves that weren't among them
+	reportText   string                // the report written at the end, "" if none
+	onReport     func(text string)
+
+	// Review: a saved game stepped through without an engine
+	review      *sgf.Replayer
+	reviewCount string // the position's local count, "" until asked for
+
+	// Guessing: the reviewed game's next move hidden until guessed, and
+	// how the guesses scored
+	guessing        bool
+	guessAt         training.Point       // the guess on show
+	guessReview     *training.Review     // its marks and explanation, nil until guessed
+	guessCandidates []training.Candidate // the engine's moves for the position, nil until known
+	guessCredit     float64
+	guessTries      int
+	guessAnalyst    GuessAnalyst      // nil without analysis
+	guessSched      *engine.Scheduler // debounces the analyst, nil without one
+
+	// Commenting: a note being typed for the last move, shown in the
+	// hint bar until Enter saves it
+	commenting  bool
+	commentText []rune
+	commentMove int // index in moveHistory of the move being commented
+	commentRec  int // the same move's index in the recorder, -1 if not recorded
+
+	// Clocks: each side's time left, by color, under the game's time
+	// controls; the side to move is charged as time passes
+	clockMu   sync.Mutex // guards clocks and clockAt against the engine's move callback
+	clocks    [3]engine.GameClock
+	clockAt   time.Time     // when the side to move was last charged
+	clockStop chan struct{} // closed to stop the ticker; nil for an untimed game
+
+	// Count: digits typed before a movement key, as in vi's "5l"
+	count   int
+	countAt time.Time // when the last digit was typed
+
+	// level is the engine's level, which a thinking budget may step down
+	level int
 
 	// Planning mode state
 	planningMode   bool
 	planTree       *sgf.GameTree
-	planBoard      [][]int           // local board for planning (board[y][x])
+	plan           *rules.Position   // local board for planning, with its captures and ko
 	planColor      int               // next color to play (alternates)
 	planLastMove   [2]int            // last move in planning for highlight (-1,-1 if none)
 	prePlanBoard   *types.BoardState // snapshot to restore when exiting
@@ -73,6 +174,13 @@ func (g *GoBoardUI) SelectedTile() *types.BoardPos {
 }
 
 func (g *GoBoardUI) MoveSelection(h, v int) {
+	// Moving off a move waiting to be confirmed drops it
+	g.CancelConfirm()
+	if g.passPrompt {
+		// Looking around the board dismisses the suggestion.
+		g.passPrompt = false
+		g.refreshHint()
+	}
 	if !g.planningMode && g.BoardState.Finished() {
 		g.ResetSelection()
 		return
@@ -106,6 +214,7 @@ func (g *GoBoardUI) MoveSelection(h, v int) {
 func (g *GoBoardUI) ResetSelection() {
 	g.selX = -1
 	g.selY = -1
+	g.CancelConfirm()
 }
 
 func NewGoBoard(app *tview.Application, c *config.Config, hint *tview.TextView) *GoBoardUI {
@@ -128,10 +237,24 @@ func NewGoBoard(app *tview.Application, c *config.Config, hint *tview.TextView)
 		// Choose board data and last-move indicator based on planning mode
 		boardData := goBoard.BoardState.Board
 		lastMoveX, lastMoveY := goBoard.BoardState.LastMove.X, goBoard.BoardState.LastMove.Y
-		if goBoard.planningMode && goBoard.planBoard != nil {
-			boardData = goBoard.planBoard
+		koX, koY := -1, -1
+		if goBoard.planningMode && goBoard.plan != nil {
+			boardData = goBoard.plan.Board
 			lastMoveX, lastMoveY = goBoard.planLastMove[0], goBoard.planLastMove[1]
+			koX, koY = goBoard.plan.Ko[0], goBoard.plan.Ko[1]
+		}
+		prevMoveX, prevMoveY := -1, -1
+		if goBoard.cfg.HighlightPrev {
+			prevMoveX, prevMoveY = goBoard.prevMove()
 		}
+		// After a counted game, territory is tinted by owner
+		territory := goBoard.territoryOverlay()
+		// Before passing, the engine's view of the borders
+		estimate := goBoard.estimateOverlay()
+		// Stones and markers, in ASCII when the theme asks for it
+		symbols, ascii := goBoard.symbols(), goBoard.ascii()
+		// Beginner hints tint the empty edge points through the opening
+		edgeTint := goBoard.cfg.BeginnerHints && !goBoard.finished && goBoard.review == nil && inOpening(goBoard.BoardState.MoveNumber)
 
 		for boardY := 0; boardY < goBoard.BoardState.Height(); boardY++ {
 			for boardX := 0; boardX < goBoard.BoardState.Width(); boardX++ {
@@ -152,23 +275,15 @@ func NewGoBoard(app *tview.Application, c *config.Config, hint *tview.TextView)
 					i += 3
 					iInv += 3
 				}
-				var drawRune rune
-				if goBoard.cfg.Theme.UseGridLines && stone == 0 {
-					// Use grid lines for empty intersections
-					boardSize := goBoard.BoardState.Width()
-					hoshi := isHoshiPoint(boardX, boardY, boardSize)
-					drawRune = getGridRune(boardX, boardY, goBoard.BoardState.Width(), goBoard.BoardState.Height(), hoshi)
-				} else {
-					drawRune = goBoard.cfg.Theme.Symbols.BoardSquare
+				// The stone, or grid lines for empty intersections, as the text render picks them
+				drawRune := render.Point(stone, boardX, boardY, goBoard.BoardState.Width(), goBoard.BoardState.Height(),
+					symbols, goBoard.cfg.Theme.UseGridLines, ascii)
+				if stone == 0 && boardX == koX && boardY == koY {
+					// Forbidden to retake just yet
+					drawRune = koMarker
 				}
 
 				if stone > 0 {
-					switch stone {
-					case 1:
-						drawRune = goBoard.cfg.Theme.Symbols.BlackStone
-					case 2:
-						drawRune = goBoard.cfg.Theme.Symbols.WhiteStone
-					}
 					if goBoard.cfg.Theme.DrawStoneBackground {
 						// Cursor color is inverted stone color, or cursor color when not on a stone.
 						fgColor = goBoard.styles[iInv]
@@ -184,28 +299,108 @@ func NewGoBoard(app *tview.Application, c *config.Config, hint *tview.TextView)
 					if goBoard.cfg.Theme.DrawCursorBackground {
 						i = 8
 					} else if !goBoard.cfg.Theme.UseGridLines {
-						drawRune = goBoard.cfg.Theme.Symbols.Cursor
+						drawRune = symbols.Cursor
 					}
 					// For grid lines theme, keep the grid character but cursor background will highlight
 				} else if boardX == lastMoveX && boardY == lastMoveY {
 					if goBoard.cfg.Theme.DrawLastPlayedBackground {
 						i = 7
 					} else if !goBoard.cfg.Theme.UseGridLines {
-						drawRune = goBoard.cfg.Theme.Symbols.LastPlayed
+						drawRune = symbols.LastPlayed
+					}
+				} else if boardX == prevMoveX && boardY == prevMoveY {
+					if goBoard.cfg.Theme.DrawLastPlayedBackground {
+						i = 10
+					}
+				} else if mark, rank, ok := goBoard.guessMark(boardX, boardY); ok {
+					// After a guess: the guess, the game move and the engine's choices
+					if rank >= 0 {
+						i = analysisStyle + rank
+					}
+					if stone == 0 {
+						drawRune = mark
+					}
+				} else if rank := goBoard.analysisRank(boardX, boardY); rank >= 0 {
+					i = analysisStyle + rank
+					if goBoard.cfg.Theme.NoColor && stone == 0 {
+						drawRune = rune('1' + rank)
+					}
+				} else if estimate != nil && estimate[boardY][boardX] > 0 {
+					i = 11 + estimate[boardY][boardX]
+				} else if territory != nil && territory[boardY][boardX] > 0 {
+					i = 11 + territory[boardY][boardX]
+				} else if edgeTint && stone == 0 && edgeLine(boardX, boardY, goBoard.BoardState.Width()) == 1 {
+					i = 11
+				}
+
+				dead := stone > 0 && ((goBoard.scoring || territory != nil) && goBoard.deadMarks[[2]int{boardX, boardY}] ||
+					estimate != nil && goBoard.estimateDead[[2]int{boardX, boardY}])
+				if dead {
+					// Dead stones fade towards the grid
+					fgColor = goBoard.styles[9]
+				}
+				owners := territory
+				if owners == nil {
+					owners = estimate
+				}
+				if owners != nil && stone == 0 && goBoard.cfg.Theme.NoColor {
+					// No tint to show ownership with: mark the point instead
+					switch owners[boardY][boardX] {
+					case 1:
+						drawRune = '×'
+					case 2:
+						drawRune = '·'
 					}
 				}
 
-				if goBoard.cfg.Theme.UseGridLines && stone == 0 {
+				if ascii {
+					drawRune = asciiRune(drawRune)
+				}
+
+				style := tcell.StyleDefault.Background(goBoard.styles[i]).Foreground(fgColor)
+				if goBoard.cfg.Theme.NoColor {
+					// No backgrounds to highlight with: reverse the cursor, embolden the last move
+					if boardX == goBoard.selX && boardY == goBoard.selY {
+						style = style.Reverse(true)
+					} else if boardX == lastMoveX && boardY == lastMoveY {
+						style = style.Bold(true).Underline(true)
+					} else if boardX == prevMoveX && boardY == prevMoveY {
+						style = style.Underline(true)
+					}
+				}
+				if dead {
+					style = style.Dim(true)
+				}
+				ghost := stone == 0 && goBoard.ghostAt(boardX, boardY)
+				if ghost {
+					// The move waiting for a second Enter, faintly in the player's color
+					player := goBoard.eng.GetPlayerColor()
+					drawRune = symbols.BlackStone
+					if player == 2 {
+						drawRune = symbols.WhiteStone
+					}
+					style = style.Foreground(goBoard.styles[player]).Dim(true)
+				}
+				if goBoard.flashing(boardX, boardY) {
+					// The point a move was just refused at
+					if goBoard.cfg.Theme.NoColor {
+						style = style.Reverse(true).Blink(true)
+					} else {
+						style = style.Background(tcell.ColorRed)
+					}
+				}
+
+				if goBoard.cfg.Theme.UseGridLines && stone == 0 && !ghost {
 					// Check if there's a stone to the right (no line should connect to it)
 					hasStoneRight := false
 					if boardX < goBoard.BoardState.Width()-1 {
-						hasStoneRight = boardData[boardY][boardX+1] > 0
+						hasStoneRight = boardData[boardY][boardX+1] > 0 || goBoard.ghostAt(boardX+1, boardY)
 					}
 					// Empty intersection with grid lines - draw grid character + connectors
-					drawGridCell(screen, tcell.StyleDefault.Background(goBoard.styles[i]).Foreground(fgColor), drawRune, boardX, boardY, x+4, y, goBoard.BoardState.Width(), hasStoneRight)
+					drawGridCell(screen, style, drawRune, boardX, boardY, x+4, y, goBoard.BoardState.Width(), hasStoneRight, ascii)
 				} else {
 					// Stone or non-grid theme - use stone cell drawing
-					drawStoneCell(screen, tcell.StyleDefault.Background(goBoard.styles[i]).Foreground(fgColor), drawRune, boardX, boardY, x+4, y)
+					drawStoneCell(screen, style, drawRune, boardX, boardY, x+4, y)
 				}
 			}
 		}
@@ -218,20 +413,65 @@ func NewGoBoard(app *tview.Application, c *config.Config, hint *tview.TextView)
 
 // ConnectEngine connects the board to a game engine.
 func (g *GoBoardUI) ConnectEngine(e engine.GameEngine) error {
-	g.finished = false
+	g.resetGame()
 	g.eng = e
-	g.moveHistory = nil
+	g.loadLabel, g.loadDone, g.loadTotal = "Loading game", 0, 0
 
+	e.OnProgress(g.showProgress)
+	g.estimateSched = engine.NewScheduler(engine.DefaultDebounce, nil, g.evaluateEstimate(e))
 	if err := e.Connect(); err != nil {
+		g.loadTotal = 0
 		return err
 	}
 
 	e.OnMove(func(x, y, color int, boardState *types.BoardState) {
+		g.stateMu.Lock()
+		defer g.stateMu.Unlock()
+		// The engine reported it only after the game ended, e.g. with the
+		// player resigning meanwhile; it isn't part of the game
+		if g.finished {
+			debugLog.Printf("dropped %s reported after the game ended", sgf.MoveNode(color, x, y))
+			return
+		}
 		g.lastTurnPass = (x == -1 && y == -1)
+		g.notice = ""
+		g.pending = nil
+		if color == e.GetPlayerColor() {
+			g.noteQuestionable(x, y, color, boardState.MoveNumber)
+		}
+		g.clearAnalysis()
+		g.clearEstimate()
+		g.passEstimate = ""
+		g.passPrompt = g.cfg.PassAssist && color != e.GetPlayerColor() && sgf.IsSettled(boardState.Board)
+		if color == e.GetPlayerColor() {
+			g.tip = g.beginnerTip(x, y, boardState.MoveNumber-1)
+		}
+		levelNote := ""
+		if boardState.LevelChange != 0 && color != e.GetPlayerColor() {
+			levelNote = g.levelChanged(boardState.LevelChange)
+		}
+		repeated := 0
+		if !g.lastTurnPass {
+			repeated = g.repeatOf(boardState.Hash)
+		}
 		g.BoardState = boardState
-		g.moveHistory = append(g.moveHistory, MoveEntry{X: x, Y: y, Color: color})
-		if g.recorder != nil {
-			g.recorder.AddMove(x, y, color)
+		g.chargeMove(color)
+		now := time.Now()
+		g.histMu.Lock()
+		g.moveHistory = append(g.moveHistory, MoveEntry{X: x, Y: y, Color: color, At: now, Think: now.Sub(g.lastMoveAt),
+			Book: boardState.BookMove && color != e.GetPlayerColor(), Comment: levelNote, Hash: boardState.Hash})
+		g.histMu.Unlock()
+		g.lastMoveAt = now
+		if g.lastTurnPass && color != e.GetPlayerColor() && boardState.MoveNumber > lateGameMove(boardState.Width()) {
+			g.estimatePass()
+		}
+		if g.recorder != nil && g.recorder.AddMove(x, y, color) == nil && levelNote != "" {
+			g.recorder.AddComment(g.recorder.MoveCount()-1, levelNote)
+		}
+		if levelNote != "" {
+			g.notice = levelNote
+		} else if repeated > 0 {
+			g.notice = repeatNote(repeated)
 		}
 		g.refreshHint()
 		// Spawn goroutine to avoid deadlock when called from main thread
@@ -240,11 +480,19 @@ func (g *GoBoardUI) ConnectEngine(e engine.GameEngine) error {
 		}()
 	})
 
+	e.OnScoring(g.enterScoring)
+
 	e.OnGameEnd(func(outcome string) {
+		g.stateMu.Lock()
+		defer g.stateMu.Unlock()
+		g.stopClocks()
 		g.finished = true
 		g.BoardState = e.GetBoardState()
+		g.showCount(g.BoardState)
 		if g.recorder != nil {
 			g.recorder.SetResult(outcome)
+			g.recordScoreComment(outcome)
+			g.writeReport(outcome)
 		}
 		g.ResetSelection()
 		g.refreshHint()
@@ -258,25 +506,111 @@ func (g *GoBoardUI) ConnectEngine(e engine.GameEngine) error {
 	return nil
 }
 
+// resetGame clears what the last game left behind, before a new one or a
+// review.
+func (g *GoBoardUI) resetGame() {
+	g.stateMu.Lock()
+	g.finished = false
+	g.histMu.Lock()
+	g.moveHistory = nil
+	g.histMu.Unlock()
+	g.stateMu.Unlock()
+	g.StopGuessing()
+	g.review = nil
+	g.lastMoveAt = time.Now()
+	g.startedAt = g.lastMoveAt
+	g.analyzed = false
+	g.questionable = nil
+	g.reportText = ""
+	g.offBook = nil
+	g.pending = nil
+	g.undosUsed = 0
+	g.notice = ""
+	g.flash = ""
+	g.tip = ""
+	g.passPrompt = false
+	g.scoring = false
+	g.deadMarks = nil
+	g.clearMarkHistory()
+	g.commenting = false
+	g.territory = nil
+	g.hideTerritory = false
+	g.clearAnalysis()
+	g.analyzing = false
+	if g.estimateSched != nil {
+		g.estimateSched.Stop()
+		g.estimateSched = nil
+	}
+	g.clearEstimate()
+	g.passEstimate = ""
+	g.stopClocks()
+	g.loadTotal = 0
+	if g.infoPanel != nil {
+		g.infoPanel.SetHeader("")
+	}
+}
+
+// showProgress follows the engine while it replays a game, updating the
+// board as the position builds up.
+func (g *GoBoardUI) showProgress(done, total int, boardState *types.BoardState) {
+	g.loadDone, g.loadTotal = done, total
+	g.BoardState = boardState
+	g.refreshHint()
+	go func() {
+		g.app.QueueUpdateDraw(func() {})
+	}()
+}
+
+// IsLoading returns true while the engine is replaying moves.
+func (g *GoBoardUI) IsLoading() bool {
+	return g.loadTotal > 0 && g.loadDone < g.loadTotal
+}
+
+// recordScoreComment annotates the final node with the territory/prisoner
+// breakdown so a counted result can be checked later. Resignations have
+// nothing to break down.
+func (g *GoBoardUI) recordScoreComment(outcome string) {
+	bs := g.BoardState
+	if bs == nil || !bs.Scored || g.recorder.MoveCount() == 0 {
+		return
+	}
+	score := g.ruleset().Score(bs.Board, bs.DeadStones, bs.CapturesBlack, bs.CapturesWhite, g.gameConfig.Komi)
+	score.EngineResult = outcome
+	comment := sgf.FormatScoreComment(score)
+	// Keep a note the player left on the last move
+	if moves := g.MovesSnapshot(); len(moves) > 0 && moves[len(moves)-1].Comment != "" {
+		comment = moves[len(moves)-1].Comment + "\n\n" + comment
+	}
+	g.recorder.AddComment(g.recorder.MoveCount()-1, comment)
+}
+
 // PlayMove plays a move at the given coordinates.
 func (g *GoBoardUI) PlayMove(x, y int) {
 	if g.planningMode {
 		g.PlanPlayMove(x, y)
 		return
 	}
-	if g.finished {
+	if g.eng == nil {
 		return
 	}
-	if g.eng == nil {
+	if g.ended() {
+		g.flashError(g.moveError(engine.ErrGameOver, x, y), -1, -1)
 		return
 	}
 	if !g.eng.IsMyTurn() {
+		g.flashError(g.moveError(engine.ErrNotYourTurn, x, y), -1, -1)
 		return
 	}
-	if err := g.eng.PlayMove(x, y); err != nil {
-		// Could show error for illegal move
+	if !g.confirmMove(x, y) || !g.confirmLeaveBook(x, y) {
 		return
 	}
+	switch err := g.eng.PlayMove(x, y); {
+	case err == nil, errors.Is(err, engine.ErrClosed):
+	case errors.Is(err, engine.ErrIllegalMove):
+		g.flashError(g.moveError(err, x, y), x, y)
+	default:
+		g.flashError(g.moveError(err, x, y), -1, -1)
+	}
 }
 
 // Pass passes the current turn.
@@ -285,7 +619,7 @@ func (g *GoBoardUI) Pass() {
 		g.planPass()
 		return
 	}
-	if g.finished {
+	if g.ended() {
 		return
 	}
 	if g.eng == nil {
@@ -294,78 +628,274 @@ func (g *GoBoardUI) Pass() {
 	if !g.eng.IsMyTurn() {
 		return
 	}
+	g.pending = nil
+	if !g.confirmLeaveBook(-1, -1) {
+		return
+	}
 	g.eng.Pass()
 }
 
+// beginnerTip returns the nudge for the player's move at x, y, judged on
+// the position before it, or "" when beginner hints are off, have been
+// shown enough times, or have nothing to say.
+func (g *GoBoardUI) beginnerTip(x, y, moveNumber int) string {
+	if !g.cfg.BeginnerHints || g.edgeHints >= maxEdgeHints || g.BoardState == nil {
+		return ""
+	}
+	hint := edgeHint(g.BoardState.Board, x, y, moveNumber)
+	if hint != "" {
+		g.edgeHints++
+	}
+	return hint
+}
+
+// CanResign returns true when there is a live game to concede: not
+// finished, not still loading, and not in planning mode.
+func (g *GoBoardUI) CanResign() bool {
+	return g.eng != nil && !g.finished && !g.IsLoading() && !g.planningMode && !g.scoring
+}
+
+// Resign concedes the game. The engine may be busy thinking, so this runs
+// off the UI goroutine; the end-of-game callback records the result.
+func (g *GoBoardUI) Resign() {
+	if !g.CanResign() {
+		return
+	}
+	eng := g.eng
+	go eng.Resign()
+}
+
+// confirmLeaveBook returns true if the move at x, y (-1, -1 for a pass) may
+// be played. A move off the opening book only goes through when repeated.
+func (g *GoBoardUI) confirmLeaveBook(x, y int) bool {
+	bx, by, ok := g.gameConfig.Book.Next(g.eng.GetPlayerColor())
+	if !ok || (bx == x && by == y) {
+		g.offBook = nil
+		return true
+	}
+	if g.offBook != nil && g.offBook.X == x && g.offBook.Y == y {
+		g.offBook = nil
+		return true
+	}
+	g.offBook = &types.BoardPos{X: x, Y: y}
+	g.refreshHint()
+	return false
+}
+
+// bookStatus describes the opening book state for the hint bar.
+func (g *GoBoardUI) bookStatus() string {
+	book := g.gameConfig.Book
+	if book.Len() == 0 {
+		return ""
+	}
+	if n := book.LeftAt(); n > 0 {
+		return tag("dimgray", fmt.Sprintf("· left book at move %d", n))
+	}
+	x, y, ok := book.Next(g.eng.GetPlayerColor())
+	if !ok {
+		return tag("dimgray", "· book")
+	}
+	move := coords.Display(x, y, g.gameConfig.BoardSize)
+	if g.offBook != nil {
+		return tag("yellow", fmt.Sprintf("· off book (%s) — again to leave", move))
+	}
+	return tag("dimgray", "· book "+move)
+}
+
 // Close disconnects the engine and finalizes any active recording.
 func (g *GoBoardUI) Close() {
+	g.stopClocks()
+	g.stateMu.Lock()
 	if g.recorder != nil {
 		g.recorder.Close()
 		g.recorder = nil
 	}
+	g.stateMu.Unlock()
 	if g.eng == nil {
 		return
 	}
 	g.eng.Close()
 }
 
-// SetRecorder sets the active SGF recorder.
+// SetRecorder sets the active SGF recorder. From here on it is written on
+// its own goroutine, so a slow disk doesn't hold up the engine.
 func (g *GoBoardUI) SetRecorder(rec *sgf.GameRecord) {
-	g.recorder = rec
+	g.stateMu.Lock()
+	defer g.stateMu.Unlock()
+	if rec == nil {
+		g.recorder = nil
+		return
+	}
+	g.recorder = g.watchRecorder(sgf.NewRecordWriter(rec))
+	// A resumed game keeps counting the undos it already used.
+	fmt.Sscanf(rec.Comment, "undos used: %d", &g.undosUsed)
+}
+
+// watchRecorder redraws the hint bar whenever writing w's file fails,
+// recovers, or finds the file deleted, and returns w.
+func (g *GoBoardUI) watchRecorder(w *sgf.RecordWriter) *sgf.RecordWriter {
+	w.OnStatus(func() {
+		if g.app != nil {
+			// Not on the writer's goroutine, which Close may be waiting for
+			go g.app.QueueUpdateDraw(g.refreshHint)
+		}
+	})
+	return w
+}
+
+// recTag is the hint bar's recording indicator: REC, or what went wrong
+// writing the record, so a game that isn't being saved doesn't look as if
+// it were.
+func recTag(st sgf.RecordStatus) string {
+	switch {
+	case st.Err != nil:
+		return tag("white:red", " NOT SAVED: "+tview.Escape(st.Err.Error())+" ")
+	case st.Recreated:
+		return tag("red", "REC") + " " + tag("yellow", "record file was deleted; wrote it again")
+	}
+	return tag("red", "REC")
 }
 
 // SetGameConfig stores the game configuration for mid-game recording toggle.
 func (g *GoBoardUI) SetGameConfig(gc engine.GameConfig) {
 	g.gameConfig = gc
+	g.level = gc.EngineLevel
+	g.refreshHeader()
+	g.startClocks(gc)
+}
+
+// GameConfig returns the configuration the current game was started with.
+func (g *GoBoardUI) GameConfig() engine.GameConfig {
+	return g.gameConfig
 }
 
 // SetMoveHistory populates the move history from loaded game data.
 func (g *GoBoardUI) SetMoveHistory(moves [][3]int) {
+	g.histMu.Lock()
+	defer g.histMu.Unlock()
 	g.moveHistory = nil
 	for _, m := range moves {
 		g.moveHistory = append(g.moveHistory, MoveEntry{X: m[1], Y: m[2], Color: m[0]})
 	}
 }
 
-// UndoMove undoes the last player+engine move pair so it's the player's turn again.
+// SetMoveComments attaches comments read from an SGF to the move history,
+// keyed by move index.
+func (g *GoBoardUI) SetMoveComments(comments map[int]string) {
+	g.histMu.Lock()
+	defer g.histMu.Unlock()
+	for i, c := range comments {
+		if i >= 0 && i < len(g.moveHistory) {
+			g.moveHistory[i].Comment = c
+		}
+	}
+}
+
+// MovesSnapshot returns a copy of the game's move history. It is safe to call
+// from any goroutine.
+func (g *GoBoardUI) MovesSnapshot() []MoveEntry {
+	g.histMu.Lock()
+	defer g.histMu.Unlock()
+	return append([]MoveEntry(nil), g.moveHistory...)
+}
+
+// undosLeft returns how many undos remain this game. limited is false when
+// the game allows unlimited undos.
+func (g *GoBoardUI) undosLeft() (left int, limited bool) {
+	max := g.gameConfig.MaxUndos
+	if max <= 0 {
+		return 0, false
+	}
+	if g.undosUsed >= max {
+		return 0, true
+	}
+	return max - g.undosUsed, true
+}
+
+// undosComment is the root comment recording how many undos a game used.
+func undosComment(n int) string {
+	return fmt.Sprintf("undos used: %d", n)
+}
+
+// UndoMove takes back the player's last move and the plies after it, so
+// it's the player's turn again just before that move: usually the move and
+// the engine's reply. If the engine can't take them all back, the ones it
+// did are played again and the player is told why nothing changed.
 func (g *GoBoardUI) UndoMove() {
+	// Held throughout, so the game can't end between the engine's undo and
+	// the record's; Undo doesn't report back
+	g.stateMu.Lock()
+	defer g.stateMu.Unlock()
 	if g.finished || g.eng == nil {
 		return
 	}
 	if !g.eng.IsMyTurn() {
 		return
 	}
-	// Need at least 2 moves to undo (engine response + player move)
-	if len(g.moveHistory) < 2 {
+	plies := undoPlies(g.moveHistory, g.eng.GetPlayerColor())
+	if plies == 0 {
+		g.notice = "nothing to undo: you haven't moved yet"
+		g.refreshHint()
 		return
 	}
-
-	// Undo engine's last response
-	if err := g.eng.Undo(); err != nil {
+	if left, limited := g.undosLeft(); limited && left == 0 {
+		g.notice = "no undos left"
+		g.refreshHint()
 		return
 	}
-	// Undo player's last move
-	if err := g.eng.Undo(); err != nil {
+
+	// Take back the engine's reply, if any, then the player's move
+	undone := 0
+	var err error
+	for ; undone < plies; undone++ {
+		if err = g.eng.Undo(); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		g.notice = "can't undo: " + err.Error()
+		if undone > 0 {
+			// Stopped partway, maybe on the engine's turn with nothing to
+			// make it move: put back what was taken
+			if rerr := g.eng.ResetAndReplay(g.historyMoves()); rerr != nil {
+				g.notice += "; the game couldn't be restored: " + rerr.Error()
+			}
+			g.BoardState = g.eng.GetBoardState()
+		}
+		g.refreshHint()
 		return
 	}
 
 	// Truncate move history
-	g.moveHistory = g.moveHistory[:len(g.moveHistory)-2]
+	g.histMu.Lock()
+	g.moveHistory = g.moveHistory[:len(g.moveHistory)-undone]
+	g.histMu.Unlock()
+	g.undosUsed++
+	g.passPrompt = false
+	g.pending = nil
+	g.passEstimate = ""
+	g.clearAnalysis()
+	g.clearEstimate()
 
 	// Truncate SGF recorder
 	if g.recorder != nil {
-		g.recorder.UndoMoves(2)
+		g.recorder.Begin()
+		g.recorder.UndoMoves(undone)
+		g.recorder.SetComment(undosComment(g.undosUsed))
+		g.recorder.Commit()
 	}
 
 	// Resync board state from engine
 	g.BoardState = g.eng.GetBoardState()
-	g.lastTurnPass = false
+	g.dropQuestionable(g.BoardState.MoveNumber)
 
-	// Restore last move indicator from history
+	// Restore last move indicator, and any pass to answer, from history
+	g.lastTurnPass = false
 	if len(g.moveHistory) > 0 {
 		last := g.moveHistory[len(g.moveHistory)-1]
 		g.BoardState.LastMove.X = last.X
 		g.BoardState.LastMove.Y = last.Y
+		g.lastTurnPass = last.X == -1 && last.Y == -1
 	}
 
 	g.refreshHint()
@@ -374,6 +904,18 @@ func (g *GoBoardUI) UndoMove() {
 	}()
 }
 
+// undoPlies returns how many moves to take back so that it is player's
+// turn again, just before their last move: that move and any replies
+// after it. It is 0 if player hasn't moved yet.
+func undoPlies(history []MoveEntry, player int) int {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Color == player {
+			return len(history) - i
+		}
+	}
+	return 0
+}
+
 // IsPlanningMode returns true if planning mode is active.
 func (g *GoBoardUI) IsPlanningMode() bool {
 	return g.planningMode
@@ -386,27 +928,27 @@ func (g *GoBoardUI) TogglePlanningMode() {
 	if g.planningMode {
 		// Exit planning mode - restore pre-plan state
 		g.BoardState = g.prePlanBoard
+		g.histMu.Lock()
 		g.moveHistory = g.prePlanHistory
+		g.histMu.Unlock()
 		g.planningMode = false
 		g.planTree = nil
-		g.planBoard = nil
+		g.plan = nil
 		g.prePlanBoard = nil
 		g.prePlanHistory = nil
 	} else {
-		if g.finished || g.BoardState == nil {
+		if g.finished || g.BoardState == nil || g.IsLoading() {
 			return
 		}
 		// Enter planning mode - snapshot current state
 		g.prePlanBoard = g.copyBoardState()
+		g.histMu.Lock()
 		g.prePlanHistory = make([]MoveEntry, len(g.moveHistory))
 		copy(g.prePlanHistory, g.moveHistory)
+		g.histMu.Unlock()
 
 		// Initialize plan board from current board
-		size := g.BoardState.Width()
-		g.planBoard = sgf.MakeBoard(size)
-		for y := 0; y < size; y++ {
-			copy(g.planBoard[y], g.BoardState.Board[y])
-		}
+		g.startPlanPosition()
 
 		// Set next color to play
 		if g.eng != nil {
@@ -423,6 +965,7 @@ func (g *GoBoardUI) TogglePlanningMode() {
 		g.planTree = sgf.NewGameTree()
 		g.planningMode = true
 	}
+	g.notice = ""
 	g.refreshHint()
 	go func() {
 		g.app.QueueUpdateDraw(func() {})
@@ -431,27 +974,20 @@ func (g *GoBoardUI) TogglePlanningMode() {
 
 // PlanPlayMove places a stone locally during planning mode.
 func (g *GoBoardUI) PlanPlayMove(x, y int) {
-	if !g.planningMode || g.planBoard == nil {
+	if !g.planningMode || g.plan == nil {
 		return
 	}
-	size := g.BoardState.Width()
-	if x < 0 || x >= size || y < 0 || y >= size {
-		return
-	}
-	// Must be empty intersection
-	if g.planBoard[y][x] != 0 {
-		return
-	}
-
-	// Place stone and handle captures
-	g.planBoard[y][x] = g.planColor
-	sgf.RemoveCaptures(g.planBoard, size, x, y, g.planColor)
 
-	// Check for suicide: if the placed stone's group has no liberties after captures
-	if !sgf.HasLiberty(g.planBoard, size, x, y, g.planColor) {
-		g.planBoard[y][x] = 0 // undo the placement
+	// Place stone and handle captures, as the game's rules allow
+	if _, err := g.ruleset().ApplyMove(g.plan, x, y, g.planColor); err != nil {
+		// Ko is worth explaining; a taken point or a suicide isn't
+		if errors.Is(err, rules.ErrKo) || errors.Is(err, rules.ErrSuperko) {
+			g.notice = err.Error()
+			g.refreshHint()
+		}
 		return
 	}
+	g.notice = ""
 
 	// Build SGF move string
 	colorChar := "B"
@@ -482,7 +1018,9 @@ func (g *GoBoardUI) planPass() {
 	move := fmt.Sprintf(";%s[]", colorChar)
 	g.planTree.AddMove(move)
 	g.planLastMove = [2]int{-1, -1}
+	g.plan.Pass()
 	g.planColor = oppositeColor(g.planColor)
+	g.notice = ""
 	g.refreshHint()
 	go func() {
 		g.app.QueueUpdateDraw(func() {})
@@ -572,26 +1110,56 @@ func (g *GoBoardUI) ResumeFromPlan() {
 		allMoves = append(allMoves, [3]int{color, x, y})
 	}
 
-	// Reset engine and replay all moves
-	if err := g.eng.ResetAndReplay(allMoves); err != nil {
+	// Reset engine and replay all moves off the UI goroutine so progress
+	// can be drawn; planning input is closed until it finishes.
+	g.planningMode = false
+	g.loadLabel, g.loadDone, g.loadTotal = "Replaying", 0, len(allMoves)
+	g.refreshHint()
+	go func() {
+		err := g.eng.ResetAndReplay(allMoves)
+		g.app.QueueUpdateDraw(func() {
+			g.finishResume(allMoves, err)
+		})
+	}()
+}
+
+// finishResume completes ResumeFromPlan once the engine has replayed allMoves.
+func (g *GoBoardUI) finishResume(allMoves [][3]int, err error) {
+	g.loadTotal = 0
+	if errors.Is(err, engine.ErrClosed) {
+		// The game was left while replaying; nothing to resume into.
+		return
+	}
+	if err != nil {
 		// Failed to resume, just exit planning
+		g.planningMode = true
 		g.TogglePlanningMode()
 		return
 	}
 
-	// Update move history
-	g.moveHistory = nil
-	for _, m := range allMoves {
+	g.clearAnalysis()
+	g.clearEstimate()
+
+	// Update move history, keeping timing for moves that were actually played
+	g.stateMu.Lock()
+	g.histMu.Lock()
+	g.moveHistory = append([]MoveEntry(nil), g.prePlanHistory...)
+	for _, m := range allMoves[len(g.prePlanHistory):] {
 		g.moveHistory = append(g.moveHistory, MoveEntry{X: m[1], Y: m[2], Color: m[0]})
 	}
+	g.histMu.Unlock()
 
-	// Update SGF recorder
+	// Add the plan's moves to the SGF record, rewriting the file once
+	// rather than per move. The moves before it are already there, with
+	// any comments and markup an opened file gave them.
 	if g.recorder != nil {
-		g.recorder.UndoMoves(len(g.prePlanHistory))
-		for _, m := range allMoves {
+		g.recorder.Begin()
+		for _, m := range allMoves[len(g.prePlanHistory):] {
 			g.recorder.AddMove(m[1], m[2], m[0])
 		}
+		g.recorder.Commit()
 	}
+	g.stateMu.Unlock()
 
 	// Sync board state from engine
 	g.BoardState = g.eng.GetBoardState()
@@ -599,7 +1167,7 @@ func (g *GoBoardUI) ResumeFromPlan() {
 	// Exit planning mode without restoring snapshot
 	g.planningMode = false
 	g.planTree = nil
-	g.planBoard = nil
+	g.plan = nil
 	g.prePlanBoard = nil
 	g.prePlanHistory = nil
 
@@ -613,10 +1181,7 @@ func (g *GoBoardUI) ResumeFromPlan() {
 func (g *GoBoardUI) rebuildPlanBoard() {
 	size := g.BoardState.Width()
 	// Start from pre-plan board snapshot
-	g.planBoard = sgf.MakeBoard(size)
-	for y := 0; y < size; y++ {
-		copy(g.planBoard[y], g.prePlanBoard.Board[y])
-	}
+	g.startPlanPosition()
 
 	// Determine starting color from pre-plan state
 	startColor := g.prePlanBoard.PlayerToMove
@@ -624,6 +1189,7 @@ func (g *GoBoardUI) rebuildPlanBoard() {
 	// Replay path from root
 	path := g.planTree.PathFromRoot()
 	g.planLastMove = [2]int{g.prePlanBoard.LastMove.X, g.prePlanBoard.LastMove.Y}
+	g.notice = ""
 	currentColor := startColor
 
 	for _, moveStr := range path {
@@ -632,12 +1198,13 @@ func (g *GoBoardUI) rebuildPlanBoard() {
 			currentColor = oppositeColor(color)
 		}
 		if x >= 0 && y >= 0 && x < size && y < size {
-			g.planBoard[y][x] = color
-			sgf.RemoveCaptures(g.planBoard, size, x, y, color)
+			// Allowed when it was played
+			g.ruleset().ApplyMove(g.plan, x, y, color)
 			g.planLastMove = [2]int{x, y}
 		} else {
 			// pass
 			g.planLastMove = [2]int{-1, -1}
+			g.plan.Pass()
 		}
 	}
 
@@ -661,15 +1228,49 @@ func (g *GoBoardUI) copyBoardState() *types.BoardState {
 		copy(boardCopy[i], g.BoardState.Board[i])
 	}
 	return &types.BoardState{
-		MoveNumber:   g.BoardState.MoveNumber,
-		PlayerToMove: g.BoardState.PlayerToMove,
-		Phase:        g.BoardState.Phase,
-		Board:        boardCopy,
-		Outcome:      g.BoardState.Outcome,
-		LastMove:     g.BoardState.LastMove,
+		MoveNumber:    g.BoardState.MoveNumber,
+		PlayerToMove:  g.BoardState.PlayerToMove,
+		Phase:         g.BoardState.Phase,
+		Board:         boardCopy,
+		Outcome:       g.BoardState.Outcome,
+		LastMove:      g.BoardState.LastMove,
+		BookMove:      g.BoardState.BookMove,
+		Hash:          g.BoardState.Hash,
+		CapturesBlack: g.BoardState.CapturesBlack,
+		CapturesWhite: g.BoardState.CapturesWhite,
 	}
 }
 
+// prevMove returns the move played before the last one, in planning mode
+// counting plan moves after the game's. It returns (-1,-1) when there is
+// none or it was a pass.
+func (g *GoBoardUI) prevMove() (x, y int) {
+	if g.planningMode && g.planTree != nil {
+		path := g.planTree.PathFromRoot()
+		switch {
+		case len(path) >= 2:
+			_, x, y = parsePlanMove(path[len(path)-2])
+			return x, y
+		case len(path) == 1:
+			return lastEntry(g.prePlanHistory, 1)
+		default:
+			return lastEntry(g.prePlanHistory, 2)
+		}
+	}
+	g.histMu.Lock()
+	defer g.histMu.Unlock()
+	return lastEntry(g.moveHistory, 2)
+}
+
+// lastEntry returns the point of the n-th last move, or (-1,-1).
+func lastEntry(moves []MoveEntry, n int) (x, y int) {
+	if len(moves) < n {
+		return -1, -1
+	}
+	m := moves[len(moves)-n]
+	return m.X, m.Y
+}
+
 // parsePlanMove extracts color, x, y from an SGF move string like ";B[pd]" or ";W[]".
 func parsePlanMove(move string) (color, x, y int) {
 	if len(move) < 3 {
@@ -711,9 +1312,22 @@ func oppositeColor(color int) int {
 	return 1
 }
 
+// IsRecording returns true if the game is being recorded.
+func (g *GoBoardUI) IsRecording() bool {
+	g.stateMu.Lock()
+	defer g.stateMu.Unlock()
+	return g.recorder != nil
+}
+
 // ToggleRecording toggles SGF recording on or off.
 // When toggling on mid-game, captures the current board position via AB[]/AW[].
 func (g *GoBoardUI) ToggleRecording(cfg *config.Config) {
+	if g.IsLoading() {
+		// The position is still being replayed; a snapshot now would be wrong
+		return
+	}
+	g.stateMu.Lock()
+	defer g.stateMu.Unlock()
 	if g.recorder != nil {
 		// Stop recording
 		g.recorder.Close()
@@ -721,34 +1335,69 @@ func (g *GoBoardUI) ToggleRecording(cfg *config.Config) {
 	} else {
 		// Start recording
 		gc := g.gameConfig
-		rec, err := sgf.NewGameRecord(config.HistoryDir(), gc.BoardSize, gc.Komi, gc.PlayerColor, gc.EngineLevel)
+		rec, err := sgf.NewGameRecord(config.HistoryDir(), gc.BoardSize, gc.Komi, gc.PlayerColor, gc.EngineLevel, gc.RecordOptions())
 		if err != nil {
 			g.refreshHint()
 			return
 		}
+		// The header and snapshot are written once, at the Commit below
+		rec.Begin()
+		if gc.EngineName != "" && gc.EngineName != config.GnuGoProfile {
+			rec.SetOpponent(gc.EngineName)
+		}
+		if gc.PlayerName != "" {
+			rec.SetPlayerName(gc.PlayerName)
+		}
+		w := sgf.NewRecordWriter(rec)
 		// If game is in progress, snapshot current position
 		if g.BoardState != nil && g.BoardState.MoveNumber > 0 {
-			rec.AddSetupPosition(g.BoardState.Board)
+			w.AddSetupPosition(g.BoardState.Board, g.BoardState.PlayerToMove, g.BoardState.MoveNumber)
+		}
+		if g.undosUsed > 0 {
+			w.SetComment(undosComment(g.undosUsed))
 		}
-		g.recorder = rec
+		w.Commit()
+		g.recorder = g.watchRecorder(w)
 	}
 	g.refreshHint()
 }
 
-func (g *GoBoardUI) SetConfig(c *config.Config) {
-	g.styles = []tcell.Color{
-		tcell.PaletteColor(c.Theme.Colors.BoardColor),        // 0
-		tcell.PaletteColor(c.Theme.Colors.BlackColor),        // 1
-		tcell.PaletteColor(c.Theme.Colors.WhiteColor),        // 2
-		tcell.PaletteColor(c.Theme.Colors.BoardColorAlt),     // 3
-		tcell.PaletteColor(c.Theme.Colors.BlackColorAlt),     // 4
-		tcell.PaletteColor(c.Theme.Colors.WhiteColorAlt),     // 5
-		tcell.PaletteColor(c.Theme.Colors.CursorColorFG),     // 6
-		tcell.PaletteColor(c.Theme.Colors.LastPlayedColorBG), // 7
-		tcell.PaletteColor(c.Theme.Colors.CursorColorBG),     // 8
-		tcell.PaletteColor(c.Theme.Colors.LineColor),         // 9
+// themeColor resolves a theme color from the config: a truecolor as is, a
+// palette index from the terminal's palette.
+func themeColor(v int) tcell.Color {
+	if rgb, ok := config.RGB(v); ok {
+		return tcell.NewHexColor(rgb)
 	}
+	return tcell.PaletteColor(v)
+}
+
+func (g *GoBoardUI) SetConfig(c *config.Config) {
 	g.cfg = c
+	if c.Theme.NoColor {
+		g.styles = make([]tcell.Color, analysisStyle+maxCandidates)
+		for i := range g.styles {
+			g.styles[i] = tcell.ColorDefault
+		}
+		return
+	}
+	g.styles = []tcell.Color{
+		themeColor(c.Theme.Colors.BoardColor),        // 0
+		themeColor(c.Theme.Colors.BlackColor),        // 1
+		themeColor(c.Theme.Colors.WhiteColor),        // 2
+		themeColor(c.Theme.Colors.BoardColorAlt),     // 3
+		themeColor(c.Theme.Colors.BlackColorAlt),     // 4
+		themeColor(c.Theme.Colors.WhiteColorAlt),     // 5
+		themeColor(c.Theme.Colors.CursorColorFG),     // 6
+		themeColor(c.Theme.Colors.LastPlayedColorBG), // 7
+		themeColor(c.Theme.Colors.CursorColorBG),     // 8
+		themeColor(c.Theme.Colors.LineColor),         // 9
+		themeColor(c.Theme.Colors.PrevPlayedColorBG), // 10
+		themeColor(c.Theme.Colors.EdgeColorBG),       // 11
+		themeColor(c.Theme.Colors.TerritoryBlackBG),  // 12
+		themeColor(c.Theme.Colors.TerritoryWhiteBG),  // 13
+	}
+	g.styles = append(g.styles, analysisShades(
+		themeColor(c.Theme.Colors.AnalysisColorBG), themeColor(c.Theme.Colors.BoardColor))...) // 14-18
 }
 
 // SetKomi sets the komi value on the info panel.
@@ -763,9 +1412,15 @@ func (g *GoBoardUI) refreshHint() {
 	if g.infoPanel != nil {
 		if g.planningMode && g.planTree != nil {
 			g.infoPanel.SetPlanningMode(g.planTree)
+			g.infoPanel.SetCaptures(g.plan.Captures[1], g.plan.Captures[2])
 		} else {
 			g.infoPanel.ClearPlanningMode()
+			if g.BoardState != nil {
+				g.infoPanel.SetCaptures(g.BoardState.CapturesBlack, g.BoardState.CapturesWhite)
+			}
 		}
+		g.infoPanel.SetCurrentMove(g.ReviewPosition())
+		g.infoPanel.setASCII(g.ascii())
 		g.infoPanel.SetBoardState(g.BoardState)
 	}
 
@@ -783,7 +1438,31 @@ func (g *GoBoardUI) refreshHint() {
 
 	var status, controls string
 
-	if g.planningMode {
+	if g.IsLoading() {
+		// Engine busy replaying a loaded game or a resumed plan
+		status = fmt.Sprintf("%s %d/%d %s", tag("yellow", g.loadLabel+"…"), g.loadDone, g.loadTotal,
+			tag("dimgray", progressBar(g.loadDone, g.loadTotal, 12)))
+		controls = keyHints("q", "quit")
+	} else if g.commenting {
+		// Typing a note for the last move
+		status = fmt.Sprintf("%s %s%s", tag("yellow", "COMMENT"), tview.Escape(string(g.commentText)), tag("white", "▏"))
+		controls = keyHints("⏎", "save", "esc", "cancel")
+	} else if g.guessing {
+		// Guessing the reviewed game's next move
+		status = g.guessStatus()
+		if g.guessReview != nil {
+			controls = keyHints("⏎", "next", "g", "stop guessing", "q", "back")
+		} else {
+			controls = keyHints("hjkl", "move", "⏎", "guess", "p", "pass", "g", "stop guessing", "q", "back")
+		}
+	} else if g.review != nil {
+		// Stepping through a saved game
+		status = fmt.Sprintf("%s move %d/%d", tag("yellow", "REVIEW"), g.review.Position(), g.review.Len())
+		if g.reviewCount != "" {
+			status += fmt.Sprintf("  count %s %s", g.reviewCount, tag("dimgray", "· no stones marked dead"))
+		}
+		controls = keyHints("←→", "step", "home end", "first/last", "g", "guess", "s", "count", "c", "continue", "b", "branch", "y", "copy", "q", "back")
+	} else if g.planningMode {
 		// Planning mode state
 		stone := "●"
 		colorName := "Black"
@@ -793,14 +1472,33 @@ func (g *GoBoardUI) refreshHint() {
 		}
 		varInfo := ""
 		if g.planTree != nil && g.planTree.NumVariations() > 1 {
-			varInfo = fmt.Sprintf("  [dimgray]var %d/%d[-]", g.planTree.VariationIndex()+1, g.planTree.NumVariations())
+			varInfo = "  " + tag("dimgray", fmt.Sprintf("var %d/%d", g.planTree.VariationIndex()+1, g.planTree.NumVariations()))
 		}
-		status = fmt.Sprintf("[yellow]PLAN[-] %s %s%s", stone, colorName, varInfo)
-		controls = "[dimgray]⏎[-] play  [dimgray]p[-] pass  [dimgray][ ][-] nav  [dimgray]{ }[-] branch  [dimgray]a[-] exit  [dimgray]A[-] resume"
+		status = fmt.Sprintf("%s %s %s%s", tag("yellow", "PLAN"), stone, colorName, varInfo)
+		if g.notice != "" {
+			status += "  " + tag("red", g.notice)
+		}
+		controls = keyHints("⏎", "play", "p", "pass", "[ ]", "nav", "{ }", "branch", "a", "exit", "A", "resume")
+	} else if g.scoring {
+		// Both passed: marking dead stones
+		status = fmt.Sprintf("%s %s  %s", tag("yellow", "SCORING"), g.scoringResult(),
+			tag("dimgray", fmt.Sprintf("· %d dead", len(g.deadMarks))))
+		controls = keyHints("hjkl", "move", "⏎", "toggle dead", "u U", "undo/redo", "c", "confirm", "esc", "resume play", "q", "quit")
 	} else if g.finished {
 		// Game over state
-		status = fmt.Sprintf("[::b]Game Complete[::-]  %s", g.BoardState.Outcome)
-		controls = "[dimgray]q[-] quit"
+		result := sgf.FormatResult(g.BoardState.Outcome)
+		if g.eng != nil {
+			result = resultTag(sgf.ResultFor(g.BoardState.Outcome, g.eng.GetPlayerColor()), result)
+		}
+		status = fmt.Sprintf("%s  %s", tag("::b", "Game Complete"), result)
+		if local, ok := g.countDisagrees(); ok {
+			status += "  " + tag("yellow", "local count: "+local)
+		}
+		if g.territory != nil {
+			controls = keyHints("t", "territory", ";", "comment", "n", "rematch", "q", "quit")
+		} else {
+			controls = keyHints(";", "comment", "n", "rematch", "q", "quit")
+		}
 	} else {
 		// Active game state
 		if g.eng != nil && g.eng.IsMyTurn() {
@@ -810,21 +1508,59 @@ func (g *GoBoardUI) refreshHint() {
 				stone = "○"
 				color = "White"
 			}
-			if g.lastTurnPass {
-				status = fmt.Sprintf("%s Your move (%s)  [dimgray]· opponent passed[-]", stone, color)
+			msg := passMessage(g.engineName(), g.BoardState.MoveNumber, g.BoardState.Width(), g.passEstimate)
+			if g.lastTurnPass && msg != "" {
+				status = fmt.Sprintf("%s Your move (%s)  %s", stone, color, tag("yellow", msg))
+			} else if g.lastTurnPass {
+				status = fmt.Sprintf("%s Your move (%s)  %s", stone, color, tag("dimgray", "· opponent passed"))
 			} else {
 				status = fmt.Sprintf("%s Your move (%s)", stone, color)
 			}
 		} else {
-			status = "[dimgray]◌[-] Thinking..."
+			status = tag("dimgray", "◌") + " Thinking..."
+		}
+		if book := g.bookStatus(); book != "" {
+			status += "  " + book
+		}
+		if left, limited := g.undosLeft(); limited {
+			status += "  " + tag("dimgray", fmt.Sprintf("· undos left: %d", left))
+		}
+		if g.notice != "" {
+			status += "  " + tag("red", g.notice)
+		}
+		if prompt := g.confirmPrompt(); prompt != "" {
+			status += "  " + prompt
+		}
+		if g.tip != "" {
+			status += "  " + tag("yellow", g.tip)
 		}
-		controls = "[dimgray]hjkl[-] move  [dimgray]⏎[-] play  [dimgray]p[-] pass  [dimgray]u[-] undo  [dimgray]r[-] rec  [dimgray]a[-] plan  [dimgray]f[-] focus  [dimgray]q[-] quit"
+		if g.analyzing || g.estimating {
+			status += "  " + tag("dimgray", "· analyzing…")
+		}
+		if g.estimate != nil {
+			status += "  " + tag("yellow", "engine's view — not final: "+sgf.FormatResult(g.estimateResult()))
+		}
+		if g.PassPromptActive() {
+			status += "  " + tag("yellow", "nothing useful left? (estimate) — ⏎ to pass")
+		}
+		controls = keyHints("hjkl", "move", "⏎", "play", "p", "pass", "u", "undo", "R", "resign", "t", "analyze", "e", "borders", "r", "rec", ";", "comment", "a", "plan", "f", "focus", "?", "keys", "q", "quit")
+	}
+
+	if g.flash != "" {
+		color := "red"
+		if g.flashNote {
+			color = "green"
+		}
+		status += "  " + tag(color, g.flash)
+	}
+	if g.count > 0 {
+		status += "  " + tag("yellow", fmt.Sprintf("%d…", g.count))
 	}
 
 	// Prepend REC indicator when recording
 	rec := ""
 	if g.recorder != nil {
-		rec = "[red]REC[-] "
+		rec = recTag(g.recorder.Status()) + " "
 	}
 
 	// Build the horizontal bar: status left, controls right
@@ -841,7 +1577,49 @@ func (g *GoBoardUI) refreshHint() {
 		spacer += " "
 	}
 
-	g.hint.SetText(fmt.Sprintf("  %s%s%s%s", rec, status, spacer, controls))
+	text := fmt.Sprintf("  %s%s%s%s", rec, status, spacer, controls)
+	if g.ascii() {
+		text = asciiText(text)
+	}
+	g.hint.SetText(text)
+}
+
+// keyHints formats alternating key/label pairs for the hint bar,
+// e.g. keyHints("p", "pass", "q", "quit") renders "p pass  q quit" with dimmed keys.
+func keyHints(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		b.WriteString(tag("dimgray", pairs[i]))
+		b.WriteString(" ")
+		b.WriteString(pairs[i+1])
+	}
+	return b.String()
+}
+
+// progressBar renders done/total as a bar of width cells, e.g. "█████░░░".
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	if filled > width {
+		filled = width
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// SetNotice shows a one-off message in the hint bar until the next move.
+func (g *GoBoardUI) SetNotice(msg string) {
+	g.notice = msg
+	g.refreshHint()
+}
+
+// PassPromptActive returns true while the pass assist is suggesting a pass.
+func (g *GoBoardUI) PassPromptActive() bool {
+	return g.passPrompt && !g.finished && !g.planningMode
 }
 
 // IsFinished returns true if the game is over.
@@ -858,84 +1636,12 @@ func drawStoneCell(s tcell.Screen, c tcell.Style, r rune, x, y, l, t int) {
 }
 
 // drawGridCell draws a cell using box-drawing characters for grid lines
-func drawGridCell(s tcell.Screen, c tcell.Style, r rune, x, y, l, t, boardWidth int, hasStoneRight bool) {
+func drawGridCell(s tcell.Screen, c tcell.Style, r rune, x, y, l, t, boardWidth int, hasStoneRight, ascii bool) {
 	// 2-char cell: [intersection][right-line]
 	s.SetContent(l+x*2, t+y, r, nil, c)
 
 	// Right connector: space if at right edge or if there's a stone to the right
-	rightConn := '─'
-	if x == boardWidth-1 || hasStoneRight {
-		rightConn = ' '
-	}
-	s.SetContent(l+x*2+1, t+y, rightConn, nil, c)
-}
-
-// getGridRune returns the appropriate box-drawing character for a grid position
-func getGridRune(x, y, width, height int, isHoshi bool) rune {
-	if isHoshi {
-		return '◦' // Subtle star point marker
-	}
-
-	isTop := y == 0
-	isBottom := y == height-1
-	isLeft := x == 0
-	isRight := x == width-1
-
-	switch {
-	case isTop && isLeft:
-		return '┌'
-	case isTop && isRight:
-		return '┐'
-	case isBottom && isLeft:
-		return '└'
-	case isBottom && isRight:
-		return '┘'
-	case isTop:
-		return '┬'
-	case isBottom:
-		return '┴'
-	case isLeft:
-		return '├'
-	case isRight:
-		return '┤'
-	default:
-		return '┼'
-	}
-}
-
-// isHoshiPoint checks if a position is a hoshi (star point) on the board
-func isHoshiPoint(x, y, boardSize int) bool {
-	var hoshiPositions [][2]int
-
-	switch boardSize {
-	case 9:
-		hoshiPositions = [][2]int{
-			{2, 2}, {2, 6},
-			{4, 4},
-			{6, 2}, {6, 6},
-		}
-	case 13:
-		hoshiPositions = [][2]int{
-			{3, 3}, {3, 9},
-			{6, 6},
-			{9, 3}, {9, 9},
-		}
-	case 19:
-		hoshiPositions = [][2]int{
-			{3, 3}, {3, 9}, {3, 15},
-			{9, 3}, {9, 9}, {9, 15},
-			{15, 3}, {15, 9}, {15, 15},
-		}
-	default:
-		return false
-	}
-
-	for _, pos := range hoshiPositions {
-		if x == pos[0] && y == pos[1] {
-			return true
-		}
-	}
-	return false
+	s.SetContent(l+x*2+1, t+y, render.Connector(x, boardWidth, hasStoneRight, ascii), nil, c)
 }
 
 func drawCoordinates(s tcell.Screen, x, y int, ui *GoBoardUI) {
@@ -953,6 +1659,10 @@ func drawCoordinates(s tcell.Screen, x, y int, ui *GoBoardUI) {
 	style := tcell.StyleDefault
 	highlight := tcell.StyleDefault.Background(ui.styles[8])
 	lpHighlight := tcell.StyleDefault.Background(ui.styles[7])
+	if ui.cfg.Theme.NoColor {
+		highlight = tcell.StyleDefault.Reverse(true)
+		lpHighlight = tcell.StyleDefault.Bold(true)
+	}
 
 	for ix := 0; ix < w; ix++ {
 		_style := style
@@ -974,7 +1684,7 @@ func drawCoordinates(s tcell.Screen, x, y int, ui *GoBoardUI) {
 		} else if iyInv == lmY {
 			_style = lpHighlight
 		}
-		displayNum := iy + 1
+		displayNum := coords.DisplayRow(iyInv, h)
 		tensRune := ' '
 		if displayNum >= 10 {
 			tensRune = rune('0' + int((displayNum-(displayNum%10))/10))
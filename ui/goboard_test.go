@@ -0,0 +1,535 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+// fakeEngine is a GameEngine that accepts every move and replies to nothing.
+type fakeEngine struct {
+	state      *types.BoardState
+	undos      int
+	onMove     func(x, y, color int, bs *types.BoardState)
+	onProgress func(done, total int, bs *types.BoardState)
+	onScoring  func(bs *types.BoardState)
+	onGameEnd  func(outcome string)
+	confirmed  [][2]int
+	resumed    bool
+	top        []engine.Candidate
+	lostOnTime bool
+	playErr    error
+	player     int
+	undoErr    error // Undo fails with it once undoOK calls have gone through
+	undoOK     int
+	replayed   [][3]int // the moves last given to ResetAndReplay
+}
+
+func newFakeEngine(size int) *fakeEngine {
+	return &fakeEngine{state: types.NewBoardState(size), player: 1}
+}
+
+func (f *fakeEngine) Connect() error                                            { return nil }
+func (f *fakeEngine) GetBoardState() *types.BoardState                          { return f.state }
+func (f *fakeEngine) PlayMove(x, y int) error                                   { return f.playErr }
+func (f *fakeEngine) Pass() error                                               { return nil }
+func (f *fakeEngine) Resign() error                                             { return nil }
+func (f *fakeEngine) LoseOnTime() error                                         { f.lostOnTime = true; return nil }
+func (f *fakeEngine) IsMyTurn() bool                                            { return true }
+func (f *fakeEngine) GetPlayerColor() int                                       { return f.player }
+func (f *fakeEngine) ResetAndReplay(moves [][3]int) error                       { f.replayed = moves; return nil }
+func (f *fakeEngine) OnGameEnd(cb func(outcome string))                         { f.onGameEnd = cb }
+func (f *fakeEngine) OnProgress(cb func(done, total int, bs *types.BoardState)) { f.onProgress = cb }
+func (f *fakeEngine) Close()                                                    {}
+func (f *fakeEngine) OnMove(cb func(x, y, c int, bs *types.BoardState))         { f.onMove = cb }
+func (f *fakeEngine) OnScoring(cb func(bs *types.BoardState))                   { f.onScoring = cb }
+func (f *fakeEngine) ConfirmScore(dead [][2]int) error                          { f.confirmed = dead; return nil }
+func (f *fakeEngine) ResumePlay() error                                         { f.resumed = true; return nil }
+func (f *fakeEngine) TopMoves() ([]engine.Candidate, error)                     { return f.top, nil }
+func (f *fakeEngine) EstimateDead() ([][2]int, error)                           { return nil, nil }
+func (f *fakeEngine) CanEstimate() bool                                         { return false }
+
+func (f *fakeEngine) Undo() error {
+	if f.undoErr != nil && f.undos >= f.undoOK {
+		return f.undoErr
+	}
+	f.undos++
+	return nil
+}
+
+// newTestBoard returns a board connected to a fake engine with n moves of history.
+func newTestBoard(t *testing.T, gc engine.GameConfig, n int) (*GoBoardUI, *fakeEngine) {
+	t.Helper()
+	cfg := config.DefaultConfig
+	g := NewGoBoard(tview.NewApplication(), &cfg, tview.NewTextView())
+	eng := newFakeEngine(9)
+	if err := g.ConnectEngine(eng); err != nil {
+		t.Fatalf("ConnectEngine: %v", err)
+	}
+	g.SetGameConfig(gc)
+	moves := make([][3]int, n)
+	for i := range moves {
+		moves[i] = [3]int{i%2 + 1, i, 0}
+	}
+	g.SetMoveHistory(moves)
+	return g, eng
+}
+
+func TestUndoAllowance(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, MaxUndos: 2}, 8)
+
+	if left, limited := g.undosLeft(); !limited || left != 2 {
+		t.Fatalf("undosLeft() = %d, %v; want 2, true", left, limited)
+	}
+
+	// Each undo takes back a player+engine pair but counts once.
+	g.UndoMove()
+	g.UndoMove()
+	if eng.undos != 4 {
+		t.Errorf("engine undos = %d, want 4", eng.undos)
+	}
+	if g.undosUsed != 2 {
+		t.Errorf("undosUsed = %d, want 2", g.undosUsed)
+	}
+
+	g.UndoMove()
+	if eng.undos != 4 || len(g.moveHistory) != 4 {
+		t.Errorf("undo past the allowance went through: engine undos %d, history %d", eng.undos, len(g.moveHistory))
+	}
+	if !strings.Contains(g.hint.GetText(true), "no undos left") {
+		t.Errorf("hint = %q, want refusal message", g.hint.GetText(true))
+	}
+
+	// The refusal goes away with the next move.
+	eng.onMove(4, 4, 1, eng.state)
+	if g.notice != "" {
+		t.Errorf("notice = %q after a move, want empty", g.notice)
+	}
+}
+
+func TestUndoUnlimitedByDefault(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 10)
+
+	for i := 0; i < 5; i++ {
+		g.UndoMove()
+	}
+	if eng.undos != 10 {
+		t.Errorf("engine undos = %d, want 10", eng.undos)
+	}
+	if _, limited := g.undosLeft(); limited {
+		t.Error("MaxUndos 0 should mean unlimited")
+	}
+	if strings.Contains(g.hint.GetText(true), "undos left") {
+		t.Errorf("hint = %q, should not show a count when unlimited", g.hint.GetText(true))
+	}
+}
+
+func TestUndoAsWhite(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	eng.player = 2
+
+	// Only Black's first move: nothing of the player's to take back
+	g.SetMoveHistory([][3]int{{1, 4, 4}})
+	g.UndoMove()
+	if eng.undos != 0 || len(g.moveHistory) != 1 {
+		t.Errorf("undo at move 1 went through: engine undos %d, history %d", eng.undos, len(g.moveHistory))
+	}
+	if !strings.Contains(g.notice, "nothing to undo") {
+		t.Errorf("notice = %q, want why nothing was undone", g.notice)
+	}
+
+	g.SetMoveHistory([][3]int{{1, 4, 4}, {2, 2, 2}, {1, 6, 6}})
+	g.UndoMove()
+	if eng.undos != 2 || len(g.moveHistory) != 1 {
+		t.Errorf("undo as White: engine undos %d, history %d; want 2, 1", eng.undos, len(g.moveHistory))
+	}
+	if g.BoardState.LastMove.X != 4 || g.BoardState.LastMove.Y != 4 {
+		t.Errorf("last move %d,%d after undo, want Black's 4,4", g.BoardState.LastMove.X, g.BoardState.LastMove.Y)
+	}
+}
+
+func TestUndoAfterPass(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+
+	// The engine passed, the player played, the engine answered: undoing
+	// goes back to answering the pass
+	g.SetMoveHistory([][3]int{{1, 4, 4}, {2, -1, -1}, {1, 2, 2}, {2, 6, 6}})
+	g.UndoMove()
+	if eng.undos != 2 || !g.lastTurnPass {
+		t.Errorf("engine undos %d, opponent passed %v; want 2, true", eng.undos, g.lastTurnPass)
+	}
+	if !strings.Contains(g.hint.GetText(true), "opponent passed") {
+		t.Errorf("hint = %q, want the pass pointed out", g.hint.GetText(true))
+	}
+
+	// Taking back the player's own move after a pass leaves no pass to answer
+	g.UndoMove()
+	if eng.undos != 4 || g.lastTurnPass || len(g.moveHistory) != 0 {
+		t.Errorf("engine undos %d, opponent passed %v, history %d; want 4, false, 0", eng.undos, g.lastTurnPass, len(g.moveHistory))
+	}
+}
+
+// An undo the engine can't finish is put back, and one it can't start is
+// refused, each saying why.
+func TestUndoFails(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, MaxUndos: 3}, 4)
+	eng.undoErr, eng.undoOK = errors.New("undo failed: cannot undo"), 1
+
+	// The engine's reply is taken back, the player's move isn't
+	g.UndoMove()
+	if len(g.moveHistory) != 4 || g.undosUsed != 0 {
+		t.Errorf("history %d, undos used %d after a failed undo; want 4, 0", len(g.moveHistory), g.undosUsed)
+	}
+	if len(eng.replayed) != 4 || eng.replayed[3] != [3]int{2, 3, 0} {
+		t.Errorf("engine given %v to replay, want the 4 moves as they were", eng.replayed)
+	}
+	if !strings.Contains(g.notice, "can't undo: undo failed: cannot undo") {
+		t.Errorf("notice = %q, want why the undo failed", g.notice)
+	}
+
+	// Refused outright, there's nothing to put back
+	eng.undos, eng.undoOK, eng.replayed = 0, 0, nil
+	g.notice = ""
+	g.UndoMove()
+	if len(g.moveHistory) != 4 || eng.replayed != nil || !strings.Contains(g.notice, "can't undo") {
+		t.Errorf("history %d, replayed %v, notice %q; want 4, nothing and why", len(g.moveHistory), eng.replayed, g.notice)
+	}
+}
+
+// settledBoard is a 5x5 endgame with no dame and nothing in atari.
+func settledBoard() *types.BoardState {
+	bs := types.NewBoardState(5)
+	for y := 0; y < 5; y++ {
+		bs.Board[y][2] = 1
+		bs.Board[y][3] = 2
+	}
+	return bs
+}
+
+func TestPassAssistPrompt(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 5}, 0)
+	g.cfg.PassAssist = true
+
+	// Engine's move leaves a settled board: suggest passing.
+	bs := settledBoard()
+	eng.onMove(3, 0, 2, bs)
+	if !g.PassPromptActive() {
+		t.Fatal("prompt should appear on a settled board after the engine moves")
+	}
+	if !strings.Contains(g.hint.GetText(true), "to pass") {
+		t.Errorf("hint = %q, want pass prompt", g.hint.GetText(true))
+	}
+
+	// Moving the cursor dismisses it.
+	g.MoveSelection(1, 0)
+	if g.PassPromptActive() {
+		t.Error("moving the cursor should dismiss the prompt")
+	}
+
+	// A dame point means there is still something to play.
+	bs = settledBoard()
+	bs.Board[2][3] = 0
+	eng.onMove(3, 0, 2, bs)
+	if g.PassPromptActive() {
+		t.Error("prompt should not appear while dame remain")
+	}
+
+	// Never after the player's own move.
+	eng.onMove(2, 0, 1, settledBoard())
+	if g.PassPromptActive() {
+		t.Error("prompt should only follow the engine's move")
+	}
+}
+
+func TestPassAssistOff(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 5}, 0)
+	eng.onMove(3, 0, 2, settledBoard())
+	if g.PassPromptActive() {
+		t.Error("pass assist is off by default")
+	}
+}
+
+func TestLoadingProgress(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+
+	bs := types.NewBoardState(9)
+	bs.Board[0][0] = 1
+	eng.onProgress(112, 240, bs)
+	if !g.IsLoading() {
+		t.Fatal("should be loading mid-replay")
+	}
+	hint := g.hint.GetText(true)
+	if !strings.Contains(hint, "Loading game…") || !strings.Contains(hint, "112/240") || strings.Contains(hint, "Thinking") {
+		t.Errorf("hint = %q, want loading progress instead of thinking", hint)
+	}
+	if g.BoardState != bs {
+		t.Error("board should follow the replay as it builds up")
+	}
+
+	// Recording can't snapshot a half-replayed position.
+	g.ToggleRecording(g.cfg)
+	if g.recorder != nil {
+		t.Error("recording should not start while loading")
+	}
+
+	eng.onProgress(240, 240, bs)
+	if g.IsLoading() {
+		t.Error("still loading after the last move")
+	}
+	if strings.Contains(g.hint.GetText(true), "Loading") {
+		t.Errorf("hint = %q after loading finished", g.hint.GetText(true))
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		done, total int
+		want        string
+	}{
+		{0, 240, "░░░░░░░░"},
+		{120, 240, "████░░░░"},
+		{240, 240, "████████"},
+		{5, 0, "░░░░░░░░"},
+	}
+	for _, tt := range tests {
+		if got := progressBar(tt.done, tt.total, 8); got != tt.want {
+			t.Errorf("progressBar(%d, %d) = %q, want %q", tt.done, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestRecTag(t *testing.T) {
+	plain := func(st sgf.RecordStatus) string {
+		return tview.NewTextView().SetDynamicColors(true).SetText(recTag(st)).GetText(true)
+	}
+	if got := plain(sgf.RecordStatus{}); got != "REC" {
+		t.Errorf("healthy recorder shows %q", got)
+	}
+	if got := plain(sgf.RecordStatus{Recreated: true}); !strings.Contains(got, "deleted") {
+		t.Errorf("recreated file shows %q", got)
+	}
+	if got := plain(sgf.RecordStatus{Err: errors.New("disk full")}); !strings.Contains(got, "NOT SAVED: disk full") || strings.Contains(got, "REC") {
+		t.Errorf("failing writes show %q", got)
+	}
+}
+
+func TestPrevMove(t *testing.T) {
+	g, _ := newTestBoard(t, engine.GameConfig{}, 1)
+	if x, y := g.prevMove(); x != -1 || y != -1 {
+		t.Errorf("prevMove after one move = (%d,%d), want none", x, y)
+	}
+	g.SetMoveHistory([][3]int{{1, 2, 2}, {2, 6, 6}, {1, 4, 4}})
+	if x, y := g.prevMove(); x != 6 || y != 6 {
+		t.Errorf("prevMove = (%d,%d), want the engine's reply at (6,6)", x, y)
+	}
+
+	// Planning continues from the game's moves.
+	g.TogglePlanningMode()
+	if x, y := g.prevMove(); x != 6 || y != 6 {
+		t.Errorf("prevMove entering planning = (%d,%d), want (6,6)", x, y)
+	}
+	g.PlanPlayMove(3, 3)
+	if x, y := g.prevMove(); x != 4 || y != 4 {
+		t.Errorf("prevMove after one plan move = (%d,%d), want the last game move (4,4)", x, y)
+	}
+	g.PlanPlayMove(5, 5)
+	if x, y := g.prevMove(); x != 3 || y != 3 {
+		t.Errorf("prevMove after two plan moves = (%d,%d), want (3,3)", x, y)
+	}
+	g.planPass()
+	if x, y := g.prevMove(); x != 5 || y != 5 {
+		t.Errorf("prevMove after a plan pass = (%d,%d), want (5,5)", x, y)
+	}
+}
+
+func TestPlanCaptures(t *testing.T) {
+	g, _ := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.infoPanel = NewGameInfoPanel()
+	g.BoardState.Board[0][0] = 2
+	g.BoardState.Board[0][1] = 1
+	g.BoardState.CapturesBlack = 2
+	g.BoardState.CapturesWhite = 3
+	g.refreshHint()
+	if text := g.infoPanel.Box().GetText(true); !strings.Contains(text, "Captures: B 2 / W 3") {
+		t.Errorf("panel shows %q, want the game's captures", text)
+	}
+
+	g.TogglePlanningMode()
+	g.PlanPlayMove(0, 1)
+	if g.plan.Board[0][0] != 0 {
+		t.Fatalf("plan move did not capture the corner stone")
+	}
+	if text := g.infoPanel.Box().GetText(true); !strings.Contains(text, "Captures: B 3 / W 3") {
+		t.Errorf("panel shows %q after a plan capture, want B 3", text)
+	}
+
+	g.PlanBack()
+	if g.plan.Captures != [3]int{0, 2, 3} {
+		t.Errorf("planCaptures after PlanBack = %v, want the game's counts", g.plan.Captures)
+	}
+	g.PlanForward()
+	if g.plan.Captures[1] != 3 {
+		t.Errorf("black captures after PlanForward = %d, want 3", g.plan.Captures[1])
+	}
+
+	g.TogglePlanningMode()
+	if text := g.infoPanel.Box().GetText(true); !strings.Contains(text, "Captures: B 2 / W 3") {
+		t.Errorf("panel shows %q after leaving planning, want the game's captures", text)
+	}
+}
+
+func TestPlanKo(t *testing.T) {
+	g, _ := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	// A ko on the top edge: Black takes at (2,0) from (3,0)
+	for _, p := range [][3]int{{2, 0, 2}, {1, 0, 1}, {2, 1, 1}, {4, 0, 2}, {3, 1, 2}} {
+		g.BoardState.Board[p[1]][p[0]] = p[2]
+	}
+	g.BoardState.PlayerToMove = 1
+	g.TogglePlanningMode()
+	g.planColor = 1
+
+	g.PlanPlayMove(3, 0)
+	if g.plan.Board[0][2] != 0 || g.plan.Ko != [2]int{2, 0} {
+		t.Fatalf("after the capture: (2,0) = %d, ko at %v", g.plan.Board[0][2], g.plan.Ko)
+	}
+	g.PlanPlayMove(2, 0)
+	if g.plan.Board[0][2] != 0 || g.planColor != 2 || !strings.Contains(g.notice, "ko") {
+		t.Errorf("immediate retake allowed: (2,0) = %d, notice %q", g.plan.Board[0][2], g.notice)
+	}
+	if hint := g.hint.GetText(true); !strings.Contains(hint, g.notice) {
+		t.Errorf("hint %q doesn't say why", hint)
+	}
+
+	// Stepping back and forth keeps the ko.
+	g.PlanBack()
+	g.PlanForward()
+	if g.plan.Ko != [2]int{2, 0} {
+		t.Errorf("ko at %v after PlanBack, PlanForward", g.plan.Ko)
+	}
+
+	// A threat and an answer, and the retake is fine.
+	g.PlanPlayMove(7, 7)
+	g.PlanPlayMove(7, 6)
+	if g.notice != "" || g.plan.Ko != [2]int{-1, -1} {
+		t.Errorf("notice %q, ko at %v after moves elsewhere", g.notice, g.plan.Ko)
+	}
+	g.PlanPlayMove(2, 0)
+	if g.plan.Board[0][2] != 2 || g.plan.Board[0][3] != 0 {
+		t.Errorf("retake after a threat: (2,0) = %d, (3,0) = %d", g.plan.Board[0][2], g.plan.Board[0][3])
+	}
+}
+
+func TestPlanSuperko(t *testing.T) {
+	g, _ := newTestBoard(t, engine.GameConfig{BoardSize: 9, Ruleset: "Chinese"}, 0)
+	// Three kos: Black can take on the top and bottom edges, White on the left
+	rows := []string{
+		".XO.O....",
+		"..XO.....",
+		".........",
+		"X........",
+		".X.......",
+		"XO.......",
+		"O........",
+		"..XO.....",
+		".XO.O....",
+	}
+	for y, row := range rows {
+		for x, c := range row {
+			g.BoardState.Board[y][x] = strings.IndexRune(".XO", c)
+		}
+	}
+	g.TogglePlanningMode()
+	g.planColor = 1
+
+	// Each side takes a ko in turn; the sixth capture would bring back
+	// the start.
+	for _, p := range [][2]int{{3, 0}, {0, 4}, {3, 8}, {2, 0}, {0, 5}} {
+		g.PlanPlayMove(p[0], p[1])
+		if g.notice != "" {
+			t.Fatalf("plan move %v refused: %q", p, g.notice)
+		}
+	}
+	g.PlanPlayMove(2, 8)
+	if g.plan.Board[8][2] != 0 || g.plan.Board[8][3] != 1 || g.planColor != 2 || !strings.Contains(g.notice, "superko") {
+		t.Errorf("cycle closed: (2,8) = %d, (3,8) = %d, notice %q", g.plan.Board[8][2], g.plan.Board[8][3], g.notice)
+	}
+
+	// Going back a move and forth again still knows the way here.
+	g.PlanBack()
+	g.PlanForward()
+	g.PlanPlayMove(2, 8)
+	if g.plan.Board[8][2] != 0 {
+		t.Error("superko forgotten after PlanBack, PlanForward")
+	}
+
+	// Japanese rules have no superko: the cycle goes round.
+	g.TogglePlanningMode()
+	g.gameConfig.Ruleset = "Japanese"
+	g.TogglePlanningMode()
+	g.planColor = 1
+	for _, p := range [][2]int{{3, 0}, {0, 4}, {3, 8}, {2, 0}, {0, 5}, {2, 8}} {
+		g.PlanPlayMove(p[0], p[1])
+	}
+	if g.plan.Board[8][2] != 2 || g.notice != "" {
+		t.Errorf("Japanese rules: (2,8) = %d, notice %q", g.plan.Board[8][2], g.notice)
+	}
+}
+
+func TestRepeatedPositionNoted(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	bs := types.NewBoardState(9)
+	bs.Hash = 42
+	eng.onMove(2, 2, 1, bs)
+	eng.onMove(3, 3, 2, &types.BoardState{Board: bs.Board, Hash: 43})
+	if g.notice != "" {
+		t.Fatalf("notice %q with no repeat", g.notice)
+	}
+	eng.onMove(4, 4, 1, &types.BoardState{Board: bs.Board, Hash: 42})
+	if g.notice != repeatNote(1) {
+		t.Errorf("notice %q, want the repeat of move 1", g.notice)
+	}
+	if moves := g.MovesSnapshot(); moves[len(moves)-1].Hash != 42 {
+		t.Errorf("move entry hash %d", moves[len(moves)-1].Hash)
+	}
+}
+
+func TestResumePlanKeepsRecordExtras(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sgf")
+	content := "(;GM[1]FF[4]SZ[9]KM[6.5]PB[Player]PW[GnuGo Level 5];B[ee]C[a fine start]LB[ee:A];W[cc]TR[cc])"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := sgf.OpenGameRecord(path)
+	if err != nil {
+		t.Fatalf("OpenGameRecord: %v", err)
+	}
+	g, _ := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.SetRecorder(rec)
+	g.SetMoveHistory([][3]int{{1, 4, 4}, {2, 2, 2}})
+
+	g.TogglePlanningMode()
+	g.PlanPlayMove(6, 6)
+	g.finishResume([][3]int{{1, 4, 4}, {2, 2, 2}, {1, 6, 6}}, nil)
+	if err := g.recorder.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{";B[ee]LB[ee:A]C[a fine start]", ";W[cc]TR[cc]", ";B[gg]"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("record after resuming lost %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"termsuji-local/engine"
+	"termsuji-local/training"
+)
+
+// Guessing: while reviewing, the player guesses each next move of the
+// game before it is shown. After a guess the board marks it, the game
+// move and, when an engine analyses the game, its top choices, and the
+// hint bar explains the difference; Enter then plays the game move.
+
+// GuessAnalyst rates positions of the game being reviewed, by how many of
+// its moves have been played. The engine behind it is closed with it.
+type GuessAnalyst interface {
+	TopMovesAt(n int) ([]engine.Candidate, error)
+	Close()
+}
+
+// Marks for the guess and the game move; the engine's choices are
+// numbered from 1, its first.
+const (
+	guessRune    = '×'
+	gameMoveRune = '○'
+)
+
+// StartGuessing hides the next move of the reviewed game until it is
+// guessed. analyst may be nil, for review without analysis.
+func (g *GoBoardUI) StartGuessing(analyst GuessAnalyst) {
+	if g.review == nil || g.guessing {
+		if analyst != nil {
+			analyst.Close()
+		}
+		return
+	}
+	g.guessing = true
+	g.notice = ""
+	g.guessCredit, g.guessTries = 0, 0
+	g.guessAnalyst = analyst
+	if analyst != nil {
+		g.guessSched = engine.NewScheduler(engine.DefaultDebounce, nil, g.analyseGuess(analyst))
+	}
+	g.clearGuess()
+	g.ResetSelection()
+	g.MoveSelection(0, 0)
+	g.refreshHint()
+}
+
+// IsGuessing returns true while the next move is being guessed.
+func (g *GoBoardUI) IsGuessing() bool {
+	return g.guessing
+}
+
+// StopGuessing goes back to stepping through the game, closing the
+// analyst's engine.
+func (g *GoBoardUI) StopGuessing() {
+	if !g.guessing {
+		return
+	}
+	g.guessing = false
+	g.guessReview = nil
+	g.guessCandidates = nil
+	if g.guessSched != nil {
+		g.guessSched.Stop()
+		g.guessSched = nil
+	}
+	if g.guessAnalyst != nil {
+		g.guessAnalyst.Close()
+		g.guessAnalyst = nil
+	}
+	g.ResetSelection()
+	g.refreshHint()
+}
+
+// GuessShown returns true once the current move has been guessed and its
+// review is on the board.
+func (g *GoBoardUI) GuessShown() bool {
+	return g.guessReview != nil
+}
+
+// Guess guesses x, y, or a pass for -1, -1, as the next move of the game
+// and shows how it compares. A point already taken is refused.
+func (g *GoBoardUI) Guess(x, y int) {
+	if !g.guessing || g.guessReview != nil {
+		return
+	}
+	n := g.review.Position()
+	if n >= g.review.Len() {
+		return
+	}
+	if x >= 0 && g.BoardState.Board[y][x] != 0 {
+		g.flashError("occupied", x, y)
+		return
+	}
+	g.guessAt = training.Point{X: x, Y: y}
+	g.reviewGuess()
+	g.guessCredit += g.guessReview.Credit
+	g.guessTries++
+	// The cursor would hide the marks
+	g.ResetSelection()
+	g.refreshHint()
+}
+
+// NextGuess plays the game move that was guessed and waits for the next
+// guess.
+func (g *GoBoardUI) NextGuess() {
+	if !g.guessing || g.guessReview == nil {
+		return
+	}
+	g.ReviewStep(1)
+	g.clearGuess()
+	if g.guessAt.IsPass() {
+		g.MoveSelection(0, 0)
+	} else {
+		g.selX, g.selY = g.guessAt.X, g.guessAt.Y
+	}
+	g.refreshHint()
+}
+
+// clearGuess drops the review of the last guess and asks for the
+// analysis of the position now on the board.
+func (g *GoBoardUI) clearGuess() {
+	g.guessReview = nil
+	g.guessCandidates = nil
+	if g.guessSched != nil && g.review.Position() < g.review.Len() {
+		g.guessSched.Request(g.review.Moves()[:g.review.Position()])
+	}
+}
+
+// reviewGuess builds the review of the guess on show with the candidates
+// known so far.
+func (g *GoBoardUI) reviewGuess() {
+	m := g.review.Moves()[g.review.Position()]
+	review := training.ReviewGuess(g.guessAt, training.Point{X: m[1], Y: m[2]}, g.guessCandidates, g.review.Size())
+	g.guessReview = &review
+}
+
+// analyseGuess returns the scheduler's evaluation: the analyst's
+// candidates for the position, which are dropped if a newer position has
+// been asked for by the time they come.
+func (g *GoBoardUI) analyseGuess(analyst GuessAnalyst) func(gen uint64, moves [][3]int) {
+	return func(gen uint64, moves [][3]int) {
+		found, err := analyst.TopMovesAt(len(moves))
+		g.app.QueueUpdateDraw(func() {
+			if g.guessAnalyst != analyst || !g.guessSched.Current(gen) || errors.Is(err, engine.ErrClosed) {
+				return
+			}
+			if err != nil {
+				g.notice = "no analysis: " + err.Error()
+			} else {
+				g.setGuessCandidates(found)
+			}
+			g.refreshHint()
+		})
+	}
+}
+
+// setGuessCandidates takes the engine's moves for the position, and
+// reviews a guess already made again with them.
+func (g *GoBoardUI) setGuessCandidates(found []engine.Candidate) {
+	g.guessCandidates = make([]training.Candidate, len(found))
+	for i, c := range found {
+		g.guessCandidates[i] = training.Candidate{Point: training.Point{X: c.X, Y: c.Y}, Score: c.Value}
+	}
+	if g.guessReview != nil {
+		g.reviewGuess()
+	}
+}
+
+// guessMark returns the mark drawn at x, y for the guess on show, and
+// the rank of the engine's choice there for its shade, -1 if it isn't
+// one. ok is false for a point without a mark. The guess and the game
+// move are marked over the engine's number.
+func (g *GoBoardUI) guessMark(x, y int) (mark rune, rank int, ok bool) {
+	if g.guessReview == nil {
+		return 0, -1, false
+	}
+	rank = -1
+	engineMoves := 0
+	for _, m := range g.guessReview.Marks {
+		here := m.X == x && m.Y == y
+		switch m.Kind {
+		case training.MarkGuess:
+			if here {
+				mark = guessRune
+			}
+		case training.MarkGameMove:
+			if here {
+				mark = gameMoveRune
+			}
+		default:
+			if here {
+				rank = engineMoves
+			}
+			engineMoves++
+		}
+	}
+	if mark == 0 && rank >= 0 {
+		mark = rune('1' + rank)
+	}
+	return mark, rank, mark != 0
+}
+
+// guessStatus is the hint bar's status while guessing.
+func (g *GoBoardUI) guessStatus() string {
+	status := fmt.Sprintf("%s move %d/%d", tag("yellow", "GUESS"), g.review.Position()+1, g.review.Len())
+	if g.review.Position() >= g.review.Len() {
+		status = fmt.Sprintf("%s end of game", tag("yellow", "GUESS"))
+	}
+	if g.guessTries > 0 {
+		status += "  " + tag("dimgray", fmt.Sprintf("· score %g/%d", g.guessCredit, g.guessTries))
+	}
+	if g.guessReview != nil {
+		color := "red"
+		if g.guessReview.Credit > 0 {
+			color = "green"
+		}
+		status += "  " + tag(color, g.guessReview.Explanation)
+	} else if g.guessSched != nil && g.guessCandidates == nil && g.review.Position() < g.review.Len() {
+		status += "  " + tag("dimgray", "· analyzing…")
+	}
+	if g.notice != "" {
+		status += "  " + tag("red", g.notice)
+	}
+	return status
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+)
+
+// fakeAnalyst answers every position with the same candidates and tells
+// which positions it was asked about.
+type fakeAnalyst struct {
+	asked  chan int
+	closed bool
+}
+
+func (f *fakeAnalyst) TopMovesAt(n int) ([]engine.Candidate, error) {
+	f.asked <- n
+	return []engine.Candidate{{X: 4, Y: 4, Value: 10}, {X: 2, Y: 2, Value: 8}, {X: 6, Y: 6, Value: 7}}, nil
+}
+
+func (f *fakeAnalyst) Close() {
+	f.closed = true
+}
+
+func TestGuessWithoutAnalysis(t *testing.T) {
+	cfg := config.DefaultConfig
+	g := NewGoBoard(tview.NewApplication(), &cfg, tview.NewTextView())
+	moves := [][3]int{{1, 2, 6}, {2, 6, 2}, {1, 4, 4}}
+	g.StartReview(sgf.NewReplayer(9, nil, moves))
+	g.StartGuessing(nil)
+	if !g.IsGuessing() || g.SelectedTile() == nil {
+		t.Fatalf("StartGuessing: guessing %v, cursor %v", g.IsGuessing(), g.SelectedTile())
+	}
+
+	// D3 for C3: touching it, half credit
+	g.Guess(3, 6)
+	if !g.GuessShown() || g.guessCredit != 0.5 || g.guessTries != 1 {
+		t.Fatalf("after a near miss: shown %v, credit %v of %d", g.GuessShown(), g.guessCredit, g.guessTries)
+	}
+	if mark, _, ok := g.guessMark(2, 6); !ok || mark != gameMoveRune {
+		t.Errorf("game move mark = %q, %v", mark, ok)
+	}
+	if mark, _, ok := g.guessMark(3, 6); !ok || mark != guessRune {
+		t.Errorf("guess mark = %q, %v", mark, ok)
+	}
+	if g.BoardState.Board[6][2] != 0 {
+		t.Error("the game move should stay off the board until moving on")
+	}
+	if text := g.hint.GetText(true); !strings.Contains(text, "Near miss (half credit): game move C3, you played D3") {
+		t.Errorf("hint = %q, want the explanation", text)
+	}
+
+	// A second guess waits for Enter to move on
+	g.Guess(2, 6)
+	if g.guessTries != 1 {
+		t.Error("a guess on show should not be replaced")
+	}
+	g.NextGuess()
+	if g.ReviewPosition() != 1 || g.GuessShown() || g.BoardState.Board[6][2] != 1 {
+		t.Fatalf("NextGuess: position %d, shown %v", g.ReviewPosition(), g.GuessShown())
+	}
+
+	g.Guess(2, 6)
+	if g.GuessShown() {
+		t.Error("a guess on a stone should be refused")
+	}
+	g.Guess(6, 2)
+	g.NextGuess()
+	g.Guess(-1, -1)
+	if g.guessCredit != 1.5 || g.guessTries != 3 {
+		t.Errorf("score = %v of %d, want 1.5 of 3", g.guessCredit, g.guessTries)
+	}
+	g.NextGuess()
+	g.Guess(0, 0)
+	if g.guessTries != 3 || !strings.Contains(g.hint.GetText(true), "end of game") {
+		t.Errorf("guessing past the end: %d tries, hint %q", g.guessTries, g.hint.GetText(true))
+	}
+
+	g.StopGuessing()
+	if g.IsGuessing() || !g.IsReviewing() {
+		t.Errorf("StopGuessing: guessing %v, reviewing %v", g.IsGuessing(), g.IsReviewing())
+	}
+}
+
+func TestGuessWithAnalysis(t *testing.T) {
+	cfg := config.DefaultConfig
+	g := NewGoBoard(tview.NewApplication(), &cfg, tview.NewTextView())
+	moves := [][3]int{{1, 2, 6}, {2, 6, 2}}
+	g.StartReview(sgf.NewReplayer(9, nil, moves))
+	analyst := &fakeAnalyst{asked: make(chan int, 10)}
+	g.StartGuessing(analyst)
+
+	select {
+	case n := <-analyst.asked:
+		if n != 0 {
+			t.Errorf("analysed after %d moves, want the start", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the position was not analysed")
+	}
+
+	// The answer comes through the app's queue, which isn't running here
+	g.setGuessCandidates([]engine.Candidate{{X: 4, Y: 4, Value: 10}, {X: 2, Y: 2, Value: 8}, {X: 6, Y: 6, Value: 7}, {X: 2, Y: 6, Value: 5}})
+	g.Guess(0, 0)
+	if g.guessCredit != 0 {
+		t.Errorf("credit = %v for a miss", g.guessCredit)
+	}
+	for _, p := range []struct {
+		x, y int
+		mark rune
+		rank int
+	}{{4, 4, '1', 0}, {2, 2, '2', 1}, {6, 6, '3', 2}, {2, 6, gameMoveRune, -1}, {0, 0, guessRune, -1}} {
+		if mark, rank, ok := g.guessMark(p.x, p.y); !ok || mark != p.mark || rank != p.rank {
+			t.Errorf("mark at %d,%d = %q rank %d, want %q rank %d", p.x, p.y, mark, rank, p.mark, p.rank)
+		}
+	}
+	if text := g.hint.GetText(true); !strings.Contains(text, "Missed: game move C3 (-5.0), you A9, engine prefers E5") {
+		t.Errorf("hint = %q, want the score deltas", text)
+	}
+
+	g.StopReview()
+	if !analyst.closed || g.IsGuessing() {
+		t.Errorf("leaving review: analyst closed %v, guessing %v", analyst.closed, g.IsGuessing())
+	}
+}
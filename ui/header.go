@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+
+	"termsuji-local/sgf"
+)
+
+// The info panel leads with who the game is against and how it was set
+// up, so games left open in several terminals can be told apart.
+
+// matchHeader sums up a game, e.g. "vs GnuGo L7 · 19x19 · H0". The level
+// is left out when it's 0, as for a saved game against someone else.
+func matchHeader(opponent string, level, size, handicap int) string {
+	header := "vs " + opponent
+	if level > 0 {
+		header += fmt.Sprintf(" L%d", level)
+	}
+	return header + fmt.Sprintf(" · %dx%d · H%d", size, size, handicap)
+}
+
+// refreshHeader puts the game being played in the panel's header.
+func (g *GoBoardUI) refreshHeader() {
+	if g.infoPanel != nil {
+		g.infoPanel.SetHeader(matchHeader(g.engineName(), g.level, g.gameConfig.BoardSize, g.gameConfig.Handicap))
+	}
+}
+
+// SetGameInfo puts a saved game in the panel's header as its record
+// describes it, for review.
+func (g *GoBoardUI) SetGameInfo(game sgf.GameInfo) {
+	if g.infoPanel == nil {
+		return
+	}
+	setup := sgf.InferSetup(game, g.cfg.PlayerName)
+	opponent := setup.Opponent
+	if opponent == "" {
+		opponent = "?"
+	}
+	g.infoPanel.SetHeader(matchHeader(opponent, setup.EngineLevel, game.BoardSize, game.Handicap))
+}
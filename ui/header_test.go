@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+func TestMatchHeader(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	g.infoPanel = NewGameInfoPanel()
+	g.infoPanel.SetBoardState(g.BoardState)
+	g.SetGameConfig(engine.GameConfig{BoardSize: 19, EngineLevel: 7, Handicap: 2})
+	text := g.infoPanel.Box().GetText(true)
+	if !strings.HasPrefix(text, "vs GnuGo L7 · 19x19 · H2\n") {
+		t.Errorf("panel starts %q", text)
+	}
+
+	// A level change mid-game shows.
+	bs := types.NewBoardState(19)
+	bs.LevelChange = 6
+	eng.onMove(3, 3, 2, bs)
+	if text := g.infoPanel.Box().GetText(true); !strings.HasPrefix(text, "vs GnuGo L6 ·") {
+		t.Errorf("after the level change the panel starts %q", text)
+	}
+
+	// Review goes by the record, not the last game played.
+	g.SetGameInfo(sgf.GameInfo{BoardSize: 13, PlayerBlack: "KataGo", PlayerWhite: "Player", Handicap: 0})
+	if text := g.infoPanel.Box().GetText(true); !strings.HasPrefix(text, "vs KataGo · 13x13 · H0") {
+		t.Errorf("reviewing, the panel starts %q", text)
+	}
+	g.SetGameInfo(sgf.GameInfo{BoardSize: 9, PlayerBlack: "GnuGo Level 3", PlayerWhite: "Player"})
+	if text := g.infoPanel.Box().GetText(true); !strings.HasPrefix(text, "vs GnuGo L3 · 9x9 · H0") {
+		t.Errorf("reviewing a GnuGo game, the panel starts %q", text)
+	}
+}
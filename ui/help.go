@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+)
+
+// helpWidth is the width of the help card, wide enough for the longest
+// action on one line at most terminal sizes.
+const helpWidth = 64
+
+// KeysWidth is the width of the key sheet printed by the keys subcommand
+// and saved from the key reference.
+const KeysWidth = 80
+
+// keysFile is the name the key sheet is saved under.
+const keysFile = "termsuji-local-keys.txt"
+
+// helpHint is the line under the card, saying what its keys do.
+const helpHint = "↑↓ scroll · r rules · K save · ? or Esc close"
+
+// HelpUI is the key reference: the Keymap laid out on a card over the page
+// that called it up.
+type HelpUI struct {
+	flex      *tview.Flex
+	box       *tview.Box
+	card      *MenuCard
+	hint      *tview.TextView
+	lines     []string // FormatKeys output for the card's width
+	offset    int      // first line shown, for scrolling
+	visible   int      // lines that fit on the card at the last draw
+	exportDir string   // where K saves the sheet
+	onClose   func()
+	onRules   func()
+}
+
+// NewHelp creates the help overlay. onClose is called on Esc, q or ?.
+func NewHelp(onClose func()) *HelpUI {
+	h := &HelpUI{
+		card:      NewMenuCard("K E Y S"),
+		exportDir: config.ExportDir(),
+		onClose:   onClose,
+	}
+	h.lines = strings.Split(strings.TrimRight(FormatKeys(Keymap(), helpWidth-8), "\n"), "\n")
+
+	h.box = tview.NewBox()
+	h.box.SetDrawFunc(h.draw)
+	h.box.SetInputCapture(h.handleInput)
+
+	h.hint = tview.NewTextView().
+		SetText(glyphs(helpHint)).
+		SetTextAlign(tview.AlignCenter)
+	h.hint.SetTextColor(MenuColors.Hint)
+	h.hint.SetBackgroundColor(tcell.ColorDefault)
+
+	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 1, 0, false).
+		AddItem(h.box, 0, 1, true).
+		AddItem(h.hint, 1, 0, false)
+
+	h.flex = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(innerFlex, helpWidth, 0, true).
+		AddItem(nil, 0, 1, false)
+	return h
+}
+
+// Flex returns the flex container for this UI.
+func (h *HelpUI) Flex() *tview.Flex {
+	return h.flex
+}
+
+// SetRulesFunc sets the callback for the 'r' key, which opens the rules
+// reference.
+func (h *HelpUI) SetRulesFunc(onRules func()) {
+	h.onRules = onRules
+}
+
+// Reset scrolls back to the top, for the next time the overlay opens.
+func (h *HelpUI) Reset() {
+	h.offset = 0
+	h.hint.SetText(glyphs(helpHint))
+}
+
+// saveKeys writes the key sheet, as the keys subcommand prints it, to
+// the export directory, replacing an older one, and says where on the
+// hint line.
+func (h *HelpUI) saveKeys() {
+	path, err := exportKeys(h.exportDir)
+	if err != nil {
+		h.hint.SetText("can't save the keys: " + err.Error())
+		return
+	}
+	h.hint.SetText("keys saved to " + path)
+}
+
+// exportKeys writes the key sheet into dir, returning the path written.
+func exportKeys(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, keysFile)
+	return path, os.WriteFile(path, []byte(FormatKeys(Keymap(), KeysWidth)), 0644)
+}
+
+// draw renders the card with the part of the key sheet that fits.
+func (h *HelpUI) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	h.card.SetRect(x, y, width, height)
+	h.card.Draw(screen)
+
+	headingStyle := tcell.StyleDefault.Foreground(MenuColors.Title).Background(MenuColors.CardBG).Bold(true)
+	ruleStyle := tcell.StyleDefault.Foreground(MenuColors.Border).Background(MenuColors.CardBG)
+	lineStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
+
+	// Below the title and its divider, inside the border
+	top, bottom := y+6, y+height-2
+	h.visible = bottom - top
+	if h.visible < 1 {
+		return x, y, width, height
+	}
+	h.clampOffset()
+	for i := 0; i < h.visible && h.offset+i < len(h.lines); i++ {
+		line := h.lines[h.offset+i]
+		style := lineStyle
+		switch {
+		case strings.HasPrefix(line, "─"):
+			style = ruleStyle
+		case line != "" && !strings.HasPrefix(line, " "):
+			style = headingStyle
+		}
+		drawText(screen, x+4, top+i, glyphs(line), style)
+	}
+
+	// Say there is more above or below
+	moreStyle := tcell.StyleDefault.Foreground(MenuColors.Hint).Background(MenuColors.CardBG)
+	if h.offset > 0 {
+		drawText(screen, x+width-4, top, glyphs("↑"), moreStyle)
+	}
+	if h.offset+h.visible < len(h.lines) {
+		drawText(screen, x+width-4, bottom-1, glyphs("↓"), moreStyle)
+	}
+	return x, y, width, height
+}
+
+// clampOffset keeps the scroll within the sheet.
+func (h *HelpUI) clampOffset() {
+	if max := len(h.lines) - h.visible; h.offset > max {
+		h.offset = max
+	}
+	if h.offset < 0 {
+		h.offset = 0
+	}
+}
+
+// scroll moves the sheet by n lines, up for negative n.
+func (h *HelpUI) scroll(n int) {
+	h.offset += n
+	h.clampOffset()
+}
+
+func (h *HelpUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEsc:
+		h.onClose()
+	case tcell.KeyUp:
+		h.scroll(-1)
+	case tcell.KeyDown:
+		h.scroll(1)
+	case tcell.KeyPgUp:
+		h.scroll(-h.visible)
+	case tcell.KeyPgDn:
+		h.scroll(h.visible)
+	case tcell.KeyHome:
+		h.offset = 0
+	case tcell.KeyEnd:
+		h.scroll(len(h.lines))
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case '?', 'q':
+			h.onClose()
+		case 'k':
+			h.scroll(-1)
+		case 'j':
+			h.scroll(1)
+		case 'r':
+			if h.onRules != nil {
+				h.onRules()
+			}
+		case 'K':
+			h.saveKeys()
+		default:
+			return event
+		}
+	default:
+		return event
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// screenText returns the screen's contents, a line per row.
+func screenText(screen tcell.SimulationScreen) string {
+	cells, w, h := screen.GetContents()
+	var b strings.Builder
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if r := cells[y*w+x].Runes; len(r) > 0 {
+				b.WriteRune(r[0])
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestHelpScroll(t *testing.T) {
+	closed := 0
+	h := NewHelp(func() { closed++ })
+	screen := newTestScreen(t, 80, 20)
+	h.flex.SetRect(0, 0, 80, 20)
+	h.flex.Draw(screen)
+	screen.Show()
+	if text := screenText(screen); !strings.Contains(text, "Setup") || strings.Contains(text, "History") {
+		t.Fatalf("top of the sheet should show Setup but not History:\n%s", text)
+	}
+
+	// End shows the last group, and scrolling stops there
+	h.handleInput(tcell.NewEventKey(tcell.KeyEnd, 0, tcell.ModNone))
+	end := h.offset
+	h.handleInput(tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone))
+	if h.offset != end {
+		t.Errorf("scrolled past the end: %d, then %d", end, h.offset)
+	}
+	h.flex.Draw(screen)
+	screen.Show()
+	if text := screenText(screen); !strings.Contains(text, "back to the key reference") || strings.Contains(text, "─  ─") {
+		t.Errorf("end of the sheet should show the rules keys:\n%s", text)
+	}
+
+	for _, k := range []*tcell.EventKey{
+		tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModNone),
+		tcell.NewEventKey(tcell.KeyEsc, 0, tcell.ModNone),
+	} {
+		if h.handleInput(k) != nil {
+			t.Errorf("%v not kept by the overlay", k.Name())
+		}
+	}
+	if closed != 2 {
+		t.Errorf("closed %d times, want 2", closed)
+	}
+	h.Reset()
+	if h.offset != 0 {
+		t.Errorf("Reset left offset %d", h.offset)
+	}
+}
+
+// K saves the sheet the keys subcommand prints, and says where.
+func TestHelpSavesKeys(t *testing.T) {
+	h := NewHelp(func() {})
+	h.exportDir = filepath.Join(t.TempDir(), "Downloads")
+	if h.handleInput(tcell.NewEventKey(tcell.KeyRune, 'K', tcell.ModNone)) != nil {
+		t.Error("K not taken by the overlay")
+	}
+	path := filepath.Join(h.exportDir, keysFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != FormatKeys(Keymap(), KeysWidth) {
+		t.Errorf("saved sheet differs from the printed one:\n%s", data)
+	}
+	if got := h.hint.GetText(true); got != "keys saved to "+path {
+		t.Errorf("hint %q", got)
+	}
+
+	// Saving again replaces it; a directory that can't be made is reported
+	if _, err := exportKeys(h.exportDir); err != nil {
+		t.Errorf("saving again: %v", err)
+	}
+	h.exportDir = filepath.Join(path, "sub")
+	h.saveKeys()
+	if got := h.hint.GetText(true); !strings.HasPrefix(got, "can't save the keys: ") {
+		t.Errorf("hint %q, want the error", got)
+	}
+	h.Reset()
+	if got := h.hint.GetText(true); got != helpHint {
+		t.Errorf("Reset left the hint %q", got)
+	}
+}
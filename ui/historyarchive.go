@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"path/filepath"
+
+	"github.com/rivo/tview"
+
+	"termsuji-local/sgf"
+)
+
+// toggleArchive switches the list between the history's games and the
+// archived ones. The filter and order stay, and so do the previews
+// already replayed, which are kept by path.
+func (hb *HistoryBrowserUI) toggleArchive() {
+	hb.archived = !hb.archived
+	if !hb.archived {
+		hb.list = hb.active
+	} else {
+		if hb.archive == nil {
+			hb.archive = NewGameList(filepath.Join(hb.active.dir, sgf.ArchiveDir))
+		}
+		hb.list = hb.archive
+	}
+	hb.loadGames()
+	hb.setHint("")
+}
+
+// archiveSelected moves the selected game into the archive, or in the
+// archive view back among the games.
+func (hb *HistoryBrowserUI) archiveSelected() {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return
+	}
+	game := hb.games[hb.selected]
+	if !hb.wholeFile(game) {
+		return
+	}
+	move, verb := sgf.ArchiveGame, "archived "
+	if hb.archived {
+		move, verb = sgf.UnarchiveGame, "unarchived "
+	}
+	to, err := move(game.FilePath)
+	if err != nil {
+		hb.setHint(tag("red", "can't move: "+tview.Escape(err.Error())))
+		return
+	}
+	hb.active.Invalidate()
+	if hb.archive != nil {
+		hb.archive.Invalidate()
+	}
+
+	// The preview is the same wherever the file is
+	if pos, ok := hb.boards[game.FilePath]; ok {
+		hb.boards[to] = pos
+	}
+	hb.removeGame(game)
+	hint := verb + game.FileName
+	if !hb.archived {
+		hint += ", v shows the archive"
+	}
+	hb.setHint(tag("yellow", hint))
+}
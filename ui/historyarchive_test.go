@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/sgf"
+)
+
+func TestHistoryBrowserArchive(t *testing.T) {
+	dir := writeHistory(t, []int{9, 13, 19}, []string{"B+R", "W+3.5", "W+R"})
+	var reviewed string
+	hb := NewHistoryBrowser(NewGameList(dir), nil, nil, nil, nil, nil)
+	hb.onReview = func(g sgf.GameInfo) { reviewed = g.FilePath }
+	hb.Refresh()
+	key := func(r rune) { hb.handleInput(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)) }
+
+	hb.gameList.SetCurrentItem(1)
+	game := hb.games[1]
+	hb.finalBoard(game)
+	key('a')
+	if len(hb.games) != 2 || hb.games[1].FilePath == game.FilePath {
+		t.Fatalf("still listed after archiving: %+v", hb.games)
+	}
+
+	// The archive view lists it alone, its preview still cached
+	key('v')
+	if !hb.archived || len(hb.games) != 1 || hb.games[0].FileName != game.FileName {
+		t.Fatalf("archive lists %+v", hb.games)
+	}
+	if !strings.Contains(hb.gameList.GetTitle(), "Archive") {
+		t.Errorf("title %q in the archive", hb.gameList.GetTitle())
+	}
+	if _, ok := hb.boards[hb.games[0].FilePath]; !ok {
+		t.Error("preview replayed again after the move")
+	}
+
+	// Archived games are for review only
+	key('d')
+	if len(hb.games) != 1 {
+		t.Error("deleted a game in the archive")
+	}
+	hb.handleInput(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if reviewed != hb.games[0].FilePath {
+		t.Errorf("reviewed %q from the archive", reviewed)
+	}
+
+	// a moves it back
+	key('a')
+	if len(hb.games) != 0 {
+		t.Errorf("archive lists %+v after unarchiving", hb.games)
+	}
+	key('v')
+	if hb.archived || len(hb.games) != 3 {
+		t.Errorf("games after unarchiving: %+v", hb.games)
+	}
+}
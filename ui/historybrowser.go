@@ -1,35 +1,70 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
-	"termsuji-local/config"
+	"termsuji-local/coords"
 	"termsuji-local/sgf"
 )
 
 // HistoryBrowserUI provides a screen for browsing saved SGF game history.
 type HistoryBrowserUI struct {
-	flex     *tview.Flex
-	gameList *tview.List
-	preview  *tview.Box
-	hint     *tview.TextView
-	games    []sgf.GameInfo
-	boards   map[int][][]int // cached final positions
-	selected int
-	onDone   func()
-	onOpen   func(sgf.GameInfo)
-}
-
-// NewHistoryBrowser creates a new history browser screen.
-func NewHistoryBrowser(onDone func(), onOpen func(sgf.GameInfo)) *HistoryBrowserUI {
+	flex        *tview.Flex
+	body        *tview.Pages // the list and preview, with the detail popup over them
+	gameList    *tview.List
+	preview     *tview.Box
+	hint        *tview.TextView
+	list        *GameList                     // the games shown: active's, or archive's in the archive view
+	active      *GameList                     // the history's games
+	archive     *GameList                     // the archived ones, listed once the archive is first shown
+	archived    bool                          // the list shows the archive
+	all         []sgf.GameInfo                // the history's games, as last read from list
+	games       []sgf.GameInfo                // those the filter lets through, as listed
+	boards      map[string]*sgf.FinalPosition // cached final positions, by gameKey; nil if unreadable
+	selected    int                           // index into games
+	marked      string                        // gameKey of the game marked with space for comparison, "" if none
+	diff        *sgf.PositionDiff             // comparison of the marked and selected games, nil when off
+	diffGames   [2]sgf.GameInfo               // the marked and selected games diff compares
+	filter      historyFilter                 // narrows games; the zero value lets all through
+	sortKey     sgf.SortKey                   // the order all is in
+	onSort      func(sgf.SortKey)             // told each new order, to remember it
+	typing      bool                          // keys go to the filter bar, opened with '/'
+	player      string                        // the name the human plays under, to tell wins from losses
+	trashed     []sgf.Trashed                 // games deleted this session, the last one restored first by U
+	queue       func(func())                  // runs a function on the UI goroutine; nil to read games inline
+	unread      int                           // headers still being read in the background
+	stopLoad    chan struct{}                 // closed to stop the background reading of the last load
+	replaying   map[string]bool               // games being replayed for the preview, by gameKey
+	expanded    map[string]bool               // collection files listed a row per game, by path
+	collections map[string][]sgf.GameInfo     // the games of collection files expanded, by path
+	onDone      func()
+	onReview    func(sgf.GameInfo)
+	onOpen      func(sgf.GameInfo)
+	onRematch   func(sgf.GameInfo)
+	onVerify    func()
+}
+
+// NewHistoryBrowser creates a new history browser screen for the games in
+// list. They are read on Refresh.
+func NewHistoryBrowser(list *GameList, onDone func(), onReview, onOpen, onRematch func(sgf.GameInfo), onVerify func()) *HistoryBrowserUI {
 	hb := &HistoryBrowserUI{
-		onDone: onDone,
-		onOpen: onOpen,
-		boards: make(map[int][][]int),
+		list:        list,
+		active:      list,
+		onDone:      onDone,
+		onReview:    onReview,
+		onOpen:      onOpen,
+		onRematch:   onRematch,
+		onVerify:    onVerify,
+		boards:      make(map[string]*sgf.FinalPosition),
+		replaying:   make(map[string]bool),
+		expanded:    make(map[string]bool),
+		collections: make(map[string][]sgf.GameInfo),
 	}
 
 	// Game list (left panel)
@@ -53,11 +88,14 @@ func NewHistoryBrowser(onDone func(), onOpen func(sgf.GameInfo)) *HistoryBrowser
 	hb.hint = tview.NewTextView()
 	hb.hint.SetDynamicColors(true)
 	hb.hint.SetBorder(false)
-	hb.hint.SetText("  [dimgray]o[-] open  [dimgray]d[-] delete  [dimgray]q[-] back")
+	hb.setHint("")
 
 	// Handle list selection changes
 	hb.gameList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
 		hb.selected = index
+		if hb.diff != nil {
+			hb.closeDiff()
+		}
 	})
 
 	// Input handling
@@ -68,11 +106,14 @@ func NewHistoryBrowser(onDone func(), onOpen func(sgf.GameInfo)) *HistoryBrowser
 		AddItem(hb.gameList, 38, 0, true).
 		AddItem(hb.preview, 0, 1, false)
 
+	hb.body = tview.NewPages().
+		AddPage("list", topRow, true, true)
+
 	hb.flex = tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(topRow, 0, 1, true).
+		AddItem(hb.body, 0, 1, true).
 		AddItem(hb.hint, 1, 0, false)
 
-	hb.loadGames()
+	hb.showGames()
 	return hb
 }
 
@@ -81,83 +122,644 @@ func (hb *HistoryBrowserUI) Flex() *tview.Flex {
 	return hb.flex
 }
 
-// Refresh reloads the game list from disk.
+// SetApp has the browser read headers and replay previews in the
+// background, drawing app again as they come in. Without it they are read
+// as they are needed.
+func (hb *HistoryBrowserUI) SetApp(app *tview.Application) {
+	hb.queue = func(f func()) { app.QueueUpdateDraw(f) }
+}
+
+// SetPlayerName sets the name the human plays under, besides "Player", so
+// results read as wins and losses in games played under it.
+func (hb *HistoryBrowserUI) SetPlayerName(name string) {
+	hb.player = name
+}
+
+// SetSort puts the list in the order key gives; s then cycles through the
+// orders, telling onChange of each.
+func (hb *HistoryBrowserUI) SetSort(key sgf.SortKey, onChange func(sgf.SortKey)) {
+	hb.sortKey, hb.onSort = key, onChange
+	sgf.SortGames(hb.all, key)
+	hb.showGames()
+}
+
+// cycleSort moves on to the next order.
+func (hb *HistoryBrowserUI) cycleSort() {
+	hb.sortKey = hb.sortKey.Next()
+	sgf.SortGames(hb.all, hb.sortKey)
+	hb.showGames()
+	if hb.onSort != nil {
+		hb.onSort(hb.sortKey)
+	}
+}
+
+// Refresh reloads the game list, from disk if the list was invalidated.
+// The filter stays.
 func (hb *HistoryBrowserUI) Refresh() {
-	hb.boards = make(map[int][][]int)
+	hb.boards = make(map[string]*sgf.FinalPosition)
+	hb.replaying = make(map[string]bool)
+	hb.collections = make(map[string][]sgf.GameInfo)
 	hb.loadGames()
 }
 
-// loadGames scans the history directory for SGF files.
+// headerBatch is how many headers are read before the list shows them.
+const headerBatch = 50
+
+// loadGames lists the history's games straight away and reads the headers
+// not cached yet, in the background once SetApp gives a way back to the
+// UI. Until its header is read a game is listed by its file's date.
 func (hb *HistoryBrowserUI) loadGames() {
+	if hb.stopLoad != nil {
+		close(hb.stopLoad)
+		hb.stopLoad = nil
+	}
+	hb.marked = ""
+	hb.all = nil
+	if files, err := hb.list.Files(); err == nil {
+		hb.all = files
+		sgf.SortGames(hb.all, hb.sortKey)
+	}
+	var unread []sgf.GameInfo
+	for _, g := range hb.all {
+		if !headerRead(g) {
+			unread = append(unread, g)
+		}
+	}
+	hb.unread = len(unread)
+	hb.selected = 0
+	if hb.queue == nil {
+		hb.fillHeaders(hb.readHeaders(unread))
+		return
+	}
+	hb.showGames()
+	if len(unread) == 0 {
+		return
+	}
+	stop := make(chan struct{})
+	hb.stopLoad = stop
+	go func() {
+		for start := 0; start < len(unread); start += headerBatch {
+			end := start + headerBatch
+			if end > len(unread) {
+				end = len(unread)
+			}
+			read := hb.readHeaders(unread[start:end])
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			hb.queue(func() {
+				select {
+				case <-stop:
+				default:
+					hb.fillHeaders(read)
+				}
+			})
+		}
+	}()
+}
+
+// headerRead reports whether g is more than a file listed by
+// GameList.Files: its header has been read, or found unreadable.
+func headerRead(g sgf.GameInfo) bool {
+	return g.BoardSize != 0 || g.ReadErr != nil
+}
+
+// readHeaders reads the headers of files through the list's cache,
+// keyed by path. Those that aren't games come with ReadErr set.
+func (hb *HistoryBrowserUI) readHeaders(files []sgf.GameInfo) map[string]sgf.GameInfo {
+	read := make(map[string]sgf.GameInfo, len(files))
+	for _, f := range files {
+		g, _ := hb.list.Header(f)
+		read[f.FilePath] = g
+	}
+	return read
+}
+
+// fillHeaders puts the headers read in place of the files listed for them,
+// files that turned out not to be games included, and lists the games
+// again in order. Files deleted meanwhile stay out.
+func (hb *HistoryBrowserUI) fillHeaders(read map[string]sgf.GameInfo) {
+	hb.unread -= len(read)
+	all := hb.all[:0:0]
+	for _, g := range hb.all {
+		if r, ok := read[g.FilePath]; ok && !headerRead(g) {
+			g = r
+		}
+		all = append(all, g)
+	}
+	hb.all = all
+	sgf.SortGames(hb.all, hb.sortKey)
+	hb.showGames()
+}
+
+// showGames fills the list with the games the filter lets through, from
+// those already read, keeping the selected game selected if it still
+// shows.
+func (hb *HistoryBrowserUI) showGames() {
+	var selected string
+	if hb.selected >= 0 && hb.selected < len(hb.games) {
+		selected = gameKey(hb.games[hb.selected])
+	}
 	hb.gameList.Clear()
-	hb.games = nil
+	hb.closeDiff()
+
+	hb.games = hb.withCollections(hb.filter.apply(hb.all))
 	hb.selected = 0
+	for i, g := range hb.games {
+		hb.gameList.AddItem(hb.label(i), "", 0, nil)
+		if gameKey(g) == selected {
+			hb.selected = i
+		}
+	}
+	hb.gameList.SetTitle(hb.title())
+	switch {
+	case len(hb.all) == 0:
+		hb.gameList.AddItem(tag("dimgray", "No games found"), "", 0, nil)
+	case len(hb.games) == 0:
+		hb.gameList.AddItem(tag("dimgray", "No games match"), "", 0, nil)
+	default:
+		hb.gameList.SetCurrentItem(hb.selected)
+	}
+}
 
-	games, err := sgf.ListGames(config.HistoryDir())
-	if err != nil || len(games) == 0 {
-		hb.gameList.AddItem("[dimgray]No games found[-]", "", 0, nil)
+// title is the list's title: the order, after the filter and how many
+// games pass it when one is set, and how many headers are still being
+// read.
+func (hb *HistoryBrowserUI) title() string {
+	reading := ""
+	if hb.unread > 0 {
+		reading = fmt.Sprintf("reading %d… ", hb.unread)
+	}
+	if hb.filter.empty() {
+		name := "Game History"
+		if hb.archived {
+			name = "Archive"
+		}
+		return glyphs(fmt.Sprintf(" %s · %s %s", name, hb.sortKey, reading))
+	}
+	return glyphs(fmt.Sprintf(" %s · %d of %d · %s %s", hb.filter, len(hb.games), len(hb.all), hb.sortKey, reading))
+}
+
+// label is the list text for game i, flagged when it is marked for comparison.
+func (hb *HistoryBrowserUI) label(i int) string {
+	g := hb.games[i]
+	mark := "  "
+	if gameKey(g) == hb.marked && hb.marked != "" {
+		mark = tag("yellow", "A") + " "
+	}
+	if !headerRead(g) {
+		date := g.Date
+		if date == "" {
+			date = g.FileName
+		}
+		return mark + date + "  " + tag("dimgray", glyphs("…"))
+	}
+	if g.ReadErr != nil {
+		return mark + tag("dimgray", tview.Escape(g.FileName+"  unreadable: "+readReason(g.ReadErr)))
+	}
+	result := resultTag(g.OutcomeFor(hb.player), sgf.ShortResult(g.Result))
+	if g.Result == "" || g.Result == "?" {
+		result = "..."
+	}
+	label := fmt.Sprintf("%s%s  %dx%d  %s", mark, g.Date, g.BoardSize, g.BoardSize, result)
+	if g.GameIndex > 0 {
+		// Under the collection's first game, in place of the date it shares
+		label = fmt.Sprintf("%s  %s  %dx%d  %s", mark, hb.collectionLabel(g), g.BoardSize, g.BoardSize, result)
+	} else if c := hb.collectionLabel(g); c != "" {
+		label += "  " + c
+	}
+	if g.GameName != "" {
+		label += "  " + tag("white", tview.Escape(g.GameName))
+	}
+	if !g.GameType.VsEngine() {
+		// Not a game against the engine, so not one to judge progress by
+		label += "  " + tag("teal", string(g.GameType))
+	}
+	return label
+}
+
+// readReason says in a few words why a file isn't a readable game.
+func readReason(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		// The path is in the row already
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// readable reports whether game can be opened, saying in the hint why
+// not when it can't.
+func (hb *HistoryBrowserUI) readable(game sgf.GameInfo) bool {
+	if game.ReadErr == nil {
+		return true
+	}
+	hb.setHint(tag("red", tview.Escape(game.FileName+" is unreadable: "+readReason(game.ReadErr))+"; V checks and repairs"))
+	return false
+}
+
+// setHint shows the key hints, preceded by msg when it isn't empty. While
+// the filter is typed the hint line is the filter bar.
+func (hb *HistoryBrowserUI) setHint(msg string) {
+	if hb.typing {
+		hb.hint.SetText("  " + glyphs(tag("yellow", "/")+" "+tview.Escape(hb.filter.query)+tag("dimgray", "▏")+"  "+
+			keyHints("⏎", "done", "esc", "clear")))
+		return
+	}
+	hints := keyHints("⏎", "review", "c", "continue", "m", "rematch", "d", "delete", "a", "archive", "r", "rename", "space", "mark", "=", "compare",
+		"/", "filter", "1 2 3", "9/13/19", "s", "sort", "v", "archived", "V", "verify", "i", "details", "q", "back")
+	if hb.archived {
+		hints = keyHints("⏎", "review", "a", "unarchive", "space", "mark", "=", "compare",
+			"/", "filter", "1 2 3", "9/13/19", "s", "sort", "v", "games", "i", "details", "q", "back")
+	}
+	if msg != "" {
+		hints = msg + "  " + hints
+	}
+	hb.hint.SetText("  " + glyphs(hints))
+}
+
+// toggleMark marks the selected game as the first side of a comparison,
+// or clears the mark if it is already there.
+func (hb *HistoryBrowserUI) toggleMark() {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return
+	}
+	path := gameKey(hb.games[hb.selected])
+	if hb.marked == path {
+		hb.marked = ""
+	} else {
+		hb.marked = path
+	}
+	for i := range hb.games {
+		hb.gameList.SetItemText(i, hb.label(i), "")
+	}
+	hb.setHint("")
+}
+
+// compareWithMarked diffs the final positions of the marked and selected
+// games and shows the result in the preview, or says why it can't.
+func (hb *HistoryBrowserUI) compareWithMarked() {
+	if hb.diff != nil {
+		hb.closeDiff()
+		return
+	}
+	marked, ok := hb.markedGame()
+	if !ok || hb.selected < 0 || hb.selected >= len(hb.games) {
+		hb.setHint(tag("red", "mark a game with space first"))
+		return
+	}
+	selected := hb.games[hb.selected]
+	if gameKey(marked) == gameKey(selected) {
+		hb.setHint(tag("red", "select a different game to compare with"))
+		return
+	}
+	a, b := hb.finalBoard(marked), hb.finalBoard(selected)
+	if a == nil || b == nil {
+		hb.setHint(tag("red", "could not read one of the games"))
 		return
 	}
+	diff, err := sgf.DiffPositions(a, b)
+	if err != nil {
+		hb.setHint(tag("red", "can't compare: "+err.Error()))
+		return
+	}
+	hb.diff = diff
+	hb.diffGames = [2]sgf.GameInfo{marked, selected}
+	hb.preview.SetTitle(" Compare ")
+	hb.setHint(tag("dimgray", "= to close"))
+}
+
+// closeDiff returns the preview to the selected game.
+func (hb *HistoryBrowserUI) closeDiff() {
+	hb.diff = nil
+	hb.preview.SetTitle(" Preview ")
+	hb.setHint("")
+}
 
-	hb.games = games
-	for _, g := range games {
-		result := g.Result
-		if result == "" || result == "?" {
-			result = "..."
+// markedGame returns the game marked for comparison, which the filter
+// may be hiding.
+func (hb *HistoryBrowserUI) markedGame() (sgf.GameInfo, bool) {
+	for _, g := range append(hb.games, hb.all...) {
+		if hb.marked != "" && gameKey(g) == hb.marked {
+			return g, true
 		}
-		label := fmt.Sprintf("%s  %dx%d  %s", g.Date, g.BoardSize, g.BoardSize, result)
-		hb.gameList.AddItem(label, "", 0, nil)
 	}
+	return sgf.GameInfo{}, false
+}
+
+// finalBoard returns the final position of game, replaying and caching
+// it on first use. It returns nil if the game can't be read.
+func (hb *HistoryBrowserUI) finalBoard(game sgf.GameInfo) [][]int {
+	if pos := hb.finalPosition(game); pos != nil {
+		return pos.Board
+	}
+	return nil
+}
+
+// finalPosition is finalBoard with the last move and captures.
+func (hb *HistoryBrowserUI) finalPosition(game sgf.GameInfo) *sgf.FinalPosition {
+	if pos, ok := hb.boards[gameKey(game)]; ok {
+		return pos
+	}
+	pos := replayFinal(game)
+	hb.boards[gameKey(game)] = pos
+	return pos
+}
+
+// replayFinal replays game, or returns nil if it can't be read.
+func replayFinal(game sgf.GameInfo) *sgf.FinalPosition {
+	pos, err := sgf.ReplayFinal(game.FilePath, game.GameIndex)
+	if err != nil {
+		return nil
+	}
+	return &pos
+}
+
+// previewBoard returns the final position of game for the preview, and
+// whether it is ready. With SetApp a game not yet replayed is replayed in
+// the background, and the preview drawn again when it is.
+func (hb *HistoryBrowserUI) previewBoard(game sgf.GameInfo) (*sgf.FinalPosition, bool) {
+	path := gameKey(game)
+	if pos, ok := hb.boards[path]; ok || hb.queue == nil {
+		if !ok {
+			pos = hb.finalPosition(game)
+		}
+		return pos, true
+	}
+	if !hb.replaying[path] {
+		hb.replaying[path] = true
+		go func() {
+			pos := replayFinal(game)
+			hb.queue(func() {
+				// Not if the game was deleted or the list refreshed meanwhile
+				if hb.replaying[path] {
+					delete(hb.replaying, path)
+					hb.boards[path] = pos
+				}
+			})
+		}()
+	}
+	return nil, false
 }
 
 // handleInput processes keyboard input for the history browser.
 func (hb *HistoryBrowserUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	if hb.typing {
+		return hb.handleFilterKey(event)
+	}
 	switch event.Key() {
 	case tcell.KeyEscape:
+		if !hb.filter.empty() {
+			hb.setFilter(historyFilter{})
+			return nil
+		}
 		if hb.onDone != nil {
 			hb.onDone()
 		}
 		return nil
+	case tcell.KeyEnter:
+		hb.reviewSelected()
+		return nil
+	case tcell.KeyRight:
+		if hb.expandSelected() {
+			return nil
+		}
+	case tcell.KeyLeft:
+		if hb.collapseSelected() {
+			return nil
+		}
 	case tcell.KeyRune:
+		if hb.archived && strings.ContainsRune("cmdUr", event.Rune()) {
+			hb.setHint(tag("yellow", "archived games can only be reviewed; a unarchives"))
+			return nil
+		}
 		switch event.Rune() {
 		case 'q':
 			if hb.onDone != nil {
 				hb.onDone()
 			}
 			return nil
-		case 'o':
+		case 'c':
 			hb.openSelected()
 			return nil
+		case 'm':
+			hb.rematchSelected()
+			return nil
 		case 'd':
-			hb.deleteSelected()
+			hb.confirmDelete()
+			return nil
+		case 'U':
+			hb.undoDelete()
+			return nil
+		case 'r':
+			hb.renameSelected()
+			return nil
+		case ' ':
+			hb.toggleMark()
+			return nil
+		case '=':
+			hb.compareWithMarked()
+			return nil
+		case 'a':
+			hb.archiveSelected()
+			return nil
+		case 'v':
+			hb.toggleArchive()
+			return nil
+		case 'V':
+			if hb.onVerify != nil {
+				hb.onVerify()
+			}
+			return nil
+		case 'i':
+			hb.showDetails()
+			return nil
+		case 's':
+			hb.cycleSort()
+			return nil
+		case '/':
+			hb.typing = true
+			hb.setHint("")
+			return nil
+		case '1', '2', '3':
+			f := hb.filter
+			f.toggleSize(filterSizes[event.Rune()-'1'])
+			hb.setFilter(f)
 			return nil
 		}
 	}
 	return event
 }
 
+// reviewSelected opens the selected game to step through.
+func (hb *HistoryBrowserUI) reviewSelected() {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return
+	}
+	if !hb.readable(hb.games[hb.selected]) {
+		return
+	}
+	if hb.onReview != nil {
+		hb.onReview(hb.games[hb.selected])
+	}
+}
+
 // openSelected loads the currently selected game for continued play.
 func (hb *HistoryBrowserUI) openSelected() {
 	if hb.selected < 0 || hb.selected >= len(hb.games) {
 		return
 	}
+	if !hb.readable(hb.games[hb.selected]) {
+		return
+	}
+	if hb.games[hb.selected].Games > 1 {
+		hb.setHint(tag("yellow", "a collection's games can't be continued; b in review plays on from any"))
+		return
+	}
 	if hb.onOpen != nil {
 		hb.onOpen(hb.games[hb.selected])
 	}
 }
 
-// deleteSelected removes the currently selected game file.
+// rematchSelected starts a new game with the selected game's setup.
+func (hb *HistoryBrowserUI) rematchSelected() {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return
+	}
+	if !hb.readable(hb.games[hb.selected]) {
+		return
+	}
+	if hb.onRematch != nil {
+		hb.onRematch(hb.games[hb.selected])
+	}
+}
+
+// confirmDelete asks before deleting the selected game, naming it.
+func (hb *HistoryBrowserUI) confirmDelete() {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return
+	}
+	game := hb.games[hb.selected]
+	if !hb.wholeFile(game) {
+		return
+	}
+	result := sgf.ShortResult(game.Result)
+	if game.Result == "" || game.Result == "?" {
+		result = "unfinished"
+	}
+	text := fmt.Sprintf("Delete the %dx%d game of %s, %s?\n\nIt goes to the trash; U brings it back.",
+		game.BoardSize, game.BoardSize, game.Date, result)
+	if game.Games > 1 {
+		text = fmt.Sprintf("Delete %s, a collection of %d games?\n\nIt goes to the trash; U brings it back.", game.FileName, game.Games)
+	}
+	if game.ReadErr != nil {
+		text = fmt.Sprintf("Delete %s, which can't be read as a game?\n\nIt goes to the trash; U brings it back.", game.FileName)
+	}
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			hb.body.RemovePage("delete")
+			if buttonLabel == "Delete" {
+				hb.deleteSelected()
+			}
+		})
+	hb.body.AddPage("delete", modal, true, true)
+}
+
+// deleteSelected moves the selected game to the trash.
 func (hb *HistoryBrowserUI) deleteSelected() {
 	if hb.selected < 0 || hb.selected >= len(hb.games) {
 		return
 	}
 
 	game := hb.games[hb.selected]
-	os.Remove(game.FilePath)
+	t, err := sgf.TrashGame(game.FilePath)
+	if err != nil {
+		hb.setHint(tag("red", "can't delete: "+tview.Escape(err.Error())))
+		return
+	}
+	hb.trashed = append(hb.trashed, t)
+	hb.list.Invalidate()
+	hb.removeGame(game)
+	hb.setHint(tag("yellow", "moved "+game.FileName+" to the trash, U to undo"))
+}
 
-	// Clear board cache and reload
-	hb.boards = make(map[int][][]int)
-	hb.loadGames()
+// removeGame drops game from the list once its file has gone, rather
+// than read the rest again, selecting the game after it.
+func (hb *HistoryBrowserUI) removeGame(game sgf.GameInfo) {
+	hb.forgetFile(game.FilePath)
+	if hb.marked == game.FilePath || strings.HasPrefix(hb.marked, game.FilePath+"#") {
+		hb.marked = ""
+	}
+	for i, g := range hb.all {
+		if g.FilePath == game.FilePath {
+			hb.all = append(hb.all[:i:i], hb.all[i+1:]...)
+			break
+		}
+	}
+	next := hb.selected
+	hb.selected = -1
+	hb.showGames()
+	if next >= len(hb.games) {
+		next = len(hb.games) - 1
+	}
+	if next > 0 {
+		hb.selected = next
+		hb.gameList.SetCurrentItem(next)
+	}
+}
+
+// undoDelete puts back the game deleted last and selects it.
+func (hb *HistoryBrowserUI) undoDelete() {
+	if len(hb.trashed) == 0 {
+		hb.setHint(tag("dimgray", "nothing to undo"))
+		return
+	}
+	t := hb.trashed[len(hb.trashed)-1]
+	hb.trashed = hb.trashed[:len(hb.trashed)-1]
+	if err := sgf.RestoreGame(t); err != nil {
+		hb.setHint(tag("red", "can't restore: "+tview.Escape(err.Error())))
+		return
+	}
+	hb.list.Invalidate()
+
+	// Whatever was cached for the path is from before the delete
+	hb.forgetFile(t.From)
+	game, err := sgf.ParseHeader(t.From)
+	if err != nil {
+		hb.Refresh()
+		return
+	}
+	game.ModTime = t.ModTime
+	hb.all = append(hb.all, *game)
+	sgf.SortGames(hb.all, hb.sortKey)
+	hb.showGames()
+	for i, g := range hb.games {
+		if g.FilePath == t.From {
+			hb.selected = i
+			hb.gameList.SetCurrentItem(i)
+		}
+	}
+	hb.setHint(tag("yellow", "restored "+game.FileName))
+}
+
+// drawUnreadable fills the preview for a file that isn't a readable game:
+// its name, why, and what to do about it, wrapped to width.
+func (hb *HistoryBrowserUI) drawUnreadable(screen tcell.Screen, x, y, width int, game sgf.GameInfo) {
+	drawText(screen, x, y, game.FileName, fgStyle(tcell.PaletteColor(250)))
+	y += 2
+	for _, line := range wrapWords("unreadable: "+readReason(game.ReadErr), width) {
+		drawText(screen, x, y, line, fgStyle(tcell.PaletteColor(174)))
+		y++
+	}
+	y++
+	for _, line := range wrapWords("V checks the history and repairs what it can; d deletes the file.", width) {
+		drawText(screen, x, y, line, fgStyle(tcell.PaletteColor(245)))
+		y++
+	}
 }
 
 // drawPreview renders a mini board preview and game metadata.
@@ -166,29 +768,34 @@ func (hb *HistoryBrowserUI) drawPreview(screen tcell.Screen, x, y, width, height
 		return x, y, width, height
 	}
 
-	game := hb.games[hb.selected]
+	if hb.diff != nil {
+		hb.drawDiff(screen, x, y, width, height)
+		return x, y, width, height
+	}
 
-	// Lazy-load and cache the board position
-	board, ok := hb.boards[hb.selected]
-	if !ok {
-		b, _, err := sgf.ReplayToEnd(game.FilePath)
-		if err == nil {
-			board = b
-			hb.boards[hb.selected] = board
-		}
+	game := hb.games[hb.selected]
+	if game.ReadErr != nil {
+		hb.drawUnreadable(screen, x+2, y+1, width-4, game)
+		return x, y, width, height
+	}
+	pos, ready := hb.previewBoard(game)
+	if !ready || !headerRead(game) {
+		drawText(screen, x+2, y+1, glyphs("loading…"), fgStyle(tcell.PaletteColor(245)))
+		return x, y, width, height
 	}
 
 	// Draw mini board
-	if board != nil {
+	if pos != nil {
+		board := pos.Board
 		size := len(board)
 		startX := x + 2
 		startY := y + 1
 
 		// Check we have room (2 chars wide per cell for square aspect ratio)
 		if width >= size*2+4 && height >= size+6 {
-			emptyStyle := tcell.StyleDefault.Foreground(tcell.PaletteColor(240))
-			blackStyle := tcell.StyleDefault.Foreground(tcell.PaletteColor(255)).Bold(true)
-			whiteStyle := tcell.StyleDefault.Foreground(tcell.PaletteColor(250))
+			emptyStyle := fgStyle(tcell.PaletteColor(240))
+			blackStyle := fgStyle(tcell.PaletteColor(255)).Bold(true)
+			whiteStyle := fgStyle(tcell.PaletteColor(250))
 
 			for by := 0; by < size; by++ {
 				for bx := 0; bx < size; bx++ {
@@ -202,14 +809,20 @@ func (hb *HistoryBrowserUI) drawPreview(screen tcell.Screen, x, y, width, height
 						ch = '○'
 						style = whiteStyle
 					}
-					screen.SetContent(startX+bx*2, startY+by, ch, nil, style)
+					screen.SetContent(startX+bx*2, startY+by, glyph(ch), nil, style)
 				}
 			}
+			// The last move in brackets, as the board marks it
+			if lx, ly := pos.LastX, pos.LastY; lx >= 0 && lx < size && ly >= 0 && ly < size {
+				markStyle := fgStyle(tcell.PaletteColor(179)).Bold(true)
+				screen.SetContent(startX+lx*2-1, startY+ly, '(', nil, markStyle)
+				screen.SetContent(startX+lx*2+1, startY+ly, ')', nil, markStyle)
+			}
 
 			// Metadata below the board
 			infoY := startY + size + 1
-			infoStyle := tcell.StyleDefault.Foreground(tcell.PaletteColor(250))
-			dimStyle := tcell.StyleDefault.Foreground(tcell.PaletteColor(245))
+			infoStyle := fgStyle(tcell.PaletteColor(250))
+			dimStyle := fgStyle(tcell.PaletteColor(245))
 
 			drawText(screen, startX, infoY, fmt.Sprintf("%dx%d", game.BoardSize, game.BoardSize), infoStyle)
 			drawText(screen, startX+6, infoY, fmt.Sprintf("| %d moves", game.MoveCount), dimStyle)
@@ -220,21 +833,109 @@ func (hb *HistoryBrowserUI) drawPreview(screen tcell.Screen, x, y, width, height
 			drawText(screen, startX, infoY, fmt.Sprintf("W: %s", game.PlayerWhite), dimStyle)
 
 			infoY++
-			result := game.Result
-			if result == "" || result == "?" {
-				result = "Unfinished"
+			result := sgf.FormatResult(game.Result)
+			if game.Result == "" || game.Result == "?" {
+				// What resuming it starts with
+				toPlay := "Black"
+				if game.NextColor == 2 {
+					toPlay = "White"
+				}
+				result = glyphs("Unfinished · " + toPlay + " to play")
+			}
+			resultStyle := outcomeStyle(game.OutcomeFor(hb.player), fgStyle(tcell.PaletteColor(109)))
+			resultText := fmt.Sprintf("Result: %s", result)
+			drawText(screen, startX, infoY, resultText, resultStyle)
+			if game.HasReport {
+				// A report was written beside the game
+				drawText(screen, startX+textWidth(resultText)+2, infoY, "[report]", fgStyle(tcell.PaletteColor(245)))
+			}
+
+			if pos.LastColor != 0 && infoY+1 < y+height {
+				infoY++
+				drawText(screen, startX, infoY, glyphs(lastMoveLine(*pos)), dimStyle)
+			}
+
+			if game.GameName != "" && infoY+1 < y+height {
+				infoY++
+				drawText(screen, startX, infoY, game.GameName, fgStyle(tcell.PaletteColor(255)).Bold(true))
 			}
-			resultStyle := tcell.StyleDefault.Foreground(tcell.PaletteColor(109))
-			drawText(screen, startX, infoY, fmt.Sprintf("Result: %s", result), resultStyle)
 		}
 	}
 
 	return x, y, width, height
 }
 
+// lastMoveLine sums up how pos was reached for the preview, e.g.
+// "last: Q16 (W) · captures B 3, W 1".
+func lastMoveLine(pos sgf.FinalPosition) string {
+	side := "B"
+	if pos.LastColor == 2 {
+		side = "W"
+	}
+	return fmt.Sprintf("last: %s (%s) · captures B %d, W %d",
+		coords.Display(pos.LastX, pos.LastY, len(pos.Board)), side, pos.CapturesBlack, pos.CapturesWhite)
+}
+
+// drawDiff renders the comparison: stones in both games as usual, and
+// stones only in A, only in B, or of different colors each tinted.
+func (hb *HistoryBrowserUI) drawDiff(screen tcell.Screen, x, y, width, height int) {
+	d := hb.diff
+	size := len(d.A)
+	startX := x + 2
+	startY := y + 1
+	if width < size*2+4 || height < size+7 {
+		return
+	}
+
+	emptyStyle := fgStyle(tcell.PaletteColor(240))
+	blackStyle := fgStyle(tcell.PaletteColor(255)).Bold(true)
+	whiteStyle := fgStyle(tcell.PaletteColor(250))
+	onlyAStyle := fgStyle(tcell.PaletteColor(173)).Bold(true)
+	onlyBStyle := fgStyle(tcell.PaletteColor(74)).Bold(true)
+	changedStyle := fgStyle(tcell.PaletteColor(167)).Bold(true)
+	dimStyle := fgStyle(tcell.PaletteColor(245))
+
+	stone := func(color int) rune {
+		if color == 1 {
+			return '●'
+		}
+		return '○'
+	}
+	for by := 0; by < size; by++ {
+		for bx := 0; bx < size; bx++ {
+			ch, style := '·', emptyStyle
+			switch d.Kinds[by][bx] {
+			case sgf.DiffSame:
+				ch, style = stone(d.A[by][bx]), whiteStyle
+				if d.A[by][bx] == 1 {
+					style = blackStyle
+				}
+			case sgf.DiffOnlyA:
+				ch, style = stone(d.A[by][bx]), onlyAStyle
+			case sgf.DiffOnlyB:
+				ch, style = stone(d.B[by][bx]), onlyBStyle
+			case sgf.DiffChanged:
+				ch, style = stone(d.B[by][bx]), changedStyle
+			}
+			screen.SetContent(startX+bx*2, startY+by, glyph(ch), nil, style)
+		}
+	}
+
+	// Legend
+	infoY := startY + size + 1
+	a, b := hb.diffGames[0], hb.diffGames[1]
+	drawText(screen, startX, infoY, glyphs(fmt.Sprintf("● only A: %d", d.OnlyA)), onlyAStyle)
+	drawText(screen, startX+16, infoY, a.Date, dimStyle)
+	infoY++
+	drawText(screen, startX, infoY, glyphs(fmt.Sprintf("● only B: %d", d.OnlyB)), onlyBStyle)
+	drawText(screen, startX+16, infoY, b.Date, dimStyle)
+	infoY++
+	drawText(screen, startX, infoY, glyphs(fmt.Sprintf("● color differs: %d", d.Changed)), changedStyle)
+	infoY++
+	drawText(screen, startX, infoY, fmt.Sprintf("in both: %d", d.Same), dimStyle)
+}
+
 // drawText writes a string to the screen at the given position.
 func drawText(screen tcell.Screen, x, y int, text string, style tcell.Style) {
-	for i, ch := range text {
-		screen.SetContent(x+i, y, ch, nil, style)
-	}
+	drawRunes(screen, x, y, text, style)
 }
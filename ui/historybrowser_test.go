@@ -0,0 +1,294 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/sgf"
+)
+
+func TestHistoryBrowserSort(t *testing.T) {
+	dir := writeHistory(t, []int{19, 9, 13}, []string{"W+R", "?", "B+2.5"})
+	list := NewGameList(dir)
+	games, _ := list.Games()
+	// The oldest-named game was the last touched
+	now := time.Now()
+	for i, g := range games {
+		when := now.Add(time.Duration(-i) * time.Minute)
+		if g.Date == "2026-01-01" {
+			when = now.Add(time.Minute)
+		}
+		os.Chtimes(g.FilePath, when, when)
+	}
+	list.Invalidate()
+
+	hb := NewHistoryBrowser(list, nil, nil, nil, nil, nil)
+	hb.Refresh()
+	var saved []sgf.SortKey
+	hb.SetSort(sgf.SortSize, func(key sgf.SortKey) { saved = append(saved, key) })
+	order := func() string {
+		var d []string
+		for _, g := range hb.games {
+			d = append(d, g.Date[8:])
+		}
+		return strings.Join(d, " ")
+	}
+	if got := order(); got != "02 03 01" {
+		t.Errorf("by size: %s", got)
+	}
+	if !strings.Contains(hb.gameList.GetTitle(), "by board size") {
+		t.Errorf("title %q doesn't name the order", hb.gameList.GetTitle())
+	}
+
+	// s cycles on, keeping the selected game selected
+	hb.gameList.SetCurrentItem(2)
+	s := tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone)
+	for _, want := range []string{"01 03 02", "03 01 02", "01 03 02"} {
+		hb.handleInput(s)
+		if got := order(); got != want {
+			t.Errorf("%s: %s, want %s", hb.sortKey, got, want)
+		}
+		if hb.games[hb.selected].Date != "2026-01-01" {
+			t.Errorf("%s: selected %s, want the Jan 1 game", hb.sortKey, hb.games[hb.selected].Date)
+		}
+	}
+	if len(saved) != 3 || saved[2] != sgf.SortNewest {
+		t.Errorf("orders saved: %v", saved)
+	}
+}
+
+// pressModal focuses the modal on top of hb's body, as the app would, and
+// presses the button label.
+func pressModal(t *testing.T, hb *HistoryBrowserUI, label string) {
+	t.Helper()
+	_, front := hb.body.GetFrontPage()
+	modal, ok := front.(*tview.Modal)
+	if !ok {
+		t.Fatalf("front page is %T, not a modal", front)
+	}
+	var focused tview.Primitive
+	var delegate func(p tview.Primitive)
+	delegate = func(p tview.Primitive) {
+		focused = p
+		p.Focus(delegate)
+	}
+	modal.Focus(delegate)
+	for i := 0; i < 3; i++ {
+		if b, ok := focused.(*tview.Button); ok && b.GetLabel() == label {
+			b.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), delegate)
+			return
+		}
+		focused.InputHandler()(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone), delegate)
+	}
+	t.Fatalf("no %q button", label)
+}
+
+func TestHistoryBrowserDeleteAndUndo(t *testing.T) {
+	dir := writeHistory(t, []int{9, 13, 19}, []string{"B+R", "W+3.5", "?"})
+	hb := NewHistoryBrowser(NewGameList(dir), nil, nil, nil, nil, nil)
+	hb.Refresh()
+	key := func(r rune) { hb.handleInput(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)) }
+
+	hb.gameList.SetCurrentItem(1)
+	game := hb.games[1]
+	hb.finalBoard(game)
+
+	// d only asks, naming the game
+	key('d')
+	if name, _ := hb.body.GetFrontPage(); name != "delete" {
+		t.Fatalf("front page %q after d, want the confirmation", name)
+	}
+	pressModal(t, hb, "Cancel")
+	if len(hb.games) != 3 || hb.body.HasPage("delete") {
+		t.Fatalf("cancel left %d games, modal open %v", len(hb.games), hb.body.HasPage("delete"))
+	}
+
+	key('d')
+	pressModal(t, hb, "Delete")
+	if len(hb.games) != 2 {
+		t.Fatalf("%d games after delete, want 2", len(hb.games))
+	}
+	if _, err := os.Stat(filepath.Join(dir, sgf.TrashDir, game.FileName)); err != nil {
+		t.Errorf("game not in the trash: %v", err)
+	}
+	if _, ok := hb.boards[game.FilePath]; ok {
+		t.Error("deleted game's preview still cached")
+	}
+
+	// U brings it back, selected, and its preview is read afresh
+	key('U')
+	if len(hb.games) != 3 || hb.games[hb.selected].FilePath != game.FilePath {
+		t.Fatalf("after U: %d games, selected %s", len(hb.games), hb.games[hb.selected].FileName)
+	}
+	if hb.finalBoard(game) == nil {
+		t.Error("restored game's preview can't be read")
+	}
+	if games, _ := hb.list.Games(); len(games) != 3 {
+		t.Errorf("list has %d games after U, want 3", len(games))
+	}
+	key('U')
+	if !strings.Contains(hb.hint.GetText(true), "nothing to undo") {
+		t.Errorf("hint %q after a second U", hb.hint.GetText(true))
+	}
+}
+
+func TestHistoryBrowserReadsInBackground(t *testing.T) {
+	dir := writeHistory(t, []int{9, 13, 19}, []string{"B+R", "W+3.5", "?"})
+	list := NewGameList(dir)
+	hb := NewHistoryBrowser(list, nil, nil, nil, nil, nil)
+	queued := make(chan func(), 8)
+	hb.queue = func(f func()) { queued <- f }
+	run := func() {
+		t.Helper()
+		select {
+		case f := <-queued:
+			f()
+		case <-time.After(time.Second):
+			t.Fatal("nothing came back from the background")
+		}
+	}
+
+	// Listed at once by file, headers to follow
+	hb.Refresh()
+	if len(hb.games) != 3 || headerRead(hb.games[0]) {
+		t.Fatalf("%d games listed, first %+v; want all three, unread", len(hb.games), hb.games[0])
+	}
+	if !strings.Contains(hb.gameList.GetTitle(), "reading 3") {
+		t.Errorf("title %q while reading", hb.gameList.GetTitle())
+	}
+
+	// Selection and deletion work meanwhile
+	hb.gameList.SetCurrentItem(1)
+	deleted := hb.games[1].FilePath
+	hb.deleteSelected()
+	selected := hb.games[hb.selected].FilePath
+	run()
+	if len(hb.games) != 2 || !headerRead(hb.games[0]) || !headerRead(hb.games[1]) {
+		t.Fatalf("after reading: %+v", hb.games)
+	}
+	for _, g := range hb.games {
+		if g.FilePath == deleted {
+			t.Error("deleted game listed again once its header was read")
+		}
+	}
+	if hb.games[hb.selected].FilePath != selected {
+		t.Errorf("selection moved to %s", hb.games[hb.selected].FileName)
+	}
+	if strings.Contains(hb.gameList.GetTitle(), "reading") {
+		t.Errorf("title %q once read", hb.gameList.GetTitle())
+	}
+
+	// The preview is replayed in the background too
+	game := hb.games[hb.selected]
+	if _, ready := hb.previewBoard(game); ready {
+		t.Fatal("preview ready before the replay")
+	}
+	run()
+	if board, ready := hb.previewBoard(game); !ready || board == nil {
+		t.Errorf("preview after the replay: %v, %v", board, ready)
+	}
+
+	// A second visit finds every header cached
+	list.Invalidate()
+	hb.Refresh()
+	if hb.unread != 0 || len(hb.games) != 2 || !headerRead(hb.games[0]) {
+		t.Errorf("second visit: %d unread, games %+v", hb.unread, hb.games)
+	}
+	select {
+	case <-queued:
+		t.Error("second visit read headers again")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestHistoryPreviewLastMove(t *testing.T) {
+	dir := writeHistory(t, []int{9, 9}, []string{"B+R", "?"})
+	hb := NewHistoryBrowser(NewGameList(dir), nil, nil, nil, nil, nil)
+	hb.Refresh()
+	screen := newTestScreen(t, 100, 30)
+	defer screen.Fini()
+	draw := func() string {
+		screen.Clear()
+		hb.flex.SetRect(0, 0, 100, 30)
+		hb.flex.Draw(screen)
+		screen.Show()
+		return screenText(screen)
+	}
+
+	// The newest game is unfinished: Black played A9, so White is to play
+	text := draw()
+	if !strings.Contains(text, "last: A9 (B)") || !strings.Contains(text, "Unfinished · White to play") {
+		t.Errorf("preview of the unfinished game:\n%s", text)
+	}
+	if !strings.Contains(text, "(●)") {
+		t.Errorf("last move not in brackets:\n%s", text)
+	}
+
+	hb.gameList.SetCurrentItem(1)
+	if text := draw(); strings.Contains(text, "to play") {
+		t.Errorf("a finished game has no side to play:\n%s", text)
+	}
+}
+
+func TestHistoryUnreadableFiles(t *testing.T) {
+	dir := writeHistory(t, []int{9}, []string{"B+R"})
+	for name, content := range map[string]string{
+		"2026-01-02_120000_9x9.sgf": "(;GM[1]SZ[9];B[ee];W[c",
+		"2026-01-03_120000_9x9.sgf": "not a game record",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reviewed := 0
+	list := NewGameList(dir)
+	hb := NewHistoryBrowser(list, nil, func(sgf.GameInfo) { reviewed++ }, nil, nil, nil)
+	hb.Refresh()
+	if len(hb.games) != 3 {
+		t.Fatalf("%d rows, want the game and both unreadable files", len(hb.games))
+	}
+	if games, _ := list.Games(); len(games) != 1 {
+		t.Errorf("Games returned %d, want only the readable one", len(games))
+	}
+
+	screen := newTestScreen(t, 100, 30)
+	defer screen.Fini()
+	draw := func() string {
+		screen.Clear()
+		hb.flex.SetRect(0, 0, 100, 30)
+		hb.flex.Draw(screen)
+		screen.Show()
+		return screenText(screen)
+	}
+	text := draw()
+	if !strings.Contains(text, "not an SGF file") || !strings.Contains(text, "2026-01-03_120000_9x9.sgf") {
+		t.Errorf("the newest file should show as unreadable, with its name:\n%s", text)
+	}
+	hb.gameList.SetCurrentItem(1)
+	if text := draw(); !strings.Contains(text, "unreadable: truncated") {
+		t.Errorf("preview of the truncated file:\n%s", text)
+	}
+
+	hb.handleInput(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if reviewed != 0 || !strings.Contains(hb.hint.GetText(true), "is unreadable") {
+		t.Errorf("Enter on an unreadable file reviewed %d, hint %q", reviewed, hb.hint.GetText(true))
+	}
+	hb.gameList.SetCurrentItem(2)
+	hb.handleInput(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if reviewed != 1 {
+		t.Errorf("Enter on the game reviewed %d, want 1", reviewed)
+	}
+
+	// Deleting still works, to clear the file away
+	hb.gameList.SetCurrentItem(1)
+	hb.deleteSelected()
+	if len(hb.games) != 2 {
+		t.Errorf("%d rows after deleting the truncated file, want 2", len(hb.games))
+	}
+}
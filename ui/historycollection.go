@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"termsuji-local/sgf"
+)
+
+// A collection file, several games one after another, lists as its first
+// game with the count beside it. → expands it into a row for each of the
+// others, ← folds them back. Each row reviews its own game; acting on the
+// file, deleting or archiving it, is done from the first.
+
+// gameKey tells the games in the list apart: the path, with the game's
+// number after it for all but a file's first.
+func gameKey(g sgf.GameInfo) string {
+	if g.GameIndex == 0 {
+		return g.FilePath
+	}
+	return fmt.Sprintf("%s#%d", g.FilePath, g.GameIndex+1)
+}
+
+// withCollections returns games with the other games of each expanded
+// collection after its first.
+func (hb *HistoryBrowserUI) withCollections(games []sgf.GameInfo) []sgf.GameInfo {
+	rows := games
+	for i := len(games) - 1; i >= 0; i-- {
+		g := games[i]
+		if g.Games < 2 || !hb.expanded[g.FilePath] {
+			continue
+		}
+		rest := hb.collections[g.FilePath]
+		if len(rest) > 1 {
+			rest = rest[1:]
+		}
+		if len(rows) == len(games) {
+			rows = append([]sgf.GameInfo(nil), games...)
+		}
+		rows = append(rows[:i+1:i+1], append(rest, rows[i+1:]...)...)
+	}
+	return rows
+}
+
+// expandSelected lists every game of the selected collection, reading
+// them on first use.
+func (hb *HistoryBrowserUI) expandSelected() bool {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return false
+	}
+	g := hb.games[hb.selected]
+	if g.Games < 2 || g.GameIndex > 0 || hb.expanded[g.FilePath] {
+		return false
+	}
+	if _, ok := hb.collections[g.FilePath]; !ok {
+		games, err := sgf.ParseCollection(g.FilePath)
+		if err != nil {
+			hb.setHint(tag("red", "can't read the collection: "+tview.Escape(err.Error())))
+			return true
+		}
+		hb.collections[g.FilePath] = games
+	}
+	hb.expanded[g.FilePath] = true
+	hb.showGames()
+	return true
+}
+
+// collapseSelected folds the selected game's collection back into its
+// first game, selecting it.
+func (hb *HistoryBrowserUI) collapseSelected() bool {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return false
+	}
+	g := hb.games[hb.selected]
+	if !hb.expanded[g.FilePath] {
+		return false
+	}
+	delete(hb.expanded, g.FilePath)
+	hb.showGames()
+	for i, r := range hb.games {
+		if r.FilePath == g.FilePath {
+			hb.selected = i
+			hb.gameList.SetCurrentItem(i)
+			break
+		}
+	}
+	return true
+}
+
+// collectionLabel is what a collection adds to a game's row: the count
+// on its first game, the place in it on the others.
+func (hb *HistoryBrowserUI) collectionLabel(g sgf.GameInfo) string {
+	switch {
+	case g.Games < 2:
+		return ""
+	case g.GameIndex > 0:
+		return tag("teal", fmt.Sprintf("%d/%d", g.GameIndex+1, g.Games))
+	case hb.expanded[g.FilePath]:
+		return tag("teal", glyphs(fmt.Sprintf("▾ 1/%d", g.Games)))
+	default:
+		return tag("teal", glyphs(fmt.Sprintf("▸ %d games", g.Games)))
+	}
+}
+
+// wholeFile reports whether game's row stands for its file, to delete,
+// archive or rename, saying in the hint which row does when it doesn't.
+func (hb *HistoryBrowserUI) wholeFile(game sgf.GameInfo) bool {
+	if game.GameIndex == 0 {
+		return true
+	}
+	hb.setHint(tag("yellow", "the file's first game stands for the collection"))
+	return false
+}
+
+// forgetFile drops what is cached for any game of the file at path.
+func (hb *HistoryBrowserUI) forgetFile(path string) {
+	for key := range hb.boards {
+		if key == path || strings.HasPrefix(key, path+"#") {
+			delete(hb.boards, key)
+		}
+	}
+	for key := range hb.replaying {
+		if key == path || strings.HasPrefix(key, path+"#") {
+			delete(hb.replaying, key)
+		}
+	}
+	delete(hb.collections, path)
+	delete(hb.expanded, path)
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/sgf"
+)
+
+func TestHistoryCollection(t *testing.T) {
+	dir := writeHistory(t, []int{9}, []string{"B+R"})
+	collection := "(;GM[1]SZ[9]DT[2026-01-05]RE[B+R];B[ee])\n(;GM[1]SZ[13]DT[2026-01-05]RE[W+R];B[dd];W[cc])\n(;GM[1]SZ[9]RE[?];B[aa])"
+	if err := os.WriteFile(filepath.Join(dir, "2026-01-05_120000_9x9.sgf"), []byte(collection), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var reviewed, opened []sgf.GameInfo
+	hb := NewHistoryBrowser(NewGameList(dir), nil, func(g sgf.GameInfo) { reviewed = append(reviewed, g) },
+		func(g sgf.GameInfo) { opened = append(opened, g) }, nil, nil)
+	hb.Refresh()
+	if len(hb.games) != 2 || hb.games[0].Games != 3 {
+		t.Fatalf("%d rows, first of %d games; want the collection as one row", len(hb.games), hb.games[0].Games)
+	}
+	if l := hb.label(0); !strings.Contains(l, "3 games") {
+		t.Errorf("collection row %q doesn't give the count", l)
+	}
+
+	key := func(k tcell.Key) { hb.handleInput(tcell.NewEventKey(k, 0, tcell.ModNone)) }
+	key(tcell.KeyRight)
+	if len(hb.games) != 4 || hb.games[1].GameIndex != 1 || hb.games[2].GameIndex != 2 || hb.games[3].Games != 1 {
+		t.Fatalf("expanded rows %+v", hb.games)
+	}
+	if l := hb.label(1); !strings.Contains(l, "2/3") || !strings.Contains(l, "13x13") {
+		t.Errorf("second game's row %q", l)
+	}
+
+	// The preview and review are of the game on the row
+	hb.gameList.SetCurrentItem(1)
+	screen := newTestScreen(t, 100, 30)
+	defer screen.Fini()
+	hb.flex.SetRect(0, 0, 100, 30)
+	hb.flex.Draw(screen)
+	screen.Show()
+	if pos := hb.boards[gameKey(hb.games[1])]; pos == nil || len(pos.Board) != 13 || pos.Moves != 2 {
+		t.Errorf("second game's preview %+v", pos)
+	}
+	key(tcell.KeyEnter)
+	if len(reviewed) != 1 || reviewed[0].GameIndex != 1 || reviewed[0].BoardSize != 13 {
+		t.Errorf("reviewed %+v, want the second game", reviewed)
+	}
+
+	// The file is deleted from its first game's row only
+	hb.handleInput(tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone))
+	if hb.body.HasPage("delete") || !strings.Contains(hb.hint.GetText(true), "first game") {
+		t.Errorf("d on the second game: hint %q", hb.hint.GetText(true))
+	}
+	hb.handleInput(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if !strings.Contains(hb.hint.GetText(true), "can't be continued") {
+		t.Errorf("c on the second game: hint %q", hb.hint.GetText(true))
+	}
+
+	key(tcell.KeyLeft)
+	if len(hb.games) != 2 || hb.selected != 0 {
+		t.Errorf("after folding: %d rows, selected %d", len(hb.games), hb.selected)
+	}
+	hb.handleInput(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if len(opened) != 0 || !strings.Contains(hb.hint.GetText(true), "can't be continued") {
+		t.Errorf("c on the collection's row: opened %d, hint %q", len(opened), hb.hint.GetText(true))
+	}
+	hb.handleInput(tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone))
+	if !hb.body.HasPage("delete") {
+		t.Errorf("d on the collection's row should ask to delete the file")
+	}
+}
@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/sgf"
+)
+
+// The detail popup shows where a saved game lives on disk and gathers the
+// actions on its file in one list.
+
+// gameDetails is what the popup shows about a game's file.
+type gameDetails struct {
+	Path     string
+	Size     int64
+	Modified time.Time
+	Moves    int
+	SHA1     string
+}
+
+// readDetails stats and hashes the file of game.
+func readDetails(game sgf.GameInfo) (gameDetails, error) {
+	d := gameDetails{Path: game.FilePath, Moves: game.MoveCount}
+	f, err := os.Open(game.FilePath)
+	if err != nil {
+		return d, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return d, err
+	}
+	d.Size, d.Modified = fi.Size(), fi.ModTime()
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return d, err
+	}
+	d.SHA1 = fmt.Sprintf("%x", h.Sum(nil))
+	return d, nil
+}
+
+// String lays the details out one per line.
+func (d gameDetails) String() string {
+	return fmt.Sprintf("%s\n%s %d bytes\n%s %s\n%s %d\n%s %s",
+		tview.Escape(d.Path),
+		tag("dimgray", "size    "), d.Size,
+		tag("dimgray", "modified"), d.Modified.Format("2006-01-02 15:04:05"),
+		tag("dimgray", "moves   "), d.Moves,
+		tag("dimgray", "sha1    "), d.SHA1)
+}
+
+// verifySummary lists what sgf.Verify found in the file at path.
+func verifySummary(path string) string {
+	r, err := sgf.Verify(path)
+	if err != nil {
+		return tag("red", err.Error())
+	}
+	if r.OK() && len(r.Warnings) == 0 {
+		return tag("green", "no problems found")
+	}
+	var lines []string
+	for _, p := range r.Problems {
+		lines = append(lines, tag("red", "• "+tview.Escape(p)))
+	}
+	for _, w := range r.Warnings {
+		lines = append(lines, tag("yellow", "• "+tview.Escape(w)))
+	}
+	if r.Repairable {
+		lines = append(lines, tag("dimgray", "truncated; v in the history list can repair it"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exportGame copies the file at path into dir under the same name,
+// refusing to overwrite. It returns the path written.
+func exportGame(path, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	out := filepath.Join(dir, filepath.Base(path))
+	dst, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("%s already exists", out)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(out)
+		return "", err
+	}
+	return out, dst.Close()
+}
+
+// osc52 is the escape sequence asking the terminal to put text on the
+// system clipboard.
+func osc52(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
+
+// CopyToClipboard asks the terminal to put text on the clipboard with
+// OSC 52. A terminal without support ignores it, and there is no way to
+// tell that it did.
+func CopyToClipboard(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		_, err = os.Stdout.WriteString(osc52(text))
+		return err
+	}
+	defer tty.Close()
+	_, err = tty.WriteString(osc52(text))
+	return err
+}
+
+// openInViewer opens path with the desktop's handler for SGF files.
+func openInViewer(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// showDetails opens the popup for the selected game.
+func (hb *HistoryBrowserUI) showDetails() {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return
+	}
+	game := hb.games[hb.selected]
+
+	info := tview.NewTextView()
+	info.SetDynamicColors(true)
+	info.SetWordWrap(true)
+	if d, err := readDetails(game); err != nil {
+		info.SetText(tag("red", err.Error()))
+	} else {
+		info.SetText(d.String())
+	}
+
+	result := tview.NewTextView()
+	result.SetDynamicColors(true)
+	result.SetWordWrap(true)
+
+	actions := tview.NewList()
+	actions.ShowSecondaryText(false)
+	actions.SetHighlightFullLine(true)
+	actions.SetMainTextStyle(tcell.StyleDefault.Foreground(MenuColors.Label))
+	actions.SetSelectedStyle(tcell.StyleDefault.Foreground(MenuColors.ButtonText).Background(MenuColors.ButtonFocus))
+	actions.AddItem("Copy path to clipboard", "", 'c', func() {
+		if err := CopyToClipboard(game.FilePath); err != nil {
+			result.SetText(tag("red", err.Error()))
+			return
+		}
+		result.SetText("path sent to the clipboard")
+	})
+	actions.AddItem("Open in external viewer", "", 'o', func() {
+		if err := openInViewer(game.FilePath); err != nil {
+			result.SetText(tag("red", "can't open a viewer: "+err.Error()))
+			return
+		}
+		result.SetText("opened")
+	})
+	actions.AddItem("Export to "+config.ExportDir(), "", 'e', func() {
+		out, err := exportGame(game.FilePath, config.ExportDir())
+		if err != nil {
+			result.SetText(tag("red", tview.Escape(err.Error())))
+			return
+		}
+		result.SetText("exported to " + tview.Escape(out))
+	})
+	actions.AddItem("Verify", "", 'v', func() {
+		result.SetText(verifySummary(game.FilePath))
+	})
+	actions.AddItem("Close", "", 'q', hb.closeDetails)
+	actions.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			hb.closeDetails()
+			return nil
+		}
+		return event
+	})
+
+	popup := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(info, 5, 0, false).
+		AddItem(actions, 5, 0, true).
+		AddItem(result, 0, 1, false)
+	popup.SetBorder(true)
+	popup.SetTitle(" " + game.FileName + " ")
+	popup.SetBorderPadding(0, 0, 1, 1)
+
+	hb.body.AddPage("details", centered(popup, 72, 18), true, true)
+}
+
+// closeDetails closes the popup, back to the list.
+func (hb *HistoryBrowserUI) closeDetails() {
+	hb.body.RemovePage("details")
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"termsuji-local/sgf"
+)
+
+func TestReadDetails(t *testing.T) {
+	dir := t.TempDir()
+	content := "(;GM[1]SZ[9];B[ee];W[cc])"
+	path := filepath.Join(dir, "game.sgf")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := readDetails(sgf.GameInfo{FilePath: path, MoveCount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Size != int64(len(content)) {
+		t.Errorf("Size = %d, want %d", d.Size, len(content))
+	}
+	if want := fmt.Sprintf("%x", sha1.Sum([]byte(content))); d.SHA1 != want {
+		t.Errorf("SHA1 = %s, want %s", d.SHA1, want)
+	}
+	if d.Moves != 2 || d.Modified.IsZero() {
+		t.Errorf("Moves = %d, Modified = %v", d.Moves, d.Modified)
+	}
+
+	if _, err := readDetails(sgf.GameInfo{FilePath: filepath.Join(dir, "gone.sgf")}); err == nil {
+		t.Error("readDetails of a missing file: want an error")
+	}
+}
+
+func TestExportGame(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "game.sgf")
+	if err := os.WriteFile(src, []byte("(;GM[1]SZ[9])"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "Downloads")
+
+	out, err := exportGame(src, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "(;GM[1]SZ[9])" {
+		t.Errorf("exported %q, %v", data, err)
+	}
+	if _, err := exportGame(src, dir); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second export: err = %v, want already exists", err)
+	}
+}
+
+func TestOSC52(t *testing.T) {
+	if got, want := osc52("/tmp/a.sgf"), "\x1b]52;c;L3RtcC9hLnNnZg==\a"; got != want {
+		t.Errorf("osc52 = %q, want %q", got, want)
+	}
+}
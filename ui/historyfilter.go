@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/sgf"
+)
+
+// filterSizes are the board sizes the history's 1, 2 and 3 keys filter to.
+var filterSizes = [3]int{9, 13, 19}
+
+// historyFilter narrows the history browser's list. The zero value lets
+// every game through.
+type historyFilter struct {
+	query string // typed after '/'; each word must be in the date, size, result or name
+	size  int    // board size from the 1/2/3 toggles, 0 for any
+}
+
+// empty reports whether f lets every game through.
+func (f historyFilter) empty() bool {
+	return strings.TrimSpace(f.query) == "" && f.size == 0
+}
+
+// String describes f for the list's title, e.g. "/B+ 9x9".
+func (f historyFilter) String() string {
+	var parts []string
+	if q := strings.TrimSpace(f.query); q != "" {
+		parts = append(parts, "/"+q)
+	}
+	if f.size != 0 {
+		parts = append(parts, fmt.Sprintf("%dx%d", f.size, f.size))
+	}
+	return strings.Join(parts, " ")
+}
+
+// toggleSize filters to size, or back to any size if it already does.
+func (f *historyFilter) toggleSize(size int) {
+	if f.size == size {
+		f.size = 0
+	} else {
+		f.size = size
+	}
+}
+
+// matches reports whether game passes f. Words in the query match case
+// insensitively, so "b+" finds Black's wins as "B+" does, and "draw" finds
+// the drawn games however they were recorded.
+func (f historyFilter) matches(game sgf.GameInfo) bool {
+	if f.size != 0 && game.BoardSize != f.size {
+		return false
+	}
+	fields := strings.ToLower(fmt.Sprintf("%s %dx%d %s %s %s", game.Date, game.BoardSize, game.BoardSize, game.Result, sgf.ShortResult(game.Result), game.GameName))
+	for _, word := range strings.Fields(strings.ToLower(f.query)) {
+		if !strings.Contains(fields, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns the games that pass f, in their order, in a slice of
+// their own, so sorting games leaves it as listed.
+func (f historyFilter) apply(games []sgf.GameInfo) []sgf.GameInfo {
+	shown := make([]sgf.GameInfo, 0, len(games))
+	for _, g := range games {
+		if f.matches(g) {
+			shown = append(shown, g)
+		}
+	}
+	return shown
+}
+
+// setFilter lists the games f lets through.
+func (hb *HistoryBrowserUI) setFilter(f historyFilter) {
+	hb.filter = f
+	hb.showGames()
+	hb.setHint("")
+}
+
+// handleFilterKey edits the filter while it is typed, narrowing the list
+// at each key. Enter keeps the filter, Esc clears it, and the arrow keys
+// still move through the list.
+func (hb *HistoryBrowserUI) handleFilterKey(event *tcell.EventKey) *tcell.EventKey {
+	f := hb.filter
+	switch event.Key() {
+	case tcell.KeyEnter:
+		hb.typing = false
+		hb.setHint("")
+		return nil
+	case tcell.KeyEscape:
+		hb.typing = false
+		f.query = ""
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if q := []rune(f.query); len(q) > 0 {
+			f.query = string(q[:len(q)-1])
+		}
+	case tcell.KeyCtrlU:
+		f.query = ""
+	case tcell.KeyRune:
+		f.query += string(event.Rune())
+	case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyHome, tcell.KeyEnd:
+		return event
+	default:
+		return nil
+	}
+	hb.setFilter(f)
+	return nil
+}
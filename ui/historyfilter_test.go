@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/sgf"
+)
+
+func TestHistoryFilterMatches(t *testing.T) {
+	game := sgf.GameInfo{Date: "2026-01-15", BoardSize: 9, Result: "B+R"}
+	for _, tt := range []struct {
+		filter historyFilter
+		want   bool
+	}{
+		{historyFilter{}, true},
+		{historyFilter{query: "2026-01"}, true},
+		{historyFilter{query: "9x9"}, true},
+		{historyFilter{query: "b+"}, true},
+		{historyFilter{query: " 01-15  B+R "}, true},
+		{historyFilter{query: "W+"}, false},
+		{historyFilter{query: "19x19"}, false},
+		{historyFilter{size: 9}, true},
+		{historyFilter{size: 13}, false},
+		{historyFilter{query: "B+", size: 19}, false},
+	} {
+		if got := tt.filter.matches(game); got != tt.want {
+			t.Errorf("%+v matches = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	// A draw is found as one however it is written
+	for _, re := range []string{"0", "Jigo", "Draw"} {
+		if !(historyFilter{query: "draw"}).matches(sgf.GameInfo{Result: re}) {
+			t.Errorf("draw doesn't find RE[%s]", re)
+		}
+	}
+}
+
+// writeHistory writes games of the given sizes and results to a new
+// history directory, a day apart, newest last.
+func writeHistory(t *testing.T, sizes []int, results []string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for i, size := range sizes {
+		date := fmt.Sprintf("2026-01-%02d", i+1)
+		name := fmt.Sprintf("%s_120000_%dx%d.sgf", date, size, size)
+		content := fmt.Sprintf("(;GM[1]SZ[%d]DT[%s]PB[Player]PW[GnuGo Level 5]RE[%s];B[aa])", size, date, results[i])
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestHistoryBrowserFilter(t *testing.T) {
+	dir := writeHistory(t, []int{9, 13, 9, 19, 9}, []string{"B+R", "W+3.5", "W+R", "B+T", "B+0.5"})
+	hb := NewHistoryBrowser(NewGameList(dir), nil, nil, nil, nil, nil)
+	hb.Refresh()
+	key := func(r rune) { hb.handleInput(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)) }
+	dates := func() []string {
+		var d []string
+		for _, g := range hb.games {
+			d = append(d, g.Date)
+		}
+		return d
+	}
+	if len(hb.games) != 5 {
+		t.Fatalf("%d games listed, want 5", len(hb.games))
+	}
+
+	// Select the 9x9 game of Jan 3 and filter to 9x9: it stays selected
+	hb.gameList.SetCurrentItem(2)
+	key('1')
+	if got := fmt.Sprint(dates()); got != "[2026-01-05 2026-01-03 2026-01-01]" {
+		t.Fatalf("9x9 games = %s", got)
+	}
+	if hb.games[hb.selected].Date != "2026-01-03" || hb.gameList.GetCurrentItem() != hb.selected {
+		t.Errorf("selected %d (list at %d), want the Jan 3 game", hb.selected, hb.gameList.GetCurrentItem())
+	}
+
+	// Type a result: Black's 9x9 wins
+	key('/')
+	for _, r := range "b+" {
+		key(r)
+	}
+	hb.handleInput(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if got := fmt.Sprint(dates()); got != "[2026-01-05 2026-01-01]" {
+		t.Fatalf("9x9 B+ games = %s", got)
+	}
+	if hb.gameList.GetItemCount() != 2 || hb.typing {
+		t.Errorf("%d items, typing %v", hb.gameList.GetItemCount(), hb.typing)
+	}
+
+	// The preview cache and delete go by the game shown, not its index
+	hb.gameList.SetCurrentItem(1)
+	first := hb.games[1]
+	if hb.finalBoard(first) == nil {
+		t.Fatal("no board for the Jan 1 game")
+	}
+	hb.deleteSelected()
+	if _, err := os.Stat(first.FilePath); !os.IsNotExist(err) {
+		t.Errorf("Jan 1 game not deleted: %v", err)
+	}
+	if _, ok := hb.boards[first.FilePath]; ok {
+		t.Error("deleted game still cached")
+	}
+	if got := fmt.Sprint(dates()); got != "[2026-01-05]" || hb.selected != 0 {
+		t.Errorf("after delete: %s, selected %d", got, hb.selected)
+	}
+
+	// Clearing comes back to every game from what was read, not the disk
+	os.Remove(hb.all[len(hb.all)-1].FilePath)
+	hb.handleInput(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if !hb.filter.empty() || len(hb.games) != 4 {
+		t.Errorf("after Esc: filter %q, %d games, want all 4 read", hb.filter, len(hb.games))
+	}
+	if hb.games[hb.selected].Date != "2026-01-05" {
+		t.Errorf("selected %s after clearing, want the Jan 5 game", hb.games[hb.selected].Date)
+	}
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/sgf"
+)
+
+// maxGameNameLen caps a game name typed in the history, in runes.
+const maxGameNameLen = 60
+
+// renameSelected opens a field over the list to name the selected game.
+// Enter saves the name, Esc leaves it as it was.
+func (hb *HistoryBrowserUI) renameSelected() {
+	if hb.selected < 0 || hb.selected >= len(hb.games) {
+		return
+	}
+	game := hb.games[hb.selected]
+	if !hb.readable(game) || !hb.wholeFile(game) {
+		return
+	}
+
+	field := tview.NewInputField()
+	field.SetText(game.GameName)
+	field.SetPlaceholder("a name for the game; empty for none")
+	field.SetAcceptanceFunc(tview.InputFieldMaxLength(maxGameNameLen))
+	field.SetFieldBackgroundColor(MenuColors.InputBG)
+	field.SetFieldTextColor(MenuColors.Label)
+	field.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			hb.body.RemovePage("rename")
+			hb.setGameName(game, field.GetText())
+		case tcell.KeyEscape:
+			hb.body.RemovePage("rename")
+		}
+	})
+	field.SetBorder(true)
+	field.SetTitle(" Name " + game.FileName + " ")
+	hb.body.AddPage("rename", centered(field, 64, 3), true, true)
+}
+
+// setGameName writes name into game's file and shows it in the list.
+func (hb *HistoryBrowserUI) setGameName(game sgf.GameInfo, name string) {
+	name = strings.TrimSpace(name)
+	if name == game.GameName {
+		return
+	}
+	if err := sgf.SetGameName(game.FilePath, name); err != nil {
+		hb.setHint(tag("red", "can't rename: "+tview.Escape(err.Error())))
+		return
+	}
+	hb.list.Invalidate()
+	for i := range hb.all {
+		if hb.all[i].FilePath == game.FilePath {
+			hb.all[i].GameName = name
+		}
+	}
+	hb.showGames()
+	if name == "" {
+		hb.setHint(tag("yellow", "name removed"))
+	} else {
+		hb.setHint(tag("yellow", "named "+tview.Escape(name)))
+	}
+}
+
+// centered places p, width by height, in the middle of the page.
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+}
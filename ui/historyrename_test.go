@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/sgf"
+)
+
+func TestHistoryBrowserRename(t *testing.T) {
+	dir := writeHistory(t, []int{9, 13}, []string{"B+R", "W+3.5"})
+	hb := NewHistoryBrowser(NewGameList(dir), nil, nil, nil, nil, nil)
+	hb.Refresh()
+	hb.gameList.SetCurrentItem(1)
+	game := hb.games[1]
+
+	hb.handleInput(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone))
+	name, front := hb.body.GetFrontPage()
+	if name != "rename" {
+		t.Fatalf("front page %q after r, want the name field", name)
+	}
+	field := front.(*tview.Flex).GetItem(1).(*tview.Flex).GetItem(1).(*tview.InputField)
+	field.SetText("  ladder study ")
+	field.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+
+	if hb.body.HasPage("rename") {
+		t.Error("name field still open after Enter")
+	}
+	if info, err := sgf.ParseHeader(game.FilePath); err != nil || info.GameName != "ladder study" {
+		t.Errorf("file named %+v, %v", info, err)
+	}
+	if hb.games[hb.selected].FilePath != game.FilePath {
+		t.Errorf("selection moved to %s", hb.games[hb.selected].FileName)
+	}
+	if main, _ := hb.gameList.GetItemText(hb.selected); !strings.Contains(main, "ladder study") {
+		t.Errorf("list shows %q, without the name", main)
+	}
+
+	// The filter finds games by name
+	hb.setFilter(historyFilter{query: "ladder"})
+	if len(hb.games) != 1 || hb.games[0].FilePath != game.FilePath {
+		t.Errorf("filter by name lists %d games", len(hb.games))
+	}
+}
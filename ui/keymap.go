@@ -0,0 +1,251 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Binding is a key, or keys, and what pressing it does.
+type Binding struct {
+	Keys   string
+	Action string
+}
+
+// keyNames are the keys the sheet names, rather than shows as typed.
+var keyNames = map[string]tcell.Key{
+	"↑": tcell.KeyUp, "↓": tcell.KeyDown, "←": tcell.KeyLeft, "→": tcell.KeyRight,
+	"Enter": tcell.KeyEnter, "Esc": tcell.KeyEscape, "Tab": tcell.KeyTab, "shift-Tab": tcell.KeyBacktab,
+	"PgUp": tcell.KeyPgUp, "PgDn": tcell.KeyPgDn, "Home": tcell.KeyHome, "End": tcell.KeyEnd,
+	"ctrl-z": tcell.KeyCtrlZ,
+}
+
+// Events returns the key events b's keys stand for: names like "Enter"
+// and "space", single characters, and ranges like "1-9". It returns nil
+// if one of the keys is none of those.
+func (b Binding) Events() []*tcell.EventKey {
+	var events []*tcell.EventKey
+	for _, k := range strings.Fields(b.Keys) {
+		r := []rune(k)
+		switch key, named := keyNames[k]; {
+		case named:
+			events = append(events, tcell.NewEventKey(key, 0, tcell.ModNone))
+		case k == "space":
+			events = append(events, tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone))
+		case len(r) == 1:
+			events = append(events, tcell.NewEventKey(tcell.KeyRune, r[0], tcell.ModNone))
+		case len(r) == 3 && r[1] == '-' && r[0] < r[2]:
+			for c := r[0]; c <= r[2]; c++ {
+				events = append(events, tcell.NewEventKey(tcell.KeyRune, c, tcell.ModNone))
+			}
+		default:
+			return nil
+		}
+	}
+	return events
+}
+
+// KeyGroup is the bindings that apply in one place, e.g. "Playing".
+type KeyGroup struct {
+	Context  string
+	Bindings []Binding
+}
+
+// Keymap returns every key binding, grouped by where it applies, in the
+// order the screens come up.
+func Keymap() []KeyGroup {
+	return []KeyGroup{
+		{"Setup", []Binding{
+			{"↑ ↓", "choose an option"},
+			{"← →", "change it"},
+			{"Tab", "next option"},
+			{"shift-Tab", "previous option"},
+			{"PgUp PgDn", "switch between the Quick and Advanced tabs"},
+			{"p", "play"},
+			{"l", "review the last game"},
+			{"o", "open an SGF file"},
+			{"a", "about"},
+			{"?", "this key reference"},
+			{"Esc", "quit"},
+		}},
+		{"Playing", []Binding{
+			{"h j k l", "move the cursor"},
+			{"← ↓ ↑ →", "move the cursor too"},
+			{"1-9", "repeat the next cursor move, as in vi's 5l"},
+			{"Enter", "play at the cursor, or pass when asked; press twice with confirm_moves on"},
+			{"p", "pass"},
+			{"u", "undo back to your last move"},
+			{"R", "resign, after confirming"},
+			{"r", "start or stop recording"},
+			{";", "comment on the last move"},
+			{"y Y", "copy the move list, as text or as SGF moves"},
+			{"t", "show the engine's candidate moves; territory after a counted game"},
+			{"e", "show where the engine sees the borders"},
+			{"a", "plan variations"},
+			{"f", "focus mode"},
+			{"n", "rematch, once the game is over"},
+			{"v", "the game's report, once the game is over with write_reports on"},
+			{"q", "drop a move awaiting confirmation, clear the cursor, or quit the game"},
+			{"?", "this key reference"},
+			{"ctrl-z", "suspend to the shell"},
+		}},
+		{"Planning", []Binding{
+			{"Enter", "play at the cursor"},
+			{"p", "pass"},
+			{"[ ]", "step back and forward"},
+			{"{ }", "previous and next variation"},
+			{"a", "leave the plan"},
+			{"A", "play on from the plan's position"},
+		}},
+		{"Counting", []Binding{
+			{"Enter", "mark a group dead or alive"},
+			{"u U", "undo and redo marks"},
+			{"c", "confirm the score"},
+			{"Esc", "resume play"},
+		}},
+		{"Reviewing", []Binding{
+			{"h l ← →", "step back and forward"},
+			{"Home End", "first and last move"},
+			{"s", "count the position as it stands, without the engine"},
+			{"g", "guess the next moves, scored against the game"},
+			{"c", "continue the game from here"},
+			{"b", "play on from this move in a new game"},
+			{"y Y", "copy the move list, as text or as SGF moves"},
+			{"q", "back"},
+			{"?", "this key reference"},
+		}},
+		{"Guessing", []Binding{
+			{"h j k l", "move the cursor"},
+			{"← ↓ ↑ →", "move the cursor too"},
+			{"Enter", "guess the move at the cursor, or go on once it's shown"},
+			{"p", "guess a pass"},
+			{"g Esc", "stop guessing"},
+			{"q", "back"},
+			{"?", "this key reference"},
+		}},
+		{"History", []Binding{
+			{"Enter", "review"},
+			{"→ ←", "list the games of a collection file, or fold them"},
+			{"c", "continue"},
+			{"m", "rematch"},
+			{"d", "delete, after confirming; the game goes to the trash"},
+			{"U", "undo the last delete"},
+			{"a", "archive the game; in the archive, move it back"},
+			{"v", "switch between the games and the archive"},
+			{"r", "name the game"},
+			{"space", "mark for comparison"},
+			{"=", "compare with the marked game"},
+			{"/", "filter by date, size, result or name"},
+			{"1 2 3", "only 9x9, 13x13 or 19x19 games"},
+			{"s", "sort by date, size, moves or result"},
+			{"V", "verify the history"},
+			{"i", "details"},
+			{"q Esc", "back; Esc clears a filter first"},
+		}},
+		{"Key reference", []Binding{
+			{"↑ ↓ j k", "scroll"},
+			{"PgUp PgDn", "scroll by the page"},
+			{"Home End", "top and bottom"},
+			{"r", "the rules of go, with diagrams"},
+			{"K", "save this sheet to the downloads folder, for printing"},
+			{"? q Esc", "close"},
+		}},
+		{"Rules", []Binding{
+			{"j k", "next and previous section"},
+			{"↑ ↓", "scroll"},
+			{"PgUp PgDn", "scroll by the page"},
+			{"Home End", "top and bottom"},
+			{"q Esc", "back to the key reference"},
+		}},
+	}
+}
+
+// minKeysWidth is the narrowest sheet FormatKeys lays out; a smaller width
+// is taken as this.
+const minKeysWidth = 24
+
+// FormatKeys lays groups out as a plain-text cheat sheet no wider than
+// width: each context as a heading, then its keys in a column with what
+// they do wrapped beside them. Keys too long for the column get a line of
+// their own, their action starting under it.
+func FormatKeys(groups []KeyGroup, width int) string {
+	if width < minKeysWidth {
+		width = minKeysWidth
+	}
+	const indent, gap = 2, 2
+
+	// The key column fits the longest keys, up to a third of the width
+	keysWidth := 0
+	for _, g := range groups {
+		for _, b := range g.Bindings {
+			if n := runeLen(b.Keys); n > keysWidth {
+				keysWidth = n
+			}
+		}
+	}
+	if keysWidth > width/3 {
+		keysWidth = width / 3
+	}
+	actionCol := indent + keysWidth + gap
+	pad := strings.Repeat(" ", actionCol)
+
+	var b strings.Builder
+	for i, g := range groups {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(g.Context + "\n")
+		b.WriteString(strings.Repeat("─", runeLen(g.Context)) + "\n")
+		for _, bind := range g.Bindings {
+			lines := wrapWords(bind.Action, width-actionCol)
+			keys := strings.Repeat(" ", indent) + bind.Keys
+			if runeLen(bind.Keys) > keysWidth {
+				b.WriteString(keys + "\n")
+			} else {
+				b.WriteString(keys + strings.Repeat(" ", actionCol-runeLen(keys)) + lines[0] + "\n")
+				lines = lines[1:]
+			}
+			for _, line := range lines {
+				b.WriteString(pad + line + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// wrapWords breaks text into lines of at most width runes, between words
+// where it can; a word longer than width is split.
+func wrapWords(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for runeLen(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			r := []rune(word)
+			lines = append(lines, string(r[:width]))
+			word = string(r[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case runeLen(line)+1+runeLen(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// runeLen is the length of s in runes, which is its width for the key
+// names and text on the sheet.
+func runeLen(s string) int {
+	return len([]rune(s))
+}
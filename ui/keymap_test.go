@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites it with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs; got:\n%s", name, got)
+	}
+}
+
+func TestFormatKeysDefault(t *testing.T) {
+	checkGolden(t, "keys_default.golden", FormatKeys(Keymap(), 80))
+}
+
+// Key names of any length, and actions too long for a narrow sheet.
+func TestFormatKeysCustom(t *testing.T) {
+	groups := []KeyGroup{
+		{"Playing", []Binding{
+			{"x", "play at the cursor"},
+			{"ctrl-alt-shift-F12", "resign, after confirming"},
+			{"→", "move the cursor right, repeated by a count typed first"},
+			{"g", "supercalifragilisticexpialidocious"},
+		}},
+		{"Counting", []Binding{{"space", "mark"}}},
+	}
+	got := FormatKeys(groups, 30)
+	for _, line := range strings.Split(got, "\n") {
+		if runeLen(line) > 30 {
+			t.Errorf("line wider than 30: %q", line)
+		}
+	}
+	checkGolden(t, "keys_custom.golden", got)
+}
+
+// everyKey is a press of each printable character and of the keys a
+// terminal sends beside them.
+func everyKey() []*tcell.EventKey {
+	var events []*tcell.EventKey
+	for r := ' '; r <= '~'; r++ {
+		events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	for k := tcell.KeyCtrlA; k <= tcell.KeyCtrlZ; k++ {
+		events = append(events, tcell.NewEventKey(k, 0, tcell.ModNone))
+	}
+	for _, k := range []tcell.Key{
+		tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight, tcell.KeyEscape, tcell.KeyBacktab,
+		tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyHome, tcell.KeyEnd, tcell.KeyInsert, tcell.KeyDelete,
+		tcell.KeyBackspace2, tcell.KeyF1, tcell.KeyF10,
+	} {
+		events = append(events, tcell.NewEventKey(k, 0, tcell.ModNone))
+	}
+	return events
+}
+
+// listedKeys returns the names of the keys bound in the groups of
+// Keymap named by contexts.
+func listedKeys(t *testing.T, contexts ...string) map[string]bool {
+	t.Helper()
+	listed := map[string]bool{}
+	for _, g := range Keymap() {
+		for _, c := range contexts {
+			if g.Context != c {
+				continue
+			}
+			for _, b := range g.Bindings {
+				events := b.Events()
+				if events == nil {
+					t.Errorf("%s: can't read the keys %q", c, b.Keys)
+				}
+				for _, ev := range events {
+					listed[ev.Name()] = true
+				}
+			}
+		}
+	}
+	if len(listed) == 0 {
+		t.Fatalf("no keys listed for %v", contexts)
+	}
+	return listed
+}
+
+// checkKeymap presses every key on a handler fresh from newHandler, and
+// fails for a key it takes that the key reference doesn't list under
+// context. Listed keys may do nothing at the time, e.g. folding a game
+// that isn't a collection.
+func checkKeymap(t *testing.T, context string, newHandler func() func(*tcell.EventKey) *tcell.EventKey) {
+	t.Helper()
+	listed := listedKeys(t, context)
+	for _, ev := range everyKey() {
+		if newHandler()(ev) == nil && !listed[ev.Name()] {
+			t.Errorf("%s: %s does something but isn't in the key reference", context, ev.Name())
+		}
+	}
+}
+
+func TestKeymapMatchesHandlers(t *testing.T) {
+	noop := func() {}
+	checkKeymap(t, "Setup", func() func(*tcell.EventKey) *tcell.EventKey {
+		s := NewGameSetup(func(engine.GameConfig) {}, noop, noop, noop)
+		s.SetAboutFunc(noop)
+		s.SetHelpFunc(noop)
+		s.SetLoadFunc(noop)
+		s.SetLastGame("9x9 B+R", sgf.OutcomeWin, noop)
+		// On an option between others, so that ↑ and ↓ both move
+		s.handleInput(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+		return s.handleInput
+	})
+
+	dir := writeHistory(t, []int{9, 13}, []string{"B+R", "W+R"})
+	checkKeymap(t, "History", func() func(*tcell.EventKey) *tcell.EventKey {
+		hb := NewHistoryBrowser(NewGameList(dir), noop, func(sgf.GameInfo) {}, func(sgf.GameInfo) {}, func(sgf.GameInfo) {}, noop)
+		hb.Refresh()
+		return hb.handleInput
+	})
+
+	checkKeymap(t, "Key reference", func() func(*tcell.EventKey) *tcell.EventKey {
+		h := NewHelp(noop)
+		h.SetRulesFunc(noop)
+		return h.handleInput
+	})
+
+	checkKeymap(t, "Rules", func() func(*tcell.EventKey) *tcell.EventKey {
+		return NewRules(noop).handleInput
+	})
+}
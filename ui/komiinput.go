@@ -87,22 +87,22 @@ func (k *KomiInput) Draw(screen tcell.Screen, x, y, width int) int {
 	bgStyle := tcell.StyleDefault.Background(MenuColors.CardBG)
 	labelStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
 	accentStyle := tcell.StyleDefault.Foreground(MenuColors.TitleAccent).Background(MenuColors.CardBG)
-	selectedStyle := tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG)
-	inputStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(tcell.PaletteColor(238))
-	cursorStyle := tcell.StyleDefault.Foreground(MenuColors.CardBG).Background(MenuColors.Selected)
+	selectedStyle := emphasis(tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG))
+	inputStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.InputBG)
+	cursorStyle := filled(tcell.StyleDefault.Foreground(MenuColors.CardBG).Background(MenuColors.Selected))
 
 	col := x
 
 	// Focus cursor
 	if k.focused {
-		screen.SetContent(col, y, '▸', nil, selectedStyle)
+		screen.SetContent(col, y, glyph('▸'), nil, selectedStyle)
 	} else {
 		screen.SetContent(col, y, ' ', nil, bgStyle)
 	}
 	col += 2
 
 	// Label with diamond prefix: ◈ Komi
-	screen.SetContent(col, y, '◈', nil, accentStyle)
+	screen.SetContent(col, y, glyph('◈'), nil, accentStyle)
 	col += 2
 
 	for _, ch := range k.label {
@@ -153,6 +153,11 @@ func (k *KomiInput) Value() float64 {
 	return k.value
 }
 
+// Nudge adjusts the komi value by delta points.
+func (k *KomiInput) Nudge(delta float64) {
+	k.SetValue(k.value + delta)
+}
+
 // SetValue sets the komi value.
 func (k *KomiInput) SetValue(v float64) {
 	k.value = v
@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+)
+
+// Under a thinking budget the engine's level is stepped down while its
+// moves run long, and back up once they're quick again. Each step is
+// announced in the hint bar and kept as a comment on the move it follows.
+
+// levelNote says why the engine went from one level to another.
+func levelNote(name string, from, to int, budget time.Duration) string {
+	if to < from {
+		return fmt.Sprintf("%s down to level %d: its moves were taking over %s", name, to, budget)
+	}
+	return fmt.Sprintf("%s back up to level %d: its moves are well within %s", name, to, budget)
+}
+
+// levelChanged notes the engine now playing at level and returns what to
+// tell the player.
+func (g *GoBoardUI) levelChanged(level int) string {
+	note := levelNote(g.engineName(), g.level, level, g.gameConfig.MoveBudget)
+	g.level = level
+	g.refreshHeader()
+	return note
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"termsuji-local/engine"
+	"termsuji-local/types"
+)
+
+func TestLevelChangeNoted(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, EngineLevel: 8, MoveBudget: 5 * time.Second}, 0)
+
+	bs := types.NewBoardState(9)
+	bs.LevelChange = 7
+	eng.onMove(2, 2, 2, bs)
+	moves := g.MovesSnapshot()
+	want := "GnuGo down to level 7: its moves were taking over 5s"
+	if got := moves[len(moves)-1].Comment; got != want {
+		t.Errorf("comment %q, want %q", got, want)
+	}
+	if g.notice != want || g.level != 7 {
+		t.Errorf("notice %q at level %d", g.notice, g.level)
+	}
+
+	// The next move without a change says nothing more.
+	eng.onMove(3, 3, 1, types.NewBoardState(9))
+	bs = types.NewBoardState(9)
+	eng.onMove(4, 4, 2, bs)
+	if moves := g.MovesSnapshot(); moves[len(moves)-1].Comment != "" || g.notice != "" {
+		t.Errorf("unchanged level noted: %q", g.notice)
+	}
+
+	bs = types.NewBoardState(9)
+	bs.LevelChange = 8
+	eng.onMove(5, 5, 2, bs)
+	if !strings.HasPrefix(g.notice, "GnuGo back up to level 8") {
+		t.Errorf("notice %q after stepping back up", g.notice)
+	}
+}
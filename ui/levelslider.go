@@ -61,21 +61,21 @@ func (s *LevelSlider) Draw(screen tcell.Screen, x, y, width int) int {
 	bgStyle := tcell.StyleDefault.Background(MenuColors.CardBG)
 	labelStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
 	accentStyle := tcell.StyleDefault.Foreground(MenuColors.TitleAccent).Background(MenuColors.CardBG)
-	selectedStyle := tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG)
+	selectedStyle := emphasis(tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG))
 	unselectedStyle := tcell.StyleDefault.Foreground(MenuColors.Unselected).Background(MenuColors.CardBG)
 
 	col := x
 
 	// Focus cursor
 	if s.focused {
-		screen.SetContent(col, y, '▸', nil, selectedStyle)
+		screen.SetContent(col, y, glyph('▸'), nil, selectedStyle)
 	} else {
 		screen.SetContent(col, y, ' ', nil, bgStyle)
 	}
 	col += 2
 
 	// Label with diamond prefix: ◈ Strength
-	screen.SetContent(col, y, '◈', nil, accentStyle)
+	screen.SetContent(col, y, glyph('◈'), nil, accentStyle)
 	col += 2
 
 	for _, ch := range s.label {
@@ -89,7 +89,7 @@ func (s *LevelSlider) Draw(screen tcell.Screen, x, y, width int) int {
 	if s.focused {
 		arrowStyle = selectedStyle
 	}
-	screen.SetContent(col, y, '◀', nil, arrowStyle)
+	screen.SetContent(col, y, glyph('◀'), nil, arrowStyle)
 	col += 2
 
 	// Progress bar
@@ -103,7 +103,7 @@ func (s *LevelSlider) Draw(screen tcell.Screen, x, y, width int) int {
 			char = '█'
 			style = selectedStyle
 		}
-		screen.SetContent(col, y, char, nil, style)
+		screen.SetContent(col, y, glyph(char), nil, style)
 		col++
 	}
 	col++
@@ -117,7 +117,7 @@ func (s *LevelSlider) Draw(screen tcell.Screen, x, y, width int) int {
 	col++
 
 	// Right arrow
-	screen.SetContent(col, y, '▶', nil, arrowStyle)
+	screen.SetContent(col, y, glyph('▶'), nil, arrowStyle)
 
 	return 1
 }
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// LoadSGFUI asks for the path of an SGF from anywhere on disk, such as a
+// game downloaded from a go server, and whether to review it or play on
+// from its last move.
+type LoadSGFUI struct {
+	box      *tview.Box
+	flex     *tview.Flex
+	card     *MenuCard
+	onOpen   func(path string, cont, copyToHistory bool)
+	onCancel func()
+
+	// Components
+	pathInput      *PathInput
+	copySelect     *ValueSelect
+	reviewButton   *MenuButton
+	continueButton *MenuButton
+	backButton     *MenuButton
+
+	// Focus management
+	focusIndex int
+	focusables []focusableComponent
+}
+
+// NewLoadSGF creates the load card. onOpen receives the path, true to
+// continue the game rather than review it, and whether to copy the file
+// into the history first.
+func NewLoadSGF(onOpen func(path string, cont, copyToHistory bool), onCancel func()) *LoadSGFUI {
+	l := &LoadSGFUI{
+		onOpen:   onOpen,
+		onCancel: onCancel,
+		card:     NewMenuCard("L O A D"),
+	}
+
+	l.pathInput = NewPathInput("SGF file")
+	l.copySelect = NewValueSelect("Copy to history", []int{0, 1}, 0, func(v int) string {
+		if v == 1 {
+			return "yes"
+		}
+		return "no"
+	}, nil)
+	l.reviewButton = NewMenuButton("REVIEW", true, func() { l.open(false) })
+	l.continueButton = NewMenuButton("CONTINUE", false, func() { l.open(true) })
+	l.backButton = NewMenuButton("BACK", false, func() { l.onCancel() })
+
+	l.focusables = []focusableComponent{
+		l.pathInput,
+		l.copySelect,
+		l.reviewButton,
+		l.continueButton,
+		l.backButton,
+	}
+	l.pathInput.SetFocused(true)
+
+	l.box = tview.NewBox()
+	l.box.SetDrawFunc(l.draw)
+	l.box.SetInputCapture(l.handleInput)
+
+	helpText := tview.NewTextView().
+		SetText("Tab next · Enter review · Esc back").
+		SetTextAlign(tview.AlignCenter)
+	helpText.SetTextColor(MenuColors.Hint)
+	helpText.SetBackgroundColor(tcell.ColorDefault)
+
+	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(l.box, 15, 0, true).
+		AddItem(nil, 0, 1, false).
+		AddItem(helpText, 1, 0, false)
+
+	l.flex = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(innerFlex, 60, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	return l
+}
+
+// Flex returns the flex container for this UI.
+func (l *LoadSGFUI) Flex() *tview.Flex {
+	return l.flex
+}
+
+// Reset moves focus back to the path, keeping what was typed so a
+// mistyped path can be corrected.
+func (l *LoadSGFUI) Reset() {
+	for _, f := range l.focusables {
+		f.SetFocused(false)
+	}
+	l.focusIndex = 0
+	l.pathInput.SetFocused(true)
+}
+
+// open hands the path on, unless none was given.
+func (l *LoadSGFUI) open(cont bool) {
+	path := l.pathInput.Path()
+	if path == "" {
+		return
+	}
+	l.onOpen(path, cont, l.copySelect.Value() == 1)
+}
+
+// draw renders the card, the path field, the copy choice and buttons.
+func (l *LoadSGFUI) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	l.card.SetRect(x, y, width, height)
+	l.card.Draw(screen)
+
+	l.pathInput.Draw(screen, x+4, y+5, width-8)
+	l.copySelect.Draw(screen, x+4, y+8, width-8)
+
+	reviewW := l.reviewButton.Width()
+	continueW := l.continueButton.Width()
+	backW := l.backButton.Width()
+	spacing := 2
+	buttonX := x + (width-reviewW-continueW-backW-spacing*2)/2
+	buttonX += l.reviewButton.Draw(screen, buttonX, y+11)
+	buttonX += spacing
+	buttonX += l.continueButton.Draw(screen, buttonX, y+11)
+	buttonX += spacing
+	l.backButton.Draw(screen, buttonX, y+11)
+
+	return x, y, width, height
+}
+
+// handleInput processes keyboard input for focus management. Typing goes
+// to the path, so the card has no letter hotkeys.
+func (l *LoadSGFUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		l.onCancel()
+		return nil
+	case tcell.KeyTab, tcell.KeyDown:
+		l.cycleFocus(1)
+		return nil
+	case tcell.KeyBacktab, tcell.KeyUp:
+		l.cycleFocus(-1)
+		return nil
+	case tcell.KeyEnter:
+		if l.focusIndex < 2 {
+			l.open(false)
+			return nil
+		}
+	}
+	if l.focusables[l.focusIndex].HandleKey(event) {
+		return nil
+	}
+	switch event.Key() {
+	case tcell.KeyRight:
+		l.cycleFocus(1)
+		return nil
+	case tcell.KeyLeft:
+		l.cycleFocus(-1)
+		return nil
+	}
+	return event
+}
+
+// cycleFocus moves focus to the next/previous component.
+func (l *LoadSGFUI) cycleFocus(delta int) {
+	l.focusables[l.focusIndex].SetFocused(false)
+	l.focusIndex = (l.focusIndex + delta + len(l.focusables)) % len(l.focusables)
+	l.focusables[l.focusIndex].SetFocused(true)
+}
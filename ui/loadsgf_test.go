@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func typeKeys(handle func(*tcell.EventKey) *tcell.EventKey, text string) {
+	for _, r := range text {
+		handle(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+}
+
+func TestPathInput(t *testing.T) {
+	p := NewPathInput("SGF file")
+	key := func(k tcell.Key) { p.HandleKey(tcell.NewEventKey(k, 0, tcell.ModNone)) }
+	typeKeys(func(ev *tcell.EventKey) *tcell.EventKey { p.HandleKey(ev); return nil }, "~/gmes.sgf")
+	for i := 0; i < len("mes.sgf"); i++ {
+		key(tcell.KeyLeft)
+	}
+	p.HandleKey(tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone))
+	key(tcell.KeyEnd)
+	key(tcell.KeyBackspace2)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	if got, want := p.Path(), filepath.Join(home, "games.sg"); got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadSGFOpen(t *testing.T) {
+	var gotPath string
+	var gotCont, gotCopy bool
+	opened := 0
+	l := NewLoadSGF(func(path string, cont, copyToHistory bool) {
+		opened++
+		gotPath, gotCont, gotCopy = path, cont, copyToHistory
+	}, func() {})
+	key := func(k tcell.Key) { l.handleInput(tcell.NewEventKey(k, 0, tcell.ModNone)) }
+
+	// Nothing typed: nothing to open.
+	key(tcell.KeyEnter)
+	if opened != 0 {
+		t.Fatalf("opened with an empty path")
+	}
+
+	typeKeys(l.handleInput, " /tmp/game.sgf ")
+	key(tcell.KeyEnter)
+	if opened != 1 || gotPath != "/tmp/game.sgf" || gotCont || gotCopy {
+		t.Errorf("Enter on the path: %d opens, %q continue %v copy %v", opened, gotPath, gotCont, gotCopy)
+	}
+
+	// Copy on, then the CONTINUE button.
+	key(tcell.KeyTab)
+	key(tcell.KeyRight)
+	key(tcell.KeyTab)
+	key(tcell.KeyTab)
+	key(tcell.KeyEnter)
+	if opened != 2 || !gotCont || !gotCopy {
+		t.Errorf("CONTINUE with copy: %d opens, continue %v copy %v", opened, gotCont, gotCopy)
+	}
+}
@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/engine"
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+)
+
+// MatchConfirmUI is a small pre-game card shown before a rematch starts.
+// It summarizes the computed settings and lets the player nudge handicap
+// and komi before confirming, instead of silently launching the game.
+type MatchConfirmUI struct {
+	box       *tview.Box
+	flex      *tview.Flex
+	card      *MenuCard
+	onConfirm func(engine.GameConfig)
+	onCancel  func()
+
+	// Components
+	handicapSel  *ValueSelect
+	komiInput    *KomiInput
+	playButton   *MenuButton
+	cancelButton *MenuButton
+
+	// Focus management
+	focusIndex int
+	focusables []focusableComponent
+
+	gameCfg  engine.GameConfig
+	evenKomi float64 // the komi an even game of gameCfg would have
+}
+
+// NewMatchConfirm creates a new match confirmation card.
+func NewMatchConfirm(onConfirm func(engine.GameConfig), onCancel func()) *MatchConfirmUI {
+	m := &MatchConfirmUI{
+		onConfirm: onConfirm,
+		onCancel:  onCancel,
+		card:      NewMenuCard("M A T C H"),
+	}
+
+	m.handicapSel = NewValueSelect("Handicap", []int{0, 2, 3, 4, 5, 6, 7, 8, 9}, 0, m.formatHandicap, m.setHandicap)
+	m.komiInput = NewKomiInput("Komi", 6.5, func(komi float64) {
+		m.gameCfg.Komi = komi
+	})
+
+	m.playButton = NewMenuButton("(P)LAY", true, func() {
+		m.onConfirm(m.gameCfg)
+	})
+	m.cancelButton = NewMenuButton("BACK", false, func() {
+		m.onCancel()
+	})
+
+	m.focusables = []focusableComponent{
+		m.handicapSel,
+		m.komiInput,
+		m.playButton,
+		m.cancelButton,
+	}
+
+	m.box = tview.NewBox()
+	m.box.SetDrawFunc(m.draw)
+	m.box.SetInputCapture(m.handleInput)
+
+	helpText := tview.NewTextView().
+		SetText(glyphs("↑↓ change · Tab next · p play · Esc back")).
+		SetTextAlign(tview.AlignCenter)
+	helpText.SetTextColor(MenuColors.Hint)
+	helpText.SetBackgroundColor(tcell.ColorDefault)
+
+	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(m.box, 14, 0, true).
+		AddItem(nil, 0, 1, false).
+		AddItem(helpText, 1, 0, false)
+
+	m.flex = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(innerFlex, 52, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	return m
+}
+
+// SetGameConfig loads the computed configuration to be confirmed
+// and resets focus to the PLAY button.
+func (m *MatchConfirmUI) SetGameConfig(gc engine.GameConfig) {
+	m.evenKomi = gc.Komi
+	if gc.Handicap >= 2 {
+		m.evenKomi = rules.ByName(gc.Ruleset).DefaultKomi()
+	}
+	m.gameCfg = gc
+	m.handicapSel.SetValue(gc.Handicap)
+	m.komiInput.SetValue(gc.Komi)
+
+	for i, f := range m.focusables {
+		f.SetFocused(f == m.playButton)
+		if f == m.playButton {
+			m.focusIndex = i
+		}
+	}
+}
+
+// suggestedKomi is the komi for the game with handicap: its even-game
+// komi without stones, a token one with them.
+func (m *MatchConfirmUI) suggestedKomi(handicap int) float64 {
+	return engine.CheckSetup(m.gameCfg.PlayerColor, handicap, m.evenKomi).SuggestedKomi
+}
+
+// setHandicap applies a handicap change. The komi follows the handicap
+// unless the player has nudged it.
+func (m *MatchConfirmUI) setHandicap(handicap int) {
+	custom := m.gameCfg.Komi != m.suggestedKomi(m.gameCfg.Handicap)
+	m.gameCfg.Handicap = handicap
+	if !custom {
+		m.komiInput.SetValue(m.suggestedKomi(handicap))
+	}
+}
+
+// formatHandicap describes a handicap for the selector, noting when the
+// stones go to the engine.
+func (m *MatchConfirmUI) formatHandicap(handicap int) string {
+	if handicap < 2 {
+		return "none"
+	}
+	if engine.CheckSetup(m.gameCfg.PlayerColor, handicap, m.gameCfg.Komi).EngineTakesHandicap {
+		return fmt.Sprintf("%d stones (engine's)", handicap)
+	}
+	return fmt.Sprintf("%d stones", handicap)
+}
+
+// Flex returns the flex container for this UI.
+func (m *MatchConfirmUI) Flex() *tview.Flex {
+	return m.flex
+}
+
+// matchSummary renders a one-line description of a game configuration,
+// e.g. "19x19 · you take White · komi 6.5 · GnuGo L6". A handicap shows
+// as "H3" after the color.
+func matchSummary(gc engine.GameConfig) string {
+	color := "Black"
+	if gc.PlayerColor == engine.White {
+		color = "White"
+	}
+	if gc.Handicap >= 2 {
+		color += fmt.Sprintf(" · H%d", gc.Handicap)
+	}
+	return fmt.Sprintf("%dx%d · you take %s · komi %s · GnuGo L%d",
+		gc.BoardSize, gc.BoardSize, color, sgf.FormatKomi(gc.Komi), gc.EngineLevel)
+}
+
+// draw renders the card, summary line, handicap and komi, and buttons.
+func (m *MatchConfirmUI) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	m.card.SetRect(x, y, width, height)
+	m.card.Draw(screen)
+
+	summary := glyphs(matchSummary(m.gameCfg))
+	summaryStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
+	summaryX := x + (width-textWidth(summary))/2
+	drawText(screen, summaryX, y+6, summary, summaryStyle)
+
+	m.handicapSel.Draw(screen, x+4, y+8, width-8)
+	m.komiInput.Draw(screen, x+4, y+9, width-8)
+
+	playW := m.playButton.Width()
+	cancelW := m.cancelButton.Width()
+	spacing := 2
+	buttonX := x + (width-playW-cancelW-spacing)/2
+	buttonX += m.playButton.Draw(screen, buttonX, y+11)
+	buttonX += spacing
+	m.cancelButton.Draw(screen, buttonX, y+11)
+
+	return x, y, width, height
+}
+
+// handleInput processes keyboard input for focus management and nudging
+// the handicap and komi.
+func (m *MatchConfirmUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	focused := m.focusables[m.focusIndex]
+
+	switch event.Key() {
+	case tcell.KeyUp:
+		switch focused {
+		case m.komiInput:
+			m.komiInput.Nudge(1)
+		case m.handicapSel:
+			m.handicapSel.Step(1)
+		}
+		return nil
+	case tcell.KeyDown:
+		switch focused {
+		case m.komiInput:
+			m.komiInput.Nudge(-1)
+		case m.handicapSel:
+			m.handicapSel.Step(-1)
+		}
+		return nil
+	case tcell.KeyEscape:
+		m.onCancel()
+		return nil
+	}
+
+	if m.focusables[m.focusIndex].HandleKey(event) {
+		return nil
+	}
+
+	switch event.Key() {
+	case tcell.KeyTab, tcell.KeyRight:
+		m.cycleFocus(1)
+		return nil
+	case tcell.KeyBacktab, tcell.KeyLeft:
+		m.cycleFocus(-1)
+		return nil
+	case tcell.KeyEnter:
+		m.onConfirm(m.gameCfg)
+		return nil
+	case tcell.KeyRune:
+		if event.Rune() == 'p' {
+			m.onConfirm(m.gameCfg)
+			return nil
+		}
+	}
+	return event
+}
+
+// cycleFocus moves focus to the next/previous component.
+func (m *MatchConfirmUI) cycleFocus(delta int) {
+	m.focusables[m.focusIndex].SetFocused(false)
+	m.focusIndex = (m.focusIndex + delta + len(m.focusables)) % len(m.focusables)
+	m.focusables[m.focusIndex].SetFocused(true)
+}
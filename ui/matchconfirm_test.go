@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+)
+
+func TestMatchSummary(t *testing.T) {
+	gc := engine.GameConfig{BoardSize: 19, Komi: 6.5, PlayerColor: 2, EngineLevel: 6}
+	want := "19x19 · you take White · komi 6.5 · GnuGo L6"
+	if got := matchSummary(gc); got != want {
+		t.Errorf("matchSummary = %q, want %q", got, want)
+	}
+}
+
+func TestMatchSummaryHandicap(t *testing.T) {
+	gc := engine.GameConfig{BoardSize: 9, Komi: 0.5, PlayerColor: 1, EngineLevel: 3, Handicap: 3}
+	want := "9x9 · you take Black · H3 · komi 0.5 · GnuGo L3"
+	if got := matchSummary(gc); got != want {
+		t.Errorf("matchSummary = %q, want %q", got, want)
+	}
+}
+
+func TestMatchSummaryQuarterKomi(t *testing.T) {
+	gc := engine.GameConfig{BoardSize: 13, Komi: 6.25, PlayerColor: 1, EngineLevel: 5}
+	want := "13x13 · you take Black · komi 6.25 · GnuGo L5"
+	if got := matchSummary(gc); got != want {
+		t.Errorf("matchSummary = %q, want %q", got, want)
+	}
+}
+
+func TestMatchConfirmHandicap(t *testing.T) {
+	var got engine.GameConfig
+	m := NewMatchConfirm(func(gc engine.GameConfig) { got = gc }, func() {})
+	m.SetGameConfig(engine.GameConfig{BoardSize: 19, Komi: 6.5, PlayerColor: 1, EngineLevel: 6})
+	press := func(keys ...tcell.Key) {
+		for _, k := range keys {
+			m.handleInput(tcell.NewEventKey(k, 0, tcell.ModNone))
+		}
+	}
+
+	// Back from PLAY to the handicap: two up is 3 stones, and the komi
+	// follows
+	press(tcell.KeyBacktab, tcell.KeyBacktab, tcell.KeyUp, tcell.KeyUp, tcell.KeyEnter)
+	if got.Handicap != 3 || got.Komi != engine.HandicapKomi {
+		t.Errorf("H%d, komi %v; want H3 and %v", got.Handicap, got.Komi, engine.HandicapKomi)
+	}
+
+	// Down past none stops there, with the even game's komi back
+	press(tcell.KeyDown, tcell.KeyDown, tcell.KeyDown, tcell.KeyEnter)
+	if got.Handicap != 0 || got.Komi != 6.5 {
+		t.Errorf("H%d, komi %v; want no handicap and 6.5", got.Handicap, got.Komi)
+	}
+
+	// Komi nudged by hand stays put when the handicap changes
+	press(tcell.KeyTab, tcell.KeyUp, tcell.KeyBacktab, tcell.KeyRight, tcell.KeyEnter)
+	if got.Handicap != 2 || got.Komi != 7.5 {
+		t.Errorf("H%d, komi %v; want H2 and the nudged 7.5", got.Handicap, got.Komi)
+	}
+}
+
+// Playing White, the stones are the engine's, and the card says so.
+func TestMatchConfirmEngineHandicap(t *testing.T) {
+	m := NewMatchConfirm(func(engine.GameConfig) {}, func() {})
+	m.SetGameConfig(engine.GameConfig{BoardSize: 9, Komi: 0.5, PlayerColor: 2, EngineLevel: 3, Handicap: 2})
+	if got := m.formatHandicap(m.handicapSel.Value()); got != "2 stones (engine's)" {
+		t.Errorf("handicap shown as %q", got)
+	}
+}
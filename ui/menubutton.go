@@ -43,27 +43,23 @@ func (b *MenuButton) HandleKey(event *tcell.EventKey) bool {
 func (b *MenuButton) Draw(screen tcell.Screen, x, y int) int {
 	label := b.label
 	if b.primary {
-		label = "▶ " + label
+		label = string(glyph('▶')) + " " + label
 	}
 
 	padding := 1
-	width := len([]rune(label)) + padding*2
+	width := textWidth(label) + padding*2
 
 	if b.focused {
 		// Filled background, bright text
-		style := tcell.StyleDefault.
+		style := filled(tcell.StyleDefault.
 			Foreground(MenuColors.ButtonText).
-			Background(MenuColors.ButtonFocus)
+			Background(MenuColors.ButtonFocus))
 		// Draw filled pill
 		for i := 0; i < width; i++ {
 			screen.SetContent(x+i, y, ' ', nil, style)
 		}
 		// Draw label centered
-		col := x + padding
-		for _, ch := range label {
-			screen.SetContent(col, y, ch, nil, style)
-			col++
-		}
+		drawRunes(screen, x+padding, y, label, style)
 	} else {
 		// Dim text with brackets, no fill
 		dimStyle := tcell.StyleDefault.
@@ -74,11 +70,7 @@ func (b *MenuButton) Draw(screen tcell.Screen, x, y int) int {
 			Background(MenuColors.CardBG)
 
 		screen.SetContent(x, y, '[', nil, bracketStyle)
-		col := x + 1
-		for _, ch := range label {
-			screen.SetContent(col, y, ch, nil, dimStyle)
-			col++
-		}
+		col := drawRunes(screen, x+1, y, label, dimStyle)
 		screen.SetContent(col, y, ']', nil, bracketStyle)
 	}
 
@@ -89,7 +81,7 @@ func (b *MenuButton) Draw(screen tcell.Screen, x, y int) int {
 func (b *MenuButton) Width() int {
 	label := b.label
 	if b.primary {
-		label = "▶ " + label
+		label = string(glyph('▶')) + " " + label
 	}
-	return len([]rune(label)) + 2 // 1 padding on each side (or brackets)
+	return textWidth(label) + 2 // 1 padding on each side (or brackets)
 }
@@ -46,24 +46,24 @@ func (c *MenuCard) Draw(screen tcell.Screen) {
 
 	// Draw rounded corners and borders
 	// Top border: ╭───╮
-	screen.SetContent(x, y, '╭', nil, borderStyle)
+	screen.SetContent(x, y, glyph('╭'), nil, borderStyle)
 	for col := x + 1; col < x+width-1; col++ {
-		screen.SetContent(col, y, '─', nil, borderStyle)
+		screen.SetContent(col, y, glyph('─'), nil, borderStyle)
 	}
-	screen.SetContent(x+width-1, y, '╮', nil, borderStyle)
+	screen.SetContent(x+width-1, y, glyph('╮'), nil, borderStyle)
 
 	// Side borders
 	for row := y + 1; row < y+height-1; row++ {
-		screen.SetContent(x, row, '│', nil, borderStyle)
-		screen.SetContent(x+width-1, row, '│', nil, borderStyle)
+		screen.SetContent(x, row, glyph('│'), nil, borderStyle)
+		screen.SetContent(x+width-1, row, glyph('│'), nil, borderStyle)
 	}
 
 	// Bottom border: ╰───╯
-	screen.SetContent(x, y+height-1, '╰', nil, borderStyle)
+	screen.SetContent(x, y+height-1, glyph('╰'), nil, borderStyle)
 	for col := x + 1; col < x+width-1; col++ {
-		screen.SetContent(col, y+height-1, '─', nil, borderStyle)
+		screen.SetContent(col, y+height-1, glyph('─'), nil, borderStyle)
 	}
-	screen.SetContent(x+width-1, y+height-1, '╯', nil, borderStyle)
+	screen.SetContent(x+width-1, y+height-1, glyph('╯'), nil, borderStyle)
 
 	// Draw title centered with decoration
 	if c.title != "" {
@@ -71,32 +71,24 @@ func (c *MenuCard) Draw(screen tcell.Screen) {
 		accentStyle := tcell.StyleDefault.Foreground(MenuColors.TitleAccent).Background(MenuColors.CardBG)
 
 		// Title with hexagon decoration: ⬡ T E R M S U J I
-		fullTitle := "⬡  " + c.title
-		titleLen := len([]rune(fullTitle))
-		titleX := x + (width-titleLen)/2
+		accent := string(glyph('⬡'))
+		titleX := x + (width-textWidth(accent+"  "+c.title))/2
 
 		// Draw on row y+2 (after top border and a blank line)
 		titleY := y + 2
 
-		// Draw the accent character
-		screen.SetContent(titleX, titleY, '⬡', nil, accentStyle)
-
-		// Draw spaces
-		screen.SetContent(titleX+1, titleY, ' ', nil, bgStyle)
-		screen.SetContent(titleX+2, titleY, ' ', nil, bgStyle)
-
-		// Draw title text
-		for i, ch := range c.title {
-			screen.SetContent(titleX+3+i, titleY, ch, nil, titleStyle)
-		}
+		// The accent character, two spaces, then the title text
+		col := drawRunes(screen, titleX, titleY, accent, accentStyle)
+		col = drawRunes(screen, col, titleY, "  ", bgStyle)
+		drawRunes(screen, col, titleY, c.title, titleStyle)
 
 		// Draw divider after title: ├───┤
 		divY := y + 4
-		screen.SetContent(x, divY, '├', nil, borderStyle)
+		screen.SetContent(x, divY, glyph('├'), nil, borderStyle)
 		for col := x + 1; col < x+width-1; col++ {
-			screen.SetContent(col, divY, '─', nil, borderStyle)
+			screen.SetContent(col, divY, glyph('─'), nil, borderStyle)
 		}
-		screen.SetContent(x+width-1, divY, '┤', nil, borderStyle)
+		screen.SetContent(x+width-1, divY, glyph('┤'), nil, borderStyle)
 	}
 }
 
@@ -109,11 +101,11 @@ func (c *MenuCard) DrawDivider(screen tcell.Screen, divY int) {
 	}
 	borderStyle := tcell.StyleDefault.Foreground(borderColor).Background(MenuColors.CardBG)
 
-	screen.SetContent(x, divY, '├', nil, borderStyle)
+	screen.SetContent(x, divY, glyph('├'), nil, borderStyle)
 	for col := x + 1; col < x+width-1; col++ {
-		screen.SetContent(col, divY, '─', nil, borderStyle)
+		screen.SetContent(col, divY, glyph('─'), nil, borderStyle)
 	}
-	screen.SetContent(x+width-1, divY, '┤', nil, borderStyle)
+	screen.SetContent(x+width-1, divY, glyph('┤'), nil, borderStyle)
 }
 
 // SetFocused sets the focus state of the card.
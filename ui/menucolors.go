@@ -1,6 +1,13 @@
 package ui
 
-import "github.com/gdamore/tcell/v2"
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/sgf"
+)
 
 // MenuColors defines the Nord-inspired color palette for the menu UI.
 var MenuColors = struct {
@@ -16,6 +23,7 @@ var MenuColors = struct {
 	ButtonBG    tcell.Color // Button background (unused in flat design)
 	ButtonFocus tcell.Color // Focused button
 	ButtonText  tcell.Color // Button text
+	InputBG     tcell.Color // Text input field background
 }{
 	Border:      tcell.PaletteColor(60),  // Muted blue-gray
 	BorderFocus: tcell.PaletteColor(109), // Brighter blue
@@ -29,4 +37,206 @@ var MenuColors = struct {
 	ButtonBG:    tcell.PaletteColor(60),  // Nord blue (unused in flat design)
 	ButtonFocus: tcell.PaletteColor(109), // Brighter blue
 	ButtonText:  tcell.PaletteColor(255), // White
+	InputBG:     tcell.PaletteColor(238), // Slightly lighter than card
+}
+
+// noColor is true when the UI must only use the terminal's default colors
+// (NO_COLOR env var or --no-color flag).
+var noColor bool
+
+// Palettes to restore when no-color mode or the light variants are
+// switched off again.
+var (
+	defaultMenuColors  = MenuColors
+	defaultTviewStyles = tview.Styles
+)
+
+// SetNoColor switches the whole UI to the terminal's default foreground and
+// background. Emphasis is carried by bold and reverse video instead of color.
+// Must be called before any tview primitives are created, since tview copies
+// its default styles at construction time.
+func SetNoColor(enabled bool) {
+	noColor = enabled
+	if !enabled {
+		SetLightBackground(lightBackground)
+		return
+	}
+
+	MenuColors.Border = tcell.ColorDefault
+	MenuColors.BorderFocus = tcell.ColorDefault
+	MenuColors.CardBG = tcell.ColorDefault
+	MenuColors.Title = tcell.ColorDefault
+	MenuColors.TitleAccent = tcell.ColorDefault
+	MenuColors.Label = tcell.ColorDefault
+	MenuColors.Hint = tcell.ColorDefault
+	MenuColors.Selected = tcell.ColorDefault
+	MenuColors.Unselected = tcell.ColorDefault
+	MenuColors.ButtonBG = tcell.ColorDefault
+	MenuColors.ButtonFocus = tcell.ColorDefault
+	MenuColors.ButtonText = tcell.ColorDefault
+	MenuColors.InputBG = tcell.ColorDefault
+
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor:    tcell.ColorDefault,
+		ContrastBackgroundColor:     tcell.ColorDefault,
+		MoreContrastBackgroundColor: tcell.ColorDefault,
+		BorderColor:                 tcell.ColorDefault,
+		TitleColor:                  tcell.ColorDefault,
+		GraphicsColor:               tcell.ColorDefault,
+		PrimaryTextColor:            tcell.ColorDefault,
+		SecondaryTextColor:          tcell.ColorDefault,
+		TertiaryTextColor:           tcell.ColorDefault,
+		InverseTextColor:            tcell.ColorDefault,
+		ContrastSecondaryTextColor:  tcell.ColorDefault,
+	}
+}
+
+// lightBackground is true when the palettes are the light-terminal variants.
+var lightBackground bool
+
+// lightTagColors stands in for the tag colors too pale to read on a light
+// background.
+var lightTagColors = map[string]string{
+	"white":   "black",
+	"yellow":  "olive",
+	"dimgray": "#4e4e4e",
+	"green":   "darkgreen",
+}
+
+// SetLightBackground switches the menu palette, tview's defaults and tag
+// colors to their variants for a light terminal background, or back to the
+// dark ones. Like SetNoColor, it must come before any primitives are made.
+func SetLightBackground(light bool) {
+	lightBackground = light
+	MenuColors = defaultMenuColors
+	tview.Styles = defaultTviewStyles
+	if !light {
+		return
+	}
+
+	MenuColors.Border = tcell.PaletteColor(103)     // Muted blue-gray, a shade darker
+	MenuColors.BorderFocus = tcell.PaletteColor(24) // Deep blue
+	MenuColors.CardBG = tcell.PaletteColor(254)     // Near-white
+	MenuColors.Title = tcell.PaletteColor(232)      // Near-black
+	MenuColors.TitleAccent = tcell.PaletteColor(24) // Deep blue
+	MenuColors.Label = tcell.PaletteColor(238)      // Dark gray
+	MenuColors.Hint = tcell.PaletteColor(241)       // Mid gray
+	MenuColors.Selected = tcell.PaletteColor(24)    // Deep blue
+	MenuColors.Unselected = tcell.PaletteColor(241) // Mid gray
+	MenuColors.ButtonBG = tcell.PaletteColor(103)   // Unused in flat design
+	MenuColors.ButtonFocus = tcell.PaletteColor(24) // Deep blue
+	MenuColors.ButtonText = tcell.PaletteColor(255) // White on the blue
+	MenuColors.InputBG = tcell.PaletteColor(252)    // Slightly darker than card
+
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor:    tcell.ColorDefault,
+		ContrastBackgroundColor:     tcell.PaletteColor(252),
+		MoreContrastBackgroundColor: tcell.PaletteColor(250),
+		BorderColor:                 tcell.PaletteColor(240),
+		TitleColor:                  tcell.PaletteColor(232),
+		GraphicsColor:               tcell.PaletteColor(240),
+		PrimaryTextColor:            tcell.PaletteColor(232),
+		SecondaryTextColor:          tcell.PaletteColor(94),
+		TertiaryTextColor:           tcell.PaletteColor(22),
+		InverseTextColor:            tcell.PaletteColor(24),
+		ContrastSecondaryTextColor:  tcell.PaletteColor(24),
+	}
+}
+
+// IsLightBackground returns true if the light-terminal palettes are in use.
+func IsLightBackground() bool {
+	return lightBackground
+}
+
+// IsNoColor returns true if the UI is running in no-color mode.
+func IsNoColor() bool {
+	return noColor
+}
+
+// emphasis marks a selected or focused style. Color already carries this in
+// the normal palette, so it only adds bold in no-color mode.
+func emphasis(style tcell.Style) tcell.Style {
+	if noColor {
+		return style.Bold(true)
+	}
+	return style
+}
+
+// fgStyle returns a default style with the given foreground color,
+// or the plain default style in no-color mode.
+func fgStyle(color tcell.Color) tcell.Style {
+	if noColor {
+		return tcell.StyleDefault
+	}
+	return tcell.StyleDefault.Foreground(color)
+}
+
+// tag wraps text in a tview style tag such as "dimgray" or "white::b".
+// In no-color mode only the attribute part of the tag is kept, and on a
+// light background pale colors are swapped for darker ones, so callers
+// never need to branch on the mode themselves.
+func tag(style, text string) string {
+	if !noColor {
+		if lightBackground {
+			fg, attrs, cut := strings.Cut(style, ":")
+			if light, ok := lightTagColors[fg]; ok && cut {
+				style = light + ":" + attrs
+			} else if ok {
+				style = light
+			}
+		}
+		return "[" + style + "]" + text + "[-:-:-]"
+	}
+	parts := strings.SplitN(style, ":", 3)
+	if len(parts) == 3 && parts[2] != "" {
+		return "[::" + parts[2] + "]" + text + "[::-]"
+	}
+	return text
+}
+
+// outcomeTags are the tag colors for results by how the game went for the
+// human.
+var outcomeTags = map[sgf.Outcome]string{
+	sgf.OutcomeWin:  "green",
+	sgf.OutcomeLoss: "red",
+	sgf.OutcomeDraw: "dimgray",
+}
+
+// resultTag colors a result's text green for a win, red for a loss and
+// gray for a draw, as tag does. An unknown outcome is left as it is.
+func resultTag(o sgf.Outcome, text string) string {
+	if color, ok := outcomeTags[o]; ok {
+		return tag(color, text)
+	}
+	return text
+}
+
+// outcomeColors are the palette colors outcomeStyle gives results, on a
+// dark and on a light background.
+var outcomeColors = map[sgf.Outcome][2]int{
+	sgf.OutcomeWin:  {71, 28},
+	sgf.OutcomeLoss: {167, 124},
+	sgf.OutcomeDraw: {245, 241},
+}
+
+// outcomeStyle returns style with the foreground resultTag would give o.
+// An unknown outcome, or no-color mode, keeps style as it is.
+func outcomeStyle(o sgf.Outcome, style tcell.Style) tcell.Style {
+	colors, ok := outcomeColors[o]
+	if noColor || !ok {
+		return style
+	}
+	if lightBackground {
+		return style.Foreground(tcell.PaletteColor(colors[1]))
+	}
+	return style.Foreground(tcell.PaletteColor(colors[0]))
+}
+
+// filled marks a style whose background acts as a highlight (cursors, focused
+// buttons). In no-color mode the background is lost, so reverse video is used.
+func filled(style tcell.Style) tcell.Style {
+	if noColor {
+		return tcell.StyleDefault.Reverse(true)
+	}
+	return style
 }
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		style, text string
+		noColor     bool
+		want        string
+	}{
+		{"dimgray", "hjkl", false, "[dimgray]hjkl[-:-:-]"},
+		{"white::b", "Moves", false, "[white::b]Moves[-:-:-]"},
+		{"dimgray", "hjkl", true, "hjkl"},
+		{"white::b", "Moves", true, "[::b]Moves[::-]"},
+		{"::b", "Game Complete", true, "[::b]Game Complete[::-]"},
+	}
+	for _, tt := range tests {
+		SetNoColor(tt.noColor)
+		got := tag(tt.style, tt.text)
+		if got != tt.want {
+			t.Errorf("tag(%q, %q) noColor=%v = %q, want %q", tt.style, tt.text, tt.noColor, got, tt.want)
+		}
+	}
+	SetNoColor(false)
+}
+
+func TestResultTag(t *testing.T) {
+	tests := []struct {
+		outcome sgf.Outcome
+		want    string
+	}{
+		{sgf.OutcomeWin, "[green]B+R[-:-:-]"},
+		{sgf.OutcomeLoss, "[red]B+R[-:-:-]"},
+		{sgf.OutcomeDraw, "[dimgray]B+R[-:-:-]"},
+		{sgf.OutcomeUnknown, "B+R"},
+	}
+	for _, tt := range tests {
+		if got := resultTag(tt.outcome, "B+R"); got != tt.want {
+			t.Errorf("resultTag(%v) = %q, want %q", tt.outcome, got, tt.want)
+		}
+	}
+
+	base := tcell.StyleDefault.Foreground(tcell.PaletteColor(245))
+	if fg, _, _ := outcomeStyle(sgf.OutcomeWin, base).Decompose(); fg != tcell.PaletteColor(71) {
+		t.Errorf("win foreground = %v", fg)
+	}
+	if outcomeStyle(sgf.OutcomeUnknown, base) != base {
+		t.Error("an unknown outcome changed the style")
+	}
+	SetNoColor(true)
+	defer SetNoColor(false)
+	if got := resultTag(sgf.OutcomeLoss, "B+R"); got != "B+R" {
+		t.Errorf("no-color resultTag = %q", got)
+	}
+	if outcomeStyle(sgf.OutcomeLoss, base) != base {
+		t.Error("no-color outcomeStyle changed the style")
+	}
+}
+
+func TestTagLightBackground(t *testing.T) {
+	SetLightBackground(true)
+	defer SetLightBackground(false)
+	tests := []struct{ style, want string }{
+		{"dimgray", "[#4e4e4e]hjkl[-:-:-]"},
+		{"white::b", "[black::b]hjkl[-:-:-]"},
+		{"red", "[red]hjkl[-:-:-]"},
+		{"::b", "[::b]hjkl[-:-:-]"},
+	}
+	for _, tt := range tests {
+		if got := tag(tt.style, "hjkl"); got != tt.want {
+			t.Errorf("tag(%q) on a light background = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+	if MenuColors.CardBG == defaultMenuColors.CardBG {
+		t.Error("menu colors not switched to the light variant")
+	}
+
+	// No-color mode still wins, and switching it off keeps the light variant
+	SetNoColor(true)
+	if got := tag("dimgray", "hjkl"); got != "hjkl" {
+		t.Errorf("no-color tag = %q", got)
+	}
+	SetNoColor(false)
+	if !IsLightBackground() || MenuColors.CardBG == defaultMenuColors.CardBG {
+		t.Error("light variant lost after no-color mode")
+	}
+}
+
+// assertDefaultColors fails if any cell on the screen uses a non-default color.
+func assertDefaultColors(t *testing.T, screen tcell.SimulationScreen) {
+	t.Helper()
+	w, h := screen.Size()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			mainc, _, style, _ := screen.GetContent(x, y)
+			fg, bg, _ := style.Decompose()
+			if fg != tcell.ColorDefault || bg != tcell.ColorDefault {
+				t.Fatalf("cell (%d,%d) %q has fg=%v bg=%v, want default colors", x, y, mainc, fg, bg)
+			}
+		}
+	}
+}
+
+func newTestScreen(t *testing.T, w, h int) tcell.SimulationScreen {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("screen init: %v", err)
+	}
+	screen.SetSize(w, h)
+	return screen
+}
+
+func TestNoColorBoard(t *testing.T) {
+	SetNoColor(true)
+	defer SetNoColor(false)
+
+	cfg := config.DefaultConfig
+	cfg.Theme = config.NoColorTheme
+	hint := tview.NewTextView()
+	hint.SetDynamicColors(true)
+	board := NewGoBoard(tview.NewApplication(), &cfg, hint)
+	board.BoardState = types.NewBoardState(9)
+	board.BoardState.Board[4][4] = 1
+	board.BoardState.Board[2][2] = 2
+	board.BoardState.LastMove.X, board.BoardState.LastMove.Y = 4, 4
+	board.selX, board.selY = 3, 3
+	board.refreshHint()
+
+	screen := newTestScreen(t, 80, 14)
+	defer screen.Fini()
+	board.Box.SetRect(0, 0, 80, 12)
+	board.Box.Draw(screen)
+	hint.SetRect(0, 12, 80, 2)
+	hint.Draw(screen)
+
+	assertDefaultColors(t, screen)
+
+	// The cursor must still be visible without color
+	_, _, style, _ := screen.GetContent(4+3*2, 3)
+	if _, _, attr := style.Decompose(); attr&tcell.AttrReverse == 0 {
+		t.Error("cursor cell should be drawn in reverse video")
+	}
+}
+
+func TestNoColorSetup(t *testing.T) {
+	SetNoColor(true)
+	defer SetNoColor(false)
+
+	setup := NewGameSetup(func(engine.GameConfig) {}, func() {}, nil, nil)
+
+	screen := newTestScreen(t, 80, 24)
+	defer screen.Fini()
+	setup.Form().SetRect(0, 0, 80, 24)
+	setup.Form().Draw(screen)
+
+	assertDefaultColors(t, screen)
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"termsuji-local/coords"
+	"termsuji-local/sgf"
+)
+
+// movesPerLine is how many moves FormatMoveList puts on a line.
+const movesPerLine = 10
+
+// colorLetter is "B" for black and "W" for white.
+func colorLetter(color int) string {
+	if color == 2 {
+		return "W"
+	}
+	return "B"
+}
+
+// moveCoord is a move's point as the move list shows it: in the display
+// style coords.SetRowsFromTop picks, or "pass".
+func moveCoord(x, y, size int) string {
+	if x < 0 || y < 0 || size <= 0 {
+		return "pass"
+	}
+	return coords.Display(x, y, size)
+}
+
+// FormatMoveList lays moves out as numbered text for pasting into notes,
+// e.g. "1. B Q16  2. W D4", ten moves to a line. Numbers are padded to the
+// widest and points to four cells, so the columns of a long game line up.
+func FormatMoveList(moves []MoveEntry, size int) string {
+	numWidth := len(strconv.Itoa(len(moves)))
+	var b strings.Builder
+	for i, m := range moves {
+		entry := fmt.Sprintf("%*d. %s %-4s", numWidth, i+1, colorLetter(m.Color), moveCoord(m.X, m.Y, size))
+		switch {
+		case i == len(moves)-1 || (i+1)%movesPerLine == 0:
+			b.WriteString(strings.TrimRight(entry, " ") + "\n")
+		default:
+			b.WriteString(entry + " ")
+		}
+	}
+	return b.String()
+}
+
+// FormatMoveNodes is moves as SGF nodes, e.g. ";B[pd];W[dp]", to paste into
+// a game record.
+func FormatMoveNodes(moves []MoveEntry) string {
+	var b strings.Builder
+	for _, m := range moves {
+		b.WriteString(sgf.MoveNode(m.Color, m.X, m.Y))
+	}
+	return b.String()
+}
+
+// CopyMoves puts the game's moves on the clipboard, as numbered text or as
+// SGF nodes, and says so in the hint bar.
+func (g *GoBoardUI) CopyMoves(asSGF bool) {
+	moves := g.MovesSnapshot()
+	if len(moves) == 0 {
+		g.flashError("No moves to copy", -1, -1)
+		return
+	}
+	text, what := FormatMoveList(moves, g.BoardState.Width()), "Move list"
+	if asSGF {
+		text, what = FormatMoveNodes(moves), "SGF moves"
+	}
+	if err := CopyToClipboard(text); err != nil {
+		g.flashError("Can't copy: "+err.Error(), -1, -1)
+		return
+	}
+	g.flashNotice(fmt.Sprintf("%s sent to the clipboard (%d moves)", what, len(moves)))
+}
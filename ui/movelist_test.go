@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"termsuji-local/coords"
+)
+
+func TestFormatMoveList(t *testing.T) {
+	moves := []MoveEntry{
+		{X: 15, Y: 3, Color: 1},
+		{X: 3, Y: 15, Color: 2},
+		{X: -1, Y: -1, Color: 1},
+		{X: 16, Y: 16, Color: 2},
+	}
+	if got, want := FormatMoveList(moves, 19), "1. B Q16  2. W D4   3. B pass 4. W R3\n"; got != want {
+		t.Errorf("FormatMoveList = %q, want %q", got, want)
+	}
+	if got := FormatMoveList(nil, 19); got != "" {
+		t.Errorf("FormatMoveList(nil) = %q, want \"\"", got)
+	}
+
+	// Rows numbered from the top, as the rest of the UI shows them
+	coords.SetRowsFromTop(true)
+	defer coords.SetRowsFromTop(false)
+	if got, want := FormatMoveList(moves[:2], 19), "1. B Q4   2. W D16\n"; got != want {
+		t.Errorf("FormatMoveList rows from top = %q, want %q", got, want)
+	}
+}
+
+func TestFormatMoveListLongGame(t *testing.T) {
+	moves := make([]MoveEntry, 105)
+	for i := range moves {
+		moves[i] = MoveEntry{X: i % 19, Y: i / 19, Color: 1 + i%2}
+	}
+	lines := strings.Split(strings.TrimSuffix(FormatMoveList(moves, 19), "\n"), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("got %d lines, want 11", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "  1. B A19 ") {
+		t.Errorf("first line %q: numbers not padded to the widest", lines[0])
+	}
+	if !strings.HasPrefix(lines[10], "101. B ") || !strings.HasSuffix(lines[10], "105. B K14") {
+		t.Errorf("last line = %q", lines[10])
+	}
+	// Each move starts in the same column on every full line
+	for _, line := range lines[1:10] {
+		if len(line) != len(lines[0]) {
+			t.Errorf("line %q is %d wide, want %d", line, len(line), len(lines[0]))
+		}
+	}
+}
+
+func TestFormatMoveNodes(t *testing.T) {
+	moves := []MoveEntry{{X: 15, Y: 3, Color: 1}, {X: -1, Y: -1, Color: 2}}
+	if got, want := FormatMoveNodes(moves), ";B[pd];W[]"; got != want {
+		t.Errorf("FormatMoveNodes = %q, want %q", got, want)
+	}
+}
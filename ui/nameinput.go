@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// nameFieldWidth is how many characters of a NameInput show; longer names
+// scroll.
+const nameFieldWidth = 16
+
+// maxNameLen caps a name typed into a NameInput, in runes.
+const maxNameLen = 40
+
+// NameInput is a text field for a name. Typing only edits it; the name is
+// passed on when the field loses focus or on Enter, so a half-typed name
+// isn't saved at every key.
+type NameInput struct {
+	label       string
+	placeholder string // shown dimmed while the field is empty
+	text        []rune
+	saved       string // the name last passed to onChange
+	focused     bool
+	cursor      int
+	onChange    func(string)
+}
+
+// NewNameInput creates a name field holding initial. placeholder is what
+// an empty field stands for.
+func NewNameInput(label, initial, placeholder string, onChange func(string)) *NameInput {
+	return &NameInput{
+		label:       label,
+		placeholder: placeholder,
+		text:        []rune(initial),
+		saved:       initial,
+		cursor:      len([]rune(initial)),
+		onChange:    onChange,
+	}
+}
+
+// SetFocused sets the focus state. Leaving the field passes on its name.
+func (n *NameInput) SetFocused(focused bool) {
+	if n.focused && !focused {
+		n.commit()
+	}
+	n.focused = focused
+}
+
+// HandleKey processes keyboard input. Returns true if handled.
+func (n *NameInput) HandleKey(event *tcell.EventKey) bool {
+	switch event.Key() {
+	case tcell.KeyLeft:
+		if n.cursor > 0 {
+			n.cursor--
+		}
+		return true
+	case tcell.KeyRight:
+		if n.cursor < len(n.text) {
+			n.cursor++
+		}
+		return true
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		n.cursor = 0
+		return true
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		n.cursor = len(n.text)
+		return true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if n.cursor > 0 {
+			n.text = append(n.text[:n.cursor-1], n.text[n.cursor:]...)
+			n.cursor--
+		}
+		return true
+	case tcell.KeyDelete:
+		if n.cursor < len(n.text) {
+			n.text = append(n.text[:n.cursor], n.text[n.cursor+1:]...)
+		}
+		return true
+	case tcell.KeyCtrlU:
+		n.text = n.text[:0]
+		n.cursor = 0
+		return true
+	case tcell.KeyEnter:
+		n.commit()
+		return true
+	case tcell.KeyRune:
+		if len(n.text) < maxNameLen {
+			n.text = append(n.text[:n.cursor], append([]rune{event.Rune()}, n.text[n.cursor:]...)...)
+			n.cursor++
+		}
+		return true
+	}
+	return false
+}
+
+// commit passes the name on if it changed since last time.
+func (n *NameInput) commit() {
+	name := n.Value()
+	if name == n.saved {
+		return
+	}
+	n.saved = name
+	if n.onChange != nil {
+		n.onChange(name)
+	}
+}
+
+// Value returns the name as typed, without surrounding spaces.
+func (n *NameInput) Value() string {
+	return strings.TrimSpace(string(n.text))
+}
+
+// Draw renders the name field.
+// Returns the number of rows used.
+func (n *NameInput) Draw(screen tcell.Screen, x, y, width int) int {
+	bgStyle := tcell.StyleDefault.Background(MenuColors.CardBG)
+	labelStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
+	accentStyle := tcell.StyleDefault.Foreground(MenuColors.TitleAccent).Background(MenuColors.CardBG)
+	selectedStyle := emphasis(tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG))
+	inputStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.InputBG)
+	placeholderStyle := inputStyle.Dim(true)
+	cursorStyle := filled(tcell.StyleDefault.Foreground(MenuColors.CardBG).Background(MenuColors.Selected))
+
+	col := x
+
+	// Focus cursor
+	if n.focused {
+		screen.SetContent(col, y, glyph('▸'), nil, selectedStyle)
+	} else {
+		screen.SetContent(col, y, ' ', nil, bgStyle)
+	}
+	col += 2
+
+	// Label with diamond prefix: ◈ Your name
+	screen.SetContent(col, y, glyph('◈'), nil, accentStyle)
+	col += 2
+
+	for _, ch := range n.label {
+		screen.SetContent(col, y, ch, nil, labelStyle)
+		col++
+	}
+	col += 3 // spacing
+
+	// Input field with brackets: [ Player           ]
+	screen.SetContent(col, y, '[', nil, labelStyle)
+	col++
+	screen.SetContent(col, y, ' ', nil, inputStyle)
+	col++
+
+	// The end of a long name, so the cursor stays in view
+	start := 0
+	if n.cursor >= nameFieldWidth {
+		start = n.cursor - nameFieldWidth + 1
+	}
+	inputStart := col
+	if len(n.text) == 0 && !n.focused {
+		for _, ch := range n.placeholder {
+			screen.SetContent(col, y, ch, nil, placeholderStyle)
+			col++
+		}
+	}
+	for i := start; i < len(n.text) && col < inputStart+nameFieldWidth; i++ {
+		style := inputStyle
+		if n.focused && i == n.cursor {
+			style = cursorStyle
+		}
+		screen.SetContent(col, y, n.text[i], nil, style)
+		col++
+	}
+
+	// Cursor at end
+	if n.focused && n.cursor >= len(n.text) {
+		screen.SetContent(col, y, ' ', nil, cursorStyle)
+		col++
+	}
+
+	// Pad to fixed width
+	for col < inputStart+nameFieldWidth {
+		screen.SetContent(col, y, ' ', nil, inputStyle)
+		col++
+	}
+
+	screen.SetContent(col, y, ' ', nil, inputStyle)
+	col++
+	screen.SetContent(col, y, ']', nil, labelStyle)
+
+	return 1
+}
@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// PathInput is a one-line text field for a file path. The label sits on
+// its own row so long paths get the card's full width; the end of the
+// path stays in view while typing.
+type PathInput struct {
+	label   string
+	text    []rune
+	cursor  int
+	focused bool
+}
+
+// NewPathInput creates an empty path field.
+func NewPathInput(label string) *PathInput {
+	return &PathInput{label: label}
+}
+
+// SetFocused sets the focus state.
+func (p *PathInput) SetFocused(focused bool) {
+	p.focused = focused
+}
+
+// HandleKey processes keyboard input. Returns true if handled.
+func (p *PathInput) HandleKey(event *tcell.EventKey) bool {
+	switch event.Key() {
+	case tcell.KeyLeft:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+		return true
+	case tcell.KeyRight:
+		if p.cursor < len(p.text) {
+			p.cursor++
+		}
+		return true
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		p.cursor = 0
+		return true
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		p.cursor = len(p.text)
+		return true
+	case tcell.KeyCtrlU:
+		p.text = p.text[p.cursor:]
+		p.cursor = 0
+		return true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if p.cursor > 0 {
+			p.text = append(p.text[:p.cursor-1], p.text[p.cursor:]...)
+			p.cursor--
+		}
+		return true
+	case tcell.KeyDelete:
+		if p.cursor < len(p.text) {
+			p.text = append(p.text[:p.cursor], p.text[p.cursor+1:]...)
+		}
+		return true
+	case tcell.KeyRune:
+		p.text = append(p.text[:p.cursor], append([]rune{event.Rune()}, p.text[p.cursor:]...)...)
+		p.cursor++
+		return true
+	}
+	return false
+}
+
+// Draw renders the label and, on the next row, the field.
+// Returns the number of rows used.
+func (p *PathInput) Draw(screen tcell.Screen, x, y, width int) int {
+	bgStyle := tcell.StyleDefault.Background(MenuColors.CardBG)
+	labelStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
+	accentStyle := tcell.StyleDefault.Foreground(MenuColors.TitleAccent).Background(MenuColors.CardBG)
+	selectedStyle := emphasis(tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG))
+	inputStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.InputBG)
+	cursorStyle := filled(tcell.StyleDefault.Foreground(MenuColors.CardBG).Background(MenuColors.Selected))
+
+	// Focus cursor and label: ▸ ◈ File
+	if p.focused {
+		screen.SetContent(x, y, glyph('▸'), nil, selectedStyle)
+	} else {
+		screen.SetContent(x, y, ' ', nil, bgStyle)
+	}
+	screen.SetContent(x+2, y, glyph('◈'), nil, accentStyle)
+	drawText(screen, x+4, y, p.label, labelStyle)
+
+	// Field across the rest of the width, scrolled to keep the cursor in view
+	fieldX, fieldW := x+4, width-4
+	if fieldW < 1 {
+		return 2
+	}
+	start := 0
+	if p.cursor >= fieldW {
+		start = p.cursor - fieldW + 1
+	}
+	for i := 0; i < fieldW; i++ {
+		ch, style := ' ', inputStyle
+		if start+i < len(p.text) {
+			ch = p.text[start+i]
+		}
+		if p.focused && start+i == p.cursor {
+			style = cursorStyle
+		}
+		screen.SetContent(fieldX+i, y+1, ch, nil, style)
+	}
+	return 2
+}
+
+// Path returns the text entered, trimmed, with a leading "~" expanded to
+// the home directory.
+func (p *PathInput) Path() string {
+	path := strings.TrimSpace(string(p.text))
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
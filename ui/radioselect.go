@@ -63,7 +63,7 @@ func (r *RadioSelect) Draw(screen tcell.Screen, x, y, width int) int {
 	bgStyle := tcell.StyleDefault.Background(MenuColors.CardBG)
 	labelStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
 	accentStyle := tcell.StyleDefault.Foreground(MenuColors.TitleAccent).Background(MenuColors.CardBG)
-	selectedStyle := tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG)
+	selectedStyle := emphasis(tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG))
 	unselectedStyle := tcell.StyleDefault.Foreground(MenuColors.Unselected).Background(MenuColors.CardBG)
 	hintStyle := tcell.StyleDefault.Foreground(MenuColors.Hint).Background(MenuColors.CardBG)
 
@@ -71,13 +71,10 @@ func (r *RadioSelect) Draw(screen tcell.Screen, x, y, width int) int {
 
 	// Draw label with diamond prefix: ◈ Board Size
 	col := x
-	screen.SetContent(col, row, '◈', nil, accentStyle)
+	screen.SetContent(col, row, glyph('◈'), nil, accentStyle)
 	col += 2
 
-	for _, ch := range r.label {
-		screen.SetContent(col, row, ch, nil, labelStyle)
-		col++
-	}
+	drawRunes(screen, col, row, r.label, labelStyle)
 	row++
 
 	// Draw options
@@ -86,7 +83,7 @@ func (r *RadioSelect) Draw(screen tcell.Screen, x, y, width int) int {
 
 		// Focus cursor
 		if r.focused && i == r.selected {
-			screen.SetContent(col, row, '▸', nil, selectedStyle)
+			screen.SetContent(col, row, glyph('▸'), nil, selectedStyle)
 		} else {
 			screen.SetContent(col, row, ' ', nil, bgStyle)
 		}
@@ -99,22 +96,16 @@ func (r *RadioSelect) Draw(screen tcell.Screen, x, y, width int) int {
 			bullet = '●'
 			style = selectedStyle
 		}
-		screen.SetContent(col, row, bullet, nil, style)
+		screen.SetContent(col, row, glyph(bullet), nil, style)
 		col += 2
 
 		// Option label
-		for _, ch := range opt.Label {
-			screen.SetContent(col, row, ch, nil, style)
-			col++
-		}
+		col = drawRunes(screen, col, row, glyphs(opt.Label), style)
 
 		// Description (dimmed)
 		if opt.Description != "" {
 			col++ // space
-			for _, ch := range opt.Description {
-				screen.SetContent(col, row, ch, nil, hintStyle)
-				col++
-			}
+			drawRunes(screen, col, row, glyphs(opt.Description), hintStyle)
 		}
 
 		row++
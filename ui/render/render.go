@@ -0,0 +1,177 @@
+// Package render draws a board as plain text, without tcell, for tests and
+// tools. The board widget picks its glyphs with the same functions, so
+// the text and the screen show the same board.
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"termsuji-local/config"
+	"termsuji-local/coords"
+	"termsuji-local/types"
+)
+
+// RenderOptions chooses what BoardToLines draws.
+type RenderOptions struct {
+	Symbols     config.ConfigSymbols // stone and board glyphs; config.ASCIISymbols when ASCII
+	GridLines   bool                 // empty points as box-drawing lines, as UseGridLines themes draw them
+	ASCII       bool                 // grid lines in ASCII, as AsciiOnly themes draw them
+	Coordinates bool                 // row numbers down the left, column letters underneath
+	LastMove    bool                 // the last move in parentheses
+	Marks       map[[2]int]rune      // glyphs drawn over points, keyed by [x, y]
+}
+
+// BoardToLines draws state a line per board row, two columns per point as
+// the board widget draws it, followed by a line of column letters when
+// opts.Coordinates is set.
+func BoardToLines(state *types.BoardState, opts RenderOptions) []string {
+	w, h := state.Width(), state.Height()
+	sym := opts.Symbols
+	if opts.ASCII {
+		sym = config.ASCIISymbols
+	}
+	lines := make([]string, 0, h+1)
+	for y := 0; y < h; y++ {
+		// A lead column before the board, so the last move's "(" has
+		// somewhere to go on the left edge
+		row := make([]rune, 0, 2*w+1)
+		row = append(row, ' ')
+		for x := 0; x < w; x++ {
+			stone := state.Board[y][x]
+			r := Point(stone, x, y, w, h, sym, opts.GridLines, opts.ASCII)
+			if m, ok := opts.Marks[[2]int{x, y}]; ok {
+				r = m
+			}
+			right := ' '
+			if opts.GridLines && stone == 0 {
+				right = Connector(x, w, x+1 < w && state.Board[y][x+1] > 0, opts.ASCII)
+			}
+			row = append(row, r, right)
+		}
+		if opts.LastMove && state.LastMove.Y == y && state.LastMove.X >= 0 && state.LastMove.X < w {
+			row[2*state.LastMove.X] = '('
+			row[2*state.LastMove.X+2] = ')'
+		}
+		line := string(row)
+		if opts.Coordinates {
+			line = fmt.Sprintf("%3d", coords.DisplayRow(y, h)) + line
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	if opts.Coordinates {
+		var b strings.Builder
+		b.WriteString("    ")
+		for x := 0; x < w; x++ {
+			b.WriteString(coords.ToGTP(x, 0, h)[:1])
+			b.WriteByte(' ')
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return lines
+}
+
+// Point returns the glyph for the point (x, y) of a width x height board
+// holding stone (0 empty, 1 black, 2 white): the stone's symbol, or for an
+// empty point the grid line under it or the board square.
+func Point(stone, x, y, width, height int, sym config.ConfigSymbols, gridLines, ascii bool) rune {
+	switch stone {
+	case 1:
+		return sym.BlackStone
+	case 2:
+		return sym.WhiteStone
+	}
+	if gridLines {
+		return GridRune(x, y, width, height, ascii)
+	}
+	return sym.BoardSquare
+}
+
+// Connector returns what follows an empty grid point in its two-column
+// cell: the line to the next point, or a space at the right edge or before
+// a stone.
+func Connector(x, width int, occupiedRight, ascii bool) rune {
+	if x == width-1 || occupiedRight {
+		return ' '
+	}
+	if ascii {
+		return '-'
+	}
+	return '─'
+}
+
+// GridRune returns the appropriate box-drawing character for a grid position,
+// or '+' and '*' for hoshi when ascii is set
+func GridRune(x, y, width, height int, ascii bool) rune {
+	isHoshi := IsHoshi(x, y, width)
+	if ascii {
+		if isHoshi {
+			return '*'
+		}
+		return '+'
+	}
+	if isHoshi {
+		return '◦' // Subtle star point marker
+	}
+
+	isTop := y == 0
+	isBottom := y == height-1
+	isLeft := x == 0
+	isRight := x == width-1
+
+	switch {
+	case isTop && isLeft:
+		return '┌'
+	case isTop && isRight:
+		return '┐'
+	case isBottom && isLeft:
+		return '└'
+	case isBottom && isRight:
+		return '┘'
+	case isTop:
+		return '┬'
+	case isBottom:
+		return '┴'
+	case isLeft:
+		return '├'
+	case isRight:
+		return '┤'
+	default:
+		return '┼'
+	}
+}
+
+// IsHoshi checks if a position is a hoshi (star point) on the board
+func IsHoshi(x, y, boardSize int) bool {
+	var hoshiPositions [][2]int
+
+	switch boardSize {
+	case 9:
+		hoshiPositions = [][2]int{
+			{2, 2}, {2, 6},
+			{4, 4},
+			{6, 2}, {6, 6},
+		}
+	case 13:
+		hoshiPositions = [][2]int{
+			{3, 3}, {3, 9},
+			{6, 6},
+			{9, 3}, {9, 9},
+		}
+	case 19:
+		hoshiPositions = [][2]int{
+			{3, 3}, {3, 9}, {3, 15},
+			{9, 3}, {9, 9}, {9, 15},
+			{15, 3}, {15, 9}, {15, 15},
+		}
+	default:
+		return false
+	}
+
+	for _, pos := range hoshiPositions {
+		if x == pos[0] && y == pos[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"termsuji-local/config"
+	"termsuji-local/types"
+)
+
+func TestBoardToLines(t *testing.T) {
+	state := types.NewBoardState(5)
+	state.Board[2][2] = 1
+	state.Board[1][3] = 2
+	state.LastMove.X, state.LastMove.Y = 2, 2
+
+	got := BoardToLines(state, RenderOptions{
+		GridLines:   true,
+		ASCII:       true,
+		Coordinates: true,
+		LastMove:    true,
+		Marks:       map[[2]int]rune{{0, 4}: 'x'},
+	})
+	want := []string{
+		"  5 +-+-+-+-+",
+		"  4 +-+-+ O +",
+		"  3 +-+(#)+-+",
+		"  2 +-+-+-+-+",
+		"  1 x-+-+-+-+",
+		"    A B C D E",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("BoardToLines =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestBoardToLinesTheme(t *testing.T) {
+	state := types.NewBoardState(9)
+	state.Board[0][0] = 2
+	got := BoardToLines(state, RenderOptions{Symbols: config.DefaultTheme.Symbols, GridLines: true})
+	if got[0] != " ● ┬─┬─┬─┬─┬─┬─┬─┐" {
+		t.Errorf("top row = %q", got[0])
+	}
+	if got[2] != " ├─┼─◦─┼─┼─┼─◦─┼─┤" {
+		t.Errorf("hoshi row = %q", got[2])
+	}
+
+	// Without grid lines every empty point is the board square
+	sym := config.ConfigSymbols{BlackStone: 'X', WhiteStone: 'O', BoardSquare: '.'}
+	got = BoardToLines(state, RenderOptions{Symbols: sym})
+	if got[0] != " O . . . . . . . ." {
+		t.Errorf("top row = %q", got[0])
+	}
+}
+
+func TestGridRune(t *testing.T) {
+	for _, c := range []struct {
+		x, y, size int
+		ascii      bool
+		want       rune
+	}{
+		{0, 0, 9, false, '┌'},
+		{8, 8, 9, false, '┘'},
+		{4, 0, 9, false, '┬'},
+		{4, 4, 9, false, '◦'},
+		{3, 3, 19, false, '◦'},
+		{3, 3, 9, false, '┼'},
+		{4, 4, 9, true, '*'},
+		{0, 0, 9, true, '+'},
+	} {
+		if got := GridRune(c.x, c.y, c.size, c.size, c.ascii); got != c.want {
+			t.Errorf("GridRune(%d, %d, %d, ascii %v) = %q, want %q", c.x, c.y, c.size, c.ascii, got, c.want)
+		}
+	}
+}
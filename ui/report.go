@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/coords"
+	"termsuji-local/report"
+)
+
+// noteQuestionable keeps the player's move at x, y, the game's move'th,
+// for the report when the engine's candidates were on show and it wasn't
+// one of them.
+func (g *GoBoardUI) noteQuestionable(x, y, color, move int) {
+	if len(g.analysis) == 0 || g.analysisRank(x, y) >= 0 {
+		return
+	}
+	size := g.gameConfig.BoardSize
+	best := g.analysis[0]
+	g.questionable = append(g.questionable, report.Questionable{
+		Move:   move,
+		Color:  color,
+		Played: coords.Display(x, y, size),
+		Best:   coords.Display(best.X, best.Y, size),
+	})
+}
+
+// dropQuestionable forgets the questionable moves after the move'th, once
+// they are undone.
+func (g *GoBoardUI) dropQuestionable(move int) {
+	kept := g.questionable[:0]
+	for _, q := range g.questionable {
+		if q.Move <= move {
+			kept = append(kept, q)
+		}
+	}
+	g.questionable = kept
+}
+
+// reportSummary gathers what the report of the game just ended with
+// outcome says.
+func (g *GoBoardUI) reportSummary(outcome string) report.Summary {
+	player := g.gameConfig.PlayerName
+	if player == "" {
+		player = "Player"
+	}
+	opponent := g.engineName()
+	if g.level > 0 {
+		opponent += fmt.Sprintf(" L%d", g.level)
+	}
+	black, white := player, opponent
+	if g.gameConfig.PlayerColor == 2 {
+		black, white = opponent, player
+	}
+	s := report.Summary{
+		Result:       outcome,
+		Started:      g.startedAt,
+		Ended:        time.Now(),
+		BoardSize:    g.gameConfig.BoardSize,
+		Komi:         g.gameConfig.Komi,
+		Black:        black,
+		White:        white,
+		UndosUsed:    g.undosUsed,
+		Analyzed:     g.analyzed,
+		Questionable: g.questionable,
+	}
+	if g.recorder != nil {
+		s.Game = filepath.Base(g.recorder.FilePath)
+	}
+	if bs := g.BoardState; bs != nil {
+		s.Moves = bs.MoveNumber
+		s.CapturesBlack, s.CapturesWhite = bs.CapturesBlack, bs.CapturesWhite
+		s.Board = bs.Board
+	}
+	return s
+}
+
+// writeReport writes the report of the game just ended beside its record
+// when write_reports is on, and opens it.
+func (g *GoBoardUI) writeReport(outcome string) {
+	if !g.cfg.WriteReports || g.recorder == nil {
+		return
+	}
+	text := report.Format(g.reportSummary(outcome))
+	if _, err := report.Write(g.recorder.FilePath, text); err != nil {
+		g.notice = err.Error()
+		return
+	}
+	g.reportText = text
+	if g.onReport != nil && g.app != nil {
+		// Off the engine's callback, which holds stateMu
+		go g.app.QueueUpdateDraw(func() { g.onReport(text) })
+	}
+}
+
+// SetReportFunc sets the callback that shows a report once the game's is
+// written, and on the 'v' key after.
+func (g *GoBoardUI) SetReportFunc(onReport func(text string)) {
+	g.onReport = onReport
+}
+
+// ShowReport opens the finished game's report again, if one was written.
+func (g *GoBoardUI) ShowReport() {
+	if g.reportText != "" && g.onReport != nil {
+		g.onReport(g.reportText)
+	}
+}
+
+// HasReport returns true once the game's report is written.
+func (g *GoBoardUI) HasReport() bool {
+	return g.reportText != ""
+}
+
+// ReportUI shows a game's report on a card, scrolling when it is longer
+// than the card.
+type ReportUI struct {
+	flex    *tview.Flex
+	box     *tview.Box
+	card    *MenuCard
+	lines   []string
+	offset  int // first line shown, for scrolling
+	visible int // lines that fit on the card at the last draw
+	onClose func()
+}
+
+// NewReportViewer creates the report viewer. onClose is called on Esc or
+// q.
+func NewReportViewer(onClose func()) *ReportUI {
+	r := &ReportUI{
+		card:    NewMenuCard("R E P O R T"),
+		onClose: onClose,
+	}
+	r.box = tview.NewBox()
+	r.box.SetDrawFunc(r.draw)
+	r.box.SetInputCapture(r.handleInput)
+
+	hintText := tview.NewTextView().
+		SetText(glyphs("↑↓ scroll · Esc close")).
+		SetTextAlign(tview.AlignCenter)
+	hintText.SetTextColor(MenuColors.Hint)
+	hintText.SetBackgroundColor(tcell.ColorDefault)
+
+	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 1, 0, false).
+		AddItem(r.box, 0, 1, true).
+		AddItem(hintText, 1, 0, false)
+
+	r.flex = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(innerFlex, helpWidth, 0, true).
+		AddItem(nil, 0, 1, false)
+	return r
+}
+
+// Flex returns the flex container for this UI.
+func (r *ReportUI) Flex() *tview.Flex {
+	return r.flex
+}
+
+// SetReport shows text, from the top.
+func (r *ReportUI) SetReport(text string) {
+	r.lines = strings.Split(strings.TrimRight(text, "\n"), "\n")
+	r.offset = 0
+}
+
+// draw renders the card with the part of the report that fits.
+func (r *ReportUI) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	r.card.SetRect(x, y, width, height)
+	r.card.Draw(screen)
+
+	headingStyle := tcell.StyleDefault.Foreground(MenuColors.Title).Background(MenuColors.CardBG).Bold(true)
+	lineStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
+
+	top, bottom := y+6, y+height-2
+	r.visible = bottom - top
+	if r.visible < 1 {
+		return x, y, width, height
+	}
+	r.clampOffset()
+	for i := 0; i < r.visible && r.offset+i < len(r.lines); i++ {
+		line := r.lines[r.offset+i]
+		style := lineStyle
+		if line != "" && !strings.HasPrefix(line, " ") && !strings.Contains(line, "  ") {
+			// Headings stand alone; fields have their value after a gap
+			style = headingStyle
+		}
+		drawText(screen, x+4, top+i, line, style)
+	}
+
+	moreStyle := tcell.StyleDefault.Foreground(MenuColors.Hint).Background(MenuColors.CardBG)
+	if r.offset > 0 {
+		drawText(screen, x+width-4, top, glyphs("↑"), moreStyle)
+	}
+	if r.offset+r.visible < len(r.lines) {
+		drawText(screen, x+width-4, bottom-1, glyphs("↓"), moreStyle)
+	}
+	return x, y, width, height
+}
+
+// clampOffset keeps the scroll within the report.
+func (r *ReportUI) clampOffset() {
+	if max := len(r.lines) - r.visible; r.offset > max {
+		r.offset = max
+	}
+	if r.offset < 0 {
+		r.offset = 0
+	}
+}
+
+// scroll moves the report by n lines, up for negative n.
+func (r *ReportUI) scroll(n int) {
+	r.offset += n
+	r.clampOffset()
+}
+
+func (r *ReportUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEsc:
+		r.onClose()
+	case tcell.KeyUp:
+		r.scroll(-1)
+	case tcell.KeyDown:
+		r.scroll(1)
+	case tcell.KeyPgUp:
+		r.scroll(-r.visible)
+	case tcell.KeyPgDn:
+		r.scroll(r.visible)
+	case tcell.KeyHome:
+		r.offset = 0
+	case tcell.KeyEnd:
+		r.scroll(len(r.lines))
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q', 'v':
+			r.onClose()
+		case 'k':
+			r.scroll(-1)
+		case 'j':
+			r.scroll(1)
+		}
+	}
+	// The viewer keeps every key from the board beneath
+	return nil
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+func TestQuestionableMoves(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, PlayerColor: 1}, 0)
+	move := func(x, y, color, n int) {
+		bs := types.NewBoardState(9)
+		bs.MoveNumber = n
+		eng.onMove(x, y, color, bs)
+	}
+
+	// Only the player's moves against candidates on show count
+	move(4, 4, 1, 1)
+	g.setAnalysis([]engine.Candidate{{X: 2, Y: 2}, {X: 6, Y: 6}})
+	move(6, 6, 1, 3)
+	g.setAnalysis([]engine.Candidate{{X: 2, Y: 2}})
+	move(4, 2, 1, 5)
+	g.setAnalysis([]engine.Candidate{{X: 2, Y: 6}})
+	move(3, 3, 2, 6)
+	if !g.analyzed || len(g.questionable) != 1 {
+		t.Fatalf("questionable %+v, analyzed %v; want the 5th move alone", g.questionable, g.analyzed)
+	}
+	if q := g.questionable[0]; q.Move != 5 || q.Played != "E7" || q.Best != "C7" {
+		t.Errorf("questionable move %+v", q)
+	}
+
+	// Undone moves drop out
+	g.dropQuestionable(4)
+	if len(g.questionable) != 0 {
+		t.Errorf("questionable %+v after undoing the 5th move", g.questionable)
+	}
+}
+
+func TestWriteReportAtGameEnd(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, Komi: 6.5, PlayerColor: 1, EngineLevel: 5}, 0)
+	dir := t.TempDir()
+	rec, err := sgf.NewGameRecord(dir, 9, 6.5, 1, 5, sgf.RecordOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetRecorder(rec)
+	t.Cleanup(func() { g.recorder.Close() })
+
+	// Off by default
+	eng.onGameEnd("W+R")
+	if g.HasReport() {
+		t.Fatal("report written with write_reports off")
+	}
+
+	g.resetGame()
+	g.cfg.WriteReports = true
+	eng.state.MoveNumber, eng.state.CapturesWhite = 2, 1
+	eng.onGameEnd("W+R")
+	if !g.HasReport() {
+		t.Fatalf("no report; notice %q", g.notice)
+	}
+	data, err := os.ReadFile(sgf.ReportPath(rec.FilePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"White wins by resignation", "Black     Player", "White     GnuGo L5", "Black took 0, White took 1", "Final position"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("report lacks %q:\n%s", want, data)
+		}
+	}
+
+	files, _ := sgf.ListGameFiles(dir)
+	if len(files) != 1 || !files[0].HasReport {
+		t.Errorf("listed %+v; want the game, with its report", files)
+	}
+}
+
+func TestReportViewer(t *testing.T) {
+	closed := 0
+	r := NewReportViewer(func() { closed++ })
+	r.SetReport(strings.Repeat("line\n", 40) + "last\n")
+	screen := newTestScreen(t, 80, 20)
+	defer screen.Fini()
+	r.flex.SetRect(0, 0, 80, 20)
+	r.flex.Draw(screen)
+	r.handleInput(tcell.NewEventKey(tcell.KeyEnd, 0, tcell.ModNone))
+	r.flex.Draw(screen)
+	screen.Show()
+	if text := screenText(screen); !strings.Contains(text, "last") {
+		t.Errorf("End should show the last line:\n%s", text)
+	}
+	r.SetReport("another")
+	if r.offset != 0 {
+		t.Errorf("a new report opens at line %d", r.offset)
+	}
+	if r.handleInput(tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone)) != nil || closed != 1 {
+		t.Errorf("q closed %d times, want 1", closed)
+	}
+}
+
+func TestHistoryReportBadge(t *testing.T) {
+	dir := writeHistory(t, []int{9, 9}, []string{"B+R", "W+R"})
+	hb := NewHistoryBrowser(NewGameList(dir), nil, nil, nil, nil, nil)
+	hb.Refresh()
+	if err := os.WriteFile(sgf.ReportPath(hb.games[0].FilePath), []byte("report"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hb.list.Invalidate()
+	hb.Refresh()
+
+	screen := newTestScreen(t, 100, 30)
+	defer screen.Fini()
+	draw := func() string {
+		screen.Clear()
+		hb.flex.SetRect(0, 0, 100, 30)
+		hb.flex.Draw(screen)
+		screen.Show()
+		return screenText(screen)
+	}
+	if text := draw(); !strings.Contains(text, "[report]") {
+		t.Errorf("no badge for the game with a report:\n%s", text)
+	}
+	hb.gameList.SetCurrentItem(1)
+	if text := draw(); strings.Contains(text, "[report]") {
+		t.Errorf("badge for a game without a report:\n%s", text)
+	}
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"termsuji-local/rules"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+// Review shows a saved game a move at a time. No engine is started: the
+// board only follows the replayer, and the move list marks where it is.
+
+// StartReview shows rep on the board from its first move. The previous
+// game's engine must already be closed.
+func (g *GoBoardUI) StartReview(rep *sgf.Replayer) {
+	g.resetGame()
+	g.eng = nil
+	g.recorder = nil
+	g.review = rep
+	g.SetMoveHistory(rep.Moves())
+	g.SetMoveComments(rep.Comments())
+	g.ResetSelection()
+	g.showReview()
+}
+
+// IsReviewing returns true while a saved game is being reviewed.
+func (g *GoBoardUI) IsReviewing() bool {
+	return g.review != nil
+}
+
+// StopReview leaves review mode.
+func (g *GoBoardUI) StopReview() {
+	g.StopGuessing()
+	g.review = nil
+	g.reviewCount = ""
+	g.territory = nil
+	g.refreshHint()
+}
+
+// ScoreReview counts the position on the board with komi under the named
+// ruleset, as rules.ByName reads it, and tints the territory. Nothing is
+// taken off as dead, so the count is of the position as it stands; it
+// lasts until the next step.
+func (g *GoBoardUI) ScoreReview(komi float64, ruleset string) {
+	if g.review == nil {
+		return
+	}
+	bs := g.BoardState
+	g.reviewCount = rules.ByName(ruleset).Score(bs.Board, nil, bs.CapturesBlack, bs.CapturesWhite, komi).LocalResult()
+	g.territory = sgf.Territory(bs.Board, nil)
+	g.refreshHint()
+}
+
+// ReviewStep moves delta moves forwards, or backwards when negative.
+func (g *GoBoardUI) ReviewStep(delta int) {
+	if g.review == nil {
+		return
+	}
+	g.ReviewGoto(g.review.Position() + delta)
+}
+
+// ReviewGoto shows the position after n moves, clamped to the game.
+func (g *GoBoardUI) ReviewGoto(n int) {
+	if g.review == nil {
+		return
+	}
+	g.review.Goto(n)
+	g.showReview()
+}
+
+// ReviewPosition returns how many moves of the reviewed game are on the
+// board, or -1 when not reviewing.
+func (g *GoBoardUI) ReviewPosition() int {
+	if g.review == nil {
+		return -1
+	}
+	return g.review.Position()
+}
+
+// showReview copies the replayer's position into BoardState for drawing.
+func (g *GoBoardUI) showReview() {
+	rep := g.review
+	bs := types.NewBoardState(rep.Size())
+	for y, row := range rep.Board() {
+		copy(bs.Board[y], row)
+	}
+	bs.MoveNumber = rep.Position()
+	bs.CapturesBlack, bs.CapturesWhite = rep.Captures()
+	if color, x, y, ok := rep.LastMove(); ok {
+		bs.LastMove.X, bs.LastMove.Y = x, y
+		bs.PlayerToMove = oppositeColor(color)
+	}
+	g.BoardState = bs
+	g.reviewCount = ""
+	g.territory = nil
+	g.refreshHint()
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/sgf"
+)
+
+func TestReviewSteps(t *testing.T) {
+	cfg := config.DefaultConfig
+	g := NewGoBoard(tview.NewApplication(), &cfg, tview.NewTextView())
+	g.infoPanel = NewGameInfoPanel()
+	g.infoPanel.SetMoveHistory(&g.moveHistory, 9)
+	// White's stone at (1,0) is captured by the fifth move.
+	moves := [][3]int{{1, 0, 0}, {2, 1, 0}, {1, 2, 0}, {2, 4, 4}, {1, 1, 1}}
+	g.StartReview(sgf.NewReplayer(9, nil, moves))
+
+	if !g.IsReviewing() || g.eng != nil {
+		t.Fatalf("StartReview: reviewing %v with engine %v", g.IsReviewing(), g.eng)
+	}
+	if g.BoardState.MoveNumber != 0 || g.BoardState.Board[0][0] != 0 {
+		t.Errorf("review should start before the first move")
+	}
+
+	g.ReviewGoto(5)
+	if g.BoardState.Board[0][1] != 0 || g.BoardState.CapturesBlack != 1 {
+		t.Errorf("after move 5: (1,0) = %d, black captures %d; want captured, 1",
+			g.BoardState.Board[0][1], g.BoardState.CapturesBlack)
+	}
+	if g.BoardState.LastMove.X != 1 || g.BoardState.LastMove.Y != 1 {
+		t.Errorf("last move = (%d,%d), want (1,1)", g.BoardState.LastMove.X, g.BoardState.LastMove.Y)
+	}
+
+	g.ReviewStep(-1)
+	if g.BoardState.Board[0][1] != 2 {
+		t.Errorf("stepping back did not restore the captured stone")
+	}
+	if g.BoardState.PlayerToMove != 1 {
+		t.Errorf("PlayerToMove after White's move = %d, want Black", g.BoardState.PlayerToMove)
+	}
+	if text := g.infoPanel.Box().GetText(true); !strings.Contains(text, ">  4. W") {
+		t.Errorf("move list should mark move 4:\n%s", text)
+	}
+	if text := g.hint.GetText(true); !strings.Contains(text, "move 4/5") {
+		t.Errorf("hint = %q, want the review position", text)
+	}
+
+	// Counting the position as it stands after the capture: Black has the
+	// point taken and the prisoner, 2 against komi 6.5; by area, three
+	// stones and the point against White's one stone
+	g.ReviewGoto(5)
+	g.ScoreReview(6.5, "")
+	if text := g.hint.GetText(true); !strings.Contains(text, "count W+4.5") {
+		t.Errorf("hint = %q, want the count", text)
+	}
+	if g.territoryOverlay() == nil {
+		t.Error("counting should tint the territory")
+	}
+	g.ScoreReview(6.5, "Chinese")
+	if text := g.hint.GetText(true); !strings.Contains(text, "count W+3.5") {
+		t.Errorf("hint = %q, want the area count", text)
+	}
+	g.ReviewStep(-1)
+	if text := g.hint.GetText(true); strings.Contains(text, "count W+") || g.territoryOverlay() != nil {
+		t.Errorf("a step should drop the count: %q", text)
+	}
+
+	g.ReviewStep(-10)
+	if g.BoardState.MoveNumber != 0 {
+		t.Errorf("stepping back past the start stopped at %d", g.BoardState.MoveNumber)
+	}
+
+	g.StopReview()
+	if g.IsReviewing() {
+		t.Error("StopReview left review mode on")
+	}
+}
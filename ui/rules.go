@@ -0,0 +1,411 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"termsuji-local/config"
+	"termsuji-local/types"
+	"termsuji-local/ui/render"
+)
+
+// ruleSection is a section of the rules reference: a heading, what the
+// rule says, and diagrams side by side to show it.
+type ruleSection struct {
+	Title    string
+	Text     []string // paragraphs
+	Diagrams []ruleDiagram
+}
+
+// ruleDiagram is a small position, drawn by the board renderer.
+type ruleDiagram struct {
+	Caption      string
+	Size         int
+	Black, White [][2]int        // [x, y] points
+	Marks        map[[2]int]rune // letters over points, named in the text
+}
+
+// rulesSections is the reference's content. The diagrams are 5x5 so two
+// or three fit side by side on the card.
+var rulesSections = []ruleSection{
+	{
+		Title: "Liberties",
+		Text: []string{
+			"The empty points next to a stone, up, down, left and right, are its liberties. Stones of one color next to each other form a group and share their liberties.",
+		},
+		Diagrams: []ruleDiagram{
+			{Caption: "four: a to d", Size: 5, Black: [][2]int{{2, 2}},
+				Marks: map[[2]int]rune{{2, 1}: 'a', {3, 2}: 'b', {2, 3}: 'c', {1, 2}: 'd'}},
+			{Caption: "on the edge, three", Size: 5, Black: [][2]int{{0, 2}},
+				Marks: map[[2]int]rune{{0, 1}: 'a', {1, 2}: 'b', {0, 3}: 'c'}},
+			{Caption: "in the corner, two", Size: 5, Black: [][2]int{{0, 0}},
+				Marks: map[[2]int]rune{{1, 0}: 'a', {0, 1}: 'b'}},
+		},
+	},
+	{
+		Title: "Capturing",
+		Text: []string{
+			"A move that takes away the last liberty of a group captures it: its stones come off the board and count as prisoners for the capturer.",
+		},
+		Diagrams: []ruleDiagram{
+			{Caption: "Black has one liberty, a", Size: 5, Black: [][2]int{{2, 2}}, White: [][2]int{{2, 1}, {1, 2}, {3, 2}},
+				Marks: map[[2]int]rune{{2, 3}: 'a'}},
+			{Caption: "White plays a and captures", Size: 5, White: [][2]int{{2, 1}, {1, 2}, {3, 2}, {2, 3}}},
+		},
+	},
+	{
+		Title: "Suicide",
+		Text: []string{
+			"A stone may not be played where it would have no liberties, unless the move captures and so makes some.",
+		},
+		Diagrams: []ruleDiagram{
+			{Caption: "Black may not play a", Size: 5, White: [][2]int{{2, 1}, {1, 2}, {3, 2}, {2, 3}},
+				Marks: map[[2]int]rune{{2, 2}: 'a'}},
+			{Caption: "but here b captures", Size: 5, Black: [][2]int{{2, 0}, {0, 2}, {1, 1}},
+				White: [][2]int{{0, 1}, {1, 0}}, Marks: map[[2]int]rune{{0, 0}: 'b'}},
+		},
+	},
+	{
+		Title: "Ko",
+		Text: []string{
+			"Black captures one stone at a. White could take back at b at once, and the two could repeat forever, so White must first play elsewhere. This is ko: no move may bring back the position before the previous one.",
+		},
+		Diagrams: []ruleDiagram{
+			{Caption: "Black captures at a", Size: 5, Black: [][2]int{{1, 1}, {0, 2}, {1, 3}},
+				White: [][2]int{{2, 1}, {1, 2}, {3, 2}, {2, 3}}, Marks: map[[2]int]rune{{2, 2}: 'a'}},
+			{Caption: "White may not retake b yet", Size: 5, Black: [][2]int{{1, 1}, {0, 2}, {1, 3}, {2, 2}},
+				White: [][2]int{{2, 1}, {3, 2}, {2, 3}}, Marks: map[[2]int]rune{{1, 2}: 'b'}},
+		},
+	},
+	{
+		Title: "Passing",
+		Text: []string{
+			"Instead of playing you may pass, with p. Once neither side can gain by playing on, both pass and the game ends: two passes in a row end it.",
+		},
+		Diagrams: []ruleDiagram{
+			{Caption: "the border is closed: pass", Size: 5,
+				Black: [][2]int{{2, 0}, {2, 1}, {2, 2}, {2, 3}, {2, 4}},
+				White: [][2]int{{3, 0}, {3, 1}, {3, 2}, {3, 3}, {3, 4}}},
+		},
+	},
+	{
+		Title: "Scoring",
+		Text: []string{
+			"Each side scores the empty points it surrounds, its territory. Counting by territory adds the prisoners taken; counting by area adds the stones on the board instead. White gets komi, a few points for moving second.",
+		},
+		Diagrams: []ruleDiagram{
+			{Caption: "Black 10 (b), White 5 (w)", Size: 5,
+				Black: [][2]int{{2, 0}, {2, 1}, {2, 2}, {2, 3}, {2, 4}},
+				White: [][2]int{{3, 0}, {3, 1}, {3, 2}, {3, 3}, {3, 4}},
+				Marks: columnMarks(map[int]rune{0: 'b', 1: 'b', 4: 'w'}, 5)},
+		},
+	},
+}
+
+// columnMarks marks every point of the columns in marks, on a size x size
+// board.
+func columnMarks(marks map[int]rune, size int) map[[2]int]rune {
+	m := make(map[[2]int]rune)
+	for x, r := range marks {
+		for y := 0; y < size; y++ {
+			m[[2]int{x, y}] = r
+		}
+	}
+	return m
+}
+
+// ruleSymbols are the diagrams' stones, told apart by shape as the
+// reference has no board colors.
+var ruleSymbols = config.ConfigSymbols{BlackStone: '●', WhiteStone: '○', BoardSquare: '┼'}
+
+// diagramGap is the space between diagrams side by side.
+const diagramGap = 3
+
+// lines draws the diagram with the board renderer, in ASCII when the
+// menus are.
+func (d ruleDiagram) lines() []string {
+	state := &types.BoardState{Board: make([][]int, d.Size)}
+	for y := range state.Board {
+		state.Board[y] = make([]int, d.Size)
+	}
+	for _, p := range d.Black {
+		state.Board[p[1]][p[0]] = 1
+	}
+	for _, p := range d.White {
+		state.Board[p[1]][p[0]] = 2
+	}
+	return render.BoardToLines(state, render.RenderOptions{
+		Symbols:   ruleSymbols,
+		GridLines: true,
+		ASCII:     menuASCII(),
+		Marks:     d.Marks,
+	})
+}
+
+// ruleLineKind says how a line of the reference is drawn.
+type ruleLineKind int
+
+const (
+	ruleText ruleLineKind = iota
+	ruleHeading
+	ruleBoard
+	ruleCaption
+)
+
+// ruleLine is a line of the laid out reference.
+type ruleLine struct {
+	text string
+	kind ruleLineKind
+}
+
+// layoutRules lays sections out in lines no wider than width, with the
+// index of each section's heading.
+func layoutRules(sections []ruleSection, width int) (lines []ruleLine, starts []int) {
+	for i, s := range sections {
+		if i > 0 {
+			lines = append(lines, ruleLine{})
+		}
+		starts = append(starts, len(lines))
+		lines = append(lines, ruleLine{text: s.Title, kind: ruleHeading})
+		for _, p := range s.Text {
+			for _, l := range wrapWords(p, width) {
+				lines = append(lines, ruleLine{text: l})
+			}
+		}
+		if len(s.Diagrams) == 0 {
+			continue
+		}
+		lines = append(lines, ruleLine{})
+		lines = append(lines, layoutDiagrams(s.Diagrams)...)
+	}
+	return lines, starts
+}
+
+// layoutDiagrams puts diagrams side by side, each over its caption.
+func layoutDiagrams(diagrams []ruleDiagram) []ruleLine {
+	var boards [][]string
+	var widths []int
+	rows := 0
+	for _, d := range diagrams {
+		b := d.lines()
+		w := 2 * d.Size
+		if n := runeLen(d.Caption); n > w {
+			w = n
+		}
+		boards, widths = append(boards, b), append(widths, w)
+		if len(b) > rows {
+			rows = len(b)
+		}
+	}
+	row := func(cell func(i int) string, kind ruleLineKind) ruleLine {
+		var b strings.Builder
+		for i := range diagrams {
+			if i > 0 {
+				b.WriteString(strings.Repeat(" ", diagramGap))
+			}
+			text := cell(i)
+			b.WriteString(text)
+			b.WriteString(strings.Repeat(" ", widths[i]-runeLen(text)))
+		}
+		return ruleLine{text: strings.TrimRight(b.String(), " "), kind: kind}
+	}
+	var lines []ruleLine
+	for y := 0; y < rows; y++ {
+		lines = append(lines, row(func(i int) string {
+			if y < len(boards[i]) {
+				return boards[i][y]
+			}
+			return ""
+		}, ruleBoard))
+	}
+	lines = append(lines, row(func(i int) string { return diagrams[i].Caption }, ruleCaption))
+	return lines
+}
+
+// RulesUI is a short reference to the rules of go, opened from the key
+// reference: a section per rule, each with diagrams.
+type RulesUI struct {
+	flex    *tview.Flex
+	box     *tview.Box
+	card    *MenuCard
+	lines   []ruleLine
+	starts  []int // the line of each section's heading
+	offset  int   // first line shown, for scrolling
+	visible int   // lines that fit on the card at the last draw
+	onClose func()
+}
+
+// NewRules creates the rules reference. onClose is called on Esc or q.
+func NewRules(onClose func()) *RulesUI {
+	r := &RulesUI{
+		card:    NewMenuCard("R U L E S"),
+		onClose: onClose,
+	}
+	r.lines, r.starts = layoutRules(rulesSections, helpWidth-8)
+
+	r.box = tview.NewBox()
+	r.box.SetDrawFunc(r.draw)
+	r.box.SetInputCapture(r.handleInput)
+
+	hintText := tview.NewTextView().
+		SetText(glyphs("j k section · ↑↓ PgUp PgDn scroll · Esc back")).
+		SetTextAlign(tview.AlignCenter)
+	hintText.SetTextColor(MenuColors.Hint)
+	hintText.SetBackgroundColor(tcell.ColorDefault)
+
+	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 1, 0, false).
+		AddItem(r.box, 0, 1, true).
+		AddItem(hintText, 1, 0, false)
+
+	r.flex = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(innerFlex, helpWidth, 0, true).
+		AddItem(nil, 0, 1, false)
+	return r
+}
+
+// Flex returns the flex container for this UI.
+func (r *RulesUI) Flex() *tview.Flex {
+	return r.flex
+}
+
+// Reset goes back to the first section, for the next time it opens.
+func (r *RulesUI) Reset() {
+	r.offset = 0
+}
+
+// draw renders the card with the part of the reference that fits.
+func (r *RulesUI) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	r.card.SetRect(x, y, width, height)
+	r.card.Draw(screen)
+
+	bg := tcell.StyleDefault.Background(MenuColors.CardBG)
+	headingStyle := bg.Foreground(MenuColors.Title).Bold(true)
+	textStyle := bg.Foreground(MenuColors.Label)
+	captionStyle := bg.Foreground(MenuColors.Hint)
+	gridStyle := bg.Foreground(MenuColors.Border)
+	blackStyle := bg.Foreground(MenuColors.Title).Bold(true)
+	whiteStyle := bg.Foreground(MenuColors.Label)
+	markStyle := bg.Foreground(MenuColors.TitleAccent).Bold(true)
+
+	top, bottom := y+6, y+height-2
+	r.visible = bottom - top
+	if r.visible < 1 {
+		return x, y, width, height
+	}
+	r.clampOffset()
+	for i := 0; i < r.visible && r.offset+i < len(r.lines); i++ {
+		line := r.lines[r.offset+i]
+		switch line.kind {
+		case ruleHeading:
+			drawText(screen, x+4, top+i, glyphs("◈ ")+line.text, headingStyle)
+		case ruleCaption:
+			drawText(screen, x+4, top+i, line.text, captionStyle)
+		case ruleText:
+			drawText(screen, x+4, top+i, line.text, textStyle)
+		case ruleBoard:
+			col := x + 4
+			for _, ch := range line.text {
+				style := gridStyle
+				switch {
+				case ch == ruleSymbols.BlackStone || ch == config.ASCIISymbols.BlackStone:
+					style = blackStyle
+				case ch == ruleSymbols.WhiteStone || ch == config.ASCIISymbols.WhiteStone:
+					style = whiteStyle
+				case ch >= 'a' && ch <= 'z':
+					style = markStyle
+				}
+				col = drawRunes(screen, col, top+i, string(ch), style)
+			}
+		}
+	}
+
+	moreStyle := tcell.StyleDefault.Foreground(MenuColors.Hint).Background(MenuColors.CardBG)
+	if r.offset > 0 {
+		drawText(screen, x+width-4, top, glyphs("↑"), moreStyle)
+	}
+	if r.offset+r.visible < len(r.lines) {
+		drawText(screen, x+width-4, bottom-1, glyphs("↓"), moreStyle)
+	}
+	return x, y, width, height
+}
+
+// clampOffset keeps the scroll within the reference.
+func (r *RulesUI) clampOffset() {
+	if max := len(r.lines) - r.visible; r.offset > max {
+		r.offset = max
+	}
+	if r.offset < 0 {
+		r.offset = 0
+	}
+}
+
+// scroll moves the reference by n lines, up for negative n.
+func (r *RulesUI) scroll(n int) {
+	r.offset += n
+	r.clampOffset()
+}
+
+// section returns the index of the section at the top of the card.
+func (r *RulesUI) section() int {
+	current := 0
+	for i, start := range r.starts {
+		if start <= r.offset {
+			current = i
+		}
+	}
+	return current
+}
+
+// jump scrolls to the heading of the section n away, back for negative n.
+func (r *RulesUI) jump(n int) {
+	i := r.section() + n
+	if n < 0 && r.offset > r.starts[r.section()] {
+		// Back to the start of a section scrolled into first
+		i++
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(r.starts) {
+		i = len(r.starts) - 1
+	}
+	r.offset = r.starts[i]
+	r.clampOffset()
+}
+
+func (r *RulesUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEsc:
+		r.onClose()
+	case tcell.KeyUp:
+		r.scroll(-1)
+	case tcell.KeyDown:
+		r.scroll(1)
+	case tcell.KeyPgUp:
+		r.scroll(-r.visible)
+	case tcell.KeyPgDn:
+		r.scroll(r.visible)
+	case tcell.KeyHome:
+		r.offset = 0
+	case tcell.KeyEnd:
+		r.scroll(len(r.lines))
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q':
+			r.onClose()
+		case 'j':
+			r.jump(1)
+		case 'k':
+			r.jump(-1)
+		default:
+			return event
+		}
+	default:
+		return event
+	}
+	return nil
+}
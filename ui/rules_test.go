@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRulesSections(t *testing.T) {
+	var titles []string
+	for _, s := range rulesSections {
+		titles = append(titles, s.Title)
+	}
+	if got, want := strings.Join(titles, " "), "Liberties Capturing Suicide Ko Passing Scoring"; got != want {
+		t.Errorf("sections %q, want %q", got, want)
+	}
+
+	lines, starts := layoutRules(rulesSections, helpWidth-8)
+	if len(starts) != len(rulesSections) {
+		t.Fatalf("%d section starts for %d sections", len(starts), len(rulesSections))
+	}
+	for i, start := range starts {
+		if l := lines[start]; l.kind != ruleHeading || l.text != rulesSections[i].Title {
+			t.Errorf("section %d starts at %+v", i, l)
+		}
+	}
+	for _, l := range lines {
+		if n := textWidth(l.text); n > helpWidth-8 {
+			t.Errorf("line wider than the card, %d: %q", n, l.text)
+		}
+	}
+}
+
+func TestRulesDiagram(t *testing.T) {
+	// The ko diagram, as the board renderer draws it
+	got := rulesSections[3].Diagrams[0].lines()
+	want := []string{
+		" ┌─┬─┬─┬─┐",
+		" ├ ● ○ ┼─┤",
+		" ● ○ a ○ ┤",
+		" ├ ● ○ ┼─┤",
+		" └─┴─┴─┴─┘",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ko diagram:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	SetASCIIOnly(true)
+	defer SetASCIIOnly(false)
+	for _, l := range rulesSections[3].Diagrams[0].lines() {
+		for _, r := range l {
+			if r > 127 {
+				t.Fatalf("non-ASCII %q in the ASCII diagram: %q", r, l)
+			}
+		}
+	}
+}
+
+func TestRulesNavigation(t *testing.T) {
+	closed := 0
+	r := NewRules(func() { closed++ })
+	screen := newTestScreen(t, 80, 24)
+	defer screen.Fini()
+	r.flex.SetRect(0, 0, 80, 24)
+	r.flex.Draw(screen)
+	screen.Show()
+	if text := screenText(screen); !strings.Contains(text, "Liberties") || !strings.Contains(text, "●") {
+		t.Fatalf("the first section should show with its diagrams:\n%s", text)
+	}
+
+	key := func(ch rune) { r.handleInput(tcell.NewEventKey(tcell.KeyRune, ch, tcell.ModNone)) }
+	key('j')
+	if r.offset != r.starts[1] {
+		t.Errorf("j went to line %d, want %d", r.offset, r.starts[1])
+	}
+	key('j')
+	key('k')
+	if r.offset != r.starts[1] {
+		t.Errorf("j j k went to line %d, want %d", r.offset, r.starts[1])
+	}
+	// k from inside a section goes to its heading first
+	r.scroll(1)
+	key('k')
+	if r.offset != r.starts[1] {
+		t.Errorf("k inside a section went to line %d, want %d", r.offset, r.starts[1])
+	}
+
+	// The last sections may not reach the top, but j still ends at the bottom
+	for range rulesSections {
+		key('j')
+	}
+	r.flex.Draw(screen)
+	screen.Show()
+	if text := screenText(screen); !strings.Contains(text, "Scoring") {
+		t.Errorf("the end should show the scoring section:\n%s", text)
+	}
+
+	r.handleInput(tcell.NewEventKey(tcell.KeyPgUp, 0, tcell.ModNone))
+	if r.handleInput(tcell.NewEventKey(tcell.KeyEsc, 0, tcell.ModNone)) != nil || closed != 1 {
+		t.Errorf("Esc closed %d times, want 1", closed)
+	}
+	r.Reset()
+	if r.offset != 0 {
+		t.Errorf("Reset left offset %d", r.offset)
+	}
+}
+
+func TestHelpOpensRules(t *testing.T) {
+	opened := 0
+	h := NewHelp(func() {})
+	h.handleInput(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone))
+	h.SetRulesFunc(func() { opened++ })
+	h.handleInput(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone))
+	if opened != 1 {
+		t.Errorf("rules opened %d times, want 1", opened)
+	}
+}
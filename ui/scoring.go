@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"errors"
+	"sort"
+
+	"termsuji-local/engine"
+	"termsuji-local/sgf"
+	"termsuji-local/types"
+)
+
+// Scoring mode follows two passes: the engine's dead stones are shown
+// dimmed, the player toggles whole groups with Enter, and then either
+// confirms the count or goes back to play. Toggles can be undone and
+// redone until scoring ends.
+
+// markToggle is one toggle of a group's dead mark, kept for undo.
+type markToggle struct {
+	group [][2]int
+	dead  bool // the group was marked dead, rather than alive again
+}
+
+// enterScoring starts scoring mode from the engine's view of the board.
+func (g *GoBoardUI) enterScoring(boardState *types.BoardState) {
+	g.BoardState = boardState
+	g.scoring = true
+	g.deadMarks = make(map[[2]int]bool, len(boardState.DeadStones))
+	for _, p := range boardState.DeadStones {
+		g.deadMarks[p] = true
+	}
+	g.clearMarkHistory()
+	g.refreshHint()
+	go func() {
+		g.app.QueueUpdateDraw(func() {})
+	}()
+}
+
+// IsScoring returns true while dead stones are being marked.
+func (g *GoBoardUI) IsScoring() bool {
+	return g.scoring
+}
+
+// ToggleDead marks the group at x, y dead, or alive again if it already
+// was. Empty points are ignored.
+func (g *GoBoardUI) ToggleDead(x, y int) {
+	if !g.scoring {
+		return
+	}
+	group := sgf.Group(g.BoardState.Board, x, y)
+	if len(group) == 0 {
+		return
+	}
+	op := markToggle{group: group, dead: !g.deadMarks[[2]int{x, y}]}
+	g.applyMark(op.group, op.dead)
+	g.markUndo = append(g.markUndo, op)
+	g.markRedo = nil
+	g.refreshHint()
+}
+
+// UndoMark takes back the last toggle of a dead mark.
+func (g *GoBoardUI) UndoMark() {
+	if !g.scoring || len(g.markUndo) == 0 {
+		return
+	}
+	op := g.markUndo[len(g.markUndo)-1]
+	g.markUndo = g.markUndo[:len(g.markUndo)-1]
+	g.applyMark(op.group, !op.dead)
+	g.markRedo = append(g.markRedo, op)
+	g.refreshHint()
+}
+
+// RedoMark makes the last undone toggle again.
+func (g *GoBoardUI) RedoMark() {
+	if !g.scoring || len(g.markRedo) == 0 {
+		return
+	}
+	op := g.markRedo[len(g.markRedo)-1]
+	g.markRedo = g.markRedo[:len(g.markRedo)-1]
+	g.applyMark(op.group, op.dead)
+	g.markUndo = append(g.markUndo, op)
+	g.refreshHint()
+}
+
+// applyMark marks every stone of group dead or alive.
+func (g *GoBoardUI) applyMark(group [][2]int, dead bool) {
+	for _, p := range group {
+		if dead {
+			g.deadMarks[p] = true
+		} else {
+			delete(g.deadMarks, p)
+		}
+	}
+}
+
+// clearMarkHistory forgets the toggles, once they can no longer be undone.
+func (g *GoBoardUI) clearMarkHistory() {
+	g.markUndo, g.markRedo = nil, nil
+}
+
+// deadStones returns the marked stones in board order.
+func (g *GoBoardUI) deadStones() [][2]int {
+	dead := make([][2]int, 0, len(g.deadMarks))
+	for p := range g.deadMarks {
+		dead = append(dead, p)
+	}
+	sort.Slice(dead, func(i, j int) bool {
+		if dead[i][1] != dead[j][1] {
+			return dead[i][1] < dead[j][1]
+		}
+		return dead[i][0] < dead[j][0]
+	})
+	return dead
+}
+
+// scoringResult counts the board with the current marks, e.g. "W+3.5".
+func (g *GoBoardUI) scoringResult() string {
+	bs := g.BoardState
+	score := g.ruleset().Score(bs.Board, g.deadStones(), bs.CapturesBlack, bs.CapturesWhite, g.gameConfig.Komi)
+	return score.LocalResult()
+}
+
+// ConfirmScore ends the game with the marked dead stones. The result
+// arrives through the engine's end-of-game callback like any other.
+func (g *GoBoardUI) ConfirmScore() {
+	if !g.scoring {
+		return
+	}
+	dead := g.deadStones()
+	g.scoring = false
+	g.deadMarks = nil
+	g.clearMarkHistory()
+	g.eng.ConfirmScore(dead)
+}
+
+// ResumePlay leaves scoring mode and continues the game.
+func (g *GoBoardUI) ResumePlay() {
+	if !g.scoring {
+		return
+	}
+	g.scoring = false
+	g.deadMarks = nil
+	g.clearMarkHistory()
+	if err := g.eng.ResumePlay(); errors.Is(err, engine.ErrClosed) {
+		return
+	}
+	g.BoardState = g.eng.GetBoardState()
+	g.lastTurnPass = false
+	g.refreshHint()
+}
+
+// showCount keeps the dead stones and territory of a counted game for
+// the overlay. Games that ended any other way have nothing to show.
+func (g *GoBoardUI) showCount(bs *types.BoardState) {
+	g.territory = nil
+	g.deadMarks = nil
+	if bs == nil || !bs.Scored {
+		return
+	}
+	g.territory = sgf.Territory(bs.Board, bs.DeadStones)
+	g.deadMarks = make(map[[2]int]bool, len(bs.DeadStones))
+	for _, p := range bs.DeadStones {
+		g.deadMarks[p] = true
+	}
+}
+
+// countDisagrees returns the local count of a counted game when the
+// engine's result differs from it, so the game-over hint can say so.
+func (g *GoBoardUI) countDisagrees() (string, bool) {
+	bs := g.BoardState
+	if bs == nil || !bs.Scored {
+		return "", false
+	}
+	score := g.ruleset().Score(bs.Board, bs.DeadStones, bs.CapturesBlack, bs.CapturesWhite, g.gameConfig.Komi)
+	score.EngineResult = bs.Outcome
+	return score.LocalResult(), score.Disagrees()
+}
+
+// territoryOverlay returns the owners to tint, or nil when the overlay
+// is off or there is nothing to show.
+func (g *GoBoardUI) territoryOverlay() [][]int {
+	if g.review != nil {
+		return g.territory // only once the position is counted
+	}
+	if !g.finished || g.hideTerritory || g.planningMode {
+		return nil
+	}
+	return g.territory
+}
+
+// ToggleTerritory shows or hides the territory of a counted game.
+func (g *GoBoardUI) ToggleTerritory() {
+	if g.territory == nil {
+		return
+	}
+	g.hideTerritory = !g.hideTerritory
+}
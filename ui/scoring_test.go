@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"termsuji-local/engine"
+	"termsuji-local/types"
+)
+
+func TestScoringMarks(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, Komi: 6.5}, 0)
+
+	bs := types.NewBoardState(9)
+	bs.Phase = "scoring"
+	bs.Board[4][4], bs.Board[4][5] = 1, 1 // a black pair
+	bs.Board[0][0] = 2
+	bs.DeadStones = [][2]int{{0, 0}} // the engine thinks the white stone is dead
+	eng.onScoring(bs)
+
+	if !g.IsScoring() || !strings.Contains(g.hint.GetText(true), "B+73.5") {
+		t.Fatalf("scoring %v, hint %q", g.IsScoring(), g.hint.GetText(true))
+	}
+
+	// Toggling takes the whole group, both ways.
+	g.ToggleDead(5, 4)
+	if !g.deadMarks[[2]int{4, 4}] || !g.deadMarks[[2]int{5, 4}] {
+		t.Errorf("marks after toggling the pair: %v", g.deadMarks)
+	}
+	g.ToggleDead(4, 4)
+	g.ToggleDead(0, 0)
+	g.ToggleDead(3, 3) // empty: nothing to mark
+	if len(g.deadMarks) != 0 {
+		t.Errorf("marks left: %v", g.deadMarks)
+	}
+
+	g.ToggleDead(0, 0)
+	g.ConfirmScore()
+	if g.IsScoring() || len(eng.confirmed) != 1 || eng.confirmed[0] != [2]int{0, 0} {
+		t.Errorf("confirmed %v, still scoring %v", eng.confirmed, g.IsScoring())
+	}
+}
+
+func TestScoringResume(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+	eng.onScoring(types.NewBoardState(9))
+	g.ResumePlay()
+	if g.IsScoring() || !eng.resumed {
+		t.Errorf("after resume: scoring %v, engine resumed %v", g.IsScoring(), eng.resumed)
+	}
+}
+
+func TestTerritoryOverlay(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+
+	// A wall down column 4 splits the board; a white stone on Black's side
+	// was counted dead.
+	eng.state = types.NewBoardState(9)
+	for y := 0; y < 9; y++ {
+		eng.state.Board[y][4] = 1
+		eng.state.Board[y][5] = 2
+	}
+	eng.state.Board[0][0] = 2
+	eng.state.Phase = "finished"
+	eng.state.Scored = true
+	eng.state.DeadStones = [][2]int{{0, 0}}
+	eng.onGameEnd("B+1.5")
+
+	owners := g.territoryOverlay()
+	if owners == nil {
+		t.Fatal("no territory after a counted game")
+	}
+	if owners[0][0] != 1 || owners[3][2] != 1 || owners[3][7] != 2 || owners[3][4] != 0 {
+		t.Errorf("owners row 3 = %v, corner %d", owners[3], owners[0][0])
+	}
+	if !g.deadMarks[[2]int{0, 0}] {
+		t.Error("the dead stone should stay marked")
+	}
+
+	g.ToggleTerritory()
+	if g.territoryOverlay() != nil {
+		t.Error("the overlay should toggle off")
+	}
+	g.ToggleTerritory()
+	if g.territoryOverlay() == nil {
+		t.Error("the overlay should toggle back on")
+	}
+
+	// A resignation has no count to show.
+	eng.state.Scored = false
+	eng.onGameEnd("W+R")
+	if g.territoryOverlay() != nil {
+		t.Error("overlay after a resignation")
+	}
+}
+
+func TestGameOverCrossChecksTheCount(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9}, 0)
+
+	// Black has columns 0-3 and a dead white stone, White columns 6-8:
+	// 36 + 1 against 27 is B+10.
+	eng.state = types.NewBoardState(9)
+	for y := 0; y < 9; y++ {
+		eng.state.Board[y][4] = 1
+		eng.state.Board[y][5] = 2
+	}
+	eng.state.Board[0][0] = 2
+	eng.state.Phase = "finished"
+	eng.state.Scored = true
+	eng.state.DeadStones = [][2]int{{0, 0}}
+
+	eng.state.Outcome = "B+10.0"
+	eng.onGameEnd(eng.state.Outcome)
+	if hint := g.hint.GetText(true); strings.Contains(hint, "local count") {
+		t.Errorf("hint flags a count that agrees: %q", hint)
+	}
+	eng.state.Outcome = "W+3.5"
+	eng.onGameEnd(eng.state.Outcome)
+	if hint := g.hint.GetText(true); !strings.Contains(hint, "local count: B+10") {
+		t.Errorf("hint = %q, want the disagreeing local count", hint)
+	}
+
+	// A resignation has no count to check
+	eng.state.Scored = false
+	eng.onGameEnd("W+R")
+	if hint := g.hint.GetText(true); strings.Contains(hint, "local count") {
+		t.Errorf("hint after a resignation: %q", hint)
+	}
+}
+
+func TestScoringUndoRedo(t *testing.T) {
+	g, eng := newTestBoard(t, engine.GameConfig{BoardSize: 9, Komi: 6.5}, 0)
+
+	bs := types.NewBoardState(9)
+	bs.Board[4][4], bs.Board[4][5] = 1, 1
+	bs.Board[0][0] = 2
+	bs.DeadStones = [][2]int{{0, 0}}
+	eng.onScoring(bs)
+
+	// Each step: the action, then the dead stones and count that follow.
+	steps := []struct {
+		do     func()
+		dead   int
+		result string
+	}{
+		{func() { g.ToggleDead(0, 0) }, 0, "W+6.5"},  // white alive: nobody's area
+		{func() { g.ToggleDead(4, 4) }, 2, "W+88.5"}, // the black pair too: the board is White's
+		{g.UndoMark, 0, "W+6.5"},
+		{g.UndoMark, 1, "B+73.5"}, // back to the engine's marks
+		{g.UndoMark, 1, "B+73.5"}, // nothing left to undo
+		{g.RedoMark, 0, "W+6.5"},
+		{g.RedoMark, 2, "W+88.5"},
+		{g.RedoMark, 2, "W+88.5"}, // nothing left to redo
+		{g.UndoMark, 0, "W+6.5"},
+		{func() { g.ToggleDead(4, 4) }, 2, "W+88.5"}, // a new toggle drops the redo
+		{g.RedoMark, 2, "W+88.5"},
+	}
+	for i, s := range steps {
+		s.do()
+		if len(g.deadMarks) != s.dead {
+			t.Errorf("step %d: %d dead stones, want %d", i+1, len(g.deadMarks), s.dead)
+		}
+		if g.scoringResult() != s.result {
+			t.Errorf("step %d: result %s, want %s", i+1, g.scoringResult(), s.result)
+		}
+	}
+
+	g.ResumePlay()
+	if len(g.markUndo) != 0 || len(g.markRedo) != 0 {
+		t.Errorf("resuming kept %d undo and %d redo toggles", len(g.markUndo), len(g.markRedo))
+	}
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"fmt"
+
+	"termsuji-local/rules"
+)
+
+// Positional superko: under Chinese and New Zealand rules no move may
+// bring back a whole-board position seen earlier in the game. Plans are
+// held to the game's rules; in play the engine is the referee, so a repeat
+// is only pointed out.
+
+// repeatOf returns the number of the move after which the game was last
+// in the position hashed, or 0 if it hasn't been.
+func (g *GoBoardUI) repeatOf(hash uint64) int {
+	if hash == 0 {
+		return 0
+	}
+	g.histMu.Lock()
+	defer g.histMu.Unlock()
+	for i := len(g.moveHistory) - 1; i >= 0; i-- {
+		if g.moveHistory[i].Hash == hash {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// repeatNote tells the player the position is the one after move n.
+func repeatNote(n int) string {
+	return fmt.Sprintf("same position as after move %d", n)
+}
+
+// startPlanPosition sets a plan's board to the game's position, which
+// keeps the game's earlier positions in mind for superko.
+func (g *GoBoardUI) startPlanPosition() {
+	bs := g.prePlanBoard
+	g.plan = rules.NewPosition(bs.Board)
+	g.plan.Captures = [3]int{0, bs.CapturesBlack, bs.CapturesWhite}
+	for _, m := range g.prePlanHistory {
+		g.plan.Remember(m.Hash)
+	}
+}
+
+// ruleset returns the rules the game is played by.
+func (g *GoBoardUI) ruleset() rules.Ruleset {
+	return rules.ByName(g.gameConfig.Ruleset)
+}
@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ValueSelect steps through a fixed list of values with the arrow keys,
+// for settings whose values aren't a contiguous range.
+type ValueSelect struct {
+	label    string
+	values   []int
+	index    int
+	focused  bool
+	format   func(int) string
+	onChange func(int)
+}
+
+// NewValueSelect creates a selector over values, starting at initial (or
+// the first value if initial isn't one of them). format renders a value
+// for display; nil shows the number.
+func NewValueSelect(label string, values []int, initial int, format func(int) string, onChange func(int)) *ValueSelect {
+	v := &ValueSelect{
+		label:    label,
+		values:   values,
+		format:   format,
+		onChange: onChange,
+	}
+	for i, value := range values {
+		if value == initial {
+			v.index = i
+		}
+	}
+	if v.format == nil {
+		v.format = func(value int) string { return fmt.Sprintf("%d", value) }
+	}
+	return v
+}
+
+// SetFocused sets the focus state.
+func (v *ValueSelect) SetFocused(focused bool) {
+	v.focused = focused
+}
+
+// HandleKey processes keyboard input. Returns true if handled.
+func (v *ValueSelect) HandleKey(event *tcell.EventKey) bool {
+	switch event.Key() {
+	case tcell.KeyLeft:
+		v.Step(-1)
+		return true
+	case tcell.KeyRight:
+		v.Step(1)
+		return true
+	}
+	return false
+}
+
+// Step moves the selection delta choices along, stopping at either end.
+func (v *ValueSelect) Step(delta int) {
+	i := v.index + delta
+	if i < 0 {
+		i = 0
+	}
+	if i > len(v.values)-1 {
+		i = len(v.values) - 1
+	}
+	if i != v.index {
+		v.index = i
+		v.changed()
+	}
+}
+
+func (v *ValueSelect) changed() {
+	if v.onChange != nil {
+		v.onChange(v.Value())
+	}
+}
+
+// Draw renders the selector, e.g. "◈ Handicap   ◀ 3 stones ▶".
+// Returns the number of rows used.
+func (v *ValueSelect) Draw(screen tcell.Screen, x, y, width int) int {
+	bgStyle := tcell.StyleDefault.Background(MenuColors.CardBG)
+	labelStyle := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
+	accentStyle := tcell.StyleDefault.Foreground(MenuColors.TitleAccent).Background(MenuColors.CardBG)
+	selectedStyle := emphasis(tcell.StyleDefault.Foreground(MenuColors.Selected).Background(MenuColors.CardBG))
+	unselectedStyle := tcell.StyleDefault.Foreground(MenuColors.Unselected).Background(MenuColors.CardBG)
+
+	col := x
+
+	// Focus cursor
+	if v.focused {
+		screen.SetContent(col, y, glyph('▸'), nil, selectedStyle)
+	} else {
+		screen.SetContent(col, y, ' ', nil, bgStyle)
+	}
+	col += 2
+
+	screen.SetContent(col, y, glyph('◈'), nil, accentStyle)
+	col += 2
+
+	for _, ch := range v.label {
+		screen.SetContent(col, y, ch, nil, labelStyle)
+		col++
+	}
+	col += 3 // spacing
+
+	arrowStyle := unselectedStyle
+	if v.focused {
+		arrowStyle = selectedStyle
+	}
+	screen.SetContent(col, y, glyph('◀'), nil, arrowStyle)
+	col += 2
+
+	for _, ch := range v.format(v.Value()) {
+		screen.SetContent(col, y, ch, nil, labelStyle)
+		col++
+	}
+	col++
+
+	screen.SetContent(col, y, glyph('▶'), nil, arrowStyle)
+
+	return 1
+}
+
+// Value returns the selected value.
+func (v *ValueSelect) Value() int {
+	return v.values[v.index]
+}
+
+// SetValue selects value if it is one of the choices.
+func (v *ValueSelect) SetValue(value int) {
+	for i, candidate := range v.values {
+		if candidate == value {
+			v.index = i
+			v.changed()
+			return
+		}
+	}
+}
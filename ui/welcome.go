@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tutorialText is the short introduction behind the welcome card's
+// Tutorial choice, in the markdown renderReleaseNotes understands.
+const tutorialText = `## Playing Go
+
+Black and White take turns placing a stone on an empty point. Stones of one color that touch along the lines form a group.
+
+## Capturing
+
+The empty points next to a group are its liberties. A group whose last liberty is filled is captured and taken off the board. You can't play a stone that would have no liberties, unless it captures.
+
+## Ending the game
+
+When neither side can gain anything more, both pass. You then mark the dead stones and count: your area is your stones plus the empty points only you surround. White gets komi for moving second.
+
+## Keys
+
+- **hjkl** or arrows move the cursor, **Enter** plays
+- **p** passes, **u** takes back a move, **R** resigns
+- **a** explores variations without touching the game
+- **q** leaves the game`
+
+// WelcomeUI is the card shown once on a fresh install, offering a small
+// game against an easy GnuGo, a short tutorial, or the usual setup card.
+type WelcomeUI struct {
+	pages    *tview.Pages
+	box      *tview.Box
+	card     *MenuCard
+	tutorial *tview.TextView
+	onQuick  func()
+	onManual func()
+
+	// Focus management
+	focusIndex int
+	buttons    []*MenuButton
+}
+
+// NewWelcome creates the welcome card. onQuick starts the starter game;
+// onManual goes to the setup card.
+func NewWelcome(onQuick func(), onManual func()) *WelcomeUI {
+	w := &WelcomeUI{
+		onQuick:  onQuick,
+		onManual: onManual,
+		card:     NewMenuCard("W E L C O M E"),
+	}
+
+	w.buttons = []*MenuButton{
+		NewMenuButton("(Q)UICK 9x9 VS EASY GNUGO", true, func() { w.onQuick() }),
+		NewMenuButton("(T)UTORIAL", false, w.showTutorial),
+		NewMenuButton("(S)ET UP MANUALLY", false, func() { w.onManual() }),
+	}
+	w.buttons[0].SetFocused(true)
+
+	w.box = tview.NewBox()
+	w.box.SetDrawFunc(w.draw)
+	w.box.SetInputCapture(w.handleInput)
+
+	helpText := tview.NewTextView().
+		SetText("↑↓ choose · Enter select · Esc set up manually").
+		SetTextAlign(tview.AlignCenter)
+	helpText.SetTextColor(MenuColors.Hint)
+	helpText.SetBackgroundColor(tcell.ColorDefault)
+
+	innerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(w.box, 15, 0, true).
+		AddItem(nil, 0, 1, false).
+		AddItem(helpText, 1, 0, false)
+
+	choices := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(innerFlex, 52, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	w.tutorial = tview.NewTextView()
+	w.tutorial.SetDynamicColors(true)
+	w.tutorial.SetWordWrap(true)
+	w.tutorial.SetBorder(true)
+	w.tutorial.SetBorderPadding(0, 0, 1, 1)
+	w.tutorial.SetTitle(" Tutorial ")
+	w.tutorial.SetText(renderReleaseNotes(tutorialText))
+	w.tutorial.SetInputCapture(w.handleTutorialInput)
+
+	tutorialHint := tview.NewTextView()
+	tutorialHint.SetDynamicColors(true)
+	tutorialHint.SetBorder(false)
+	tutorialHint.SetText("  " + keyHints("jk", "scroll", "Enter", "play a 9x9 game", "q", "back"))
+
+	tutorialPage := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(w.tutorial, 0, 1, true).
+		AddItem(tutorialHint, 1, 0, false)
+
+	w.pages = tview.NewPages().
+		AddPage("choices", choices, true, true).
+		AddPage("tutorial", tutorialPage, true, false)
+	return w
+}
+
+// Pages returns the container for this UI.
+func (w *WelcomeUI) Pages() *tview.Pages {
+	return w.pages
+}
+
+// showTutorial switches from the choices to the tutorial text.
+func (w *WelcomeUI) showTutorial() {
+	w.tutorial.ScrollToBeginning()
+	w.pages.SwitchToPage("tutorial")
+}
+
+// draw renders the card, a greeting and the choices, one per line.
+func (w *WelcomeUI) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	w.card.SetRect(x, y, width, height)
+	w.card.Draw(screen)
+
+	greeting := "New here? Pick a way to start."
+	style := tcell.StyleDefault.Foreground(MenuColors.Label).Background(MenuColors.CardBG)
+	drawText(screen, x+(width-textWidth(greeting))/2, y+5, greeting, style)
+
+	for i, b := range w.buttons {
+		b.Draw(screen, x+6, y+7+2*i)
+	}
+	return x, y, width, height
+}
+
+// handleInput moves between the choices and runs the one picked.
+func (w *WelcomeUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyUp, tcell.KeyBacktab:
+		w.cycleFocus(-1)
+		return nil
+	case tcell.KeyDown, tcell.KeyTab:
+		w.cycleFocus(1)
+		return nil
+	case tcell.KeyEscape:
+		w.onManual()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'k':
+			w.cycleFocus(-1)
+		case 'j':
+			w.cycleFocus(1)
+		case 'q':
+			w.onQuick()
+		case 't':
+			w.showTutorial()
+		case 's':
+			w.onManual()
+		}
+		return nil
+	}
+	if w.buttons[w.focusIndex].HandleKey(event) {
+		return nil
+	}
+	return event
+}
+
+// handleTutorialInput goes back to the choices, or straight into the
+// starter game.
+func (w *WelcomeUI) handleTutorialInput(event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Key() == tcell.KeyEscape, event.Key() == tcell.KeyRune && event.Rune() == 'q':
+		w.pages.SwitchToPage("choices")
+		return nil
+	case event.Key() == tcell.KeyEnter:
+		w.onQuick()
+		return nil
+	}
+	// j/k and arrows scroll via the TextView's own handling.
+	return event
+}
+
+// cycleFocus moves focus to the next/previous choice.
+func (w *WelcomeUI) cycleFocus(delta int) {
+	w.buttons[w.focusIndex].SetFocused(false)
+	w.focusIndex = (w.focusIndex + delta + len(w.buttons)) % len(w.buttons)
+	w.buttons[w.focusIndex].SetFocused(true)
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestWelcomeChoices(t *testing.T) {
+	var quick, manual int
+	w := NewWelcome(func() { quick++ }, func() { manual++ })
+	key := func(k tcell.Key, r rune) {
+		if front, _ := w.pages.GetFrontPage(); front == "tutorial" {
+			w.handleTutorialInput(tcell.NewEventKey(k, r, tcell.ModNone))
+		} else {
+			w.handleInput(tcell.NewEventKey(k, r, tcell.ModNone))
+		}
+	}
+	front := func() string {
+		name, _ := w.pages.GetFrontPage()
+		return name
+	}
+
+	key(tcell.KeyEnter, 0)
+	if quick != 1 {
+		t.Errorf("Enter on the first choice started %d quick games, want 1", quick)
+	}
+
+	key(tcell.KeyDown, 0)
+	key(tcell.KeyEnter, 0)
+	if front() != "tutorial" {
+		t.Fatalf("second choice shows %q, want the tutorial", front())
+	}
+	key(tcell.KeyRune, 'q')
+	if front() != "choices" {
+		t.Errorf("q in the tutorial shows %q, want the choices", front())
+	}
+	key(tcell.KeyRune, 't')
+	key(tcell.KeyEnter, 0)
+	if quick != 2 {
+		t.Errorf("Enter in the tutorial started %d quick games in all, want 2", quick)
+	}
+
+	w.pages.SwitchToPage("choices")
+	key(tcell.KeyEscape, 0)
+	key(tcell.KeyRune, 's')
+	if manual != 2 {
+		t.Errorf("Esc and s chose manual setup %d times, want 2", manual)
+	}
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// WhatsNewUI shows the release notes of a newly installed version.
+type WhatsNewUI struct {
+	flex    *tview.Flex
+	notes   *tview.TextView
+	hint    *tview.TextView
+	onClose func(dismiss bool)
+}
+
+// NewWhatsNew creates the release notes screen. onClose receives true when
+// the user asked not to see these notes again.
+func NewWhatsNew(onClose func(dismiss bool)) *WhatsNewUI {
+	wn := &WhatsNewUI{onClose: onClose}
+
+	wn.notes = tview.NewTextView()
+	wn.notes.SetDynamicColors(true)
+	wn.notes.SetWordWrap(true)
+	wn.notes.SetBorder(true)
+	wn.notes.SetBorderPadding(0, 0, 1, 1)
+	wn.notes.SetInputCapture(wn.handleInput)
+
+	wn.hint = tview.NewTextView()
+	wn.hint.SetDynamicColors(true)
+	wn.hint.SetBorder(false)
+	wn.hint.SetText("  " + keyHints("jk", "scroll", "q", "close", "d", "don't show again"))
+
+	wn.flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(wn.notes, 0, 1, true).
+		AddItem(wn.hint, 1, 0, false)
+	return wn
+}
+
+// Flex returns the flex container for this UI.
+func (wn *WhatsNewUI) Flex() *tview.Flex {
+	return wn.flex
+}
+
+// SetNotes sets the version and the raw (markdown) release notes to show.
+func (wn *WhatsNewUI) SetNotes(version, body string) {
+	wn.notes.SetTitle(fmt.Sprintf(" What's new in %s ", version))
+	wn.notes.SetText(renderReleaseNotes(body))
+	wn.notes.ScrollToBeginning()
+}
+
+func (wn *WhatsNewUI) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEsc:
+		wn.onClose(false)
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q':
+			wn.onClose(false)
+			return nil
+		case 'd':
+			wn.onClose(true)
+			return nil
+		}
+	}
+	// j/k and arrows scroll via the TextView's own handling.
+	return event
+}
+
+var (
+	mdLink   = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	mdBold   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdCode   = regexp.MustCompile("`([^`]+)`")
+	mdBullet = regexp.MustCompile(`^(\s*)[-*+] +`)
+)
+
+// renderReleaseNotes turns GitHub-flavored release notes into plain text
+// with tview tags: headings are bolded, bullets become "•", links show
+// their URL, and inline markup is dropped.
+func renderReleaseNotes(md string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+
+		heading := false
+		if trimmed := strings.TrimLeft(line, "#"); len(trimmed) < len(line) && strings.HasPrefix(trimmed, " ") {
+			line = strings.TrimSpace(trimmed)
+			heading = true
+		}
+
+		line = mdLink.ReplaceAllString(line, "$1 ($2)")
+		line = tview.Escape(line)
+		line = mdBold.ReplaceAllString(line, "$1")
+		line = mdCode.ReplaceAllString(line, "$1")
+		line = mdBullet.ReplaceAllString(line, "$1• ")
+
+		if heading {
+			line = tag("::b", line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
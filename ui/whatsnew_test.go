@@ -0,0 +1,11 @@
+package ui
+
+import "testing"
+
+func TestRenderReleaseNotes(t *testing.T) {
+	md := "## Highlights\r\n\r\n- **Rematch** with `n`\n  * see [docs](https://example.com)\n- keep [red] literal"
+	want := "[::b]Highlights[-:-:-]\n\n• Rematch with n\n  • see docs (https://example.com)\n• keep [red[] literal"
+	if got := renderReleaseNotes(md); got != want {
+		t.Errorf("renderReleaseNotes =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"termsuji-local/config"
+	"termsuji-local/sgf"
+)
+
+// runVerify implements "termsuji-local verify [-repair] [file.sgf ...]".
+// With no files it checks the game history. It returns the exit status:
+// 0 when everything is clean (or was repaired), 1 otherwise.
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "Write a "+sgf.RepairedSuffix+" copy of each truncated file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: termsuji-local verify [-repair] [file.sgf ...]")
+		fmt.Fprintln(fs.Output(), "Checks SGF files (default: the game history) for damage, and warns when")
+		fmt.Fprintln(fs.Output(), "a game's DT disagrees with the timestamp in its file name.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var reports []sgf.Report
+	if fs.NArg() == 0 {
+		var err error
+		if reports, err = sgf.VerifyDir(config.HistoryDir()); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			return 1
+		}
+	}
+	for _, path := range fs.Args() {
+		r, err := sgf.Verify(path)
+		if err != nil {
+			r = &sgf.Report{FilePath: path, Problems: []string{err.Error()}}
+		}
+		reports = append(reports, *r)
+	}
+
+	status := 0
+	damaged := 0
+	for _, r := range reports {
+		if r.OK() {
+			if len(r.Warnings) > 0 {
+				fmt.Printf("%s:\n", r.FilePath)
+				printWarnings(r)
+			}
+			continue
+		}
+		damaged++
+		fmt.Printf("%s:\n", r.FilePath)
+		for _, p := range r.Problems {
+			fmt.Printf("  %s\n", p)
+		}
+		printWarnings(r)
+		switch {
+		case r.Repairable && *repair:
+			out, err := sgf.RepairFile(r.FilePath)
+			if err != nil {
+				fmt.Printf("  repair failed: %s\n", err)
+				status = 1
+				continue
+			}
+			fmt.Printf("  repaired -> %s\n", out)
+		case r.Repairable:
+			fmt.Println("  repairable (run with -repair)")
+			status = 1
+		default:
+			status = 1
+		}
+	}
+	fmt.Printf("%d files checked, %d with problems\n", len(reports), damaged)
+	return status
+}
+
+// printWarnings lists a report's warnings under its file name. They
+// don't affect the exit status.
+func printWarnings(r sgf.Report) {
+	for _, w := range r.Warnings {
+		fmt.Printf("  warning: %s\n", w)
+	}
+}